@@ -17,6 +17,8 @@ import (
 	"text/template"
 	"time"
 
+	"fodmap/data"
+
 	"google.golang.org/genai"
 )
 
@@ -188,6 +190,8 @@ type Session struct {
 	FodmapClient   FodmapSessionClient
 	AllergenClient AllergenClient
 	ProductClient  ProductIngredientClient
+	MenuClient     MenuClient // nil when no menu store is configured
+	BusinessID     string     // the restaurant search_menu searches; "" in general mode
 	Backend        ChatBackend
 	SystemPrompt   string
 	Tools          []ToolDeclaration
@@ -273,7 +277,7 @@ func (s *Session) SendWithToolCalls(ctx context.Context, input string, onText fu
 				Result: resultMap,
 			})
 
-			result.ToolCalls = append(result.ToolCalls, fmt.Sprintf("%s(%v)", call.Name, call.Args["ingredient"]))
+			result.ToolCalls = append(result.ToolCalls, fmt.Sprintf("%s(%v)", call.Name, toolCallSubject(call.Args)))
 			turn.Calls = append(turn.Calls, ToolCallEntry(call))
 			turn.Responses = append(turn.Responses, ToolResponseEntry{Name: call.Name, Result: resultMap})
 		}
@@ -313,11 +317,25 @@ func (s *Session) DispatchTool(ctx context.Context, name string, args map[string
 			return ProductFodmapResponse{Product: product, Error: "product lookup is not configured"}
 		}
 		return AnalyzeProductFodmap(ctx, s.ProductClient, s.FodmapClient, product)
+	case "search_menu":
+		query, _ := args["query"].(string)
+		return s.searchMenu(ctx, query)
 	default:
 		return map[string]any{"error": "unknown tool: " + name}
 	}
 }
 
+// toolCallSubject returns the argument a tool call is about, for the
+// summaries in SendResult.ToolCalls.
+func toolCallSubject(args map[string]any) any {
+	for _, k := range []string{"ingredient", "product", "query"} {
+		if v, ok := args[k]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
 // ToMap converts a value to a map[string]any via JSON round-trip. This is
 // used to normalize tool response structs for Gemini's function-calling API.
 // Marshal/unmarshal errors are ignored because the input is always a struct
@@ -329,21 +347,6 @@ func ToMap(v any) map[string]any {
 	return m
 }
 
-// fodmapLevelRank orders FODMAP levels by severity so the worst case across a
-// product's ingredients can be computed. Unknown levels rank 0.
-func fodmapLevelRank(level string) int {
-	switch level {
-	case "low":
-		return 1
-	case "moderate":
-		return 2
-	case "high":
-		return 3
-	default:
-		return 0
-	}
-}
-
 // AnalyzeProductFodmap fetches a packaged product's ingredient list via the
 // ProductIngredientClient and classifies each ingredient with the FODMAP
 // client, returning the worst-case level and the union of FODMAP groups found.
@@ -376,7 +379,7 @@ func AnalyzeProductFodmap(ctx context.Context, products ProductIngredientClient,
 			Level:      res.FodmapLevel,
 			Groups:     res.FodmapGroups,
 		})
-		if r := fodmapLevelRank(res.FodmapLevel); r > worst {
+		if r := data.FodmapLevelRank(res.FodmapLevel); r > worst {
 			worst = r
 		}
 		for _, g := range res.FodmapGroups {
@@ -403,7 +406,8 @@ func AnalyzeProductFodmap(ctx context.Context, products ProductIngredientClient,
 // ---- tool declarations ----
 
 // FodmapAllergenTools returns the tool declarations for FODMAP and allergen
-// lookups, suitable for passing to Gemini's function-calling API.
+// lookups and menu search, suitable for passing to Gemini's function-calling
+// API.
 func FodmapAllergenTools() []ToolDeclaration {
 	return []ToolDeclaration{
 		{
@@ -421,6 +425,11 @@ func FodmapAllergenTools() []ToolDeclaration {
 			Description: "Assess the overall FODMAP level of a packaged or branded food product by name. Fetches the product's ingredient list from Open Food Facts, classifies each ingredient, and returns the worst-case FODMAP level plus the groups present.",
 			Parameters:  json.RawMessage(`{"type":"OBJECT","properties":{"product":{"type":"STRING","description":"The packaged or branded product name to assess (e.g. \"Oreo cookies\", \"Heinz tomato ketchup\")"}},"required":["product"]}`),
 		},
+		{
+			Name:        "search_menu",
+			Description: "Semantically search this restaurant's scraped menu. Returns matching dishes with their menu section, description, stated ingredients, price and FODMAP level.",
+			Parameters:  json.RawMessage(`{"type":"OBJECT","properties":{"query":{"type":"STRING","description":"What to look for on the menu: a dish, an ingredient or a kind of food (e.g. \"rice noodles\", \"dishes without garlic\", \"vegetarian mains\")"}},"required":["query"]}`),
+		},
 	}
 }
 
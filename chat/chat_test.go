@@ -645,14 +645,14 @@ func TestToMap_Nil(t *testing.T) {
 
 func TestFodmapAllergenTools_HasAllDeclarations(t *testing.T) {
 	tools := FodmapAllergenTools()
-	if len(tools) != 3 {
-		t.Fatalf("expected 3 declarations, got %d", len(tools))
+	if len(tools) != 4 {
+		t.Fatalf("expected 4 declarations, got %d", len(tools))
 	}
 	names := map[string]bool{}
 	for _, decl := range tools {
 		names[decl.Name] = true
 	}
-	if !names["lookup_fodmap"] || !names["lookup_allergens"] || !names["lookup_product_fodmap"] {
+	if !names["lookup_fodmap"] || !names["lookup_allergens"] || !names["lookup_product_fodmap"] || !names["search_menu"] {
 		t.Errorf("missing expected tool declarations: %v", names)
 	}
 }
@@ -0,0 +1,139 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Menu sizes for chat sessions.
+const (
+	// MenuContextLimit is how many dishes of a restaurant's menu are loaded
+	// into the system prompt; the search_menu tool finds the rest.
+	MenuContextLimit = 150
+	// MenuSearchLimit is how many dishes a search_menu call returns.
+	MenuSearchLimit = 8
+)
+
+// MenuItem is a dish from a restaurant's scraped menu.
+type MenuItem struct {
+	DishName    string   `json:"dish_name"`
+	Section     string   `json:"menu_section,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Ingredients []string `json:"stated_ingredients,omitempty"`
+	Price       *float64 `json:"price,omitempty"`
+	FodmapLevel string   `json:"fodmap_level,omitempty"` // low, moderate, high or unknown, classified from the dish's text
+}
+
+// MenuClient searches the dishes of one restaurant's menu. An empty query
+// lists the menu.
+type MenuClient interface {
+	SearchMenu(ctx context.Context, businessID, query string, limit int) ([]MenuItem, error)
+}
+
+// MenuToolResponse is the result of a search_menu tool call.
+type MenuToolResponse struct {
+	Query   string     `json:"query"`
+	Dishes  []MenuItem `json:"dishes,omitempty"`
+	Message string     `json:"message,omitempty"`
+	Error   string     `json:"error,omitempty"`
+}
+
+// searchMenu runs a search_menu tool call against the session's restaurant.
+func (s *Session) searchMenu(ctx context.Context, query string) MenuToolResponse {
+	resp := MenuToolResponse{Query: query}
+	if s.MenuClient == nil || s.BusinessID == "" {
+		resp.Error = "no menu is available for this restaurant"
+		return resp
+	}
+	items, err := s.MenuClient.SearchMenu(ctx, s.BusinessID, query, MenuSearchLimit)
+	if err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+	if len(items) == 0 {
+		resp.Message = "no dishes on this restaurant's menu match the query"
+	}
+	resp.Dishes = items
+	return resp
+}
+
+// FormatMenuContext lays out a restaurant's menu for the system prompt,
+// grouped by menu section in the order the sections first appear. It
+// returns "" for an empty menu.
+func FormatMenuContext(bizName string, items []MenuItem) string {
+	if len(items) == 0 {
+		return ""
+	}
+	var sections []string
+	bySection := make(map[string][]MenuItem)
+	for _, it := range items {
+		if _, ok := bySection[it.Section]; !ok {
+			sections = append(sections, it.Section)
+		}
+		bySection[it.Section] = append(bySection[it.Section], it)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**MENU OF %s** (%d dishes, as scraped from the restaurant's menu):\n", strings.ToUpper(bizName), len(items))
+	for _, sec := range sections {
+		heading := sec
+		if heading == "" {
+			heading = "Other"
+		}
+		fmt.Fprintf(&sb, "\n%s\n", heading)
+		for _, it := range bySection[sec] {
+			sb.WriteString("- " + it.DishName)
+			if it.Price != nil {
+				fmt.Fprintf(&sb, " ($%.2f)", *it.Price)
+			}
+			if it.FodmapLevel != "" {
+				fmt.Fprintf(&sb, " [FODMAP: %s]", it.FodmapLevel)
+			}
+			if it.Description != "" {
+				sb.WriteString(": " + it.Description)
+			}
+			if len(it.Ingredients) > 0 {
+				sb.WriteString(" Ingredients: " + strings.Join(it.Ingredients, ", ") + ".")
+			}
+			sb.WriteString("\n")
+		}
+	}
+	if len(items) >= MenuContextLimit {
+		sb.WriteString("\nThe menu may list more dishes than these; call search_menu to find them.\n")
+	}
+	return sb.String()
+}
+
+// SearchMenu searches a restaurant's menu through the server's menu search
+// endpoint. An empty query lists the menu.
+func (c *HTTPFodmapServerClient) SearchMenu(ctx context.Context, businessID, query string, limit int) ([]MenuItem, error) {
+	u := c.serverURL + "/api/v1/search/menu/" + url.PathEscape(query) + "?business_id=" + url.QueryEscape(businessID) + "&limit=" + strconv.Itoa(limit)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("building request: %w", err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP GET %s: %w", u, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("server returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var data struct {
+		Items []MenuItem `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("decoding menu response: %w", err)
+	}
+	return data.Items, nil
+}
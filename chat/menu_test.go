@@ -0,0 +1,119 @@
+package chat
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFormatMenuContext(t *testing.T) {
+	price := 14.5
+	items := []MenuItem{
+		{DishName: "Margherita", Section: "Pizza", Price: &price, FodmapLevel: "high", Ingredients: []string{"tomato", "mozzarella"}},
+		{DishName: "Tiramisu", Section: "Dessert", FodmapLevel: "unknown"},
+		{DishName: "Marinara", Section: "Pizza", Description: "No cheese", FodmapLevel: "high"},
+		{DishName: "Bread", FodmapLevel: "high"},
+	}
+	got := FormatMenuContext("Luigi's", items)
+
+	for _, want := range []string{
+		"**MENU OF LUIGI'S** (4 dishes",
+		"- Margherita ($14.50) [FODMAP: high] Ingredients: tomato, mozzarella.",
+		"- Marinara [FODMAP: high]: No cheese",
+		"\nOther\n- Bread",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("menu context missing %q:\n%s", want, got)
+		}
+	}
+	// Sections keep the order they first appear in, with their dishes
+	// together.
+	pizza, marinara, dessert := strings.Index(got, "\nPizza\n"), strings.Index(got, "Marinara"), strings.Index(got, "\nDessert\n")
+	if pizza < 0 || dessert < 0 || !(pizza < marinara && marinara < dessert) {
+		t.Errorf("sections out of order:\n%s", got)
+	}
+	if strings.Contains(got, "search_menu") {
+		t.Error("a short menu should not point at search_menu")
+	}
+}
+
+func TestFormatMenuContext_Empty(t *testing.T) {
+	if got := FormatMenuContext("Luigi's", nil); got != "" {
+		t.Errorf("got %q, want empty", got)
+	}
+}
+
+func TestFormatMenuContext_AtLimit(t *testing.T) {
+	items := make([]MenuItem, MenuContextLimit)
+	for i := range items {
+		items[i] = MenuItem{DishName: "Dish"}
+	}
+	if got := FormatMenuContext("Luigi's", items); !strings.Contains(got, "call search_menu") {
+		t.Error("a menu cut at the limit should point at search_menu")
+	}
+}
+
+func TestDispatchTool_SearchMenu(t *testing.T) {
+	var gotPath, gotBusiness, gotLimit string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotBusiness = r.URL.Query().Get("business_id")
+		gotLimit = r.URL.Query().Get("limit")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"items": []map[string]any{
+				{"dish_name": "Risotto", "menu_section": "Mains", "fodmap_level": "low", "stated_ingredients": []string{"rice"}},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	s := &Session{MenuClient: NewHTTPFodmapServerClient(srv.URL), BusinessID: "b1"}
+	result := s.DispatchTool(t.Context(), "search_menu", map[string]any{"query": "rice dishes"}).(MenuToolResponse)
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if len(result.Dishes) != 1 || result.Dishes[0].DishName != "Risotto" || result.Dishes[0].Ingredients[0] != "rice" {
+		t.Errorf("dishes = %+v", result.Dishes)
+	}
+	if gotPath != "/api/v1/search/menu/rice dishes" || gotBusiness != "b1" || gotLimit != "8" {
+		t.Errorf("request path=%q business_id=%q limit=%q", gotPath, gotBusiness, gotLimit)
+	}
+}
+
+func TestDispatchTool_SearchMenuNoResults(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"items": []any{}})
+	}))
+	defer srv.Close()
+
+	s := &Session{MenuClient: NewHTTPFodmapServerClient(srv.URL), BusinessID: "b1"}
+	result := s.DispatchTool(t.Context(), "search_menu", map[string]any{"query": "sushi"}).(MenuToolResponse)
+	if result.Message == "" || result.Error != "" {
+		t.Errorf("got %+v, want a no-match message", result)
+	}
+}
+
+func TestDispatchTool_SearchMenuWithoutRestaurant(t *testing.T) {
+	s := &Session{MenuClient: NewHTTPFodmapServerClient("http://unused")}
+	result := s.DispatchTool(t.Context(), "search_menu", map[string]any{"query": "pasta"}).(MenuToolResponse)
+	if result.Error == "" {
+		t.Error("expected error when the session has no restaurant")
+	}
+}
+
+func TestDispatchTool_SearchMenuServerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	s := &Session{MenuClient: NewHTTPFodmapServerClient(srv.URL), BusinessID: "b1"}
+	result := s.DispatchTool(t.Context(), "search_menu", map[string]any{"query": "pasta"}).(MenuToolResponse)
+	if result.Error == "" {
+		t.Error("expected error in response")
+	}
+}
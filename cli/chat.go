@@ -56,6 +56,7 @@ func runChat(cmd *cobra.Command, args []string) error {
 
 	var biz *chat.Business
 	var reviews []chat.Review
+	var menu []chat.MenuItem
 
 	if query != "" {
 		var err error
@@ -70,6 +71,12 @@ func runChat(cmd *cobra.Command, args []string) error {
 			} else {
 				fmt.Printf("Fetched %d reviews.\n", len(reviews))
 			}
+			menu, err = fodmapClient.SearchMenu(ctx, biz.ID, "", chat.MenuContextLimit)
+			if err != nil {
+				slog.Warn("fetching menu failed", "error", err)
+			} else {
+				fmt.Printf("Fetched %d menu dishes.\n", len(menu))
+			}
 		}
 	}
 
@@ -93,6 +100,9 @@ func runChat(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("rendering system prompt: %w", err)
 	}
+	if menuContext := chat.FormatMenuContext(biz.Name, menu); menuContext != "" {
+		systemPrompt += "\n\n" + menuContext
+	}
 
 	var history []chat.Message
 	if len(reviews) > 0 {
@@ -147,6 +157,10 @@ func runChat(cmd *cobra.Command, args []string) error {
 		Tools:          chat.FodmapAllergenTools(),
 		History:        history,
 	}
+	if biz.ID != "" {
+		session.MenuClient = fodmapClient
+		session.BusinessID = biz.ID
+	}
 
 	scanner := bufio.NewScanner(os.Stdin)
 	fmt.Print("> ")
@@ -0,0 +1,292 @@
+package cli
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"fodmap/search"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/riverqueue/river"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var embeddingsCmd = &cobra.Command{
+	Use:   "embeddings",
+	Short: "Manage the versioned embedding spaces of the Postgres search backend",
+	Long: `Switch embedding models without a wipe. Every collection (reviews, menu,
+fodmap) has one active embedding space serving queries. "create" adds a new
+space for another model and enqueues a background job that fills it while the
+active one keeps serving; "activate" swaps the filled space in atomically.
+The job runs on a server started with --enable-pipeline.`,
+}
+
+func init() {
+	rootCmd.AddCommand(embeddingsCmd)
+	embeddingsCmd.PersistentFlags().String("postgres-dsn", "", "PostgreSQL DSN (or POSTGRES_DSN env)")
+
+	embeddingsCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List embedding spaces and how far each is filled",
+		Args:  cobra.NoArgs,
+		RunE:  runEmbeddingsList,
+	})
+
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Start a new embedding space and enqueue its re-embedding job",
+		Long: `Create an embedding space for --collection with the given embedder, add
+its vector column and enqueue the River job that embeds every row into it.
+With --activate the job cuts the collection over once the space is filled;
+otherwise run "embeddings activate <id>" when ready.`,
+		Args: cobra.NoArgs,
+		RunE: runEmbeddingsCreate,
+	}
+	createCmd.Flags().String("collection", "", "Collection to re-embed: reviews | menu | fodmap")
+	createCmd.Flags().String("embedder", "ollama", "Embedding backend: ollama | tei | vectorizer | hash")
+	createCmd.Flags().String("ollama-url", "http://localhost:11434", "Ollama server URL")
+	createCmd.Flags().String("ollama-model", "nomic-embed-text", "Ollama embedding model")
+	createCmd.Flags().String("tei-url", "", "Text Embeddings Inference (TEI) service URL (used when --embedder=tei)")
+	createCmd.Flags().String("tei-model", "nomic-embed-text", "TEI model name (informational; TEI serves one model per instance)")
+	createCmd.Flags().String("vectorizer-url", "", "Base URL for the HTTP vectorizer-proxy (used when --embedder=vectorizer)")
+	createCmd.Flags().Int("embedding-dim", search.ExpectedEmbeddingDim, "Vector dimension the new model returns")
+	createCmd.Flags().Bool("activate", false, "Activate the space as soon as it is filled")
+	_ = createCmd.MarkFlagRequired("collection")
+	embeddingsCmd.AddCommand(createCmd)
+
+	embeddingsCmd.AddCommand(&cobra.Command{
+		Use:   "activate [id]",
+		Short: "Make a filled embedding space the active one for its collection",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runEmbeddingsActivate,
+	})
+	embeddingsCmd.AddCommand(&cobra.Command{
+		Use:   "drop [id]",
+		Short: "Drop a pending or retired embedding space and its vectors",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runEmbeddingsDrop,
+	})
+}
+
+// embeddingsDSN resolves the embeddings command's Postgres DSN.
+func embeddingsDSN(cmd *cobra.Command) (string, error) {
+	dsn, _ := cmd.Flags().GetString("postgres-dsn")
+	if dsn == "" {
+		dsn = viper.GetString("POSTGRES_DSN")
+	}
+	if dsn == "" {
+		return "", fmt.Errorf("must specify --postgres-dsn")
+	}
+	return dsn, nil
+}
+
+// openEmbeddingSpaces connects a PostgresClient for managing embedding
+// spaces; it embeds nothing itself, so it carries no embedder.
+func openEmbeddingSpaces(cmd *cobra.Command) (*search.PostgresClient, error) {
+	dsn, err := embeddingsDSN(cmd)
+	if err != nil {
+		return nil, err
+	}
+	sc, err := search.NewPostgresClient(dsn, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connect to db: %w", err)
+	}
+	return sc, nil
+}
+
+func runEmbeddingsList(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	sc, err := openEmbeddingSpaces(cmd)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = sc.Close() }()
+
+	spaces, err := sc.EmbeddingSpaces(ctx)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	for _, s := range spaces {
+		progress := "-"
+		if s.Status == search.SpaceBuilding || s.Status == search.SpaceReady {
+			filled, total, err := sc.EmbeddingSpaceProgress(ctx, s)
+			if err != nil {
+				return err
+			}
+			progress = fmt.Sprintf("%d/%d", filled, total)
+		}
+		fmt.Fprintf(out, "%-4d %-8s %-9s %-24s dim=%-5d %-20s %s\n",
+			s.ID, s.Collection, s.Status, s.Model, s.Dim, s.Column, progress)
+	}
+	return nil
+}
+
+func runEmbeddingsCreate(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	flags := cmd.Flags()
+	collection, _ := flags.GetString("collection")
+	activate, _ := flags.GetBool("activate")
+	cfg := search.EmbedderConfig{}
+	cfg.Type, _ = flags.GetString("embedder")
+	cfg.OllamaURL, _ = flags.GetString("ollama-url")
+	cfg.OllamaModel, _ = flags.GetString("ollama-model")
+	cfg.TEIURL, _ = flags.GetString("tei-url")
+	cfg.TEIModel, _ = flags.GetString("tei-model")
+	cfg.VectorizerURL, _ = flags.GetString("vectorizer-url")
+	cfg.Dim, _ = flags.GetInt("embedding-dim")
+
+	// Building the embedder pings it and checks its dimension, so a
+	// misconfigured model fails here rather than in the background job.
+	e, err := search.NewEmbedder(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("building embedder: %w", err)
+	}
+	_ = e.Close()
+
+	dsn, err := embeddingsDSN(cmd)
+	if err != nil {
+		return err
+	}
+	sc, err := search.NewPostgresClient(dsn, nil)
+	if err != nil {
+		return fmt.Errorf("connect to db: %w", err)
+	}
+	defer func() { _ = sc.Close() }()
+
+	space, err := sc.CreateEmbeddingSpace(ctx, collection, cfg)
+	if err != nil {
+		return err
+	}
+
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return fmt.Errorf("connect to db: %w", err)
+	}
+	defer pool.Close()
+	riverClient, err := newRiverClient(pool, &river.Config{})
+	if err != nil {
+		return fmt.Errorf("create river client: %w", err)
+	}
+	_, err = riverClient.Insert(ctx, search.ReembedArgs{SpaceID: space.ID, Activate: activate},
+		&river.InsertOpts{UniqueOpts: river.UniqueOpts{ByArgs: true}})
+	if err != nil {
+		return fmt.Errorf("enqueue re-embedding (space %d was created; drop it or enqueue again): %w", space.ID, err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Created embedding space %d (%s, %s, dim %d) in column %s and enqueued its re-embedding job\n",
+		space.ID, space.Collection, space.Model, space.Dim, space.Column)
+	return nil
+}
+
+func runEmbeddingsActivate(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid space id %q: %w", args[0], err)
+	}
+	sc, err := openEmbeddingSpaces(cmd)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = sc.Close() }()
+
+	if err := sc.ActivateEmbeddingSpace(ctx, id); err != nil {
+		return err
+	}
+
+	// Rows written during the grace period lose their vector; the
+	// re-embedding job embeds them again once it is over. It is inserted
+	// without uniqueness, since the job that filled the space has completed
+	// with the same args.
+	dsn, _ := embeddingsDSN(cmd)
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return fmt.Errorf("connect to db: %w", err)
+	}
+	defer pool.Close()
+	riverClient, err := newRiverClient(pool, &river.Config{})
+	if err != nil {
+		return fmt.Errorf("create river client: %w", err)
+	}
+	if _, err := riverClient.Insert(ctx, search.ReembedArgs{SpaceID: id}, nil); err != nil {
+		return fmt.Errorf("enqueue re-embedding of rows written during cutover (space %d is active; enqueue it again): %w", id, err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Activated embedding space %d; running processes pick it up within %s, and rows written until then are re-embedded after %s\n",
+		id, search.DefaultEmbeddingSpaceTTL, search.EmbeddingSpaceGrace)
+	return nil
+}
+
+func runEmbeddingsDrop(cmd *cobra.Command, args []string) error {
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid space id %q: %w", args[0], err)
+	}
+	sc, err := openEmbeddingSpaces(cmd)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = sc.Close() }()
+
+	if err := sc.DropEmbeddingSpace(cmd.Context(), id); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Dropped embedding space %d\n", id)
+	return nil
+}
+
+// addEmbeddingCacheFlags registers the embedding cache flags on a command
+// that embeds documents.
+func addEmbeddingCacheFlags(cmd *cobra.Command) {
+	cmd.Flags().String("embedding-cache", "", "Cache vectors by model and text so re-runs skip texts already embedded: postgres (embedding_cache table at --postgres-dsn) or a directory for an on-disk cache; empty disables")
+	cmd.Flags().Int("embedding-cache-max-entries", search.DefaultEmbeddingCacheMaxEntries, "Vectors the embedding cache keeps; least recently used ones beyond it are evicted (0 = unbounded)")
+}
+
+// withEmbeddingCache wraps e, built from cfg, in the cache --embedding-cache
+// selects. With caching off it returns e and a nil cache. On error e is
+// closed.
+func withEmbeddingCache(e search.Embedder, cfg search.EmbedderConfig) (search.Embedder, *search.CachingEmbedder, error) {
+	where := viper.GetString("embedding-cache")
+	if where == "" {
+		return e, nil, nil
+	}
+	var store search.EmbeddingCacheStore
+	if where == "postgres" {
+		dsn := viper.GetString("postgres-dsn")
+		if dsn == "" {
+			dsn = viper.GetString("POSTGRES_DSN")
+		}
+		if dsn == "" {
+			_ = e.Close()
+			return nil, nil, fmt.Errorf("--embedding-cache=postgres requires --postgres-dsn")
+		}
+		pc, err := search.NewPostgresEmbeddingCache(dsn)
+		if err != nil {
+			_ = e.Close()
+			return nil, nil, fmt.Errorf("embedding cache: %w", err)
+		}
+		store = pc
+	} else {
+		lc, err := search.NewLocalEmbeddingCache(where)
+		if err != nil {
+			_ = e.Close()
+			return nil, nil, fmt.Errorf("embedding cache: %w", err)
+		}
+		store = lc
+	}
+	cache := search.NewCachingEmbedder(e, cfg, store, viper.GetInt("embedding-cache-max-entries"))
+	slog.Info("embedding cache ready", "store", where, "namespace", cache.Stats().Namespace)
+	return cache, cache, nil
+}
+
+// logEmbeddingCacheStats logs a run's embedding cache hits and misses; a nil
+// cache logs nothing.
+func logEmbeddingCacheStats(cache *search.CachingEmbedder) {
+	if cache == nil {
+		return
+	}
+	s := cache.Stats()
+	slog.Info("embedding cache", "hits", s.Hits, "misses", s.Misses, "hit_rate", s.HitRate, "evicted", s.Evicted, "errors", s.Errors)
+}
@@ -2,12 +2,11 @@ package cli
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net"
-	"os"
 	"strconv"
 	"strings"
 	"sync"
@@ -40,20 +39,26 @@ func init() {
 	indexCmd.Flags().Int("batch-size", 512, "Number of reviews per Weaviate batch")
 	indexCmd.Flags().Int("workers", 4, "Number of concurrent batch upload goroutines")
 	indexCmd.Flags().String("archive", data.DefaultArchivePath, "Path to the Yelp dataset TAR archive")
-	indexCmd.Flags().String("checkpoint", "index.checkpoint", "Path to checkpoint file (empty string disables checkpointing)")
-	indexCmd.Flags().Int("start-offset", 0, "Skip this many reviews before indexing (overrides checkpoint)")
+	indexCmd.Flags().String("source", "", "Reviews to index: tar:<archive>, avro:<file or dir> (from `event write`), jsonl:<file or dir>, or postgres[:<dsn>] (the reviews table); default is the --archive tar")
+	indexCmd.Flags().String("checkpoint", "index.checkpoint", "Path to checkpoint file recording the source position reached (empty string disables checkpointing)")
+	indexCmd.Flags().Int("start-offset", 0, "Skip this many reviews of the tar source before indexing (overrides checkpoint)")
+	indexCmd.Flags().String("review-hashes", "index-hashes", "Where per-review content hashes are kept so unchanged reviews are not re-chunked or re-embedded: postgres (review_hashes table at --postgres-dsn) or a directory; empty re-indexes every review")
 	indexCmd.Flags().String("vectorizer", "", "t2v-transformers host:port for direct pre-vectorization (legacy; prefer --embedder=vectorizer with --vectorizer-url)")
-	indexCmd.Flags().String("embedder", "ollama", "Embedding backend: ollama | tei | vectorizer")
+	indexCmd.Flags().String("embedder", "ollama", "Embedding backend: ollama | tei | vectorizer | hash (deterministic, no service; for local development)")
 	indexCmd.Flags().String("ollama-url", "http://localhost:11434", "Ollama server URL")
 	indexCmd.Flags().String("ollama-model", "nomic-embed-text", "Ollama embedding model")
 	indexCmd.Flags().String("tei-url", "", "Text Embeddings Inference (TEI) service URL (used when --embedder=tei)")
 	indexCmd.Flags().String("tei-model", "nomic-embed-text", "TEI model name (informational; TEI serves one model per instance)")
+	indexCmd.Flags().Int("embedding-dim", search.ExpectedEmbeddingDim, "Vector dimension the embedding model returns (must match the collection's active embedding space)")
+	addEmbeddingCacheFlags(indexCmd)
 	indexCmd.Flags().String("vectorizer-url", "", "Base URL for the HTTP vectorizer-proxy (used when --embedder=vectorizer)")
 	indexCmd.Flags().String("filter-city", "", "Filter reviews by city")
+	indexCmd.Flags().String("local-index", "", "Directory of the embedded on-disk search backend; when set it is used instead of Postgres/Pinecone/Weaviate (no service needed)")
 	indexCmd.Flags().Bool("postgres-search", false, "Use PostgreSQL (pgvector) for vector search instead of Weaviate/Pinecone")
 	indexCmd.Flags().String("postgres-dsn", "", "PostgreSQL connection string (required if postgres-search is true)")
 	indexCmd.Flags().String("pinecone-api-key", "", "Pinecone API Key")
 	indexCmd.Flags().String("pinecone-index-host", "", "Pinecone Index Host (e.g. https://index-name.svc.pinecone.io)")
+	indexCmd.Flags().String("pinecone-bm25-stats", search.DefaultPineconeBM25StatsPath, "File the BM25 corpus statistics of the Pinecone review index are kept in (empty disables)")
 }
 
 func runIndex(cmd *cobra.Command, _ []string) error {
@@ -63,6 +68,7 @@ func runIndex(cmd *cobra.Command, _ []string) error {
 	batchSize := viper.GetInt("batch-size")
 	numWorkers := viper.GetInt("workers")
 	archivePath := viper.GetString("archive")
+	sourceSpec := viper.GetString("source")
 	checkpointPath := viper.GetString("checkpoint")
 	startOffset := viper.GetInt("start-offset")
 	vectorizerHost := viper.GetString("vectorizer")
@@ -77,6 +83,13 @@ func runIndex(cmd *cobra.Command, _ []string) error {
 	if numWorkers <= 0 {
 		return fmt.Errorf("workers must be greater than 0")
 	}
+	source, err := parseReviewSource(sourceSpec, archivePath)
+	if err != nil {
+		return err
+	}
+	if startOffset > 0 && source.Kind != "tar" {
+		return fmt.Errorf("--start-offset applies to the tar source only")
+	}
 
 	postgresSearch := viper.GetBool("postgres-search")
 	postgresDSN := viper.GetString("postgres-dsn")
@@ -94,47 +107,91 @@ func runIndex(cmd *cobra.Command, _ []string) error {
 		vectorizerURL = "http://" + vectorizerHost
 	}
 
-	embedder, err := search.NewEmbedder(ctx, search.EmbedderConfig{
+	embedderCfg := search.EmbedderConfig{
 		Type:          embedderType,
 		OllamaURL:     ollamaURL,
 		OllamaModel:   ollamaModel,
 		TEIURL:        viper.GetString("tei-url"),
 		TEIModel:      viper.GetString("tei-model"),
 		VectorizerURL: vectorizerURL,
-	})
+		Dim:           viper.GetInt("embedding-dim"),
+	}
+	embedder, err := search.NewEmbedder(ctx, embedderCfg)
 	if err != nil {
 		return fmt.Errorf("building embedder: %w", err)
 	}
+	// A re-run (e.g. after a crash) finds the chunks it already embedded
+	// in the cache.
+	embedder, embedCache, err := withEmbeddingCache(embedder, embedderCfg)
+	if err != nil {
+		return err
+	}
 	defer func() { _ = embedder.Close() }()
+	defer logEmbeddingCacheStats(embedCache)
 	slog.Info("embedder ready", "type", embedderType)
 
+	// docEmbedder vectorises review chunks. On Postgres it follows the
+	// reviews embedding space, so chunks indexed after a cutover are
+	// embedded with the new model.
+	docEmbedder := embedder
+
+	// target names the index the reviews go to, keying their content
+	// hashes: a review indexed into one backend or with one model is still
+	// new to another.
 	var client server.Searcher
-	if postgresSearch && postgresDSN != "" {
+	var target string
+	if localIndex := viper.GetString("local-index"); localIndex != "" {
+		lc, err := search.NewLocalClient(localIndex, embedder)
+		if err != nil {
+			return fmt.Errorf("local index: %w", err)
+		}
+		defer func() { _ = lc.Close() }()
+		client = lc
+		target = "local:" + localIndex
+		postgresSearch = false // no restaurants table to union Yelp IDs into
+	} else if postgresSearch && postgresDSN != "" {
 		sc, err := search.NewPostgresClient(postgresDSN, embedder)
 		if err != nil {
 			return fmt.Errorf("postgres client: %w", err)
 		}
+		sc.FollowEmbeddingSpaces(search.DefaultEmbeddingSpaceTTL)
+		docEmbedder = sc.CollectionEmbedder(search.CollectionReviews)
 		client = sc
+		target = "postgres"
 	} else if pineconeAPIKey != "" && pineconeIndexHost != "" {
-		client = search.NewPineconeClient(pineconeAPIKey, pineconeIndexHost, embedder)
+		pc := search.NewPineconeClient(pineconeAPIKey, pineconeIndexHost, embedder)
+		if statsPath := viper.GetString("pinecone-bm25-stats"); statsPath != "" {
+			if err := pc.LoadCorpusStats(statsPath); err != nil {
+				return err
+			}
+		}
+		client = pc
+		target = "pinecone:" + pineconeIndexHost
 	} else {
 		sc, err := search.NewClient(host, scheme, apiKey, embedder)
 		if err != nil {
 			return fmt.Errorf("weaviate client: %w", err)
 		}
 		client = sc
+		target = "weaviate:" + host
 	}
+	target += "|" + search.EmbeddingCacheNamespace(embedderCfg)
 
 	if err := client.EnsureSchema(ctx); err != nil {
 		return fmt.Errorf("schema init: %w", err)
 	}
 
-	slog.Info("loading business metadata")
-	businessMap, err := data.BusinessMap("")
-	if err != nil {
-		return fmt.Errorf("loading business map: %w", err)
+	// The reviews table carries each review's business metadata; the file
+	// sources take it from the archive's business file.
+	var businessMap map[string]schemas.Business
+	if source.Kind != "postgres" {
+		slog.Info("loading business metadata")
+		businessMap, err = data.BusinessMap(archivePath)
+		if err != nil {
+			return fmt.Errorf("loading business map: %w", err)
+		}
+		slog.Info("business metadata loaded", "count", len(businessMap))
 	}
-	slog.Info("business metadata loaded", "count", len(businessMap))
 
 	// Yelp union step (Postgres only): upsert each distinct Yelp business_id
 	// into restaurants (with yelp_id) to get the surrogate UUID, then map
@@ -142,7 +199,7 @@ func runIndex(cmd *cobra.Command, _ []string) error {
 	// reviews.business_id FK is satisfied. Weaviate/Pinecone store the raw
 	// Yelp string and skip this step.
 	var yelpToUUID map[string]uuid.UUID
-	if postgresSearch && postgresDSN != "" {
+	if postgresSearch && postgresDSN != "" && businessMap != nil {
 		yelpToUUID, err = buildYelpUUIDMap(ctx, postgresDSN, businessMap)
 		if err != nil {
 			return fmt.Errorf("building yelp→uuid map: %w", err)
@@ -150,100 +207,168 @@ func runIndex(cmd *cobra.Command, _ []string) error {
 		slog.Info("yelp union complete", "mapped", len(yelpToUUID))
 	}
 
-	offset := 0
+	hashes, err := openReviewHashes()
+	if err != nil {
+		return err
+	}
+	if hashes != nil {
+		defer func() { _ = hashes.Close() }()
+	}
+
+	position := ""
 	if startOffset > 0 {
-		offset = startOffset
-		slog.Info("starting from explicit offset", "offset", offset)
+		position = "lines:" + strconv.Itoa(startOffset)
+		slog.Info("starting from explicit offset", "offset", startOffset)
 	} else if checkpointPath != "" {
-		offset, err = readCheckpoint(checkpointPath)
+		cp, err := readCheckpoint(checkpointPath)
 		if err != nil {
 			return fmt.Errorf("reading checkpoint: %w", err)
 		}
-		if offset > 0 {
-			slog.Info("resuming from checkpoint", "offset", offset)
+		switch {
+		case cp.Position == "":
+		case cp.Source == source.Name() || (cp.Source == "" && source.Kind == "tar"):
+			position = cp.Position
+			slog.Info("resuming from checkpoint", "source", source.Name(), "position", position)
+		default:
+			slog.Warn("checkpoint is for another source, starting from the beginning", "checkpoint_source", cp.Source, "source", source.Name())
 		}
 	}
 
-	scanner, closer, err := data.GetArchive(archivePath, "review")
+	src, err := source.Open(ctx, position)
 	if err != nil {
-		return fmt.Errorf("opening archive: %w", err)
+		return fmt.Errorf("opening source: %w", err)
 	}
-	defer func() { _ = closer.Close() }()
-	buf := make([]byte, 4*1024*1024)
-	scanner.Buffer(buf, 4*1024*1024)
+	defer func() { _ = src.Close() }()
 
-	// Fast-forward past already-indexed records.
-	for i := 0; i < offset; i++ {
-		if !scanner.Scan() {
-			break
+	slog.Info("source opened, beginning indexing", "source", source.Name())
+
+	// Reviews finish out of order across the pipeline's workers; the
+	// checkpoint only moves past a review once it and every review before
+	// it are indexed, or skipped.
+	tracker := newPositionTracker(position)
+	saveCheckpoint := func() {
+		if checkpointPath == "" {
+			return
+		}
+		if pos, changed := tracker.take(); changed {
+			if werr := writeCheckpoint(checkpointPath, indexCheckpoint{Source: source.Name(), Position: pos}); werr != nil {
+				slog.Warn("checkpoint write failed", "error", werr)
+			}
 		}
 	}
-
-	slog.Info("archive opened, beginning indexing")
+	stopSaving := make(chan struct{})
+	savingDone := make(chan struct{})
+	go func() {
+		defer close(savingDone)
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				saveCheckpoint()
+			case <-stopSaving:
+				return
+			}
+		}
+	}()
 
 	// Deep buffers so the producer stays ahead of the GPU and the GPU stays
 	// ahead of the Weaviate upload workers — minimising idle time at each stage.
-	// Deep buffers so the producer stays ahead of the GPU and Weaviate.
-	rawLineCh := make(chan []byte, numWorkers*batchSize*2)
-	batchCh := make(chan []search.IndexItem, numWorkers*4)
-	vectorizedCh := make(chan []search.IndexItem, numWorkers*4)
+	recordCh := make(chan sourceRecord, numWorkers*batchSize*2)
+	batchCh := make(chan indexBatch, numWorkers*4)
+	vectorizedCh := make(chan indexBatch, numWorkers*4)
 
 	var (
-		parseWg  sync.WaitGroup
-		vecWg    sync.WaitGroup
-		uploadWg sync.WaitGroup
-		total    atomic.Int64
-		firstErr atomic.Pointer[error]
+		parseWg   sync.WaitGroup
+		vecWg     sync.WaitGroup
+		uploadWg  sync.WaitGroup
+		total     atomic.Int64
+		unchanged atomic.Int64
+		firstErr  atomic.Pointer[error]
 	)
 
-	total.Store(int64(offset))
-
 	// Stage 1: Parallel JSON parsing workers
 	for range numWorkers {
 		parseWg.Add(1)
 		go func() {
 			defer parseWg.Done()
-			batch := make([]search.IndexItem, 0, batchSize)
-			for b := range rawLineCh {
+			batch := indexBatch{}
+			// flush drops the reviews already indexed with the same content
+			// and passes the rest on.
+			flush := func() {
+				if len(batch.items) == 0 {
+					return
+				}
+				out := batch
+				batch = indexBatch{}
+				if hashes != nil {
+					kept, skipped, err := out.dropUnchanged(ctx, hashes, target)
+					if err != nil {
+						e := fmt.Errorf("loading review hashes: %w", err)
+						firstErr.CompareAndSwap(nil, &e)
+						return
+					}
+					unchanged.Add(int64(len(skipped)))
+					tracker.finish(skipped...)
+					out = kept
+				}
+				if len(out.items) > 0 {
+					batchCh <- out
+				}
+			}
+			for rec := range recordCh {
 				if firstErr.Load() != nil {
 					continue
 				}
-				var r schemas.Review
-				if err := json.Unmarshal(b, &r); err != nil {
-					slog.Warn("skipping malformed record", "error", err)
-					continue
+				r := rec.Review
+				if r == nil {
+					review, err := data.UnmarshalReview(rec.Line)
+					if err != nil {
+						slog.Warn("skipping malformed record", "error", err)
+						tracker.finish(rec.seq)
+						continue
+					}
+					r = &review
 				}
-				item := search.IndexItem{Review: r}
+				item := search.IndexItem{Review: *r}
 
-				// Apply recursive chunking. Using 800 chars (~150 words) with 100 overlap.
-				chunkTexts := search.ChunkText(r.Text, 800, 100)
+				// Apply recursive chunking.
+				chunkTexts := search.ChunkText(r.Text, chunkSize, chunkOverlap)
 				for _, ct := range chunkTexts {
 					item.Chunks = append(item.Chunks, search.Chunk{Text: ct})
 				}
 
-				if biz, ok := businessMap[r.BusinessID]; ok {
+				biz, ok := businessMap[r.BusinessID]
+				if rec.Business != nil {
+					biz, ok = *rec.Business, true
+				}
+				if ok {
 					item.BusinessName = biz.Name
 					item.City = biz.City
 					item.State = biz.State
 					item.Categories = biz.Categories
 				}
-				if yelpToUUID != nil {
-					if uid, ok := yelpToUUID[r.BusinessID]; ok {
-						item.BusinessUUID = &uid
-					}
+				if uid, ok := yelpToUUID[r.BusinessID]; ok {
+					item.BusinessUUID = &uid
+				} else if uid, err := uuid.Parse(r.BusinessID); postgresSearch && err == nil {
+					// Reviews read back from Postgres already reference
+					// restaurants.id.
+					item.BusinessUUID = &uid
 				}
 				if filterCity != "" && !strings.EqualFold(filterCity, item.City) {
+					tracker.finish(rec.seq)
 					continue
 				}
-				batch = append(batch, item)
-				if len(batch) >= batchSize {
-					batchCh <- append([]search.IndexItem(nil), batch...)
-					batch = batch[:0]
+				batch.items = append(batch.items, item)
+				batch.seqs = append(batch.seqs, rec.seq)
+				if hashes != nil {
+					batch.hashes = append(batch.hashes, search.ReviewContentHash(item, chunkSize, chunkOverlap))
+				}
+				if len(batch.items) >= batchSize {
+					flush()
 				}
 			}
-			if len(batch) > 0 {
-				batchCh <- append([]search.IndexItem(nil), batch...)
-			}
+			flush()
 		}()
 	}
 	go func() {
@@ -265,10 +390,11 @@ func runIndex(cmd *cobra.Command, _ []string) error {
 		vecWg.Add(1)
 		go func() {
 			defer vecWg.Done()
-			for batch := range batchCh {
+			for ib := range batchCh {
 				if firstErr.Load() != nil {
 					continue
 				}
+				batch := ib.items
 				if vectorizerHost != "" || (ollamaURL != "" && ollamaModel != "") {
 					var err error
 					for attempt := 0; attempt < 5; attempt++ {
@@ -288,6 +414,7 @@ func runIndex(cmd *cobra.Command, _ []string) error {
 						// timeouts or exceeding payload limits in the vectorizer backend.
 						const subBatchLimit = 100
 						var allVecs [][]float32
+						var allSpaces []int64
 						var subBatchErr error
 
 						for i := 0; i < len(allTexts); i += subBatchLimit {
@@ -295,12 +422,15 @@ func runIndex(cmd *cobra.Command, _ []string) error {
 							if end > len(allTexts) {
 								end = len(allTexts)
 							}
-							subVecs, e := embedder.EmbedBatch(ctx, allTexts[i:end])
+							subVecs, space, e := search.EmbedBatchInSpace(ctx, docEmbedder, allTexts[i:end])
 							if e != nil {
 								subBatchErr = e
 								break
 							}
 							allVecs = append(allVecs, subVecs...)
+							for range subVecs {
+								allSpaces = append(allSpaces, space)
+							}
 						}
 
 						if subBatchErr == nil {
@@ -310,12 +440,15 @@ func runIndex(cmd *cobra.Command, _ []string) error {
 								if len(item.Chunks) > 0 {
 									for j := range item.Chunks {
 										batch[i].Chunks[j].Vector = allVecs[vecIndex]
+										batch[i].Chunks[j].EmbeddingSpace = allSpaces[vecIndex]
 										vecIndex++
 									}
 									// Set parent vector to the first chunk's vector for compatibility
 									batch[i].Vector = allVecs[vecIndex-len(item.Chunks)]
+									batch[i].EmbeddingSpace = allSpaces[vecIndex-len(item.Chunks)]
 								} else {
 									batch[i].Vector = allVecs[vecIndex]
+									batch[i].EmbeddingSpace = allSpaces[vecIndex]
 									vecIndex++
 								}
 							}
@@ -332,7 +465,7 @@ func runIndex(cmd *cobra.Command, _ []string) error {
 						continue
 					}
 				}
-				vectorizedCh <- batch
+				vectorizedCh <- ib
 			}
 		}()
 	}
@@ -352,7 +485,7 @@ func runIndex(cmd *cobra.Command, _ []string) error {
 				}
 				var err error
 				for attempt := 0; attempt < 5; attempt++ {
-					err = client.BatchUpsert(ctx, batch)
+					err = client.BatchUpsert(ctx, batch.items)
 					if err == nil {
 						break
 					}
@@ -364,85 +497,117 @@ func runIndex(cmd *cobra.Command, _ []string) error {
 					firstErr.CompareAndSwap(nil, &e)
 					continue
 				}
-				n := total.Add(int64(len(batch)))
-				slog.Info("indexed batch", "total", n)
-
-				if checkpointPath != "" {
-					// Rewind the checkpoint behind n to cover batches still in-flight across
-					// the three pipeline stages (rawLineCh, batchCh, vectorizedCh) plus active
-					// workers. Upserts are idempotent (deterministic SHA1 UUIDs), so re-processing
-					// these items on restart is safe — this only trades a small amount of
-					// duplicate work for a guaranteed-consistent restart position.
-					safeOffset := n - int64(8*numWorkers*batchSize)
-					if safeOffset < 0 {
-						safeOffset = 0
-					}
-					if werr := writeCheckpoint(checkpointPath, safeOffset); werr != nil {
-						slog.Warn("checkpoint write failed", "error", werr)
+				if hashes != nil {
+					// The upsert is idempotent, so a failure here only costs
+					// re-indexing the batch next run.
+					if err := hashes.StoreReviewHashes(ctx, target, batch.hashMap()); err != nil {
+						slog.Warn("storing review hashes failed", "error", err)
 					}
 				}
+				tracker.finish(batch.seqs...)
+				n := total.Add(int64(len(batch.items)))
+				slog.Info("indexed batch", "total", n)
 			}
 		}()
 	}
 
-	// Stage 0: Main thread reads raw lines from archive
-	for scanner.Scan() {
-		if firstErr.Load() != nil {
+	// Stage 0: Main thread reads reviews from the source
+	var readErr error
+	for firstErr.Load() == nil {
+		rec, err := src.Next()
+		if errors.Is(err, io.EOF) {
 			break
 		}
-		b := make([]byte, len(scanner.Bytes()))
-		copy(b, scanner.Bytes())
-		rawLineCh <- b
+		if err != nil {
+			readErr = fmt.Errorf("reading %s: %w", source.Name(), err)
+			break
+		}
+		recordCh <- sourceRecord{SourceRecord: rec, seq: tracker.add(rec.Position)}
 	}
-	close(rawLineCh)
+	close(recordCh)
 	uploadWg.Wait()
-
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("scanner error: %w", err)
+	close(stopSaving)
+	<-savingDone
+	// Whatever stopped the run, every review before the saved position is
+	// indexed.
+	saveCheckpoint()
+
+	if readErr != nil {
+		return readErr
 	}
 
 	if p := firstErr.Load(); p != nil {
 		return *p
 	}
 
-	slog.Info("indexing complete", "total_reviews", total.Load())
+	slog.Info("indexing complete", "indexed_reviews", total.Load(), "unchanged_reviews", unchanged.Load())
 	return nil
 }
 
-// readCheckpoint returns the number of reviews previously indexed from path.
-// Returns 0 if the file does not exist.
-func readCheckpoint(path string) (int, error) {
-	b, err := os.ReadFile(path)
-	if errors.Is(err, os.ErrNotExist) {
-		return 0, nil
+// Chunking parameters of indexed reviews: 800 chars (~150 words) with 100
+// overlap. They are part of each review's content hash.
+const (
+	chunkSize    = 800
+	chunkOverlap = 100
+)
+
+// sourceRecord is a review read from the source, numbered for the
+// positionTracker.
+type sourceRecord struct {
+	data.SourceRecord
+	seq int64
+}
+
+// indexBatch is a batch of reviews on their way through the pipeline, with
+// their tracker sequence numbers and, when hashing is on, content hashes.
+type indexBatch struct {
+	items  []search.IndexItem
+	seqs   []int64
+	hashes []string
+}
+
+// dropUnchanged splits the batch into the reviews whose content differs
+// from the hash stored for target, or has none stored, and the sequence
+// numbers of the rest.
+func (b indexBatch) dropUnchanged(ctx context.Context, store search.ReviewHashStore, target string) (indexBatch, []int64, error) {
+	ids := make([]string, len(b.items))
+	for i, it := range b.items {
+		ids[i] = it.Review.ReviewID
 	}
+	stored, err := store.LoadReviewHashes(ctx, target, ids)
 	if err != nil {
-		return 0, fmt.Errorf("read checkpoint: %w", err)
+		return indexBatch{}, nil, err
 	}
-	n, err := strconv.Atoi(string(b))
-	if err != nil {
-		return 0, fmt.Errorf("parse checkpoint: %w", err)
+	var kept indexBatch
+	var skipped []int64
+	for i, it := range b.items {
+		if stored[it.Review.ReviewID] == b.hashes[i] {
+			skipped = append(skipped, b.seqs[i])
+			continue
+		}
+		kept.items = append(kept.items, it)
+		kept.seqs = append(kept.seqs, b.seqs[i])
+		kept.hashes = append(kept.hashes, b.hashes[i])
 	}
-	return n, nil
+	return kept, skipped, nil
 }
 
-// writeCheckpoint atomically persists n to path via a temp file and rename.
-func writeCheckpoint(path string, n int64) error {
-	tmp := path + ".tmp"
-	if err := os.WriteFile(tmp, []byte(strconv.FormatInt(n, 10)), 0o644); err != nil {
-		return fmt.Errorf("write checkpoint tmp: %w", err)
-	}
-	if err := os.Rename(tmp, path); err != nil {
-		return fmt.Errorf("rename checkpoint: %w", err)
+// hashMap returns the batch's content hashes by review ID.
+func (b indexBatch) hashMap() map[string]string {
+	m := make(map[string]string, len(b.hashes))
+	for i, h := range b.hashes {
+		m[b.items[i].Review.ReviewID] = h
 	}
-	return nil
+	return m
 }
 
 // buildYelpUUIDMap upserts every Yelp business into the restaurants table
 // (keyed by yelp_id) and returns a map from Yelp business_id string to the
 // surrogate restaurants.id UUID. This is the union step that lets
 // reviews.business_id (UUID FK) reference restaurants.id even though Yelp
-// reviews carry the Yelp string ID, not a UUID.
+// reviews carry the Yelp string ID, not a UUID. Businesses already merged
+// into another source's restaurant by `restaurants import --source yelp`
+// resolve to that row through its yelp_id.
 func buildYelpUUIDMap(ctx context.Context, dsn string, businessMap map[string]schemas.Business) (map[string]uuid.UUID, error) {
 	pool, err := pgxpool.New(ctx, dsn)
 	if err != nil {
@@ -0,0 +1,212 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"fodmap/data"
+	"fodmap/search"
+
+	"github.com/spf13/viper"
+)
+
+// indexCheckpoint is what `index` persists between runs: the source it read
+// and the position after the last review every earlier one of which has
+// been indexed (or skipped).
+type indexCheckpoint struct {
+	Source   string `json:"source"`
+	Position string `json:"position"`
+}
+
+// readCheckpoint returns the checkpoint saved at path, or a zero one if the
+// file does not exist. A bare line count, written before checkpoints were
+// keyed by position, reads as a "lines:N" position with no source.
+func readCheckpoint(path string) (indexCheckpoint, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return indexCheckpoint{}, nil
+	}
+	if err != nil {
+		return indexCheckpoint{}, fmt.Errorf("read checkpoint: %w", err)
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(string(b))); err == nil {
+		return indexCheckpoint{Position: "lines:" + strconv.Itoa(n)}, nil
+	}
+	var cp indexCheckpoint
+	if err := json.Unmarshal(b, &cp); err != nil {
+		return indexCheckpoint{}, fmt.Errorf("parse checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+// writeCheckpoint atomically persists cp to path via a temp file and rename.
+func writeCheckpoint(path string, cp indexCheckpoint) error {
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("encode checkpoint: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return fmt.Errorf("write checkpoint tmp: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("rename checkpoint: %w", err)
+	}
+	return nil
+}
+
+// reviewSourceSpec is a parsed --source value.
+type reviewSourceSpec struct {
+	Kind string // tar, avro, jsonl or postgres
+	Path string // file or directory; the DSN for postgres
+}
+
+// parseReviewSource parses a --source value: tar:<archive>, avro:<file or
+// dir>, jsonl:<file or dir>, or postgres[:<dsn>]. An empty value is the
+// archive at archivePath, and a bare postgres takes --postgres-dsn.
+func parseReviewSource(spec, archivePath string) (reviewSourceSpec, error) {
+	if spec == "" {
+		return reviewSourceSpec{Kind: "tar", Path: archivePath}, nil
+	}
+	if strings.HasPrefix(spec, "postgres://") || strings.HasPrefix(spec, "postgresql://") {
+		return reviewSourceSpec{Kind: "postgres", Path: spec}, nil
+	}
+	kind, path, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "tar", "avro", "jsonl":
+		if path == "" {
+			return reviewSourceSpec{}, fmt.Errorf("--source %s needs a path, e.g. %s:reviews/", kind, kind)
+		}
+	case "postgres":
+		if path == "" {
+			path = viper.GetString("postgres-dsn")
+		}
+		if path == "" {
+			path = viper.GetString("POSTGRES_DSN")
+		}
+		if path == "" {
+			return reviewSourceSpec{}, fmt.Errorf("--source postgres requires --postgres-dsn")
+		}
+	default:
+		return reviewSourceSpec{}, fmt.Errorf("unknown --source %q: want tar:<archive>, avro:<path>, jsonl:<path> or postgres[:<dsn>]", spec)
+	}
+	return reviewSourceSpec{Kind: kind, Path: path}, nil
+}
+
+// Name identifies the source in checkpoints. The reviews table is named
+// without its DSN, which may carry a password.
+func (s reviewSourceSpec) Name() string {
+	if s.Kind == "postgres" {
+		return "postgres"
+	}
+	return s.Kind + ":" + s.Path
+}
+
+// Open opens the source, resuming at position.
+func (s reviewSourceSpec) Open(ctx context.Context, position string) (data.ReviewSource, error) {
+	switch s.Kind {
+	case "tar":
+		return data.OpenTarReviews(s.Path, position)
+	case "avro":
+		return data.OpenAvroReviews(s.Path, position)
+	case "jsonl":
+		return data.OpenJSONLReviews(s.Path, position)
+	default:
+		return search.NewPostgresReviewSource(ctx, s.Path, position)
+	}
+}
+
+// openReviewHashes opens the store --review-hashes selects: postgres (the
+// review_hashes table at --postgres-dsn) or a directory. It returns nil
+// when hashing is off.
+func openReviewHashes() (search.ReviewHashStore, error) {
+	where := viper.GetString("review-hashes")
+	switch where {
+	case "":
+		return nil, nil
+	case "postgres":
+		dsn := viper.GetString("postgres-dsn")
+		if dsn == "" {
+			dsn = viper.GetString("POSTGRES_DSN")
+		}
+		if dsn == "" {
+			return nil, fmt.Errorf("--review-hashes=postgres requires --postgres-dsn")
+		}
+		s, err := search.NewPostgresReviewHashes(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("review hashes: %w", err)
+		}
+		return s, nil
+	default:
+		s, err := search.NewLocalReviewHashes(where)
+		if err != nil {
+			return nil, fmt.Errorf("review hashes: %w", err)
+		}
+		return s, nil
+	}
+}
+
+// positionTracker follows reviews through the concurrent index pipeline,
+// which finishes them out of order, and yields the source position after
+// the longest run of reviews that are all finished: the point a restart can
+// resume from without losing any.
+type positionTracker struct {
+	mu        sync.Mutex
+	positions map[int64]string // position after each unfinished review, by sequence number
+	finished  map[int64]bool   // finished reviews past the run
+	next      int64            // sequence number the next review is given
+	low       int64            // first unfinished sequence number
+	position  string           // position after the run
+	changed   bool             // position moved since the last take
+}
+
+func newPositionTracker(position string) *positionTracker {
+	return &positionTracker{
+		positions: make(map[int64]string),
+		finished:  make(map[int64]bool),
+		position:  position,
+	}
+}
+
+// add registers the review read before position and returns its sequence
+// number.
+func (t *positionTracker) add(position string) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	seq := t.next
+	t.next++
+	t.positions[seq] = position
+	return seq
+}
+
+// finish marks reviews indexed, or skipped.
+func (t *positionTracker) finish(seqs ...int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, seq := range seqs {
+		t.finished[seq] = true
+	}
+	for t.finished[t.low] {
+		t.position = t.positions[t.low]
+		delete(t.finished, t.low)
+		delete(t.positions, t.low)
+		t.low++
+		t.changed = true
+	}
+}
+
+// take returns the resumable position and whether it moved since the last
+// call.
+func (t *positionTracker) take() (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	changed := t.changed
+	t.changed = false
+	return t.position, changed
+}
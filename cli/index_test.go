@@ -15,27 +15,37 @@ func TestCheckpointing(t *testing.T) {
 	tmpDir := t.TempDir()
 	path := filepath.Join(tmpDir, "test.checkpoint")
 
-	// Read missing file returns 0
-	n, err := readCheckpoint(path)
+	// Read missing file returns the zero checkpoint
+	cp, err := readCheckpoint(path)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if n != 0 {
-		t.Errorf("expected 0, got %d", n)
+	if cp != (indexCheckpoint{}) {
+		t.Errorf("expected zero checkpoint, got %+v", cp)
 	}
 
-	// Write 42
-	if err := writeCheckpoint(path, 42); err != nil {
+	want := indexCheckpoint{Source: "jsonl:deltas", Position: "2024-06-03.jsonl#4096"}
+	if err := writeCheckpoint(path, want); err != nil {
 		t.Fatalf("unexpected write error: %v", err)
 	}
+	cp, err = readCheckpoint(path)
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	if cp != want {
+		t.Errorf("expected %+v, got %+v", want, cp)
+	}
 
-	// Read back 42
-	n, err = readCheckpoint(path)
+	// A line count from before positions reads as a tar position
+	if err := os.WriteFile(path, []byte("42"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	cp, err = readCheckpoint(path)
 	if err != nil {
 		t.Fatalf("unexpected read error: %v", err)
 	}
-	if n != 42 {
-		t.Errorf("expected 42, got %d", n)
+	if cp != (indexCheckpoint{Position: "lines:42"}) {
+		t.Errorf("expected lines:42, got %+v", cp)
 	}
 }
 
@@ -52,6 +62,53 @@ func TestReadCheckpoint_Corrupt(t *testing.T) {
 	}
 }
 
+func TestPositionTracker(t *testing.T) {
+	tr := newPositionTracker("start")
+	a, b, c := tr.add("p1"), tr.add("p2"), tr.add("p3")
+
+	tr.finish(b, c)
+	if pos, changed := tr.take(); pos != "start" || changed {
+		t.Errorf("before the first review finishes: %q, %v; want start, false", pos, changed)
+	}
+	tr.finish(a)
+	if pos, changed := tr.take(); pos != "p3" || !changed {
+		t.Errorf("after all finish: %q, %v; want p3, true", pos, changed)
+	}
+	if _, changed := tr.take(); changed {
+		t.Error("take reported a change twice")
+	}
+}
+
+func TestParseReviewSource(t *testing.T) {
+	tests := []struct {
+		spec     string
+		wantKind string
+		wantPath string
+		wantErr  bool
+	}{
+		{"", "tar", "yelp.tar", false},
+		{"avro:events/", "avro", "events/", false},
+		{"jsonl:deltas", "jsonl", "deltas", false},
+		{"postgres:host=db user=x", "postgres", "host=db user=x", false},
+		{"postgres://u@db/fodmap", "postgres", "postgres://u@db/fodmap", false},
+		{"jsonl:", "", "", true},
+		{"parquet:x", "", "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseReviewSource(tt.spec, "yelp.tar")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: err = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if got.Kind != tt.wantKind || got.Path != tt.wantPath {
+			t.Errorf("%q: got %+v, want %s %s", tt.spec, got, tt.wantKind, tt.wantPath)
+		}
+	}
+	if got, _ := parseReviewSource("postgres://u:secret@db/fodmap", ""); got.Name() != "postgres" {
+		t.Errorf("Name = %q, want the DSN left out", got.Name())
+	}
+}
+
 // TestBuildYelpUUIDMap_Integration exercises buildYelpUUIDMap against a live
 // Postgres: upserts Yelp businesses, verifies the returned map maps Yelp string
 // IDs to valid UUIDs, and confirms a second call is idempotent. Skipped unless
@@ -11,13 +11,16 @@ import (
 	"time"
 
 	"fodmap/chat"
+	"fodmap/egress"
 	"fodmap/menusearch"
 	"fodmap/menutracking"
 	menutrackingstore "fodmap/menutracking/store"
+	"fodmap/pipeline"
 	"fodmap/scraper"
 	"fodmap/search"
 	"fodmap/server"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/riverqueue/river"
 	"github.com/riverqueue/river/rivertype"
@@ -99,6 +102,8 @@ func runMenutrackingAddSource(cmd *cobra.Command, args []string) error {
 type PipelineConfig struct {
 	DSN                       string
 	Fetcher                   scraper.Fetcher
+	SourceFetcher             scraper.Fetcher // regulatory sources; ignores robots.txt. Defaults to Fetcher
+	Egress                    *egress.Pool // optional; shared by discovery and directory probes
 	VectorSink                menutracking.VectorSink
 	ChatBackend               chat.ChatBackend
 	MenuStore                 server.MenuStore
@@ -115,6 +120,17 @@ type PipelineConfig struct {
 	WebagentAdapter           string
 	BronzeDir                 string
 	ScrapeMaxAttempts         int
+	RuleMinPassRate           *float64 // nil uses menutracking.DefaultMinPassRate; 0 turns the gate off
+	// RefreshInterval is how often the menu refresh policy runs; 0 disables
+	// scheduled re-scrapes.
+	RefreshInterval time.Duration
+	RefreshPolicy   menusearch.RefreshPolicy
+	// Quality scores every extraction and quarantines results below its
+	// threshold; nil stores every extraction unchecked.
+	Quality *pipeline.QualityPolicy
+	// Translator adds English translations to non-English menus before they
+	// are stored; nil stores menus in their original language only.
+	Translator scraper.MenuTranslator
 }
 
 // PipelineResult holds the running pipeline's stop function and references
@@ -137,14 +153,12 @@ func (h *deadLetterHandler) handleFinalScrapeFailure(ctx context.Context, job *r
 	if h.restaurantStore == nil || job.Kind != "menusearch.scrape_menu" || job.Attempt < job.MaxAttempts {
 		return
 	}
-	var args struct {
-		CAMIS string `json:"camis"`
-	}
-	if jsonErr := json.Unmarshal(job.EncodedArgs, &args); jsonErr != nil || args.CAMIS == "" {
+	var args menusearch.ScrapeMenuArgs
+	if jsonErr := json.Unmarshal(job.EncodedArgs, &args); jsonErr != nil || args.RestaurantID == uuid.Nil {
 		return
 	}
-	if storeErr := h.restaurantStore.UpdateScrapeResult(ctx, args.CAMIS, menusearch.StatusFailedScrape, 0, errMsg); storeErr != nil {
-		slog.Warn("menutracking: failed to update scrape status on discard", "camis", args.CAMIS, "err", storeErr)
+	if storeErr := h.restaurantStore.UpdateScrapeResult(ctx, args.RestaurantID, menusearch.StatusFailedScrape, 0, errMsg); storeErr != nil {
+		slog.Warn("menutracking: failed to update scrape status on discard", "restaurant_id", args.RestaurantID, "err", storeErr)
 	}
 }
 
@@ -184,32 +198,31 @@ func StartMenutrackingPipeline(ctx context.Context, cfg PipelineConfig) (*Pipeli
 		return nil, fmt.Errorf("pinging postgres: %w", err)
 	}
 
-	// Load sources and seed rate limiters.
+	// Load sources. Per-host politeness is enforced by cfg.Fetcher's crawl
+	// policy, shared by every worker below.
 	sources, err := menutracking.ListSources(ctx, pool)
 	if err != nil {
 		pool.Close()
 		return nil, fmt.Errorf("loading sources: %w", err)
 	}
-	domains := make([]string, len(sources))
-	for i, s := range sources {
-		domains[i] = s.Domain
-	}
-	limiterMap := menutracking.NewDomainLimiterMap(1, 1) // 1 request/sec, burst 1
-	limiterMap.Seed(domains)
 
 	// Build river workers.
+	sourceFetcher := cfg.SourceFetcher
+	if sourceFetcher == nil {
+		sourceFetcher = cfg.Fetcher
+	}
 	workers := river.NewWorkers()
 	promotionWorker := &menutracking.RulePromotionWorker{
-		Pool:    pool,
-		Fetcher: cfg.Fetcher,
+		Pool:        pool,
+		Fetcher:     sourceFetcher,
+		MinPassRate: cfg.RuleMinPassRate,
 	}
 	scrapeWorker := &menutracking.ScrapeWorker{
-		Pool:         pool,
-		Fetcher:      cfg.Fetcher,
-		RateLimiters: limiterMap,
-		AgentConfig:  menutracking.DefaultAgentPathConfig(),
-		VectorSink:   cfg.VectorSink,
-		ChatBackend:  cfg.ChatBackend,
+		Pool:        pool,
+		Fetcher:     sourceFetcher,
+		AgentConfig: menutracking.DefaultAgentPathConfig(),
+		VectorSink:  cfg.VectorSink,
+		ChatBackend: cfg.ChatBackend,
 	}
 	river.AddWorker(workers, scrapeWorker)
 	river.AddWorker(workers, promotionWorker)
@@ -222,12 +235,7 @@ func StartMenutrackingPipeline(ctx context.Context, cfg PipelineConfig) (*Pipeli
 		ScrapeStaggerSeconds: cfg.DiscoveryStaggerSeconds,
 		MaxNoURLAttempts:     cfg.DiscoveryMaxNoURLAttempts,
 		MaxAttempts:          cfg.ScrapeMaxAttempts,
-		HTTPClient: &http.Client{
-			Timeout: 10 * time.Second,
-			CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
-				return http.ErrUseLastResponse
-			},
-		},
+		HTTPClient:           discoveryClient(cfg.Egress),
 	}
 	discoverEnabled := cfg.GenAIClient != nil
 	if discoverEnabled {
@@ -253,6 +261,9 @@ func StartMenutrackingPipeline(ctx context.Context, cfg PipelineConfig) (*Pipeli
 		UsePdftotext:    cfg.UsePdftotext,
 		WebagentAdapter: cfg.WebagentAdapter,
 		BronzeDir:       cfg.BronzeDir,
+		Egress:          cfg.Egress,
+		Quality:         cfg.Quality,
+		Translator:      cfg.Translator,
 	}
 	scrapeEnabled := cfg.MenuStore != nil && cfg.Embedder != nil && cfg.Extractor != nil
 	if scrapeEnabled {
@@ -264,8 +275,56 @@ func StartMenutrackingPipeline(ctx context.Context, cfg PipelineConfig) (*Pipeli
 			"has_extractor", cfg.Extractor != nil)
 	}
 
+	// Approved menu reviews are stored the way scrapes are, so the apply
+	// worker needs the same menu store and embedder.
+	applyReviewEnabled := cfg.MenuStore != nil && cfg.Embedder != nil
+	if applyReviewEnabled {
+		river.AddWorker(workers, &menusearch.ApplyMenuReviewWorker{
+			Store:      menusearch.NewStore(pool),
+			MenuStore:  cfg.MenuStore,
+			Embedder:   cfg.Embedder,
+			Translator: cfg.Translator,
+		})
+	}
+
+	// Embedding model migrations: fill a new embedding space in the
+	// background while the active one serves, then cut over.
+	spaceClient, err := search.NewPostgresClient(cfg.DSN, cfg.Embedder)
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("connecting embedding space client: %w", err)
+	}
+	river.AddWorker(workers, &search.ReembedWorker{Client: spaceClient})
+
 	// Build periodic jobs from sources.
 	var periodicJobs []*river.PeriodicJob
+
+	// Scheduled menu re-scrapes. The refresh worker queues scrape jobs, so it
+	// only runs when the scrape worker does.
+	var refreshWorker *menusearch.RefreshMenusWorker
+	if scrapeEnabled && cfg.RefreshInterval > 0 {
+		refreshStore := menusearch.NewStore(pool)
+		refreshStore.SetRiverSchema(riverSchemaName())
+		refreshWorker = &menusearch.RefreshMenusWorker{
+			Store:                refreshStore,
+			Policy:               cfg.RefreshPolicy,
+			MaxAttempts:          cfg.ScrapeMaxAttempts,
+			ScrapeStaggerSeconds: cfg.DiscoveryStaggerSeconds,
+		}
+		river.AddWorker(workers, refreshWorker)
+		periodicJobs = append(periodicJobs, river.NewPeriodicJob(
+			river.PeriodicInterval(cfg.RefreshInterval),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return menusearch.RefreshMenusArgs{}, &river.InsertOpts{
+					MaxAttempts: 1,
+					UniqueOpts:  river.UniqueOpts{ByPeriod: cfg.RefreshInterval},
+				}
+			},
+			&river.PeriodicJobOpts{RunOnStart: true},
+		))
+		slog.Info("menusearch: scheduled menu refresh enabled",
+			"interval", cfg.RefreshInterval, "daily_budget", cfg.RefreshPolicy.DailyBudget, "max_per_run", cfg.RefreshPolicy.MaxPerRun)
+	}
 	for _, s := range sources {
 		schedule, err := parseCronSchedule(s.CronSchedule)
 		if err != nil {
@@ -297,20 +356,26 @@ func StartMenutrackingPipeline(ctx context.Context, cfg PipelineConfig) (*Pipeli
 		ErrorHandler:                &deadLetterHandler{pool: pool, restaurantStore: menusearch.NewStore(pool)},
 	})
 	if err != nil {
+		_ = spaceClient.Close()
 		pool.Close()
 		return nil, fmt.Errorf("creating river client: %w", err)
 	}
 	scrapeWorker.RiverClient = riverClient
 	discoverWorker.RiverClient = riverClient
+	if refreshWorker != nil {
+		refreshWorker.RiverClient = riverClient
+	}
 
 	jobQueue := &menusearch.JobQueue{
-		Client:          riverClient,
-		MaxAttempts:     cfg.ScrapeMaxAttempts,
-		DiscoverEnabled: discoverEnabled,
-		ScrapeEnabled:   scrapeEnabled,
+		Client:             riverClient,
+		MaxAttempts:        cfg.ScrapeMaxAttempts,
+		DiscoverEnabled:    discoverEnabled,
+		ScrapeEnabled:      scrapeEnabled,
+		ApplyReviewEnabled: applyReviewEnabled,
 	}
 
 	if err := riverClient.Start(ctx); err != nil {
+		_ = spaceClient.Close()
 		pool.Close()
 		return nil, fmt.Errorf("starting river client: %w", err)
 	}
@@ -323,6 +388,7 @@ func StartMenutrackingPipeline(ctx context.Context, cfg PipelineConfig) (*Pipeli
 		if err := riverClient.Stop(stopCtx); err != nil {
 			return fmt.Errorf("stopping river client: %w", err)
 		}
+		_ = spaceClient.Close()
 		pool.Close()
 		return nil
 	}
@@ -356,3 +422,16 @@ func parseCronSchedule(cronExpr string) (river.PeriodicSchedule, error) {
 		return nil, fmt.Errorf("%w: unsupported schedule %q: only @daily, @hourly, @weekly supported in phase 1", ErrInvalidCron, cronExpr)
 	}
 }
+
+// discoveryClient returns the HTTP client discovery uses to probe candidate
+// menu URLs. Redirects are not followed so probes see the original status.
+func discoveryClient(pool *egress.Pool) *http.Client {
+	client := &http.Client{Timeout: 10 * time.Second}
+	if pool != nil {
+		client = pool.Client(10 * time.Second)
+	}
+	client.CheckRedirect = func(_ *http.Request, _ []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return client
+}
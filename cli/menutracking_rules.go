@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"fodmap/menutracking"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var menutrackingTestRuleCmd = &cobra.Command{
+	Use:   "test-rule [rule-id]",
+	Short: "Replay an extraction rule against every stored bronze snapshot for its domain.",
+	Long: `Replay an extraction rule against every bronze snapshot stored for its
+domain and report pass/fail/changed per snapshot. Pass a rule ID to test a
+specific (e.g. proposed) rule, or --domain to test the domain's active rule.
+When testing a non-active rule, the domain's active rule is used as the
+baseline for detecting changed output. Exits non-zero if the pass rate is
+below --min-pass-rate.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMenutrackingTestRule,
+}
+
+func init() {
+	menutrackingCmd.AddCommand(menutrackingTestRuleCmd)
+
+	menutrackingTestRuleCmd.Flags().String("postgres-dsn", "", "PostgreSQL DSN (or POSTGRES_DSN env)")
+	menutrackingTestRuleCmd.Flags().String("domain", "", "Test the active rule for this domain instead of a rule ID")
+	menutrackingTestRuleCmd.Flags().String("bronze-dir", menutracking.BronzeDir, "Root directory of menutracking bronze snapshots")
+	menutrackingTestRuleCmd.Flags().Float64("min-pass-rate", menutracking.DefaultMinPassRate, "Fraction of snapshots the rule must extract from")
+}
+
+func runMenutrackingTestRule(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	domain, _ := cmd.Flags().GetString("domain")
+	bronzeDir, _ := cmd.Flags().GetString("bronze-dir")
+	minPassRate, _ := cmd.Flags().GetFloat64("min-pass-rate")
+	if (len(args) == 0) == (domain == "") {
+		return errors.New("exactly one of <rule-id> or --domain is required")
+	}
+
+	dsn := viper.GetString("postgres-dsn")
+	if dsn == "" {
+		return fmt.Errorf("postgres-dsn is required (set via --postgres-dsn or POSTGRES_DSN env)")
+	}
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return fmt.Errorf("connecting to postgres: %w", err)
+	}
+	defer pool.Close()
+
+	var rule *menutracking.ExtractionRule
+	if domain != "" {
+		rule, err = menutracking.ActiveRule(ctx, pool, domain)
+		if err != nil {
+			return err
+		}
+		if rule == nil {
+			return fmt.Errorf("no active rule for domain %s", domain)
+		}
+	} else {
+		rule, err = menutracking.RuleByID(ctx, pool, args[0])
+		if err != nil {
+			return err
+		}
+		if rule == nil {
+			return fmt.Errorf("rule %s not found", args[0])
+		}
+	}
+
+	var baseline *menutracking.ExtractionRule
+	if rule.Status != menutracking.RuleStatusActive {
+		baseline, err = menutracking.ActiveRule(ctx, pool, rule.Domain)
+		if err != nil {
+			return err
+		}
+	}
+
+	report, err := menutracking.ReplayRule(ctx, rule, baseline, bronzeDir)
+	if err != nil {
+		return fmt.Errorf("replaying rule: %w", err)
+	}
+	printRuleTestReport(cmd, report)
+	return report.Check(minPassRate)
+}
+
+// printRuleTestReport writes one line per snapshot followed by a summary.
+func printRuleTestReport(cmd *cobra.Command, report *menutracking.RuleTestReport) {
+	out := cmd.OutOrStdout()
+	for _, r := range report.Results {
+		_, _ = fmt.Fprintf(out, "%-7s %s\n", r.Outcome, r.Path)
+		if r.Outcome == menutracking.SnapshotChanged {
+			_, _ = fmt.Fprintf(out, "        baseline: %s\n        rule:     %s\n", r.Baseline, r.Output)
+		}
+	}
+	_, _ = fmt.Fprintf(out, "rule %s (%s): %d snapshots, %d passed, %d changed, %d failed, pass rate %.2f\n",
+		report.RuleID, report.Domain, report.Total(), report.Passed, report.Changed, report.Failed, report.PassRate())
+}
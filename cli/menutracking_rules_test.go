@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"fodmap/menutracking"
+
+	"github.com/spf13/cobra"
+)
+
+// newTestRuleTestCmd builds a command with the flags init() registers on
+// menutrackingTestRuleCmd so runMenutrackingTestRule can run in isolation.
+func newTestRuleTestCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "test-rule"}
+	cmd.SetContext(context.Background())
+	cmd.Flags().String("domain", "", "")
+	cmd.Flags().String("bronze-dir", menutracking.BronzeDir, "")
+	cmd.Flags().Float64("min-pass-rate", menutracking.DefaultMinPassRate, "")
+	return cmd
+}
+
+func TestRunMenutrackingTestRuleRequiresExactlyOneTarget(t *testing.T) {
+	cmd := newTestRuleTestCmd()
+	if err := runMenutrackingTestRule(cmd, nil); err == nil {
+		t.Fatal("expected error with neither rule ID nor --domain, got nil")
+	}
+
+	cmd = newTestRuleTestCmd()
+	if err := cmd.Flags().Set("domain", "gov.example"); err != nil {
+		t.Fatalf("setting domain flag: %v", err)
+	}
+	if err := runMenutrackingTestRule(cmd, []string{"rule-1"}); err == nil {
+		t.Fatal("expected error with both rule ID and --domain, got nil")
+	}
+}
+
+func TestPrintRuleTestReport(t *testing.T) {
+	report := &menutracking.RuleTestReport{
+		RuleID: "rule-1",
+		Domain: "gov.example",
+		Results: []menutracking.SnapshotResult{
+			{Path: "a.html", Outcome: menutracking.SnapshotPass},
+			{Path: "b.html", Outcome: menutracking.SnapshotChanged, Output: `{"new":1}`, Baseline: `{"old":1}`},
+			{Path: "c.html", Outcome: menutracking.SnapshotFail},
+		},
+		Passed:  1,
+		Changed: 1,
+		Failed:  1,
+	}
+	var buf bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&buf)
+
+	printRuleTestReport(cmd, report)
+
+	out := buf.String()
+	for _, want := range []string{"pass    a.html", "changed b.html", `baseline: {"old":1}`, "fail    c.html", "3 snapshots, 1 passed, 1 changed, 1 failed, pass rate 0.67"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -6,8 +6,12 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
+	"fodmap/directory"
+	"fodmap/geo"
 	"fodmap/menusearch"
 	"fodmap/pipeline"
 	"fodmap/scraper"
@@ -40,10 +44,27 @@ func init() {
 
 	importCmd := &cobra.Command{
 		Use:   "import",
-		Short: "Import restaurants from NYC OpenData",
-		RunE:  runImportRestaurants,
-	}
-	importCmd.Flags().String("area", "", "NTA or ZIP to import (e.g. astoria-lic)")
+		Short: "Import restaurants from NYC OpenData or another directory source",
+		Long: `Import restaurants from a directory source. Records are linked to
+existing restaurants by their source ID, or merged with a restaurant from
+another source when name, address, phone or location agree.
+
+Sources:
+  nyc      NYC DOHMH inspections via Socrata (--area required)
+  yelp     Yelp business file or dataset archive (--file)
+  csv      Any CSV with a header row, e.g. another city's inspections (--file, --source-name)
+  geojson  GeoJSON FeatureCollection, e.g. an OpenStreetMap extract (--file, --source-name)
+
+CSV columns and GeoJSON properties are matched by common names; use
+--column field=name to override (fields: ` + strings.Join(directory.Fields, ", ") + `).`,
+		RunE: runImportRestaurants,
+	}
+	importCmd.Flags().String("source", "nyc", "Directory source: nyc | yelp | csv | geojson")
+	importCmd.Flags().String("file", "", "Input file for yelp, csv and geojson sources")
+	importCmd.Flags().String("source-name", "", "Name recorded with csv/geojson external IDs, e.g. chicago_inspections")
+	importCmd.Flags().StringToString("column", nil, "Column or property per field, e.g. name=\"DBA Name\",id=\"License #\"")
+	importCmd.Flags().String("area", "", "Named area to import (see `restaurants areas list`), e.g. astoria-lic; optional for file sources")
+	importCmd.Flags().String("areas-file", "", "YAML file of area definitions; defaults to the geo_areas table")
 	importCmd.Flags().String("postgres-dsn", "", "PostgreSQL DSN")
 	importCmd.Flags().String("nyc-app-token", "", "NYC OpenData App Token")
 	importCmd.Flags().Int("limit", 100, "Limit the number of records to import")
@@ -133,11 +154,13 @@ func init() {
 	replayMenusCmd.Flags().String("ollama-url", "http://localhost:11434", "Ollama base URL")
 	replayMenusCmd.Flags().String("ollama-model", "nomic-embed-text", "Ollama embedding model")
 	replayMenusCmd.Flags().String("vectorizer", "", "HTTP vectorizer host:port")
+	replayMenusCmd.Flags().Float64("min-extraction-quality", pipeline.DefaultQualityPolicy().MinScore, "Quarantine replayed menus scoring below this quality score for review instead of storing them (0 scores but never quarantines)")
 	restaurantsCmd.AddCommand(replayMenusCmd)
 }
 
 func runImportRestaurants(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
+	sourceKind, _ := cmd.Flags().GetString("source")
 	areaName, _ := cmd.Flags().GetString("area")
 	dsn, _ := cmd.Flags().GetString("postgres-dsn")
 	appToken, _ := cmd.Flags().GetString("nyc-app-token")
@@ -151,7 +174,7 @@ func runImportRestaurants(cmd *cobra.Command, args []string) error {
 		appToken = viper.GetString("NYC_APP_TOKEN")
 	}
 
-	if areaName == "" {
+	if areaName == "" && sourceKind == "nyc" {
 		return fmt.Errorf("must specify --area")
 	}
 	if dsn == "" {
@@ -166,47 +189,36 @@ func runImportRestaurants(cmd *cobra.Command, args []string) error {
 
 	store := menusearch.NewStore(pool)
 
-	riverClient, err := newRiverClient(pool, &river.Config{})
-	if err != nil {
-		return fmt.Errorf("create river client: %w", err)
-	}
-
-	bronzeDir, _ := cmd.Flags().GetString("bronze-dir")
-	todayStr := time.Now().UTC().Format("2006-01-02")
-	avroPath := filepath.Join(bronzeDir, fmt.Sprintf("%s-%s.avro", areaName, todayStr))
-
-	var records []menusearch.NYCRestaurantRecord
-
-	if _, err := os.Stat(avroPath); err == nil {
-		fmt.Printf("Reusing today's avro file: %s\n", avroPath)
-		records, err = menusearch.ReadNYCRestaurantAvro(avroPath)
+	var area *geo.Area
+	if areaName != "" {
+		areasFile, _ := cmd.Flags().GetString("areas-file")
+		area, err = loadArea(ctx, newAreaStore(areasFile, pool), areaName)
 		if err != nil {
-			return fmt.Errorf("read avro: %w", err)
-		}
-	} else {
-		since, err := store.MaxUpdatedAt(ctx)
-		if err != nil {
-			fmt.Printf("Warning: failed to get max updated_at: %v\n", err)
+			return err
 		}
+	}
 
-		fmt.Printf("Fetching restaurants for area %q (since %v)...\n", areaName, since)
-		reader, err := menusearch.FetchNYCRestaurants(ctx, areaName, appToken, since)
-		if err != nil {
-			return fmt.Errorf("fetch restaurants: %w", err)
-		}
-		defer func() { _ = reader.Close() }()
+	src, err := newDirectorySource(cmd, store, area, appToken)
+	if err != nil {
+		return err
+	}
 
-		records, err = menusearch.ParseNYCCSV(reader)
-		if err != nil {
-			return fmt.Errorf("parse csv: %w", err)
-		}
+	fmt.Printf("Fetching restaurants from %s...\n", src.Name())
+	records, err := src.Fetch(ctx)
+	if err != nil {
+		return err
+	}
 
-		if len(records) > 0 {
-			fmt.Printf("Saving %d records to %s\n", len(records), avroPath)
-			if err := menusearch.WriteNYCRestaurantAvro(ctx, avroPath, records); err != nil {
-				return fmt.Errorf("write avro: %w", err)
-			}
+	// Socrata can only prefilter polygon areas by bounding box, and file
+	// sources are not prefiltered at all; keep the records inside the area.
+	if area != nil {
+		matcher, err := area.Matcher()
+		if err != nil {
+			return err
 		}
+		records = slices.DeleteFunc(records, func(rec directory.Record) bool {
+			return !matcher.Match(rec.Place())
+		})
 	}
 
 	offset, _ := cmd.Flags().GetInt("offset")
@@ -214,52 +226,106 @@ func runImportRestaurants(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("Fetched %d unique restaurants.\n", len(records))
 
+	var riverClient *river.Client[pgx.Tx]
+	if !skipDiscovery {
+		riverClient, err = newRiverClient(pool, &river.Config{})
+		if err != nil {
+			return fmt.Errorf("create river client: %w", err)
+		}
+	}
+	maxAttempts := viper.GetInt("scrape-max-attempts")
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+
+	outcomes := make(map[menusearch.UpsertOutcome]int)
 	for _, rec := range records {
-		_, err := store.Upsert(ctx, server.Restaurant{
-			CAMIS:     &rec.CAMIS,
-			DBA:       rec.DBA,
-			Boro:      strPtr(rec.Boro),
-			Building:  strPtr(rec.Building),
-			Street:    strPtr(rec.Street),
-			Zipcode:   strPtr(rec.Zipcode),
-			Phone:     strPtr(rec.Phone),
-			Cuisine:   strPtr(rec.CuisineDescription),
-			Latitude:  floatPtr(rec.Latitude),
-			Longitude: floatPtr(rec.Longitude),
-			NTA:       strPtr(rec.NTA),
-			Status:    menusearch.StatusPendingDiscovery,
-		})
+		id, outcome, err := store.UpsertDirectoryRecord(ctx, rec, menusearch.StatusPendingDiscovery)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "failed to upsert %s: %v\n", rec.CAMIS, err)
+			fmt.Fprintf(os.Stderr, "failed to upsert %s %s: %v\n", rec.Source, rec.ExternalID, err)
 			continue
 		}
+		outcomes[outcome]++
 
-		if !skipDiscovery {
-			maxAttempts := viper.GetInt("scrape-max-attempts")
-			if maxAttempts <= 0 {
-				maxAttempts = 3
-			}
-			_, err = riverClient.Insert(ctx, menusearch.DiscoverMenuURLArgs{
-				CAMIS:    rec.CAMIS,
-				DBA:      rec.DBA,
-				Building: rec.Building,
-				Street:   rec.Street,
-				Boro:     rec.Boro,
-				Zipcode:  rec.Zipcode,
-				Attempt:  1,
-			}, &river.InsertOpts{MaxAttempts: maxAttempts})
+		if riverClient != nil {
+			_, err = riverClient.Insert(ctx, discoverArgsFor(id, rec), &river.InsertOpts{MaxAttempts: maxAttempts})
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "failed to enqueue discovery for %s: %v\n", rec.CAMIS, err)
+				fmt.Fprintf(os.Stderr, "failed to enqueue discovery for %s: %v\n", rec.ExternalID, err)
 			}
 		}
 	}
 
-	fmt.Printf("Imported %d restaurants.\n", len(records))
+	fmt.Printf("Imported %d restaurants (%d new, %d updated, %d merged with other sources).\n",
+		len(records), outcomes[menusearch.UpsertCreated], outcomes[menusearch.UpsertUpdated], outcomes[menusearch.UpsertMerged])
 	return nil
 }
 
+// newDirectorySource builds the directory source selected by --source.
+func newDirectorySource(cmd *cobra.Command, store *menusearch.Store, area *geo.Area, appToken string) (directory.Source, error) {
+	kind, _ := cmd.Flags().GetString("source")
+	file, _ := cmd.Flags().GetString("file")
+	name, _ := cmd.Flags().GetString("source-name")
+	columns, _ := cmd.Flags().GetStringToString("column")
+	for field := range columns {
+		if !slices.Contains(directory.Fields, field) {
+			return nil, fmt.Errorf("--column: unknown field %q (want one of %v)", field, directory.Fields)
+		}
+	}
+
+	if kind != "nyc" && file == "" {
+		return nil, fmt.Errorf("--source %s needs --file", kind)
+	}
+	if (kind == "csv" || kind == "geojson") && name == "" {
+		return nil, fmt.Errorf("--source %s needs --source-name, e.g. chicago_inspections or osm", kind)
+	}
+
+	switch kind {
+	case "nyc":
+		since, err := store.MaxUpdatedAt(cmd.Context())
+		if err != nil {
+			fmt.Printf("Warning: failed to get max updated_at: %v\n", err)
+		}
+		bronzeDir, _ := cmd.Flags().GetString("bronze-dir")
+		todayStr := time.Now().UTC().Format("2006-01-02")
+		return &menusearch.NYCSource{
+			Area:       *area,
+			AppToken:   appToken,
+			Since:      since,
+			BronzePath: filepath.Join(bronzeDir, fmt.Sprintf("%s-%s.avro", area.Name, todayStr)),
+		}, nil
+	case "yelp":
+		return &directory.YelpSource{Path: file}, nil
+	case "csv":
+		return &directory.CSVSource{SourceName: name, Path: file, Columns: columns}, nil
+	case "geojson":
+		return &directory.GeoJSONSource{SourceName: name, Path: file, Columns: columns}, nil
+	}
+	return nil, fmt.Errorf("unknown --source %q (want nyc, yelp, csv or geojson)", kind)
+}
+
+// discoverArgsFor builds the discovery job for restaurant id, imported from
+// rec.
+func discoverArgsFor(id uuid.UUID, rec directory.Record) menusearch.DiscoverMenuURLArgs {
+	args := menusearch.DiscoverMenuURLArgs{
+		RestaurantID: id,
+		DBA:          rec.Name,
+		Building:     rec.Building,
+		Street:       rec.Street,
+		Boro:         rec.Boro,
+		Zipcode:      rec.Zipcode,
+		Attempt:      1,
+	}
+	if rec.Source == directory.SourceNYC {
+		args.CAMIS = rec.ExternalID
+	}
+	if args.Boro == "" {
+		args.Address = rec.FullAddress()
+	}
+	return args
+}
+
 // paginateRecords applies limit and offset to a slice of restaurant records.
-func paginateRecords(records []menusearch.NYCRestaurantRecord, limit, offset int) []menusearch.NYCRestaurantRecord {
+func paginateRecords[T any](records []T, limit, offset int) []T {
 	if offset > 0 {
 		if offset >= len(records) {
 			return nil
@@ -350,7 +416,7 @@ func runEnqueueScrape(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("create river client: %w", err)
 	}
 
-	if err = store.UpdateScrapeResult(ctx, safeDeref(r.CAMIS), menusearch.StatusURLFound, 0, ""); err != nil {
+	if err = store.UpdateScrapeResult(ctx, r.ID, menusearch.StatusURLFound, 0, ""); err != nil {
 		return fmt.Errorf("update status: %w", err)
 	}
 
@@ -411,20 +477,12 @@ func runEnqueueDiscover(cmd *cobra.Command, args []string) error {
 	if maxAttempts <= 0 {
 		maxAttempts = 3
 	}
-	_, err = riverClient.Insert(ctx, menusearch.DiscoverMenuURLArgs{
-		CAMIS:    safeDeref(r.CAMIS),
-		DBA:      r.DBA,
-		Building: safeStr(r.Building),
-		Street:   safeStr(r.Street),
-		Boro:     safeStr(r.Boro),
-		Zipcode:  safeStr(r.Zipcode),
-		Attempt:  1,
-	}, &river.InsertOpts{MaxAttempts: maxAttempts})
+	_, err = riverClient.Insert(ctx, menusearch.DiscoverArgsFor(*r), &river.InsertOpts{MaxAttempts: maxAttempts})
 	if err != nil {
 		return fmt.Errorf("enqueue discover: %w", err)
 	}
 
-	err = store.UpdateScrapeResult(ctx, safeDeref(r.CAMIS), menusearch.StatusPendingDiscovery, 0, "")
+	err = store.UpdateScrapeResult(ctx, r.ID, menusearch.StatusPendingDiscovery, 0, "")
 	if err != nil {
 		return fmt.Errorf("update status: %w", err)
 	}
@@ -440,20 +498,6 @@ func safeStr(s *string) string {
 	return *s
 }
 
-func strPtr(s string) *string {
-	if s == "" {
-		return nil
-	}
-	return &s
-}
-
-func floatPtr(f float64) *float64 {
-	if f == 0 {
-		return nil
-	}
-	return &f
-}
-
 func runRetryRestaurant(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 	camis := args[0]
@@ -503,25 +547,17 @@ func runRetryRestaurant(cmd *cobra.Command, args []string) error {
 				return fmt.Errorf("enqueue scrape %s: %w", u, err)
 			}
 		}
-		err = store.UpdateScrapeResult(ctx, safeDeref(r.CAMIS), menusearch.StatusURLFound, 0, "")
+		err = store.UpdateScrapeResult(ctx, r.ID, menusearch.StatusURLFound, 0, "")
 		if err != nil {
 			return fmt.Errorf("update status: %w", err)
 		}
 		fmt.Printf("Re-enqueued scrape job for %s\n", camis)
 	} else {
-		_, err = riverClient.Insert(ctx, menusearch.DiscoverMenuURLArgs{
-			CAMIS:    safeDeref(r.CAMIS),
-			DBA:      r.DBA,
-			Building: safeStr(r.Building),
-			Street:   safeStr(r.Street),
-			Boro:     safeStr(r.Boro),
-			Zipcode:  safeStr(r.Zipcode),
-			Attempt:  1,
-		}, &river.InsertOpts{MaxAttempts: maxAttempts})
+		_, err = riverClient.Insert(ctx, menusearch.DiscoverArgsFor(*r), &river.InsertOpts{MaxAttempts: maxAttempts})
 		if err != nil {
 			return fmt.Errorf("enqueue discover: %w", err)
 		}
-		err = store.UpdateScrapeResult(ctx, safeDeref(r.CAMIS), menusearch.StatusPendingDiscovery, 0, "")
+		err = store.UpdateScrapeResult(ctx, r.ID, menusearch.StatusPendingDiscovery, 0, "")
 		if err != nil {
 			return fmt.Errorf("update status: %w", err)
 		}
@@ -620,26 +656,18 @@ func runRetryAllFailed(cmd *cobra.Command, args []string) error {
 					failed++
 					continue
 				}
-				if e := store.UpdateScrapeResult(ctx, safeDeref(r.CAMIS), menusearch.StatusURLFound, 0, ""); e != nil {
+				if e := store.UpdateScrapeResult(ctx, r.ID, menusearch.StatusURLFound, 0, ""); e != nil {
 					slog.Warn("failed to update status after re-enqueue", "camis", r.CAMIS, "error", e)
 				}
 				scraped++
 			} else {
-				_, e := riverClient.Insert(ctx, menusearch.DiscoverMenuURLArgs{
-					CAMIS:    safeDeref(r.CAMIS),
-					DBA:      r.DBA,
-					Building: safeStr(r.Building),
-					Street:   safeStr(r.Street),
-					Boro:     safeStr(r.Boro),
-					Zipcode:  safeStr(r.Zipcode),
-					Attempt:  1,
-				}, &river.InsertOpts{MaxAttempts: maxAttempts})
+				_, e := riverClient.Insert(ctx, menusearch.DiscoverArgsFor(r), &river.InsertOpts{MaxAttempts: maxAttempts})
 				if e != nil {
 					slog.Error("failed to enqueue discover job", "camis", r.CAMIS, "error", e)
 					failed++
 					continue
 				}
-				if e := store.UpdateScrapeResult(ctx, safeDeref(r.CAMIS), menusearch.StatusPendingDiscovery, 0, ""); e != nil {
+				if e := store.UpdateScrapeResult(ctx, r.ID, menusearch.StatusPendingDiscovery, 0, ""); e != nil {
 					slog.Warn("failed to update status after re-enqueue", "camis", r.CAMIS, "error", e)
 				}
 				discovered++
@@ -752,20 +780,8 @@ func runReplayRestaurants(cmd *cobra.Command, args []string) error {
 	var totalUpserted, totalEnqueued int
 
 	for _, rec := range records {
-		_, err := store.Upsert(ctx, server.Restaurant{
-			CAMIS:     &rec.CAMIS,
-			DBA:       rec.DBA,
-			Boro:      strPtr(rec.Boro),
-			Building:  strPtr(rec.Building),
-			Street:    strPtr(rec.Street),
-			Zipcode:   strPtr(rec.Zipcode),
-			Phone:     strPtr(rec.Phone),
-			Cuisine:   strPtr(rec.CuisineDescription),
-			Latitude:  floatPtr(rec.Latitude),
-			Longitude: floatPtr(rec.Longitude),
-			NTA:       strPtr(rec.NTA),
-			Status:    menusearch.StatusPendingDiscovery,
-		})
+		dirRec := menusearch.NYCDirectoryRecord(rec)
+		id, _, err := store.UpsertDirectoryRecord(ctx, dirRec, menusearch.StatusPendingDiscovery)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "failed to upsert %s: %v\n", rec.CAMIS, err)
 			continue
@@ -773,15 +789,7 @@ func runReplayRestaurants(cmd *cobra.Command, args []string) error {
 		totalUpserted++
 
 		if !skipDiscovery {
-			_, err = riverClient.Insert(ctx, menusearch.DiscoverMenuURLArgs{
-				CAMIS:    rec.CAMIS,
-				DBA:      rec.DBA,
-				Building: rec.Building,
-				Street:   rec.Street,
-				Boro:     rec.Boro,
-				Zipcode:  rec.Zipcode,
-				Attempt:  1,
-			}, &river.InsertOpts{MaxAttempts: maxAttempts})
+			_, err = riverClient.Insert(ctx, discoverArgsFor(id, dirRec), &river.InsertOpts{MaxAttempts: maxAttempts})
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "failed to enqueue discovery for %s: %v\n", rec.CAMIS, err)
 				continue
@@ -869,6 +877,7 @@ func runReplayMenus(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("Found %d avro files to replay\n", len(files))
+	policy := newQualityPolicy()
 
 	for _, file := range files {
 		f, err := os.Open(file)
@@ -922,6 +931,7 @@ func runReplayMenus(cmd *cobra.Command, args []string) error {
 				if rest.Phone != nil {
 					res.PhoneNumber = *rest.Phone
 				}
+				res.Latitude, res.Longitude = rest.Latitude, rest.Longitude
 			}
 
 			for _, itemA := range itemsAny {
@@ -951,6 +961,20 @@ func runReplayMenus(cmd *cobra.Command, args []string) error {
 						}
 					}
 				}
+				if lang, _ := itemMap["language"].(string); lang != "" {
+					res.Language = lang
+				}
+				if dishEn, _ := itemMap["dish_name_en"].(string); dishEn != "" {
+					descEn, _ := itemMap["description_en"].(string)
+					entry.Translation = &scraper.EntryTranslation{DishName: dishEn, Description: descEn}
+					if si, ok := itemMap["stated_ingredients_en"].([]any); ok {
+						for _, s := range si {
+							if str, ok := s.(string); ok {
+								entry.Translation.StatedIngredients = append(entry.Translation.StatedIngredients, str)
+							}
+						}
+					}
+				}
 				if modsAny, ok := itemMap["modifiers"].([]any); ok {
 					for _, m := range modsAny {
 						mMap, ok := m.(map[string]any)
@@ -979,6 +1003,25 @@ func runReplayMenus(cmd *cobra.Command, args []string) error {
 				}
 			}
 
+			// Replayed records carry no source page, so only the structural
+			// checks apply.
+			res.ExtractionTier, _ = record["extraction_tier"].(string)
+			report, quarantined, err := restaurantStore.ScoreExtraction(ctx, *policy, menusearch.ExtractionQuality{
+				BusinessID: restaurantID,
+				SourceURL:  sourceURL,
+			}, &res)
+			if quarantined {
+				if err != nil {
+					slog.Error("failed to quarantine menu", "business_id", businessIDStr, "error", err)
+				} else {
+					fmt.Printf("quarantined %s for review: %s\n", businessIDStr, report)
+				}
+				continue
+			}
+			if err != nil {
+				slog.Warn("failed to record extraction quality", "business_id", businessIDStr, "error", err)
+			}
+
 			count, err := pipeline.StoreMenu(ctx, &res, restaurantID, sourceURL, menuStore, embedder)
 			if err != nil {
 				slog.Error("failed to store menu items", "business_id", businessIDStr, "error", err)
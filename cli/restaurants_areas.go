@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fodmap/geo"
+	"fodmap/menusearch"
+	"fodmap/server"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+var areasCmd = &cobra.Command{
+	Use:   "areas",
+	Short: "Manage the named geographic areas restaurants are imported by",
+	Long: `Manage named geographic areas. An area matches restaurants by NTA code,
+zip code, borough, NTA+zip pairs, a bounding box, or GeoJSON polygons
+(point-in-polygon on the restaurant's coordinates). Areas live in the
+geo_areas Postgres table, or in a YAML file when --areas-file is set.`,
+}
+
+func init() {
+	restaurantsCmd.AddCommand(areasCmd)
+	areasCmd.PersistentFlags().String("postgres-dsn", "", "PostgreSQL DSN (or POSTGRES_DSN env)")
+	areasCmd.PersistentFlags().String("areas-file", "", "YAML file of area definitions, used instead of Postgres")
+
+	areasCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List areas",
+		Args:  cobra.NoArgs,
+		RunE:  runAreasList,
+	})
+	areasCmd.AddCommand(&cobra.Command{
+		Use:   "show [name]",
+		Short: "Print an area definition as YAML",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runAreasShow,
+	})
+
+	putCmd := &cobra.Command{
+		Use:   "put [name]",
+		Short: "Create or replace an area",
+		Long: `Create or replace an area, either from a YAML/JSON definition (--file)
+or from filter flags. --geojson accepts a Polygon, MultiPolygon, Feature or
+FeatureCollection (e.g. an NTA boundary export) and stores its polygons.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runAreasPut,
+	}
+	putCmd.Flags().String("file", "", "YAML or JSON file holding one area definition")
+	putCmd.Flags().String("description", "", "Human-readable description")
+	putCmd.Flags().StringSlice("nta", nil, "NTA codes, e.g. BK73,BK76")
+	putCmd.Flags().StringSlice("zip", nil, "Zip codes")
+	putCmd.Flags().StringSlice("borough", nil, "Boroughs, e.g. Brooklyn,Manhattan")
+	putCmd.Flags().Float64Slice("bbox", nil, "Bounding box minLon,minLat,maxLon,maxLat")
+	putCmd.Flags().String("geojson", "", "GeoJSON file with the area's polygons")
+	areasCmd.AddCommand(putCmd)
+
+	areasCmd.AddCommand(&cobra.Command{
+		Use:   "delete [name]",
+		Short: "Delete an area",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runAreasDelete,
+	})
+}
+
+// newAreaStore returns the YAML file store when path is set, else the
+// Postgres store on pool.
+func newAreaStore(path string, pool *pgxpool.Pool) server.AreaStore {
+	if path != "" {
+		return menusearch.NewFileAreaStore(path)
+	}
+	return menusearch.NewAreaStore(pool)
+}
+
+// openAreaStore resolves the area store from the areas command's flags. The
+// returned close func releases any database pool.
+func openAreaStore(cmd *cobra.Command) (server.AreaStore, func(), error) {
+	if path, _ := cmd.Flags().GetString("areas-file"); path != "" {
+		return menusearch.NewFileAreaStore(path), func() {}, nil
+	}
+	dsn, _ := cmd.Flags().GetString("postgres-dsn")
+	if dsn == "" {
+		dsn = viper.GetString("POSTGRES_DSN")
+	}
+	if dsn == "" {
+		return nil, nil, fmt.Errorf("must specify --areas-file or --postgres-dsn")
+	}
+	pool, err := pgxpool.New(cmd.Context(), dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("connect to db: %w", err)
+	}
+	return menusearch.NewAreaStore(pool), pool.Close, nil
+}
+
+func runAreasList(cmd *cobra.Command, _ []string) error {
+	store, closeStore, err := openAreaStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	areas, err := store.ListAreas(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("list areas: %w", err)
+	}
+	out := cmd.OutOrStdout()
+	for _, a := range areas {
+		_, _ = fmt.Fprintf(out, "%-24s %s\n", a.Name, describeArea(a))
+	}
+	return nil
+}
+
+func runAreasShow(cmd *cobra.Command, args []string) error {
+	store, closeStore, err := openAreaStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	area, err := store.GetArea(cmd.Context(), args[0])
+	if err != nil {
+		return fmt.Errorf("get area: %w", err)
+	}
+	if area == nil {
+		return fmt.Errorf("unknown area: %q", args[0])
+	}
+	data, err := yaml.Marshal(area)
+	if err != nil {
+		return err
+	}
+	_, err = cmd.OutOrStdout().Write(data)
+	return err
+}
+
+func runAreasPut(cmd *cobra.Command, args []string) error {
+	area, err := areaFromFlags(cmd, args)
+	if err != nil {
+		return err
+	}
+	if err := area.Validate(); err != nil {
+		return err
+	}
+
+	store, closeStore, err := openAreaStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	if err := store.PutArea(cmd.Context(), area); err != nil {
+		return fmt.Errorf("save area: %w", err)
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Saved area %q: %s\n", area.Name, describeArea(area))
+	return nil
+}
+
+func runAreasDelete(cmd *cobra.Command, args []string) error {
+	store, closeStore, err := openAreaStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	if err := store.DeleteArea(cmd.Context(), args[0]); err != nil {
+		return fmt.Errorf("delete area %q: %w", args[0], err)
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Deleted area %q\n", args[0])
+	return nil
+}
+
+// areaFromFlags builds the area for `areas put` from --file, or from the
+// filter flags and the name argument.
+func areaFromFlags(cmd *cobra.Command, args []string) (geo.Area, error) {
+	var area geo.Area
+	if path, _ := cmd.Flags().GetString("file"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return area, fmt.Errorf("read area file: %w", err)
+		}
+		// JSON is valid YAML, so one decoder covers both.
+		if err := yaml.Unmarshal(data, &area); err != nil {
+			return area, fmt.Errorf("parse area file %s: %w", path, err)
+		}
+	}
+	if len(args) == 1 {
+		if area.Name != "" && area.Name != args[0] {
+			return area, fmt.Errorf("name argument %q does not match file name %q", args[0], area.Name)
+		}
+		area.Name = args[0]
+	}
+
+	flags := cmd.Flags()
+	if v, _ := flags.GetString("description"); v != "" {
+		area.Description = v
+	}
+	if v, _ := flags.GetStringSlice("nta"); len(v) > 0 {
+		area.NTAs = v
+	}
+	if v, _ := flags.GetStringSlice("zip"); len(v) > 0 {
+		area.Zipcodes = v
+	}
+	if v, _ := flags.GetStringSlice("borough"); len(v) > 0 {
+		area.Boroughs = v
+	}
+	if v, _ := flags.GetFloat64Slice("bbox"); len(v) > 0 {
+		if len(v) != 4 {
+			return area, fmt.Errorf("--bbox needs 4 values (minLon,minLat,maxLon,maxLat), got %d", len(v))
+		}
+		area.BBox = &geo.BBox{v[0], v[1], v[2], v[3]}
+	}
+	if path, _ := flags.GetString("geojson"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return area, fmt.Errorf("read GeoJSON: %w", err)
+		}
+		g, err := geo.ParseGeoJSON(data)
+		if err != nil {
+			return area, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		area.Geometry = g
+	}
+	return area, nil
+}
+
+// describeArea summarises an area's filters on one line.
+func describeArea(a geo.Area) string {
+	var parts []string
+	if len(a.NTAs) > 0 {
+		parts = append(parts, "ntas="+strings.Join(a.NTAs, ","))
+	}
+	if len(a.NTAZipRestrict) > 0 {
+		b, _ := json.Marshal(a.NTAZipRestrict)
+		parts = append(parts, "nta_zip_restrict="+string(b))
+	}
+	if len(a.Zipcodes) > 0 {
+		parts = append(parts, "zipcodes="+strings.Join(a.Zipcodes, ","))
+	}
+	if len(a.Boroughs) > 0 {
+		parts = append(parts, "boroughs="+strings.Join(a.Boroughs, ","))
+	}
+	if a.BBox != nil {
+		parts = append(parts, fmt.Sprintf("bbox=%v", *a.BBox))
+	}
+	if a.Geometry != nil {
+		parts = append(parts, "geometry="+a.Geometry.Type)
+	}
+	return strings.Join(parts, " ")
+}
+
+// loadArea fetches a named area for commands that only need to read one.
+func loadArea(ctx context.Context, store server.AreaStore, name string) (*geo.Area, error) {
+	area, err := store.GetArea(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("load area: %w", err)
+	}
+	if area == nil {
+		return nil, fmt.Errorf("unknown area: %q (see `restaurants areas list`)", name)
+	}
+	return area, nil
+}
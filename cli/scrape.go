@@ -37,8 +37,9 @@ func init() {
 	scrapeCmd.Flags().String("camis", "cli-manual", "Restaurant CAMIS (NYC DOHMH ID); links menu_items.business_id to restaurants.camis")
 
 	// Storage backend
-	scrapeCmd.Flags().String("menu-store", "", "Menu store backend: postgres | weaviate | dual (preferred over --store)")
-	scrapeCmd.Flags().String("store", "weaviate", "Storage backend (deprecated alias for --menu-store): weaviate | postgres | pinecone")
+	scrapeCmd.Flags().String("menu-store", "", "Menu store backend: postgres | weaviate | dual | local (preferred over --store)")
+	scrapeCmd.Flags().String("store", "weaviate", "Storage backend (deprecated alias for --menu-store): weaviate | postgres | pinecone | local")
+	scrapeCmd.Flags().String("local-index", "", "Directory of the embedded on-disk search backend (used when --menu-store=local)")
 	scrapeCmd.Flags().String("weaviate", "localhost:8090", "Weaviate host:port")
 	scrapeCmd.Flags().String("weaviate-scheme", "http", "Weaviate scheme (http or https)")
 	scrapeCmd.Flags().String("weaviate-api-key", "", "Weaviate API key")
@@ -47,11 +48,13 @@ func init() {
 	scrapeCmd.Flags().String("pinecone-index-host", "", "Pinecone index host")
 
 	// Embedding backend
-	scrapeCmd.Flags().String("embedder", "ollama", "Embedding backend: ollama | tei | vectorizer")
+	scrapeCmd.Flags().String("embedder", "ollama", "Embedding backend: ollama | tei | vectorizer | hash (deterministic, no service; for local development)")
 	scrapeCmd.Flags().String("embed-backend", "ollama", "Embedding backend (deprecated alias for --embedder): ollama | vectorizer")
 	scrapeCmd.Flags().String("ollama-url", "http://localhost:11434", "Ollama base URL")
 	scrapeCmd.Flags().String("ollama-model", "nomic-embed-text", "Ollama embedding model")
 	scrapeCmd.Flags().String("vectorizer", "", "HTTP vectorizer host:port (legacy; prefer --embedder=vectorizer with --vectorizer-url)")
+	addEmbeddingCacheFlags(scrapeCmd)
+	scrapeCmd.Flags().Int("embedding-dim", search.ExpectedEmbeddingDim, "Vector dimension the embedding model returns (must match the menu collection's active embedding space)")
 	scrapeCmd.Flags().String("vectorizer-url", "", "HTTP vectorizer base URL (used when --embedder=vectorizer)")
 	scrapeCmd.Flags().String("tei-url", "", "Text Embeddings Inference (TEI) service URL (used when --embedder=tei)")
 	scrapeCmd.Flags().String("tei-model", "nomic-embed-text", "TEI model name (informational; TEI serves one model per instance)")
@@ -69,6 +72,8 @@ func init() {
 	scrapeCmd.Flags().String("llm-model", "qwen3-vl", "LLM model name")
 	scrapeCmd.Flags().String("llm-api-key", "", "API key for cloud backends (OpenAI, Gemini, etc.)")
 	scrapeCmd.Flags().String("llm-reasoning-effort", "none", "Reasoning effort: none | low | medium | high (none = fastest, cost-optimal for Gemini)")
+	scrapeCmd.Flags().Bool("translate", false, "Translate non-English menus to English with the --llm-* endpoint; originals are kept")
+	scrapeCmd.Flags().Float64("min-extraction-quality", pipeline.DefaultQualityPolicy().MinScore, "Quarantine menu extractions scoring below this grounding/quality score instead of storing them (0 scores but never quarantines)")
 
 	// Fetch options
 	scrapeCmd.Flags().Bool("ignore-robots", false, "Skip robots.txt check")
@@ -130,21 +135,28 @@ func runScrape(cmd *cobra.Command, args []string) error {
 		embedderType = "vectorizer"
 		vectorizerURL = "http://" + vectorizerHost
 	}
-	embedder, err := search.NewEmbedder(ctx, search.EmbedderConfig{
+	embedderCfg := search.EmbedderConfig{
 		Type:          embedderType,
 		OllamaURL:     ollamaURL,
 		OllamaModel:   ollamaModel,
 		TEIURL:        viper.GetString("tei-url"),
 		TEIModel:      viper.GetString("tei-model"),
 		VectorizerURL: vectorizerURL,
-	})
+		Dim:           viper.GetInt("embedding-dim"),
+	}
+	embedder, err := search.NewEmbedder(ctx, embedderCfg)
 	if err != nil {
 		return fmt.Errorf("building embedder: %w", err)
 	}
+	embedder, embedCache, err := withEmbeddingCache(embedder, embedderCfg)
+	if err != nil {
+		return err
+	}
 	defer func() { _ = embedder.Close() }()
+	defer logEmbeddingCacheStats(embedCache)
 	slog.Info("embedder ready", "type", embedderType)
 
-	// Build MenuStore. --menu-store selects postgres|weaviate|dual; when
+	// Build MenuStore. --menu-store selects postgres|weaviate|dual|local; when
 	// empty, the legacy --store flag is consulted for backward compat.
 	menuStoreType := viper.GetString("menu-store")
 	if menuStoreType == "" {
@@ -152,11 +164,12 @@ func runScrape(cmd *cobra.Command, args []string) error {
 		// Legacy --store values: weaviate|postgres|pinecone. Map to the new
 		// factory (pinecone is unsupported for menus -> error).
 		if menuStoreType == "pinecone" {
-			return fmt.Errorf("pinecone does not support menus yet; use --menu-store=postgres|weaviate|dual")
+			return fmt.Errorf("pinecone does not support menus yet; use --menu-store=postgres|weaviate|dual|local")
 		}
 	}
 	store, err := server.NewMenuStore(ctx, server.MenuStoreConfig{
 		Type:           menuStoreType,
+		LocalIndexDir:  viper.GetString("local-index"),
 		PostgresDSN:    viper.GetString("postgres-dsn"),
 		WeaviateHost:   weaviateHost,
 		WeaviateScheme: weaviateScheme,
@@ -166,6 +179,9 @@ func runScrape(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("building store: %w", err)
 	}
+	// On Postgres, including a dual store writing Postgres first, embed menu
+	// items with the menu collection's active embedding space.
+	menuEmbedder := server.MenuEmbedder(store, embedder)
 
 	// Build extractor. When --extractor-url is set, all extraction routes to
 	// the Python scraper service (HTML/text, PDF, image). When empty, the plain
@@ -214,6 +230,7 @@ func runScrape(cmd *cobra.Command, args []string) error {
 	// Otherwise derive a deterministic UUID from the camis string so ad-hoc
 	// CLI scrapes still produce a stable surrogate key.
 	var restaurantID uuid.UUID
+	var restaurantStore *menusearch.Store
 	pgDSN := viper.GetString("postgres-dsn")
 	if pgDSN != "" {
 		pool, pErr := pgxpool.New(ctx, pgDSN)
@@ -221,7 +238,7 @@ func runScrape(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("connect to db for restaurant lookup: %w", pErr)
 		}
 		defer pool.Close()
-		restaurantStore := menusearch.NewStore(pool)
+		restaurantStore = menusearch.NewStore(pool)
 		rest, gErr := restaurantStore.Get(ctx, camis)
 		if gErr != nil {
 			slog.Warn("failed to look up restaurant by camis", "camis", camis, "error", gErr)
@@ -239,10 +256,12 @@ func runScrape(cmd *cobra.Command, args []string) error {
 			rest = upserted
 		}
 		restaurantID = rest.ID
+		result.Latitude, result.Longitude = rest.Latitude, rest.Longitude
 	} else {
 		restaurantID = uuid.NewSHA1(uuid.NameSpaceDNS, []byte(camis))
 	}
 
+	var bronzePath string
 	if len(rawBody) > 0 {
 		date := time.Now().UTC().Format("2006-01-02")
 		bronzeDir := os.Getenv("RESTAURANT_BRONZE_DIR")
@@ -254,6 +273,7 @@ func runScrape(cmd *cobra.Command, args []string) error {
 			if wErr := os.WriteFile(htmlPath, rawBody, 0o644); wErr != nil {
 				slog.Warn("failed to write HTML bronze", "path", htmlPath, "error", wErr)
 			} else {
+				bronzePath = htmlPath
 				slog.Info("saved raw body to bronze layer", "path", htmlPath)
 			}
 		}
@@ -264,14 +284,72 @@ func runScrape(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Score the extraction under the same policy as the scrape worker. With
+	// a database the report is recorded and a quarantined menu queued for
+	// review; without one a quarantined menu is only reported.
+	var sources [][]byte
+	if len(rawBody) > 0 {
+		sources = append(sources, rawBody)
+	}
+	policy := newQualityPolicy()
+	var report pipeline.QualityReport
+	var quarantined bool
+	if restaurantStore != nil {
+		report, quarantined, err = restaurantStore.ScoreExtraction(ctx, *policy, menusearch.ExtractionQuality{
+			BusinessID: restaurantID,
+			SourceURL:  rawURL,
+			BronzePath: bronzePath,
+		}, result, sources...)
+		if err != nil {
+			if quarantined {
+				return err
+			}
+			slog.Warn("failed to record extraction quality", "error", err)
+		}
+	} else {
+		report = policy.Score(*result, sources...)
+		quarantined = policy.Quarantine(report)
+	}
+	if quarantined {
+		fmt.Printf("Extraction from %s quarantined, not stored: %s (threshold %.2f)\n", rawURL, report, policy.MinScore)
+		return nil
+	}
+	slog.Info("extraction quality", "score", report.Score, "flagged", report.Flagged(), "grounded", report.Grounded)
+
+	// Translation always uses the --llm-* endpoint: the scraper service has
+	// no translation route, so with --extractor-url a separate client is
+	// built for it.
+	if translate, _ := cmd.Flags().GetBool("translate"); translate {
+		tr, ok := ex.(scraper.MenuTranslator)
+		if !ok {
+			oaex, err := scraper.NewOpenAICompatExtractor(llmURL, llmModel, llmAPIKey, llmReasoningEffort)
+			if err != nil {
+				return fmt.Errorf("building translator: %w", err)
+			}
+			tr = oaex
+		}
+		if err := pipeline.TranslateMenu(ctx, result, tr); err != nil {
+			slog.Warn("menu translation failed; storing original text only", "language", result.Language, "error", err)
+		} else if result.Language != scraper.LanguageEnglish {
+			slog.Info("translated menu to English", "language", result.Language)
+		}
+	}
+
 	items := make([]search.MenuItem, 0, len(result.Items))
 	for _, entry := range result.Items {
-		items = append(items, search.MenuItem{
+		item := search.MenuItem{
 			DishName:           entry.DishName,
 			Description:        entry.Description,
 			StatedIngredients:  entry.StatedIngredients,
 			HasFullIngredients: entry.HasFullIngredients,
-		})
+			Language:           result.Language,
+		}
+		if t := entry.Translation; t != nil {
+			item.DishNameEn = t.DishName
+			item.DescriptionEn = t.Description
+			item.StatedIngredientsEn = t.StatedIngredients
+		}
+		items = append(items, item)
 	}
 
 	record := menusearch.MenuExtractionRecord{
@@ -298,7 +376,7 @@ func runScrape(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	count, err := pipeline.StoreMenu(ctx, result, restaurantID, rawURL, store, embedder)
+	count, err := pipeline.StoreMenu(ctx, result, restaurantID, rawURL, store, menuEmbedder)
 	if err != nil {
 		return err
 	}
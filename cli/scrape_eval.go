@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"fodmap/pipeline"
+	"fodmap/scraper"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var scrapeEvalCmd = &cobra.Command{
+	Use:   "eval <corpus-dir>",
+	Short: "Score menu extraction against golden results and compare backends.",
+	Long: `Runs the extraction cascade over a corpus of saved pages, PDFs and images,
+each with a <name>.golden.json MenuExtractionResult, and reports item
+precision/recall, ingredient F1 and price accuracy per extraction tier.
+
+Pages are served from the corpus, never fetched. With --cassette, LLM and
+scraper-service responses are replayed from recordings (or saved there with
+--record), so a run can be repeated fully offline. Pass earlier --json reports
+with --baseline to compare backends side by side.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runScrapeEval,
+}
+
+func init() {
+	scrapeCmd.AddCommand(scrapeEvalCmd)
+
+	scrapeEvalCmd.Flags().String("llm-url", "http://localhost:8000/v1", "Base URL for the OpenAI-compatible LLM endpoint (include version segment)")
+	scrapeEvalCmd.Flags().String("llm-model", "qwen3-vl", "LLM model name")
+	scrapeEvalCmd.Flags().String("llm-api-key", "", "API key for cloud backends (OpenAI, Gemini, etc.)")
+	scrapeEvalCmd.Flags().String("llm-reasoning-effort", "none", "Reasoning effort: none | low | medium | high")
+	scrapeEvalCmd.Flags().String("extractor-url", "", "Base URL of the Python scraper service; empty = --llm-* extractor")
+	scrapeEvalCmd.Flags().Duration("extractor-page-timeout", 2*time.Minute, "Per-page request timeout when calling the scraper service")
+	scrapeEvalCmd.Flags().Duration("extractor-pdf-timeout", 10*time.Minute, "Overall PDF deadline when calling the scraper service")
+	scrapeEvalCmd.Flags().Bool("enable-vision", false, "Send PDFs/images to the vision LLM (pure-Go path)")
+	scrapeEvalCmd.Flags().Bool("pdftotext", false, "Use system pdftotext (poppler) for PDF text extraction")
+	scrapeEvalCmd.Flags().String("webagent-adapter", "", "webagent adapter ID (site/target) for JS-rendered pages; requires --extractor-url")
+
+	scrapeEvalCmd.Flags().String("cassette", "", "Directory of recorded backend responses to replay (offline run)")
+	scrapeEvalCmd.Flags().Bool("record", false, "Call the backend and save its responses to --cassette")
+	scrapeEvalCmd.Flags().String("label", "", "Name of this run in the report (default: model or service URL)")
+	scrapeEvalCmd.Flags().String("report", "", "Write the Markdown report here instead of stdout")
+	scrapeEvalCmd.Flags().String("json", "", "Also write this run's report as JSON, for use as a --baseline")
+	scrapeEvalCmd.Flags().StringSlice("baseline", nil, "JSON reports of earlier runs to compare against (repeatable)")
+}
+
+func runScrapeEval(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	cases, err := pipeline.LoadEvalCases(args[0])
+	if err != nil {
+		return fmt.Errorf("loading eval corpus: %w", err)
+	}
+	if len(cases) == 0 {
+		return fmt.Errorf("no *.golden.json cases in %s", args[0])
+	}
+
+	var baselines []pipeline.EvalReport
+	for _, path := range viper.GetStringSlice("baseline") {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading baseline: %w", err)
+		}
+		var r pipeline.EvalReport
+		if err := json.Unmarshal(data, &r); err != nil {
+			return fmt.Errorf("decoding baseline %s: %w", path, err)
+		}
+		baselines = append(baselines, r)
+	}
+
+	cassetteDir := viper.GetString("cassette")
+	record := viper.GetBool("record")
+	if record && cassetteDir == "" {
+		return fmt.Errorf("--record requires --cassette")
+	}
+	var cassette *scraper.Cassette
+	if cassetteDir != "" {
+		cassette = &scraper.Cassette{Dir: cassetteDir, Record: record}
+	}
+
+	label := viper.GetString("label")
+	var ex scraper.Extractor
+	if extractorURL := viper.GetString("extractor-url"); extractorURL != "" {
+		sex := scraper.NewServiceExtractor(extractorURL, viper.GetDuration("extractor-page-timeout"), viper.GetDuration("extractor-pdf-timeout"))
+		if cassette != nil {
+			sex.SetTransport(cassette)
+		}
+		ex = sex
+		if label == "" {
+			label = "service " + extractorURL
+		}
+	} else {
+		oaex, err := scraper.NewOpenAICompatExtractor(viper.GetString("llm-url"), viper.GetString("llm-model"),
+			viper.GetString("llm-api-key"), viper.GetString("llm-reasoning-effort"))
+		if err != nil {
+			return fmt.Errorf("building extractor: %w", err)
+		}
+		if cassette != nil {
+			oaex.SetTransport(cassette)
+		}
+		ex = oaex
+		if label == "" {
+			label = viper.GetString("llm-model")
+		}
+	}
+
+	slog.Info("running extraction eval", "cases", len(cases), "label", label, "cassette", cassetteDir, "record", record)
+	report, err := pipeline.RunEval(ctx, cases, ex, pipeline.EvalOptions{
+		Label:           label,
+		EnableVision:    viper.GetBool("enable-vision"),
+		UsePdftotext:    viper.GetBool("pdftotext"),
+		WebagentAdapter: viper.GetString("webagent-adapter"),
+	})
+	if err != nil {
+		return err
+	}
+
+	if path := viper.GetString("json"); path != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return fmt.Errorf("writing JSON report: %w", err)
+		}
+	}
+
+	var out io.Writer = cmd.OutOrStdout()
+	if path := viper.GetString("report"); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("creating report: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		out = f
+	}
+	return pipeline.WriteEvalMarkdown(out, append([]pipeline.EvalReport{report}, baselines...)...)
+}
@@ -96,7 +96,7 @@ func (s *stubMenuStore) EnsureMenuSchema(_ context.Context) error { return nil }
 func (s *stubMenuStore) BatchUpsertMenu(_ context.Context, _ []search.MenuItem) error {
 	return nil
 }
-func (s *stubMenuStore) SearchMenu(_ context.Context, _ string, _ int) ([]search.MenuItem, error) {
+func (s *stubMenuStore) SearchMenu(_ context.Context, _ string, _ int, _ search.SearchFilter) ([]search.MenuItem, error) {
 	return nil, nil
 }
 
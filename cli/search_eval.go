@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"fodmap/search"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var searchEvalCmd = &cobra.Command{
+	Use:   "eval <queries.jsonl>",
+	Short: "Score search relevance against judged queries and compare configurations.",
+	Long: `Runs a query set with relevance judgements against a search backend and
+reports nDCG@k, MRR, recall@k and latency percentiles per kind of search
+(reviews, businesses, fodmap, menu). Each line of the query set is a JSON
+object:
+
+  {"id": "gf-pasta", "kind": "reviews", "query": "gluten free pasta",
+   "filter": {"city": "Boston"}, "relevant": {"review-123": 2, "review-456": 1}}
+
+relevant maps result IDs (review IDs, "name|city|state" business keys,
+ingredient names or menu item IDs) to a grade; higher is more relevant.
+
+--backend names the backend as for search migrate, connected with the same
+flags as serve; --alpha, --rrf and --rerank-* change how it ranks. Pass
+earlier --json reports with --baseline to compare configurations side by
+side. With --backend local and --embedder hash a run needs no services.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSearchEval,
+}
+
+func init() {
+	searchCmd.AddCommand(searchEvalCmd)
+
+	searchEvalCmd.Flags().String("backend", "", "Backend to evaluate: weaviate | postgres | pinecone | local, optionally :<target>")
+	searchEvalCmd.Flags().Int("k", search.DefaultEvalK, "Rank cutoff for nDCG@k and recall@k, and results requested per query")
+	searchEvalCmd.Flags().Float32("alpha", -1, "Hybrid alpha for every query: between 0 and 1 blends BM25 and vector scores, higher weighing vector more; 0 and 1 are both pure vector, as for ?alpha=; negative keeps each query's own")
+	searchEvalCmd.Flags().Bool("rrf", false, "Fuse dense and lexical candidates by reciprocal-rank fusion, as serve --rrf does")
+	searchEvalCmd.Flags().Int("rrf-candidates", search.DefaultFusionCandidates, "Candidates each retriever contributes to fusion and reranking")
+	searchEvalCmd.Flags().String("rerank-url", "", "TEI cross-encoder service URL whose /rerank re-scores the top candidates; empty disables reranking")
+	searchEvalCmd.Flags().Int("rerank-top-n", search.DefaultRerankTopN, "Candidates the cross-encoder re-scores (at least --k)")
+	searchEvalCmd.Flags().String("weaviate", "localhost:8090", "Weaviate host:port")
+	searchEvalCmd.Flags().String("weaviate-scheme", "http", "Weaviate scheme (http or https)")
+	searchEvalCmd.Flags().String("weaviate-api-key", "", "Weaviate API Key (for Weaviate Cloud)")
+	searchEvalCmd.Flags().String("postgres-dsn", "", "PostgreSQL connection string (or POSTGRES_DSN env)")
+	searchEvalCmd.Flags().String("pinecone-api-key", "", "Pinecone API Key")
+	searchEvalCmd.Flags().String("pinecone-index-host", "", "Pinecone Index Host (e.g. https://index-name.svc.pinecone.io)")
+	searchEvalCmd.Flags().String("pinecone-bm25-stats", search.DefaultPineconeBM25StatsPath, "BM25 corpus statistics file written by `index` for Pinecone hybrid review search (empty ignores term rarity)")
+	searchEvalCmd.Flags().String("local-index", "", "Directory of the embedded on-disk search backend")
+	searchEvalCmd.Flags().String("embedder", "ollama", "Embedding backend: ollama | tei | vectorizer | hash (deterministic, no service; for local development)")
+	searchEvalCmd.Flags().String("ollama-url", "http://localhost:11434", "Ollama server URL")
+	searchEvalCmd.Flags().String("ollama-model", "nomic-embed-text", "Ollama embedding model")
+	searchEvalCmd.Flags().String("tei-url", "", "Text Embeddings Inference (TEI) service URL (used when --embedder=tei)")
+	searchEvalCmd.Flags().String("tei-model", "nomic-embed-text", "TEI model name (informational; TEI serves one model per instance)")
+	searchEvalCmd.Flags().String("vectorizer-url", "", "Base URL for the HTTP vectorizer-proxy (used when --embedder=vectorizer)")
+	searchEvalCmd.Flags().Int("embedding-dim", search.ExpectedEmbeddingDim, "Vector dimension the embedding model returns; Postgres collections cut over to another embedding space use that space's recorded embedder instead")
+
+	searchEvalCmd.Flags().String("label", "", "Name of this run in the report (default: backend and embedder)")
+	searchEvalCmd.Flags().String("report", "", "Write the Markdown report here instead of stdout")
+	searchEvalCmd.Flags().String("json", "", "Also write this run's report as JSON, for use as a --baseline")
+	searchEvalCmd.Flags().StringSlice("baseline", nil, "JSON reports of earlier runs to compare against (repeatable)")
+	_ = searchEvalCmd.MarkFlagRequired("backend")
+}
+
+func runSearchEval(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	queries, err := search.LoadEvalQueries(args[0])
+	if err != nil {
+		return fmt.Errorf("loading eval queries: %w", err)
+	}
+	if len(queries) == 0 {
+		return fmt.Errorf("no queries in %s", args[0])
+	}
+
+	var baselines []search.EvalReport
+	for _, path := range viper.GetStringSlice("baseline") {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading baseline: %w", err)
+		}
+		var r search.EvalReport
+		if err := json.Unmarshal(data, &r); err != nil {
+			return fmt.Errorf("decoding baseline %s: %w", path, err)
+		}
+		// Fail before running rather than after: WriteEvalMarkdown refuses
+		// to compare runs scored at different cutoffs.
+		if k := cmp.Or(max(viper.GetInt("k"), 0), search.DefaultEvalK); r.K != k {
+			return fmt.Errorf("baseline %s was scored at k=%d, this run at k=%d", path, r.K, k)
+		}
+		baselines = append(baselines, r)
+	}
+
+	embedder, err := search.NewEmbedder(ctx, search.EmbedderConfig{
+		Type:          viper.GetString("embedder"),
+		OllamaURL:     viper.GetString("ollama-url"),
+		OllamaModel:   viper.GetString("ollama-model"),
+		TEIURL:        viper.GetString("tei-url"),
+		TEIModel:      viper.GetString("tei-model"),
+		VectorizerURL: viper.GetString("vectorizer-url"),
+		Dim:           viper.GetInt("embedding-dim"),
+	})
+	if err != nil {
+		return fmt.Errorf("building embedder: %w", err)
+	}
+	defer func() { _ = embedder.Close() }()
+
+	backend, name, closeBackend, err := openSearchBackend(viper.GetString("backend"), embedder, true)
+	if err != nil {
+		return fmt.Errorf("--backend: %w", err)
+	}
+	defer closeBackend()
+	if pg, ok := backend.(*search.PostgresClient); ok {
+		pg.FollowEmbeddingSpaces(search.DefaultEmbeddingSpaceTTL)
+	}
+
+	opts := search.EvalOptions{
+		Label:    viper.GetString("label"),
+		K:        viper.GetInt("k"),
+		Pipeline: newRetrievalPipeline(),
+	}
+	if opts.Label == "" {
+		opts.Label = name + " " + viper.GetString("embedder")
+	}
+	if alpha := float32(viper.GetFloat64("alpha")); alpha >= 0 {
+		opts.Alpha = &alpha
+	}
+
+	slog.Info("running search eval", "queries", len(queries), "label", opts.Label, "k", opts.K)
+	report, err := search.RunEval(ctx, queries, backend, opts)
+	if err != nil {
+		return err
+	}
+
+	if path := viper.GetString("json"); path != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return fmt.Errorf("writing JSON report: %w", err)
+		}
+	}
+
+	var out io.Writer = cmd.OutOrStdout()
+	if path := viper.GetString("report"); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("creating report: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		out = f
+	}
+	return search.WriteEvalMarkdown(out, append([]search.EvalReport{report}, baselines...)...)
+}
@@ -0,0 +1,336 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"fodmap/menusearch"
+	"fodmap/search"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search",
+	Short: "Manage the search backends' stored data",
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Copy reviews, ingredients, menus and regulatory updates with their vectors between search backends",
+		Long: `Stream every collection from one search backend to another with the vectors
+it stores, so switching backends needs no re-embedding:
+
+  fodmap-detector search migrate --from weaviate --to postgres --postgres-dsn postgres://...
+
+--from and --to name a backend (weaviate | postgres | pinecone | local),
+connected with the same flags as index and serve. To copy between two
+backends of one kind, give the target after a colon: weaviate:host:port
+(or weaviate:https://host), postgres:<dsn>, pinecone:<index host> or
+local:<dir>.
+
+Progress is saved to --checkpoint after every batch, so an interrupted
+migration re-run with the same flags resumes where it stopped. Each
+collection is verified by comparing the vector counts of both backends;
+a mismatch fails the command. A collection one of the backends does not
+store is reported and left alone.`,
+		Args: cobra.NoArgs,
+		RunE: runSearchMigrate,
+	}
+	migrateCmd.Flags().String("from", "", "Backend to copy from: weaviate | postgres | pinecone | local, optionally :<target>")
+	migrateCmd.Flags().String("to", "", "Backend to copy to, as for --from")
+	migrateCmd.Flags().StringSlice("collections", nil, "Collections to copy (default all both backends store): reviews, fodmap, menu, regulatory")
+	migrateCmd.Flags().Int("batch-size", search.DefaultMigrationBatchSize, "Records read and written per batch")
+	migrateCmd.Flags().String("checkpoint", "search-migrate.checkpoint", "Path to checkpoint file (empty string disables checkpointing)")
+	migrateCmd.Flags().String("from-model", "", "Embedding model of the source's vectors, checked against a Postgres destination's active embedding space (a Postgres source records its own)")
+	migrateCmd.Flags().String("weaviate", "localhost:8090", "Weaviate host:port")
+	migrateCmd.Flags().String("weaviate-scheme", "http", "Weaviate scheme (http or https)")
+	migrateCmd.Flags().String("weaviate-api-key", "", "Weaviate API Key (for Weaviate Cloud)")
+	migrateCmd.Flags().String("postgres-dsn", "", "PostgreSQL connection string (or POSTGRES_DSN env)")
+	migrateCmd.Flags().String("pinecone-api-key", "", "Pinecone API Key")
+	migrateCmd.Flags().String("pinecone-index-host", "", "Pinecone Index Host (e.g. https://index-name.svc.pinecone.io)")
+	migrateCmd.Flags().String("pinecone-bm25-stats", search.DefaultPineconeBM25StatsPath, "File the BM25 corpus statistics of a Pinecone destination are kept in (empty disables)")
+	migrateCmd.Flags().String("local-index", "", "Directory of the embedded on-disk search backend")
+	_ = migrateCmd.MarkFlagRequired("from")
+	_ = migrateCmd.MarkFlagRequired("to")
+	searchCmd.AddCommand(migrateCmd)
+
+	backfillCmd := &cobra.Command{
+		Use:   "backfill-bm25",
+		Short: "Index review chunks for keyword search and count them into the BM25 statistics",
+		Long: `Fill the full-text vector of review chunks indexed before the Postgres
+backend kept BM25 statistics, counting each chunk into them, one batch per
+transaction. Until a chunk is filled, keyword search does not match it and
+the statistics leave it out. Safe to run while indexing, and to re-run: it
+stops when every chunk is counted.`,
+		Args: cobra.NoArgs,
+		RunE: runSearchBackfillBM25,
+	}
+	backfillCmd.Flags().String("postgres-dsn", "", "PostgreSQL connection string (or POSTGRES_DSN env)")
+	backfillCmd.Flags().Int("batch-size", 1000, "Review chunks per transaction")
+	searchCmd.AddCommand(backfillCmd)
+
+	updatedAtCmd := &cobra.Command{
+		Use:   "backfill-review-updated-at",
+		Short: "Give reviews stored before migration 000022 their created_at as updated_at",
+		Long: `Set updated_at to created_at on reviews stored before the column existed,
+one batch per statement. Until every review has one, the reviews table
+cannot be read as an index source (index --source postgres). Safe to run
+while indexing, and to re-run: it stops when no review is left.`,
+		Args: cobra.NoArgs,
+		RunE: runSearchBackfillReviewUpdatedAt,
+	}
+	updatedAtCmd.Flags().String("postgres-dsn", "", "PostgreSQL connection string (or POSTGRES_DSN env)")
+	updatedAtCmd.Flags().Int("batch-size", 10000, "Reviews updated per statement")
+	searchCmd.AddCommand(updatedAtCmd)
+
+	locationCmd := &cobra.Command{
+		Use:   "backfill-menu-location",
+		Short: "Give Weaviate menu items stored without coordinates their restaurant's location",
+		Long: `Set the location of Weaviate menu items stored without one, from the
+latitude and longitude of their row in the restaurants table, so geo-filtered
+menu search finds them. Items are re-upserted with the vectors they have;
+nothing is re-embedded. Items whose restaurant has no coordinates are left
+alone. Safe to re-run.`,
+		Args: cobra.NoArgs,
+		RunE: runSearchBackfillMenuLocation,
+	}
+	locationCmd.Flags().String("postgres-dsn", "", "PostgreSQL connection string (or POSTGRES_DSN env)")
+	locationCmd.Flags().String("weaviate", "localhost:8090", "Weaviate host:port")
+	locationCmd.Flags().String("weaviate-scheme", "http", "Weaviate scheme (http or https)")
+	locationCmd.Flags().String("weaviate-api-key", "", "Weaviate API Key (for Weaviate Cloud)")
+	locationCmd.Flags().Int("batch-size", search.DefaultMigrationBatchSize, "Menu items read and written per batch")
+	searchCmd.AddCommand(locationCmd)
+}
+
+func runSearchBackfillMenuLocation(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	dsn := viper.GetString("postgres-dsn")
+	if dsn == "" {
+		return fmt.Errorf("must specify --postgres-dsn")
+	}
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return fmt.Errorf("connect to db: %w", err)
+	}
+	defer pool.Close()
+	restaurants := menusearch.NewStore(pool)
+
+	wv, err := search.NewClient(viper.GetString("weaviate"), viper.GetString("weaviate-scheme"), viper.GetString("weaviate-api-key"), nil)
+	if err != nil {
+		return fmt.Errorf("weaviate client: %w", err)
+	}
+	n, err := wv.BackfillMenuLocations(ctx, max(viper.GetInt("batch-size"), 1), func(ctx context.Context, id uuid.UUID) (*float64, *float64, error) {
+		r, err := restaurants.GetByID(ctx, id)
+		if err != nil || r == nil {
+			return nil, nil, err
+		}
+		return r.Latitude, r.Longitude, nil
+	})
+	if err != nil {
+		return fmt.Errorf("after %d menu items: %w", n, err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "set the location of %d menu items\n", n)
+	return nil
+}
+
+func runSearchBackfillBM25(cmd *cobra.Command, _ []string) error {
+	dsn := viper.GetString("postgres-dsn")
+	if dsn == "" {
+		return fmt.Errorf("must specify --postgres-dsn")
+	}
+	sc, err := search.NewPostgresClient(dsn, nil)
+	if err != nil {
+		return fmt.Errorf("connect to db: %w", err)
+	}
+	defer func() { _ = sc.Close() }()
+
+	batch := max(viper.GetInt("batch-size"), 1)
+	total := 0
+	for {
+		n, err := sc.BackfillCorpusStats(cmd.Context(), batch)
+		if err != nil {
+			return fmt.Errorf("after %d chunks: %w", total, err)
+		}
+		if n == 0 {
+			break
+		}
+		total += n
+		slog.Info("bm25 backfill", "chunks", total)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "counted %d review chunks into the BM25 statistics\n", total)
+	return nil
+}
+
+func runSearchBackfillReviewUpdatedAt(cmd *cobra.Command, _ []string) error {
+	dsn := viper.GetString("postgres-dsn")
+	if dsn == "" {
+		return fmt.Errorf("must specify --postgres-dsn")
+	}
+	sc, err := search.NewPostgresClient(dsn, nil)
+	if err != nil {
+		return fmt.Errorf("connect to db: %w", err)
+	}
+	defer func() { _ = sc.Close() }()
+
+	batch := max(viper.GetInt("batch-size"), 1)
+	total := 0
+	for {
+		n, err := sc.BackfillReviewUpdatedAt(cmd.Context(), batch)
+		if err != nil {
+			return fmt.Errorf("after %d reviews: %w", total, err)
+		}
+		if n == 0 {
+			break
+		}
+		total += n
+		slog.Info("review updated_at backfill", "reviews", total)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "set updated_at on %d reviews\n", total)
+	return nil
+}
+
+func runSearchMigrate(cmd *cobra.Command, _ []string) error {
+	from, fromName, closeFrom, err := openSearchBackend(viper.GetString("from"), nil, false)
+	if err != nil {
+		return fmt.Errorf("--from: %w", err)
+	}
+	defer closeFrom()
+	to, toName, closeTo, err := openSearchBackend(viper.GetString("to"), nil, true)
+	if err != nil {
+		return fmt.Errorf("--to: %w", err)
+	}
+	defer closeTo()
+	if fromName == toName {
+		return fmt.Errorf("--from and --to are both %s", fromName)
+	}
+
+	m := &search.Migration{
+		From:        from,
+		To:          to,
+		FromName:    fromName,
+		ToName:      toName,
+		Collections: viper.GetStringSlice("collections"),
+		BatchSize:   viper.GetInt("batch-size"),
+		Checkpoint:  viper.GetString("checkpoint"),
+		FromModel:   viper.GetString("from-model"),
+	}
+	results, err := m.Run(cmd.Context())
+
+	out := cmd.OutOrStdout()
+	var mismatched []string
+	for _, r := range results {
+		if r.Unsupported != "" {
+			fmt.Fprintf(out, "%-11s skipped: %s\n", r.Collection, r.Unsupported)
+			continue
+		}
+		status := "ok"
+		switch {
+		case r.Source < 0 || r.Destination < 0:
+			status = "not verified (counts unavailable)"
+		case !r.Verified:
+			status = "MISMATCH"
+			mismatched = append(mismatched, r.Collection)
+		}
+		fmt.Fprintf(out, "%-11s copied=%d skipped=%d source_vectors=%d destination_vectors=%d %s\n",
+			r.Collection, r.Copied, r.Skipped, r.Source, r.Destination, status)
+	}
+	if err != nil {
+		return err
+	}
+	if len(mismatched) > 0 {
+		return fmt.Errorf("vector counts differ for %s (a destination that already held data, or Pinecone's stats still catching up, also causes this)",
+			strings.Join(mismatched, ", "))
+	}
+	return nil
+}
+
+// openSearchBackend connects the backend spec names, "kind" or
+// "kind:target", embedding queries with embedder (nil for a migration, which
+// copies stored vectors). It returns the client, a name identifying it
+// without credentials, and a function closing it. loadStats loads a
+// Pinecone backend's BM25 corpus statistics, which only its writes and
+// queries use.
+func openSearchBackend(spec string, embedder search.Embedder, loadStats bool) (any, string, func(), error) {
+	kind, target, _ := strings.Cut(spec, ":")
+	noop := func() {}
+	switch kind {
+	case "weaviate":
+		host, scheme := viper.GetString("weaviate"), viper.GetString("weaviate-scheme")
+		if target != "" {
+			host = target
+			if s, h, ok := strings.Cut(target, "://"); ok {
+				scheme, host = s, h
+			}
+		}
+		c, err := search.NewClient(host, scheme, viper.GetString("weaviate-api-key"), embedder)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("weaviate client: %w", err)
+		}
+		return c, "weaviate:" + scheme + "://" + host, noop, nil
+	case "postgres":
+		dsn := target
+		if dsn == "" {
+			dsn = viper.GetString("postgres-dsn")
+		}
+		if dsn == "" {
+			return nil, "", nil, fmt.Errorf("must specify --postgres-dsn")
+		}
+		c, err := search.NewPostgresClient(dsn, embedder)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("postgres client: %w", err)
+		}
+		return c, "postgres:" + redactDSN(dsn), func() { _ = c.Close() }, nil
+	case "pinecone":
+		host := target
+		if host == "" {
+			host = viper.GetString("pinecone-index-host")
+		}
+		apiKey := viper.GetString("pinecone-api-key")
+		if host == "" || apiKey == "" {
+			return nil, "", nil, fmt.Errorf("must specify --pinecone-api-key and --pinecone-index-host")
+		}
+		c := search.NewPineconeClient(apiKey, host, embedder)
+		if statsPath := viper.GetString("pinecone-bm25-stats"); loadStats && statsPath != "" {
+			if err := c.LoadCorpusStats(statsPath); err != nil {
+				return nil, "", nil, err
+			}
+		}
+		return c, "pinecone:" + host, noop, nil
+	case "local":
+		dir := target
+		if dir == "" {
+			dir = viper.GetString("local-index")
+		}
+		if dir == "" {
+			return nil, "", nil, fmt.Errorf("must specify --local-index")
+		}
+		c, err := search.NewLocalClient(dir, embedder)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("local index: %w", err)
+		}
+		return c, "local:" + dir, func() { _ = c.Close() }, nil
+	}
+	return nil, "", nil, fmt.Errorf("unknown backend %q (want weaviate, postgres, pinecone or local)", kind)
+}
+
+// redactDSN identifies a Postgres DSN without its password: URL DSNs are
+// redacted, key=value ones hashed.
+func redactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		return u.Redacted()
+	}
+	sum := sha256.Sum256([]byte(dsn))
+	return hex.EncodeToString(sum[:8])
+}
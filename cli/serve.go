@@ -9,8 +9,12 @@ import (
 
 	"fodmap/auth"
 	"fodmap/chat"
+	"fodmap/crawlpolicy"
+	"fodmap/egress"
 	"fodmap/fodmap/store"
+	"fodmap/menusearch"
 	"fodmap/menutracking"
+	"fodmap/pipeline"
 	"fodmap/scraper"
 	"fodmap/search"
 	"fodmap/server"
@@ -19,6 +23,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"golang.org/x/time/rate"
 )
 
 var serveCmd = &cobra.Command{
@@ -55,17 +60,23 @@ var serveCmd = &cobra.Command{
 		postgresSearch := viper.GetBool("postgres-search")
 		enablePipeline := viper.GetBool("enable-pipeline")
 
-		embedder, embedErr := search.NewEmbedder(cmd.Context(), search.EmbedderConfig{
+		embedderCfg := search.EmbedderConfig{
 			Type:          viper.GetString("embedder"),
 			OllamaURL:     viper.GetString("ollama-url"),
 			OllamaModel:   viper.GetString("ollama-model"),
 			TEIURL:        viper.GetString("tei-url"),
 			TEIModel:      viper.GetString("tei-model"),
 			VectorizerURL: viper.GetString("vectorizer-url"),
-		})
+			Dim:           viper.GetInt("embedding-dim"),
+		}
+		embedder, embedErr := search.NewEmbedder(cmd.Context(), embedderCfg)
 		if embedErr != nil {
 			return fmt.Errorf("building embedder: %w", embedErr)
 		}
+		embedder, embedCache, embedErr := withEmbeddingCache(embedder, embedderCfg)
+		if embedErr != nil {
+			return embedErr
+		}
 		defer func() { _ = embedder.Close() }()
 		slog.Info("embedder ready", "type", viper.GetString("embedder"))
 		if jwtSecret == "" {
@@ -118,31 +129,42 @@ var serveCmd = &cobra.Command{
 		}
 
 		srv, err := server.New(context.Background(), server.Config{
-			Port:                port,
-			WeaviateHost:        weaviateHost,
-			WeaviateScheme:      weaviateScheme,
-			WeaviateAPIKey:      weaviateAPIKey,
-			PostgresSearch:      postgresSearch,
-			PostgresDSN:         postgresDSN,
-			CatalogStore:        catalogStore,
-			GoogleCloudProject:  os.Getenv("GOOGLE_CLOUD_PROJECT"),
-			GoogleCloudLocation: os.Getenv("GOOGLE_CLOUD_LOCATION"),
-			ChatModel:           chatModel,
-			FilterModel:         filterModel,
-			ChatAPIKey:          chatAPIKey,
-			CORSAllowedOrigins:  corsOrigins,
-			UserStore:           userStore,
-			JWTSecret:           jwtSecret,
-			AdminEmail:          adminEmail,
-			PineconeAPIKey:      pineconeAPIKey,
-			PineconeIndexHost:   pineconeIndexHost,
-			VectorizerURL:       vectorizerURL,
-			Embedder:            embedder,
-			MenuStoreType:       viper.GetString("menu-store"),
+			Port:                   port,
+			LocalIndexDir:          viper.GetString("local-index"),
+			WeaviateHost:           weaviateHost,
+			WeaviateScheme:         weaviateScheme,
+			WeaviateAPIKey:         weaviateAPIKey,
+			PostgresSearch:         postgresSearch,
+			PostgresDSN:            postgresDSN,
+			CatalogStore:           catalogStore,
+			GoogleCloudProject:     os.Getenv("GOOGLE_CLOUD_PROJECT"),
+			GoogleCloudLocation:    os.Getenv("GOOGLE_CLOUD_LOCATION"),
+			ChatModel:              chatModel,
+			FilterModel:            filterModel,
+			ChatAPIKey:             chatAPIKey,
+			CORSAllowedOrigins:     corsOrigins,
+			UserStore:              userStore,
+			JWTSecret:              jwtSecret,
+			AdminEmail:             adminEmail,
+			PineconeAPIKey:         pineconeAPIKey,
+			PineconeIndexHost:      pineconeIndexHost,
+			PineconeBM25Stats:      viper.GetString("pinecone-bm25-stats"),
+			VectorizerURL:          vectorizerURL,
+			Embedder:               embedder,
+			MenuStoreType:          viper.GetString("menu-store"),
+			SecondarySearch:        viper.GetString("secondary-search"),
+			SecondaryLocalIndexDir: viper.GetString("secondary-local-index"),
+			ShadowRead:             viper.GetBool("shadow-read"),
+			Retrieval:              newRetrievalPipeline(),
+			ParseQueries:           viper.GetBool("parse-queries"),
+			ParseQueriesLLM:        viper.GetBool("parse-queries-llm"),
 		})
 		if err != nil {
 			return fmt.Errorf("initializing server: %w", err)
 		}
+		if embedCache != nil {
+			srv.SetEmbeddingCache(embedCache)
+		}
 
 		// Start the menutracking pipeline if enabled. The pipeline shares the
 		// server's lifecycle: when srv.Start() returns (on SIGTERM or error),
@@ -152,11 +174,40 @@ var serveCmd = &cobra.Command{
 			if postgresDSN == "" {
 				return fmt.Errorf("postgres-dsn is required when --enable-pipeline is set")
 			}
-			fetcher := scraper.NewHTTPFetcher(true) // ignore robots for regulatory sources
+			// One crawl policy for every pipeline worker so menusearch and
+			// menutracking scrapes of the same host share robots.txt, the
+			// per-host token bucket and the daily budget.
+			domainBudgets, err := cmd.Flags().GetStringToInt("crawl-domain-budget")
+			if err != nil {
+				return fmt.Errorf("parsing --crawl-domain-budget: %w", err)
+			}
+			// One egress pool for the same reason: exit health and per-domain
+			// block rates are only meaningful if every fetcher reports into it.
+			egressPool, err := newEgressPool()
+			if err != nil {
+				return err
+			}
+			policy := scraper.NewCrawlPolicy(crawlpolicy.Config{
+				IgnoreRobots:  viper.GetBool("ignore-robots"),
+				Rate:          rate.Limit(viper.GetFloat64("crawl-rate")),
+				DailyBudget:   viper.GetInt("crawl-daily-budget"),
+				DomainBudgets: domainBudgets,
+			})
+			var fetcher scraper.Fetcher = scraper.NewHTTPFetcherWithEgress(policy, egressPool)
+			// Regulatory sources are fetched regardless of robots.txt, as
+			// they always were, but still under the shared rate limits.
+			var sourceFetcher scraper.Fetcher = scraper.NewHTTPFetcherWithEgress(policy.WithoutRobots(), egressPool)
+			// Revalidate previously fetched pages with conditional GETs so
+			// unchanged menus skip LLM extraction on re-scrape.
+			if cacheDir := viper.GetString("http-cache-dir"); cacheDir != "" {
+				cache := scraper.NewHTTPCache(cacheDir)
+				fetcher = scraper.NewCachingFetcher(fetcher, cache)
+				sourceFetcher = scraper.NewCachingFetcher(sourceFetcher, cache)
+			}
 
 			// Build a VectorSink from the server's Searcher if available.
 			var vectorSink menutracking.VectorSink
-			if vs, ok := srv.Searcher().(menutracking.VectorSink); ok {
+			if vs, ok := server.UnwrapSearcher(srv.Searcher()).(menutracking.VectorSink); ok {
 				vectorSink = vs
 			}
 
@@ -195,9 +246,13 @@ var serveCmd = &cobra.Command{
 			var menuStore server.MenuStore
 			if ms := srv.MenuStore(); ms != nil {
 				menuStore = ms
-			} else if ms, ok := srv.Searcher().(server.MenuStore); ok {
+			} else if ms, ok := server.UnwrapSearcher(srv.Searcher()).(server.MenuStore); ok {
 				menuStore = ms
 			}
+			// Scraped menus are embedded with the menu collection's active
+			// embedding space when the menu store is (or writes first to)
+			// Postgres.
+			menuEmbedder := server.MenuEmbedder(menuStore, embedder)
 
 			extractorURL := viper.GetString("extractor-url")
 			var extractor scraper.Extractor
@@ -205,14 +260,30 @@ var serveCmd = &cobra.Command{
 				extractor = scraper.NewServiceExtractor(extractorURL, 30*time.Second, 120*time.Second)
 			}
 
+			refreshPolicy, err := newRefreshPolicy(cmd)
+			if err != nil {
+				return err
+			}
+			translator, err := newMenuTranslator()
+			if err != nil {
+				return err
+			}
+
+			ruleMinPassRate := viper.GetFloat64("rule-min-pass-rate")
+			if ruleMinPassRate < 0 || ruleMinPassRate > 1 {
+				return fmt.Errorf("--rule-min-pass-rate must be between 0 and 1")
+			}
+
 			var pipelineErr error
 			pipelineResult, pipelineErr = StartMenutrackingPipeline(cmd.Context(), PipelineConfig{
 				DSN:                       postgresDSN,
 				Fetcher:                   fetcher,
+				SourceFetcher:             sourceFetcher,
+				Egress:                    egressPool,
 				VectorSink:                vectorSink,
 				ChatBackend:               chatBackend,
 				MenuStore:                 menuStore,
-				Embedder:                  embedder,
+				Embedder:                  menuEmbedder,
 				GenAIClient:               genAIClient,
 				Extractor:                 extractor,
 				DiscoveryAvroDestDir:      viper.GetString("discovery-avro-dir"),
@@ -225,11 +296,18 @@ var serveCmd = &cobra.Command{
 				WebagentAdapter:           viper.GetString("webagent-adapter"),
 				BronzeDir:                 viper.GetString("restaurant-bronze-dir"),
 				ScrapeMaxAttempts:         viper.GetInt("scrape-max-attempts"),
+				RuleMinPassRate:           &ruleMinPassRate,
+				RefreshInterval:           viper.GetDuration("refresh-interval"),
+				RefreshPolicy:             refreshPolicy,
+				Quality:                   newQualityPolicy(),
+				Translator:                translator,
 			})
 			if pipelineErr != nil {
 				return fmt.Errorf("starting menutracking pipeline: %w", pipelineErr)
 			}
 
+			srv.SetEgressStats(egressPool)
+
 			// Wire menutracking admin endpoints using the pipeline's pool.
 			srv.SetMenutrackingAdmin(&menutracking.AdminHandler{Pool: pipelineResult.Pool})
 
@@ -242,6 +320,14 @@ var serveCmd = &cobra.Command{
 			}
 		}
 
+		// Geographic areas: a YAML file when --areas-file is set, else the
+		// geo_areas table when the pipeline's database is available.
+		if areasFile := viper.GetString("areas-file"); areasFile != "" {
+			srv.SetAreaStore(menusearch.NewFileAreaStore(areasFile))
+		} else if pipelineResult != nil {
+			srv.SetAreaStore(menusearch.NewAreaStore(pipelineResult.Pool))
+		}
+
 		// Start the HTTP server (blocks until SIGTERM or error).
 		if err := srv.Start(); err != nil {
 			if pipelineResult != nil {
@@ -278,12 +364,25 @@ func init() {
 	serveCmd.Flags().String("postgres-dsn", "", "PostgreSQL connection string (required)")
 	serveCmd.Flags().String("admin-email", "", "Email of the user to promote to admin on startup")
 	serveCmd.Flags().Bool("postgres-search", false, "Use PostgreSQL (pgvector) for vector search instead of Weaviate/Pinecone")
-	serveCmd.Flags().String("menu-store", "", "Menu store backend: postgres | weaviate | dual (empty = fall back to --postgres-search / weaviate selection)")
+	serveCmd.Flags().String("menu-store", "", "Menu store backend: postgres | weaviate | dual | local (empty = fall back to --postgres-search / weaviate selection)")
+	serveCmd.Flags().String("secondary-search", "", "Second search backend review and FODMAP writes are mirrored to best-effort while the primary serves reads: postgres | weaviate | pinecone | local (empty disables)")
+	serveCmd.Flags().String("secondary-local-index", "", "Directory of the embedded backend when --secondary-search=local; it must differ from --local-index")
+	serveCmd.Flags().Bool("shadow-read", false, "Repeat searches against --secondary-search in the background and log ranking divergence (overlap@k, rank correlation); responses are unaffected")
+	serveCmd.Flags().String("local-index", "", "Directory of the embedded on-disk search backend; when set it is used instead of Postgres/Pinecone/Weaviate search (no service needed)")
 	serveCmd.Flags().String("jwt-secret", "", "Secret key for JWT signing (or use JWT_SECRET env var)")
 	serveCmd.Flags().String("pinecone-api-key", "", "Pinecone API Key")
 	serveCmd.Flags().String("pinecone-index-host", "", "Pinecone Index Host (e.g. https://index-name.svc.pinecone.io)")
+	serveCmd.Flags().String("pinecone-bm25-stats", search.DefaultPineconeBM25StatsPath, "BM25 corpus statistics file written by `index` for Pinecone hybrid review search (empty ignores term rarity)")
+	serveCmd.Flags().Bool("rrf", false, "Retrieve dense and lexical candidates separately and fuse them by reciprocal-rank fusion for review, business and menu search")
+	serveCmd.Flags().Int("rrf-candidates", search.DefaultFusionCandidates, "Candidates each retriever contributes to fusion and reranking")
+	serveCmd.Flags().String("rerank-url", "", "Text Embeddings Inference (TEI) cross-encoder service URL whose /rerank re-scores the top candidates; empty disables reranking")
+	serveCmd.Flags().Int("rerank-top-n", search.DefaultRerankTopN, "Candidates the cross-encoder re-scores (at least the requested limit)")
+	serveCmd.Flags().Bool("parse-queries", true, "Read location, cuisine, diet, avoided ingredients, price and FODMAP level out of free-text search and chat queries into filters; explicit query parameters still win")
+	serveCmd.Flags().Bool("parse-queries-llm", false, "Ask the filter model (--filter-model) to resolve places the query parser's gazetteer does not know; needs the chat configuration")
+	serveCmd.Flags().Int("embedding-dim", search.ExpectedEmbeddingDim, "Vector dimension the embedding model returns; Postgres collections cut over to another embedding space use that space's recorded embedder instead")
+	addEmbeddingCacheFlags(serveCmd)
 	serveCmd.Flags().String("vectorizer-url", "", "Base URL for the HTTP vectorizer-proxy (used when --embedder=vectorizer)")
-	serveCmd.Flags().String("embedder", "ollama", "Embedding backend: ollama | tei | vectorizer")
+	serveCmd.Flags().String("embedder", "ollama", "Embedding backend: ollama | tei | vectorizer | hash (deterministic, no service; for local development)")
 	serveCmd.Flags().String("ollama-url", "http://localhost:11434", "Ollama server URL")
 	serveCmd.Flags().String("ollama-model", "nomic-embed-text", "Ollama embedding model")
 	serveCmd.Flags().String("tei-url", "", "Text Embeddings Inference (TEI) service URL (used when --embedder=tei)")
@@ -300,8 +399,113 @@ func init() {
 	serveCmd.Flags().String("webagent-adapter", "", "Webagent adapter (site/target) for JS-rendered menus via Python scraper service")
 	serveCmd.Flags().String("restaurant-bronze-dir", "data/bronze/restaurants", "Directory for raw HTML bronze files from restaurant scrape jobs")
 	serveCmd.Flags().Int("scrape-max-attempts", 3, "Max attempts for scraping and discovery jobs in the pipeline")
+	serveCmd.Flags().Bool("ignore-robots", false, "Skip robots.txt Disallow checks in the restaurant menu fetcher (Crawl-delay is still honoured; regulatory sources always skip them)")
+	serveCmd.Flags().Float64("crawl-rate", 1, "Default pipeline requests per second per host; robots.txt Crawl-delay can only lower it")
+	serveCmd.Flags().Int("crawl-daily-budget", 500, "Max pipeline requests per domain per UTC day (0 = unlimited)")
+	serveCmd.Flags().StringToInt("crawl-domain-budget", nil, "Per-domain daily budget overrides, e.g. health.ny.gov=50")
+	serveCmd.Flags().StringSlice("egress-proxy", nil, "Proxy URLs (http, https, socks5) the pipeline fetches through, round-robin; empty fetches directly")
+	serveCmd.Flags().StringSlice("egress-user-agent", nil, "User-Agent profiles assigned to egress exits round-robin; empty sends the scraper's own User-Agent")
+	serveCmd.Flags().Duration("egress-cooldown", egress.DefaultCooldown, "How long an exit rests for a domain after a 403/429; doubles on consecutive blocks")
+	serveCmd.Flags().String("areas-file", "", "YAML file of geographic area definitions for the admin area API; defaults to the geo_areas table when the pipeline runs")
+	serveCmd.Flags().String("http-cache-dir", scraper.DefaultHTTPCacheDir, "Directory for the pipeline's HTTP content cache (ETag/Last-Modified revalidation); empty disables")
+	serveCmd.Flags().Float64("min-extraction-quality", pipeline.DefaultQualityPolicy().MinScore, "Quarantine menu extractions scoring below this grounding/quality score for review instead of storing them (0 scores but never quarantines)")
+	serveCmd.Flags().String("translate-llm-url", "", "OpenAI-compatible LLM endpoint used to translate non-English menus to English (include version segment); empty disables translation")
+	serveCmd.Flags().String("translate-llm-model", "qwen3-vl", "LLM model used for menu translation")
+	serveCmd.Flags().String("translate-llm-api-key", "", "API key for the translation LLM endpoint")
+	serveCmd.Flags().Duration("refresh-interval", time.Hour, "How often the pipeline queues re-scrapes of stale menus (0 disables)")
+	serveCmd.Flags().Int("refresh-daily-budget", 200, "Max scrape jobs per UTC day across the pipeline; scheduled re-scrapes use what is left (0 = unlimited)")
+	serveCmd.Flags().Duration("refresh-stale-after", 30*24*time.Hour, "Re-scrape a scraped menu this long after its last scrape")
+	serveCmd.Flags().Duration("refresh-failed-after", 7*24*time.Hour, "Retry a failed scrape this long after the failure (doubling on repeated failures)")
+	serveCmd.Flags().Duration("refresh-permanent-failure-after", 60*24*time.Hour, "Retry a permanently failed restaurant this long after the failure (0 = never)")
+	serveCmd.Flags().StringToString("refresh-tier-stale-after", nil, "Per-extraction-tier staleness overrides for scraped menus, e.g. jsonld=336h,webagent=1008h")
+	serveCmd.Flags().Float64("rule-min-pass-rate", menutracking.DefaultMinPassRate, "Fraction of bronze snapshots a proposed extraction rule must pass before promotion (0 turns the check off)")
 
 	_ = viper.BindPFlags(serveCmd.Flags())
 	_ = viper.BindEnv("admin-email", "ADMIN_EMAIL")
 	_ = viper.BindEnv("postgres-dsn", "POSTGRES_DSN")
 }
+
+// newRefreshPolicy builds the menu refresh policy from the --refresh-*
+// flags. The per-run cap spreads the daily budget evenly over the runs in a
+// day.
+func newRefreshPolicy(cmd *cobra.Command) (menusearch.RefreshPolicy, error) {
+	p := menusearch.DefaultRefreshPolicy()
+	tiers, err := cmd.Flags().GetStringToString("refresh-tier-stale-after")
+	if err != nil {
+		return p, err
+	}
+	p.DailyBudget = viper.GetInt("refresh-daily-budget")
+	p.StaleAfter[menusearch.StatusScraped] = viper.GetDuration("refresh-stale-after")
+	p.StaleAfter[menusearch.StatusFailedScrape] = viper.GetDuration("refresh-failed-after")
+	p.StaleAfter[menusearch.StatusFailedPermanently] = viper.GetDuration("refresh-permanent-failure-after")
+	for tier, v := range tiers {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return p, fmt.Errorf("--refresh-tier-stale-after %s=%q: want a positive duration such as 336h", tier, v)
+		}
+		p.TierStaleAfter[tier] = d
+	}
+	if interval := viper.GetDuration("refresh-interval"); interval > 0 && p.DailyBudget > 0 {
+		runs := max(int((24*time.Hour)/interval), 1)
+		p.MaxPerRun = max((p.DailyBudget+runs-1)/runs, 1)
+	}
+	return p, nil
+}
+
+// newQualityPolicy builds the extraction quality policy from
+// --min-extraction-quality.
+func newQualityPolicy() *pipeline.QualityPolicy {
+	p := pipeline.DefaultQualityPolicy()
+	p.MinScore = viper.GetFloat64("min-extraction-quality")
+	return &p
+}
+
+// newMenuTranslator builds the pipeline's menu translator from the
+// --translate-llm-* flags; nil when no endpoint is configured.
+func newMenuTranslator() (scraper.MenuTranslator, error) {
+	url := viper.GetString("translate-llm-url")
+	if url == "" {
+		return nil, nil
+	}
+	tr, err := scraper.NewOpenAICompatExtractor(url, viper.GetString("translate-llm-model"), viper.GetString("translate-llm-api-key"), "none")
+	if err != nil {
+		return nil, fmt.Errorf("building menu translator: %w", err)
+	}
+	return tr, nil
+}
+
+// newRetrievalPipeline builds the search retrieval pipeline from the --rrf
+// and --rerank-* flags, or returns nil when neither fusion nor reranking is
+// enabled.
+func newRetrievalPipeline() *search.RetrievalPipeline {
+	fuse, rerankURL := viper.GetBool("rrf"), viper.GetString("rerank-url")
+	if !fuse && rerankURL == "" {
+		return nil
+	}
+	p := &search.RetrievalPipeline{
+		Fuse:       fuse,
+		Candidates: viper.GetInt("rrf-candidates"),
+		RerankTopN: viper.GetInt("rerank-top-n"),
+	}
+	if rerankURL != "" {
+		p.Reranker = search.NewTEIReranker(rerankURL)
+	}
+	return p
+}
+
+// newEgressPool builds the pipeline's egress pool from the --egress-* flags.
+func newEgressPool() (*egress.Pool, error) {
+	var profiles []egress.Profile
+	for _, ua := range viper.GetStringSlice("egress-user-agent") {
+		profiles = append(profiles, egress.Profile{UserAgent: ua})
+	}
+	pool, err := egress.New(egress.Config{
+		Proxies:  viper.GetStringSlice("egress-proxy"),
+		Profiles: profiles,
+		Cooldown: viper.GetDuration("egress-cooldown"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("building egress pool: %w", err)
+	}
+	return pool, nil
+}
@@ -0,0 +1,79 @@
+package crawlpolicy
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrBudgetExhausted is returned when a domain has used its daily request
+// budget. Callers should retry after the next UTC midnight.
+var ErrBudgetExhausted = errors.New("daily crawl budget exhausted")
+
+// Budget caps the number of requests per domain per UTC day. Counts live in
+// memory and reset at midnight UTC; a restart resets them too, which errs on
+// the side of the site only when the process restarts mid-day.
+type Budget struct {
+	mu        sync.Mutex
+	limit     int
+	overrides map[string]int
+	day       string
+	counts    map[string]int
+	now       func() time.Time
+}
+
+// NewBudget returns a Budget allowing limit requests per domain per day, with
+// per-domain overrides. A limit (or override) of zero or less means unlimited.
+func NewBudget(limit int, overrides map[string]int) *Budget {
+	return &Budget{
+		limit:     limit,
+		overrides: overrides,
+		counts:    make(map[string]int),
+		now:       time.Now,
+	}
+}
+
+// Take consumes one request from domain's budget. It returns an error
+// wrapping ErrBudgetExhausted if none remain today.
+func (b *Budget) Take(domain string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if today := b.now().UTC().Format(time.DateOnly); today != b.day {
+		b.day = today
+		clear(b.counts)
+	}
+	limit := b.limit
+	if v, ok := b.overrides[domain]; ok {
+		limit = v
+	}
+	if limit <= 0 {
+		return nil
+	}
+	if b.counts[domain] >= limit {
+		return fmt.Errorf("%w: %s used %d requests today", ErrBudgetExhausted, domain, limit)
+	}
+	b.counts[domain]++
+	return nil
+}
+
+// Refund returns one request taken today to domain's budget, for a request
+// that was abandoned before it was sent.
+func (b *Budget) Refund(domain string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.now().UTC().Format(time.DateOnly) == b.day && b.counts[domain] > 0 {
+		b.counts[domain]--
+	}
+}
+
+// Used returns the number of requests domain has made today.
+func (b *Budget) Used(domain string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.now().UTC().Format(time.DateOnly) != b.day {
+		return 0
+	}
+	return b.counts[domain]
+}
@@ -0,0 +1,52 @@
+package crawlpolicy
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBudget_Take(t *testing.T) {
+	now := time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC)
+	b := NewBudget(2, map[string]int{"health.state.gov": 1, "unlimited.example": 0})
+	b.now = func() time.Time { return now }
+
+	for i := range 2 {
+		if err := b.Take("example.com"); err != nil {
+			t.Fatalf("Take #%d: %v", i+1, err)
+		}
+	}
+	if err := b.Take("example.com"); !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("third Take = %v, want ErrBudgetExhausted", err)
+	}
+	if got := b.Used("example.com"); got != 2 {
+		t.Errorf("Used = %d, want 2", got)
+	}
+
+	if err := b.Take("health.state.gov"); err != nil {
+		t.Fatalf("override Take: %v", err)
+	}
+	if err := b.Take("health.state.gov"); !errors.Is(err, ErrBudgetExhausted) {
+		t.Errorf("override second Take = %v, want ErrBudgetExhausted", err)
+	}
+	for range 5 {
+		if err := b.Take("unlimited.example"); err != nil {
+			t.Fatalf("unlimited override Take: %v", err)
+		}
+	}
+
+	// Budgets reset at midnight UTC.
+	now = now.Add(2 * time.Hour)
+	if err := b.Take("example.com"); err != nil {
+		t.Errorf("Take after midnight: %v", err)
+	}
+}
+
+func TestBudget_Unlimited(t *testing.T) {
+	b := NewBudget(0, nil)
+	for range 100 {
+		if err := b.Take("example.com"); err != nil {
+			t.Fatalf("Take: %v", err)
+		}
+	}
+}
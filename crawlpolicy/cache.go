@@ -0,0 +1,94 @@
+package crawlpolicy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// maxRobotsBytes is the largest robots.txt body we parse. RFC 9309 requires
+// crawlers to parse at least 500 KiB.
+const maxRobotsBytes = 512 * 1024
+
+// RobotsCache fetches robots.txt once per host and caches the parsed result
+// for TTL. A robots.txt that is missing (4xx) or unreachable (network error,
+// 5xx) is treated as allowing everything; unreachable results are cached only
+// for ErrorTTL so a transient outage is retried soon.
+type RobotsCache struct {
+	client    *http.Client
+	userAgent string
+	ttl       time.Duration
+	errorTTL  time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]robotsEntry
+}
+
+// robotsEntry is a cached robots.txt with its expiry.
+type robotsEntry struct {
+	robots  *Robots
+	expires time.Time
+}
+
+// NewRobotsCache returns a cache that fetches robots.txt with client, sending
+// userAgent. ttl bounds how long a fetched file is trusted; errorTTL bounds
+// how long an unreachable file is treated as allow-all.
+func NewRobotsCache(client *http.Client, userAgent string, ttl, errorTTL time.Duration) *RobotsCache {
+	return &RobotsCache{
+		client:    client,
+		userAgent: userAgent,
+		ttl:       ttl,
+		errorTTL:  errorTTL,
+		now:       time.Now,
+		entries:   make(map[string]robotsEntry),
+	}
+}
+
+// Robots returns the parsed robots.txt for u's scheme and host, fetching it
+// if it is not cached or has expired. It never returns nil.
+func (c *RobotsCache) Robots(ctx context.Context, u *url.URL) *Robots {
+	key := u.Scheme + "://" + u.Host
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(e.expires) {
+		return e.robots
+	}
+
+	robots, ttl := c.fetch(ctx, key+"/robots.txt")
+	c.mu.Lock()
+	c.entries[key] = robotsEntry{robots: robots, expires: c.now().Add(ttl)}
+	c.mu.Unlock()
+	return robots
+}
+
+// fetch downloads and parses robotsURL, returning the result and how long it
+// may be cached.
+func (c *RobotsCache) fetch(ctx context.Context, robotsURL string) (*Robots, time.Duration) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return &Robots{}, c.errorTTL
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return &Robots{}, c.errorTTL
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return &Robots{}, c.errorTTL
+	case resp.StatusCode != http.StatusOK:
+		return &Robots{}, c.ttl
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsBytes))
+	if err != nil {
+		return &Robots{}, c.errorTTL
+	}
+	return ParseRobots(string(body)), c.ttl
+}
@@ -0,0 +1,72 @@
+package crawlpolicy
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// HostLimiter holds one token bucket per host. Every worker that fetches
+// through the same HostLimiter shares the bucket, so politeness holds across
+// the menusearch and menutracking pipelines. The host set is bounded by the
+// sources and restaurants we scrape, so no eviction logic is needed.
+type HostLimiter struct {
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+	r        rate.Limit
+	burst    int
+}
+
+// NewHostLimiter creates an empty limiter. New buckets are created on first
+// access with the given default rate (tokens per second) and burst.
+func NewHostLimiter(r rate.Limit, burst int) *HostLimiter {
+	return &HostLimiter{
+		limiters: make(map[string]*rate.Limiter),
+		r:        r,
+		burst:    burst,
+	}
+}
+
+// Seed populates the limiter with one bucket per host at the default rate.
+func (l *HostLimiter) Seed(hosts []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, h := range hosts {
+		l.limiters[h] = rate.NewLimiter(l.r, l.burst)
+	}
+}
+
+// SetCrawlDelay slows host's bucket to one request per delay when that is
+// slower than the default rate. A delay never speeds a host up, and a zero
+// delay is ignored.
+func (l *HostLimiter) SetCrawlDelay(host string, delay time.Duration) {
+	if delay <= 0 {
+		return
+	}
+	limit := rate.Every(delay)
+	lim := l.get(host)
+	if limit < lim.Limit() {
+		lim.SetLimit(limit)
+		lim.SetBurst(1)
+	}
+}
+
+// Wait blocks until host's bucket allows a request, or the context is
+// cancelled. It is safe to call from multiple goroutines.
+func (l *HostLimiter) Wait(ctx context.Context, host string) error {
+	return l.get(host).Wait(ctx)
+}
+
+// get returns the bucket for host, creating one if it doesn't exist.
+func (l *HostLimiter) get(host string) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if lim, ok := l.limiters[host]; ok {
+		return lim
+	}
+	lim := rate.NewLimiter(l.r, l.burst)
+	l.limiters[host] = lim
+	return lim
+}
@@ -0,0 +1,52 @@
+package crawlpolicy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestHostLimiter_SeedAndReuse(t *testing.T) {
+	l := NewHostLimiter(rate.Limit(1), 1)
+	l.Seed([]string{"epa.gov", "fda.gov"})
+	if len(l.limiters) != 2 {
+		t.Fatalf("expected 2 seeded limiters, got %d", len(l.limiters))
+	}
+	if l.get("epa.gov") != l.get("epa.gov") {
+		t.Error("get created a new limiter instead of reusing the existing one")
+	}
+}
+
+func TestHostLimiter_SetCrawlDelay(t *testing.T) {
+	l := NewHostLimiter(rate.Limit(10), 5)
+
+	l.SetCrawlDelay("slow.example", 2*time.Second)
+	if got := l.get("slow.example").Limit(); got != rate.Every(2*time.Second) {
+		t.Errorf("limit after crawl-delay = %v, want %v", got, rate.Every(2*time.Second))
+	}
+
+	// A shorter delay must not speed the host back up.
+	l.SetCrawlDelay("slow.example", 10*time.Millisecond)
+	if got := l.get("slow.example").Limit(); got != rate.Every(2*time.Second) {
+		t.Errorf("limit after shorter crawl-delay = %v, want unchanged", got)
+	}
+
+	// A delay faster than the default rate is ignored.
+	l.SetCrawlDelay("fast.example", time.Millisecond)
+	if got := l.get("fast.example").Limit(); got != rate.Limit(10) {
+		t.Errorf("limit = %v, want default 10", got)
+	}
+}
+
+func TestHostLimiter_WaitContextCancelled(t *testing.T) {
+	// Burst of 0 means no token is ever available, so Wait must respect
+	// the cancelled context and return its error.
+	l := NewHostLimiter(rate.Limit(1), 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx, "epa.gov"); err == nil {
+		t.Error("expected error from Wait with cancelled context, got nil")
+	}
+}
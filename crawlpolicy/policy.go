@@ -0,0 +1,124 @@
+// Package crawlpolicy enforces the crawl politeness shared by every fetcher:
+// cached robots.txt rules with wildcard matching, per-host token buckets that
+// honour Crawl-delay, and per-domain daily request budgets. One Policy is
+// meant to be shared by all workers in a process so that menusearch and
+// menutracking scrapes of the same host draw from the same bucket.
+package crawlpolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Defaults applied by New when the corresponding Config field is zero.
+const (
+	DefaultRobotsTTL      = 24 * time.Hour
+	DefaultRobotsErrorTTL = 10 * time.Minute
+)
+
+// ErrDisallowed is returned by Policy.Acquire when robots.txt disallows the
+// requested path for our user agent.
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+// Config configures a Policy.
+type Config struct {
+	UserAgent string
+	// IgnoreRobots skips Disallow checks. Crawl-delay is still honoured.
+	IgnoreRobots   bool
+	RobotsTTL      time.Duration
+	RobotsErrorTTL time.Duration
+	// Rate is the default per-host request rate in requests per second. Zero
+	// means unlimited apart from any Crawl-delay the host declares.
+	Rate  rate.Limit
+	Burst int
+	// DailyBudget caps requests per domain per UTC day; zero is unlimited.
+	// DomainBudgets overrides it for individual domains.
+	DailyBudget   int
+	DomainBudgets map[string]int
+}
+
+// Policy decides whether and when a URL may be fetched. It is safe for
+// concurrent use.
+type Policy struct {
+	userAgent    string
+	ignoreRobots bool
+	robots       *RobotsCache
+	limiter      *HostLimiter
+	budget       *Budget
+}
+
+// New returns a Policy that fetches robots.txt with client.
+func New(client *http.Client, cfg Config) *Policy {
+	if cfg.RobotsTTL == 0 {
+		cfg.RobotsTTL = DefaultRobotsTTL
+	}
+	if cfg.RobotsErrorTTL == 0 {
+		cfg.RobotsErrorTTL = DefaultRobotsErrorTTL
+	}
+	if cfg.Rate == 0 {
+		cfg.Rate = rate.Inf
+	}
+	if cfg.Burst == 0 {
+		cfg.Burst = 1
+	}
+	return &Policy{
+		userAgent:    cfg.UserAgent,
+		ignoreRobots: cfg.IgnoreRobots,
+		robots:       NewRobotsCache(client, cfg.UserAgent, cfg.RobotsTTL, cfg.RobotsErrorTTL),
+		limiter:      NewHostLimiter(cfg.Rate, cfg.Burst),
+		budget:       NewBudget(cfg.DailyBudget, cfg.DomainBudgets),
+	}
+}
+
+// WithoutRobots returns a view of p that skips Disallow checks but shares
+// p's robots cache, host buckets and budgets. Use it for fetchers that must
+// reach disallowed paths (e.g. regulatory feeds) without escaping the shared
+// rate limits.
+func (p *Policy) WithoutRobots() *Policy {
+	q := *p
+	q.ignoreRobots = true
+	return &q
+}
+
+// UserAgent returns the User-Agent the policy evaluates robots.txt against.
+func (p *Policy) UserAgent() string { return p.userAgent }
+
+// Limiter returns the policy's shared per-host limiter.
+func (p *Policy) Limiter() *HostLimiter { return p.limiter }
+
+// Acquire blocks until u may be fetched. It checks robots.txt (unless the
+// policy ignores robots), applies the host's Crawl-delay, consumes one
+// request from the domain's daily budget, and waits for the host's token
+// bucket; a wait cut short by ctx gives the request back to the budget.
+// Errors wrap ErrDisallowed or ErrBudgetExhausted when those apply.
+func (p *Policy) Acquire(ctx context.Context, u *url.URL) error {
+	robots := p.robots.Robots(ctx, u)
+	if !p.ignoreRobots && !robots.Allowed(p.userAgent, u.RequestURI()) {
+		return fmt.Errorf("%w: %s for our user-agent (use --ignore-robots to override)", ErrDisallowed, u.RequestURI())
+	}
+	host := u.Hostname()
+	p.limiter.SetCrawlDelay(host, robots.CrawlDelay(p.userAgent))
+	domain := Domain(host)
+	if err := p.budget.Take(domain); err != nil {
+		return err
+	}
+	if err := p.limiter.Wait(ctx, host); err != nil {
+		p.budget.Refund(domain)
+		return err
+	}
+	return nil
+}
+
+// Domain normalises a hostname to the key used for daily budgets: lowercased
+// with any leading "www." removed, so www.example.com and example.com share a
+// budget.
+func Domain(host string) string {
+	return strings.TrimPrefix(strings.ToLower(host), "www.")
+}
@@ -0,0 +1,149 @@
+package crawlpolicy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// newRobotsServer serves body at /robots.txt (or status if body is empty)
+// and counts robots.txt fetches.
+func newRobotsServer(t *testing.T, status int, body string, fetches *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/robots.txt" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		fetches.Add(1)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func mustParse(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("parsing %q: %v", raw, err)
+	}
+	return u
+}
+
+func TestPolicy_AcquireRobots(t *testing.T) {
+	var fetches atomic.Int32
+	srv := newRobotsServer(t, http.StatusOK, "User-agent: *\nDisallow: /secret\nSitemap: https://x.example/sitemap.xml\n", &fetches)
+	p := New(srv.Client(), Config{UserAgent: ua})
+	ctx := context.Background()
+
+	if err := p.Acquire(ctx, mustParse(t, srv.URL+"/menu")); err != nil {
+		t.Fatalf("Acquire /menu: %v", err)
+	}
+	err := p.Acquire(ctx, mustParse(t, srv.URL+"/secret/page"))
+	if !errors.Is(err, ErrDisallowed) {
+		t.Fatalf("Acquire /secret = %v, want ErrDisallowed", err)
+	}
+	if err := p.WithoutRobots().Acquire(ctx, mustParse(t, srv.URL+"/secret/page")); err != nil {
+		t.Fatalf("WithoutRobots Acquire /secret: %v", err)
+	}
+	if got := fetches.Load(); got != 1 {
+		t.Errorf("robots.txt fetched %d times, want 1 (cached)", got)
+	}
+}
+
+func TestPolicy_RobotsCacheExpires(t *testing.T) {
+	var fetches atomic.Int32
+	srv := newRobotsServer(t, http.StatusNotFound, "", &fetches)
+	p := New(srv.Client(), Config{UserAgent: ua, RobotsTTL: time.Hour})
+	now := time.Now()
+	p.robots.now = func() time.Time { return now }
+	u := mustParse(t, srv.URL+"/menu")
+
+	for range 3 {
+		if err := p.Acquire(context.Background(), u); err != nil {
+			t.Fatalf("Acquire: %v", err)
+		}
+	}
+	now = now.Add(2 * time.Hour)
+	if err := p.Acquire(context.Background(), u); err != nil {
+		t.Fatalf("Acquire after TTL: %v", err)
+	}
+	if got := fetches.Load(); got != 2 {
+		t.Errorf("robots.txt fetched %d times, want 2", got)
+	}
+}
+
+func TestPolicy_UnreachableRobotsAllows(t *testing.T) {
+	var fetches atomic.Int32
+	srv := newRobotsServer(t, http.StatusServiceUnavailable, "User-agent: *\nDisallow: /\n", &fetches)
+	p := New(srv.Client(), Config{UserAgent: ua})
+	if err := p.Acquire(context.Background(), mustParse(t, srv.URL+"/menu")); err != nil {
+		t.Fatalf("Acquire with 503 robots.txt: %v", err)
+	}
+}
+
+func TestPolicy_CrawlDelaySharedAcrossViews(t *testing.T) {
+	var fetches atomic.Int32
+	srv := newRobotsServer(t, http.StatusOK, "User-agent: *\nCrawl-delay: 60\n", &fetches)
+	p := New(srv.Client(), Config{UserAgent: ua, Rate: rate.Limit(100)})
+	u := mustParse(t, srv.URL+"/menu")
+
+	if err := p.Acquire(context.Background(), u); err != nil {
+		t.Fatalf("first Acquire: %v", err)
+	}
+	// The second request from a different view must wait out the 60 s
+	// crawl delay on the shared bucket, so a short deadline expires.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := p.WithoutRobots().Acquire(ctx, u); err == nil {
+		t.Fatal("second Acquire within crawl delay succeeded, want deadline error")
+	}
+}
+
+func TestPolicy_DailyBudget(t *testing.T) {
+	var fetches atomic.Int32
+	srv := newRobotsServer(t, http.StatusNotFound, "", &fetches)
+	p := New(srv.Client(), Config{UserAgent: ua, DailyBudget: 1})
+	u := mustParse(t, srv.URL+"/menu")
+
+	if err := p.Acquire(context.Background(), u); err != nil {
+		t.Fatalf("first Acquire: %v", err)
+	}
+	if err := p.Acquire(context.Background(), u); !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("second Acquire = %v, want ErrBudgetExhausted", err)
+	}
+}
+
+func TestPolicy_CancelledWaitRefundsBudget(t *testing.T) {
+	var fetches atomic.Int32
+	srv := newRobotsServer(t, http.StatusOK, "User-agent: *\nCrawl-delay: 60\n", &fetches)
+	p := New(srv.Client(), Config{UserAgent: ua, DailyBudget: 2})
+	u := mustParse(t, srv.URL+"/menu")
+
+	if err := p.Acquire(context.Background(), u); err != nil {
+		t.Fatalf("first Acquire: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := p.Acquire(ctx, u); err == nil {
+		t.Fatal("second Acquire within crawl delay succeeded, want deadline error")
+	}
+	if used := p.budget.Used(Domain(u.Hostname())); used != 1 {
+		t.Errorf("budget used = %d after a cancelled wait, want 1", used)
+	}
+}
+
+func TestDomain(t *testing.T) {
+	if got := Domain("WWW.Example.com"); got != "example.com" {
+		t.Errorf("Domain = %q, want example.com", got)
+	}
+}
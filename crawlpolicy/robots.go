@@ -0,0 +1,184 @@
+package crawlpolicy
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Robots is a parsed robots.txt file. The zero value allows every path.
+type Robots struct {
+	groups   []robotsGroup
+	Sitemaps []string // absolute sitemap URLs listed in the file
+}
+
+// robotsGroup is one User-agent block: the agents it applies to and the
+// rules and crawl delay declared under them.
+type robotsGroup struct {
+	agents     []string // lowercased product tokens; "*" matches any agent
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// robotsRule is a single Allow or Disallow line.
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// ParseRobots parses a robots.txt body following RFC 9309: consecutive
+// User-agent lines open a group, Allow/Disallow/Crawl-delay lines apply to the
+// group most recently opened, and Sitemap lines are global. Unknown
+// directives and malformed lines are ignored.
+func ParseRobots(body string) *Robots {
+	r := &Robots{}
+	var cur *robotsGroup
+	inAgents := false
+	for _, line := range strings.Split(body, "\n") {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				r.groups = append(r.groups, robotsGroup{})
+				cur = &r.groups[len(r.groups)-1]
+				inAgents = true
+			}
+			cur.agents = append(cur.agents, strings.ToLower(value))
+			continue
+		case "sitemap":
+			if value != "" {
+				r.Sitemaps = append(r.Sitemaps, value)
+			}
+		case "allow", "disallow":
+			// An empty Disallow means "allow everything" and adds no rule.
+			if cur != nil && value != "" {
+				cur.rules = append(cur.rules, robotsRule{allow: key == "allow", pattern: value})
+			}
+		case "crawl-delay":
+			if cur != nil {
+				if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+					cur.crawlDelay = time.Duration(secs * float64(time.Second))
+				}
+			}
+		}
+		inAgents = false
+	}
+	return r
+}
+
+// Allowed reports whether userAgent may fetch path (the URL path plus any
+// query string). The longest matching rule wins; on a tie Allow wins. A path
+// no rule matches is allowed.
+func (r *Robots) Allowed(userAgent, path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	allowed, best := true, -1
+	for _, g := range r.groupsFor(userAgent) {
+		for _, rule := range g.rules {
+			if !matchPattern(rule.pattern, path) {
+				continue
+			}
+			n := len(rule.pattern)
+			if n > best || (n == best && rule.allow) {
+				allowed, best = rule.allow, n
+			}
+		}
+	}
+	return allowed
+}
+
+// CrawlDelay returns the largest Crawl-delay declared in the groups that
+// apply to userAgent, or zero if none is declared.
+func (r *Robots) CrawlDelay(userAgent string) time.Duration {
+	var d time.Duration
+	for _, g := range r.groupsFor(userAgent) {
+		d = max(d, g.crawlDelay)
+	}
+	return d
+}
+
+// groupsFor returns the groups that apply to userAgent: every group naming
+// the longest agent token that matches userAgent's product token, or the "*"
+// groups if no group names it.
+func (r *Robots) groupsFor(userAgent string) []robotsGroup {
+	token := productToken(userAgent)
+	var specific, wildcard []robotsGroup
+	bestLen := 0
+	for _, g := range r.groups {
+		n := g.matchLen(token)
+		switch {
+		case n == 0:
+			wildcard = append(wildcard, g)
+		case n > bestLen:
+			specific, bestLen = []robotsGroup{g}, n
+		case n == bestLen:
+			specific = append(specific, g)
+		}
+	}
+	if specific != nil {
+		return specific
+	}
+	return wildcard
+}
+
+// matchLen returns the length of the longest agent in g contained in token,
+// 0 if g only applies via "*", or -1 if g does not apply at all.
+func (g robotsGroup) matchLen(token string) int {
+	n := -1
+	for _, a := range g.agents {
+		switch {
+		case a == "*":
+			n = max(n, 0)
+		case a != "" && strings.Contains(token, a):
+			n = max(n, len(a))
+		}
+	}
+	return n
+}
+
+// productToken returns the lowercased product name of a User-Agent header,
+// e.g. "fodmap-detector" for "fodmap-detector/0.1 (+https://...)".
+func productToken(userAgent string) string {
+	token, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(userAgent)), "/")
+	token, _, _ = strings.Cut(token, " ")
+	return token
+}
+
+// matchPattern reports whether path matches a robots.txt path pattern. "*"
+// matches any sequence of characters and a trailing "$" anchors the pattern
+// to the end of the path; otherwise the pattern is a prefix match.
+func matchPattern(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = pattern[:len(pattern)-1]
+	}
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	if len(parts) == 1 {
+		return !anchored || rest == ""
+	}
+	for i, part := range parts[1:] {
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(rest, part)
+		}
+		idx := strings.Index(rest, part)
+		if idx < 0 {
+			return false
+		}
+		rest = rest[idx+len(part):]
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package crawlpolicy
+
+import (
+	"testing"
+	"time"
+)
+
+const ua = "fodmap-detector/0.1 (+https://github.com/edwardpyang/fodmap-detector)"
+
+func TestRobots_Allowed(t *testing.T) {
+	robots := ParseRobots(`
+# comment line
+User-agent: *
+Disallow: /admin
+Disallow: /*.pdf$
+Disallow: /private*/data
+Allow: /admin/public
+Crawl-delay: 2
+
+User-agent: Googlebot
+Disallow: /
+
+Sitemap: https://example.com/sitemap.xml
+`)
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/", true},
+		{"/menu", true},
+		{"/admin", false},
+		{"/admin/users", false},
+		{"/admin/public/page", true},
+		{"/files/menu.pdf", false},
+		{"/files/menu.pdf?v=2", true},
+		{"/files/menu.pdfx", true},
+		{"/private-area/data", false},
+		{"/private/other", true},
+	}
+	for _, tt := range tests {
+		if got := robots.Allowed(ua, tt.path); got != tt.want {
+			t.Errorf("Allowed(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if got := robots.CrawlDelay(ua); got != 2*time.Second {
+		t.Errorf("CrawlDelay = %v, want 2s", got)
+	}
+	if len(robots.Sitemaps) != 1 || robots.Sitemaps[0] != "https://example.com/sitemap.xml" {
+		t.Errorf("Sitemaps = %v", robots.Sitemaps)
+	}
+}
+
+func TestRobots_SpecificGroupOverridesWildcard(t *testing.T) {
+	robots := ParseRobots(`
+User-agent: *
+Disallow: /
+
+User-agent: other-bot
+User-agent: fodmap-detector
+Disallow: /secret
+Crawl-delay: 0.5
+`)
+	if !robots.Allowed(ua, "/menu") {
+		t.Error("specific group should allow /menu")
+	}
+	if robots.Allowed(ua, "/secret/x") {
+		t.Error("specific group should disallow /secret")
+	}
+	if got := robots.CrawlDelay(ua); got != 500*time.Millisecond {
+		t.Errorf("CrawlDelay = %v, want 500ms", got)
+	}
+	if robots.Allowed("SomeOtherBot/1.0", "/menu") {
+		t.Error("wildcard group should disallow everything for other agents")
+	}
+}
+
+func TestRobots_AllowWinsTie(t *testing.T) {
+	robots := ParseRobots("User-agent: *\nDisallow: /page\nAllow: /page\n")
+	if !robots.Allowed(ua, "/page") {
+		t.Error("equal-length Allow should win over Disallow")
+	}
+}
+
+func TestRobots_EmptyAllowsAll(t *testing.T) {
+	for _, body := range []string{"", "User-agent: *\nDisallow:\n", "garbage without colons"} {
+		if !ParseRobots(body).Allowed(ua, "/anything") {
+			t.Errorf("ParseRobots(%q) should allow everything", body)
+		}
+	}
+	var zero Robots
+	if !zero.Allowed(ua, "") {
+		t.Error("zero Robots should allow everything")
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"/", "/anything", true},
+		{"/a$", "/a", true},
+		{"/a$", "/ab", false},
+		{"/*/menu", "/x/menu/lunch", true},
+		{"/*/menu", "/menu", false},
+		{"*.php$", "/index.php", true},
+		{"*.php$", "/index.php5", false},
+		{"/a*b*c$", "/a-b-c", true},
+		{"/a*b*c$", "/a-b-cd", false},
+	}
+	for _, tt := range tests {
+		if got := matchPattern(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchPattern(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
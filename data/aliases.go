@@ -0,0 +1,287 @@
+package data
+
+// IngredientAliases maps ingredient names as they appear on Spanish, Italian,
+// Portuguese, French, Greek, Chinese, Japanese and Korean menus to the
+// English FODMAP table key they denote, so DishClassifier can read a menu
+// that was never translated. Keys are lowercase; accents are optional in the
+// menu text, since matching ignores them. Words whose meaning shifts between
+// languages or regions ("batata" is sweet potato in Spanish but potato in
+// Portuguese, "plátano" is often plantain) and single characters that occur
+// inside unrelated words ("梨" in "凤梨", "밀" in "밀크") are left out, as
+// are words that name something else on NYC menus ("burro", "pomme" in
+// "pommes frites"): a missed match reads as unknown, a wrong one as a false
+// verdict.
+var IngredientAliases = map[string]string{
+	// Spanish
+	"ajo":             "garlic",
+	"cebolla":         "onion",
+	"trigo":           "wheat",
+	"cebada":          "barley",
+	"centeno":         "rye",
+	"chalota":         "shallot",
+	"remolacha":       "beetroot",
+	"betabel":         "beetroot",
+	"alcachofa":       "artichoke",
+	"garbanzo":        "chickpeas",
+	"lenteja":         "lentils",
+	"frijoles negros": "black beans",
+	"frijol negro":    "black beans",
+	"frijoles rojos":  "kidney beans",
+	"anacardo":        "cashews",
+	"pistacho":        "pistachios",
+	"leche":           "milk",
+	"yogur":           "yogurt",
+	"helado":          "ice cream",
+	"crema":           "cream",
+	"nata":            "cream",
+	"manzana":         "apple",
+	"miel":            "honey",
+	"sandía":          "watermelon",
+	"cereza":          "cherry",
+	"tamarindo":       "tamarind",
+	"aguacate":        "avocado",
+	"durazno":         "peach",
+	"melocotón":       "peach",
+	"ciruela":         "plum",
+	"champiñón":       "mushroom",
+	"champiñones":     "mushroom",
+	"hongos":          "mushroom",
+	"coliflor":        "cauliflower",
+	"apio":            "celery",
+	"camote":          "sweet potato",
+	"guisante":        "peas",
+	"chícharo":        "peas",
+	"brócoli":         "broccoli",
+	"pollo":           "chicken",
+	"arroz":           "rice",
+	"avena":           "oats",
+	"maíz":            "corn",
+	"papa":            "potato",
+	"patata":          "potato",
+	"tomate":          "tomato",
+	"pepino":          "cucumber",
+	"pimiento":        "bell pepper",
+	"chile":           "chili pepper",
+	"fresa":           "strawberry",
+	"piña":            "pineapple",
+	"frambuesa":       "raspberry",
+	"mantequilla":     "butter",
+	"aceite de oliva": "olive oil",
+	"salsa de soya":   "soy sauce",
+	"salsa de soja":   "soy sauce",
+	"vinagre":         "vinegar",
+	"mostaza":         "mustard",
+	"mayonesa":        "mayonnaise",
+	"nuez":            "walnuts",
+	"nueces":          "walnuts",
+	"cacahuate":       "peanuts",
+	"cacahuete":       "peanuts",
+	"maní":            "peanuts",
+	"almendra":        "almonds",
+	"ajonjolí":        "sesame seeds",
+	"café":            "coffee",
+	"cerveza":         "beer",
+	"vino":            "wine",
+
+	// Italian
+	"aglio":        "garlic",
+	"cipolla":      "onion",
+	"cipolle":      "onion",
+	"ceci":         "chickpeas",
+	"lenticchie":   "lentils",
+	"panna":        "cream",
+	"funghi":       "mushroom",
+	"cavolfiore":   "cauliflower",
+	"sedano":       "celery",
+	"piselli":      "peas",
+	"riso":         "rice",
+	"pomodoro":     "tomato",
+	"pomodori":     "tomato",
+	"patate":       "potato",
+	"miele":        "honey",
+	"mela":         "apple",
+	"carciofo":     "artichoke",
+	"carciofi":     "artichoke",
+	"mandorle":     "almonds",
+	"pinoli":       "pine nuts",
+	"olio d'oliva": "olive oil",
+
+	// Portuguese
+	"alho":         "garlic",
+	"cebola":       "onion",
+	"grão de bico": "chickpeas",
+	"feijão preto": "black beans",
+	"leite":        "milk",
+	"cogumelos":    "mushroom",
+	"frango":       "chicken",
+	"milho":        "corn",
+	"manteiga":     "butter",
+	"amendoim":     "peanuts",
+
+	// French
+	"ail":             "garlic",
+	"oignon":          "onion",
+	"blé":             "wheat",
+	"échalote":        "shallot",
+	"pois chiches":    "chickpeas",
+	"lentilles":       "lentils",
+	"lait":            "milk",
+	"crème":           "cream",
+	"pomme de terre":  "potato",
+	"pommes de terre": "potato",
+	"champignons":     "mushroom",
+	"chou-fleur":      "cauliflower",
+	"céleri":          "celery",
+	"poulet":          "chicken",
+	"riz":             "rice",
+	"beurre":          "butter",
+
+	// Greek
+	"σκόρδο":    "garlic",
+	"κρεμμύδι":  "onion",
+	"κρεμμύδια": "onion",
+	"σιτάρι":    "wheat",
+	"ρεβίθια":   "chickpeas",
+	"φακές":     "lentils",
+	"φέτα":      "feta",
+	"γιαούρτι":  "yogurt",
+	"γάλα":      "milk",
+	"μέλι":      "honey",
+	"μανιτάρια": "mushroom",
+	"κοτόπουλο": "chicken",
+	"ρύζι":      "rice",
+	"ντομάτα":   "tomato",
+	"ντομάτες":  "tomato",
+	"πατάτα":    "potato",
+	"πατάτες":   "potato",
+	"αγγούρι":   "cucumber",
+	"βούτυρο":   "butter",
+	"ελαιόλαδο": "olive oil",
+	"αγκινάρα":  "artichoke",
+	"αγκινάρες": "artichoke",
+	"καρπούζι":  "watermelon",
+	"μήλο":      "apple",
+
+	// Chinese (simplified and traditional)
+	"大蒜":  "garlic",
+	"蒜":   "garlic",
+	"洋葱":  "onion",
+	"洋蔥":  "onion",
+	"小麦":  "wheat",
+	"小麥":  "wheat",
+	"面粉":  "wheat",
+	"麵粉":  "wheat",
+	"大麦":  "barley",
+	"大麥":  "barley",
+	"腰果":  "cashews",
+	"开心果": "pistachios",
+	"開心果": "pistachios",
+	"牛奶":  "milk",
+	"豆浆":  "soy milk",
+	"豆漿":  "soy milk",
+	"酸奶":  "yogurt",
+	"冰淇淋": "ice cream",
+	"奶油":  "cream",
+	"苹果":  "apple",
+	"蘋果":  "apple",
+	"芒果":  "mango",
+	"蜂蜜":  "honey",
+	"西瓜":  "watermelon",
+	"牛油果": "avocado",
+	"荔枝":  "lychee",
+	"蘑菇":  "mushroom",
+	"香菇":  "mushroom",
+	"花椰菜": "cauliflower",
+	"芹菜":  "celery",
+	"红薯":  "sweet potato",
+	"紅薯":  "sweet potato",
+	"地瓜":  "sweet potato",
+	"豌豆":  "peas",
+	"鸡肉":  "chicken",
+	"雞肉":  "chicken",
+	"米饭":  "rice",
+	"米飯":  "rice",
+	"豆腐":  "tofu",
+	"米粉":  "rice noodles",
+	"河粉":  "rice noodles",
+	"土豆":  "potato",
+	"马铃薯": "potato",
+	"馬鈴薯": "potato",
+	"番茄":  "tomato",
+	"西红柿": "tomato",
+	"黄瓜":  "cucumber",
+	"黃瓜":  "cucumber",
+	"青椒":  "bell pepper",
+	"辣椒":  "chili pepper",
+	"香菜":  "cilantro",
+	"菠萝":  "pineapple",
+	"鳳梨":  "pineapple",
+	"凤梨":  "pineapple",
+	"草莓":  "strawberry",
+	"香蕉":  "banana",
+	"黄油":  "butter",
+	"黃油":  "butter",
+	"酱油":  "soy sauce",
+	"醬油":  "soy sauce",
+	"醋":   "vinegar",
+	"芝麻":  "sesame seeds",
+	"花生":  "peanuts",
+	"核桃":  "walnuts",
+	"杏仁":  "almonds",
+	"咖啡":  "coffee",
+	"啤酒":  "beer",
+	"鹰嘴豆": "chickpeas",
+	"鷹嘴豆": "chickpeas",
+
+	// Japanese
+	"にんにく": "garlic",
+	"ニンニク": "garlic",
+	"玉ねぎ":  "onion",
+	"玉葱":   "onion",
+	"たまねぎ": "onion",
+	"きのこ":  "mushroom",
+	"しいたけ": "mushroom",
+	"ご飯":   "rice",
+	"醤油":   "soy sauce",
+
+	// Korean
+	"마늘":    "garlic",
+	"양파":    "onion",
+	"밀가루":   "wheat",
+	"보리":    "barley",
+	"우유":    "milk",
+	"요거트":   "yogurt",
+	"아이스크림": "ice cream",
+	"크림":    "cream",
+	"사과":    "apple",
+	"꿀":     "honey",
+	"수박":    "watermelon",
+	"버섯":    "mushroom",
+	"셀러리":   "celery",
+	"고구마":   "sweet potato",
+	"닭고기":   "chicken",
+	"치킨":    "chicken",
+	"닭":     "chicken",
+	"쌀":     "rice",
+	"밥":     "rice",
+	"쌀국수":   "rice noodles",
+	"두부":    "tofu",
+	"감자":    "potato",
+	"토마토":   "tomato",
+	"오이":    "cucumber",
+	"고추":    "chili pepper",
+	"파인애플":  "pineapple",
+	"딸기":    "strawberry",
+	"바나나":   "banana",
+	"버터":    "butter",
+	"간장":    "soy sauce",
+	"식초":    "vinegar",
+	"참깨":    "sesame seeds",
+	"땅콩":    "peanuts",
+	"호두":    "walnuts",
+	"아몬드":   "almonds",
+	"커피":    "coffee",
+	"맥주":    "beer",
+	"와인":    "wine",
+	"옥수수":   "corn",
+}
@@ -0,0 +1,206 @@
+package data
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"fodmap/textwords"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// FodmapLevelRank orders FODMAP levels by severity so the worst case across
+// several ingredients can be computed: low 1, moderate 2, high 3. Unknown
+// levels rank 0.
+func FodmapLevelRank(level string) int {
+	switch level {
+	case "low":
+		return 1
+	case "moderate":
+		return 2
+	case "high":
+		return 3
+	default:
+		return 0
+	}
+}
+
+// DishClassifier estimates a menu item's FODMAP level from the ingredients
+// its text names, using a FODMAP table such as FodmapDB or the catalog.
+type DishClassifier struct {
+	// phrases holds the tokenized table keys, longest first, so "garlic-infused
+	// oil" is preferred over "garlic" where both match.
+	phrases []classifierPhrase
+	// unspaced holds aliases in scripts written without spaces between
+	// words (Chinese, Japanese, Korean compounds), longest first. They are
+	// matched as substrings rather than by word.
+	unspaced []classifierPhrase
+}
+
+type classifierPhrase struct {
+	name   string
+	tokens []string
+	text   string
+	level  string
+}
+
+// NewDishClassifier indexes db for Classify, together with the
+// IngredientAliases of its keys so menus in other languages are read too.
+// A match on an alias reports the table key it stands for.
+func NewDishClassifier(db map[string]FodmapEntry) *DishClassifier {
+	c := &DishClassifier{phrases: make([]classifierPhrase, 0, len(db)+len(IngredientAliases))}
+	add := func(phrase, name, level string) {
+		if isUnspaced(phrase) {
+			c.unspaced = append(c.unspaced, classifierPhrase{name: name, text: strings.ToLower(phrase), level: level})
+			return
+		}
+		if tokens := wordTokens(phrase); len(tokens) > 0 {
+			c.phrases = append(c.phrases, classifierPhrase{name: name, tokens: tokens, level: level})
+		}
+	}
+	for name, entry := range db {
+		if FodmapLevelRank(entry.Level) == 0 {
+			continue
+		}
+		add(name, name, entry.Level)
+	}
+	for alias, name := range IngredientAliases {
+		entry, ok := db[name]
+		if _, shadowed := db[alias]; !ok || shadowed || FodmapLevelRank(entry.Level) == 0 {
+			continue
+		}
+		add(alias, name, entry.Level)
+	}
+	sort.Slice(c.phrases, func(i, j int) bool {
+		if len(c.phrases[i].tokens) != len(c.phrases[j].tokens) {
+			return len(c.phrases[i].tokens) > len(c.phrases[j].tokens)
+		}
+		if c.phrases[i].name != c.phrases[j].name {
+			return c.phrases[i].name < c.phrases[j].name
+		}
+		return strings.Join(c.phrases[i].tokens, " ") < strings.Join(c.phrases[j].tokens, " ")
+	})
+	sort.Slice(c.unspaced, func(i, j int) bool {
+		ni, nj := utf8.RuneCountInString(c.unspaced[i].text), utf8.RuneCountInString(c.unspaced[j].text)
+		if ni != nj {
+			return ni > nj
+		}
+		return c.unspaced[i].text < c.unspaced[j].text
+	})
+	return c
+}
+
+// Classify returns the worst FODMAP level among the table ingredients named
+// in the dish, with the names matched. Stated ingredients are used when
+// present; otherwise the dish name and description are scanned. Matching is
+// by whole words with a plural "s"/"es" allowance, so "onions" matches
+// "onion" but "pea" does not match "peanut"; aliases in Chinese, Japanese
+// or Korean match anywhere in the text. The level is "" when no
+// ingredient is recognised, which callers should treat as unknown rather
+// than safe.
+func (c *DishClassifier) Classify(dishName, description string, ingredients []string) (string, []string) {
+	texts := ingredients
+	if len(texts) == 0 {
+		texts = []string{dishName, description}
+	}
+	worst := ""
+	seen := make(map[string]bool)
+	var matched []string
+	note := func(p classifierPhrase) {
+		if !seen[p.name] {
+			seen[p.name] = true
+			matched = append(matched, p.name)
+		}
+		if FodmapLevelRank(p.level) > FodmapLevelRank(worst) {
+			worst = p.level
+		}
+	}
+	for _, text := range texts {
+		// Unspaced aliases are cut out of the text as they match, so the
+		// "크림" inside "아이스크림" is not counted a second time.
+		if len(c.unspaced) > 0 {
+			text = strings.ToLower(text)
+			for _, p := range c.unspaced {
+				if strings.Contains(text, p.text) {
+					note(p)
+					text = strings.ReplaceAll(text, p.text, " ")
+				}
+			}
+		}
+		tokens := wordTokens(text)
+		for i := 0; i < len(tokens); {
+			p, ok := c.longestMatch(tokens[i:])
+			if !ok {
+				i++
+				continue
+			}
+			i += len(p.tokens)
+			note(p)
+		}
+	}
+	return worst, matched
+}
+
+func (c *DishClassifier) longestMatch(tokens []string) (classifierPhrase, bool) {
+	for _, p := range c.phrases {
+		if len(p.tokens) > len(tokens) {
+			continue
+		}
+		ok := true
+		for k, want := range p.tokens {
+			if !wordEq(tokens[k], want) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return p, true
+		}
+	}
+	return classifierPhrase{}, false
+}
+
+// wordEq compares words ignoring a plural suffix on either side.
+func wordEq(a, b string) bool {
+	return a == b || a == b+"s" || a == b+"es" || b == a+"s" || b == a+"es"
+}
+
+// wordTokens lowercases s, drops accents from Latin and Greek letters and
+// splits it into letter/digit runs, so "Maíz" and "maiz" or "ΣΚΟΡΔΟ" and
+// "σκόρδο" compare equal.
+func wordTokens(s string) []string {
+	return textwords.Split(foldAccents(s))
+}
+
+// foldAccents strips combining marks from Latin and Greek letters and maps
+// the Greek final sigma to σ. Other scripts pass through unchanged: their
+// marks (Japanese dakuten, Devanagari vowel signs) carry meaning.
+func foldAccents(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r == 'ς':
+			b.WriteRune('σ')
+		case r >= utf8.RuneSelf && (unicode.Is(unicode.Latin, r) || unicode.Is(unicode.Greek, r)):
+			base, _ := utf8.DecodeRuneInString(norm.NFD.String(string(r)))
+			b.WriteRune(base)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// isUnspaced reports whether s is written in a script that does not separate
+// words with spaces.
+func isUnspaced(s string) bool {
+	for _, r := range s {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul, unicode.Thai) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package data
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDishClassifier_Classify(t *testing.T) {
+	c := NewDishClassifier(map[string]FodmapEntry{
+		"garlic":             {Level: "high"},
+		"garlic-infused oil": {Level: "low"},
+		"onion":              {Level: "high"},
+		"peas":               {Level: "moderate"},
+		"rice":               {Level: "low"},
+		"chicken":            {Level: "low"},
+	})
+	tests := []struct {
+		name        string
+		dish, desc  string
+		ingredients []string
+		wantLevel   string
+		wantMatches []string
+	}{
+		{"worst ingredient wins", "", "", []string{"rice", "Chicken", "roasted garlic"}, "high", []string{"rice", "chicken", "garlic"}},
+		{"longest phrase preferred", "", "", []string{"garlic-infused oil", "rice"}, "low", []string{"garlic-infused oil", "rice"}},
+		{"plurals", "", "", []string{"caramelized onions", "pea"}, "high", []string{"onion", "peas"}},
+		{"whole words only", "", "", []string{"peanut sauce", "ricepaper"}, "", nil},
+		{"falls back to name and description", "Chicken fried rice", "with peas", nil, "moderate", []string{"chicken", "rice", "peas"}},
+		{"stated ingredients take precedence", "Garlic knots", "", []string{"rice"}, "low", []string{"rice"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, matches := c.Classify(tt.dish, tt.desc, tt.ingredients)
+			if level != tt.wantLevel {
+				t.Errorf("level = %q, want %q", level, tt.wantLevel)
+			}
+			if !slices.Equal(matches, tt.wantMatches) {
+				t.Errorf("matches = %v, want %v", matches, tt.wantMatches)
+			}
+		})
+	}
+}
+
+func TestFodmapLevelRank(t *testing.T) {
+	if !(FodmapLevelRank("low") < FodmapLevelRank("moderate") && FodmapLevelRank("moderate") < FodmapLevelRank("high")) {
+		t.Error("levels should rank low < moderate < high")
+	}
+	if FodmapLevelRank("unknown") != 0 {
+		t.Error("unknown levels should rank 0")
+	}
+}
+
+func TestDishClassifier_ClassifyAliases(t *testing.T) {
+	c := NewDishClassifier(FodmapDB)
+	tests := []struct {
+		name        string
+		dish, desc  string
+		ingredients []string
+		wantMatches []string
+	}{
+		{"spanish", "Sopa de ajo", "con cebolla y arroz", nil, []string{"garlic", "onion", "rice"}},
+		{"accents optional", "Elote", "maiz asado con mantequilla", nil, []string{"corn", "butter"}},
+		{"multi-word alias", "", "", []string{"aceite de oliva", "ajo"}, []string{"olive oil", "garlic"}},
+		{"greek without accents", "ΣΚΟΡΔΟΨΩΜΟ", "ψωμί με σκορδο", nil, []string{"garlic"}},
+		{"chinese substring", "大蒜炒西兰花", "", nil, []string{"garlic"}},
+		{"korean longest alias wins", "", "", []string{"아이스크림"}, []string{"ice cream"}},
+		{"english keys still match", "Garlic soup", "", nil, []string{"garlic"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, matches := c.Classify(tt.dish, tt.desc, tt.ingredients)
+			if !slices.Equal(matches, tt.wantMatches) {
+				t.Errorf("matches = %v, want %v", matches, tt.wantMatches)
+			}
+		})
+	}
+}
+
+func TestIngredientAliases_TargetsInFodmapDB(t *testing.T) {
+	for alias, name := range IngredientAliases {
+		if _, ok := FodmapDB[name]; !ok {
+			t.Errorf("alias %q maps to %q, which is not in FodmapDB", alias, name)
+		}
+		if alias != strings.ToLower(alias) {
+			t.Errorf("alias %q should be lowercase", alias)
+		}
+	}
+}
@@ -2,25 +2,14 @@ package io
 
 import (
 	"encoding/json"
-	"errors"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"fodmap/data/schemas"
 )
 
-const outfile = "outfile.avro"
-
-// setUp ensures the outfile artifact is populated for TestReadFile.
-// It regenerates the file if it is missing or empty.
-func setUp() {
-	info, err := os.Stat(outfile)
-	if errors.Is(err, os.ErrNotExist) || (err == nil && info.Size() == 0) {
-		writeRecords(outfile, avroSampleJSONL)
-	}
-}
-
 // writeRecords is a test helper that writes JSONL lines to an Avro file via EventWriter.
 func writeRecords(path, jsonl string) {
 	f, err := os.Create(path)
@@ -51,9 +40,11 @@ var avroSampleJSONL = strings.Join([]string{
 	`{"review_id":"r2","user_id":"u2","business_id":"b2","stars":3.0,"useful":0.0,"funny":1.0,"cool":0.0,"text":"Ok."}`,
 }, "\n")
 
-// TestReadFile tests reading the committed outfile.avro fixture.
+// TestReadFile tests reading an Avro file written by EventWriter. The file
+// goes under t.TempDir so test runs leave nothing behind in the tree.
 func TestReadFile(t *testing.T) {
-	setUp()
+	outfile := filepath.Join(t.TempDir(), "outfile.avro")
+	writeRecords(outfile, avroSampleJSONL)
 	if err := ReadFile(outfile); err != nil {
 		t.Fatalf("ReadFile(%q): %v", outfile, err)
 	}
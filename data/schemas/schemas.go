@@ -132,7 +132,11 @@ var MenuExtractionSchema = `{
                         }
                     }, "default": []},
                     {"name": "source_url", "type": "string", "default": ""},
-                    {"name": "scraped_at", "type": "string", "default": ""}
+                    {"name": "scraped_at", "type": "string", "default": ""},
+                    {"name": "language", "type": "string", "default": ""},
+                    {"name": "dish_name_en", "type": "string", "default": ""},
+                    {"name": "description_en", "type": "string", "default": ""},
+                    {"name": "stated_ingredients_en", "type": {"type": "array", "items": "string"}, "default": []}
                 ]
             }
         }},
@@ -0,0 +1,410 @@
+package data
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	goio "io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fodmap/data/schemas"
+
+	"github.com/hamba/avro/v2/ocf"
+)
+
+// SourceRecord is one review read from a ReviewSource. Line-based sources
+// return the raw JSON for the caller to decode (in parallel, for the
+// archive's millions of lines); the others decode it themselves.
+type SourceRecord struct {
+	Line     []byte            // a JSON review, decoded with UnmarshalReview; nil when Review is set
+	Review   *schemas.Review   // the decoded review
+	Business *schemas.Business // business metadata the source carries; nil = look it up
+	// Position is where the source resumes to read the records after this
+	// one; pass it to the source's Open function.
+	Position string
+}
+
+// ReviewSource reads reviews in a stable order. Next returns io.EOF after
+// the last review.
+type ReviewSource interface {
+	Next() (SourceRecord, error)
+	Close() error
+}
+
+// maxReviewLine bounds a JSON review line, as the archive scanner does.
+const maxReviewLine = 4 * 1024 * 1024
+
+// tarReviews reads the review file of the Yelp archive. Positions are byte
+// offsets into that file.
+type tarReviews struct {
+	r      *bufio.Reader
+	closer goio.Closer
+	offset int64
+}
+
+// OpenTarReviews opens the review file of the Yelp TAR archive at path,
+// resuming at position: a byte offset written by an earlier run, or
+// "lines:N" to skip N lines (the line-count checkpoints `index` used to
+// write). The archive is compressed, so resuming reads past the skipped
+// bytes rather than seeking.
+func OpenTarReviews(path, position string) (ReviewSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening archive: %w", err)
+	}
+	tr, gz, err := getTarReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	closer := multiCloser{gz, f}
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, goio.EOF) {
+			_ = closer.Close()
+			return nil, errors.New(`file "review" not found in archive`)
+		}
+		if err != nil {
+			_ = closer.Close()
+			return nil, fmt.Errorf("reading tar: %w", err)
+		}
+		if strings.Contains(hdr.Name, "review") {
+			break
+		}
+	}
+	s := &tarReviews{r: bufio.NewReaderSize(tr, 1024*1024), closer: closer}
+	if err := s.skip(position); err != nil {
+		_ = closer.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *tarReviews) skip(position string) error {
+	if position == "" {
+		return nil
+	}
+	if n, ok := strings.CutPrefix(position, "lines:"); ok {
+		lines, err := strconv.Atoi(n)
+		if err != nil || lines < 0 {
+			return fmt.Errorf("bad archive position %q", position)
+		}
+		for range lines {
+			line, err := s.r.ReadSlice('\n')
+			s.offset += int64(len(line))
+			for errors.Is(err, bufio.ErrBufferFull) {
+				line, err = s.r.ReadSlice('\n')
+				s.offset += int64(len(line))
+			}
+			if errors.Is(err, goio.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("skipping archive lines: %w", err)
+			}
+		}
+		return nil
+	}
+	off, err := strconv.ParseInt(position, 10, 64)
+	if err != nil || off < 0 {
+		return fmt.Errorf("bad archive position %q", position)
+	}
+	n, err := goio.CopyN(goio.Discard, s.r, off)
+	s.offset = n
+	if err != nil && !errors.Is(err, goio.EOF) {
+		return fmt.Errorf("skipping archive bytes: %w", err)
+	}
+	return nil
+}
+
+func (s *tarReviews) Next() (SourceRecord, error) {
+	for {
+		line, n, err := readLine(s.r)
+		s.offset += n
+		if err != nil {
+			return SourceRecord{}, err
+		}
+		if len(line) > 0 {
+			return SourceRecord{Line: line, Position: strconv.FormatInt(s.offset, 10)}, nil
+		}
+	}
+}
+
+func (s *tarReviews) Close() error { return s.closer.Close() }
+
+// readLine returns the next line of r without its line ending, and the
+// bytes it consumed. It returns io.EOF only when r is exhausted.
+func readLine(r *bufio.Reader) ([]byte, int64, error) {
+	var line []byte
+	var n int64
+	for {
+		chunk, err := r.ReadSlice('\n')
+		n += int64(len(chunk))
+		line = append(line, chunk...)
+		if len(line) > maxReviewLine {
+			return nil, n, fmt.Errorf("review line longer than %d bytes", maxReviewLine)
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if errors.Is(err, goio.EOF) && len(line) > 0 {
+			err = nil
+		}
+		if err != nil {
+			return nil, n, err
+		}
+		return []byte(strings.TrimRight(string(line), "\r\n")), n, nil
+	}
+}
+
+// sourceFiles lists path itself, or the files in directory path with one of
+// exts in name order: new deltas named by date sort after the ones already
+// read.
+func sourceFiles(path string, exts ...string) (dir string, names []string, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", nil, err
+	}
+	if !info.IsDir() {
+		return filepath.Dir(path), []string{filepath.Base(path)}, nil
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() && slices.Contains(exts, filepath.Ext(e.Name())) {
+			names = append(names, e.Name())
+		}
+	}
+	return path, names, nil
+}
+
+// splitFilePosition parses a "<file>#<n>" position.
+func splitFilePosition(position string) (string, int64, error) {
+	i := strings.LastIndexByte(position, '#')
+	if i < 0 {
+		return "", 0, fmt.Errorf("bad position %q: want <file>#<n>", position)
+	}
+	n, err := strconv.ParseInt(position[i+1:], 10, 64)
+	if err != nil || n < 0 {
+		return "", 0, fmt.Errorf("bad position %q: want <file>#<n>", position)
+	}
+	return position[:i], n, nil
+}
+
+// fileSource walks a list of files in order, resuming inside the file a
+// position names; files that sort before it were read already.
+type fileSource struct {
+	dir   string
+	names []string
+	next  int // index into names of the next file to open
+}
+
+func newFileSource(path, position string, exts ...string) (*fileSource, string, int64, error) {
+	dir, names, err := sourceFiles(path, exts...)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	s := &fileSource{dir: dir, names: names}
+	if position == "" {
+		return s, "", 0, nil
+	}
+	name, n, err := splitFilePosition(position)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	s.next, _ = slices.BinarySearch(names, name)
+	return s, name, n, nil
+}
+
+// jsonlReviews reads one JSON review per line from .jsonl files, such as
+// the review file of the Yelp dataset or weekly deltas in its format.
+// Positions are "<file>#<byte offset>".
+type jsonlReviews struct {
+	*fileSource
+	f      *os.File
+	r      *bufio.Reader
+	name   string
+	offset int64
+}
+
+// OpenJSONLReviews opens a .jsonl file, or every .jsonl and .ndjson file in
+// a directory in name order, resuming at position.
+func OpenJSONLReviews(path, position string) (ReviewSource, error) {
+	fs, name, off, err := newFileSource(path, position, ".jsonl", ".ndjson")
+	if err != nil {
+		return nil, fmt.Errorf("opening JSONL reviews: %w", err)
+	}
+	s := &jsonlReviews{fileSource: fs}
+	if name != "" && fs.next < len(fs.names) && fs.names[fs.next] == name {
+		if err := s.open(off); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *jsonlReviews) open(offset int64) error {
+	s.name = s.names[s.next]
+	s.next++
+	f, err := os.Open(filepath.Join(s.dir, s.name))
+	if err != nil {
+		return fmt.Errorf("opening JSONL reviews: %w", err)
+	}
+	if _, err := f.Seek(offset, goio.SeekStart); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("seeking %s: %w", s.name, err)
+	}
+	s.f, s.r, s.offset = f, bufio.NewReader(f), offset
+	return nil
+}
+
+func (s *jsonlReviews) Next() (SourceRecord, error) {
+	for {
+		if s.f == nil {
+			if s.next >= len(s.names) {
+				return SourceRecord{}, goio.EOF
+			}
+			if err := s.open(0); err != nil {
+				return SourceRecord{}, err
+			}
+		}
+		line, n, err := readLine(s.r)
+		s.offset += n
+		if errors.Is(err, goio.EOF) {
+			_ = s.f.Close()
+			s.f = nil
+			continue
+		}
+		if err != nil {
+			return SourceRecord{}, fmt.Errorf("reading %s: %w", s.name, err)
+		}
+		if len(line) > 0 {
+			return SourceRecord{Line: line, Position: s.name + "#" + strconv.FormatInt(s.offset, 10)}, nil
+		}
+	}
+}
+
+func (s *jsonlReviews) Close() error {
+	if s.f != nil {
+		return s.f.Close()
+	}
+	return nil
+}
+
+// avroReviews reads reviews from Avro OCF files written by `event write`.
+// Positions are "<file>#<records read>": OCF blocks are compressed, so a
+// resumed file is decoded from its start and the records before the
+// position skipped.
+type avroReviews struct {
+	*fileSource
+	f     *os.File
+	dec   *ocf.Decoder
+	name  string
+	count int64
+}
+
+// OpenAvroReviews opens an Avro OCF file, or every .avro file in a
+// directory in name order, resuming at position.
+func OpenAvroReviews(path, position string) (ReviewSource, error) {
+	fs, name, skip, err := newFileSource(path, position, ".avro")
+	if err != nil {
+		return nil, fmt.Errorf("opening Avro reviews: %w", err)
+	}
+	s := &avroReviews{fileSource: fs}
+	if name != "" && fs.next < len(fs.names) && fs.names[fs.next] == name {
+		if err := s.open(); err != nil {
+			return nil, err
+		}
+		for ; s.count < skip && s.dec.HasNext(); s.count++ {
+			var datum map[string]any
+			if err := s.dec.Decode(&datum); err != nil {
+				_ = s.Close()
+				return nil, fmt.Errorf("skipping %s records: %w", s.name, err)
+			}
+		}
+	}
+	return s, nil
+}
+
+func (s *avroReviews) open() error {
+	s.name = s.names[s.next]
+	s.next++
+	f, err := os.Open(filepath.Join(s.dir, s.name))
+	if err != nil {
+		return fmt.Errorf("opening Avro reviews: %w", err)
+	}
+	dec, err := ocf.NewDecoder(f)
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("reading %s: %w", s.name, err)
+	}
+	s.f, s.dec, s.count = f, dec, 0
+	return nil
+}
+
+func (s *avroReviews) Next() (SourceRecord, error) {
+	for {
+		if s.f == nil {
+			if s.next >= len(s.names) {
+				return SourceRecord{}, goio.EOF
+			}
+			if err := s.open(); err != nil {
+				return SourceRecord{}, err
+			}
+		}
+		if !s.dec.HasNext() {
+			err := s.dec.Error()
+			_ = s.f.Close()
+			s.f = nil
+			if err != nil {
+				return SourceRecord{}, fmt.Errorf("reading %s: %w", s.name, err)
+			}
+			continue
+		}
+		var datum map[string]any
+		if err := s.dec.Decode(&datum); err != nil {
+			return SourceRecord{}, fmt.Errorf("decoding %s: %w", s.name, err)
+		}
+		s.count++
+		r := reviewFromEvent(datum)
+		return SourceRecord{Review: &r, Position: s.name + "#" + strconv.FormatInt(s.count, 10)}, nil
+	}
+}
+
+func (s *avroReviews) Close() error {
+	if s.f != nil {
+		return s.f.Close()
+	}
+	return nil
+}
+
+// reviewFromEvent converts a record of schemas.EventSchema, which stores
+// the vote counts as floats, to a Review.
+func reviewFromEvent(m map[string]any) schemas.Review {
+	str := func(k string) string { s, _ := m[k].(string); return s }
+	num := func(k string) float32 {
+		switch v := m[k].(type) {
+		case float32:
+			return v
+		case float64:
+			return float32(v)
+		}
+		return 0
+	}
+	return schemas.Review{
+		ReviewID:   str("review_id"),
+		UserID:     str("user_id"),
+		BusinessID: str("business_id"),
+		Stars:      num("stars"),
+		Useful:     int32(num("useful")),
+		Funny:      int32(num("funny")),
+		Cool:       int32(num("cool")),
+		Text:       str("text"),
+	}
+}
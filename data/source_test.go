@@ -0,0 +1,164 @@
+package data
+
+import (
+	"errors"
+	goio "io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fodmap/data/schemas"
+
+	"github.com/hamba/avro/v2/ocf"
+)
+
+// readIDs drains src, returning the review IDs it read and the position
+// after each.
+func readIDs(t *testing.T, src ReviewSource) (ids, positions []string) {
+	t.Helper()
+	for {
+		rec, err := src.Next()
+		if errors.Is(err, goio.EOF) {
+			return ids, positions
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := rec.Review
+		if r == nil {
+			review, err := UnmarshalReview(rec.Line)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r = &review
+		}
+		ids = append(ids, r.ReviewID)
+		positions = append(positions, rec.Position)
+	}
+}
+
+func TestOpenTarReviews_Resume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.tar")
+	createTestTar(t, path, map[string]string{
+		"yelp_academic_dataset_review.json": "{\"review_id\":\"r1\"}\n\n{\"review_id\":\"r2\"}\n{\"review_id\":\"r3\"}",
+	})
+
+	src, err := OpenTarReviews(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, positions := readIDs(t, src)
+	_ = src.Close()
+	if len(ids) != 3 {
+		t.Fatalf("ids = %v", ids)
+	}
+
+	for _, pos := range []string{positions[0], "lines:2"} {
+		src, err = OpenTarReviews(path, pos)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := readIDs(t, src)
+		_ = src.Close()
+		if len(got) != 2 || got[0] != "r2" {
+			t.Errorf("resumed at %q: ids = %v, want r2 r3", pos, got)
+		}
+	}
+
+	if _, err := OpenTarReviews(path, "x"); err == nil {
+		t.Error("expected error for a bad position")
+	}
+}
+
+func TestOpenJSONLReviews_Directory(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"2024-01.jsonl":  "{\"review_id\":\"a1\"}\n{\"review_id\":\"a2\"}\n",
+		"2024-02.ndjson": "{\"review_id\":\"b1\"}\n",
+		"notes.txt":      "not reviews",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	src, err := OpenJSONLReviews(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, positions := readIDs(t, src)
+	_ = src.Close()
+	if want := []string{"a1", "a2", "b1"}; len(ids) != 3 || ids[0] != want[0] || ids[2] != want[2] {
+		t.Fatalf("ids = %v, want %v", ids, want)
+	}
+
+	src, err = OpenJSONLReviews(dir, positions[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := readIDs(t, src)
+	_ = src.Close()
+	if len(got) != 2 || got[0] != "a2" || got[1] != "b1" {
+		t.Errorf("resumed at %q: ids = %v, want a2 b1", positions[0], got)
+	}
+
+	// A position at the end of a file moves on to the next one.
+	src, err = OpenJSONLReviews(dir, positions[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ = readIDs(t, src)
+	_ = src.Close()
+	if len(got) != 1 || got[0] != "b1" {
+		t.Errorf("resumed at %q: ids = %v, want b1", positions[1], got)
+	}
+}
+
+func TestOpenAvroReviews_Resume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.avro")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := ocf.NewEncoder(schemas.EventSchema, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"e1", "e2", "e3"} {
+		rec := map[string]any{
+			"review_id": id, "user_id": "u", "business_id": "b",
+			"stars": float32(4), "useful": float32(2), "funny": float32(0), "cool": float32(1),
+			"text": "Great pho",
+		}
+		if err := enc.Encode(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	src, err := OpenAvroReviews(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := src.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = src.Close()
+	if r := rec.Review; r == nil || r.ReviewID != "e1" || r.Stars != 4 || r.Useful != 2 || r.Text != "Great pho" {
+		t.Errorf("review = %+v", rec.Review)
+	}
+
+	src, err = OpenAvroReviews(path, rec.Position)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, _ := readIDs(t, src)
+	_ = src.Close()
+	if len(ids) != 2 || ids[0] != "e2" {
+		t.Errorf("resumed at %q: ids = %v, want e2 e3", rec.Position, ids)
+	}
+}
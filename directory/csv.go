@@ -0,0 +1,90 @@
+package directory
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// CSVSource imports a CSV file with a header row, such as another city's
+// open-data inspection export. Columns are found by common names (see
+// Fields); Columns overrides the mapping per field, e.g.
+// {"name": "DBA Name", "id": "License #"}. Rows sharing an ID are collapsed
+// to the last one, since inspection feeds repeat a restaurant per visit.
+type CSVSource struct {
+	SourceName string
+	Path       string
+	Columns    map[string]string
+}
+
+// Name implements Source.
+func (s *CSVSource) Name() string { return s.SourceName }
+
+// Fetch implements Source.
+func (s *CSVSource) Fetch(_ context.Context) ([]Record, error) {
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", s.Path, err)
+	}
+	defer func() { _ = f.Close() }()
+	return ParseCSV(f, s.SourceName, s.Columns)
+}
+
+// ParseCSV reads records from r. See CSVSource for the column mapping.
+func ParseCSV(r io.Reader, source string, columns map[string]string) ([]Record, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading csv header: %w", err)
+	}
+	colIdx := make(map[string]int, len(header))
+	for i, h := range header {
+		colIdx[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	mapping := make(map[string]string, len(columns))
+	for field, col := range columns {
+		col = strings.ToLower(col)
+		if _, ok := colIdx[col]; !ok {
+			return nil, fmt.Errorf("column %q for field %s not in csv header", col, field)
+		}
+		mapping[field] = col
+	}
+
+	var records []Record
+	index := make(map[string]int)
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading csv: %w", err)
+		}
+		get := func(key string) string {
+			if i, ok := colIdx[strings.ToLower(key)]; ok && i < len(row) {
+				return row[i]
+			}
+			return ""
+		}
+		rec, ok := recordFromFields(source, get, mapping)
+		if !ok {
+			continue
+		}
+		if i, seen := index[rec.ExternalID]; seen {
+			records[i] = rec
+			continue
+		}
+		index[rec.ExternalID] = len(records)
+		records = append(records, rec)
+	}
+	return records, nil
+}
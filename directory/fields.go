@@ -0,0 +1,108 @@
+package directory
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// fieldAliases lists, per Record field, the column or property names that
+// open-data inspection feeds and OpenStreetMap extracts commonly use for it,
+// in order of preference. Matching is case-insensitive.
+var fieldAliases = map[string][]string{
+	"id":        {"id", "@id", "external_id", "camis", "business_id", "license_", "license", "license_number", "osm_id"},
+	"name":      {"name", "dba", "dba_name", "business_name", "restaurant_name", "facility_name"},
+	"building":  {"building", "addr:housenumber", "house_number", "housenumber"},
+	"street":    {"street", "addr:street", "street_name"},
+	"address":   {"address", "street_address", "address_line_1", "site_address", "facility_address"},
+	"city":      {"city", "addr:city", "municipality"},
+	"state":     {"state", "addr:state"},
+	"zipcode":   {"zipcode", "zip", "zip_code", "postal_code", "postcode", "addr:postcode"},
+	"phone":     {"phone", "contact:phone", "phone_number", "telephone"},
+	"cuisine":   {"cuisine", "cuisine_description", "categories", "category", "facility_type"},
+	"latitude":  {"latitude", "lat"},
+	"longitude": {"longitude", "lon", "lng", "long"},
+}
+
+// Fields lists the Record fields a column mapping may name, for flag help
+// and validation.
+var Fields = []string{"id", "name", "building", "street", "address", "city", "state", "zipcode", "phone", "cuisine", "latitude", "longitude"}
+
+// fieldGetter returns the raw value of a named column or property.
+type fieldGetter func(key string) string
+
+// recordFromFields builds a Record by looking up each field through columns
+// (an explicit field → column mapping) and then fieldAliases. A lone
+// "address" column is split into building and street. It returns false when
+// the row has no name.
+func recordFromFields(source string, get fieldGetter, columns map[string]string) (Record, bool) {
+	lookup := func(field string) string {
+		if col, ok := columns[field]; ok {
+			return strings.TrimSpace(get(col))
+		}
+		for _, alias := range fieldAliases[field] {
+			if v := strings.TrimSpace(get(alias)); v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	rec := Record{
+		Source:     source,
+		ExternalID: lookup("id"),
+		Name:       lookup("name"),
+		Building:   lookup("building"),
+		Street:     lookup("street"),
+		City:       lookup("city"),
+		State:      lookup("state"),
+		Zipcode:    lookup("zipcode"),
+		Phone:      lookup("phone"),
+		Cuisine:    lookup("cuisine"),
+		Latitude:   parseCoord(lookup("latitude")),
+		Longitude:  parseCoord(lookup("longitude")),
+	}
+	if rec.Name == "" {
+		return rec, false
+	}
+	if rec.Street == "" {
+		rec.Building, rec.Street = SplitAddress(lookup("address"))
+	}
+	if rec.ExternalID == "" {
+		rec.ExternalID = syntheticID(rec)
+	}
+	return rec, true
+}
+
+// SplitAddress splits a one-line street address into its house number and
+// street, e.g. "31-01 Broadway" → ("31-01", "Broadway"). Addresses without a
+// leading number are returned whole as the street.
+func SplitAddress(addr string) (building, street string) {
+	addr = strings.TrimSpace(addr)
+	num, rest, ok := strings.Cut(addr, " ")
+	if !ok || num == "" || !unicode.IsDigit(rune(num[0])) {
+		return "", addr
+	}
+	return num, strings.TrimSpace(rest)
+}
+
+// syntheticID keys a record that has no source identifier by its
+// normalized name, address and zip code, so re-importing the same file links
+// to the same restaurant.
+func syntheticID(r Record) string {
+	return strings.Join([]string{
+		strings.Join(nameTokens(r.Name), "-"),
+		strings.ReplaceAll(NormalizeAddress(r.Building, r.Street), " ", "-"),
+		r.Zipcode,
+	}, "|")
+}
+
+// parseCoord parses a decimal degree, returning nil for blanks, garbage and
+// the 0 placeholder some feeds use for ungeocoded rows.
+func parseCoord(s string) *float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v == 0 {
+		return nil
+	}
+	return &v
+}
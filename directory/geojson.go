@@ -0,0 +1,120 @@
+package directory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"fodmap/geo"
+)
+
+// GeoJSONSource imports a GeoJSON FeatureCollection of restaurants, such as
+// an OpenStreetMap amenity=restaurant extract. Names and addresses come from
+// feature properties (OSM addr:* tags are understood); Point features give
+// the coordinates directly and polygon features (building outlines) use the
+// centre of their bounding box. Columns overrides the property per field as
+// in CSVSource.
+type GeoJSONSource struct {
+	SourceName string
+	Path       string
+	Columns    map[string]string
+}
+
+// Name implements Source.
+func (s *GeoJSONSource) Name() string { return s.SourceName }
+
+// Fetch implements Source.
+func (s *GeoJSONSource) Fetch(_ context.Context) ([]Record, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", s.Path, err)
+	}
+	return ParseGeoJSON(data, s.SourceName, s.Columns)
+}
+
+type geoJSONFeature struct {
+	ID         json.RawMessage            `json:"id"`
+	Geometry   *geo.Geometry              `json:"geometry"`
+	Properties map[string]json.RawMessage `json:"properties"`
+}
+
+// ParseGeoJSON reads records from a FeatureCollection. Features without a
+// name are skipped.
+func ParseGeoJSON(data []byte, source string, columns map[string]string) ([]Record, error) {
+	var doc struct {
+		Type     string           `json:"type"`
+		Features []geoJSONFeature `json:"features"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("decoding GeoJSON: %w", err)
+	}
+	if doc.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("unsupported GeoJSON type %q (want FeatureCollection)", doc.Type)
+	}
+
+	var records []Record
+	for i, f := range doc.Features {
+		props := make(map[string]string, len(f.Properties))
+		for k, v := range f.Properties {
+			if s := jsonScalar(v); s != "" {
+				props[strings.ToLower(k)] = s
+			}
+		}
+		if _, ok := props["id"]; !ok {
+			if id := jsonScalar(f.ID); id != "" {
+				props["id"] = id
+			}
+		}
+		rec, ok := recordFromFields(source, func(key string) string { return props[strings.ToLower(key)] }, columns)
+		if !ok {
+			continue
+		}
+		if f.Geometry != nil {
+			lat, lon, err := featureCoords(f.Geometry)
+			if err != nil {
+				return nil, fmt.Errorf("feature %d: %w", i, err)
+			}
+			rec.Latitude, rec.Longitude = &lat, &lon
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// jsonScalar returns a string or number property as text, keeping numeric
+// IDs and phone numbers as written rather than reformatting them as floats.
+// Null, objects and arrays yield "".
+func jsonScalar(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String()
+	}
+	return ""
+}
+
+// featureCoords returns a representative point for g.
+func featureCoords(g *geo.Geometry) (lat, lon float64, err error) {
+	if g.Type == "Point" {
+		pos, ok := g.Coordinates.([]any)
+		if !ok || len(pos) < 2 {
+			return 0, 0, fmt.Errorf("malformed Point coordinates")
+		}
+		lon, ok1 := pos[0].(float64)
+		lat, ok2 := pos[1].(float64)
+		if !ok1 || !ok2 {
+			return 0, 0, fmt.Errorf("malformed Point coordinates")
+		}
+		return lat, lon, nil
+	}
+	b, err := g.Bounds()
+	if err != nil {
+		return 0, 0, err
+	}
+	return (b.MinLat() + b.MaxLat()) / 2, (b.MinLon() + b.MaxLon()) / 2, nil
+}
@@ -0,0 +1,86 @@
+// Package directory normalizes restaurant listings from external directories
+// (NYC DOHMH inspections, the Yelp business file, other cities' open-data
+// feeds, OpenStreetMap extracts) into one Record shape, and resolves records
+// from different sources to the same restaurant by name, address, phone and
+// location.
+package directory
+
+import (
+	"context"
+	"strings"
+
+	"fodmap/geo"
+)
+
+// Well-known source names. They key restaurant_external_ids rows, so they
+// must not change once data has been imported under them.
+const (
+	SourceNYC  = "nyc_dohmh"
+	SourceYelp = "yelp"
+)
+
+// Source is a directory of restaurants that can be imported. Fetch returns
+// every record the source currently lists; callers apply area filtering and
+// pagination.
+type Source interface {
+	// Name identifies the source in restaurant_external_ids, e.g. "yelp".
+	Name() string
+	Fetch(ctx context.Context) ([]Record, error)
+}
+
+// Record is a restaurant listing normalized from any source. ExternalID is
+// the source's own identifier (CAMIS, Yelp business_id, OSM node id, ...).
+// Empty strings and nil coordinates mean the source does not provide the
+// field.
+type Record struct {
+	Source     string
+	ExternalID string
+	Name       string
+	Building   string
+	Street     string
+	City       string
+	State      string
+	Zipcode    string
+	// Boro and NTA are only set by NYC sources.
+	Boro      string
+	NTA       string
+	Phone     string
+	Cuisine   string
+	Latitude  *float64
+	Longitude *float64
+}
+
+// Place returns the area-membership view of r.
+func (r Record) Place() geo.Place {
+	return geo.Place{NTA: r.NTA, Zipcode: r.Zipcode, Boro: r.Boro, Lat: r.Latitude, Lon: r.Longitude}
+}
+
+// FullAddress joins the address parts into one line, e.g.
+// "31-01 Broadway, Astoria, NY 11106". It is empty when r has no street.
+func (r Record) FullAddress() string {
+	street := strings.TrimSpace(strings.Join(nonEmpty(r.Building, r.Street), " "))
+	if street == "" {
+		return ""
+	}
+	region := strings.Join(nonEmpty(r.State, r.Zipcode), " ")
+	return strings.Join(nonEmpty(street, r.City, region), ", ")
+}
+
+// coords returns r's coordinates, treating (0, 0) as absent since several
+// feeds use it for "not geocoded".
+func (r Record) coords() (lat, lon float64, ok bool) {
+	if r.Latitude == nil || r.Longitude == nil || (*r.Latitude == 0 && *r.Longitude == 0) {
+		return 0, 0, false
+	}
+	return *r.Latitude, *r.Longitude, true
+}
+
+func nonEmpty(parts ...string) []string {
+	out := parts[:0:0]
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
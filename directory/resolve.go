@@ -0,0 +1,194 @@
+package directory
+
+import (
+	"strings"
+	"unicode"
+
+	"fodmap/geo"
+	"fodmap/textwords"
+)
+
+// Resolution thresholds. A name similarity of 0.5 tolerates a branch suffix
+// ("Joe's Pizza" vs "Joe's Pizza Astoria"); location-only matches need a
+// closer name because neighbouring storefronts share a block.
+const (
+	minNameSimilarity    = 0.5
+	nearNameSimilarity   = 0.75
+	nearDistanceMeters   = 50
+	sameNameRadiusMeters = 150
+)
+
+// Candidate is an existing restaurant that a new record may resolve to.
+type Candidate struct {
+	ID     string
+	Record Record
+}
+
+// Resolve returns the candidate that rec most likely describes, and false
+// when none is a confident match. See Same for the matching rules; among
+// matching candidates the one with the most evidence, then the most similar
+// name, wins.
+func Resolve(rec Record, candidates []Candidate) (Candidate, bool) {
+	var best Candidate
+	bestEvidence, bestName := 0, 0.0
+	for _, c := range candidates {
+		ev, name := evidence(rec, c.Record)
+		if ev == 0 {
+			continue
+		}
+		if ev > bestEvidence || (ev == bestEvidence && name > bestName) {
+			best, bestEvidence, bestName = c, ev, name
+		}
+	}
+	return best, bestEvidence > 0
+}
+
+// Same reports whether a and b describe the same restaurant. Names must be
+// similar, and at least one of these must hold:
+//   - the phone numbers are equal;
+//   - the street addresses are equal;
+//   - the locations are within 50 m and the names closely similar;
+//   - the locations are within 150 m and the names identical once normalized.
+func Same(a, b Record) bool {
+	ev, _ := evidence(a, b)
+	return ev > 0
+}
+
+// evidence counts the independent signals that a and b are the same place,
+// returning 0 when their names are too different for any signal to count.
+func evidence(a, b Record) (int, float64) {
+	name := NameSimilarity(a.Name, b.Name)
+	if name < minNameSimilarity {
+		return 0, name
+	}
+	n := 0
+	if p := NormalizePhone(a.Phone); p != "" && p == NormalizePhone(b.Phone) {
+		n++
+	}
+	if addr := NormalizeAddress(a.Building, a.Street); addr != "" && addr == NormalizeAddress(b.Building, b.Street) {
+		n++
+	}
+	if d, ok := distance(a, b); ok {
+		if (d <= nearDistanceMeters && name >= nearNameSimilarity) || (d <= sameNameRadiusMeters && name == 1) {
+			n++
+		}
+	}
+	return n, name
+}
+
+// NormalizePhone returns the 10-digit North American number in phone, or ""
+// when it does not hold one. A leading country code 1 is dropped.
+func NormalizePhone(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	d := b.String()
+	if len(d) == 11 && d[0] == '1' {
+		d = d[1:]
+	}
+	if len(d) != 10 {
+		return ""
+	}
+	return d
+}
+
+// nameStopwords are dropped before comparing names: they are either legal
+// suffixes or too generic to distinguish restaurants.
+var nameStopwords = map[string]bool{
+	"the": true, "restaurant": true, "inc": true, "llc": true, "corp": true, "co": true,
+}
+
+// nameTokens lowercases name, spells out "&", drops apostrophes so "Joe's"
+// and "Joes" agree, and splits on anything else that is not a letter or
+// digit.
+func nameTokens(name string) []string {
+	name = strings.ReplaceAll(strings.ToLower(name), "&", " and ")
+	name = strings.NewReplacer("'", "", "’", "").Replace(name)
+	fields := strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	tokens := fields[:0]
+	for _, f := range fields {
+		if !nameStopwords[f] {
+			tokens = append(tokens, f)
+		}
+	}
+	return tokens
+}
+
+// NameSimilarity is the Jaccard similarity of the two names' token sets,
+// after normalization. Names that differ only in spacing ("Mc Donalds" vs
+// "McDonalds") score 1.
+func NameSimilarity(a, b string) float64 {
+	ta, tb := nameTokens(a), nameTokens(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	if strings.Join(ta, "") == strings.Join(tb, "") {
+		return 1
+	}
+	set := make(map[string]int, len(ta))
+	for _, t := range ta {
+		set[t] |= 1
+	}
+	for _, t := range tb {
+		set[t] |= 2
+	}
+	inter := 0
+	for _, v := range set {
+		if v == 3 {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(set))
+}
+
+// streetAbbrev maps street types and directions to the USPS abbreviations
+// most feeds already use.
+var streetAbbrev = map[string]string{
+	"street": "st", "avenue": "ave", "av": "ave", "boulevard": "blvd", "road": "rd",
+	"drive": "dr", "place": "pl", "lane": "ln", "parkway": "pkwy", "court": "ct",
+	"square": "sq", "terrace": "ter", "highway": "hwy", "expressway": "expy",
+	"north": "n", "south": "s", "east": "e", "west": "w",
+}
+
+// NormalizeAddress returns a canonical "number street" key, or "" when
+// either part is missing. Ordinal suffixes are dropped ("31st" → "31") and
+// street types and directions abbreviated.
+func NormalizeAddress(building, street string) string {
+	building = strings.ToLower(strings.TrimSpace(building))
+	words := textwords.Split(street)
+	if building == "" || len(words) == 0 {
+		return ""
+	}
+	for i, w := range words {
+		if a, ok := streetAbbrev[w]; ok {
+			words[i] = a
+			continue
+		}
+		words[i] = trimOrdinal(w)
+	}
+	return building + " " + strings.Join(words, " ")
+}
+
+// trimOrdinal strips st/nd/rd/th from a number, leaving other words alone.
+func trimOrdinal(w string) string {
+	for _, suf := range []string{"st", "nd", "rd", "th"} {
+		num, ok := strings.CutSuffix(w, suf)
+		if ok && num != "" && strings.IndexFunc(num, func(r rune) bool { return r < '0' || r > '9' }) < 0 {
+			return num
+		}
+	}
+	return w
+}
+
+// distance returns the great-circle distance between a and b in metres.
+func distance(a, b Record) (float64, bool) {
+	lat1, lon1, ok1 := a.coords()
+	lat2, lon2, ok2 := b.coords()
+	if !ok1 || !ok2 {
+		return 0, false
+	}
+	return geo.Distance(lat1, lon1, lat2, lon2), true
+}
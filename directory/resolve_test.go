@@ -0,0 +1,81 @@
+package directory
+
+import "testing"
+
+func ptr(f float64) *float64 { return &f }
+
+func TestNormalizers(t *testing.T) {
+	phones := map[string]string{
+		"(718) 555-0134":  "7185550134",
+		"+1 718-555-0134": "7185550134",
+		"555-0134":        "",
+	}
+	for in, want := range phones {
+		if got := NormalizePhone(in); got != want {
+			t.Errorf("NormalizePhone(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	if a, b := NormalizeAddress("31-01", "Thirty First Street"), NormalizeAddress("31-01", "thirty first st"); a != b {
+		t.Errorf("street suffix not normalized: %q vs %q", a, b)
+	}
+	if a, b := NormalizeAddress("2402", "31st Avenue"), NormalizeAddress("2402", "31 AVE"); a != b {
+		t.Errorf("ordinal not normalized: %q vs %q", a, b)
+	}
+	if got := NormalizeAddress("", "Broadway"); got != "" {
+		t.Errorf("address without building = %q, want empty", got)
+	}
+
+	if got := NameSimilarity("Joe's Pizza", "JOES PIZZA"); got != 1 {
+		t.Errorf("apostrophe/case similarity = %v, want 1", got)
+	}
+	if got := NameSimilarity("Taverna Kyclades", "The Taverna Kyclades Restaurant"); got != 1 {
+		t.Errorf("stopword similarity = %v, want 1", got)
+	}
+	if got := NameSimilarity("Sushi Yasaka", "Pizza Yasaka"); got >= minNameSimilarity {
+		t.Errorf("different restaurants similarity = %v, want < %v", got, minNameSimilarity)
+	}
+}
+
+func TestSame(t *testing.T) {
+	nyc := Record{
+		Name: "TAVERNA KYCLADES", Building: "33-07", Street: "DITMARS BOULEVARD",
+		Phone: "7185458666", Latitude: ptr(40.77499), Longitude: ptr(-73.90829),
+	}
+	tests := []struct {
+		name  string
+		other Record
+		want  bool
+	}{
+		{"phone", Record{Name: "Taverna Kyclades", Phone: "+1 (718) 545-8666"}, true},
+		{"address", Record{Name: "Taverna Kyclades Astoria", Building: "33-07", Street: "Ditmars Blvd"}, true},
+		{"nearby same name", Record{Name: "Taverna Kyclades", Latitude: ptr(40.7752), Longitude: ptr(-73.9081)}, true},
+		{"nearby different name", Record{Name: "Sal, Kris & Charlie's Deli", Latitude: ptr(40.7750), Longitude: ptr(-73.9083)}, false},
+		{"same phone different name", Record{Name: "Ditmars Laundromat", Phone: "7185458666"}, false},
+		{"same name far away", Record{Name: "Taverna Kyclades", Latitude: ptr(40.7359), Longitude: ptr(-73.9911)}, false},
+		{"name only", Record{Name: "Taverna Kyclades"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Same(nyc, tt.other); got != tt.want {
+				t.Errorf("Same = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve_PrefersMostEvidence(t *testing.T) {
+	rec := Record{Name: "Il Bambino", Building: "34-08", Street: "31st Ave", Phone: "718-626-0087"}
+	candidates := []Candidate{
+		{ID: "address-only", Record: Record{Name: "Il Bambino", Building: "34-08", Street: "31 Avenue"}},
+		{ID: "address-and-phone", Record: Record{Name: "IL BAMBINO", Building: "34-08", Street: "31 AVENUE", Phone: "7186260087"}},
+		{ID: "other", Record: Record{Name: "Vesta", Phone: "7185450005"}},
+	}
+	got, ok := Resolve(rec, candidates)
+	if !ok || got.ID != "address-and-phone" {
+		t.Fatalf("Resolve = %q, %v; want address-and-phone", got.ID, ok)
+	}
+	if _, ok := Resolve(rec, candidates[2:]); ok {
+		t.Error("Resolve matched an unrelated restaurant")
+	}
+}
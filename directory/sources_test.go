@@ -0,0 +1,97 @@
+package directory
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseCSV_AliasesAndOverrides(t *testing.T) {
+	const feed = "\ufeffLicense #,DBA Name,Address,City,State,Zip,Latitude,Longitude,Inspection Date\n" +
+		"1234,GIORDANO'S,730 N RUSH ST,CHICAGO,IL,60611,41.8958,-87.6257,01/02/2025\n" +
+		"1234,GIORDANO'S,730 N RUSH ST,CHICAGO,IL,60611,41.8958,-87.6257,06/02/2025\n" +
+		",,,,,,,,\n" +
+		"5678,PORTILLO'S,100 W ONTARIO ST,CHICAGO,IL,60654,,,06/02/2025\n"
+
+	recs, err := ParseCSV(strings.NewReader(feed), "chicago_inspections", map[string]string{
+		"id": "License #", "name": "DBA Name",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("got %d records, want 2 (repeat inspections collapsed, blank row skipped)", len(recs))
+	}
+	g := recs[0]
+	if g.Source != "chicago_inspections" || g.ExternalID != "1234" || g.Name != "GIORDANO'S" {
+		t.Errorf("record = %+v", g)
+	}
+	if g.Building != "730" || g.Street != "N RUSH ST" || g.Zipcode != "60611" || g.City != "CHICAGO" {
+		t.Errorf("address = %q %q %q %q", g.Building, g.Street, g.City, g.Zipcode)
+	}
+	if g.Latitude == nil || *g.Latitude != 41.8958 {
+		t.Errorf("latitude = %v", g.Latitude)
+	}
+	if recs[1].Latitude != nil {
+		t.Errorf("blank latitude parsed as %v", *recs[1].Latitude)
+	}
+}
+
+func TestParseCSV_UnknownOverrideColumn(t *testing.T) {
+	if _, err := ParseCSV(strings.NewReader("name\nX\n"), "x", map[string]string{"id": "nope"}); err == nil {
+		t.Error("expected error for a mapped column missing from the header")
+	}
+}
+
+func TestParseGeoJSON_OSMExtract(t *testing.T) {
+	const extract = `{"type":"FeatureCollection","features":[
+	  {"type":"Feature","id":"node/123456789","properties":{"name":"Kabab Café","addr:housenumber":"25-12","addr:street":"Steinway Street","addr:postcode":"11103","cuisine":"egyptian","phone":"+1 718 728 9858"},
+	   "geometry":{"type":"Point","coordinates":[-73.9167,40.7640]}},
+	  {"type":"Feature","properties":{"@id":1234567890123,"name":"Astoria Seafood"},
+	   "geometry":{"type":"Polygon","coordinates":[[[-73.92,40.76],[-73.91,40.76],[-73.91,40.77],[-73.92,40.77],[-73.92,40.76]]]}},
+	  {"type":"Feature","properties":{"amenity":"bench"},"geometry":{"type":"Point","coordinates":[-73.9,40.7]}}
+	]}`
+
+	recs, err := ParseGeoJSON([]byte(extract), "osm", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("got %d records, want 2 (unnamed feature skipped)", len(recs))
+	}
+	k := recs[0]
+	if k.ExternalID != "node/123456789" || k.Building != "25-12" || k.Street != "Steinway Street" || k.Cuisine != "egyptian" {
+		t.Errorf("point feature = %+v", k)
+	}
+	if *k.Latitude != 40.7640 || *k.Longitude != -73.9167 {
+		t.Errorf("point coords = %v,%v", *k.Latitude, *k.Longitude)
+	}
+	s := recs[1]
+	if s.ExternalID != "1234567890123" {
+		t.Errorf("numeric id = %q, want it kept as written", s.ExternalID)
+	}
+	if math.Abs(*s.Latitude-40.765) > 1e-9 || math.Abs(*s.Longitude+73.915) > 1e-9 {
+		t.Errorf("polygon centre = %v,%v", *s.Latitude, *s.Longitude)
+	}
+}
+
+func TestParseYelpBusinesses_FoodOnly(t *testing.T) {
+	const lines = `{"business_id":"abc","name":"Tacos El Gallo","address":"123 Main St","city":"Tucson","state":"AZ","postal_code":"85705","latitude":32.2,"longitude":-110.9,"categories":"Mexican, Restaurants"}
+{"business_id":"def","name":"Bright Smiles Dental","address":"9 Oak Ave","city":"Tucson","state":"AZ","postal_code":"85705","latitude":32.2,"longitude":-110.9,"categories":"Dentists, Health & Medical"}
+not json
+`
+	recs, err := ParseYelpBusinesses(strings.NewReader(lines))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 {
+		t.Fatalf("got %d records, want 1", len(recs))
+	}
+	r := recs[0]
+	if r.Source != SourceYelp || r.ExternalID != "abc" || r.Building != "123" || r.Street != "Main St" {
+		t.Errorf("record = %+v", r)
+	}
+	if got := r.FullAddress(); got != "123 Main St, Tucson, AZ 85705" {
+		t.Errorf("FullAddress = %q", got)
+	}
+}
@@ -0,0 +1,118 @@
+package directory
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"fodmap/data"
+)
+
+// YelpSource imports the Yelp Open Dataset business file. Path is either the
+// extracted yelp_academic_dataset_business.json (JSON lines) or the dataset
+// tar archive, from which the business file is read. Only businesses whose
+// categories include Restaurants or Food are returned; the file also lists
+// salons, dentists and the like.
+type YelpSource struct {
+	Path string
+}
+
+// Name implements Source.
+func (s *YelpSource) Name() string { return SourceYelp }
+
+// Fetch implements Source.
+func (s *YelpSource) Fetch(_ context.Context) ([]Record, error) {
+	if isArchive(s.Path) {
+		scanner, closer, err := data.GetArchive(s.Path, "business")
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = closer.Close() }()
+		return scanYelp(scanner)
+	}
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", s.Path, err)
+	}
+	defer func() { _ = f.Close() }()
+	return ParseYelpBusinesses(f)
+}
+
+func isArchive(path string) bool {
+	for _, ext := range []string{".tar", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// yelpBusiness is the subset of a Yelp business line used for import.
+type yelpBusiness struct {
+	BusinessID string  `json:"business_id"`
+	Name       string  `json:"name"`
+	Address    string  `json:"address"`
+	City       string  `json:"city"`
+	State      string  `json:"state"`
+	PostalCode string  `json:"postal_code"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	Categories string  `json:"categories"`
+}
+
+// ParseYelpBusinesses reads Yelp business JSON lines from r. Malformed lines
+// are skipped, matching data.BusinessMap.
+func ParseYelpBusinesses(r io.Reader) ([]Record, error) {
+	return scanYelp(bufio.NewScanner(r))
+}
+
+func scanYelp(scanner *bufio.Scanner) ([]Record, error) {
+	scanner.Buffer(make([]byte, 4*1024*1024), 4*1024*1024)
+	var records []Record
+	for scanner.Scan() {
+		var b yelpBusiness
+		if err := json.Unmarshal(scanner.Bytes(), &b); err != nil || b.BusinessID == "" {
+			continue
+		}
+		if !isFoodBusiness(b.Categories) {
+			continue
+		}
+		building, street := SplitAddress(b.Address)
+		rec := Record{
+			Source:     SourceYelp,
+			ExternalID: b.BusinessID,
+			Name:       b.Name,
+			Building:   building,
+			Street:     street,
+			City:       b.City,
+			State:      b.State,
+			Zipcode:    b.PostalCode,
+			Cuisine:    b.Categories,
+		}
+		if b.Latitude != 0 || b.Longitude != 0 {
+			lat, lon := b.Latitude, b.Longitude
+			rec.Latitude, rec.Longitude = &lat, &lon
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanning yelp businesses: %w", err)
+	}
+	return records, nil
+}
+
+// isFoodBusiness reports whether a Yelp categories list marks a place that
+// serves food.
+func isFoodBusiness(categories string) bool {
+	for c := range strings.SplitSeq(categories, ",") {
+		switch strings.TrimSpace(c) {
+		case "Restaurants", "Food":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,259 @@
+// Package egress manages the outbound identities scrapers fetch through: a
+// pool of exits (HTTP, HTTPS or SOCKS5 proxies, or the direct connection),
+// each presenting a fixed User-Agent profile. An exit that a domain answers
+// with 403 or 429 cools down for that domain with exponential backoff; an
+// exit whose connection fails cools down for every domain. Per-domain block
+// rates are kept in memory for the admin API.
+//
+// One Pool is meant to be shared by every fetcher in a process, like
+// crawlpolicy.Policy, so all workers see the same exit health.
+package egress
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"fodmap/crawlpolicy"
+)
+
+// Defaults applied by New when the corresponding Config field is zero.
+const (
+	DefaultCooldown      = 5 * time.Minute
+	DefaultMaxCooldown   = 2 * time.Hour
+	DefaultErrorCooldown = 30 * time.Second
+)
+
+// ErrNoEgress is returned by a Pool client when every exit is cooling down
+// for the requested domain.
+var ErrNoEgress = errors.New("no egress available")
+
+// Profile is the client identity an exit presents. Headers are set on every
+// request after UserAgent, so they may override it.
+type Profile struct {
+	UserAgent string
+	Headers   map[string]string
+}
+
+// Config configures a Pool.
+type Config struct {
+	// Proxies are proxy URLs with scheme http, https, socks5 or socks5h.
+	// Empty means a single direct exit.
+	Proxies []string
+	// Profiles are assigned to exits round-robin, so exit i always presents
+	// Profiles[i % len(Profiles)]. Empty leaves the caller's headers as-is.
+	Profiles []Profile
+	// Cooldown is how long an exit rests for a domain after its first 403 or
+	// 429; each further consecutive block doubles it, up to MaxCooldown. A
+	// longer Retry-After on a 429 wins.
+	Cooldown    time.Duration
+	MaxCooldown time.Duration
+	// ErrorCooldown is how long an exit rests for all domains after a
+	// connection error.
+	ErrorCooldown time.Duration
+}
+
+// Pool hands out exits and tracks their health. It is safe for concurrent
+// use.
+type Pool struct {
+	exits         []*exit
+	cooldown      time.Duration
+	maxCooldown   time.Duration
+	errorCooldown time.Duration
+	now           func() time.Time
+
+	mu      sync.Mutex
+	next    int
+	domains map[string]*DomainStats
+}
+
+// exit is one outbound route. Fields below transport are guarded by Pool.mu.
+type exit struct {
+	name      string // redacted proxy URL, or "direct"
+	profile   Profile
+	transport http.RoundTripper
+
+	downUntil time.Time
+	blocked   map[string]block
+	requests  int
+	blocks    int
+	errors    int
+}
+
+// block is an exit's cooldown for one domain.
+type block struct {
+	until   time.Time
+	strikes int
+}
+
+// New builds a Pool from cfg. It fails if a proxy URL cannot be parsed or has
+// an unsupported scheme.
+func New(cfg Config) (*Pool, error) {
+	if cfg.Cooldown == 0 {
+		cfg.Cooldown = DefaultCooldown
+	}
+	if cfg.MaxCooldown == 0 {
+		cfg.MaxCooldown = DefaultMaxCooldown
+	}
+	if cfg.ErrorCooldown == 0 {
+		cfg.ErrorCooldown = DefaultErrorCooldown
+	}
+
+	p := &Pool{
+		cooldown:      cfg.Cooldown,
+		maxCooldown:   cfg.MaxCooldown,
+		errorCooldown: cfg.ErrorCooldown,
+		now:           time.Now,
+		domains:       make(map[string]*DomainStats),
+	}
+	if len(cfg.Proxies) == 0 {
+		p.exits = append(p.exits, &exit{name: "direct", transport: http.DefaultTransport})
+	}
+	for _, raw := range cfg.Proxies {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("parsing proxy %q: %w", raw, err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("proxy %s: unsupported scheme %q", u.Redacted(), u.Scheme)
+		}
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.Proxy = http.ProxyURL(u)
+		p.exits = append(p.exits, &exit{name: u.Redacted(), transport: t})
+	}
+	for i, e := range p.exits {
+		e.blocked = make(map[string]block)
+		if len(cfg.Profiles) > 0 {
+			e.profile = cfg.Profiles[i%len(cfg.Profiles)]
+		}
+	}
+	return p, nil
+}
+
+// Client returns an *http.Client with the given timeout whose requests are
+// routed through the pool.
+func (p *Pool) Client(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: &transport{pool: p}}
+}
+
+// pick returns the next exit, in round-robin order, that is neither down nor
+// cooling down for domain.
+func (p *Pool) pick(domain string) (*exit, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	var soonest time.Time
+	for i := range p.exits {
+		e := p.exits[(p.next+i)%len(p.exits)]
+		until := e.downUntil
+		if b, ok := e.blocked[domain]; ok && b.until.After(until) {
+			until = b.until
+		}
+		if !now.Before(until) {
+			p.next = (p.next + i + 1) % len(p.exits)
+			return e, nil
+		}
+		if soonest.IsZero() || until.Before(soonest) {
+			soonest = until
+		}
+	}
+	return nil, fmt.Errorf("%w for %s: all %d exits cooling down until %s",
+		ErrNoEgress, domain, len(p.exits), soonest.UTC().Format(time.RFC3339))
+}
+
+// record updates e's health and domain's counters with the outcome of one
+// request. A cancelled request is not held against the exit.
+func (p *Pool) record(e *exit, domain string, resp *http.Response, err error, cancelled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	d := p.domain(domain)
+	e.requests++
+	d.Requests++
+
+	switch {
+	case err != nil:
+		if cancelled {
+			return
+		}
+		e.errors++
+		d.Errors++
+		e.downUntil = now.Add(p.errorCooldown)
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		e.blocks++
+		d.Blocked++
+		b := e.blocked[domain]
+		b.strikes++
+		wait := min(p.cooldown<<(b.strikes-1), p.maxCooldown)
+		if wait <= 0 { // shift overflow after many strikes
+			wait = p.maxCooldown
+		}
+		if ra := retryAfter(resp, now); ra > wait {
+			wait = ra
+		}
+		b.until = now.Add(wait)
+		e.blocked[domain] = b
+	default:
+		delete(e.blocked, domain)
+	}
+}
+
+// domain returns the stats entry for domain, creating it. p.mu must be held.
+func (p *Pool) domain(domain string) *DomainStats {
+	d, ok := p.domains[domain]
+	if !ok {
+		d = &DomainStats{Domain: domain}
+		p.domains[domain] = d
+	}
+	return d
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+// It returns zero if the header is absent or malformed.
+func retryAfter(resp *http.Response, now time.Time) time.Duration {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// transport routes each request through an exit picked from pool.
+type transport struct {
+	pool *Pool
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	domain := crawlpolicy.Domain(req.URL.Hostname())
+	e, err := t.pool.pick(domain)
+	if err != nil {
+		return nil, err
+	}
+	if e.profile.UserAgent != "" || len(e.profile.Headers) > 0 {
+		req = req.Clone(req.Context())
+		if e.profile.UserAgent != "" {
+			req.Header.Set("User-Agent", e.profile.UserAgent)
+		}
+		for k, v := range e.profile.Headers {
+			req.Header.Set(k, v)
+		}
+	}
+	resp, err := e.transport.RoundTrip(req)
+	t.pool.record(e, domain, resp, err, req.Context().Err() != nil)
+	return resp, err
+}
@@ -0,0 +1,155 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// proxyStandIn is a local forward-proxy stand-in: it answers absolute-URI
+// requests itself with status and records what it saw.
+type proxyStandIn struct {
+	*httptest.Server
+	mu     sync.Mutex
+	status map[string]int // by target host; default 200
+	hosts  []string
+	agents []string
+}
+
+func newProxyStandIn(t *testing.T) *proxyStandIn {
+	t.Helper()
+	p := &proxyStandIn{status: make(map[string]int)}
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		p.hosts = append(p.hosts, r.URL.Host)
+		p.agents = append(p.agents, r.Header.Get("User-Agent"))
+		status := p.status[r.URL.Host]
+		p.mu.Unlock()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *proxyStandIn) seen() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.hosts)
+}
+
+func get(t *testing.T, c *http.Client, rawURL string) (int, error) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, rawURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("User-Agent", "caller/1.0")
+	resp, err := c.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func TestPool_RotatesExitsWithProfiles(t *testing.T) {
+	a, b := newProxyStandIn(t), newProxyStandIn(t)
+	pool, err := New(Config{
+		Proxies:  []string{a.URL, b.URL},
+		Profiles: []Profile{{UserAgent: "ua-a"}, {UserAgent: "ua-b"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := pool.Client(5 * time.Second)
+
+	for range 4 {
+		if _, err := get(t, c, "http://menu.test/page"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if a.seen() != 2 || b.seen() != 2 {
+		t.Fatalf("requests per proxy = %d/%d, want 2/2", a.seen(), b.seen())
+	}
+	if a.agents[0] != "ua-a" || b.agents[0] != "ua-b" {
+		t.Errorf("user agents = %q/%q, want ua-a/ua-b", a.agents[0], b.agents[0])
+	}
+}
+
+func TestPool_CooldownAfterBlock(t *testing.T) {
+	a, b := newProxyStandIn(t), newProxyStandIn(t)
+	a.status["blocked.test"] = http.StatusTooManyRequests
+	b.status["blocked.test"] = http.StatusForbidden
+	pool, err := New(Config{Proxies: []string{a.URL, b.URL}, Cooldown: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	pool.now = func() time.Time { return now }
+	c := pool.Client(5 * time.Second)
+
+	for _, want := range []int{http.StatusTooManyRequests, http.StatusForbidden} {
+		if got, err := get(t, c, "http://blocked.test/"); err != nil || got != want {
+			t.Fatalf("status = %d, %v; want %d", got, err, want)
+		}
+	}
+	if _, err := get(t, c, "http://blocked.test/"); !errors.Is(err, ErrNoEgress) {
+		t.Fatalf("err = %v, want ErrNoEgress while both exits cool down", err)
+	}
+	// A block is per domain: other domains still use both exits.
+	if _, err := get(t, c, "http://open.test/"); err != nil {
+		t.Fatalf("other domain: %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := get(t, c, "http://blocked.test/"); err != nil {
+		t.Fatalf("after cooldown: %v", err)
+	}
+
+	stats := pool.Stats()
+	if len(stats.Domains) != 2 || stats.Domains[0].Domain != "blocked.test" {
+		t.Fatalf("domains = %+v", stats.Domains)
+	}
+	if d := stats.Domains[0]; d.Requests != 3 || d.Blocked != 3 || d.BlockRate != 1 {
+		t.Errorf("blocked.test stats = %+v, want 3 requests all blocked", d)
+	}
+}
+
+func TestPool_ExitDownAfterConnectionError(t *testing.T) {
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+	live := newProxyStandIn(t)
+	pool, err := New(Config{Proxies: []string{dead.URL, live.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := pool.Client(5 * time.Second)
+
+	if _, err := get(t, c, "http://menu.test/"); err == nil {
+		t.Fatal("expected connection error through dead proxy")
+	}
+	for range 3 {
+		if _, err := get(t, c, "http://other.test/"); err != nil {
+			t.Fatalf("live exit: %v", err)
+		}
+	}
+	if live.seen() != 3 {
+		t.Errorf("live proxy saw %d requests, want 3", live.seen())
+	}
+	if s := pool.Stats(); s.Exits[0].DownUntil == nil || s.Exits[0].Errors != 1 {
+		t.Errorf("dead exit stats = %+v", s.Exits[0])
+	}
+}
+
+func TestNew_RejectsUnsupportedScheme(t *testing.T) {
+	if _, err := New(Config{Proxies: []string{"ftp://proxy.example:21"}}); err == nil {
+		t.Error("expected error for ftp proxy")
+	}
+}
@@ -0,0 +1,79 @@
+package egress
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// DomainStats counts requests to one domain across all exits. Blocked counts
+// 403 and 429 responses; Errors counts connection failures.
+type DomainStats struct {
+	Domain    string  `json:"domain"`
+	Requests  int     `json:"requests"`
+	Blocked   int     `json:"blocked"`
+	Errors    int     `json:"errors"`
+	BlockRate float64 `json:"block_rate"`
+}
+
+// ExitStats describes one exit's traffic and current health.
+type ExitStats struct {
+	Name      string     `json:"name"`
+	UserAgent string     `json:"user_agent,omitempty"`
+	Requests  int        `json:"requests"`
+	Blocked   int        `json:"blocked"`
+	Errors    int        `json:"errors"`
+	DownUntil *time.Time `json:"down_until,omitempty"`
+	// CoolingDomains lists the domains this exit is currently resting for.
+	CoolingDomains []string `json:"cooling_domains,omitempty"`
+}
+
+// Stats is a point-in-time snapshot of a Pool.
+type Stats struct {
+	Exits   []ExitStats   `json:"exits"`
+	Domains []DomainStats `json:"domains"`
+}
+
+// Stats returns a snapshot of the pool. Domains are ordered by block rate,
+// highest first, then by name.
+func (p *Pool) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	var s Stats
+	for _, e := range p.exits {
+		es := ExitStats{
+			Name:      e.name,
+			UserAgent: e.profile.UserAgent,
+			Requests:  e.requests,
+			Blocked:   e.blocks,
+			Errors:    e.errors,
+		}
+		if now.Before(e.downUntil) {
+			t := e.downUntil
+			es.DownUntil = &t
+		}
+		for domain, b := range e.blocked {
+			if now.Before(b.until) {
+				es.CoolingDomains = append(es.CoolingDomains, domain)
+			}
+		}
+		slices.Sort(es.CoolingDomains)
+		s.Exits = append(s.Exits, es)
+	}
+	for _, d := range p.domains {
+		ds := *d
+		if ds.Requests > 0 {
+			ds.BlockRate = float64(ds.Blocked) / float64(ds.Requests)
+		}
+		s.Domains = append(s.Domains, ds)
+	}
+	slices.SortFunc(s.Domains, func(a, b DomainStats) int {
+		if c := cmp.Compare(b.BlockRate, a.BlockRate); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Domain, b.Domain)
+	})
+	return s
+}
@@ -0,0 +1,146 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Area is a named neighbourhood used to scope restaurant imports and admin
+// views. A place is in the area if it matches any of the filters: one of the
+// NTA codes, zip codes or boroughs; an NTA whose zips are restricted by
+// NTAZipRestrict; or coordinates inside BBox or Geometry.
+type Area struct {
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	NTAs        []string `json:"ntas,omitempty" yaml:"ntas,omitempty"`
+	// NTAZipRestrict includes only the listed zip codes of an NTA, for NTAs
+	// that straddle the area's edge.
+	NTAZipRestrict map[string][]string `json:"nta_zip_restrict,omitempty" yaml:"nta_zip_restrict,omitempty"`
+	Zipcodes       []string            `json:"zipcodes,omitempty" yaml:"zipcodes,omitempty"`
+	Boroughs       []string            `json:"boroughs,omitempty" yaml:"boroughs,omitempty"`
+	BBox           *BBox               `json:"bbox,omitempty" yaml:"bbox,omitempty"`
+	Geometry       *Geometry           `json:"geometry,omitempty" yaml:"geometry,omitempty"`
+}
+
+// Place is the location of a restaurant as far as area membership is
+// concerned. Lat and Lon are nil when the source has no coordinates.
+type Place struct {
+	NTA     string
+	Zipcode string
+	Boro    string
+	Lat     *float64
+	Lon     *float64
+}
+
+// areaNameRE restricts names to URL- and filename-safe slugs, since they
+// appear in admin API paths and bronze file names.
+var areaNameRE = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Validate checks that a has a slug name, at least one filter, and
+// well-formed geometry.
+func (a Area) Validate() error {
+	if !areaNameRE.MatchString(a.Name) {
+		return fmt.Errorf("area name %q must be a lowercase slug (a-z, 0-9, -)", a.Name)
+	}
+	if len(a.NTAs) == 0 && len(a.NTAZipRestrict) == 0 && len(a.Zipcodes) == 0 &&
+		len(a.Boroughs) == 0 && a.BBox == nil && a.Geometry == nil {
+		return fmt.Errorf("area %q has no NTA, zip, borough or geometry filters", a.Name)
+	}
+	if a.BBox != nil {
+		if err := a.BBox.Validate(); err != nil {
+			return fmt.Errorf("area %q: %w", a.Name, err)
+		}
+	}
+	if a.Geometry != nil {
+		if err := a.Geometry.Validate(); err != nil {
+			return fmt.Errorf("area %q: %w", a.Name, err)
+		}
+	}
+	return nil
+}
+
+// Bounds returns the box enclosing a's BBox and Geometry, and false when a
+// has neither.
+func (a Area) Bounds() (BBox, bool, error) {
+	var b BBox
+	ok := false
+	if a.BBox != nil {
+		b, ok = *a.BBox, true
+	}
+	if a.Geometry != nil {
+		gb, err := a.Geometry.Bounds()
+		if err != nil {
+			return BBox{}, false, err
+		}
+		if ok {
+			b = b.union(gb)
+		} else {
+			b, ok = gb, true
+		}
+	}
+	return b, ok, nil
+}
+
+// Match reports whether p is in a. Use Matcher when testing many places
+// against the same area.
+func (a Area) Match(p Place) bool {
+	m, err := a.Matcher()
+	if err != nil {
+		return false
+	}
+	return m.Match(p)
+}
+
+// Matcher is an Area with its geometry decoded once for repeated membership
+// tests. It is safe for concurrent use.
+type Matcher struct {
+	area  Area
+	polys []polygon
+}
+
+// ErrInvalidArea is returned by Matcher for an area that fails Validate.
+var ErrInvalidArea = errors.New("invalid area")
+
+// Matcher compiles a for repeated Match calls.
+func (a Area) Matcher() (*Matcher, error) {
+	if err := a.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidArea, err)
+	}
+	m := &Matcher{area: a}
+	if a.Geometry != nil {
+		polys, err := a.Geometry.polygons()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidArea, err)
+		}
+		m.polys = polys
+	}
+	return m, nil
+}
+
+// Match reports whether p is in the compiled area.
+func (m *Matcher) Match(p Place) bool {
+	a := m.area
+	if p.NTA != "" && slices.Contains(a.NTAs, p.NTA) {
+		return true
+	}
+	if zips, ok := a.NTAZipRestrict[p.NTA]; ok && p.NTA != "" && slices.Contains(zips, p.Zipcode) {
+		return true
+	}
+	if p.Zipcode != "" && slices.Contains(a.Zipcodes, p.Zipcode) {
+		return true
+	}
+	if p.Boro != "" && slices.ContainsFunc(a.Boroughs, func(b string) bool { return strings.EqualFold(b, p.Boro) }) {
+		return true
+	}
+	if p.Lat == nil || p.Lon == nil {
+		return false
+	}
+	lat, lon := *p.Lat, *p.Lon
+	if a.BBox != nil && a.BBox.Contains(lat, lon) {
+		return true
+	}
+	return slices.ContainsFunc(m.polys, func(poly polygon) bool { return poly.contains(lat, lon) })
+}
@@ -0,0 +1,100 @@
+package geo
+
+import (
+	"testing"
+)
+
+func ptr(f float64) *float64 { return &f }
+
+// square is a 1°×1° polygon with a 0.5°×0.5° hole in its middle.
+const squareGeoJSON = `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Polygon","coordinates":[
+	[[-74,40],[-73,40],[-73,41],[-74,41],[-74,40]],
+	[[-73.75,40.25],[-73.25,40.25],[-73.25,40.75],[-73.75,40.75],[-73.75,40.25]]
+]}}]}`
+
+func TestParseGeoJSON_PointInPolygon(t *testing.T) {
+	g, err := ParseGeoJSON([]byte(squareGeoJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		lat, lon float64
+		want     bool
+	}{
+		{"inside", 40.1, -73.9, true},
+		{"in hole", 40.5, -73.5, false},
+		{"outside", 41.5, -73.5, false},
+	}
+	for _, tt := range tests {
+		if got := g.Contains(tt.lat, tt.lon); got != tt.want {
+			t.Errorf("%s: Contains(%v, %v) = %v, want %v", tt.name, tt.lat, tt.lon, got, tt.want)
+		}
+	}
+	b, err := g.Bounds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b != (BBox{-74, 40, -73, 41}) {
+		t.Errorf("Bounds = %v", b)
+	}
+}
+
+func TestParseGeoJSON_RejectsPoints(t *testing.T) {
+	if _, err := ParseGeoJSON([]byte(`{"type":"Feature","geometry":{"type":"Point","coordinates":[-73.9,40.7]}}`)); err == nil {
+		t.Error("expected error for Point feature")
+	}
+}
+
+func TestArea_Match(t *testing.T) {
+	g, err := ParseGeoJSON([]byte(squareGeoJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	area := Area{
+		Name:           "test-area",
+		NTAs:           []string{"QN70"},
+		NTAZipRestrict: map[string][]string{"QN31": {"11101"}},
+		Boroughs:       []string{"Brooklyn"},
+		Geometry:       g,
+	}
+	m, err := area.Matcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		place Place
+		want  bool
+	}{
+		{"listed NTA", Place{NTA: "QN70"}, true},
+		{"restricted NTA, listed zip", Place{NTA: "QN31", Zipcode: "11101"}, true},
+		{"restricted NTA, other zip", Place{NTA: "QN31", Zipcode: "11104"}, false},
+		{"borough is case-insensitive", Place{Boro: "BROOKLYN"}, true},
+		{"inside polygon", Place{NTA: "MN01", Lat: ptr(40.1), Lon: ptr(-73.9)}, true},
+		{"polygon hole", Place{NTA: "MN01", Lat: ptr(40.5), Lon: ptr(-73.5)}, false},
+		{"no coordinates", Place{NTA: "MN01"}, false},
+	}
+	for _, tt := range tests {
+		if got := m.Match(tt.place); got != tt.want {
+			t.Errorf("%s: Match = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestArea_Validate(t *testing.T) {
+	bad := []Area{
+		{Name: "Astoria LIC", NTAs: []string{"QN70"}},
+		{Name: "empty"},
+		{Name: "flipped", BBox: &BBox{-73, 41, -74, 40}},
+		{Name: "bad-geometry", Geometry: &Geometry{Type: "Point", Coordinates: []float64{1, 2}}},
+	}
+	for _, a := range bad {
+		if err := a.Validate(); err == nil {
+			t.Errorf("Validate(%q) = nil, want error", a.Name)
+		}
+	}
+	if err := (Area{Name: "brooklyn", Boroughs: []string{"Brooklyn"}}).Validate(); err != nil {
+		t.Errorf("Validate(brooklyn) = %v", err)
+	}
+}
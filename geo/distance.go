@@ -0,0 +1,55 @@
+package geo
+
+import (
+	"errors"
+	"math"
+)
+
+// earthRadiusMeters is the mean Earth radius.
+const earthRadiusMeters = 6371008.8
+
+// Distance returns the great-circle (haversine) distance in metres between
+// two WGS84 points.
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(h))
+}
+
+// Point is a WGS84 coordinate.
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// Validate checks that p is within WGS84 range.
+func (p Point) Validate() error {
+	if p.Lat < -90 || p.Lat > 90 || p.Lon < -180 || p.Lon > 180 {
+		return errors.New("point is outside WGS84 range")
+	}
+	return nil
+}
+
+// DistanceTo returns the great-circle distance in metres from p to (lat, lon).
+func (p Point) DistanceTo(lat, lon float64) float64 {
+	return Distance(p.Lat, p.Lon, lat, lon)
+}
+
+// BoundsWithin returns a box enclosing every point within radius metres of
+// p, for index prefilters ahead of an exact Distance check. Near the poles
+// the box widens to the full longitude range.
+func (p Point) BoundsWithin(radius float64) BBox {
+	dLat := radius / (earthRadiusMeters * math.Pi / 180)
+	cos := math.Cos(p.Lat * math.Pi / 180)
+	dLon := 180.0
+	if cos > 1e-6 {
+		dLon = min(dLat/cos, 180)
+	}
+	return BBox{
+		max(p.Lon-dLon, -180), max(p.Lat-dLat, -90),
+		min(p.Lon+dLon, 180), min(p.Lat+dLat, 90),
+	}
+}
@@ -0,0 +1,191 @@
+// Package geo holds the geographic primitives shared by restaurant import,
+// admin area management and location filtering: bounding boxes, GeoJSON
+// polygons with point-in-polygon tests, and named Areas.
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// BBox is a bounding box in GeoJSON order: [minLon, minLat, maxLon, maxLat].
+type BBox [4]float64
+
+// MinLon, MinLat, MaxLon and MaxLat name the box's edges.
+func (b BBox) MinLon() float64 { return b[0] }
+func (b BBox) MinLat() float64 { return b[1] }
+func (b BBox) MaxLon() float64 { return b[2] }
+func (b BBox) MaxLat() float64 { return b[3] }
+
+// Contains reports whether (lat, lon) lies inside or on the edge of b.
+func (b BBox) Contains(lat, lon float64) bool {
+	return lat >= b.MinLat() && lat <= b.MaxLat() && lon >= b.MinLon() && lon <= b.MaxLon()
+}
+
+// Validate checks that b's corners are ordered and within WGS84 range.
+func (b BBox) Validate() error {
+	if b.MinLon() > b.MaxLon() || b.MinLat() > b.MaxLat() {
+		return errors.New("bbox must be [minLon, minLat, maxLon, maxLat]")
+	}
+	if b.MinLon() < -180 || b.MaxLon() > 180 || b.MinLat() < -90 || b.MaxLat() > 90 {
+		return errors.New("bbox is outside WGS84 range")
+	}
+	return nil
+}
+
+// union returns the smallest box containing b and o.
+func (b BBox) union(o BBox) BBox {
+	return BBox{min(b[0], o[0]), min(b[1], o[1]), max(b[2], o[2]), max(b[3], o[3])}
+}
+
+// ring is a closed linear ring of [lon, lat] positions.
+type ring [][2]float64
+
+// polygon is an outer ring followed by zero or more holes.
+type polygon []ring
+
+// Geometry is a GeoJSON Polygon or MultiPolygon geometry object. Coordinates
+// is kept untyped so the same struct decodes from JSON and YAML; Validate
+// checks its shape.
+type Geometry struct {
+	Type        string `json:"type" yaml:"type"`
+	Coordinates any    `json:"coordinates" yaml:"coordinates"`
+}
+
+// polygons decodes g's coordinates into polygons.
+func (g *Geometry) polygons() ([]polygon, error) {
+	raw, err := json.Marshal(g.Coordinates)
+	if err != nil {
+		return nil, fmt.Errorf("encoding coordinates: %w", err)
+	}
+	var polys []polygon
+	switch g.Type {
+	case "Polygon":
+		var p polygon
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return nil, fmt.Errorf("decoding Polygon coordinates: %w", err)
+		}
+		polys = []polygon{p}
+	case "MultiPolygon":
+		if err := json.Unmarshal(raw, &polys); err != nil {
+			return nil, fmt.Errorf("decoding MultiPolygon coordinates: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported geometry type %q (want Polygon or MultiPolygon)", g.Type)
+	}
+	for i, p := range polys {
+		if len(p) == 0 {
+			return nil, fmt.Errorf("polygon %d has no rings", i)
+		}
+		for j, r := range p {
+			if len(r) < 4 {
+				return nil, fmt.Errorf("polygon %d ring %d has %d positions, want at least 4", i, j, len(r))
+			}
+		}
+	}
+	return polys, nil
+}
+
+// Validate checks that g is a well-formed Polygon or MultiPolygon.
+func (g *Geometry) Validate() error {
+	_, err := g.polygons()
+	return err
+}
+
+// Contains reports whether (lat, lon) lies inside g: inside some polygon's
+// outer ring and outside all of that polygon's holes. A malformed geometry
+// contains nothing.
+func (g *Geometry) Contains(lat, lon float64) bool {
+	polys, err := g.polygons()
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(polys, func(p polygon) bool { return p.contains(lat, lon) })
+}
+
+// Bounds returns the bounding box of g's outer rings.
+func (g *Geometry) Bounds() (BBox, error) {
+	polys, err := g.polygons()
+	if err != nil {
+		return BBox{}, err
+	}
+	b := BBox{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	for _, p := range polys {
+		for _, pos := range p[0] {
+			b = b.union(BBox{pos[0], pos[1], pos[0], pos[1]})
+		}
+	}
+	return b, nil
+}
+
+// contains reports whether (lat, lon) is inside p's outer ring and outside
+// all of its holes.
+func (p polygon) contains(lat, lon float64) bool {
+	if !p[0].contains(lat, lon) {
+		return false
+	}
+	return !slices.ContainsFunc(p[1:], func(h ring) bool { return h.contains(lat, lon) })
+}
+
+// contains is the even-odd ray-casting test for a single ring.
+func (r ring) contains(lat, lon float64) bool {
+	in := false
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		xi, yi := r[i][0], r[i][1]
+		xj, yj := r[j][0], r[j][1]
+		if (yi > lat) != (yj > lat) && lon < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			in = !in
+		}
+	}
+	return in
+}
+
+// ParseGeoJSON reads a GeoJSON Polygon or MultiPolygon geometry, Feature, or
+// FeatureCollection and returns its polygons merged into one MultiPolygon.
+// Non-polygon features are rejected so an area never silently loses shape.
+func ParseGeoJSON(data []byte) (*Geometry, error) {
+	var doc struct {
+		Type     string          `json:"type"`
+		Geometry json.RawMessage `json:"geometry"`
+		Features []struct {
+			Geometry json.RawMessage `json:"geometry"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("decoding GeoJSON: %w", err)
+	}
+
+	var geometries []json.RawMessage
+	switch doc.Type {
+	case "Polygon", "MultiPolygon":
+		geometries = []json.RawMessage{data}
+	case "Feature":
+		geometries = []json.RawMessage{doc.Geometry}
+	case "FeatureCollection":
+		for _, f := range doc.Features {
+			geometries = append(geometries, f.Geometry)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported GeoJSON type %q", doc.Type)
+	}
+
+	var merged []polygon
+	for i, raw := range geometries {
+		var g Geometry
+		if err := json.Unmarshal(raw, &g); err != nil {
+			return nil, fmt.Errorf("decoding geometry %d: %w", i, err)
+		}
+		polys, err := g.polygons()
+		if err != nil {
+			return nil, fmt.Errorf("geometry %d: %w", i, err)
+		}
+		merged = append(merged, polys...)
+	}
+	if len(merged) == 0 {
+		return nil, errors.New("GeoJSON contains no polygons")
+	}
+	return &Geometry{Type: "MultiPolygon", Coordinates: merged}, nil
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_restaurants_lat_lon;
+DROP TABLE IF EXISTS geo_areas;
@@ -0,0 +1,21 @@
+-- Named geographic areas that scope restaurant imports. The definition is a
+-- geo.Area document (NTA codes, zip codes, boroughs, bbox, GeoJSON geometry).
+CREATE TABLE IF NOT EXISTS geo_areas (
+    name        TEXT PRIMARY KEY,
+    definition  JSONB NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER trg_geo_areas_updated_at BEFORE UPDATE ON geo_areas FOR EACH ROW EXECUTE FUNCTION touch_updated_at();
+
+-- Seed the area that was previously compiled into menusearch.Areas.
+INSERT INTO geo_areas (name, definition) VALUES (
+    'astoria-lic',
+    '{"name": "astoria-lic", "description": "Astoria and Long Island City, Queens",
+      "ntas": ["QN70", "QN71", "QN72", "QN68"],
+      "nta_zip_restrict": {"QN31": ["11101", "11109"]}}'
+) ON CONFLICT (name) DO NOTHING;
+
+-- Bounding-box prefilter for area membership queries.
+CREATE INDEX IF NOT EXISTS idx_restaurants_lat_lon ON restaurants(latitude, longitude);
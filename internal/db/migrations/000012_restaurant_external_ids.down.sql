@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_restaurants_phone_digits;
+DROP TABLE IF EXISTS restaurant_external_ids;
@@ -0,0 +1,27 @@
+-- Source-specific identifiers for restaurants imported from directory
+-- sources (NYC DOHMH, Yelp, other open-data feeds, OSM). One restaurant may
+-- carry IDs from several sources once entity resolution merges them.
+CREATE TABLE IF NOT EXISTS restaurant_external_ids (
+    source         TEXT NOT NULL,
+    external_id    TEXT NOT NULL,
+    restaurant_id  UUID NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (source, external_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_restaurant_external_ids_restaurant ON restaurant_external_ids(restaurant_id);
+
+CREATE TRIGGER trg_restaurant_external_ids_updated_at BEFORE UPDATE ON restaurant_external_ids FOR EACH ROW EXECUTE FUNCTION touch_updated_at();
+
+-- Backfill from the per-source columns.
+INSERT INTO restaurant_external_ids (source, external_id, restaurant_id)
+SELECT 'nyc_dohmh', camis, id FROM restaurants WHERE camis IS NOT NULL
+ON CONFLICT DO NOTHING;
+INSERT INTO restaurant_external_ids (source, external_id, restaurant_id)
+SELECT 'yelp', yelp_id, id FROM restaurants WHERE yelp_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- Entity-resolution lookup by the last ten digits of the phone number.
+CREATE INDEX IF NOT EXISTS idx_restaurants_phone_digits
+    ON restaurants (right(regexp_replace(COALESCE(phone, ''), '\D', '', 'g'), 10));
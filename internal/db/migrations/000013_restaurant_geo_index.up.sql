@@ -0,0 +1,10 @@
+-- Radius search over restaurant coordinates. earthdistance (on cube) ships
+-- with Postgres contrib, so it needs no extra image the way PostGIS would;
+-- the GiST index serves earth_box containment for radius filters and <->
+-- KNN ordering for nearest-first listings.
+CREATE EXTENSION IF NOT EXISTS cube;
+CREATE EXTENSION IF NOT EXISTS earthdistance;
+
+CREATE INDEX IF NOT EXISTS idx_restaurants_earth ON restaurants
+    USING gist (ll_to_earth(latitude, longitude))
+    WHERE latitude IS NOT NULL AND longitude IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS menu_item_changes;
+DROP TABLE IF EXISTS menu_snapshots;
+DROP INDEX IF EXISTS idx_menu_items_business_source;
+ALTER TABLE menu_items DROP COLUMN IF EXISTS removed_at;
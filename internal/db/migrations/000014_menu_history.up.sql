@@ -0,0 +1,38 @@
+-- Menu versioning. Each scrape of a menu URL records a snapshot of the items
+-- it found and the item-level changes against the previous scrape of that
+-- URL. Items that disappear from the menu are soft-deleted via removed_at so
+-- history and conversations that reference them keep resolving.
+ALTER TABLE menu_items ADD COLUMN IF NOT EXISTS removed_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_menu_items_business_source ON menu_items(business_id, source_url) WHERE removed_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS menu_snapshots (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    business_id  UUID NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    source_url   TEXT NOT NULL,
+    scraped_at   TIMESTAMPTZ NOT NULL,
+    item_count   INTEGER NOT NULL,
+    -- Items as scraped, without embeddings, so a past menu can be shown whole.
+    items        JSONB NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_menu_snapshots_business ON menu_snapshots(business_id, scraped_at DESC);
+
+CREATE TABLE IF NOT EXISTS menu_item_changes (
+    id               BIGSERIAL PRIMARY KEY,
+    snapshot_id      UUID NOT NULL REFERENCES menu_snapshots(id) ON DELETE CASCADE,
+    menu_item_id     TEXT NOT NULL,
+    dish_name        TEXT NOT NULL,
+    menu_section     TEXT,
+    change_type      TEXT NOT NULL CHECK (change_type IN ('added', 'removed', 'price_changed', 'ingredients_changed', 'description_changed')),
+    old_price        NUMERIC(10, 2),
+    new_price        NUMERIC(10, 2),
+    old_ingredients  TEXT[],
+    new_ingredients  TEXT[],
+    old_description  TEXT,
+    new_description  TEXT,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_menu_item_changes_snapshot ON menu_item_changes(snapshot_id);
+CREATE INDEX IF NOT EXISTS idx_menu_item_changes_item ON menu_item_changes(menu_item_id);
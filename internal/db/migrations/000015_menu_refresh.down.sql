@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_conversations_business_id;
+DROP TABLE IF EXISTS restaurant_favorites;
+ALTER TABLE restaurants DROP COLUMN IF EXISTS refresh_attempts;
+ALTER TABLE restaurants DROP COLUMN IF EXISTS refresh_queued_at;
@@ -0,0 +1,17 @@
+-- Scheduled menu re-scrapes. refresh_queued_at is the last time the refresh
+-- policy queued a restaurant and refresh_attempts counts refreshes queued
+-- since its last successful scrape; together they drive the retry backoff.
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS refresh_queued_at TIMESTAMPTZ;
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS refresh_attempts INTEGER NOT NULL DEFAULT 0;
+
+-- Restaurants a user has saved. Favorites are a demand signal for the
+-- refresh policy: a favorited menu is re-scraped sooner.
+CREATE TABLE IF NOT EXISTS restaurant_favorites (
+    user_id      TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    business_id  UUID NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, business_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_restaurant_favorites_business ON restaurant_favorites(business_id);
+CREATE INDEX IF NOT EXISTS idx_conversations_business_id ON conversations(business_id);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS extraction_quality;
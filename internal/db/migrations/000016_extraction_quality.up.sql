@@ -0,0 +1,21 @@
+-- One row per scored menu extraction: the quality report (per-item grounding
+-- scores and flags) and, for results quarantined below the quality threshold,
+-- the extraction itself so it can be reviewed instead of lost.
+CREATE TABLE IF NOT EXISTS extraction_quality (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    business_id      UUID NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    source_url       TEXT NOT NULL,
+    extraction_tier  TEXT,
+    score            REAL NOT NULL,
+    grounded         BOOLEAN NOT NULL,
+    item_count       INTEGER NOT NULL,
+    flagged_count    INTEGER NOT NULL,
+    report           JSONB NOT NULL,
+    quarantined      BOOLEAN NOT NULL DEFAULT FALSE,
+    result           JSONB,
+    bronze_path      TEXT,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_extraction_quality_business ON extraction_quality(business_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_extraction_quality_quarantined ON extraction_quality(created_at DESC) WHERE quarantined;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_extraction_quality_review;
+ALTER TABLE extraction_quality DROP COLUMN IF EXISTS applied_at;
+ALTER TABLE extraction_quality DROP COLUMN IF EXISTS review_note;
+ALTER TABLE extraction_quality DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE extraction_quality DROP COLUMN IF EXISTS reviewed_by;
+ALTER TABLE extraction_quality DROP COLUMN IF EXISTS corrected;
+ALTER TABLE extraction_quality DROP COLUMN IF EXISTS last_error;
+ALTER TABLE extraction_quality DROP COLUMN IF EXISTS review_status;
@@ -0,0 +1,18 @@
+-- Human review of quarantined and failed extractions. review_status is set on
+-- rows that need a reviewer ('pending'); NULL rows are plain quality records.
+-- corrected holds the items as approved by the reviewer, kept beside the
+-- extracted result as labelled data. applied_at is set once an approved
+-- review has been written to the menu store.
+ALTER TABLE extraction_quality ADD COLUMN IF NOT EXISTS review_status TEXT
+    CHECK (review_status IN ('pending', 'approved', 'rejected', 'superseded'));
+ALTER TABLE extraction_quality ADD COLUMN IF NOT EXISTS last_error TEXT;
+ALTER TABLE extraction_quality ADD COLUMN IF NOT EXISTS corrected JSONB;
+ALTER TABLE extraction_quality ADD COLUMN IF NOT EXISTS reviewed_by TEXT REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE extraction_quality ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ;
+ALTER TABLE extraction_quality ADD COLUMN IF NOT EXISTS review_note TEXT;
+ALTER TABLE extraction_quality ADD COLUMN IF NOT EXISTS applied_at TIMESTAMPTZ;
+
+UPDATE extraction_quality SET review_status = 'pending' WHERE quarantined AND review_status IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_extraction_quality_review ON extraction_quality(review_status, created_at DESC)
+    WHERE review_status IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_menu_items_language;
+ALTER TABLE menu_items DROP COLUMN IF EXISTS stated_ingredients_en;
+ALTER TABLE menu_items DROP COLUMN IF EXISTS description_en;
+ALTER TABLE menu_items DROP COLUMN IF EXISTS dish_name_en;
+ALTER TABLE menu_items DROP COLUMN IF EXISTS language;
@@ -0,0 +1,8 @@
+-- Menu language and English translations. Original dish text stays in
+-- dish_name/description/stated_ingredients; the *_en columns hold the English
+-- rendering of menus written in another language and are NULL otherwise.
+ALTER TABLE menu_items ADD COLUMN IF NOT EXISTS language TEXT;
+ALTER TABLE menu_items ADD COLUMN IF NOT EXISTS dish_name_en TEXT;
+ALTER TABLE menu_items ADD COLUMN IF NOT EXISTS description_en TEXT;
+ALTER TABLE menu_items ADD COLUMN IF NOT EXISTS stated_ingredients_en TEXT[];
+CREATE INDEX IF NOT EXISTS idx_menu_items_language ON menu_items(language) WHERE removed_at IS NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_review_chunks_tsv;
+ALTER TABLE review_chunks DROP COLUMN IF EXISTS chunk_tsv;
+DROP TABLE IF EXISTS bm25_terms;
+DROP TABLE IF EXISTS bm25_corpus;
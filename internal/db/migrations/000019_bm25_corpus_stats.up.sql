@@ -0,0 +1,26 @@
+-- Corpus statistics for BM25 keyword scoring in hybrid search. search keeps
+-- them up to date as it indexes; one row in bm25_corpus per scored corpus
+-- (review_chunks: one document per chunk) and one bm25_terms row per term.
+CREATE TABLE IF NOT EXISTS bm25_corpus (
+    corpus       TEXT PRIMARY KEY,
+    doc_count    BIGINT NOT NULL DEFAULT 0,
+    total_length BIGINT NOT NULL DEFAULT 0,
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS bm25_terms (
+    corpus   TEXT NOT NULL REFERENCES bm25_corpus(corpus) ON DELETE CASCADE,
+    term     TEXT NOT NULL,
+    doc_freq BIGINT NOT NULL,
+    PRIMARY KEY (corpus, term)
+);
+
+-- Full-text index over review chunks, so hybrid review search can recall
+-- keyword matches the vector search ranks too low to return. chunk_tsv is a
+-- plain column rather than a generated one so adding it does not rewrite the
+-- table: the indexer sets it on the chunks it writes, and chunks indexed
+-- before this migration are filled, and counted into bm25_corpus, in batches
+-- by `fodmap-detector search backfill-bm25`. A NULL chunk_tsv therefore marks
+-- a chunk the statistics do not count yet.
+ALTER TABLE review_chunks ADD COLUMN IF NOT EXISTS chunk_tsv tsvector;
+CREATE INDEX IF NOT EXISTS idx_review_chunks_tsv ON review_chunks USING gin (chunk_tsv);
@@ -0,0 +1,4 @@
+-- Shadow embedding_s<id> columns and their invalidation triggers are left in
+-- place; drop pending and retired spaces (`embeddings drop`) before rolling
+-- back.
+DROP TABLE IF EXISTS embedding_spaces;
@@ -0,0 +1,31 @@
+-- Embedding spaces: which model (and dimension) filled each collection's
+-- vectors. The active space of a collection lives in its `embedding` column;
+-- a space being built fills a shadow embedding_s<id> column that search
+-- adds, and cutover swaps the two columns by renaming them in one
+-- transaction. Retired spaces keep their column until dropped.
+CREATE TABLE IF NOT EXISTS embedding_spaces (
+    id           BIGSERIAL PRIMARY KEY,
+    collection   TEXT NOT NULL CHECK (collection IN ('reviews', 'menu', 'fodmap')),
+    model        TEXT NOT NULL,
+    dim          INT NOT NULL CHECK (dim BETWEEN 1 AND 4000),
+    -- search.EmbedderConfig the space is filled with; '{}' for the seeded
+    -- spaces, which use the embedder each process is configured with.
+    embedder     JSONB NOT NULL DEFAULT '{}',
+    status       TEXT NOT NULL CHECK (status IN ('building', 'ready', 'active', 'retired')),
+    column_name  TEXT NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    activated_at TIMESTAMPTZ,
+    retired_at   TIMESTAMPTZ
+);
+
+-- One active space per collection, and at most one migration in flight.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_embedding_spaces_active
+    ON embedding_spaces(collection) WHERE status = 'active';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_embedding_spaces_pending
+    ON embedding_spaces(collection) WHERE status IN ('building', 'ready');
+
+-- Every collection so far has been embedded with nomic-embed-text.
+INSERT INTO embedding_spaces (collection, model, dim, status, column_name, activated_at)
+SELECT c, 'nomic-embed-text', 768, 'active', 'embedding', NOW()
+FROM   unnest(ARRAY['reviews', 'menu', 'fodmap']) AS c
+WHERE  NOT EXISTS (SELECT 1 FROM embedding_spaces);
@@ -0,0 +1,15 @@
+-- Content-addressed embedding cache: one row per (model, task, text) the
+-- embedders have vectorised, so re-running an index or scrape job skips
+-- texts already embedded. key is the hex SHA-256 of the namespace
+-- (backend/model/dim), the task and the text; vector holds little-endian
+-- float32s, as cached vectors differ in dimension across models.
+CREATE TABLE IF NOT EXISTS embedding_cache (
+    key        TEXT PRIMARY KEY,
+    namespace  TEXT NOT NULL,
+    vector     BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    used_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Eviction drops the least recently used entries beyond the cache's size.
+CREATE INDEX IF NOT EXISTS idx_embedding_cache_used_at ON embedding_cache(used_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_reviews_updated_at_unset;
+DROP INDEX IF EXISTS idx_reviews_updated_at;
+ALTER TABLE reviews DROP COLUMN IF EXISTS updated_at;
+DROP TABLE IF EXISTS review_hashes;
@@ -0,0 +1,22 @@
+-- Per-review content hashes for incremental indexing: one row per review
+-- and index target (backend, collection and embedding model) it was last
+-- indexed into, so `index` re-chunks and re-embeds only reviews whose text
+-- or business metadata changed since.
+CREATE TABLE IF NOT EXISTS review_hashes (
+    target     TEXT NOT NULL,
+    review_id  TEXT NOT NULL,
+    hash       TEXT NOT NULL,
+    indexed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (target, review_id)
+);
+
+-- updated_at orders the reviews table as an index source: `index --source
+-- postgres` resumes after the (updated_at, review_id) it last read, so
+-- edited reviews are picked up again. Adding the column without a default
+-- leaves existing rows NULL rather than rewriting the table; `search
+-- backfill-review-updated-at` gives them their created_at in batches, and the
+-- partial index finds the rows it has left.
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ;
+ALTER TABLE reviews ALTER COLUMN updated_at SET DEFAULT NOW();
+CREATE INDEX IF NOT EXISTS idx_reviews_updated_at ON reviews(updated_at, review_id);
+CREATE INDEX IF NOT EXISTS idx_reviews_updated_at_unset ON reviews(review_id) WHERE updated_at IS NULL;
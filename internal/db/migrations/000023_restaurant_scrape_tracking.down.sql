@@ -0,0 +1,2 @@
+ALTER TABLE restaurants DROP COLUMN IF EXISTS scrape_failed_at;
+ALTER TABLE restaurants DROP COLUMN IF EXISTS url_item_counts;
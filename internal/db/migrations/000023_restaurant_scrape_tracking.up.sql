@@ -0,0 +1,11 @@
+-- url_item_counts keeps the item count of each scraped menu URL and
+-- item_count is their sum, so re-scraping a URL replaces its count instead
+-- of adding to it. Restaurants with a single menu URL take their current
+-- count; others fill in as each URL is next scraped.
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS url_item_counts JSONB NOT NULL DEFAULT '{}'::jsonb;
+UPDATE restaurants SET url_item_counts = jsonb_build_object(menu_urls[1], item_count)
+WHERE status = 'scraped' AND cardinality(menu_urls) = 1 AND item_count > 0;
+
+-- scrape_failed_at is when a scrape last failed; the refresh policy measures
+-- a failed restaurant's staleness from it.
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS scrape_failed_at TIMESTAMPTZ;
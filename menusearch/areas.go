@@ -1,15 +1,290 @@
 package menusearch
 
-type AreaFilter struct {
-	NTAs           []string
-	NTAZipRestrict map[string][]string
-}
-
-var Areas = map[string]AreaFilter{
-	"astoria-lic": {
-		NTAs: []string{"QN70", "QN71", "QN72", "QN68"},
-		NTAZipRestrict: map[string][]string{
-			"QN31": {"11101", "11109"},
-		},
-	},
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.yaml.in/yaml/v3"
+
+	"fodmap/geo"
+	"fodmap/server"
+)
+
+//go:embed store/sql/list_areas.sql
+var listAreasSQL string
+
+//go:embed store/sql/get_area.sql
+var getAreaSQL string
+
+//go:embed store/sql/upsert_area.sql
+var upsertAreaSQL string
+
+//go:embed store/sql/delete_area.sql
+var deleteAreaSQL string
+
+//go:embed store/sql/list_restaurants_in_area.sql
+var listRestaurantsInAreaSQL string
+
+// AreaStore keeps areas in the geo_areas table. It implements
+// server.AreaStore.
+type AreaStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewAreaStore returns a Postgres-backed area store.
+func NewAreaStore(pool *pgxpool.Pool) *AreaStore {
+	return &AreaStore{pool: pool}
+}
+
+// ListAreas returns every area ordered by name.
+func (s *AreaStore) ListAreas(ctx context.Context) ([]geo.Area, error) {
+	rows, err := s.pool.Query(ctx, listAreasSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var areas []geo.Area
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+		var a geo.Area
+		if err := json.Unmarshal(raw, &a); err != nil {
+			return nil, fmt.Errorf("decoding area: %w", err)
+		}
+		areas = append(areas, a)
+	}
+	return areas, rows.Err()
+}
+
+// GetArea returns the named area, or nil if there is none.
+func (s *AreaStore) GetArea(ctx context.Context, name string) (*geo.Area, error) {
+	var raw []byte
+	err := s.pool.QueryRow(ctx, getAreaSQL, name).Scan(&raw)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var a geo.Area
+	if err := json.Unmarshal(raw, &a); err != nil {
+		return nil, fmt.Errorf("decoding area %q: %w", name, err)
+	}
+	return &a, nil
+}
+
+// PutArea creates or replaces a.
+func (s *AreaStore) PutArea(ctx context.Context, a geo.Area) error {
+	raw, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("encoding area: %w", err)
+	}
+	_, err = s.pool.Exec(ctx, upsertAreaSQL, a.Name, raw)
+	return err
+}
+
+// DeleteArea removes the named area. It returns server.ErrAreaNotFound if
+// there is none.
+func (s *AreaStore) DeleteArea(ctx context.Context, name string) error {
+	tag, err := s.pool.Exec(ctx, deleteAreaSQL, name)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return server.ErrAreaNotFound
+	}
+	return nil
+}
+
+// areasFile is the on-disk layout of a FileAreaStore.
+type areasFile struct {
+	Areas []geo.Area `yaml:"areas"`
+}
+
+// FileAreaStore keeps areas in a YAML file, for deployments that manage
+// areas in version control rather than the database. Writes rewrite the
+// whole file atomically. It implements server.AreaStore.
+type FileAreaStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileAreaStore returns a store backed by the YAML file at path. A missing
+// file is treated as empty and created on the first write.
+func NewFileAreaStore(path string) *FileAreaStore {
+	return &FileAreaStore{path: path}
+}
+
+// ListAreas returns every area in the file, ordered by name.
+func (s *FileAreaStore) ListAreas(_ context.Context) ([]geo.Area, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read()
+}
+
+// GetArea returns the named area, or nil if there is none.
+func (s *FileAreaStore) GetArea(_ context.Context, name string) (*geo.Area, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	areas, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range areas {
+		if a.Name == name {
+			return &a, nil
+		}
+	}
+	return nil, nil
+}
+
+// PutArea creates or replaces a.
+func (s *FileAreaStore) PutArea(_ context.Context, a geo.Area) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	areas, err := s.read()
+	if err != nil {
+		return err
+	}
+	areas = slices.DeleteFunc(areas, func(x geo.Area) bool { return x.Name == a.Name })
+	return s.write(append(areas, a))
+}
+
+// DeleteArea removes the named area. It returns server.ErrAreaNotFound if
+// there is none.
+func (s *FileAreaStore) DeleteArea(_ context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	areas, err := s.read()
+	if err != nil {
+		return err
+	}
+	kept := slices.DeleteFunc(slices.Clone(areas), func(x geo.Area) bool { return x.Name == name })
+	if len(kept) == len(areas) {
+		return server.ErrAreaNotFound
+	}
+	return s.write(kept)
+}
+
+// read loads the file. s.mu must be held.
+func (s *FileAreaStore) read() ([]geo.Area, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading areas file: %w", err)
+	}
+	var f areasFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing areas file %s: %w", s.path, err)
+	}
+	slices.SortFunc(f.Areas, func(a, b geo.Area) int { return strings.Compare(a.Name, b.Name) })
+	return f.Areas, nil
+}
+
+// write replaces the file with areas. s.mu must be held.
+func (s *FileAreaStore) write(areas []geo.Area) error {
+	slices.SortFunc(areas, func(a, b geo.Area) int { return strings.Compare(a.Name, b.Name) })
+	data, err := yaml.Marshal(areasFile{Areas: areas})
+	if err != nil {
+		return fmt.Errorf("encoding areas: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("creating areas dir: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("writing areas file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("replacing areas file: %w", err)
+	}
+	return nil
+}
+
+// PlaceOf returns the area-membership view of a restaurant row.
+func PlaceOf(r server.Restaurant) geo.Place {
+	return geo.Place{
+		NTA:     safeDeref(r.NTA),
+		Zipcode: safeDeref(r.Zipcode),
+		Boro:    safeDeref(r.Boro),
+		Lat:     r.Latitude,
+		Lon:     r.Longitude,
+	}
+}
+
+// PlaceOfRecord returns the area-membership view of an NYC OpenData record.
+// Socrata reports missing coordinates as zero, which are treated as absent.
+func PlaceOfRecord(rec NYCRestaurantRecord) geo.Place {
+	p := geo.Place{NTA: rec.NTA, Zipcode: rec.Zipcode, Boro: rec.Boro}
+	if rec.Latitude != 0 || rec.Longitude != 0 {
+		p.Lat, p.Lon = &rec.Latitude, &rec.Longitude
+	}
+	return p
+}
+
+// ListInArea returns up to limit restaurants inside area. Postgres narrows
+// the candidates by attribute and bounding box; exact membership, including
+// point-in-polygon on Latitude/Longitude, is decided by geo.Matcher.
+func (s *Store) ListInArea(ctx context.Context, area geo.Area, limit int) ([]server.Restaurant, error) {
+	m, err := area.Matcher()
+	if err != nil {
+		return nil, err
+	}
+	bounds, hasBounds, err := area.Bounds()
+	if err != nil {
+		return nil, err
+	}
+	zips := slices.Clone(area.Zipcodes)
+	for _, z := range area.NTAZipRestrict {
+		zips = append(zips, z...)
+	}
+	boros := make([]string, len(area.Boroughs))
+	for i, b := range area.Boroughs {
+		boros[i] = strings.ToLower(b)
+	}
+
+	rows, err := s.pool.Query(ctx, listRestaurantsInAreaSQL,
+		area.NTAs, zips, boros,
+		hasBounds, bounds.MinLat(), bounds.MaxLat(), bounds.MinLon(), bounds.MaxLon(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []server.Restaurant
+	for rows.Next() {
+		var r server.Restaurant
+		if err := rows.Scan(
+			&r.ID, &r.CAMIS, &r.YelpID, &r.DBA, &r.Boro, &r.Building, &r.Street, &r.Zipcode, &r.Phone, &r.Address, &r.Cuisine, &r.Latitude, &r.Longitude, &r.NTA,
+			&r.Status, &r.WebsiteURL, &r.MenuURLs, &r.URLSource, &r.ExtractionTier, &r.ItemCount, &r.ScrapedAt, &r.LastError, &r.CreatedAt, &r.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if !m.Match(PlaceOf(r)) {
+			continue
+		}
+		results = append(results, r)
+		if limit > 0 && len(results) >= limit {
+			break
+		}
+	}
+	return results, rows.Err()
 }
@@ -0,0 +1,77 @@
+package menusearch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fodmap/geo"
+	"fodmap/server"
+)
+
+func TestFileAreaStore_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "areas.yaml")
+	if err := os.WriteFile(path, []byte(`areas:
+  - name: astoria-lic
+    ntas: [QN70, QN71]
+    nta_zip_restrict:
+      QN31: ["11101"]
+  - name: williamsburg
+    geometry:
+      type: Polygon
+      coordinates: [[[-73.97, 40.70], [-73.93, 40.70], [-73.93, 40.73], [-73.97, 40.73], [-73.97, 40.70]]]
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store := NewFileAreaStore(path)
+
+	wb, err := store.GetArea(ctx, "williamsburg")
+	if err != nil || wb == nil {
+		t.Fatalf("GetArea(williamsburg) = %v, %v", wb, err)
+	}
+	lat, lon := 40.715, -73.95
+	if !wb.Match(geo.Place{Lat: &lat, Lon: &lon}) {
+		t.Error("point inside the YAML polygon should match")
+	}
+
+	if err := store.PutArea(ctx, geo.Area{Name: "brooklyn", Boroughs: []string{"Brooklyn"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeleteArea(ctx, "astoria-lic"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeleteArea(ctx, "astoria-lic"); !errors.Is(err, server.ErrAreaNotFound) {
+		t.Errorf("second DeleteArea = %v, want ErrAreaNotFound", err)
+	}
+
+	areas, err := store.ListAreas(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, a := range areas {
+		names = append(names, a.Name)
+	}
+	if got := strings.Join(names, ","); got != "brooklyn,williamsburg" {
+		t.Errorf("areas = %s, want brooklyn,williamsburg", got)
+	}
+}
+
+func TestFileAreaStore_MissingFile(t *testing.T) {
+	store := NewFileAreaStore(filepath.Join(t.TempDir(), "none.yaml"))
+	areas, err := store.ListAreas(context.Background())
+	if err != nil || len(areas) != 0 {
+		t.Errorf("ListAreas = %v, %v; want empty", areas, err)
+	}
+}
+
+func TestPlaceOfRecord_ZeroCoordinatesAreAbsent(t *testing.T) {
+	p := PlaceOfRecord(NYCRestaurantRecord{NTA: "QN70"})
+	if p.Lat != nil || p.Lon != nil {
+		t.Errorf("zero coordinates should be nil, got %v,%v", p.Lat, p.Lon)
+	}
+}
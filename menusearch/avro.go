@@ -165,6 +165,10 @@ func WriteMenuExtractionAvro(ctx context.Context, destPath string, rec MenuExtra
 		if stated == nil {
 			stated = []string{}
 		}
+		statedEn := item.StatedIngredientsEn
+		if statedEn == nil {
+			statedEn = []string{}
+		}
 		mods := make([]map[string]any, 0, len(item.Modifiers))
 		for _, m := range item.Modifiers {
 			mod := map[string]any{
@@ -181,16 +185,20 @@ func WriteMenuExtractionAvro(ctx context.Context, destPath string, rec MenuExtra
 			price = *item.Price
 		}
 		items = append(items, map[string]any{
-			"menu_item_id":         item.MenuItemID,
-			"dish_name":            item.DishName,
-			"description":          item.Description,
-			"menu_section":         item.MenuSection,
-			"price":                price,
-			"stated_ingredients":   stated,
-			"has_full_ingredients": item.HasFullIngredients,
-			"modifiers":            mods,
-			"source_url":           item.SourceURL,
-			"scraped_at":           item.ScrapedAt,
+			"menu_item_id":          item.MenuItemID,
+			"dish_name":             item.DishName,
+			"description":           item.Description,
+			"menu_section":          item.MenuSection,
+			"price":                 price,
+			"stated_ingredients":    stated,
+			"has_full_ingredients":  item.HasFullIngredients,
+			"modifiers":             mods,
+			"source_url":            item.SourceURL,
+			"scraped_at":            item.ScrapedAt,
+			"language":              item.Language,
+			"dish_name_en":          item.DishNameEn,
+			"description_en":        item.DescriptionEn,
+			"stated_ingredients_en": statedEn,
 		})
 	}
 
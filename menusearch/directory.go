@@ -14,6 +14,7 @@ import (
 
 	"golang.org/x/net/html"
 
+	"fodmap/egress"
 	"fodmap/pipeline"
 	"fodmap/scraper"
 )
@@ -239,7 +240,11 @@ func extractSubURLs(
 
 // buildDirectoryClient returns an *http.Client for menuSignalFilter probing
 // during directory fanout.  We use a short timeout to bound the pre-filter
-// network time; non-2xx and timed-out URLs are kept anyway.
-func buildDirectoryClient() *http.Client {
+// network time; non-2xx and timed-out URLs are kept anyway.  When pool is
+// non-nil, probes go through the shared egress pool.
+func buildDirectoryClient(pool *egress.Pool) *http.Client {
+	if pool != nil {
+		return pool.Client(8 * time.Second)
+	}
 	return &http.Client{Timeout: 8 * time.Second}
 }
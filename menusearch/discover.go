@@ -29,6 +29,7 @@ import (
 	"google.golang.org/genai"
 
 	"fodmap/scraper"
+	"fodmap/server"
 )
 
 //go:embed prompts/discover.txt
@@ -54,6 +55,12 @@ func (w *DiscoverMenuURLWorker) Work(ctx context.Context, job *river.Job[Discove
 	logger := slog.With("job", job.ID, "camis", args.CAMIS, "dba", args.DBA)
 	logger.Info("starting discovery job")
 
+	rest, err := w.restaurantFor(ctx, args)
+	if err != nil {
+		return err
+	}
+	logger = logger.With("restaurant_id", rest.ID)
+
 	defer func() {
 		if err != nil {
 			maxAttempts := w.MaxAttempts
@@ -62,7 +69,7 @@ func (w *DiscoverMenuURLWorker) Work(ctx context.Context, job *river.Job[Discove
 			}
 			if job.Attempt >= maxAttempts {
 				logger.Info("discovery failed after max attempts, marking permanently failed", "error", err)
-				_ = w.Store.UpdateScrapeResult(ctx, args.CAMIS, StatusFailedPermanently, 0, err.Error())
+				_ = w.Store.UpdateScrapeResult(ctx, rest.ID, StatusFailedPermanently, 0, err.Error())
 			}
 		}
 	}()
@@ -70,11 +77,9 @@ func (w *DiscoverMenuURLWorker) Work(ctx context.Context, job *river.Job[Discove
 	// If a previous attempt already stored URLs (Gemini succeeded but the
 	// subsequent DB write or enqueue failed), skip the expensive Gemini call
 	// and go straight to re-enqueueing scrape jobs.
-	if job.Attempt > 1 {
-		if existing, err := w.Store.Get(ctx, args.CAMIS); err == nil && existing != nil && len(existing.MenuURLs) > 0 {
-			logger.Info("reusing URLs from previous attempt, skipping Gemini call", "count", len(existing.MenuURLs))
-			return w.enqueueScrapeJobs(ctx, args, existing.ID, existing.MenuURLs, "")
-		}
+	if job.Attempt > 1 && len(rest.MenuURLs) > 0 {
+		logger.Info("reusing URLs from previous attempt, skipping Gemini call", "count", len(rest.MenuURLs))
+		return w.enqueueScrapeJobs(ctx, args, rest.ID, rest.MenuURLs, "")
 	}
 
 	var promptBuf bytes.Buffer
@@ -231,7 +236,7 @@ func (w *DiscoverMenuURLWorker) Work(ctx context.Context, job *river.Job[Discove
 		Attempt:      job.Attempt,
 	}
 
-	avroDest := filepath.Join(w.AvroDestDir, fmt.Sprintf("%s-%d.avro", args.CAMIS, job.Attempt))
+	avroDest := filepath.Join(w.AvroDestDir, fmt.Sprintf("%s-%d.avro", restaurantFileKey(rest), job.Attempt))
 	if err := WriteGeminiDiscoveryAvro(ctx, avroDest, record); err != nil {
 		slog.Warn("failed to write gemini discovery avro", "error", err, "path", avroDest)
 	}
@@ -239,15 +244,9 @@ func (w *DiscoverMenuURLWorker) Work(ctx context.Context, job *river.Job[Discove
 	if len(foundURLs) > 0 {
 		logger.Info("found URL(s)", "count", len(foundURLs), "primary", primaryURL, "source", urlSource)
 
-		if err := w.Store.UpdateDiscoveryURLs(ctx, args.CAMIS, primaryURL, foundURLs, urlSource, result.Address, result.PhoneNumber); err != nil {
+		if err := w.Store.UpdateDiscoveryURLs(ctx, rest.ID, primaryURL, foundURLs, urlSource, result.Address, result.PhoneNumber); err != nil {
 			return fmt.Errorf("update menu url: %w", err)
 		}
-
-		// Resolve the restaurant's surrogate UUID for the scrape job args.
-		rest, err := w.Store.Get(ctx, args.CAMIS)
-		if err != nil || rest == nil {
-			return fmt.Errorf("restaurant %s not found after discovery: %w", args.CAMIS, err)
-		}
 		if err := w.enqueueScrapeJobs(ctx, args, rest.ID, foundURLs, eventID); err != nil {
 			return err
 		}
@@ -257,19 +256,40 @@ func (w *DiscoverMenuURLWorker) Work(ctx context.Context, job *river.Job[Discove
 			maxAttempts = 3
 		}
 		if job.Attempt >= maxAttempts {
-			logger.Info("no URL found after max attempts, marking permanently", "camis", args.CAMIS, "max_attempts", maxAttempts)
-			if err := w.Store.UpdateDiscoveryURLs(ctx, args.CAMIS, "", []string{}, "gemini", "", ""); err != nil {
+			logger.Info("no URL found after max attempts, marking permanently", "max_attempts", maxAttempts)
+			if err := w.Store.UpdateDiscoveryURLs(ctx, rest.ID, "", []string{}, "gemini", "", ""); err != nil {
 				return fmt.Errorf("update no-url status: %w", err)
 			}
 			return nil
 		}
-		logger.Info("no URL found, will retry", "camis", args.CAMIS, "attempt", job.Attempt, "max", maxAttempts)
-		return fmt.Errorf("no URL found for %s (attempt %d/%d)", args.CAMIS, job.Attempt, maxAttempts)
+		logger.Info("no URL found, will retry", "attempt", job.Attempt, "max", maxAttempts)
+		return fmt.Errorf("no URL found for %s (attempt %d/%d)", restaurantFileKey(rest), job.Attempt, maxAttempts)
 	}
 
 	return nil
 }
 
+// restaurantFor resolves the restaurant a discovery job is for: by id, or by
+// CAMIS for jobs enqueued before the args carried the id.
+func (w *DiscoverMenuURLWorker) restaurantFor(ctx context.Context, args DiscoverMenuURLArgs) (*server.Restaurant, error) {
+	var rest *server.Restaurant
+	var err error
+	key := args.CAMIS
+	if args.RestaurantID != uuid.Nil {
+		key = args.RestaurantID.String()
+		rest, err = w.Store.GetByID(ctx, args.RestaurantID)
+	} else {
+		rest, err = w.Store.Get(ctx, args.CAMIS)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get restaurant %s: %w", key, err)
+	}
+	if rest == nil {
+		return nil, fmt.Errorf("restaurant %s not found", key)
+	}
+	return rest, nil
+}
+
 func (w *DiscoverMenuURLWorker) Timeout(job *river.Job[DiscoverMenuURLArgs]) time.Duration {
 	return 5 * time.Minute
 }
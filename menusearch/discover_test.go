@@ -1,6 +1,7 @@
 package menusearch
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"errors"
@@ -8,6 +9,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"syscall"
 	"testing"
 	"time"
@@ -643,3 +645,21 @@ func TestCheckMenuSignal_PrivateHostDropped(t *testing.T) {
 		t.Errorf("private host must be dropped, got keep=true reason=%q", reason)
 	}
 }
+
+func TestDiscoverPrompt_Address(t *testing.T) {
+	for _, tc := range []struct {
+		args DiscoverMenuURLArgs
+		want string
+	}{
+		{DiscoverMenuURLArgs{DBA: "Joe's", Building: "31-01", Street: "Broadway", Boro: "Queens", Zipcode: "11106"}, `"Joe's" at 31-01 Broadway, Queens NY 11106.`},
+		{DiscoverMenuURLArgs{DBA: "Luigi's", Address: "3400 SE Hawthorne Blvd, OR 97214"}, `"Luigi's" at 3400 SE Hawthorne Blvd, OR 97214.`},
+	} {
+		var buf bytes.Buffer
+		if err := discoverPromptTmpl.Execute(&buf, tc.args); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), tc.want) {
+			t.Errorf("prompt does not contain %q:\n%s", tc.want, strings.SplitN(buf.String(), "\n", 2)[0])
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package menusearch
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"fodmap/server"
+)
+
+//go:embed store/sql/set_favorite.sql
+var setFavoriteSQL string
+
+//go:embed store/sql/delete_favorite.sql
+var deleteFavoriteSQL string
+
+//go:embed store/sql/list_favorites.sql
+var listFavoritesSQL string
+
+// AddFavorite saves restaurant businessID for userID. Saving it again is a
+// no-op. It returns server.ErrRestaurantNotFound when no such restaurant
+// exists.
+func (s *Store) AddFavorite(ctx context.Context, userID string, businessID uuid.UUID) error {
+	_, err := s.pool.Exec(ctx, setFavoriteSQL, userID, businessID)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.ConstraintName == "restaurant_favorites_business_id_fkey" {
+		return server.ErrRestaurantNotFound
+	}
+	return err
+}
+
+// RemoveFavorite unsaves restaurant businessID for userID.
+func (s *Store) RemoveFavorite(ctx context.Context, userID string, businessID uuid.UUID) error {
+	_, err := s.pool.Exec(ctx, deleteFavoriteSQL, userID, businessID)
+	return err
+}
+
+// ListFavorites returns userID's saved restaurants, most recent first.
+func (s *Store) ListFavorites(ctx context.Context, userID string) ([]server.FavoriteRestaurant, error) {
+	rows, err := s.pool.Query(ctx, listFavoritesSQL, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []server.FavoriteRestaurant
+	for rows.Next() {
+		var f server.FavoriteRestaurant
+		if err := rows.Scan(&f.ID, &f.Name, &f.Address, &f.Cuisine, &f.Status, &f.ScrapedAt, &f.FavoritedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// compile-time check
+var _ server.FavoriteStore = (*Store)(nil)
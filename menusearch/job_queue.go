@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/riverqueue/river"
 
@@ -13,16 +14,17 @@ import (
 
 // JobQueue implements server.RestaurantJobQueue using the River client.
 //
-// DiscoverEnabled and ScrapeEnabled reflect whether the corresponding River
+// DiscoverEnabled, ScrapeEnabled and ApplyReviewEnabled reflect whether the corresponding River
 // worker is registered in the running pipeline. When false, the matching
 // Enqueue method returns server.ErrJobKindNotRegistered instead of inserting
 // a job that no worker can process (which River would reject with "Unhandled
 // job kind").
 type JobQueue struct {
-	Client          *river.Client[pgx.Tx]
-	MaxAttempts     int
-	DiscoverEnabled bool
-	ScrapeEnabled   bool
+	Client             *river.Client[pgx.Tx]
+	MaxAttempts        int
+	DiscoverEnabled    bool
+	ScrapeEnabled      bool
+	ApplyReviewEnabled bool
 }
 
 // EnqueueDiscover inserts a discover_menu_url job for the given restaurant.
@@ -32,15 +34,7 @@ func (q *JobQueue) EnqueueDiscover(ctx context.Context, r server.Restaurant) err
 	if !q.DiscoverEnabled {
 		return server.ErrJobKindNotRegistered
 	}
-	args := DiscoverMenuURLArgs{
-		CAMIS:    safeDeref(r.CAMIS),
-		DBA:      r.DBA,
-		Building: safeDeref(r.Building),
-		Street:   safeDeref(r.Street),
-		Boro:     safeDeref(r.Boro),
-		Zipcode:  safeDeref(r.Zipcode),
-		Attempt:  1,
-	}
+	args := DiscoverArgsFor(r)
 	opts := &river.InsertOpts{
 		MaxAttempts: q.MaxAttempts,
 		UniqueOpts: river.UniqueOpts{
@@ -96,6 +90,53 @@ func (q *JobQueue) EnqueueScrape(ctx context.Context, r server.Restaurant) error
 	return nil
 }
 
+// EnqueueApplyReview inserts an apply_menu_review job for an approved
+// review. Returns server.ErrJobAlreadyQueued if one is already queued, or
+// ErrJobKindNotRegistered if no apply worker is registered.
+func (q *JobQueue) EnqueueApplyReview(ctx context.Context, reviewID uuid.UUID) error {
+	if !q.ApplyReviewEnabled {
+		return server.ErrJobKindNotRegistered
+	}
+	res, err := q.Client.Insert(ctx, ApplyMenuReviewArgs{ReviewID: reviewID}, &river.InsertOpts{
+		MaxAttempts: q.MaxAttempts,
+		UniqueOpts:  river.UniqueOpts{ByArgs: true},
+	})
+	if err != nil {
+		return fmt.Errorf("enqueue apply review: %w", err)
+	}
+	if res.UniqueSkippedAsDuplicate {
+		return server.ErrJobAlreadyQueued
+	}
+	return nil
+}
+
+// DiscoverArgsFor builds the first discovery job for r.
+func DiscoverArgsFor(r server.Restaurant) DiscoverMenuURLArgs {
+	args := DiscoverMenuURLArgs{
+		RestaurantID: r.ID,
+		CAMIS:        safeDeref(r.CAMIS),
+		DBA:          r.DBA,
+		Building:     safeDeref(r.Building),
+		Street:       safeDeref(r.Street),
+		Boro:         safeDeref(r.Boro),
+		Zipcode:      safeDeref(r.Zipcode),
+		Attempt:      1,
+	}
+	if args.Boro == "" {
+		args.Address = safeDeref(r.Address)
+	}
+	return args
+}
+
+// restaurantFileKey names a restaurant's bronze and Avro files: its CAMIS
+// when it has one, as files always were, and otherwise its id.
+func restaurantFileKey(r *server.Restaurant) string {
+	if camis := safeDeref(r.CAMIS); camis != "" {
+		return camis
+	}
+	return r.ID.String()
+}
+
 func safeDeref(s *string) string {
 	if s == nil {
 		return ""
@@ -104,4 +145,7 @@ func safeDeref(s *string) string {
 }
 
 // compile-time check
-var _ server.RestaurantJobQueue = (*JobQueue)(nil)
+var (
+	_ server.RestaurantJobQueue = (*JobQueue)(nil)
+	_ server.MenuReviewApplier  = (*JobQueue)(nil)
+)
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/google/uuid"
+
 	"fodmap/server"
 )
 
@@ -23,3 +25,11 @@ func TestJobQueue_EnqueueScrape_Disabled(t *testing.T) {
 		t.Fatalf("err = %v, want ErrJobKindNotRegistered", err)
 	}
 }
+
+func TestJobQueue_EnqueueApplyReview_Disabled(t *testing.T) {
+	q := &JobQueue{ApplyReviewEnabled: false}
+	err := q.EnqueueApplyReview(context.Background(), uuid.New())
+	if !errors.Is(err, server.ErrJobKindNotRegistered) {
+		t.Fatalf("err = %v, want ErrJobKindNotRegistered", err)
+	}
+}
@@ -3,13 +3,19 @@ package menusearch
 import "github.com/google/uuid"
 
 type DiscoverMenuURLArgs struct {
-	CAMIS    string `json:"camis"`
-	DBA      string `json:"dba"`
-	Building string `json:"building"`
-	Street   string `json:"street"`
-	Boro     string `json:"boro"`
-	Zipcode  string `json:"zipcode"`
-	Attempt  int    `json:"attempt"`
+	// RestaurantID keys the job's status writes. Jobs enqueued before it
+	// existed carry only CAMIS and are resolved through it.
+	RestaurantID uuid.UUID `json:"restaurant_id,omitempty"`
+	CAMIS        string    `json:"camis"`
+	DBA          string    `json:"dba"`
+	Building     string    `json:"building"`
+	Street       string    `json:"street"`
+	Boro         string    `json:"boro"`
+	Zipcode      string    `json:"zipcode"`
+	// Address is the full one-line address of restaurants outside NYC, whose
+	// records have no borough.
+	Address string `json:"address,omitempty"`
+	Attempt int    `json:"attempt"`
 }
 
 func (DiscoverMenuURLArgs) Kind() string {
@@ -26,6 +32,10 @@ type ScrapeMenuArgs struct {
 	// perform directory expansion; sub-URL fetches are done inline at depth 1 and
 	// never recurse further.
 	Depth int `json:"depth"`
+	// Refresh marks a re-scrape queued by the refresh policy. It keeps the
+	// job's uniqueness key apart from the original scrape of the same URL,
+	// which River would otherwise dedupe for 30 days.
+	Refresh bool `json:"refresh,omitempty"`
 }
 
 func (ScrapeMenuArgs) Kind() string {
@@ -0,0 +1,60 @@
+package menusearch
+
+import (
+	"context"
+	_ "embed"
+
+	"fodmap/geo"
+	"fodmap/search"
+	"fodmap/server"
+)
+
+//go:embed store/sql/list_restaurants_nearby.sql
+var listRestaurantsNearbySQL string
+
+// ListNearby returns up to limit restaurants matching f, nearest first, with
+// their distance from f.Origin. A bbox-only filter is ordered from the
+// centre of the box and reports no distances. It implements
+// server.NearbyRestaurantLister.
+func (s *Store) ListNearby(ctx context.Context, f search.GeoFilter, status string, limit int) ([]server.NearbyRestaurant, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	origin := f.Origin
+	if origin == nil {
+		b := f.Bounds()
+		origin = &geo.Point{Lat: (b.MinLat() + b.MaxLat()) / 2, Lon: (b.MinLon() + b.MaxLon()) / 2}
+	}
+	var box geo.BBox
+	if f.BBox != nil {
+		box = *f.BBox
+	}
+
+	rows, err := s.pool.Query(ctx, listRestaurantsNearbySQL,
+		origin.Lat, origin.Lon, f.RadiusMeters,
+		f.BBox != nil, box.MinLat(), box.MaxLat(), box.MinLon(), box.MaxLon(),
+		status, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []server.NearbyRestaurant
+	for rows.Next() {
+		var r server.Restaurant
+		if err := rows.Scan(
+			&r.ID, &r.CAMIS, &r.YelpID, &r.DBA, &r.Boro, &r.Building, &r.Street, &r.Zipcode, &r.Phone, &r.Address, &r.Cuisine, &r.Latitude, &r.Longitude, &r.NTA,
+			&r.Status, &r.WebsiteURL, &r.MenuURLs, &r.URLSource, &r.ExtractionTier, &r.ItemCount, &r.ScrapedAt, &r.LastError, &r.CreatedAt, &r.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		n := server.NearbyRestaurant{Restaurant: r}
+		if f.Origin != nil {
+			d := f.Origin.DistanceTo(*r.Latitude, *r.Longitude)
+			n.DistanceMeters = &d
+		}
+		results = append(results, n)
+	}
+	return results, rows.Err()
+}
@@ -4,44 +4,33 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
-)
-
-func FetchNYCRestaurants(ctx context.Context, area string, appToken string, since time.Time) (io.ReadCloser, error) {
-	af, ok := Areas[area]
-	if !ok {
-		return nil, fmt.Errorf("unknown area: %q", area)
-	}
 
-	var conditions []string
-	if len(af.NTAs) > 0 {
-		var quotedNTAs []string
-		for _, nta := range af.NTAs {
-			quotedNTAs = append(quotedNTAs, fmt.Sprintf("'%s'", nta))
-		}
-		conditions = append(conditions, fmt.Sprintf("nta IN (%s)", strings.Join(quotedNTAs, ",")))
-	}
+	"fodmap/geo"
+)
 
-	for nta, zips := range af.NTAZipRestrict {
-		var quotedZips []string
-		for _, zip := range zips {
-			quotedZips = append(quotedZips, fmt.Sprintf("'%s'", zip))
-		}
-		conditions = append(conditions, fmt.Sprintf("(nta='%s' AND zipcode IN (%s))", nta, strings.Join(quotedZips, ",")))
-	}
+// nycRestaurantsURL is the NYC DOHMH restaurant inspections dataset.
+// Overridden in tests.
+var nycRestaurantsURL = "https://data.cityofnewyork.us/resource/43nn-pn8j.csv"
 
-	if len(conditions) == 0 {
-		return nil, fmt.Errorf("area %q has no NTA or zip restrictions defined", area)
+// FetchNYCRestaurants downloads the inspection rows for restaurants in area
+// (updated after since, when non-zero) as CSV. Polygon areas are fetched by
+// their bounding box; callers apply exact membership with geo.Matcher.
+func FetchNYCRestaurants(ctx context.Context, area geo.Area, appToken string, since time.Time) (io.ReadCloser, error) {
+	soqlWhere, err := areaSoQL(area)
+	if err != nil {
+		return nil, err
 	}
-	soqlWhere := strings.Join(conditions, " OR ")
 	if !since.IsZero() {
 		soqlWhere = fmt.Sprintf("(%s) AND record_date > '%s'", soqlWhere, since.UTC().Format("2006-01-02T15:04:05.000"))
 	}
 
-	u, err := url.Parse("https://data.cityofnewyork.us/resource/43nn-pn8j.csv")
+	u, err := url.Parse(nycRestaurantsURL)
 	if err != nil {
 		return nil, err
 	}
@@ -73,3 +62,54 @@ func FetchNYCRestaurants(ctx context.Context, area string, appToken string, sinc
 
 	return resp.Body, nil
 }
+
+// areaSoQL builds the SoQL $where clause selecting area's restaurants: an OR
+// of its NTA, zip, borough and NTA/zip conditions, plus a within_box on the
+// location column for its bounding box and geometry.
+func areaSoQL(area geo.Area) (string, error) {
+	if err := area.Validate(); err != nil {
+		return "", err
+	}
+	var conditions []string
+	if len(area.NTAs) > 0 {
+		conditions = append(conditions, fmt.Sprintf("nta IN (%s)", soqlList(area.NTAs)))
+	}
+	ntas := slices.Sorted(maps.Keys(area.NTAZipRestrict))
+	for _, nta := range ntas {
+		conditions = append(conditions, fmt.Sprintf("(nta=%s AND zipcode IN (%s))", soqlQuote(nta), soqlList(area.NTAZipRestrict[nta])))
+	}
+	if len(area.Zipcodes) > 0 {
+		conditions = append(conditions, fmt.Sprintf("zipcode IN (%s)", soqlList(area.Zipcodes)))
+	}
+	if len(area.Boroughs) > 0 {
+		boros := make([]string, len(area.Boroughs))
+		for i, b := range area.Boroughs {
+			boros[i] = strings.ToUpper(b)
+		}
+		conditions = append(conditions, fmt.Sprintf("upper(boro) IN (%s)", soqlList(boros)))
+	}
+	bounds, ok, err := area.Bounds()
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		// within_box takes the north-west then south-east corner.
+		conditions = append(conditions, fmt.Sprintf("within_box(location, %g, %g, %g, %g)",
+			bounds.MaxLat(), bounds.MinLon(), bounds.MinLat(), bounds.MaxLon()))
+	}
+	return strings.Join(conditions, " OR "), nil
+}
+
+// soqlQuote returns v as a SoQL string literal.
+func soqlQuote(v string) string {
+	return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+}
+
+// soqlList returns vs as a comma-separated list of SoQL string literals.
+func soqlList(vs []string) string {
+	quoted := make([]string, len(vs))
+	for i, v := range vs {
+		quoted[i] = soqlQuote(v)
+	}
+	return strings.Join(quoted, ",")
+}
@@ -1,34 +1,96 @@
 package menusearch
 
 import (
+	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"fodmap/directory"
+	"fodmap/geo"
 )
 
 func TestFetchNYCRestaurants_Success(t *testing.T) {
+	var gotWhere, gotToken string
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`[
-			{
-				"camis": "12345",
-				"dba": "TEST RESTAURANT",
-				"boro": "Queens",
-				"building": "123",
-				"street": "Main St",
-				"zipcode": "11101",
-				"phone": "555-0100",
-				"cuisine_description": "American",
-				"latitude": "40.75",
-				"longitude": "-73.90",
-				"record_date": "2024-01-01T00:00:00.000"
-			}
-		]`))
+		gotWhere = r.URL.Query().Get("$where")
+		gotToken = r.Header.Get("X-App-Token")
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = w.Write([]byte("camis,dba,boro,zipcode,latitude,longitude,nta\n12345,TEST RESTAURANT,Queens,11101,40.75,-73.90,QN31\n"))
 	}))
 	defer srv.Close()
 
-	// Override URL in test by replacing the constant or using the server URL.
-	// Since FetchNYCRestaurants hardcodes the URL, we can't easily mock it without refactoring.
-	// We'll refactor nycdata.go to accept a URL parameter for tests later if needed.
+	orig := nycRestaurantsURL
+	nycRestaurantsURL = srv.URL + "/resource/43nn-pn8j.csv"
+	defer func() { nycRestaurantsURL = orig }()
+
+	area := geo.Area{Name: "astoria-lic", NTAs: []string{"QN70"}, NTAZipRestrict: map[string][]string{"QN31": {"11101"}}}
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	body, err := FetchNYCRestaurants(context.Background(), area, "token", since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = body.Close() }()
+	data, _ := io.ReadAll(body)
+
+	if !strings.Contains(string(data), "TEST RESTAURANT") {
+		t.Errorf("body = %q", data)
+	}
+	want := "(nta IN ('QN70') OR (nta='QN31' AND zipcode IN ('11101'))) AND record_date > '2024-01-01T00:00:00.000'"
+	if gotWhere != want {
+		t.Errorf("$where = %q\nwant      %q", gotWhere, want)
+	}
+	if gotToken != "token" {
+		t.Errorf("X-App-Token = %q", gotToken)
+	}
+}
+
+func TestAreaSoQL_BoroughsZipsAndGeometry(t *testing.T) {
+	area := geo.Area{
+		Name:     "brooklyn-north",
+		Boroughs: []string{"Brooklyn"},
+		Zipcodes: []string{"11211", "11222"},
+		BBox:     &geo.BBox{-74.0, 40.7, -73.9, 40.73},
+	}
+	got, err := areaSoQL(area)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "zipcode IN ('11211','11222') OR upper(boro) IN ('BROOKLYN') OR within_box(location, 40.73, -74, 40.7, -73.9)"
+	if got != want {
+		t.Errorf("areaSoQL = %q\nwant        %q", got, want)
+	}
+
+	if _, err := areaSoQL(geo.Area{Name: "empty"}); err == nil {
+		t.Error("expected error for area without filters")
+	}
+}
+
+func TestSoQLQuote_EscapesQuotes(t *testing.T) {
+	if got := soqlQuote("O'Brien"); got != "'O''Brien'" {
+		t.Errorf("soqlQuote = %q", got)
+	}
+}
+
+func TestNYCDirectoryRecord(t *testing.T) {
+	rec := NYCDirectoryRecord(NYCRestaurantRecord{
+		CAMIS: "50012345", DBA: "TEST DINER", Building: "31-01", Street: "BROADWAY",
+		Boro: "Queens", Zipcode: "11106", NTA: "QN70", Latitude: 40.7616, Longitude: -73.9245,
+	})
+	if rec.Source != directory.SourceNYC || rec.ExternalID != "50012345" || rec.Name != "TEST DINER" {
+		t.Errorf("record = %+v", rec)
+	}
+	if got := rec.FullAddress(); got != "31-01 BROADWAY, New York, NY 11106" {
+		t.Errorf("FullAddress = %q", got)
+	}
+	if rec.Latitude == nil || *rec.Latitude != 40.7616 {
+		t.Errorf("latitude = %v", rec.Latitude)
+	}
+
+	if rec := NYCDirectoryRecord(NYCRestaurantRecord{CAMIS: "1", DBA: "X"}); rec.Latitude != nil {
+		t.Error("zero coordinates should be absent")
+	}
 }
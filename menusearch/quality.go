@@ -0,0 +1,98 @@
+package menusearch
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"fodmap/pipeline"
+	"fodmap/scraper"
+	"fodmap/server"
+)
+
+//go:embed store/sql/insert_extraction_quality.sql
+var insertExtractionQualitySQL string
+
+// ExtractionQuality is one scored extraction of one menu URL.
+type ExtractionQuality struct {
+	BusinessID uuid.UUID
+	SourceURL  string
+	Tier       string
+	Report     pipeline.QualityReport
+	// Quarantined marks a result held back below the quality threshold.
+	Quarantined bool
+	// LastError records why an extraction that produced no items failed.
+	LastError string
+	// Result is the extraction itself, kept for review.
+	Result     *scraper.MenuExtractionResult
+	BronzePath string
+}
+
+// needsReview reports whether q opens a pending review: a quarantined
+// result, or a failed extraction whose source was kept.
+func (q ExtractionQuality) needsReview() bool {
+	return q.Quarantined || (q.LastError != "" && q.BronzePath != "")
+}
+
+// RecordExtractionQuality stores q and returns its ID. Quarantined results
+// and failed extractions with a stored source are queued for review,
+// superseding earlier reviews of the same URL.
+func (s *Store) RecordExtractionQuality(ctx context.Context, q ExtractionQuality) (uuid.UUID, error) {
+	report, err := json.Marshal(q.Report)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("marshal quality report: %w", err)
+	}
+	var result []byte
+	if q.Result != nil {
+		if result, err = json.Marshal(q.Result); err != nil {
+			return uuid.Nil, fmt.Errorf("marshal extraction result: %w", err)
+		}
+	}
+	var reviewStatus string
+	if q.needsReview() {
+		// The newest extraction of a URL replaces any review still pending
+		// for it, so job retries don't queue the same menu repeatedly.
+		if err := s.SupersedeMenuReviews(ctx, q.BusinessID, q.SourceURL); err != nil {
+			return uuid.Nil, fmt.Errorf("supersede pending reviews: %w", err)
+		}
+		reviewStatus = server.ReviewPending
+	}
+	var id uuid.UUID
+	err = s.pool.QueryRow(ctx, insertExtractionQualitySQL,
+		q.BusinessID, q.SourceURL, q.Tier, q.Report.Score, q.Report.Grounded,
+		len(q.Report.Items), q.Report.Flagged(), report, q.Quarantined, result, q.BronzePath,
+		reviewStatus, q.LastError,
+	).Scan(&id)
+	return id, err
+}
+
+// ScoreExtraction scores result against the sources it was read from and
+// records the report under q's restaurant and URL. A result below policy's
+// threshold is kept for review instead and the restaurant marked
+// quarantined; the caller must then not store it. It returns the report and
+// whether result was quarantined. An error recording a result that was not
+// quarantined leaves it safe to store.
+func (s *Store) ScoreExtraction(ctx context.Context, policy pipeline.QualityPolicy, q ExtractionQuality, result *scraper.MenuExtractionResult, sources ...[]byte) (pipeline.QualityReport, bool, error) {
+	report := policy.Score(*result, sources...)
+	q.Tier, q.Report = result.ExtractionTier, report
+	quarantine := policy.Quarantine(report)
+	if quarantine {
+		q.Quarantined, q.Result = true, result
+	}
+	if _, err := s.RecordExtractionQuality(ctx, q); err != nil {
+		if quarantine {
+			return report, true, fmt.Errorf("record quarantined extraction: %w", err)
+		}
+		return report, false, fmt.Errorf("record extraction quality: %w", err)
+	}
+	if quarantine {
+		msg := fmt.Sprintf("extraction quarantined for review: %s (threshold %.2f)", report, policy.MinScore)
+		if err := s.UpdateScrapeResult(ctx, q.BusinessID, StatusQuarantined, 0, msg); err != nil {
+			return report, true, fmt.Errorf("update status: %w", err)
+		}
+	}
+	return report, quarantine, nil
+}
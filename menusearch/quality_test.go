@@ -0,0 +1,21 @@
+package menusearch
+
+import "testing"
+
+func TestExtractionQuality_NeedsReview(t *testing.T) {
+	cases := []struct {
+		name string
+		q    ExtractionQuality
+		want bool
+	}{
+		{"stored", ExtractionQuality{}, false},
+		{"quarantined", ExtractionQuality{Quarantined: true}, true},
+		{"failed with source", ExtractionQuality{LastError: "no menu items found", BronzePath: "x.html"}, true},
+		{"failed without source", ExtractionQuality{LastError: "no menu items found"}, false},
+	}
+	for _, tc := range cases {
+		if got := tc.q.needsReview(); got != tc.want {
+			t.Errorf("%s: needsReview() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package menusearch
+
+import (
+	"cmp"
+	"context"
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/riverqueue/river"
+
+	"fodmap/pipeline"
+)
+
+//go:embed store/sql/list_refresh_candidates.sql
+var listRefreshCandidatesSQL string
+
+//go:embed store/sql/mark_refresh_queued.sql
+var markRefreshQueuedSQL string
+
+//go:embed store/sql/scrapes_queued_since.sql
+var scrapesQueuedSinceSQL string
+
+// refreshCandidateWindow caps how many of the stalest restaurants one
+// refresh run ranks. Priority reorders within the window, so a restaurant
+// nobody asks about still reaches the front once it is among the stalest.
+const refreshCandidateWindow = 5000
+
+// favoriteDemand weighs a favorite against conversations when scoring
+// demand: saving a restaurant says more than asking about it once.
+const favoriteDemand = 3
+
+// RefreshMenusArgs is the periodic job that queues re-scrapes of stale menus.
+type RefreshMenusArgs struct{}
+
+func (RefreshMenusArgs) Kind() string {
+	return "menusearch.refresh_menus"
+}
+
+// RefreshPolicy decides which restaurants are re-scraped and in what order.
+// A restaurant is due once its last scrape is older than its staleness
+// threshold; due restaurants are ranked by how overdue they are, user demand
+// and how often their menu has changed, then queued until the daily scrape
+// budget is spent.
+type RefreshPolicy struct {
+	// StaleAfter maps a restaurant status to how long after its last scrape
+	// it is due. Statuses not listed are never refreshed. Restaurants never
+	// scraped count from their last failed scrape or refresh, whichever is
+	// later, or else their import.
+	StaleAfter map[string]time.Duration
+	// TierStaleAfter overrides StaleAfter for scraped restaurants by
+	// extraction tier (see pipeline.Tier*), so menus that are cheap to
+	// re-extract can be kept fresher than ones needing a browser.
+	TierStaleAfter map[string]time.Duration
+	// DailyBudget caps scrape jobs per UTC day across the pipeline,
+	// including discovery-driven and admin-queued scrapes; refreshes use
+	// what is left. 0 means unlimited.
+	DailyBudget int
+	// MaxPerRun caps scrape jobs queued by one refresh run so the budget is
+	// spread over the day. 0 means no cap beyond the budget.
+	MaxPerRun int
+	// RetryBackoff is how long to wait after a refresh that has not yet
+	// produced a successful scrape. It doubles with each further attempt,
+	// up to MaxBackoff.
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+	// DemandWeight and ChangeWeight scale the demand and change-frequency
+	// boosts; 0 disables a signal.
+	DemandWeight float64
+	ChangeWeight float64
+}
+
+// DefaultRefreshPolicy re-scrapes menus monthly, JSON-LD menus (no LLM
+// cost) fortnightly and browser-rendered menus every six weeks. Failed
+// scrapes are retried weekly and permanent failures every two months, both
+// with exponential backoff.
+func DefaultRefreshPolicy() RefreshPolicy {
+	const day = 24 * time.Hour
+	return RefreshPolicy{
+		StaleAfter: map[string]time.Duration{
+			StatusScraped:           30 * day,
+			StatusFailedScrape:      7 * day,
+			StatusFailedPermanently: 60 * day,
+		},
+		TierStaleAfter: map[string]time.Duration{
+			pipeline.TierJSONLD:          14 * day,
+			pipeline.TierWebagent:        42 * day,
+			pipeline.TierDirectoryFanout: 42 * day,
+		},
+		DailyBudget:  200,
+		RetryBackoff: day,
+		MaxBackoff:   60 * day,
+		DemandWeight: 1,
+		ChangeWeight: 2,
+	}
+}
+
+// RefreshCandidate is a restaurant the refresh policy may re-scrape, with
+// the signals it is ranked by.
+type RefreshCandidate struct {
+	ID              uuid.UUID
+	CAMIS           string
+	DBA             string
+	Status          string
+	ExtractionTier  string
+	MenuURLs        []string
+	ScrapedAt       *time.Time
+	ScrapeFailedAt  *time.Time
+	RefreshQueuedAt *time.Time
+	// RefreshAttempts counts refreshes queued since the last successful
+	// scrape.
+	RefreshAttempts int
+	CreatedAt       time.Time
+	Conversations   int // conversations about the restaurant in the last 90 days
+	Favorites       int
+	Rescrapes       int // re-scrapes in the last 180 days
+	ChangedScrapes  int // of which changed the menu
+
+	// Priority is set by Plan.
+	Priority float64
+}
+
+// stalenessRef returns the time c's staleness is measured from: its last
+// successful scrape, else the later of its last failed scrape and last
+// refresh, else its import.
+func (c RefreshCandidate) stalenessRef() time.Time {
+	if c.ScrapedAt != nil {
+		return *c.ScrapedAt
+	}
+	var ref *time.Time
+	for _, t := range []*time.Time{c.ScrapeFailedAt, c.RefreshQueuedAt} {
+		if t != nil && (ref == nil || t.After(*ref)) {
+			ref = t
+		}
+	}
+	if ref == nil {
+		return c.CreatedAt
+	}
+	return *ref
+}
+
+// staleAfter returns c's staleness threshold and whether its status is
+// refreshed at all.
+func (p RefreshPolicy) staleAfter(c RefreshCandidate) (time.Duration, bool) {
+	if c.Status == StatusScraped {
+		if d, ok := p.TierStaleAfter[c.ExtractionTier]; ok {
+			return d, true
+		}
+	}
+	d, ok := p.StaleAfter[c.Status]
+	return d, ok && d > 0
+}
+
+// minStaleAfter is the shortest threshold in p, used to prefilter
+// candidates in SQL.
+func (p RefreshPolicy) minStaleAfter() time.Duration {
+	m := time.Duration(math.MaxInt64)
+	for _, d := range p.StaleAfter {
+		if d > 0 {
+			m = min(m, d)
+		}
+	}
+	for _, d := range p.TierStaleAfter {
+		if d > 0 {
+			m = min(m, d)
+		}
+	}
+	return m
+}
+
+// statuses lists the statuses p refreshes.
+func (p RefreshPolicy) statuses() []string {
+	out := make([]string, 0, len(p.StaleAfter))
+	for s, d := range p.StaleAfter {
+		if d > 0 {
+			out = append(out, s)
+		}
+	}
+	if len(p.TierStaleAfter) > 0 && !slices.Contains(out, StatusScraped) {
+		out = append(out, StatusScraped)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// backoff returns the wait after the given number of unsuccessful refresh
+// attempts.
+func (p RefreshPolicy) backoff(attempts int) time.Duration {
+	if attempts <= 0 || p.RetryBackoff <= 0 {
+		return 0
+	}
+	d := p.RetryBackoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	if p.MaxBackoff > 0 {
+		d = min(d, p.MaxBackoff)
+	}
+	return d
+}
+
+// priority scores a due candidate: how many thresholds overdue it is,
+// boosted logarithmically by demand and linearly by the share of its
+// re-scrapes that found changes. It returns 0 when c is not due at now.
+func (p RefreshPolicy) priority(now time.Time, c RefreshCandidate) float64 {
+	threshold, ok := p.staleAfter(c)
+	if !ok {
+		return 0
+	}
+	age := now.Sub(c.stalenessRef())
+	if age < threshold {
+		return 0
+	}
+	if c.RefreshAttempts > 0 && c.RefreshQueuedAt != nil &&
+		now.Before(c.RefreshQueuedAt.Add(p.backoff(c.RefreshAttempts))) {
+		return 0
+	}
+
+	score := age.Hours() / threshold.Hours()
+	demand := float64(c.Conversations + favoriteDemand*c.Favorites)
+	score *= 1 + p.DemandWeight*math.Log1p(demand)
+	if c.Rescrapes > 0 {
+		score *= 1 + p.ChangeWeight*float64(c.ChangedScrapes)/float64(c.Rescrapes)
+	}
+	return score
+}
+
+// Plan returns the due candidates to re-scrape now, highest priority first,
+// whose menu URLs fit within budget scrape jobs. A restaurant too large for
+// what is left is passed over for smaller ones. budget < 0 means unlimited.
+func (p RefreshPolicy) Plan(now time.Time, candidates []RefreshCandidate, budget int) []RefreshCandidate {
+	var due []RefreshCandidate
+	for _, c := range candidates {
+		if len(c.MenuURLs) == 0 {
+			continue
+		}
+		if c.Priority = p.priority(now, c); c.Priority > 0 {
+			due = append(due, c)
+		}
+	}
+	slices.SortStableFunc(due, func(a, b RefreshCandidate) int {
+		if c := cmp.Compare(b.Priority, a.Priority); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID.String(), b.ID.String())
+	})
+	if budget < 0 {
+		return due
+	}
+	var plan []RefreshCandidate
+	for _, c := range due {
+		if len(c.MenuURLs) > budget {
+			continue
+		}
+		budget -= len(c.MenuURLs)
+		plan = append(plan, c)
+		if budget == 0 {
+			break
+		}
+	}
+	return plan
+}
+
+// ListRefreshCandidates returns up to limit restaurants in statuses with menu
+// URLs whose last scrape is older than staleBefore, stalest first, with
+// their ranking signals.
+func (s *Store) ListRefreshCandidates(ctx context.Context, statuses []string, staleBefore time.Time, limit int) ([]RefreshCandidate, error) {
+	rows, err := s.pool.Query(ctx, listRefreshCandidatesSQL, statuses, staleBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []RefreshCandidate
+	for rows.Next() {
+		var c RefreshCandidate
+		if err := rows.Scan(&c.ID, &c.CAMIS, &c.DBA, &c.Status, &c.ExtractionTier, &c.MenuURLs,
+			&c.ScrapedAt, &c.ScrapeFailedAt, &c.RefreshQueuedAt, &c.RefreshAttempts, &c.CreatedAt,
+			&c.Conversations, &c.Favorites, &c.Rescrapes, &c.ChangedScrapes); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// MarkRefreshQueued records that the refresh policy queued restaurant id.
+func (s *Store) MarkRefreshQueued(ctx context.Context, id uuid.UUID) error {
+	_, err := s.pool.Exec(ctx, markRefreshQueuedSQL, id)
+	return err
+}
+
+// ScrapesQueuedSince counts scrape jobs inserted since t by any caller.
+func (s *Store) ScrapesQueuedSince(ctx context.Context, t time.Time) (int, error) {
+	table := pgx.Identifier{s.riverSchema, "river_job"}.Sanitize()
+	var n int
+	err := s.pool.QueryRow(ctx, fmt.Sprintf(scrapesQueuedSinceSQL, table), t).Scan(&n)
+	return n, err
+}
+
+// RefreshMenusWorker runs a RefreshPolicy: each run ranks stale restaurants
+// and queues refresh scrapes for as many as the remaining daily budget
+// allows.
+type RefreshMenusWorker struct {
+	river.WorkerDefaults[RefreshMenusArgs]
+	Store       *Store
+	RiverClient *river.Client[pgx.Tx]
+	Policy      RefreshPolicy
+	MaxAttempts int
+	// ScrapeStaggerSeconds spaces out the jobs a run queues so a run does
+	// not hit every site at once.
+	ScrapeStaggerSeconds int
+}
+
+func (w *RefreshMenusWorker) Timeout(job *river.Job[RefreshMenusArgs]) time.Duration {
+	return 5 * time.Minute
+}
+
+func (w *RefreshMenusWorker) Work(ctx context.Context, job *river.Job[RefreshMenusArgs]) error {
+	logger := slog.With("job", job.ID)
+	now := time.Now().UTC()
+
+	budget := -1
+	if w.Policy.DailyBudget > 0 {
+		used, err := w.Store.ScrapesQueuedSince(ctx, now.Truncate(24*time.Hour))
+		if err != nil {
+			return fmt.Errorf("count today's scrapes: %w", err)
+		}
+		budget = max(w.Policy.DailyBudget-used, 0)
+		if budget == 0 {
+			logger.Info("menu refresh: daily scrape budget spent", "budget", w.Policy.DailyBudget, "used", used)
+			return nil
+		}
+	}
+	if w.Policy.MaxPerRun > 0 && (budget < 0 || w.Policy.MaxPerRun < budget) {
+		budget = w.Policy.MaxPerRun
+	}
+
+	statuses := w.Policy.statuses()
+	if len(statuses) == 0 {
+		return nil
+	}
+	candidates, err := w.Store.ListRefreshCandidates(ctx, statuses, now.Add(-w.Policy.minStaleAfter()), refreshCandidateWindow)
+	if err != nil {
+		return fmt.Errorf("list refresh candidates: %w", err)
+	}
+	plan := w.Policy.Plan(now, candidates, budget)
+
+	stagger := time.Duration(max(w.ScrapeStaggerSeconds, 0)) * time.Second
+	queued := 0
+	for _, c := range plan {
+		for _, u := range c.MenuURLs {
+			_, err := w.RiverClient.Insert(ctx, ScrapeMenuArgs{
+				RestaurantID: c.ID,
+				URL:          u,
+				DBA:          c.DBA,
+				Refresh:      true,
+			}, &river.InsertOpts{
+				MaxAttempts: w.MaxAttempts,
+				UniqueOpts: river.UniqueOpts{
+					ByArgs:   true,
+					ByPeriod: 24 * time.Hour,
+				},
+				ScheduledAt: now.Add(time.Duration(queued) * stagger),
+			})
+			if err != nil {
+				return fmt.Errorf("enqueue refresh scrape for %s: %w", u, err)
+			}
+			queued++
+		}
+		if err := w.Store.MarkRefreshQueued(ctx, c.ID); err != nil {
+			return fmt.Errorf("mark refresh queued for %s: %w", c.ID, err)
+		}
+		logger.Debug("menu refresh queued", "camis", c.CAMIS, "status", c.Status, "priority", c.Priority, "urls", len(c.MenuURLs))
+	}
+	logger.Info("menu refresh run", "candidates", len(candidates), "restaurants", len(plan), "scrapes", queued, "budget", budget)
+	return nil
+}
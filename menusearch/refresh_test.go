@@ -0,0 +1,187 @@
+package menusearch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fodmap/directory"
+	"fodmap/pipeline"
+	"fodmap/search"
+)
+
+func refreshCandidate(status string, scrapedDaysAgo int, now time.Time) RefreshCandidate {
+	c := RefreshCandidate{
+		ID:        uuid.New(),
+		Status:    status,
+		MenuURLs:  []string{"https://example.com/menu"},
+		CreatedAt: now.Add(-365 * 24 * time.Hour),
+	}
+	if scrapedDaysAgo >= 0 {
+		t := now.Add(-time.Duration(scrapedDaysAgo) * 24 * time.Hour)
+		c.ScrapedAt = &t
+	}
+	return c
+}
+
+func TestRefreshPolicy_Plan_StalenessAndTiers(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	p := DefaultRefreshPolicy()
+
+	fresh := refreshCandidate(StatusScraped, 10, now)
+	stale := refreshCandidate(StatusScraped, 31, now)
+	jsonld := refreshCandidate(StatusScraped, 15, now)
+	jsonld.ExtractionTier = pipeline.TierJSONLD
+	failed := refreshCandidate(StatusFailedScrape, -1, now) // never scraped; counts from import
+	pending := refreshCandidate(StatusPendingDiscovery, -1, now)
+	noURLs := refreshCandidate(StatusScraped, 90, now)
+	noURLs.MenuURLs = nil
+
+	plan := p.Plan(now, []RefreshCandidate{fresh, stale, jsonld, failed, pending, noURLs}, -1)
+	got := map[uuid.UUID]bool{}
+	for _, c := range plan {
+		got[c.ID] = true
+	}
+	if len(plan) != 3 || !got[stale.ID] || !got[jsonld.ID] || !got[failed.ID] {
+		t.Fatalf("plan = %+v, want the stale, JSON-LD and failed restaurants", plan)
+	}
+}
+
+func TestRefreshPolicy_Plan_PriorityAndBudget(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	p := DefaultRefreshPolicy()
+
+	quiet := refreshCandidate(StatusScraped, 40, now)
+	quiet.DBA = "quiet"
+	popular := refreshCandidate(StatusScraped, 35, now)
+	popular.DBA = "popular"
+	popular.Conversations, popular.Favorites = 10, 2
+	churny := refreshCandidate(StatusScraped, 35, now)
+	churny.DBA = "churny"
+	churny.Rescrapes, churny.ChangedScrapes = 4, 4
+	big := refreshCandidate(StatusScraped, 60, now)
+	big.DBA = "big"
+	big.MenuURLs = []string{"a", "b", "c"}
+
+	plan := p.Plan(now, []RefreshCandidate{quiet, popular, churny, big}, 2)
+	if len(plan) != 2 {
+		t.Fatalf("plan has %d restaurants, want 2 within a budget of 2 scrapes: %+v", len(plan), plan)
+	}
+	// popular: 35/30 * (1+ln 17) ≈ 4.47; churny: 35/30 * 3 = 3.5; quiet: 1.33;
+	// big (3 URLs) does not fit.
+	if plan[0].ID != popular.ID || plan[1].ID != churny.ID {
+		t.Errorf("plan order = [%s %s], want popular then churny", plan[0].DBA, plan[1].DBA)
+	}
+	if plan[0].Priority <= plan[1].Priority {
+		t.Errorf("priorities not descending: %v, %v", plan[0].Priority, plan[1].Priority)
+	}
+
+	if plan := p.Plan(now, []RefreshCandidate{big}, 0); len(plan) != 0 {
+		t.Errorf("an exhausted budget should plan nothing, got %d", len(plan))
+	}
+}
+
+func TestRefreshPolicy_Plan_Backoff(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	p := DefaultRefreshPolicy()
+
+	// Stale and queued two days ago without a successful scrape since: the
+	// first retry waits a day, the third waits four.
+	c := refreshCandidate(StatusScraped, 45, now)
+	queued := now.Add(-48 * time.Hour)
+	c.RefreshQueuedAt = &queued
+	c.RefreshAttempts = 1
+	if plan := p.Plan(now, []RefreshCandidate{c}, -1); len(plan) != 1 {
+		t.Errorf("one failed attempt two days ago should be retried")
+	}
+	c.RefreshAttempts = 3
+	if plan := p.Plan(now, []RefreshCandidate{c}, -1); len(plan) != 0 {
+		t.Errorf("three failed attempts should back off four days")
+	}
+
+	if got := p.backoff(20); got != p.MaxBackoff {
+		t.Errorf("backoff(20) = %v, want the %v cap", got, p.MaxBackoff)
+	}
+}
+
+func TestRefreshPolicy_Plan_FailedCountsFromFailure(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	p := DefaultRefreshPolicy()
+
+	// Imported long ago and queued a month ago, but the scrape only failed
+	// yesterday: not due until a week after the failure.
+	c := refreshCandidate(StatusFailedScrape, -1, now)
+	queued := now.Add(-30 * 24 * time.Hour)
+	failed := now.Add(-24 * time.Hour)
+	c.RefreshQueuedAt, c.ScrapeFailedAt = &queued, &failed
+	if plan := p.Plan(now, []RefreshCandidate{c}, -1); len(plan) != 0 {
+		t.Errorf("a scrape that failed yesterday should not be retried yet")
+	}
+	failed = now.Add(-8 * 24 * time.Hour)
+	if plan := p.Plan(now, []RefreshCandidate{c}, -1); len(plan) != 1 {
+		t.Errorf("a scrape that failed eight days ago should be retried")
+	}
+}
+
+func TestScrapeMenuWorker_RefreshKeepsItemCount(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+
+	id, _, err := store.UpsertDirectoryRecord(ctx, directory.Record{
+		Source: directory.SourceYelp, ExternalID: "luigis-portland", Name: "Luigi's",
+		Street: "SE Hawthorne Blvd", City: "Portland", State: "OR", Zipcode: "97214",
+	}, StatusURLFound)
+	if err != nil {
+		t.Fatalf("UpsertDirectoryRecord: %v", err)
+	}
+
+	scrape := func(jobID int64, url string, refresh bool, dishes ...string) {
+		t.Helper()
+		w := &ScrapeMenuWorker{
+			Store:       store,
+			MenuStore:   &recordingMenuStore{},
+			Embedder:    search.NewHashEmbedder(),
+			Fetcher:     &bodyFetcher{body: jsonLDMenu(dishes...)},
+			AvroDestDir: t.TempDir(),
+			BronzeDir:   t.TempDir(),
+		}
+		args := ScrapeMenuArgs{RestaurantID: id, URL: url, Refresh: refresh}
+		if err := w.Work(ctx, scrapeJob(jobID, args)); err != nil {
+			t.Fatalf("Work(%s): %v", url, err)
+		}
+	}
+	itemCount := func() int {
+		t.Helper()
+		rest, err := store.GetByID(ctx, id)
+		if err != nil || rest == nil {
+			t.Fatalf("GetByID: %v, %v", rest, err)
+		}
+		return rest.ItemCount
+	}
+
+	scrape(1, "https://luigis.example/dinner", false, "Margherita", "Carbonara")
+	scrape(2, "https://luigis.example/dessert", false, "Tiramisu")
+	if got := itemCount(); got != 3 {
+		t.Fatalf("item_count = %d after scraping both menus, want 3", got)
+	}
+	// Refreshing the same menu twice replaces its count each time.
+	scrape(3, "https://luigis.example/dinner", true, "Margherita", "Carbonara")
+	scrape(4, "https://luigis.example/dinner", true, "Margherita", "Carbonara", "Lasagna")
+	if got := itemCount(); got != 4 {
+		t.Errorf("item_count = %d after refreshing the dinner menu twice, want 4", got)
+	}
+}
+
+func TestRefreshPolicy_Statuses(t *testing.T) {
+	p := DefaultRefreshPolicy()
+	p.StaleAfter[StatusFailedPermanently] = 0
+	got := p.statuses()
+	if len(got) != 2 || got[0] != StatusFailedScrape || got[1] != StatusScraped {
+		t.Errorf("statuses = %v, want [failed_scrape scraped]", got)
+	}
+	if m := p.minStaleAfter(); m != 7*24*time.Hour {
+		t.Errorf("minStaleAfter = %v, want 168h", m)
+	}
+}
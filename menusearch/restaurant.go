@@ -7,4 +7,8 @@ const (
 	StatusScraping          = "scraping"
 	StatusScraped           = "scraped"
 	StatusFailedScrape      = "failed_scrape"
+	// StatusQuarantined marks a restaurant whose extraction scored below the
+	// quality threshold; the menu is held in extraction_quality for review
+	// and nothing was written to the menu store.
+	StatusQuarantined = "quarantined"
 )
@@ -0,0 +1,295 @@
+package menusearch
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/riverqueue/river"
+
+	"fodmap/pipeline"
+	"fodmap/scraper"
+	"fodmap/search"
+	"fodmap/server"
+)
+
+//go:embed store/sql/list_menu_reviews.sql
+var listMenuReviewsSQL string
+
+//go:embed store/sql/count_menu_reviews.sql
+var countMenuReviewsSQL string
+
+//go:embed store/sql/get_menu_review.sql
+var getMenuReviewSQL string
+
+//go:embed store/sql/get_menu_review_source.sql
+var getMenuReviewSourceSQL string
+
+//go:embed store/sql/lock_menu_review.sql
+var lockMenuReviewSQL string
+
+//go:embed store/sql/resolve_menu_review.sql
+var resolveMenuReviewSQL string
+
+//go:embed store/sql/fail_quarantined_restaurant.sql
+var failQuarantinedRestaurantSQL string
+
+//go:embed store/sql/mark_menu_review_applied.sql
+var markMenuReviewAppliedSQL string
+
+//go:embed store/sql/supersede_menu_reviews.sql
+var supersedeMenuReviewsSQL string
+
+// TierHumanReview is recorded as the extraction tier of a menu written from
+// an approved review rather than by the extraction cascade.
+const TierHumanReview = "human_review"
+
+// ListMenuReviews returns reviews with the given status ("" for all), newest
+// first, and the total matching.
+func (s *Store) ListMenuReviews(ctx context.Context, status string, limit, offset int) ([]server.MenuReview, int, error) {
+	rows, err := s.pool.Query(ctx, listMenuReviewsSQL, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var out []server.MenuReview
+	for rows.Next() {
+		var m server.MenuReview
+		if err := rows.Scan(reviewColumns(&m)...); err != nil {
+			return nil, 0, err
+		}
+		out = append(out, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := s.pool.QueryRow(ctx, countMenuReviewsSQL, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return out, total, nil
+}
+
+// GetMenuReview returns the review with its extracted items, quality report
+// and any corrections.
+func (s *Store) GetMenuReview(ctx context.Context, id uuid.UUID) (*server.MenuReview, error) {
+	var m server.MenuReview
+	var result, report, corrected []byte
+	dest := append(reviewColumns(&m), &result, &report, &corrected)
+	if err := s.pool.QueryRow(ctx, getMenuReviewSQL, id).Scan(dest...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, server.ErrReviewNotFound
+		}
+		return nil, err
+	}
+	if result != nil {
+		var r scraper.MenuExtractionResult
+		if err := json.Unmarshal(result, &r); err != nil {
+			return nil, fmt.Errorf("unmarshal review result: %w", err)
+		}
+		m.Items = r.Items
+	}
+	if corrected != nil {
+		if err := json.Unmarshal(corrected, &m.Corrected); err != nil {
+			return nil, fmt.Errorf("unmarshal review corrections: %w", err)
+		}
+	}
+	m.Report = report
+	return &m, nil
+}
+
+// reviewColumns returns scan destinations for the columns shared by
+// list_menu_reviews.sql and get_menu_review.sql.
+func reviewColumns(m *server.MenuReview) []any {
+	return []any{
+		&m.ID, &m.BusinessID, &m.CAMIS, &m.RestaurantName, &m.RestaurantStatus, &m.SourceURL, &m.ExtractionTier,
+		&m.Score, &m.Grounded, &m.Quarantined, &m.LastError, &m.Status, &m.ItemCount, &m.FlaggedCount, &m.HasSource,
+		&m.ReviewedBy, &m.ReviewedAt, &m.ReviewNote, &m.AppliedAt, &m.CreatedAt,
+	}
+}
+
+// MenuReviewSource reads the bronze file the review's extraction ran on.
+func (s *Store) MenuReviewSource(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	var path *string
+	if err := s.pool.QueryRow(ctx, getMenuReviewSourceSQL, id).Scan(&path); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, server.ErrReviewNotFound
+		}
+		return nil, err
+	}
+	if path == nil {
+		return nil, server.ErrReviewNoSource
+	}
+	body, err := os.ReadFile(*path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, server.ErrReviewNoSource
+	}
+	return body, err
+}
+
+// ResolveMenuReview approves or rejects a review. Approval stores the
+// reviewer's items, or the extracted ones, as the corrected menu; the
+// restaurant is updated when the apply job runs. Rejection moves a
+// restaurant still in quarantine to failed_scrape.
+func (s *Store) ResolveMenuReview(ctx context.Context, id uuid.UUID, d server.ReviewDecision) (*server.MenuReview, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var businessID uuid.UUID
+	var status string
+	var applied bool
+	var result []byte
+	if err := tx.QueryRow(ctx, lockMenuReviewSQL, id).Scan(&businessID, &status, &applied, &result); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, server.ErrReviewNotFound
+		}
+		return nil, err
+	}
+	// Only pending reviews can be decided. An approved review whose apply
+	// job never ran may be approved again, e.g. to re-queue it.
+	if status != server.ReviewPending && !(d.Approve && status == server.ReviewApproved && !applied) {
+		return nil, server.ErrReviewClosed
+	}
+
+	var corrected []byte
+	next := server.ReviewRejected
+	if d.Approve {
+		next = server.ReviewApproved
+		items := d.Items
+		if items == nil && result != nil {
+			var r scraper.MenuExtractionResult
+			if err := json.Unmarshal(result, &r); err != nil {
+				return nil, fmt.Errorf("unmarshal review result: %w", err)
+			}
+			items = r.Items
+		}
+		if len(items) == 0 {
+			return nil, server.ErrReviewNoItems
+		}
+		if corrected, err = json.Marshal(items); err != nil {
+			return nil, fmt.Errorf("marshal corrected items: %w", err)
+		}
+	}
+	if _, err := tx.Exec(ctx, resolveMenuReviewSQL, id, next, corrected, d.ReviewerID, d.Note); err != nil {
+		return nil, err
+	}
+	if !d.Approve {
+		if _, err := tx.Exec(ctx, failQuarantinedRestaurantSQL, businessID, "extraction rejected in review"); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return s.GetMenuReview(ctx, id)
+}
+
+// MarkMenuReviewApplied records that an approved review's items were stored,
+// superseding other reviews pending for the same menu URL.
+func (s *Store) MarkMenuReviewApplied(ctx context.Context, id uuid.UUID) error {
+	_, err := s.pool.Exec(ctx, markMenuReviewAppliedSQL, id)
+	return err
+}
+
+// SupersedeMenuReviews closes the reviews pending for a restaurant's menu
+// URL.
+func (s *Store) SupersedeMenuReviews(ctx context.Context, businessID uuid.UUID, sourceURL string) error {
+	_, err := s.pool.Exec(ctx, supersedeMenuReviewsSQL, businessID, sourceURL)
+	return err
+}
+
+// ApplyMenuReviewArgs writes an approved review's items to the menu store.
+type ApplyMenuReviewArgs struct {
+	ReviewID uuid.UUID `json:"review_id"`
+}
+
+func (ApplyMenuReviewArgs) Kind() string {
+	return "menusearch.apply_menu_review"
+}
+
+// ApplyMenuReviewWorker stores the corrected items of an approved review as
+// the restaurant's menu for the review's URL, the way ScrapeMenuWorker
+// stores an extraction, and marks the restaurant scraped.
+type ApplyMenuReviewWorker struct {
+	river.WorkerDefaults[ApplyMenuReviewArgs]
+	Store     *Store
+	MenuStore server.MenuStore
+	Embedder  search.Embedder
+	// Translator, when set, translates an approved non-English menu before
+	// it is stored, as ScrapeMenuWorker does for accepted extractions.
+	Translator scraper.MenuTranslator
+}
+
+func (w *ApplyMenuReviewWorker) Work(ctx context.Context, job *river.Job[ApplyMenuReviewArgs]) error {
+	logger := slog.With("job", job.ID, "review_id", job.Args.ReviewID)
+
+	review, err := w.Store.GetMenuReview(ctx, job.Args.ReviewID)
+	if errors.Is(err, server.ErrReviewNotFound) {
+		logger.Warn("review no longer exists; nothing to apply")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get review: %w", err)
+	}
+	if review.Status != server.ReviewApproved || review.AppliedAt != nil {
+		logger.Info("review not awaiting apply; skipping", "status", review.Status)
+		return nil
+	}
+
+	rest, err := w.Store.GetByID(ctx, review.BusinessID)
+	if err != nil {
+		return fmt.Errorf("get restaurant by id: %w", err)
+	}
+	if rest == nil {
+		return fmt.Errorf("restaurant %s not found", review.BusinessID)
+	}
+
+	result := scraper.MenuExtractionResult{
+		RestaurantName: rest.DBA,
+		SourceURL:      review.SourceURL,
+		ScrapedAtUTC:   review.CreatedAt.UTC().Format(time.RFC3339),
+		Items:          review.Corrected,
+		ExtractionTier: TierHumanReview,
+	}
+	if w.Translator != nil {
+		if err := pipeline.TranslateMenu(ctx, &result, w.Translator); err != nil {
+			logger.Warn("menu translation failed; storing original text only", "language", result.Language, "error", err)
+		}
+	}
+	result.Latitude, result.Longitude = rest.Latitude, rest.Longitude
+	items, err := pipeline.ToMenuItems(ctx, result, rest.ID, review.SourceURL, w.Embedder)
+	if err != nil {
+		return fmt.Errorf("embed menu items: %w", err)
+	}
+	count, err := pipeline.StoreMenuItems(ctx, items, w.MenuStore)
+	if err != nil {
+		return fmt.Errorf("store menu: %w", err)
+	}
+
+	if err := w.Store.RecordScrapedMenu(ctx, rest.ID, review.SourceURL, count); err != nil {
+		return fmt.Errorf("update status: %w", err)
+	}
+	if err := w.Store.SetExtractionTier(ctx, rest.ID, TierHumanReview); err != nil {
+		logger.Warn("failed to record extraction tier", "error", err)
+	}
+	if err := w.Store.MarkMenuReviewApplied(ctx, review.ID); err != nil {
+		return fmt.Errorf("mark review applied: %w", err)
+	}
+	logger.Info("approved review applied", "restaurant_id", rest.ID, "count", count)
+	return nil
+}
+
+// compile-time check
+var _ server.MenuReviewStore = (*Store)(nil)
@@ -2,6 +2,7 @@ package menusearch
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -12,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/riverqueue/river"
 
+	"fodmap/egress"
 	"fodmap/pipeline"
 	"fodmap/scraper"
 	"fodmap/search"
@@ -28,8 +30,16 @@ type ScrapeMenuWorker struct {
 	AvroDestDir     string
 	EnableVision    bool
 	UsePdftotext    bool
-	WebagentAdapter string // "site/target" passed to ServiceExtractor.ScrapeJS
-	BronzeDir       string // base dir for raw HTML; defaults to data/bronze/restaurants
+	WebagentAdapter string       // "site/target" passed to ServiceExtractor.ScrapeJS
+	BronzeDir       string       // base dir for raw HTML; defaults to data/bronze/restaurants
+	Egress          *egress.Pool // optional; routes directory probes through shared proxies
+	// Quality, when set, scores every extraction before it is stored and
+	// quarantines results below its threshold.
+	Quality *pipeline.QualityPolicy
+	// Translator, when set, adds English translations to non-English menus
+	// before they are embedded and stored. A failed translation is logged
+	// and the menu is stored with its original text only.
+	Translator scraper.MenuTranslator
 }
 
 func (w *ScrapeMenuWorker) bronzeDir() string {
@@ -51,8 +61,9 @@ func (w *ScrapeMenuWorker) Work(ctx context.Context, job *river.Job[ScrapeMenuAr
 	logger := slog.With("job", job.ID, "restaurant_id", args.RestaurantID, "url", args.URL)
 	logger.Info("starting scrape job")
 
-	// Resolve the restaurant row to get camis (for status updates, file naming)
-	// and address/phone (for enrichment).
+	// Resolve the restaurant row for its status, file naming key and
+	// address/phone (for enrichment). Status writes are keyed by its id, since
+	// only DOHMH restaurants have a CAMIS.
 	rest, err := w.Store.GetByID(ctx, args.RestaurantID)
 	if err != nil {
 		return fmt.Errorf("get restaurant by id: %w", err)
@@ -60,23 +71,39 @@ func (w *ScrapeMenuWorker) Work(ctx context.Context, job *river.Job[ScrapeMenuAr
 	if rest == nil {
 		return fmt.Errorf("restaurant %s not found", args.RestaurantID)
 	}
-	camis := safeDeref(rest.CAMIS)
+	fileKey := restaurantFileKey(rest)
 
-	err = w.Store.UpdateScrapeResult(ctx, camis, StatusScraping, 0, "")
+	err = w.Store.UpdateScrapeResult(ctx, rest.ID, StatusScraping, 0, "")
 	if err != nil {
 		return fmt.Errorf("update status to scraping: %w", err)
 	}
 
-	result, rawBody, err := pipeline.ExtractMenu(ctx, args.URL, w.Fetcher, w.Extractor, w.EnableVision, w.UsePdftotext, w.WebagentAdapter)
+	// A restaurant that already has a menu is only re-extracted when its page
+	// changed. This relies on w.Fetcher reporting NotModified (see
+	// scraper.CachingFetcher); with a plain fetcher every scrape extracts.
+	// Menus read from sub-pages, images, APIs or a rendered page always
+	// re-extract, since the root page revalidating says nothing about those.
+	extract := pipeline.ExtractMenu
+	if rest.Status == StatusScraped && rest.ExtractionTier != nil && pipeline.RootOnlyTier(*rest.ExtractionTier) {
+		extract = pipeline.ExtractMenuIfChanged
+	}
+	result, rawBody, err := extract(ctx, args.URL, w.Fetcher, w.Extractor, w.EnableVision, w.UsePdftotext, w.WebagentAdapter)
+	if errors.Is(err, pipeline.ErrContentUnchanged) {
+		logger.Info("menu page unchanged since last scrape; skipping extraction")
+		if err := w.Store.UpdateScrapeResult(ctx, rest.ID, StatusScraped, 0, ""); err != nil {
+			return fmt.Errorf("update scrape result: %w", err)
+		}
+		return nil
+	}
 	if err != nil {
 		// Transient render errors (503 BrowserBusy/WafBlocked, 504 FetchTimeout)
 		// must not clobber the restaurant status — River will retry the job up to
 		// MaxAttempts times. Write StatusFailedScrape only for permanent failures.
 		if !scraper.IsRenderTransient(err) {
 			if isDeadDomainErr(err) {
-				_ = w.Store.UpdateScrapeResult(ctx, camis, StatusFailedPermanently, 0, err.Error())
+				_ = w.Store.UpdateScrapeResult(ctx, rest.ID, StatusFailedPermanently, 0, err.Error())
 			} else {
-				_ = w.Store.UpdateScrapeResult(ctx, camis, StatusFailedScrape, 0, err.Error())
+				_ = w.Store.UpdateScrapeResult(ctx, rest.ID, StatusFailedScrape, 0, err.Error())
 			}
 		} else {
 			logger.Warn("transient render error; skipping failed_scrape write for retry", "error", err)
@@ -93,12 +120,15 @@ func (w *ScrapeMenuWorker) Work(ctx context.Context, job *river.Job[ScrapeMenuAr
 
 	// Write raw body to bronze layer (best-effort). PDF bytes use .html extension
 	// like menutracking; the extension is informational only.
+	var bronzePath string
 	if len(rawBody) > 0 {
 		date := time.Now().UTC().Format("2006-01-02")
-		htmlPath := filepath.Join(w.bronzeDir(), date, fmt.Sprintf("%s-%d.html", camis, job.Attempt))
+		htmlPath := filepath.Join(w.bronzeDir(), date, fmt.Sprintf("%s-%d.html", fileKey, job.Attempt))
 		if mkErr := os.MkdirAll(filepath.Dir(htmlPath), 0o755); mkErr == nil {
 			if wErr := os.WriteFile(htmlPath, rawBody, 0o644); wErr != nil {
 				logger.Warn("failed to write HTML bronze", "path", htmlPath, "error", wErr)
+			} else {
+				bronzePath = htmlPath
 			}
 		}
 	}
@@ -109,7 +139,7 @@ func (w *ScrapeMenuWorker) Work(ctx context.Context, job *river.Job[ScrapeMenuAr
 	// through the full cascade, and aggregate the results into a single write.
 	// Sub-URL fetches happen at depth 1 (in-loop) and never recurse further.
 	if (result == nil || len(result.Items) == 0) && args.Depth == 0 {
-		expanded, expandErr := w.tryDirectoryExpansion(ctx, args, camis, rawBody, result, logger)
+		expanded, expandErr := w.tryDirectoryExpansion(ctx, args, rest, fileKey, rawBody, result, logger)
 		if expandErr != nil {
 			// tryDirectoryExpansion already wrote failed_scrape when appropriate.
 			return expandErr
@@ -123,11 +153,26 @@ func (w *ScrapeMenuWorker) Work(ctx context.Context, job *river.Job[ScrapeMenuAr
 	}
 
 	if result == nil || len(result.Items) == 0 {
-		_ = w.Store.UpdateScrapeResult(ctx, camis, StatusFailedScrape, 0, "no menu items found")
+		_ = w.Store.UpdateScrapeResult(ctx, rest.ID, StatusFailedScrape, 0, "no menu items found")
+		// A page that yielded nothing but was kept can still be read by a
+		// reviewer, who may enter its menu by hand.
+		if bronzePath != "" {
+			q := ExtractionQuality{BusinessID: rest.ID, SourceURL: args.URL, LastError: "no menu items found", BronzePath: bronzePath}
+			if result != nil {
+				q.Tier = result.ExtractionTier
+			}
+			if _, err := w.Store.RecordExtractionQuality(ctx, q); err != nil {
+				logger.Warn("failed to queue failed extraction for review", "error", err)
+			}
+		}
 		return fmt.Errorf("no menu items found")
 	}
 
-	return w.storeAndFinish(ctx, job, args, camis, rest.ID, result, logger)
+	var sources [][]byte
+	if len(rawBody) > 0 {
+		sources = append(sources, rawBody)
+	}
+	return w.storeAndFinish(ctx, job, args, rest, fileKey, result, sources, bronzePath, logger)
 }
 
 // tryDirectoryExpansion attempts sub-URL discovery and extraction when the root
@@ -137,12 +182,13 @@ func (w *ScrapeMenuWorker) Work(ctx context.Context, job *river.Job[ScrapeMenuAr
 func (w *ScrapeMenuWorker) tryDirectoryExpansion(
 	ctx context.Context,
 	args ScrapeMenuArgs,
-	camis string,
+	rest *server.Restaurant,
+	fileKey string,
 	rawBody []byte,
 	rootResult *scraper.MenuExtractionResult,
 	logger *slog.Logger,
 ) (bool, error) {
-	logger.Info(fmt.Sprintf("directory expansion of %s: root URL %s yielded 0 items; attempting sub-URL extraction", camis, args.URL))
+	logger.Info(fmt.Sprintf("directory expansion of %s: root URL %s yielded 0 items; attempting sub-URL extraction", fileKey, args.URL))
 
 	// Obtain HTML for anchor parsing.  rawBody is populated by the normal fetch
 	// path.  For JS-rendered pages the pipeline returns nil rawBody — in that
@@ -177,7 +223,7 @@ func (w *ScrapeMenuWorker) tryDirectoryExpansion(
 
 	// Validate candidates with a plain-GET menu-signal check (same pattern as
 	// DiscoverMenuURLWorker).  Pass "" as primaryURL so no URL is pinned.
-	httpClient := buildDirectoryClient()
+	httpClient := buildDirectoryClient(w.Egress)
 	confirmed := menuSignalFilter(ctx, httpClient, candidates, "", logger)
 	if len(confirmed) == 0 {
 		logger.Info("directory expansion: no candidates survived signal filter")
@@ -226,49 +272,97 @@ func (w *ScrapeMenuWorker) tryDirectoryExpansion(
 	aggregated.ScrapedAtUTC = time.Now().UTC().Format(time.RFC3339)
 
 	// Write sub-URL raw bodies to bronze (best-effort, per-URL provenance).
+	// The bodies are also the grounding sources for the quality check, unless
+	// a sub-URL was read by image OCR: its items cannot be grounded in text,
+	// so the aggregate falls back to the structural checks alone.
+	var sources [][]byte
+	var bronzePath string
+	groundable := true
 	for i, sr := range subResults {
+		if sr.result.ExtractionTier == pipeline.TierImageOCR || len(sr.rawBody) == 0 {
+			groundable = false
+		}
 		if len(sr.rawBody) == 0 {
 			continue
 		}
+		sources = append(sources, sr.rawBody)
 		date := time.Now().UTC().Format("2006-01-02")
 		subPath := filepath.Join(w.bronzeDir(), date,
-			fmt.Sprintf("%s-sub%d.html", camis, i))
+			fmt.Sprintf("%s-sub%d.html", fileKey, i))
 		if mkErr := os.MkdirAll(filepath.Dir(subPath), 0o755); mkErr == nil {
 			if wErr := os.WriteFile(subPath, sr.rawBody, 0o644); wErr != nil {
 				logger.Warn("failed to write sub-URL bronze", "path", subPath, "error", wErr)
+			} else if bronzePath == "" {
+				bronzePath = subPath
 			}
 		}
 	}
+	if !groundable {
+		sources = nil
+	}
 
 	logger.Info("directory expansion: aggregated items", "count", len(aggregated.Items), "sub_urls", len(subResults))
 
-	if err := w.storeAndFinish(ctx, nil, args, camis, args.RestaurantID, &aggregated, logger); err != nil {
+	if err := w.storeAndFinish(ctx, nil, args, rest, fileKey, &aggregated, sources, bronzePath, logger); err != nil {
 		return false, err
 	}
 	return true, nil
 }
 
-// storeAndFinish writes the Avro record, stores the menu items, updates scrape
-// status, and records the extraction tier.  job may be nil when called from the
-// directory expansion path (attempt/jobID fields are left as zero/empty).
+// storeAndFinish scores the result against its sources, writes the Avro
+// record, stores the menu items, updates scrape status, and records the
+// extraction tier.  job may be nil when called from the directory expansion
+// path (attempt/jobID fields are left as zero/empty).  A result below the
+// quality threshold is quarantined instead: it is recorded for review, the
+// restaurant is marked quarantined, and nothing reaches the menu store.
+// Accepted non-English menus are translated first when a Translator is set.
 func (w *ScrapeMenuWorker) storeAndFinish(
 	ctx context.Context,
 	job *river.Job[ScrapeMenuArgs],
 	args ScrapeMenuArgs,
-	camis string,
-	restaurantID uuid.UUID,
+	rest *server.Restaurant,
+	fileKey string,
 	result *scraper.MenuExtractionResult,
+	sources [][]byte,
+	bronzePath string,
 	logger *slog.Logger,
 ) error {
+	if w.Quality != nil {
+		report, quarantined, err := w.Store.ScoreExtraction(ctx, *w.Quality, ExtractionQuality{
+			BusinessID: rest.ID,
+			SourceURL:  args.URL,
+			BronzePath: bronzePath,
+		}, result, sources...)
+		if quarantined {
+			if err != nil {
+				return err
+			}
+			logger.Warn("extraction quarantined", "score", report.Score, "flagged", report.Flagged(),
+				"items", len(report.Items), "grounded", report.Grounded, "tier", result.ExtractionTier)
+			return nil
+		}
+		if err != nil {
+			logger.Warn("failed to record extraction quality", "error", err)
+		}
+		logger.Info("extraction quality", "score", report.Score, "flagged", report.Flagged(), "grounded", report.Grounded)
+	}
+
+	if w.Translator != nil {
+		if err := pipeline.TranslateMenu(ctx, result, w.Translator); err != nil {
+			logger.Warn("menu translation failed; storing original text only", "language", result.Language, "error", err)
+		}
+	}
+
 	eventID := uuid.NewString()
 
 	// Build the full []search.MenuItem once: it carries every per-item field
 	// (section, price, modifiers, source_url, scraped_at, MenuItemID) so the
 	// Avro record is a complete snapshot that can replay the menu_items table
 	// bit-for-bit, and the DB upsert reuses the same items (no re-embedding).
-	items, err := pipeline.ToMenuItems(ctx, *result, restaurantID, args.URL, w.Embedder)
+	result.Latitude, result.Longitude = rest.Latitude, rest.Longitude
+	items, err := pipeline.ToMenuItems(ctx, *result, rest.ID, args.URL, w.Embedder)
 	if err != nil {
-		_ = w.Store.UpdateScrapeResult(ctx, camis, StatusFailedScrape, 0, fmt.Errorf("embed menu items: %w", err).Error())
+		_ = w.Store.UpdateScrapeResult(ctx, rest.ID, StatusFailedScrape, 0, fmt.Errorf("embed menu items: %w", err).Error())
 		return fmt.Errorf("embed menu items: %w", err)
 	}
 
@@ -280,7 +374,7 @@ func (w *ScrapeMenuWorker) storeAndFinish(
 	}
 
 	record := MenuExtractionRecord{
-		BusinessID:       restaurantID.String(),
+		BusinessID:       rest.ID.String(),
 		SourceURL:        args.URL,
 		RestaurantName:   result.RestaurantName,
 		Items:            items,
@@ -291,27 +385,32 @@ func (w *ScrapeMenuWorker) storeAndFinish(
 		ExtractionTier:   result.ExtractionTier,
 	}
 
-	avroDest := filepath.Join(w.AvroDestDir, fmt.Sprintf("%s-%d.avro", camis, attempt))
+	avroDest := filepath.Join(w.AvroDestDir, fmt.Sprintf("%s-%d.avro", fileKey, attempt))
 	if err := WriteMenuExtractionAvro(ctx, avroDest, record); err != nil {
 		logger.Error("failed to write avro", "error", err)
 	}
 
 	count, err := pipeline.StoreMenuItems(ctx, items, w.MenuStore)
 	if err != nil {
-		_ = w.Store.UpdateScrapeResult(ctx, camis, StatusFailedScrape, 0, err.Error())
+		_ = w.Store.UpdateScrapeResult(ctx, rest.ID, StatusFailedScrape, 0, err.Error())
 		return fmt.Errorf("store menu: %w", err)
 	}
 
-	err = w.Store.UpdateScrapeResult(ctx, camis, StatusScraped, count, "")
+	err = w.Store.RecordScrapedMenu(ctx, rest.ID, args.URL, count)
 	if err != nil {
 		return fmt.Errorf("update status: %w", err)
 	}
 
 	// Tier-mix telemetry (best-effort): record which cascade tier produced this
 	// result so the JSON-LD vs. LLM/OCR/browser split can be measured.
-	if tErr := w.Store.SetExtractionTier(ctx, camis, result.ExtractionTier); tErr != nil {
+	if tErr := w.Store.SetExtractionTier(ctx, rest.ID, result.ExtractionTier); tErr != nil {
 		logger.Warn("failed to record extraction tier", "tier", result.ExtractionTier, "error", tErr)
 	}
+	// Reviews still pending for this URL describe a menu that has now been
+	// scraped successfully.
+	if sErr := w.Store.SupersedeMenuReviews(ctx, rest.ID, args.URL); sErr != nil {
+		logger.Warn("failed to supersede pending reviews", "error", sErr)
+	}
 
 	logger.Info("scrape successful", "count", count, "tier", result.ExtractionTier)
 	return nil
@@ -0,0 +1,290 @@
+package menusearch
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"fodmap/directory"
+	"fodmap/geo"
+	"fodmap/server"
+)
+
+//go:embed store/sql/get_restaurant_id_by_external_id.sql
+var getRestaurantIDByExternalIDSQL string
+
+//go:embed store/sql/link_external_id.sql
+var linkExternalIDSQL string
+
+//go:embed store/sql/list_resolution_candidates.sql
+var listResolutionCandidatesSQL string
+
+//go:embed store/sql/insert_directory_restaurant.sql
+var insertDirectoryRestaurantSQL string
+
+//go:embed store/sql/merge_directory_restaurant.sql
+var mergeDirectoryRestaurantSQL string
+
+// candidateRadiusMeters bounds the coordinate prefilter for entity
+// resolution; directory.Resolve applies the tighter per-rule distances.
+const candidateRadiusMeters = 200
+
+// NYCSource is the NYC DOHMH inspection feed for one area as a
+// directory.Source. When BronzePath is set the raw download is kept there as
+// Avro, and an existing file is replayed instead of refetching.
+type NYCSource struct {
+	Area       geo.Area
+	AppToken   string
+	Since      time.Time
+	BronzePath string
+}
+
+// Name implements directory.Source.
+func (s *NYCSource) Name() string { return directory.SourceNYC }
+
+// Fetch implements directory.Source. Polygon areas are fetched by bounding
+// box; callers apply exact membership with Record.Place.
+func (s *NYCSource) Fetch(ctx context.Context) ([]directory.Record, error) {
+	raw, err := s.fetchRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]directory.Record, len(raw))
+	for i, rec := range raw {
+		records[i] = NYCDirectoryRecord(rec)
+	}
+	return records, nil
+}
+
+func (s *NYCSource) fetchRaw(ctx context.Context) ([]NYCRestaurantRecord, error) {
+	if s.BronzePath != "" {
+		if _, err := os.Stat(s.BronzePath); err == nil {
+			slog.Info("menusearch: reusing NYC bronze file", "path", s.BronzePath)
+			records, err := ReadNYCRestaurantAvro(s.BronzePath)
+			if err != nil {
+				return nil, fmt.Errorf("read avro: %w", err)
+			}
+			return records, nil
+		}
+	}
+
+	reader, err := FetchNYCRestaurants(ctx, s.Area, s.AppToken, s.Since)
+	if err != nil {
+		return nil, fmt.Errorf("fetch restaurants: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	records, err := ParseNYCCSV(reader)
+	if err != nil {
+		return nil, fmt.Errorf("parse csv: %w", err)
+	}
+	if s.BronzePath != "" && len(records) > 0 {
+		slog.Info("menusearch: saving NYC bronze file", "path", s.BronzePath, "records", len(records))
+		if err := WriteNYCRestaurantAvro(ctx, s.BronzePath, records); err != nil {
+			return nil, fmt.Errorf("write avro: %w", err)
+		}
+	}
+	return records, nil
+}
+
+// NYCDirectoryRecord normalizes an NYC DOHMH inspection row.
+func NYCDirectoryRecord(rec NYCRestaurantRecord) directory.Record {
+	p := PlaceOfRecord(rec)
+	return directory.Record{
+		Source:     directory.SourceNYC,
+		ExternalID: rec.CAMIS,
+		Name:       rec.DBA,
+		Building:   rec.Building,
+		Street:     rec.Street,
+		City:       "New York",
+		State:      "NY",
+		Zipcode:    rec.Zipcode,
+		Boro:       rec.Boro,
+		NTA:        rec.NTA,
+		Phone:      rec.Phone,
+		Cuisine:    rec.CuisineDescription,
+		Latitude:   p.Lat,
+		Longitude:  p.Lon,
+	}
+}
+
+// directoryRecordOf is the resolution view of an existing restaurant row.
+func directoryRecordOf(r server.Restaurant) directory.Record {
+	return directory.Record{
+		Name:      r.DBA,
+		Building:  safeDeref(r.Building),
+		Street:    safeDeref(r.Street),
+		Zipcode:   safeDeref(r.Zipcode),
+		Boro:      safeDeref(r.Boro),
+		NTA:       safeDeref(r.NTA),
+		Phone:     safeDeref(r.Phone),
+		Latitude:  r.Latitude,
+		Longitude: r.Longitude,
+	}
+}
+
+// UpsertOutcome says how UpsertDirectoryRecord placed a record.
+type UpsertOutcome string
+
+const (
+	// UpsertCreated means no existing restaurant matched; a new row was added.
+	UpsertCreated UpsertOutcome = "created"
+	// UpsertUpdated means the record's external ID was already linked and
+	// the row was refreshed from it.
+	UpsertUpdated UpsertOutcome = "updated"
+	// UpsertMerged means entity resolution matched a restaurant imported
+	// from another source; the record filled in missing fields and its
+	// external ID was linked to that row.
+	UpsertMerged UpsertOutcome = "merged"
+)
+
+// UpsertDirectoryRecord stores rec and links its external ID. A record
+// already linked updates its restaurant; otherwise restaurants with the same
+// phone, nearby coordinates or the same name and zip are resolved with
+// directory.Resolve and the record merged into the best match; failing
+// that, a new restaurant is inserted with status. NYC and Yelp records also
+// set the camis and yelp_id columns the discovery and index paths key on.
+func (s *Store) UpsertDirectoryRecord(ctx context.Context, rec directory.Record, status string) (uuid.UUID, UpsertOutcome, error) {
+	if rec.Source == "" || rec.ExternalID == "" {
+		return uuid.Nil, "", errors.New("directory record needs a source and external ID")
+	}
+	var (
+		id      uuid.UUID
+		outcome UpsertOutcome
+	)
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, getRestaurantIDByExternalIDSQL, rec.Source, rec.ExternalID).Scan(&id)
+		switch {
+		case err == nil:
+			outcome = UpsertUpdated
+		case errors.Is(err, pgx.ErrNoRows):
+			match, found, err := resolveCandidate(ctx, tx, rec)
+			if err != nil {
+				return fmt.Errorf("resolve: %w", err)
+			}
+			if found {
+				id, outcome = match, UpsertMerged
+			}
+		default:
+			return fmt.Errorf("lookup external id: %w", err)
+		}
+
+		camis, yelpID := sourceColumns(rec)
+		if outcome == "" {
+			outcome = UpsertCreated
+			err = tx.QueryRow(ctx, insertDirectoryRestaurantSQL,
+				camis, yelpID, rec.Name, nullable(rec.Boro), nullable(rec.Building), nullable(rec.Street),
+				nullable(rec.Zipcode), nullable(rec.Phone), nullable(rec.FullAddress()), nullable(rec.Cuisine),
+				rec.Latitude, rec.Longitude, nullable(rec.NTA), status,
+			).Scan(&id)
+			if err != nil {
+				return fmt.Errorf("insert restaurant: %w", err)
+			}
+		} else {
+			_, err = tx.Exec(ctx, mergeDirectoryRestaurantSQL,
+				id, outcome == UpsertUpdated, camis, yelpID, rec.Name, nullable(rec.Boro), nullable(rec.Building),
+				nullable(rec.Street), nullable(rec.Zipcode), nullable(rec.Phone), nullable(rec.FullAddress()),
+				nullable(rec.Cuisine), rec.Latitude, rec.Longitude, nullable(rec.NTA),
+			)
+			if err != nil {
+				return fmt.Errorf("merge restaurant: %w", err)
+			}
+		}
+
+		if _, err := tx.Exec(ctx, linkExternalIDSQL, rec.Source, rec.ExternalID, id); err != nil {
+			return fmt.Errorf("link external id: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	return id, outcome, nil
+}
+
+// resolveCandidate loads restaurants that could be rec and returns the one
+// directory.Resolve picks.
+func resolveCandidate(ctx context.Context, tx pgx.Tx, rec directory.Record) (uuid.UUID, bool, error) {
+	var dLat, dLon float64
+	if rec.Latitude != nil && rec.Longitude != nil {
+		dLat = candidateRadiusMeters / 111_320.0
+		dLon = dLat / math.Max(math.Cos(*rec.Latitude*math.Pi/180), 0.01)
+	}
+	rows, err := tx.Query(ctx, listResolutionCandidatesSQL,
+		directory.NormalizePhone(rec.Phone), rec.Latitude, rec.Longitude, dLat, dLon, rec.Zipcode, rec.Name,
+	)
+	if err != nil {
+		return uuid.Nil, false, err
+	}
+	defer rows.Close()
+
+	var candidates []directory.Candidate
+	for rows.Next() {
+		var r server.Restaurant
+		if err := rows.Scan(
+			&r.ID, &r.CAMIS, &r.YelpID, &r.DBA, &r.Boro, &r.Building, &r.Street, &r.Zipcode, &r.Phone, &r.Address, &r.Cuisine, &r.Latitude, &r.Longitude, &r.NTA,
+			&r.Status, &r.WebsiteURL, &r.MenuURLs, &r.URLSource, &r.ExtractionTier, &r.ItemCount, &r.ScrapedAt, &r.LastError, &r.CreatedAt, &r.UpdatedAt,
+		); err != nil {
+			return uuid.Nil, false, err
+		}
+		// A restaurant that already has an ID from rec's source is a
+		// different listing in that source, not a duplicate of rec.
+		if hasSourceID(r, rec.Source) {
+			continue
+		}
+		candidates = append(candidates, directory.Candidate{ID: r.ID.String(), Record: directoryRecordOf(r)})
+	}
+	if err := rows.Err(); err != nil {
+		return uuid.Nil, false, err
+	}
+
+	match, ok := directory.Resolve(rec, candidates)
+	if !ok {
+		return uuid.Nil, false, nil
+	}
+	id, err := uuid.Parse(match.ID)
+	if err != nil {
+		return uuid.Nil, false, err
+	}
+	return id, true, nil
+}
+
+// sourceColumns returns the camis and yelp_id values rec sets.
+func sourceColumns(rec directory.Record) (camis, yelpID *string) {
+	switch rec.Source {
+	case directory.SourceNYC:
+		return &rec.ExternalID, nil
+	case directory.SourceYelp:
+		return nil, &rec.ExternalID
+	}
+	return nil, nil
+}
+
+// hasSourceID reports whether r already carries a camis or yelp_id for
+// source. Other sources are only tracked in restaurant_external_ids, so
+// they never exclude a candidate.
+func hasSourceID(r server.Restaurant, source string) bool {
+	switch source {
+	case directory.SourceNYC:
+		return r.CAMIS != nil
+	case directory.SourceYelp:
+		return r.YelpID != nil
+	}
+	return false
+}
+
+// nullable maps "" to NULL.
+func nullable(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -36,6 +36,9 @@ var updateDiscoveryURLsSQL string
 //go:embed store/sql/update_scrape_result.sql
 var updateScrapeResultSQL string
 
+//go:embed store/sql/record_scraped_menu.sql
+var recordScrapedMenuSQL string
+
 //go:embed store/sql/set_extraction_tier.sql
 var setExtractionTierSQL string
 
@@ -248,21 +251,32 @@ func (s *Store) jobCounts(ctx context.Context, counts map[string]map[string]int)
 	return rows.Err()
 }
 
-func (s *Store) UpdateDiscoveryURLs(ctx context.Context, camis, websiteURL string, menuURLs []string, source, address, phone string) error {
-	_, err := s.pool.Exec(ctx, updateDiscoveryURLsSQL, camis, websiteURL, menuURLs, source, address, phone)
+// UpdateDiscoveryURLs records the URLs discovery found for restaurant id.
+func (s *Store) UpdateDiscoveryURLs(ctx context.Context, id uuid.UUID, websiteURL string, menuURLs []string, source, address, phone string) error {
+	_, err := s.pool.Exec(ctx, updateDiscoveryURLsSQL, id, websiteURL, menuURLs, source, address, phone)
+	return err
+}
+
+// UpdateScrapeResult moves restaurant id to status. Restaurants are keyed
+// by id rather than CAMIS, which only DOHMH records carry.
+func (s *Store) UpdateScrapeResult(ctx context.Context, id uuid.UUID, status string, itemCount int, lastError string) error {
+	_, err := s.pool.Exec(ctx, updateScrapeResultSQL, id, status, itemCount, lastError)
 	return err
 }
 
-func (s *Store) UpdateScrapeResult(ctx context.Context, camis, status string, itemCount int, lastError string) error {
-	_, err := s.pool.Exec(ctx, updateScrapeResultSQL, camis, status, itemCount, lastError)
+// RecordScrapedMenu marks restaurant id scraped after sourceURL yielded
+// itemCount stored items. Its item count is the sum over its menu URLs, each
+// counted once however often it is re-scraped.
+func (s *Store) RecordScrapedMenu(ctx context.Context, id uuid.UUID, sourceURL string, itemCount int) error {
+	_, err := s.pool.Exec(ctx, recordScrapedMenuSQL, id, sourceURL, itemCount)
 	return err
 }
 
 // SetExtractionTier records which cascade tier produced a successful scrape
 // (see pipeline.Tier* constants) for tier-mix telemetry. An empty tier clears
 // the column. Best-effort: telemetry only, never blocks the scrape result.
-func (s *Store) SetExtractionTier(ctx context.Context, camis, tier string) error {
-	_, err := s.pool.Exec(ctx, setExtractionTierSQL, camis, tier)
+func (s *Store) SetExtractionTier(ctx context.Context, id uuid.UUID, tier string) error {
+	_, err := s.pool.Exec(ctx, setExtractionTierSQL, id, tier)
 	return err
 }
 
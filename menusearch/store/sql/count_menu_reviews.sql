@@ -0,0 +1,4 @@
+SELECT COUNT(*)
+FROM extraction_quality
+WHERE review_status IS NOT NULL
+  AND ($1 = '' OR review_status = $1);
@@ -0,0 +1 @@
+DELETE FROM geo_areas WHERE name = $1;
@@ -0,0 +1,2 @@
+DELETE FROM restaurant_favorites
+WHERE user_id = $1 AND business_id = $2;
@@ -0,0 +1,7 @@
+-- A rejected review leaves nothing to store; a restaurant still held in
+-- quarantine becomes an ordinary failed scrape so retry and refresh pick it up.
+UPDATE restaurants
+SET status = 'failed_scrape',
+    last_error = $2
+WHERE id = $1
+  AND status = 'quarantined';
@@ -0,0 +1 @@
+SELECT definition FROM geo_areas WHERE name = $1;
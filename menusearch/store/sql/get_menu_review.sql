@@ -0,0 +1,9 @@
+SELECT
+    q.id, q.business_id, r.camis, r.dba, r.status, q.source_url, q.extraction_tier, q.score, q.grounded, q.quarantined,
+    q.last_error, q.review_status, q.item_count, q.flagged_count, q.bronze_path IS NOT NULL,
+    q.reviewed_by, q.reviewed_at, q.review_note, q.applied_at, q.created_at,
+    q.result, q.report, q.corrected
+FROM extraction_quality q
+JOIN restaurants r ON r.id = q.business_id
+WHERE q.id = $1
+  AND q.review_status IS NOT NULL;
@@ -0,0 +1,4 @@
+SELECT bronze_path
+FROM extraction_quality
+WHERE id = $1
+  AND review_status IS NOT NULL;
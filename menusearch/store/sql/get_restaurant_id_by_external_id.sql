@@ -0,0 +1,8 @@
+-- Restaurant already linked to a source ID. Rows created before
+-- restaurant_external_ids existed, or by Upsert/UpsertByYelp, are found
+-- through their camis/yelp_id columns.
+SELECT restaurant_id FROM restaurant_external_ids WHERE source = $1 AND external_id = $2
+UNION ALL
+SELECT id FROM restaurants
+WHERE ($1 = 'nyc_dohmh' AND camis = $2) OR ($1 = 'yelp' AND yelp_id = $2)
+LIMIT 1;
@@ -0,0 +1,6 @@
+INSERT INTO restaurants (
+    camis, yelp_id, dba, boro, building, street, zipcode, phone, address, cuisine, latitude, longitude, nta, status
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+)
+RETURNING id;
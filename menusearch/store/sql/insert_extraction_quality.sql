@@ -0,0 +1,5 @@
+INSERT INTO extraction_quality
+    (business_id, source_url, extraction_tier, score, grounded, item_count, flagged_count, report, quarantined, result, bronze_path,
+     review_status, last_error)
+VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9, $10, NULLIF($11, ''), NULLIF($12, ''), NULLIF($13, ''))
+RETURNING id
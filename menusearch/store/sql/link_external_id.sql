@@ -0,0 +1,4 @@
+INSERT INTO restaurant_external_ids (source, external_id, restaurant_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (source, external_id) DO UPDATE SET
+    restaurant_id = EXCLUDED.restaurant_id;
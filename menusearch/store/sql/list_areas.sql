@@ -0,0 +1 @@
+SELECT definition FROM geo_areas ORDER BY name;
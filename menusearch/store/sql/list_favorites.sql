@@ -0,0 +1,5 @@
+SELECT r.id, r.dba, r.address, r.cuisine, r.status, r.scraped_at, f.created_at
+FROM restaurant_favorites f
+JOIN restaurants r ON r.id = f.business_id
+WHERE f.user_id = $1
+ORDER BY f.created_at DESC;
@@ -0,0 +1,10 @@
+SELECT
+    q.id, q.business_id, r.camis, r.dba, r.status, q.source_url, q.extraction_tier, q.score, q.grounded, q.quarantined,
+    q.last_error, q.review_status, q.item_count, q.flagged_count, q.bronze_path IS NOT NULL,
+    q.reviewed_by, q.reviewed_at, q.review_note, q.applied_at, q.created_at
+FROM extraction_quality q
+JOIN restaurants r ON r.id = q.business_id
+WHERE q.review_status IS NOT NULL
+  AND ($1 = '' OR q.review_status = $1)
+ORDER BY q.created_at DESC
+LIMIT $2 OFFSET $3;
@@ -0,0 +1,41 @@
+-- Restaurants in the given statuses with menu URLs whose last scrape (or,
+-- never scraped, the later of their last failure and last refresh, else their
+-- import) is older than $2, with the demand
+-- and change-frequency signals the refresh policy ranks them by:
+--   conversations in the last 90 days, favorites, and how many of the
+--   restaurant's re-scrapes in the last 180 days changed something. A
+--   URL's first snapshot lists every item as added, so only snapshots with
+--   an earlier snapshot of the same URL count.
+SELECT r.id,
+       COALESCE(r.camis, ''),
+       r.dba,
+       r.status,
+       COALESCE(r.extraction_tier, ''),
+       r.menu_urls,
+       r.scraped_at,
+       r.scrape_failed_at,
+       r.refresh_queued_at,
+       r.refresh_attempts,
+       r.created_at,
+       (SELECT count(*) FROM conversations c
+         WHERE c.business_id = r.id AND c.created_at > NOW() - interval '90 days')::int,
+       (SELECT count(*) FROM restaurant_favorites f WHERE f.business_id = r.id)::int,
+       h.rescrapes,
+       h.changed
+FROM restaurants r
+CROSS JOIN LATERAL (
+    SELECT count(*)::int AS rescrapes,
+           COALESCE(sum(CASE WHEN EXISTS (SELECT 1 FROM menu_item_changes ch WHERE ch.snapshot_id = s.id)
+                             THEN 1 ELSE 0 END), 0)::int AS changed
+    FROM menu_snapshots s
+    WHERE s.business_id = r.id
+      AND s.scraped_at > NOW() - interval '180 days'
+      AND EXISTS (SELECT 1 FROM menu_snapshots p
+                  WHERE p.business_id = s.business_id AND p.source_url = s.source_url
+                    AND p.scraped_at < s.scraped_at)
+) h
+WHERE r.status = ANY($1)
+  AND cardinality(r.menu_urls) > 0
+  AND COALESCE(r.scraped_at, GREATEST(r.scrape_failed_at, r.refresh_queued_at), r.created_at) < $2
+ORDER BY COALESCE(r.scraped_at, GREATEST(r.scrape_failed_at, r.refresh_queued_at), r.created_at)
+LIMIT $3;
@@ -0,0 +1,23 @@
+-- Restaurants that may be the same place as an incoming directory record:
+-- same phone, nearby coordinates, or same name in the same zip code.
+-- directory.Resolve makes the final decision.
+SELECT
+    id, camis, yelp_id, dba, boro, building, street, zipcode, phone, address, cuisine, latitude, longitude, nta,
+    status, website_url, menu_urls, url_source, extraction_tier, item_count, scraped_at, last_error, created_at, updated_at
+FROM restaurants
+WHERE ($1 <> '' AND right(regexp_replace(COALESCE(phone, ''), '\D', '', 'g'), 10) = $1)
+   OR ($2::double precision IS NOT NULL
+       AND latitude BETWEEN $2 - $4 AND $2 + $4
+       AND longitude BETWEEN $3::double precision - $5 AND $3::double precision + $5)
+   OR ($6 <> '' AND zipcode = $6 AND lower(dba) = lower($7))
+-- The likeliest matches first, so a dense area's real match is not cut by
+-- the limit: same phone, then the same name ignoring case and punctuation,
+-- then nearest.
+ORDER BY
+    ($1 <> '' AND right(regexp_replace(COALESCE(phone, ''), '\D', '', 'g'), 10) = $1) DESC,
+    (regexp_replace(lower(COALESCE(dba, '')), '[^a-z0-9]', '', 'g') = regexp_replace(lower($7), '[^a-z0-9]', '', 'g')) DESC,
+    CASE WHEN $2::double precision IS NULL OR latitude IS NULL OR longitude IS NULL THEN NULL
+         ELSE power(latitude - $2, 2) + power((longitude - $3::double precision) * cos(radians($2)), 2)
+    END NULLS LAST,
+    id
+LIMIT 50;
@@ -0,0 +1,12 @@
+-- Candidate restaurants for an area: any attribute match or coordinates in
+-- the area's bounding box. Callers apply exact membership (NTA/zip pairs,
+-- polygons) with geo.Matcher.
+SELECT
+    id, camis, yelp_id, dba, boro, building, street, zipcode, phone, address, cuisine, latitude, longitude, nta,
+    status, website_url, menu_urls, url_source, extraction_tier, item_count, scraped_at, last_error, created_at, updated_at
+FROM restaurants
+WHERE nta = ANY($1::text[])
+   OR zipcode = ANY($2::text[])
+   OR lower(boro) = ANY($3::text[])
+   OR ($4::boolean AND latitude BETWEEN $5 AND $6 AND longitude BETWEEN $7 AND $8)
+ORDER BY dba;
@@ -0,0 +1,16 @@
+-- Restaurants near ($1, $2), nearest first. A positive $3 limits them to
+-- that many metres (earth_box uses the GiST index, earth_distance makes the
+-- exact cut); $4 enables the [$5..$6] x [$7..$8] latitude/longitude box; a
+-- non-empty $9 filters by status.
+SELECT
+    id, camis, yelp_id, dba, boro, building, street, zipcode, phone, address, cuisine, latitude, longitude, nta,
+    status, website_url, menu_urls, url_source, extraction_tier, item_count, scraped_at, last_error, created_at, updated_at
+FROM restaurants
+WHERE latitude IS NOT NULL AND longitude IS NOT NULL
+  AND ($3::float8 <= 0 OR (
+        earth_box(ll_to_earth($1, $2), $3) @> ll_to_earth(latitude, longitude)
+    AND earth_distance(ll_to_earth($1, $2), ll_to_earth(latitude, longitude)) <= $3))
+  AND (NOT $4::boolean OR (latitude BETWEEN $5 AND $6 AND longitude BETWEEN $7 AND $8))
+  AND ($9::text = '' OR status = $9)
+ORDER BY ll_to_earth(latitude, longitude) <-> ll_to_earth($1, $2)
+LIMIT $10;
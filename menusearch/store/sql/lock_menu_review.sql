@@ -0,0 +1,5 @@
+SELECT business_id, review_status, applied_at IS NOT NULL, result
+FROM extraction_quality
+WHERE id = $1
+  AND review_status IS NOT NULL
+FOR UPDATE;
@@ -0,0 +1,14 @@
+-- Marks an approved review applied and supersedes any other review still
+-- pending for the same menu URL: the approved items now stand in for them.
+WITH applied AS (
+    UPDATE extraction_quality
+    SET applied_at = NOW()
+    WHERE id = $1
+    RETURNING business_id, source_url
+)
+UPDATE extraction_quality q
+SET review_status = 'superseded'
+FROM applied a
+WHERE q.business_id = a.business_id
+  AND q.source_url = a.source_url
+  AND q.review_status = 'pending';
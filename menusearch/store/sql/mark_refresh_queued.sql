@@ -0,0 +1,4 @@
+UPDATE restaurants
+SET refresh_queued_at = NOW(),
+    refresh_attempts = refresh_attempts + 1
+WHERE id = $1;
@@ -0,0 +1,19 @@
+-- Merge a directory record into an existing restaurant. When $2 is true the
+-- record's own source owns the row (matched by external ID) and its
+-- non-null fields win; otherwise the row was matched by entity resolution
+-- and the record only fills fields the row lacks.
+UPDATE restaurants SET
+    camis     = COALESCE(camis, $3),
+    yelp_id   = COALESCE(yelp_id, $4),
+    dba       = CASE WHEN $2 THEN $5 ELSE dba END,
+    boro      = CASE WHEN $2 THEN COALESCE($6, boro) ELSE COALESCE(boro, $6) END,
+    building  = CASE WHEN $2 THEN COALESCE($7, building) ELSE COALESCE(building, $7) END,
+    street    = CASE WHEN $2 THEN COALESCE($8, street) ELSE COALESCE(street, $8) END,
+    zipcode   = CASE WHEN $2 THEN COALESCE($9, zipcode) ELSE COALESCE(zipcode, $9) END,
+    phone     = CASE WHEN $2 THEN COALESCE($10, phone) ELSE COALESCE(phone, $10) END,
+    address   = COALESCE(address, $11),
+    cuisine   = CASE WHEN $2 THEN COALESCE($12, cuisine) ELSE COALESCE(cuisine, $12) END,
+    latitude  = CASE WHEN $2 THEN COALESCE($13, latitude) ELSE COALESCE(latitude, $13) END,
+    longitude = CASE WHEN $2 THEN COALESCE($14, longitude) ELSE COALESCE(longitude, $14) END,
+    nta       = CASE WHEN $2 THEN COALESCE($15, nta) ELSE COALESCE(nta, $15) END
+WHERE id = $1;
@@ -0,0 +1,13 @@
+-- A successful scrape of menu URL $2 that stored $3 items. The URL's count
+-- replaces any earlier one and item_count is the sum over URLs, so refreshing
+-- a menu does not inflate it.
+UPDATE restaurants r
+SET status = 'scraped',
+    url_item_counts = c.counts,
+    item_count = (SELECT COALESCE(sum(value::int), 0) FROM jsonb_each_text(c.counts))::int,
+    last_error = NULL,
+    scraped_at = NOW(),
+    refresh_attempts = 0
+FROM (SELECT id, url_item_counts || jsonb_build_object($2::text, $3::int) AS counts
+      FROM restaurants WHERE id = $1) c
+WHERE r.id = c.id;
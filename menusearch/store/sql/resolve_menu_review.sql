@@ -0,0 +1,7 @@
+UPDATE extraction_quality
+SET review_status = $2,
+    corrected = $3,
+    reviewed_by = NULLIF($4, ''),
+    reviewed_at = NOW(),
+    review_note = NULLIF($5, '')
+WHERE id = $1;
@@ -0,0 +1,7 @@
+-- Scrape jobs inserted since $1, whatever queued them (discovery, admin
+-- retries, the refresh policy). %s is the sanitized, schema-qualified
+-- river_job identifier, as in job_counts.sql.
+SELECT count(*)::int
+FROM %s
+WHERE kind = 'menusearch.scrape_menu'
+  AND created_at >= $1;
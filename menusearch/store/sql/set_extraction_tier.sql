@@ -1,3 +1,3 @@
 UPDATE restaurants
 SET extraction_tier = NULLIF($2, '')
-WHERE camis = $1;
+WHERE id = $1;
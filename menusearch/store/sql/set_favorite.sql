@@ -0,0 +1,3 @@
+INSERT INTO restaurant_favorites (user_id, business_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
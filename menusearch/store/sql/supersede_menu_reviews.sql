@@ -0,0 +1,5 @@
+UPDATE extraction_quality
+SET review_status = 'superseded'
+WHERE business_id = $1
+  AND source_url = $2
+  AND review_status = 'pending';
@@ -8,4 +8,4 @@ SET website_url = NULLIF($2::text, ''),
         WHEN NULLIF($2::text, '') IS NOT NULL OR array_length($3::text[], 1) > 0 THEN 'url_found' 
         ELSE 'failed_permanently' 
     END
-WHERE camis = $1;
+WHERE id = $1;
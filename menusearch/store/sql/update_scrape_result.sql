@@ -1,11 +1,16 @@
 UPDATE restaurants
 SET status = $2,
-    item_count = CASE WHEN $2 = 'scraped' AND status IN ('scraped', 'scraping') THEN item_count + $3 ELSE $3 END,
+    -- A 'scraped' write without a menu URL (an unchanged page) keeps the
+    -- per-URL counts; RecordScrapedMenu is what sets them.
+    item_count = CASE WHEN $2 = 'scraped' AND status IN ('scraped', 'scraping') THEN item_count ELSE $3 END,
+    url_item_counts = CASE WHEN $2 = 'scraped' AND status IN ('scraped', 'scraping') THEN url_item_counts ELSE '{}'::jsonb END,
     last_error = NULLIF($4, ''),
-    scraped_at = CASE WHEN $2 = 'scraped' THEN NOW() ELSE scraped_at END
-WHERE camis = $1
+    scraped_at = CASE WHEN $2 = 'scraped' THEN NOW() ELSE scraped_at END,
+    scrape_failed_at = CASE WHEN $2 IN ('failed_scrape', 'failed_permanently') THEN NOW() ELSE scrape_failed_at END,
+    -- A successful scrape ends any refresh backoff.
+    refresh_attempts = CASE WHEN $2 = 'scraped' THEN 0 ELSE refresh_attempts END
+WHERE id = $1
   -- Once a restaurant is successfully scraped, only another 'scraped' write may
   -- change it. This blocks BOTH a sibling menu-URL job's opening 'scraping'
-  -- reset (which would zero item_count) and a later 'failed_scrape' clobber,
-  -- while still allowing a re-scrape with more items to overwrite.
+  -- reset (which would zero item_count) and a later 'failed_scrape' clobber.
   AND NOT (status = 'scraped' AND $2 <> 'scraped');
@@ -0,0 +1,3 @@
+INSERT INTO geo_areas (name, definition)
+VALUES ($1, $2)
+ON CONFLICT (name) DO UPDATE SET definition = EXCLUDED.definition;
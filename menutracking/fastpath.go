@@ -32,46 +32,39 @@ func ApplyRule(ctx context.Context, pool *pgxpool.Pool, domain, pageContent stri
 		return &FastPathResult{}, nil
 	}
 
+	return applyRuleToContent(rule, pageContent), nil
+}
+
+// ApplyRuleWithSelector applies a specific ExtractionRule to page content.
+// Used by RulePromotionWorker to verify a proposed rule against live content.
+func ApplyRuleWithSelector(rule *ExtractionRule, pageContent string) (*FastPathResult, error) {
+	return applyRuleToContent(rule, pageContent), nil
+}
+
+// applyRuleToContent runs rule's selector over pageContent and parses the
+// extracted text as a StructuredUpdate. Extracted is nil when the selector
+// misses, the text is not valid JSON, or required fields are empty.
+func applyRuleToContent(rule *ExtractionRule, pageContent string) *FastPathResult {
 	// The selector field currently represents a simple string-matching
 	// heuristic (e.g. a CSS selector or JSON path). Phase 1 uses a basic
 	// substring/contains match for government API responses that are already
 	// structured. A full CSS/JSON path engine can be layered in later.
 	extracted := applySelector(pageContent, rule.Selector)
 	if extracted == "" {
-		return &FastPathResult{}, nil
+		return &FastPathResult{}
 	}
 
 	var update StructuredUpdate
 	if err := json.Unmarshal([]byte(extracted), &update); err != nil {
-		return &FastPathResult{Raw: extracted}, nil
+		return &FastPathResult{Raw: extracted}
 	}
 
 	// Basic validation: substance name and change type must be non-empty.
 	if update.SubstanceName == "" || update.ChangeType == "" {
-		return &FastPathResult{Raw: extracted}, nil
-	}
-
-	return &FastPathResult{Extracted: &update, Raw: extracted}, nil
-}
-
-// ApplyRuleWithSelector applies a specific ExtractionRule to page content.
-// Used by RulePromotionWorker to verify a proposed rule against live content.
-func ApplyRuleWithSelector(ctx context.Context, pool *pgxpool.Pool, rule *ExtractionRule, pageContent string) (*FastPathResult, error) {
-	extracted := applySelector(pageContent, rule.Selector)
-	if extracted == "" {
-		return &FastPathResult{}, nil
-	}
-
-	var update StructuredUpdate
-	if err := json.Unmarshal([]byte(extracted), &update); err != nil {
-		return &FastPathResult{Raw: extracted}, nil
-	}
-
-	if update.SubstanceName == "" || update.ChangeType == "" {
-		return &FastPathResult{Raw: extracted}, nil
+		return &FastPathResult{Raw: extracted}
 	}
 
-	return &FastPathResult{Extracted: &update, Raw: extracted}, nil
+	return &FastPathResult{Extracted: &update, Raw: extracted}
 }
 
 // applySelector extracts content from pageContent using the given selector.
@@ -171,7 +171,7 @@ func TestApplyRuleWithSelector_ValidRule(t *testing.T) {
 	}
 	content := `{"update": {"cas_number": "50-00-0", "substance_name": "formaldehyde", "change_type": "addition", "description": "test", "source_url": "https://example.com"}}`
 
-	result, err := ApplyRuleWithSelector(context.Background(), nil, rule, content)
+	result, err := ApplyRuleWithSelector(rule, content)
 	if err != nil {
 		t.Fatalf("ApplyRuleWithSelector: %v", err)
 	}
@@ -192,7 +192,7 @@ func TestApplyRuleWithSelector_EmptyOutput(t *testing.T) {
 	}
 	content := `{"other_key": 123}`
 
-	result, err := ApplyRuleWithSelector(context.Background(), nil, rule, content)
+	result, err := ApplyRuleWithSelector(rule, content)
 	if err != nil {
 		t.Fatalf("ApplyRuleWithSelector: %v", err)
 	}
@@ -213,7 +213,7 @@ func TestApplyRuleWithSelector_CSSSelector(t *testing.T) {
 		Status:   RuleStatusProposed,
 	}
 	// CSS selectors are not yet supported — should return empty, triggering the agent path.
-	result, err := ApplyRuleWithSelector(context.Background(), nil, rule, "<div>content</div>")
+	result, err := ApplyRuleWithSelector(rule, "<div>content</div>")
 	if err != nil {
 		t.Fatalf("ApplyRuleWithSelector: %v", err)
 	}
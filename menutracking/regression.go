@@ -0,0 +1,161 @@
+package menutracking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"fodmap/scraper"
+)
+
+// DefaultMinPassRate is the fraction of bronze snapshots a proposed rule must
+// extract from before RulePromotionWorker will promote it.
+const DefaultMinPassRate = 0.8
+
+// ErrBelowPassRate is returned by RuleTestReport.Check when the rule
+// extracted from fewer snapshots than the required pass rate.
+var ErrBelowPassRate = errors.New("rule below required pass rate")
+
+// SnapshotOutcome classifies the result of replaying a rule against one
+// bronze snapshot.
+type SnapshotOutcome string
+
+// SnapshotOutcome values.
+const (
+	// SnapshotPass means the rule produced a valid StructuredUpdate that
+	// matches the baseline rule's output (or there is no baseline).
+	SnapshotPass SnapshotOutcome = "pass"
+	// SnapshotFail means the rule produced no valid StructuredUpdate.
+	SnapshotFail SnapshotOutcome = "fail"
+	// SnapshotChanged means the rule produced a valid StructuredUpdate that
+	// differs from the baseline rule's output on the same snapshot.
+	SnapshotChanged SnapshotOutcome = "changed"
+)
+
+// SnapshotResult is the outcome of replaying a rule against a single bronze
+// snapshot.
+type SnapshotResult struct {
+	Path     string          `json:"path"`
+	Outcome  SnapshotOutcome `json:"outcome"`
+	Output   string          `json:"output,omitempty"`   // raw text the rule extracted
+	Baseline string          `json:"baseline,omitempty"` // raw text the baseline rule extracted
+}
+
+// RuleTestReport summarises a replay of one rule against every bronze
+// snapshot stored for its domain.
+type RuleTestReport struct {
+	RuleID   string           `json:"rule_id"`
+	Domain   string           `json:"domain"`
+	Results  []SnapshotResult `json:"results"`
+	Passed   int              `json:"passed"`
+	Failed   int              `json:"failed"`
+	Changed  int              `json:"changed"`
+	Baseline string           `json:"baseline_rule_id,omitempty"`
+}
+
+// Total returns the number of snapshots replayed.
+func (r *RuleTestReport) Total() int { return len(r.Results) }
+
+// PassRate returns the fraction of snapshots from which the rule produced a
+// valid update. Changed snapshots count as passing — the rule still extracted
+// something — and are reported separately so reviewers can inspect the
+// divergence. An empty report has a pass rate of 1.
+func (r *RuleTestReport) PassRate() float64 {
+	if len(r.Results) == 0 {
+		return 1
+	}
+	return float64(r.Passed+r.Changed) / float64(len(r.Results))
+}
+
+// Check returns an error wrapping ErrBelowPassRate if the report's pass rate
+// is below minPassRate.
+func (r *RuleTestReport) Check(minPassRate float64) error {
+	if rate := r.PassRate(); rate < minPassRate {
+		return fmt.Errorf("%w: rule %s passed %.2f of %d snapshots, need %.2f", ErrBelowPassRate, r.RuleID, rate, r.Total(), minPassRate)
+	}
+	return nil
+}
+
+// BronzeSnapshots returns the paths of every bronze snapshot stored for the
+// domain under bronzeDir, sorted oldest scrape date first. ScrapeWorker writes
+// snapshots to <bronzeDir>/<domain>/<YYYY-MM-DD>/<source_id>.html. A missing
+// domain directory yields no snapshots and no error.
+func BronzeSnapshots(bronzeDir, domain string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(bronzeDir, domain, "*", "*"))
+	if err != nil {
+		return nil, fmt.Errorf("listing bronze snapshots for %s: %w", domain, err)
+	}
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("stat bronze snapshot: %w", err)
+		}
+		if info.Mode().IsRegular() {
+			files = append(files, p)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// ReplayRule applies rule to every bronze snapshot stored for its domain
+// under bronzeDir and reports a per-snapshot outcome. If baseline is non-nil
+// (typically the domain's active rule), its output on each snapshot is used
+// to detect changed extractions; a snapshot the baseline cannot extract from
+// is judged on the candidate alone.
+//
+// Snapshots are read through the same trafilatura cleanup the ScrapeWorker
+// applies before the fast path, so the replay sees what the live rule would.
+func ReplayRule(ctx context.Context, rule, baseline *ExtractionRule, bronzeDir string) (*RuleTestReport, error) {
+	paths, err := BronzeSnapshots(bronzeDir, rule.Domain)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RuleTestReport{RuleID: rule.ID, Domain: rule.Domain}
+	if baseline != nil {
+		report.Baseline = baseline.ID
+	}
+	for _, p := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		raw, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("reading bronze snapshot: %w", err)
+		}
+		pageContent := scraper.TrafilaturaFallback(string(raw))
+
+		res := SnapshotResult{Path: p}
+		candidate := applyRuleToContent(rule, pageContent)
+		res.Output = candidate.Raw
+		switch {
+		case candidate.Extracted == nil:
+			res.Outcome = SnapshotFail
+		case baseline != nil:
+			base := applyRuleToContent(baseline, pageContent)
+			res.Baseline = base.Raw
+			res.Outcome = SnapshotPass
+			if base.Extracted != nil && *base.Extracted != *candidate.Extracted {
+				res.Outcome = SnapshotChanged
+			}
+		default:
+			res.Outcome = SnapshotPass
+		}
+
+		switch res.Outcome {
+		case SnapshotPass:
+			report.Passed++
+		case SnapshotFail:
+			report.Failed++
+		case SnapshotChanged:
+			report.Changed++
+		}
+		report.Results = append(report.Results, res)
+	}
+	return report, nil
+}
@@ -0,0 +1,133 @@
+package menutracking
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeSnapshot stores body as a bronze snapshot in the layout ScrapeWorker
+// uses and returns its path.
+func writeSnapshot(t *testing.T, bronzeDir, domain, date, name, body string) string {
+	t.Helper()
+	path := filepath.Join(bronzeDir, domain, date, name)
+	if err := writeBronzeFile(path, []byte(body)); err != nil {
+		t.Fatalf("writing snapshot: %v", err)
+	}
+	return path
+}
+
+const (
+	snapshotFormaldehyde = `{"update": {"substance_name": "formaldehyde", "change_type": "addition"}, "legacy": {"substance_name": "formaldehyde", "change_type": "addition"}}`
+	snapshotRenamed      = `{"item": {"substance_name": "formaldehyde", "change_type": "addition"}, "legacy": {"substance_name": "formaldehyde", "change_type": "addition"}}`
+	snapshotRevised      = `{"update": {"substance_name": "formaldehyde", "change_type": "restriction"}, "legacy": {"substance_name": "formaldehyde", "change_type": "addition"}}`
+)
+
+func TestBronzeSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	later := writeSnapshot(t, dir, "gov.example", "2026-02-01", "src.html", "b")
+	earlier := writeSnapshot(t, dir, "gov.example", "2026-01-01", "src.html", "a")
+	writeSnapshot(t, dir, "other.example", "2026-01-01", "src.html", "c")
+
+	got, err := BronzeSnapshots(dir, "gov.example")
+	if err != nil {
+		t.Fatalf("BronzeSnapshots: %v", err)
+	}
+	if len(got) != 2 || got[0] != earlier || got[1] != later {
+		t.Errorf("BronzeSnapshots = %v, want [%s %s]", got, earlier, later)
+	}
+}
+
+func TestBronzeSnapshots_MissingDomain(t *testing.T) {
+	got, err := BronzeSnapshots(t.TempDir(), "none.example")
+	if err != nil {
+		t.Fatalf("BronzeSnapshots: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected no snapshots, got %v", got)
+	}
+}
+
+func TestReplayRule_NoBaseline(t *testing.T) {
+	dir := t.TempDir()
+	writeSnapshot(t, dir, "gov.example", "2026-01-01", "src.html", snapshotFormaldehyde)
+	writeSnapshot(t, dir, "gov.example", "2026-01-08", "src.html", snapshotRenamed)
+
+	rule := &ExtractionRule{ID: "r1", Domain: "gov.example", Selector: "json:update"}
+	report, err := ReplayRule(context.Background(), rule, nil, dir)
+	if err != nil {
+		t.Fatalf("ReplayRule: %v", err)
+	}
+	if report.Total() != 2 || report.Passed != 1 || report.Failed != 1 {
+		t.Fatalf("report = %+v, want 1 passed and 1 failed", report)
+	}
+	if report.Results[0].Outcome != SnapshotPass || report.Results[1].Outcome != SnapshotFail {
+		t.Errorf("outcomes = %s, %s; want pass, fail", report.Results[0].Outcome, report.Results[1].Outcome)
+	}
+	if got := report.PassRate(); got != 0.5 {
+		t.Errorf("PassRate = %v, want 0.5", got)
+	}
+}
+
+func TestReplayRule_ChangedAgainstBaseline(t *testing.T) {
+	dir := t.TempDir()
+	writeSnapshot(t, dir, "gov.example", "2026-01-01", "src.html", snapshotFormaldehyde)
+	writeSnapshot(t, dir, "gov.example", "2026-01-08", "src.html", snapshotRevised)
+
+	candidate := &ExtractionRule{ID: "cand", Domain: "gov.example", Selector: "json:update"}
+	baseline := &ExtractionRule{ID: "active", Domain: "gov.example", Selector: "json:legacy"}
+	report, err := ReplayRule(context.Background(), candidate, baseline, dir)
+	if err != nil {
+		t.Fatalf("ReplayRule: %v", err)
+	}
+	if report.Baseline != "active" {
+		t.Errorf("Baseline = %q, want active", report.Baseline)
+	}
+	if report.Passed != 1 || report.Changed != 1 || report.Failed != 0 {
+		t.Fatalf("report = %+v, want 1 passed and 1 changed", report)
+	}
+	changed := report.Results[1]
+	if changed.Outcome != SnapshotChanged || changed.Baseline == "" || changed.Output == changed.Baseline {
+		t.Errorf("changed result = %+v", changed)
+	}
+	if got := report.PassRate(); got != 1 {
+		t.Errorf("PassRate = %v, want 1 (changed counts as passing)", got)
+	}
+}
+
+func TestRuleTestReport_Check(t *testing.T) {
+	report := &RuleTestReport{
+		RuleID:  "r1",
+		Results: make([]SnapshotResult, 4),
+		Passed:  2,
+		Changed: 1,
+		Failed:  1,
+	}
+	if err := report.Check(0.75); err != nil {
+		t.Errorf("Check(0.75) = %v, want nil", err)
+	}
+	if err := report.Check(DefaultMinPassRate); !errors.Is(err, ErrBelowPassRate) {
+		t.Errorf("Check(%v) = %v, want ErrBelowPassRate", DefaultMinPassRate, err)
+	}
+	empty := &RuleTestReport{}
+	if err := empty.Check(1); err != nil {
+		t.Errorf("empty report Check(1) = %v, want nil", err)
+	}
+}
+
+func TestReplayRule_UnreadableSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	path := writeSnapshot(t, dir, "gov.example", "2026-01-01", "src.html", snapshotFormaldehyde)
+	if err := os.Chmod(path, 0); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	if _, err := os.ReadFile(path); err == nil {
+		t.Skip("running with permissions that bypass file mode checks")
+	}
+	rule := &ExtractionRule{ID: "r1", Domain: "gov.example", Selector: "json:update"}
+	if _, err := ReplayRule(context.Background(), rule, nil, dir); err == nil {
+		t.Fatal("expected error for unreadable snapshot, got nil")
+	}
+}
@@ -88,6 +88,21 @@ func ProposedRule(ctx context.Context, pool *pgxpool.Pool, ruleID string) (*Extr
 	return nil, fmt.Errorf("getting proposed rule %s: %w", ruleID, err)
 }
 
+// RuleByID returns an extraction rule by its ID in any status, or (nil, nil)
+// if no rule exists with that ID.
+func RuleByID(ctx context.Context, pool *pgxpool.Pool, ruleID string) (*ExtractionRule, error) {
+	var r ExtractionRule
+	err := pool.QueryRow(ctx, store.RuleSQL, ruleID).
+		Scan(&r.ID, &r.Domain, &r.Selector, &r.Fields, &r.Status, &r.Provenance, &r.ProposedAt, &r.ActivatedAt, &r.CreatedAt)
+	if err == nil {
+		return &r, nil
+	}
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("getting rule %s: %w", ruleID, err)
+}
+
 // PromoteRule marks a proposed rule as active after verification.
 func PromoteRule(ctx context.Context, pool *pgxpool.Pool, ruleID string) error {
 	now := time.Now()
@@ -74,6 +74,11 @@ var UpsertRegulatoryUpdateSQL string
 //go:embed sql/get_proposed_rule.sql
 var ProposedRuleSQL string
 
+// RuleSQL retrieves an extraction rule by its ID in any status.
+//
+//go:embed sql/get_rule.sql
+var RuleSQL string
+
 // listDiscardedJobsSQLRaw is the templated SQL for listing discarded river
 // jobs. Render via RenderListDiscardedJobsSQL() to inject the River schema.
 //
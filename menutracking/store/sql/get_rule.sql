@@ -0,0 +1,5 @@
+-- name: get-rule
+-- Get an extraction rule by its ID, regardless of status.
+SELECT id, domain, selector, fields, status, provenance, proposed_at, activated_at, created_at
+FROM extraction_rules
+WHERE id = $1;
\ No newline at end of file
@@ -71,26 +71,22 @@ var BronzeDir = "data/bronze"
 type ScrapeWorker struct {
 	river.WorkerDefaults[ScrapeJobArgs]
 
-	Pool         *pgxpool.Pool
-	Fetcher      scraper.Fetcher
-	RateLimiters *DomainLimiterMap
-	AgentConfig  AgentPathConfig
-	RiverClient  RiverInserter
-	VectorSink   VectorSink
-	ChatBackend  chat.ChatBackend
+	Pool        *pgxpool.Pool
+	Fetcher     scraper.Fetcher // applies the shared crawl policy (robots, Crawl-delay, budgets)
+	AgentConfig AgentPathConfig
+	RiverClient RiverInserter
+	VectorSink  VectorSink
+	ChatBackend chat.ChatBackend
 }
 
 func (w *ScrapeWorker) Work(ctx context.Context, job *river.Job[ScrapeJobArgs]) error {
 	args := job.Args
 
-	// Step 4: Per-domain rate limiting before any HTTP call.
-	if err := w.RateLimiters.Wait(ctx, args.Domain); err != nil {
-		return fmt.Errorf("rate limiter cancelled: %w", err)
-	}
-
 	slog.Info("menutracking scrape", "source_id", args.SourceID, "url", args.URL, "attempt", job.Attempt)
 
-	// Fetch the page first — both fast path and agent path need it.
+	// Fetch the page first — both fast path and agent path need it. The
+	// fetcher's crawl policy rate-limits per host, so the wait is shared with
+	// menusearch scrapes of the same host.
 	fetchResult, err := w.Fetcher.Fetch(ctx, args.URL)
 	if err != nil {
 		return fmt.Errorf("fetching %s: %w", args.URL, err)
@@ -197,13 +193,17 @@ func (w *ScrapeWorker) Work(ctx context.Context, job *river.Job[ScrapeJobArgs])
 }
 
 // RulePromotionWorker verifies a proposed extraction rule by re-running it
-// against the same content the agent used. If the output matches the agent's
-// extract, the rule is promoted to active; otherwise it is rejected.
+// against the same content the agent used, then replaying it against every
+// bronze snapshot stored for the domain. The rule is promoted to active only
+// if it extracts from the live page and from at least MinPassRate of the
+// snapshots; otherwise it is rejected.
 type RulePromotionWorker struct {
 	river.WorkerDefaults[RulePromotionJobArgs]
 
-	Pool    *pgxpool.Pool
-	Fetcher scraper.Fetcher
+	Pool        *pgxpool.Pool
+	Fetcher     scraper.Fetcher
+	BronzeDir   string   // snapshot root; empty uses the package-level BronzeDir
+	MinPassRate *float64 // required snapshot pass rate; nil uses DefaultMinPassRate, 0 turns the gate off
 }
 
 func (w *RulePromotionWorker) Work(ctx context.Context, job *river.Job[RulePromotionJobArgs]) error {
@@ -234,7 +234,7 @@ func (w *RulePromotionWorker) Work(ctx context.Context, job *river.Job[RulePromo
 	pageContent := scraper.TrafilaturaFallback(string(rawBytes))
 
 	// Apply the proposed rule and check if it produces valid output.
-	result, err := ApplyRuleWithSelector(ctx, w.Pool, rule, pageContent)
+	result, err := ApplyRuleWithSelector(rule, pageContent)
 	if err != nil {
 		slog.Warn("menutracking: proposed rule failed verification, rejecting", "rule_id", args.RuleID, "err", err)
 		if rejectErr := RejectRule(ctx, w.Pool, args.RuleID); rejectErr != nil {
@@ -250,6 +250,21 @@ func (w *RulePromotionWorker) Work(ctx context.Context, job *river.Job[RulePromo
 		return nil
 	}
 
+	// Regression check: replay the rule against historical bronze snapshots
+	// so a rule that only fits today's page is not promoted.
+	report, err := w.replay(ctx, rule)
+	if err != nil {
+		return fmt.Errorf("replaying rule %s against bronze snapshots: %w", args.RuleID, err)
+	}
+	if err := report.Check(w.minPassRate()); err != nil {
+		slog.Warn("menutracking: proposed rule failed regression check, rejecting", "rule_id", args.RuleID,
+			"snapshots", report.Total(), "passed", report.Passed, "changed", report.Changed, "failed", report.Failed, "err", err)
+		if rejectErr := RejectRule(ctx, w.Pool, args.RuleID); rejectErr != nil {
+			slog.Warn("menutracking: failed to reject rule", "rule_id", args.RuleID, "err", rejectErr)
+		}
+		return nil
+	}
+
 	// Rule verification passed — promote to active.
 	if err := PromoteRule(ctx, w.Pool, args.RuleID); err != nil {
 		return fmt.Errorf("promoting rule %s: %w", args.RuleID, err)
@@ -258,6 +273,27 @@ func (w *RulePromotionWorker) Work(ctx context.Context, job *river.Job[RulePromo
 	return nil
 }
 
+// replay runs ReplayRule for rule against the worker's bronze directory,
+// using the domain's current active rule (if any) as the baseline.
+func (w *RulePromotionWorker) replay(ctx context.Context, rule *ExtractionRule) (*RuleTestReport, error) {
+	baseline, err := ActiveRule(ctx, w.Pool, rule.Domain)
+	if err != nil {
+		return nil, err
+	}
+	dir := w.BronzeDir
+	if dir == "" {
+		dir = BronzeDir
+	}
+	return ReplayRule(ctx, rule, baseline, dir)
+}
+
+func (w *RulePromotionWorker) minPassRate() float64 {
+	if w.MinPassRate == nil {
+		return DefaultMinPassRate
+	}
+	return *w.MinPassRate
+}
+
 // writeBronzeFile writes raw content to the bronze layer at the given path.
 // It creates intermediate directories as needed.
 func writeBronzeFile(path string, data []byte) error {
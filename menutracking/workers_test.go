@@ -120,12 +120,11 @@ func TestScrapeWorker_FastPathHit(t *testing.T) {
 	inserter := &stubRiverInserter{}
 
 	w := &ScrapeWorker{
-		Pool:         pool,
-		Fetcher:      fetcher,
-		RateLimiters: NewDomainLimiterMap(1000, 1),
-		AgentConfig:  DefaultAgentPathConfig(),
-		VectorSink:   sink,
-		RiverClient:  inserter,
+		Pool:        pool,
+		Fetcher:     fetcher,
+		AgentConfig: DefaultAgentPathConfig(),
+		VectorSink:  sink,
+		RiverClient: inserter,
 	}
 
 	job := newScrapeJob(ScrapeJobArgs{SourceID: src.ID, URL: "https://gov.example/page", Domain: domain})
@@ -184,13 +183,12 @@ func TestScrapeWorker_AgentPath(t *testing.T) {
 	updateJSON, _ := json.Marshal(update)
 
 	w := &ScrapeWorker{
-		Pool:         pool,
-		Fetcher:      fetcher,
-		RateLimiters: NewDomainLimiterMap(1000, 1),
-		AgentConfig:  DefaultAgentPathConfig(),
-		VectorSink:   sink,
-		RiverClient:  inserter,
-		ChatBackend:  &stubChatBackend{msg: chat.Message{Text: string(updateJSON)}},
+		Pool:        pool,
+		Fetcher:     fetcher,
+		AgentConfig: DefaultAgentPathConfig(),
+		VectorSink:  sink,
+		RiverClient: inserter,
+		ChatBackend: &stubChatBackend{msg: chat.Message{Text: string(updateJSON)}},
 	}
 
 	job := newScrapeJob(ScrapeJobArgs{SourceID: src.ID, URL: "https://agent.example/page", Domain: domain})
@@ -225,11 +223,10 @@ func TestScrapeWorker_NoUpdate(t *testing.T) {
 
 	fetcher := &stubFetcher{body: "{}", ct: "application/json"}
 	w := &ScrapeWorker{
-		Pool:         pool,
-		Fetcher:      fetcher,
-		RateLimiters: NewDomainLimiterMap(1000, 1),
-		AgentConfig:  DefaultAgentPathConfig(),
-		ChatBackend:  &stubChatBackend{msg: chat.Message{Text: "{}"}},
+		Pool:        pool,
+		Fetcher:     fetcher,
+		AgentConfig: DefaultAgentPathConfig(),
+		ChatBackend: &stubChatBackend{msg: chat.Message{Text: "{}"}},
 	}
 
 	job := newScrapeJob(ScrapeJobArgs{SourceID: "src3", URL: "https://empty.example/page", Domain: domain})
@@ -244,9 +241,8 @@ func TestScrapeWorker_FetchError(t *testing.T) {
 	defer pool.Close()
 
 	w := &ScrapeWorker{
-		Pool:         pool,
-		Fetcher:      &stubFetcher{err: errors.New("boom")},
-		RateLimiters: NewDomainLimiterMap(1000, 1),
+		Pool:    pool,
+		Fetcher: &stubFetcher{err: errors.New("boom")},
 	}
 
 	job := newScrapeJob(ScrapeJobArgs{SourceID: "src4", URL: "https://fail.example", Domain: "fail.example"})
@@ -305,3 +301,13 @@ func TestUpsertUpdate(t *testing.T) {
 		t.Errorf("expected 1 row, got %d", count)
 	}
 }
+
+func TestRulePromotionWorker_MinPassRate(t *testing.T) {
+	if got := (&RulePromotionWorker{}).minPassRate(); got != DefaultMinPassRate {
+		t.Errorf("unset min pass rate = %v, want %v", got, DefaultMinPassRate)
+	}
+	off := 0.0
+	if got := (&RulePromotionWorker{MinPassRate: &off}).minPassRate(); got != 0 {
+		t.Errorf("min pass rate 0 = %v, want the gate off", got)
+	}
+}
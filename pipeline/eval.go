@@ -0,0 +1,427 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"fodmap/scraper"
+	"fodmap/textwords"
+)
+
+// TierFailed groups eval cases whose extraction returned an error.
+const TierFailed = "failed"
+
+// evalMatchThreshold is the name similarity at which an extracted dish or
+// ingredient is taken to be the golden one: "Chicken Pad Thai" matches
+// "Pad Thai", "Pad See Ew" does not.
+const evalMatchThreshold = 0.6
+
+// goldenSuffix names the golden result of an eval case.
+const goldenSuffix = ".golden.json"
+
+// evalSources lists, in lookup order, the source file extensions an eval
+// corpus may hold and the content type each is served with.
+var evalSources = []struct{ ext, contentType string }{
+	{".html", "text/html; charset=utf-8"},
+	{".htm", "text/html; charset=utf-8"},
+	{".pdf", "application/pdf"},
+	{".png", "image/png"},
+	{".jpg", "image/jpeg"},
+	{".jpeg", "image/jpeg"},
+	{".webp", "image/webp"},
+}
+
+// EvalCase is one saved source with its hand-checked extraction.
+type EvalCase struct {
+	Name        string
+	URL         string
+	SourcePath  string
+	ContentType string
+	Golden      scraper.MenuExtractionResult
+}
+
+// LoadEvalCases reads an eval corpus: for every <name>.golden.json, a
+// scraper.MenuExtractionResult, the directory must hold the source it was
+// extracted from as <name>.html, .pdf, .png, .jpg, .jpeg or .webp (a bronze
+// file can be copied in as-is). The golden source_url, if set, is the URL the
+// case is extracted as, which matters for platform adapters.
+func LoadEvalCases(dir string) ([]EvalCase, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var cases []EvalCase
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), goldenSuffix) {
+			continue
+		}
+		name := strings.TrimSuffix(e.Name(), goldenSuffix)
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		c := EvalCase{Name: name}
+		if err := json.Unmarshal(data, &c.Golden); err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		for _, src := range evalSources {
+			p := filepath.Join(dir, name+src.ext)
+			if _, err := os.Stat(p); err == nil {
+				c.SourcePath, c.ContentType = p, src.contentType
+				break
+			}
+		}
+		if c.SourcePath == "" {
+			return nil, fmt.Errorf("%s: no source file %s.{html,pdf,png,jpg,jpeg,webp}", e.Name(), name)
+		}
+		c.URL = c.Golden.SourceURL
+		if c.URL == "" {
+			c.URL = "https://eval.invalid/" + url.PathEscape(name)
+		}
+		cases = append(cases, c)
+	}
+	sort.Slice(cases, func(i, j int) bool { return cases[i].Name < cases[j].Name })
+	return cases, nil
+}
+
+// EvalCounts are the match counts behind the eval metrics. Counts add up
+// across cases, so tier and run totals are micro-averages.
+type EvalCounts struct {
+	Cases          int `json:"cases"`
+	GoldenItems    int `json:"golden_items"`
+	ExtractedItems int `json:"extracted_items"`
+	MatchedItems   int `json:"matched_items"`
+	// Ingredient counts cover matched items only.
+	IngredientTP int `json:"ingredient_tp"`
+	IngredientFP int `json:"ingredient_fp"`
+	IngredientFN int `json:"ingredient_fn"`
+	// PricedItems counts matched items with a golden price; PricesCorrect
+	// those extracted with the same price, to the cent.
+	PricedItems   int `json:"priced_items"`
+	PricesCorrect int `json:"prices_correct"`
+}
+
+// Add accumulates o into c.
+func (c *EvalCounts) Add(o EvalCounts) {
+	c.Cases += o.Cases
+	c.GoldenItems += o.GoldenItems
+	c.ExtractedItems += o.ExtractedItems
+	c.MatchedItems += o.MatchedItems
+	c.IngredientTP += o.IngredientTP
+	c.IngredientFP += o.IngredientFP
+	c.IngredientFN += o.IngredientFN
+	c.PricedItems += o.PricedItems
+	c.PricesCorrect += o.PricesCorrect
+}
+
+// Precision is the share of extracted items that match a golden item; NaN
+// when nothing was extracted.
+func (c EvalCounts) Precision() float64 { return ratio(c.MatchedItems, c.ExtractedItems) }
+
+// Recall is the share of golden items extracted; NaN with no golden items.
+func (c EvalCounts) Recall() float64 { return ratio(c.MatchedItems, c.GoldenItems) }
+
+// IngredientF1 scores the stated ingredients of matched items; NaN when
+// neither side lists any.
+func (c EvalCounts) IngredientF1() float64 {
+	return ratio(2*c.IngredientTP, 2*c.IngredientTP+c.IngredientFP+c.IngredientFN)
+}
+
+// PriceAccuracy is the share of matched, golden-priced items extracted with
+// the right price; NaN when no matched item has a golden price.
+func (c EvalCounts) PriceAccuracy() float64 { return ratio(c.PricesCorrect, c.PricedItems) }
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return math.NaN()
+	}
+	return float64(n) / float64(d)
+}
+
+// ScoreExtraction compares an extraction with its golden result. Items are
+// matched one-to-one by dish-name similarity, best pairs first; sections
+// are ignored, since menus are often split differently but correctly.
+func ScoreExtraction(got, golden scraper.MenuExtractionResult) EvalCounts {
+	c := EvalCounts{Cases: 1, GoldenItems: len(golden.Items), ExtractedItems: len(got.Items)}
+	for _, m := range matchNames(dishNames(golden.Items), dishNames(got.Items)) {
+		g, x := golden.Items[m[0]], got.Items[m[1]]
+		c.MatchedItems++
+
+		tp := len(matchNames(g.StatedIngredients, x.StatedIngredients))
+		c.IngredientTP += tp
+		c.IngredientFP += len(x.StatedIngredients) - tp
+		c.IngredientFN += len(g.StatedIngredients) - tp
+
+		if g.Price != nil {
+			c.PricedItems++
+			if x.Price != nil && math.Abs(*g.Price-*x.Price) < 0.005 {
+				c.PricesCorrect++
+			}
+		}
+	}
+	return c
+}
+
+func dishNames(items []scraper.MenuEntry) []string {
+	out := make([]string, len(items))
+	for i, it := range items {
+		out[i] = it.DishName
+	}
+	return out
+}
+
+// matchNames pairs golden with got names one-to-one, most similar first,
+// and returns the [golden, got] index pairs at or above evalMatchThreshold.
+func matchNames(golden, got []string) [][2]int {
+	type pair struct {
+		g, x int
+		sim  float64
+	}
+	var pairs []pair
+	for i, g := range golden {
+		for j, x := range got {
+			if sim := nameSimilarity(g, x); sim >= evalMatchThreshold {
+				pairs = append(pairs, pair{i, j, sim})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(a, b int) bool { return pairs[a].sim > pairs[b].sim })
+
+	usedG := make([]bool, len(golden))
+	usedX := make([]bool, len(got))
+	var out [][2]int
+	for _, p := range pairs {
+		if usedG[p.g] || usedX[p.x] {
+			continue
+		}
+		usedG[p.g], usedX[p.x] = true, true
+		out = append(out, [2]int{p.g, p.x})
+	}
+	return out
+}
+
+// nameSimilarity is the Dice coefficient of two names' content words, a
+// word matching when any plural variant is shared.
+func nameSimilarity(a, b string) float64 {
+	wa, wb := contentWords(a), contentWords(b)
+	if len(wa) == 0 || len(wb) == 0 {
+		if strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b)) {
+			return 1
+		}
+		return 0
+	}
+	used := make([]bool, len(wb))
+	shared := 0
+	for _, x := range wa {
+		vx := wordVariants(x)
+		for j, y := range wb {
+			if used[j] {
+				continue
+			}
+			if slices.ContainsFunc(wordVariants(y), func(v string) bool { return slices.Contains(vx, v) }) {
+				used[j] = true
+				shared++
+				break
+			}
+		}
+	}
+	return 2 * float64(shared) / float64(len(wa)+len(wb))
+}
+
+func contentWords(s string) []string {
+	var out []string
+	for _, w := range textwords.Split(s) {
+		if !groundingStopwords[w] {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// EvalCaseResult is one case of an eval run.
+type EvalCaseResult struct {
+	Name       string     `json:"name"`
+	URL        string     `json:"url"`
+	Tier       string     `json:"tier"`
+	GoldenTier string     `json:"golden_tier,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	Seconds    float64    `json:"seconds"`
+	Counts     EvalCounts `json:"counts"`
+}
+
+// EvalReport is the outcome of one eval run, e.g. one LLM backend over the
+// corpus.
+type EvalReport struct {
+	Label string                `json:"label"`
+	Cases []EvalCaseResult      `json:"cases"`
+	Tiers map[string]EvalCounts `json:"tiers"`
+	Total EvalCounts            `json:"total"`
+}
+
+// EvalOptions configures RunEval; the fields mirror ExtractMenu's flags.
+type EvalOptions struct {
+	Label           string
+	EnableVision    bool
+	UsePdftotext    bool
+	WebagentAdapter string
+}
+
+// RunEval runs ExtractMenu over every case and scores it against its golden
+// result. Sources are served from the corpus; any other URL the cascade
+// fetches fails, so a run touches the network only through ex. A case whose
+// extraction fails is scored with no items under TierFailed.
+func RunEval(ctx context.Context, cases []EvalCase, ex scraper.Extractor, opts EvalOptions) (EvalReport, error) {
+	report := EvalReport{Label: opts.Label, Tiers: make(map[string]EvalCounts)}
+	for _, c := range cases {
+		fetcher, err := newEvalFetcher(c)
+		if err != nil {
+			return report, fmt.Errorf("%s: %w", c.Name, err)
+		}
+		start := time.Now()
+		got, _, err := ExtractMenu(ctx, c.URL, fetcher, ex, opts.EnableVision, opts.UsePdftotext, opts.WebagentAdapter)
+		res := EvalCaseResult{
+			Name:       c.Name,
+			URL:        c.URL,
+			GoldenTier: c.Golden.ExtractionTier,
+			Seconds:    math.Round(time.Since(start).Seconds()*100) / 100,
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+			res.Tier, res.Error = TierFailed, err.Error()
+			got = &scraper.MenuExtractionResult{}
+		} else {
+			res.Tier = got.ExtractionTier
+		}
+		res.Counts = ScoreExtraction(*got, c.Golden)
+
+		t := report.Tiers[res.Tier]
+		t.Add(res.Counts)
+		report.Tiers[res.Tier] = t
+		report.Total.Add(res.Counts)
+		report.Cases = append(report.Cases, res)
+	}
+	return report, nil
+}
+
+// evalFetcher serves one case's source. An image case is served behind a
+// minimal page embedding it, so it reaches the image OCR tier the way a
+// scanned menu on a restaurant site does.
+type evalFetcher map[string]scraper.FetchResult
+
+func newEvalFetcher(c EvalCase) (evalFetcher, error) {
+	body, err := os.ReadFile(c.SourcePath)
+	if err != nil {
+		return nil, err
+	}
+	f := evalFetcher{}
+	if !strings.HasPrefix(c.ContentType, "image/") {
+		f[c.URL] = scraper.FetchResult{Body: io.NopCloser(bytes.NewReader(body)), ContentType: c.ContentType}
+		return f, nil
+	}
+	page, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, err
+	}
+	img := page.ResolveReference(&url.URL{Path: "menu" + filepath.Ext(c.SourcePath)}).String()
+	wrapper := fmt.Sprintf(`<html><body><div id="menu"><img src=%q alt="Menu" width="1200" height="1600"></div></body></html>`, img)
+	f[c.URL] = scraper.FetchResult{Body: io.NopCloser(strings.NewReader(wrapper)), ContentType: "text/html; charset=utf-8"}
+	f[img] = scraper.FetchResult{Body: io.NopCloser(bytes.NewReader(body)), ContentType: c.ContentType}
+	return f, nil
+}
+
+func (f evalFetcher) Fetch(_ context.Context, rawURL string) (scraper.FetchResult, error) {
+	res, ok := f[rawURL]
+	if !ok {
+		return scraper.FetchResult{}, fmt.Errorf("eval: %s is not in the corpus", rawURL)
+	}
+	return res, nil
+}
+
+// WriteEvalMarkdown writes reports as Markdown tables: metrics per tier and
+// per case, one row per report, so runs of different backends over the same
+// corpus read side by side. Metrics without data print as "–".
+func WriteEvalMarkdown(w io.Writer, reports ...EvalReport) error {
+	var b strings.Builder
+	b.WriteString("# Extraction eval\n\n## By tier\n\n")
+	b.WriteString("| Tier | Run | Cases | Golden items | Precision | Recall | Ingredient F1 | Price accuracy |\n")
+	b.WriteString("|---|---|---:|---:|---:|---:|---:|---:|\n")
+	tierSet := map[string]bool{}
+	for _, r := range reports {
+		for t := range r.Tiers {
+			tierSet[t] = true
+		}
+	}
+	tiers := make([]string, 0, len(tierSet))
+	for t := range tierSet {
+		tiers = append(tiers, t)
+	}
+	sort.Strings(tiers)
+	row := func(tier, label string, c EvalCounts) {
+		fmt.Fprintf(&b, "| %s | %s | %d | %d | %s | %s | %s | %s |\n", tier, label, c.Cases, c.GoldenItems,
+			metric(c.Precision()), metric(c.Recall()), metric(c.IngredientF1()), metric(c.PriceAccuracy()))
+	}
+	for _, t := range tiers {
+		for _, r := range reports {
+			if c, ok := r.Tiers[t]; ok {
+				row(t, r.Label, c)
+			}
+		}
+	}
+	for _, r := range reports {
+		row("**all**", r.Label, r.Total)
+	}
+
+	b.WriteString("\n## By case\n\n")
+	b.WriteString("| Case | Run | Tier | Golden | Extracted | Matched | Ingredient F1 | Price accuracy | Seconds | Error |\n")
+	b.WriteString("|---|---|---|---:|---:|---:|---:|---:|---:|---|\n")
+	type runCase struct {
+		label string
+		res   EvalCaseResult
+	}
+	var names []string
+	byName := map[string][]runCase{}
+	for _, r := range reports {
+		for _, c := range r.Cases {
+			if _, ok := byName[c.Name]; !ok {
+				names = append(names, c.Name)
+			}
+			byName[c.Name] = append(byName[c.Name], runCase{r.Label, c})
+		}
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		for _, e := range byName[n] {
+			c := e.res
+			tier := c.Tier
+			if c.GoldenTier != "" && c.GoldenTier != c.Tier {
+				tier += " (golden: " + c.GoldenTier + ")"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %d | %d | %d | %s | %s | %.2f | %s |\n", n, e.label, tier,
+				c.Counts.GoldenItems, c.Counts.ExtractedItems, c.Counts.MatchedItems,
+				metric(c.Counts.IngredientF1()), metric(c.Counts.PriceAccuracy()), c.Seconds,
+				strings.ReplaceAll(c.Error, "|", `\|`))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func metric(v float64) string {
+	if math.IsNaN(v) {
+		return "–"
+	}
+	return fmt.Sprintf("%.3f", v)
+}
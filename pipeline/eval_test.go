@@ -0,0 +1,135 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fodmap/scraper"
+)
+
+func TestScoreExtraction(t *testing.T) {
+	golden := scraper.MenuExtractionResult{Items: []scraper.MenuEntry{
+		{DishName: "Pad Thai", Price: price(14), StatedIngredients: []string{"rice noodles", "egg", "peanuts"}},
+		{DishName: "Pad See Ew", Price: price(13)},
+		{DishName: "Green Curry", Price: price(15)},
+	}}
+	got := scraper.MenuExtractionResult{Items: []scraper.MenuEntry{
+		{DishName: "Chicken Pad Thai", Price: price(14), StatedIngredients: []string{"rice noodle", "egg", "garlic"}},
+		{DishName: "Green Curries", Price: price(16)},
+		{DishName: "Mango Sticky Rice"},
+	}}
+
+	c := ScoreExtraction(got, golden)
+	want := EvalCounts{
+		Cases: 1, GoldenItems: 3, ExtractedItems: 3, MatchedItems: 2,
+		IngredientTP: 2, IngredientFP: 1, IngredientFN: 1,
+		PricedItems: 2, PricesCorrect: 1,
+	}
+	if c != want {
+		t.Fatalf("counts = %+v, want %+v", c, want)
+	}
+	if p, r := c.Precision(), c.Recall(); math.Abs(p-2.0/3) > 1e-9 || math.Abs(r-2.0/3) > 1e-9 {
+		t.Errorf("precision, recall = %v, %v", p, r)
+	}
+	if f1 := c.IngredientF1(); math.Abs(f1-2.0/3) > 1e-9 {
+		t.Errorf("ingredient F1 = %v", f1)
+	}
+	if a := c.PriceAccuracy(); a != 0.5 {
+		t.Errorf("price accuracy = %v", a)
+	}
+	if !math.IsNaN((EvalCounts{}).Precision()) {
+		t.Error("precision with nothing extracted should be NaN")
+	}
+}
+
+// evalExtractor answers text extraction and image OCR with fixed results.
+type evalExtractor struct {
+	text, image scraper.MenuExtractionResult
+}
+
+func (e *evalExtractor) Extract(context.Context, string) (scraper.MenuExtractionResult, error) {
+	return e.text, nil
+}
+
+func (e *evalExtractor) ExtractImage(context.Context, []byte, string) (scraper.MenuExtractionResult, error) {
+	return e.image, nil
+}
+
+func writeEvalCase(t *testing.T, dir, name, ext string, source []byte, golden scraper.MenuExtractionResult) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name+ext), source, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+goldenSuffix), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunEval(t *testing.T) {
+	dir := t.TempDir()
+	page := `<html><body><h1>Thai Spot</h1><p>Pad Thai $14 — rice noodles, egg, peanuts, tamarind.</p>
+<p>Green Curry $15 — coconut milk, basil, bamboo shoots, eggplant.</p></body></html>`
+	writeEvalCase(t, dir, "thai", ".html", []byte(page), scraper.MenuExtractionResult{
+		ExtractionTier: TierHTMLLLM,
+		Items:          []scraper.MenuEntry{{DishName: "Pad Thai", Price: price(14)}, {DishName: "Green Curry", Price: price(15)}},
+	})
+	writeEvalCase(t, dir, "scan", ".png", []byte("\x89PNG\r\n\x1a\nnot really"), scraper.MenuExtractionResult{
+		Items: []scraper.MenuEntry{{DishName: "Tom Yum"}},
+	})
+	writeEvalCase(t, dir, "empty", ".html", []byte("<html></html>"), scraper.MenuExtractionResult{
+		Items: []scraper.MenuEntry{{DishName: "Larb"}},
+	})
+	if err := os.WriteFile(filepath.Join(dir, "orphan.html"), []byte("<html></html>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases, err := LoadEvalCases(dir)
+	if err != nil {
+		t.Fatalf("LoadEvalCases: %v", err)
+	}
+	if len(cases) != 3 || cases[0].Name != "empty" || cases[1].Name != "scan" || cases[2].URL != "https://eval.invalid/thai" {
+		t.Fatalf("cases = %+v", cases)
+	}
+
+	ex := &evalExtractor{
+		text:  scraper.MenuExtractionResult{Items: []scraper.MenuEntry{{DishName: "Pad Thai", Price: price(14)}}},
+		image: scraper.MenuExtractionResult{Items: []scraper.MenuEntry{{DishName: "Tom Yum Soup"}}},
+	}
+	report, err := RunEval(context.Background(), cases, ex, EvalOptions{Label: "stub"})
+	if err != nil {
+		t.Fatalf("RunEval: %v", err)
+	}
+
+	if got := report.Tiers[TierHTMLLLM]; got.MatchedItems != 1 || got.GoldenItems != 2 || got.PricesCorrect != 1 {
+		t.Errorf("html_llm = %+v", got)
+	}
+	if got := report.Tiers[TierImageOCR]; got.MatchedItems != 1 || got.Cases != 1 {
+		t.Errorf("image_ocr = %+v; the image case should reach OCR through the wrapper page", got)
+	}
+	if got := report.Tiers[TierFailed]; got.Cases != 1 || got.GoldenItems != 1 {
+		t.Errorf("failed = %+v", got)
+	}
+	if report.Total.Cases != 3 || report.Total.GoldenItems != 4 || report.Total.MatchedItems != 2 {
+		t.Errorf("total = %+v", report.Total)
+	}
+
+	var md bytes.Buffer
+	if err := WriteEvalMarkdown(&md, report, EvalReport{Label: "baseline", Tiers: map[string]EvalCounts{}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"| html_llm | stub | 1 | 2 | 1.000 | 0.500 | – | 1.000 |", "| **all** | baseline | 0 | 0 | – |", "| thai | stub | html_llm |"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown missing %q:\n%s", want, md.String())
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net/url"
 	"strings"
 	"time"
 
@@ -22,6 +23,7 @@ import (
 // LLM/OCR/browser paths) can be measured. Keep these stable — they are written
 // to the DB and queried in aggregate.
 const (
+	TierPlatform        = "platform"         // Tier 0: ordering/delivery platform state JSON or menu API (pure Go, no LLM)
 	TierJSONLD          = "jsonld"           // Tier 0: schema.org JSON-LD menu (pure Go, no LLM)
 	TierHTMLLLM         = "html_llm"         // Tier 1: HTML→Markdown → LLM structuring
 	TierPDF             = "pdf"              // PDF cascade (text-layer / pdftotext / service / vision)
@@ -38,9 +40,15 @@ const (
 // JavaScript to run this app." is 46).
 const minExtractRunes = 60
 
+// ErrContentUnchanged is returned by ExtractMenuIfChanged when the fetcher
+// reports the page is unchanged since it was last fetched.
+var ErrContentUnchanged = errors.New("menu page unchanged since last fetch")
+
 // fetchWithFallback attempts a normal HTTP fetch and, on a 403 or 429 status
 // error, falls back to the webagent rendered-fetch endpoint if ex implements
-// scraper.HTMLRenderer. Returns (bodyBytes, contentType, error).
+// scraper.HTMLRenderer. Returns (bodyBytes, contentType, notModified, error);
+// notModified echoes FetchResult.NotModified and is always false for the
+// rendered fallback.
 // On a non-403/429 HTTP error (e.g. 404, 5xx) or if ex does not implement
 // HTMLRenderer, the original error is returned immediately.
 func fetchWithFallback(
@@ -48,15 +56,15 @@ func fetchWithFallback(
 	rawURL string,
 	fetcher scraper.Fetcher,
 	ex scraper.Extractor,
-) ([]byte, string, error) {
+) ([]byte, string, bool, error) {
 	fetchRes, err := fetcher.Fetch(ctx, rawURL)
 	if err == nil {
 		bodyBytes, readErr := io.ReadAll(fetchRes.Body)
 		_ = fetchRes.Body.Close()
 		if readErr != nil {
-			return nil, "", fmt.Errorf("reading body: %w", readErr)
+			return nil, "", false, fmt.Errorf("reading body: %w", readErr)
 		}
-		return bodyBytes, fetchRes.ContentType, nil
+		return bodyBytes, fetchRes.ContentType, fetchRes.NotModified, nil
 	}
 
 	// Try the rendered-fetch fallback if available on fetch error (blocks, 404, or connection errors).
@@ -66,22 +74,26 @@ func fetchWithFallback(
 		renderRes, renderErr := renderer.FetchRenderedHTML(ctx, rawURL, scraper.RenderOptions{})
 		if renderErr != nil {
 			// Preserve the render error, but wrap with context.
-			return nil, "", fmt.Errorf("rendered-fetch fallback: %w", renderErr)
+			return nil, "", false, fmt.Errorf("rendered-fetch fallback: %w", renderErr)
 		}
 		bodyBytes, readErr := io.ReadAll(renderRes.Body)
 		_ = renderRes.Body.Close()
 		if readErr != nil {
-			return nil, "", fmt.Errorf("reading rendered body: %w", readErr)
+			return nil, "", false, fmt.Errorf("reading rendered body: %w", readErr)
 		}
-		return bodyBytes, renderRes.ContentType, nil
+		return bodyBytes, renderRes.ContentType, false, nil
 	}
 
-	return nil, "", fmt.Errorf("fetch: %w", err)
+	return nil, "", false, fmt.Errorf("fetch: %w", err)
 }
 
-// ExtractMenu fetches the URL, runs the extraction cascade (JSON-LD → HTML/text → PDF/OCR → image),
-// and returns the structured result and the raw response body.
-// The raw body is nil for webagent JS paths. Callers may write it to the bronze layer.
+// ExtractMenu fetches the URL, runs the extraction cascade (platform adapter →
+// JSON-LD → HTML/text → PDF/OCR → image),
+// and returns the structured result and the body its items were read from:
+// the fetched page, or the rendered page when the cascade had to render it.
+// The body is nil for webagent JS scrapes. Platform menus may come from a
+// rendered page or a menu API instead of the returned page. Callers may write
+// the body to the bronze layer.
 func ExtractMenu(
 	ctx context.Context,
 	rawURL string,
@@ -90,23 +102,81 @@ func ExtractMenu(
 	enableVision bool,
 	usePdftotext bool,
 	webagentAdapter string,
+) (*scraper.MenuExtractionResult, []byte, error) {
+	return tagLanguage(extractMenu(ctx, rawURL, fetcher, ex, enableVision, usePdftotext, webagentAdapter, false))
+}
+
+// RootOnlyTier reports whether menus extracted by tier come from the root
+// page alone, so that page being unchanged means the menu is. Directory
+// fan-out and image OCR read other URLs, platform adapters may call a menu
+// API, and webagent renders content the static page does not carry.
+func RootOnlyTier(tier string) bool {
+	switch tier {
+	case TierJSONLD, TierHTMLLLM, TierPDF:
+		return true
+	}
+	return false
+}
+
+// ExtractMenuIfChanged is ExtractMenu for re-scrapes: when the fetcher reports
+// the page unchanged (see scraper.CachingFetcher) it returns the raw body and
+// ErrContentUnchanged without running any extraction tier, so no LLM call is
+// spent on a menu we already have. Only use it for menus last extracted by a
+// RootOnlyTier; for the others an unchanged root page says nothing.
+func ExtractMenuIfChanged(
+	ctx context.Context,
+	rawURL string,
+	fetcher scraper.Fetcher,
+	ex scraper.Extractor,
+	enableVision bool,
+	usePdftotext bool,
+	webagentAdapter string,
+) (*scraper.MenuExtractionResult, []byte, error) {
+	return tagLanguage(extractMenu(ctx, rawURL, fetcher, ex, enableVision, usePdftotext, webagentAdapter, true))
+}
+
+// extractMenu implements ExtractMenu and ExtractMenuIfChanged.
+func extractMenu(
+	ctx context.Context,
+	rawURL string,
+	fetcher scraper.Fetcher,
+	ex scraper.Extractor,
+	enableVision bool,
+	usePdftotext bool,
+	webagentAdapter string,
+	skipUnchanged bool,
 ) (*scraper.MenuExtractionResult, []byte, error) {
 	slog.Info("scraping URL", "url", rawURL)
 
-	bodyBytes, ct, err := fetchWithFallback(ctx, rawURL, fetcher, ex)
+	bodyBytes, ct, notModified, err := fetchWithFallback(ctx, rawURL, fetcher, ex)
 	if err != nil {
 		return nil, nil, err
 	}
+	if skipUnchanged && notModified {
+		return nil, bodyBytes, ErrContentUnchanged
+	}
 
 	var result scraper.MenuExtractionResult
 	var jsonldMeta scraper.JSONLDMeta
-	var usedJSONLD bool
+	var usedStructured bool
 	var tier string
 
 	if !strings.Contains(ct, "pdf") {
 		items, meta, ok := scraper.ExtractJSONLD(bytes.NewReader(bodyBytes))
 		jsonldMeta = meta
-		if ok {
+		if platformItems, adapter := extractPlatformMenu(ctx, rawURL, bodyBytes, fetcher, ex); len(platformItems) > 0 {
+			slog.Info("Tier 0: platform menu found", "platform", adapter, "items", len(platformItems))
+			result = scraper.MenuExtractionResult{
+				RestaurantName: meta.RestaurantName,
+				City:           meta.City,
+				State:          meta.State,
+				SourceURL:      rawURL,
+				ScrapedAtUTC:   time.Now().UTC().Format(time.RFC3339),
+				Items:          platformItems,
+			}
+			usedStructured = true
+			tier = TierPlatform
+		} else if ok {
 			slog.Info("Tier 0: JSON-LD menu found", "items", len(items))
 			result = scraper.MenuExtractionResult{
 				RestaurantName: meta.RestaurantName,
@@ -116,12 +186,12 @@ func ExtractMenu(
 				ScrapedAtUTC:   time.Now().UTC().Format(time.RFC3339),
 				Items:          items,
 			}
-			usedJSONLD = true
+			usedStructured = true
 			tier = TierJSONLD
 		}
 	}
 
-	if !usedJSONLD {
+	if !usedStructured {
 		var pageText string
 		var pdfResult *scraper.MenuExtractionResult
 		var menuImgCandidates []string
@@ -226,6 +296,9 @@ func ExtractMenu(
 						if jsErr != nil {
 							return nil, bodyBytes, fmt.Errorf("webagent JS scrape: %w", jsErr)
 						}
+						// The webagent's items come from the page it rendered,
+						// not the static shell, so there is no body to return.
+						bodyBytes = nil
 						result = jsResult
 						result.SourceURL = rawURL
 						result.ScrapedAtUTC = time.Now().UTC().Format(time.RFC3339)
@@ -346,6 +419,43 @@ tier1Done:
 	return &result, bodyBytes, nil
 }
 
+// extractPlatformMenu runs the platform adapter registered for rawURL, if
+// any, and returns its items with the adapter's name. When the static page
+// carries no menu state and ex can render pages, the adapter is retried on
+// the rendered HTML, since some platforms only inject their state client-side.
+// Failures are logged and yield no items so the generic cascade still runs.
+func extractPlatformMenu(ctx context.Context, rawURL string, body []byte, fetcher scraper.Fetcher, ex scraper.Extractor) ([]scraper.MenuEntry, string) {
+	adapter := scraper.PlatformAdapterFor(rawURL)
+	if adapter == nil {
+		return nil, ""
+	}
+	pageURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, ""
+	}
+	items, err := adapter.ExtractMenu(ctx, fetcher, pageURL, body)
+	if errors.Is(err, scraper.ErrNoPlatformMenu) {
+		if renderer, ok := ex.(scraper.HTMLRenderer); ok {
+			res, renderErr := renderer.FetchRenderedHTML(ctx, rawURL, scraper.RenderOptions{})
+			if renderErr != nil {
+				slog.Warn("platform adapter: rendered-fetch failed", "platform", adapter.Name(), "url", rawURL, "error", renderErr)
+				return nil, adapter.Name()
+			}
+			rendered, readErr := io.ReadAll(res.Body)
+			_ = res.Body.Close()
+			if readErr != nil {
+				return nil, adapter.Name()
+			}
+			items, err = adapter.ExtractMenu(ctx, fetcher, pageURL, rendered)
+		}
+	}
+	if err != nil {
+		slog.Info("platform adapter found no menu; continuing cascade", "platform", adapter.Name(), "url", rawURL, "error", err)
+		return nil, adapter.Name()
+	}
+	return items, adapter.Name()
+}
+
 // StoreMenu embeds the extracted items and upserts them into the menu store (Weaviate).
 // Returns the item count.
 // renderToMarkdown renders rawURL in the headless browser and converts the
@@ -391,58 +501,106 @@ func StoreMenu(
 // StoreMenuItems upserts pre-built (already-embedded) menu items. It is the
 // lower-level half of StoreMenu, exposed so callers that already have items
 // (e.g. Avro replay, or the scrape worker writing Avro + DB in one pass) can
-// avoid re-embedding.
+// avoid re-embedding. When the store is a server.MenuVersioner the items
+// replace the menu scraped from their URL, recording a snapshot and retiring
+// dishes no longer listed; otherwise they are upserted.
 func StoreMenuItems(ctx context.Context, items []search.MenuItem, store server.MenuStore) (int, error) {
 	if len(items) == 0 {
 		return 0, nil
 	}
+	if mv, ok := store.(server.MenuVersioner); ok {
+		snap, err := mv.ReplaceMenu(ctx, items)
+		if err != nil {
+			return 0, fmt.Errorf("replacing menu: %w", err)
+		}
+		if snap != nil {
+			logMenuChanges(snap)
+		}
+		return len(items), nil
+	}
 	if err := store.BatchUpsertMenu(ctx, items); err != nil {
 		return 0, fmt.Errorf("upserting menu items: %w", err)
 	}
 	return len(items), nil
 }
 
+// logMenuChanges summarises a snapshot's changes by type.
+func logMenuChanges(snap *search.MenuSnapshot) {
+	counts := make(map[search.MenuChangeType]int)
+	for _, ch := range snap.Changes {
+		counts[ch.Type]++
+	}
+	slog.Info("menu snapshot recorded",
+		"business_id", snap.BusinessID,
+		"url", snap.SourceURL,
+		"items", snap.ItemCount,
+		"added", counts[search.MenuItemAdded],
+		"removed", counts[search.MenuItemRemoved],
+		"price_changed", counts[search.MenuItemPriceChanged],
+		"ingredients_changed", counts[search.MenuItemIngredientsChanged],
+		"description_changed", counts[search.MenuItemDescriptionChanged])
+}
+
 // ToMenuItems converts a MenuExtractionResult to []search.MenuItem, embedding each item's text vector.
 // restaurantID is the surrogate UUID PK from restaurants.id; it is used as the
 // menu item's business_id, linking menu_items to restaurants(id) via foreign key.
+// The result's coordinates are copied to every item so backends that index
+// dishes on their own (Weaviate) can serve geo filters.
 func ToMenuItems(ctx context.Context, result scraper.MenuExtractionResult, restaurantID uuid.UUID, rawURL string, embedder search.Embedder) ([]search.MenuItem, error) {
 	businessID := restaurantID
 	urlSection := scraper.MenuSection(rawURL) // fallback when the extractor didn't provide one
 	now := result.ScrapedAtUTC
 
+	lang := result.Language
+	if lang == "" {
+		lang = scraper.DetectMenuLanguage(result.Items)
+	}
+
 	texts := make([]string, len(result.Items))
 	for i, item := range result.Items {
-		parts := []string{"Menu item at " + result.RestaurantName + ": " + item.DishName}
-		if item.Description != "" {
-			parts = append(parts, item.Description)
+		m := search.MenuItem{
+			RestaurantName:    result.RestaurantName,
+			DishName:          item.DishName,
+			Description:       item.Description,
+			StatedIngredients: item.StatedIngredients,
 		}
-		if len(item.StatedIngredients) > 0 {
-			parts = append(parts, "Stated ingredients: "+strings.Join(item.StatedIngredients, ", "))
+		if t := item.Translation; t != nil {
+			m.DishNameEn, m.DescriptionEn, m.StatedIngredientsEn = t.DishName, t.Description, t.StatedIngredients
 		}
-		texts[i] = strings.Join(parts, ". ")
+		texts[i] = search.MenuEmbeddingText(m)
 	}
 
 	vectors := make([][]float32, 0, len(texts))
+	spaces := make([]int64, 0, len(texts))
 	const batchSize = 50
 	for i := 0; i < len(texts); i += batchSize {
 		end := i + batchSize
 		if end > len(texts) {
 			end = len(texts)
 		}
-		batchVectors, err := embedder.EmbedBatch(ctx, texts[i:end])
+		batchVectors, space, err := search.EmbedBatchInSpace(ctx, embedder, texts[i:end])
 		if err != nil {
 			return nil, fmt.Errorf("embedding batch [%d:%d]: %w", i, end, err)
 		}
-		// Defense-in-depth: reject wrong-dim vectors before upsert so a
-		// misconfigured embedder can't silently corrupt the index.
+		// Defense-in-depth: reject empty or mixed-dim vectors before upsert
+		// so a misbehaving embedder can't silently corrupt the index. The
+		// dimension itself is the embedding space's, checked by NewEmbedder
+		// and by the store's column type.
 		for j, v := range batchVectors {
-			if got := len(v); got != search.ExpectedEmbeddingDim {
+			want := len(batchVectors[0])
+			if len(vectors) > 0 {
+				want = len(vectors[0])
+			}
+			if got := len(v); got == 0 || got != want {
 				return nil, fmt.Errorf(
 					"embedding batch [%d:%d]: vector %d has dim %d, expected %d",
-					i, end, i+j, got, search.ExpectedEmbeddingDim)
+					i, end, i+j, got, want)
 			}
 		}
 		vectors = append(vectors, batchVectors...)
+		for range batchVectors {
+			spaces = append(spaces, space)
+		}
 	}
 
 	items := make([]search.MenuItem, len(result.Items))
@@ -475,8 +633,17 @@ func ToMenuItems(ctx context.Context, result scraper.MenuExtractionResult, resta
 			SourceURL:          rawURL,
 			Address:            result.Address,
 			PhoneNumber:        result.PhoneNumber,
+			Latitude:           result.Latitude,
+			Longitude:          result.Longitude,
 			ScrapedAt:          now,
 			Vector:             vectors[i],
+			EmbeddingSpace:     spaces[i],
+			Language:           lang,
+		}
+		if t := entry.Translation; t != nil {
+			items[i].DishNameEn = t.DishName
+			items[i].DescriptionEn = t.Description
+			items[i].StatedIngredientsEn = t.StatedIngredients
 		}
 	}
 	return items, nil
@@ -100,6 +100,65 @@ func TestExtractMenu_FallbackToExtractor(t *testing.T) {
 	}
 }
 
+func TestExtractMenu_PlatformAdapter(t *testing.T) {
+	page := `<html><head><script type="application/ld+json">{"@type":"Restaurant","name":"Golden Dumpling House"}</script></head>
+<body><div id="root"></div>
+<script>window.__PRELOADED_STATE__ = {"menu":{"categories":[{"name":"Dumplings","items":[{"name":"Pork & Chive Dumplings (8)","price":1095}]}]}};</script>
+</body></html>`
+	fetcher := &stubFetcher{
+		result: scraper.FetchResult{
+			Body:        io.NopCloser(strings.NewReader(page)),
+			ContentType: "text/html",
+		},
+	}
+	ex := &mockExtractor{}
+
+	res, _, err := ExtractMenu(context.Background(), "https://www.clover.com/online-ordering/golden-dumpling", fetcher, ex, false, false, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ex.called {
+		t.Error("LLM extractor called for a platform page with embedded menu state")
+	}
+	if res.ExtractionTier != TierPlatform {
+		t.Errorf("tier = %q, want %q", res.ExtractionTier, TierPlatform)
+	}
+	if res.RestaurantName != "Golden Dumpling House" {
+		t.Errorf("restaurant = %q, want name from JSON-LD", res.RestaurantName)
+	}
+	if len(res.Items) != 1 || res.Items[0].Price == nil || *res.Items[0].Price != 10.95 {
+		t.Errorf("items = %+v, want one dumpling at 10.95", res.Items)
+	}
+}
+
+func TestExtractMenu_PlatformAdapterRendersShell(t *testing.T) {
+	shell := `<html><body><div id="root"></div><script src="/app.js"></script></body></html>`
+	hydrated := `<html><body><script>window.__OO_STATE__ = {"menus":[{"groups":[{"name":"Noodles","items":[{"name":"Pan Mee","price":17}]}]}]};</script></body></html>`
+	fetcher := &stubFetcher{
+		result: scraper.FetchResult{
+			Body:        io.NopCloser(strings.NewReader(shell)),
+			ContentType: "text/html",
+		},
+	}
+	ex := &rendererExtractor{
+		renderResult: scraper.FetchResult{
+			Body:        io.NopCloser(strings.NewReader(hydrated)),
+			ContentType: "text/html",
+		},
+	}
+
+	res, _, err := ExtractMenu(context.Background(), "https://www.toasttab.com/kopitiam-nyc/v3", fetcher, ex, false, false, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ex.called {
+		t.Error("expected the shell to be rendered for the platform adapter")
+	}
+	if res.ExtractionTier != TierPlatform || len(res.Items) != 1 || res.Items[0].Section != "Noodles" {
+		t.Errorf("tier = %q, items = %+v", res.ExtractionTier, res.Items)
+	}
+}
+
 // ── ToMenuItems ───────────────────────────────────────────────────────────────
 
 type stubEmbedder struct{ err error }
@@ -166,6 +225,25 @@ func TestToMenuItems_Basic(t *testing.T) {
 	}
 }
 
+func TestToMenuItems_CarriesLocation(t *testing.T) {
+	lat, lon := 40.76, -73.92
+	result := scraper.MenuExtractionResult{
+		RestaurantName: "Test Resto",
+		Latitude:       &lat,
+		Longitude:      &lon,
+		Items:          []scraper.MenuEntry{{DishName: "Burger"}, {DishName: "Salad"}},
+	}
+	items, err := ToMenuItems(context.Background(), result, uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"), "https://example.com/menu", &stubEmbedder{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, it := range items {
+		if it.Latitude == nil || *it.Latitude != lat || it.Longitude == nil || *it.Longitude != lon {
+			t.Errorf("%s location = %v, %v, want %v, %v", it.DishName, it.Latitude, it.Longitude, lat, lon)
+		}
+	}
+}
+
 func TestToMenuItems_Empty(t *testing.T) {
 	result := scraper.MenuExtractionResult{RestaurantName: "Resto"}
 	items, err := ToMenuItems(context.Background(), result, uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"), "https://example.com", &stubEmbedder{})
@@ -259,7 +337,7 @@ func (s *stubMenuStore) EnsureMenuSchema(_ context.Context) error { return nil }
 func (s *stubMenuStore) BatchUpsertMenu(_ context.Context, _ []search.MenuItem) error {
 	return s.err
 }
-func (s *stubMenuStore) SearchMenu(_ context.Context, _ string, _ int) ([]search.MenuItem, error) {
+func (s *stubMenuStore) SearchMenu(_ context.Context, _ string, _ int, _ search.SearchFilter) ([]search.MenuItem, error) {
 	return nil, nil
 }
 
@@ -313,3 +391,30 @@ func TestStoreMenu_UpsertError(t *testing.T) {
 		t.Error("expected error when store fails")
 	}
 }
+
+// versioningMenuStore is a stubMenuStore that keeps menu history.
+type versioningMenuStore struct {
+	stubMenuStore
+	replaced int
+}
+
+func (s *versioningMenuStore) ReplaceMenu(_ context.Context, items []search.MenuItem) (*search.MenuSnapshot, error) {
+	s.replaced += len(items)
+	return &search.MenuSnapshot{ItemCount: len(items), Changes: search.DiffMenu(nil, items)}, nil
+}
+
+func (s *versioningMenuStore) MenuTimeline(_ context.Context, _ uuid.UUID, _ string, _ int) ([]search.MenuSnapshot, error) {
+	return nil, nil
+}
+
+func TestStoreMenuItems_PrefersReplaceMenu(t *testing.T) {
+	// BatchUpsertMenu fails, so success proves the versioned path was taken.
+	store := &versioningMenuStore{stubMenuStore: stubMenuStore{err: errors.New("should not upsert")}}
+	n, err := StoreMenuItems(context.Background(), []search.MenuItem{{MenuItemID: "a"}, {MenuItemID: "b"}}, store)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 || store.replaced != 2 {
+		t.Errorf("stored %d, replaced %d; want 2 and 2", n, store.replaced)
+	}
+}
@@ -109,10 +109,13 @@ func TestExtractMenu_JSShellReCascadeOnEmptyText(t *testing.T) {
 	}
 	ex := &jsShellExtractor{renderHTML: hydratedHTML}
 
-	res, _, err := ExtractMenu(context.Background(), "https://spa.example.com", fetcher, ex, false, false, "")
+	res, body, err := ExtractMenu(context.Background(), "https://spa.example.com", fetcher, ex, false, false, "")
 	if err != nil {
 		t.Fatalf("ExtractMenu: %v", err)
 	}
+	if !strings.Contains(string(body), "Espresso") {
+		t.Error("returned body is not the rendered page the items were read from")
+	}
 	if !ex.rendered {
 		t.Error("FetchRenderedHTML was not called (re-cascade must run on empty text + JS shell)")
 	}
@@ -173,10 +176,13 @@ func TestExtractMenu_JSShellTrivialTextPreRenders(t *testing.T) {
 	}
 	ex := &preRenderExtractor{renderHTML: hydratedHTML}
 
-	res, _, err := ExtractMenu(context.Background(), "https://spa.example.com", fetcher, ex, false, false, "")
+	res, body, err := ExtractMenu(context.Background(), "https://spa.example.com", fetcher, ex, false, false, "")
 	if err != nil {
 		t.Fatalf("ExtractMenu: %v", err)
 	}
+	if !strings.Contains(string(body), "Espresso") {
+		t.Error("returned body is not the rendered page the items were read from")
+	}
 	if !ex.rendered {
 		t.Error("FetchRenderedHTML was not called (pre-render must run on JS shell with trivial text)")
 	}
@@ -194,6 +200,36 @@ func TestExtractMenu_JSShellTrivialTextPreRenders(t *testing.T) {
 	}
 }
 
+// jsRendererExtractor implements scraper.Extractor + scraper.JSRenderer,
+// returning one item from the webagent.
+type jsRendererExtractor struct{ stubExtractor }
+
+func (e *jsRendererExtractor) ScrapeJS(_ context.Context, _ string, _ map[string]any) (scraper.MenuExtractionResult, error) {
+	return scraper.MenuExtractionResult{Items: []scraper.MenuEntry{{DishName: "Flat White"}}}, nil
+}
+
+func TestExtractMenu_WebagentReturnsNoBody(t *testing.T) {
+	// The webagent reads its items from a page it rendered itself, so the
+	// static page must not be returned as their source.
+	fetcher := &stubFetcher{
+		result: scraper.FetchResult{
+			Body:        io.NopCloser(strings.NewReader(`<html><body><p>Hi</p></body></html>`)),
+			ContentType: "text/html",
+		},
+	}
+
+	res, body, err := ExtractMenu(context.Background(), "https://spa.example.com", fetcher, &jsRendererExtractor{}, false, false, "spa/menu")
+	if err != nil {
+		t.Fatalf("ExtractMenu: %v", err)
+	}
+	if res.ExtractionTier != TierWebagent || len(res.Items) != 1 {
+		t.Fatalf("tier = %q, items = %d; want 1 webagent item", res.ExtractionTier, len(res.Items))
+	}
+	if body != nil {
+		t.Errorf("body = %q, want nil for a webagent scrape", body)
+	}
+}
+
 func TestExtractMenu_TrivialTextRefusesLLM(t *testing.T) {
 	// Near-empty page text with no render path available must refuse the LLM
 	// call instead of inviting hallucination (observed: a 1-rune page produced
@@ -257,7 +293,7 @@ func TestFetchWithFallback_SuccessNoFallback(t *testing.T) {
 	}
 	ex := &stubExtractor{}
 
-	body, ct, err := fetchWithFallback(context.Background(), "https://example.com", fetcher, ex)
+	body, ct, _, err := fetchWithFallback(context.Background(), "https://example.com", fetcher, ex)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -280,7 +316,7 @@ func TestFetchWithFallback_404_CallsFallback(t *testing.T) {
 	}
 	fetcher := &stubFetcher{err: &scraper.HTTPStatusError{StatusCode: 404, URL: "https://example.com"}}
 
-	body, ct, err := fetchWithFallback(context.Background(), "https://example.com", fetcher, renderer)
+	body, ct, _, err := fetchWithFallback(context.Background(), "https://example.com", fetcher, renderer)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -305,7 +341,7 @@ func TestFetchWithFallback_403_CallsFallback(t *testing.T) {
 	}
 	fetcher := &stubFetcher{err: &scraper.HTTPStatusError{StatusCode: 403, URL: "https://blocked.com"}}
 
-	body, ct, err := fetchWithFallback(context.Background(), "https://blocked.com", fetcher, renderer)
+	body, ct, _, err := fetchWithFallback(context.Background(), "https://blocked.com", fetcher, renderer)
 	if err != nil {
 		t.Fatalf("unexpected error after fallback: %v", err)
 	}
@@ -330,7 +366,7 @@ func TestFetchWithFallback_429_CallsFallback(t *testing.T) {
 	}
 	fetcher := &stubFetcher{err: &scraper.HTTPStatusError{StatusCode: 429, URL: "https://throttled.com"}}
 
-	body, _, err := fetchWithFallback(context.Background(), "https://throttled.com", fetcher, renderer)
+	body, _, _, err := fetchWithFallback(context.Background(), "https://throttled.com", fetcher, renderer)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -349,7 +385,7 @@ func TestFetchWithFallback_403_NoRenderer_ReturnsOriginalError(t *testing.T) {
 	fetcher := &stubFetcher{err: origErr}
 	ex := &stubExtractor{} // no HTMLRenderer
 
-	_, _, err := fetchWithFallback(context.Background(), "https://blocked.com", fetcher, ex)
+	_, _, _, err := fetchWithFallback(context.Background(), "https://blocked.com", fetcher, ex)
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -364,7 +400,7 @@ func TestFetchWithFallback_403_FallbackFails_ReturnsFallbackError(t *testing.T)
 	renderer := &rendererExtractor{renderErr: renderErr}
 	fetcher := &stubFetcher{err: &scraper.HTTPStatusError{StatusCode: 403, URL: "https://blocked.com"}}
 
-	_, _, err := fetchWithFallback(context.Background(), "https://blocked.com", fetcher, renderer)
+	_, _, _, err := fetchWithFallback(context.Background(), "https://blocked.com", fetcher, renderer)
 	if err == nil {
 		t.Fatal("expected error when fallback itself fails")
 	}
@@ -372,3 +408,43 @@ func TestFetchWithFallback_403_FallbackFails_ReturnsFallbackError(t *testing.T)
 		t.Errorf("error should mention rendered-fetch fallback, got: %v", err)
 	}
 }
+
+func TestRootOnlyTier(t *testing.T) {
+	for tier, want := range map[string]bool{
+		TierJSONLD:          true,
+		TierHTMLLLM:         true,
+		TierPDF:             true,
+		TierPlatform:        false,
+		TierImageOCR:        false,
+		TierWebagent:        false,
+		TierDirectoryFanout: false,
+		"":                  false,
+	} {
+		if got := RootOnlyTier(tier); got != want {
+			t.Errorf("RootOnlyTier(%q) = %v, want %v", tier, got, want)
+		}
+	}
+}
+
+func TestExtractMenuIfChanged_SkipsUnchanged(t *testing.T) {
+	fetcher := &stubFetcher{result: scraper.FetchResult{
+		Body:        io.NopCloser(strings.NewReader("<p>menu</p>")),
+		ContentType: "text/html",
+		NotModified: true,
+	}}
+	ex := &jsShellExtractor{}
+
+	result, body, err := ExtractMenuIfChanged(context.Background(), "https://example.com", fetcher, ex, false, false, "")
+	if !errors.Is(err, ErrContentUnchanged) {
+		t.Fatalf("err = %v, want ErrContentUnchanged", err)
+	}
+	if result != nil {
+		t.Errorf("result = %+v, want nil", result)
+	}
+	if string(body) != "<p>menu</p>" {
+		t.Errorf("body = %q, want cached body", body)
+	}
+	if len(ex.calls) != 0 {
+		t.Errorf("extractor called %d times, want 0", len(ex.calls))
+	}
+}
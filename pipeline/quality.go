@@ -0,0 +1,301 @@
+package pipeline
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"math"
+	"regexp"
+	"strings"
+
+	"fodmap/scraper"
+	"fodmap/textwords"
+)
+
+// Item quality flags, recorded on ItemQuality.Flags.
+const (
+	FlagUngrounded            = "ungrounded"             // dish name not found in the source
+	FlagUngroundedIngredients = "ungrounded_ingredients" // a stated ingredient not found in the source
+	FlagImplausiblePrice      = "implausible_price"      // price outside QualityPolicy bounds
+	FlagDuplicate             = "duplicate"              // same dish already listed in the same section
+	FlagSectionAnomaly        = "section_anomaly"        // section looks like a price, a dish or a paragraph
+)
+
+// Result-level issues, recorded on QualityReport.Issues.
+const (
+	IssueFragmentedSections = "fragmented_sections" // nearly every item in its own section
+	IssueUniformPrices      = "uniform_prices"      // every priced item has the same price
+)
+
+// QualityPolicy configures how extraction results are scored and which are
+// quarantined instead of stored.
+type QualityPolicy struct {
+	// MinScore is the QualityReport.Score below which a result is
+	// quarantined. 0 scores and records results but never quarantines.
+	MinScore float64
+	// MinItemGrounding is the grounding below which an item is flagged
+	// FlagUngrounded.
+	MinItemGrounding float64
+	// MinPrice and MaxPrice bound plausible prices in dollars; 0 disables a
+	// bound.
+	MinPrice float64
+	MaxPrice float64
+}
+
+// DefaultQualityPolicy quarantines results scoring under 0.6 and treats
+// prices outside $0.25–$500 as implausible.
+func DefaultQualityPolicy() QualityPolicy {
+	return QualityPolicy{
+		MinScore:         0.6,
+		MinItemGrounding: 0.5,
+		MinPrice:         0.25,
+		MaxPrice:         500,
+	}
+}
+
+// ItemQuality scores one extracted item. Grounding is the share of the dish
+// name's words found in the source times the share of stated ingredients
+// found there, so an invented dish or an invented ingredient both pull it
+// down. Score is Grounding after flag penalties.
+type ItemQuality struct {
+	Index                 int      `json:"index"`
+	DishName              string   `json:"dish"`
+	Grounding             float64  `json:"grounding"`
+	Score                 float64  `json:"score"`
+	UngroundedIngredients []string `json:"ungrounded_ingredients,omitempty"`
+	Flags                 []string `json:"flags,omitempty"`
+}
+
+// QualityReport is the outcome of QualityPolicy.Score.
+type QualityReport struct {
+	// Score is the mean item score, reduced for each result-level issue.
+	Score float64 `json:"score"`
+	// Grounded reports whether source text was available to ground items
+	// against. Image OCR and platform results have none, nor do webagent JS
+	// scrapes, whose page body ExtractMenu does not return; their items get
+	// grounding 1 and only the structural checks apply.
+	Grounded bool          `json:"grounded"`
+	Items    []ItemQuality `json:"items"`
+	Issues   []string      `json:"issues,omitempty"`
+}
+
+// Flagged returns the number of items carrying at least one flag.
+func (r QualityReport) Flagged() int {
+	n := 0
+	for _, it := range r.Items {
+		if len(it.Flags) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// Quarantine reports whether r falls below the policy's threshold. Empty
+// results are never quarantined; callers handle them as failed scrapes.
+func (p QualityPolicy) Quarantine(r QualityReport) bool {
+	return p.MinScore > 0 && len(r.Items) > 0 && r.Score < p.MinScore
+}
+
+// Score checks result against sources, the raw bodies it was extracted from
+// (HTML, JSON or PDF bytes; none when no body was kept). It is the hallucination
+// guard between extraction and StoreMenu: an LLM given too little text
+// invents plausible dishes, which show up here as names and ingredients that
+// never appear in the page.
+func (p QualityPolicy) Score(result scraper.MenuExtractionResult, sources ...[]byte) QualityReport {
+	words, grounded := sourceWords(result.ExtractionTier, sources)
+	report := QualityReport{Grounded: grounded, Items: make([]ItemQuality, len(result.Items))}
+
+	seen := make(map[string]bool, len(result.Items))
+	sections := make(map[string]bool)
+	var prices []float64
+	total := 0.0
+	for i, it := range result.Items {
+		q := ItemQuality{Index: i, DishName: it.DishName, Grounding: 1}
+		if grounded {
+			nameCov := words.coverage(it.DishName)
+			ingCov := 1.0
+			if len(it.StatedIngredients) > 0 {
+				found := 0
+				for _, ing := range it.StatedIngredients {
+					if words.coverage(ing) >= 0.5 {
+						found++
+					} else {
+						q.UngroundedIngredients = append(q.UngroundedIngredients, ing)
+					}
+				}
+				ingCov = float64(found) / float64(len(it.StatedIngredients))
+			}
+			q.Grounding = nameCov * ingCov
+			if nameCov < p.MinItemGrounding {
+				q.Flags = append(q.Flags, FlagUngrounded)
+			}
+			if len(q.UngroundedIngredients) > 0 {
+				q.Flags = append(q.Flags, FlagUngroundedIngredients)
+			}
+		}
+		q.Score = q.Grounding
+
+		if it.Price != nil {
+			prices = append(prices, *it.Price)
+			if !p.plausiblePrice(*it.Price) {
+				q.Flags = append(q.Flags, FlagImplausiblePrice)
+				q.Score *= 0.5
+			}
+		}
+		if sectionAnomaly(it.Section, it.DishName) {
+			q.Flags = append(q.Flags, FlagSectionAnomaly)
+			q.Score *= 0.5
+		}
+		key := strings.ToLower(strings.TrimSpace(it.Section)) + "\x00" + strings.Join(textwords.Split(it.DishName), " ")
+		if seen[key] {
+			q.Flags = append(q.Flags, FlagDuplicate)
+			q.Score = 0
+		}
+		seen[key] = true
+		if it.Section != "" {
+			sections[strings.ToLower(strings.TrimSpace(it.Section))] = true
+		}
+
+		q.Grounding = round2(q.Grounding)
+		q.Score = round2(q.Score)
+		report.Items[i] = q
+		total += q.Score
+	}
+
+	if len(sections) >= 5 && len(sections)*2 > len(result.Items) {
+		report.Issues = append(report.Issues, IssueFragmentedSections)
+	}
+	if len(prices) >= 5 && uniform(prices) {
+		report.Issues = append(report.Issues, IssueUniformPrices)
+	}
+	if len(result.Items) > 0 {
+		report.Score = total / float64(len(result.Items))
+		for range report.Issues {
+			report.Score *= 0.8
+		}
+		report.Score = round2(report.Score)
+	}
+	return report
+}
+
+func (p QualityPolicy) plausiblePrice(v float64) bool {
+	if v < 0 || (p.MinPrice > 0 && v < p.MinPrice) {
+		return false
+	}
+	return p.MaxPrice <= 0 || v <= p.MaxPrice
+}
+
+// sectionPriceRe matches a price inside a section name, a sign the extractor
+// split a "Dish ... $12" line in the wrong place.
+var sectionPriceRe = regexp.MustCompile(`\$\s?\d|\d+\.\d{2}\b`)
+
+// maxSectionRunes is longer than any real section heading; longer "sections"
+// are descriptions or notices captured as headings.
+const maxSectionRunes = 60
+
+func sectionAnomaly(section, dish string) bool {
+	s := strings.TrimSpace(section)
+	if s == "" {
+		return false
+	}
+	return len([]rune(s)) > maxSectionRunes ||
+		sectionPriceRe.MatchString(s) ||
+		strings.EqualFold(s, strings.TrimSpace(dish))
+}
+
+func uniform(prices []float64) bool {
+	for _, v := range prices[1:] {
+		if math.Abs(v-prices[0]) >= 0.005 {
+			return false
+		}
+	}
+	return true
+}
+
+func round2(v float64) float64 { return math.Round(v*100) / 100 }
+
+// wordSet is the set of normalised words in a source document.
+type wordSet map[string]bool
+
+// groundingStopwords are connecting words menus and extractors add or drop
+// freely; they carry no evidence either way.
+var groundingStopwords = map[string]bool{
+	"a": true, "and": true, "the": true, "of": true, "with": true, "w": true,
+	"in": true, "on": true, "or": true, "our": true, "de": true, "la": true,
+}
+
+// coverage returns the share of s's words found in the set; 1 for text with
+// no content words.
+func (ws wordSet) coverage(s string) float64 {
+	words := textwords.Split(s)
+	total, found := 0, 0
+	for _, w := range words {
+		if groundingStopwords[w] {
+			continue
+		}
+		total++
+		for _, v := range wordVariants(w) {
+			if ws[v] {
+				found++
+				break
+			}
+		}
+	}
+	if total == 0 {
+		return 1
+	}
+	return float64(found) / float64(total)
+}
+
+// sourceWords extracts the words of the source an extraction can be grounded
+// against. HTML and JSON bodies are used as-is, entities decoded, so embedded
+// state and JSON-LD count as evidence; PDFs contribute their text layer.
+// Image OCR results are never grounded against the page that linked the
+// image, nor platform results, which may be read from a rendered page or a
+// menu API rather than the page body.
+func sourceWords(tier string, sources [][]byte) (wordSet, bool) {
+	if tier == TierImageOCR || tier == TierPlatform {
+		return nil, false
+	}
+	set := make(wordSet)
+	for _, source := range sources {
+		text := ""
+		if bytes.HasPrefix(source, []byte("%PDF-")) {
+			t, err := scraper.ExtractPDFText(source, false)
+			if err != nil {
+				continue
+			}
+			text = t
+		} else {
+			text = html.UnescapeString(string(source))
+		}
+		for _, w := range textwords.Split(text) {
+			for _, v := range wordVariants(w) {
+				set[v] = true
+			}
+		}
+	}
+	return set, len(set) > 0
+}
+
+// wordVariants returns w with its plural suffixes stripped, so "dumplings"
+// grounds "dumpling", "tomatoes" grounds "tomato" and "sauces" grounds
+// "sauce".
+func wordVariants(w string) []string {
+	out := []string{w}
+	if len(w) > 4 && strings.HasSuffix(w, "ies") {
+		out = append(out, w[:len(w)-3]+"y")
+	}
+	if len(w) > 4 && strings.HasSuffix(w, "es") {
+		out = append(out, w[:len(w)-2])
+	}
+	if len(w) > 3 && strings.HasSuffix(w, "s") {
+		out = append(out, w[:len(w)-1])
+	}
+	return out
+}
+
+// String summarises r for logs and last_error.
+func (r QualityReport) String() string {
+	return fmt.Sprintf("score %.2f, %d of %d items flagged", r.Score, r.Flagged(), len(r.Items))
+}
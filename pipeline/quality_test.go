@@ -0,0 +1,143 @@
+package pipeline
+
+import (
+	"slices"
+	"testing"
+
+	"fodmap/scraper"
+)
+
+func price(v float64) *float64 { return &v }
+
+const qualitySource = `<html><body><h1>Kopitiam</h1>
+<h2>Noodles</h2>
+<p>Pan Mee &amp; Anchovies — hand-torn noodles, minced pork, anchovy broth $17</p>
+<p>Curry Laksa — coconut curry, tofu puffs, prawns $18</p>
+<h2>Toast</h2><p>Kaya Butter Toast $6.50</p></body></html>`
+
+func TestQualityScore_GroundedMenu(t *testing.T) {
+	result := scraper.MenuExtractionResult{
+		ExtractionTier: TierHTMLLLM,
+		Items: []scraper.MenuEntry{
+			{DishName: "Pan Mee & Anchovies", Section: "Noodles", Price: price(17), StatedIngredients: []string{"noodles", "minced pork", "anchovy broth"}},
+			{DishName: "Curry Laksa", Section: "Noodles", Price: price(18), StatedIngredients: []string{"coconut curry", "tofu puff", "prawn"}},
+			{DishName: "Kaya Butter Toast", Section: "Toast", Price: price(6.5)},
+		},
+	}
+	report := DefaultQualityPolicy().Score(result, []byte(qualitySource))
+	if !report.Grounded {
+		t.Fatal("expected an HTML source to ground the result")
+	}
+	if report.Score != 1 || report.Flagged() != 0 {
+		t.Errorf("score = %v, flagged = %d, want a clean 1.0: %+v", report.Score, report.Flagged(), report.Items)
+	}
+	if DefaultQualityPolicy().Quarantine(report) {
+		t.Error("grounded menu quarantined")
+	}
+}
+
+func TestQualityScore_HallucinatedMenu(t *testing.T) {
+	// The failure mode from the JS-shell comments: a near-empty page turned
+	// into a plausible invented menu.
+	shell := []byte(`<html><body><div id="root">Loading…</div><noscript>Enable JavaScript</noscript></body></html>`)
+	result := scraper.MenuExtractionResult{
+		ExtractionTier: TierHTMLLLM,
+		Items: []scraper.MenuEntry{
+			{DishName: "Margherita Pizza", Price: price(14), StatedIngredients: []string{"tomato", "mozzarella", "basil"}},
+			{DishName: "Caesar Salad", Price: price(11)},
+			{DishName: "Garlic Bread", Price: price(6)},
+		},
+	}
+	p := DefaultQualityPolicy()
+	report := p.Score(result, shell)
+	if report.Score != 0 {
+		t.Errorf("score = %v, want 0", report.Score)
+	}
+	if !slices.Contains(report.Items[0].Flags, FlagUngrounded) || !slices.Contains(report.Items[0].Flags, FlagUngroundedIngredients) {
+		t.Errorf("flags = %v, want ungrounded name and ingredients", report.Items[0].Flags)
+	}
+	if !p.Quarantine(report) {
+		t.Error("hallucinated menu not quarantined")
+	}
+}
+
+func TestQualityScore_InventedIngredient(t *testing.T) {
+	result := scraper.MenuExtractionResult{Items: []scraper.MenuEntry{
+		{DishName: "Curry Laksa", StatedIngredients: []string{"coconut curry", "garlic", "onion", "prawns"}},
+	}}
+	report := DefaultQualityPolicy().Score(result, []byte(qualitySource))
+	it := report.Items[0]
+	if it.Grounding != 0.5 {
+		t.Errorf("grounding = %v, want 0.5 (2 of 4 ingredients grounded)", it.Grounding)
+	}
+	if !slices.Equal(it.UngroundedIngredients, []string{"garlic", "onion"}) {
+		t.Errorf("ungrounded ingredients = %v", it.UngroundedIngredients)
+	}
+}
+
+func TestQualityScore_StructuralChecks(t *testing.T) {
+	result := scraper.MenuExtractionResult{
+		ExtractionTier: TierImageOCR,
+		Items: []scraper.MenuEntry{
+			{DishName: "Pan Mee", Section: "Noodles", Price: price(17)},
+			{DishName: "Pan mee", Section: "Noodles", Price: price(17)},
+			{DishName: "Curry Laksa", Section: "Noodles", Price: price(1800)},
+			{DishName: "Kaya Toast", Section: "Kaya Toast $6.50"},
+		},
+	}
+	report := DefaultQualityPolicy().Score(result, []byte(qualitySource))
+	if report.Grounded {
+		t.Error("image OCR results must not be grounded against the linking page")
+	}
+	want := [][]string{nil, {FlagDuplicate}, {FlagImplausiblePrice}, {FlagSectionAnomaly}}
+	for i, it := range report.Items {
+		if !slices.Equal(it.Flags, want[i]) {
+			t.Errorf("item %d flags = %v, want %v", i, it.Flags, want[i])
+		}
+	}
+	if report.Score != 0.5 {
+		t.Errorf("score = %v, want mean of 1, 0, 0.5, 0.5", report.Score)
+	}
+}
+
+func TestQualityScore_PlatformNotGrounded(t *testing.T) {
+	// Platform menus may come from a menu API, so the static page saying
+	// nothing about them must not count against them.
+	result := scraper.MenuExtractionResult{
+		ExtractionTier: TierPlatform,
+		Items: []scraper.MenuEntry{
+			{DishName: "Cubano", Section: "Sandwiches", Price: price(12.5)},
+			{DishName: "Egg & Cheese", Section: "Sandwiches", Price: price(6)},
+		},
+	}
+	report := DefaultQualityPolicy().Score(result, []byte(`<html><div id="app"></div></html>`))
+	if report.Grounded {
+		t.Error("platform result grounded against the page body")
+	}
+	if DefaultQualityPolicy().Quarantine(report) {
+		t.Errorf("platform menu quarantined: %+v", report)
+	}
+}
+
+func TestQualityScore_UniformPrices(t *testing.T) {
+	var items []scraper.MenuEntry
+	for _, name := range []string{"Pan Mee", "Curry Laksa", "Kaya Butter Toast", "Minced Pork", "Tofu Puffs"} {
+		items = append(items, scraper.MenuEntry{DishName: name, Price: price(9.99)})
+	}
+	report := DefaultQualityPolicy().Score(scraper.MenuExtractionResult{Items: items}, []byte(qualitySource))
+	if !slices.Equal(report.Issues, []string{IssueUniformPrices}) {
+		t.Errorf("issues = %v", report.Issues)
+	}
+	if report.Score != 0.8 {
+		t.Errorf("score = %v, want 0.8", report.Score)
+	}
+}
+
+func TestQualityPolicy_ZeroThresholdNeverQuarantines(t *testing.T) {
+	p := DefaultQualityPolicy()
+	p.MinScore = 0
+	report := p.Score(scraper.MenuExtractionResult{Items: []scraper.MenuEntry{{DishName: "Invented"}}}, []byte("<p>nothing</p>"))
+	if p.Quarantine(report) {
+		t.Error("MinScore 0 must not quarantine")
+	}
+}
@@ -0,0 +1,44 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"fodmap/scraper"
+)
+
+// tagLanguage records the detected menu language on a successful extraction.
+// It wraps extractMenu's results so every return path is covered.
+func tagLanguage(result *scraper.MenuExtractionResult, raw []byte, err error) (*scraper.MenuExtractionResult, []byte, error) {
+	if err == nil && result != nil && result.Language == "" {
+		result.Language = scraper.DetectMenuLanguage(result.Items)
+	}
+	return result, raw, err
+}
+
+// TranslateMenu fills in the English Translation of each entry of a
+// non-English menu. English menus and menus whose language could not be
+// detected are left untouched. The original wording is never replaced, so a
+// failed translation leaves a usable result; callers treat the error as a
+// warning.
+func TranslateMenu(ctx context.Context, result *scraper.MenuExtractionResult, tr scraper.MenuTranslator) error {
+	if result == nil || len(result.Items) == 0 {
+		return nil
+	}
+	if result.Language == "" {
+		result.Language = scraper.DetectMenuLanguage(result.Items)
+	}
+	if result.Language == "" || result.Language == scraper.LanguageEnglish {
+		return nil
+	}
+	translations, err := tr.TranslateMenu(ctx, result.Language, result.Items)
+	if err != nil {
+		return fmt.Errorf("translating %s menu: %w", result.Language, err)
+	}
+	for i, t := range translations {
+		if i < len(result.Items) && t != nil {
+			result.Items[i].Translation = t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"fodmap/scraper"
+)
+
+type stubTranslator struct {
+	calls int
+	lang  string
+	err   error
+}
+
+func (s *stubTranslator) TranslateMenu(_ context.Context, lang string, items []scraper.MenuEntry) ([]*scraper.EntryTranslation, error) {
+	s.calls++
+	s.lang = lang
+	if s.err != nil {
+		return nil, s.err
+	}
+	out := make([]*scraper.EntryTranslation, len(items))
+	out[0] = &scraper.EntryTranslation{DishName: "Garlic soup", StatedIngredients: []string{"garlic"}}
+	return out, nil
+}
+
+func spanishMenu() *scraper.MenuExtractionResult {
+	return &scraper.MenuExtractionResult{
+		RestaurantName: "La Casa",
+		Items: []scraper.MenuEntry{
+			{DishName: "Sopa de ajo", Description: "Caldo con pan y huevo de la casa", StatedIngredients: []string{"ajo"}},
+			{DishName: "Flan", Description: "Postre de la casa con caramelo"},
+		},
+	}
+}
+
+func TestTranslateMenu(t *testing.T) {
+	result := spanishMenu()
+	tr := &stubTranslator{}
+	if err := TranslateMenu(context.Background(), result, tr); err != nil {
+		t.Fatalf("TranslateMenu: %v", err)
+	}
+	if result.Language != "es" || tr.lang != "es" {
+		t.Errorf("language = %q (translator got %q), want es", result.Language, tr.lang)
+	}
+	if got := result.Items[0].Translation; got == nil || got.DishName != "Garlic soup" {
+		t.Errorf("item 0 translation = %+v, want Garlic soup", got)
+	}
+	if result.Items[0].DishName != "Sopa de ajo" {
+		t.Errorf("original dish name replaced: %q", result.Items[0].DishName)
+	}
+	if result.Items[1].Translation != nil {
+		t.Errorf("item 1 translation = %+v, want nil", result.Items[1].Translation)
+	}
+
+	items, err := ToMenuItems(context.Background(), *result, uuid.New(), "https://example.com/menu", &stubEmbedder{})
+	if err != nil {
+		t.Fatalf("ToMenuItems: %v", err)
+	}
+	if items[0].Language != "es" || items[0].DishNameEn != "Garlic soup" || len(items[0].StatedIngredientsEn) != 1 {
+		t.Errorf("item 0 = %+v, want es with English fields", items[0])
+	}
+	if items[1].DishNameEn != "" {
+		t.Errorf("item 1 DishNameEn = %q, want empty", items[1].DishNameEn)
+	}
+}
+
+func TestTranslateMenu_SkipsEnglish(t *testing.T) {
+	result := &scraper.MenuExtractionResult{Items: []scraper.MenuEntry{{DishName: "Garlic soup", Description: "With bread and a fried egg"}}}
+	tr := &stubTranslator{}
+	if err := TranslateMenu(context.Background(), result, tr); err != nil {
+		t.Fatalf("TranslateMenu: %v", err)
+	}
+	if tr.calls != 0 {
+		t.Errorf("translator called %d times for an English menu", tr.calls)
+	}
+	if result.Language != "en" {
+		t.Errorf("language = %q, want en", result.Language)
+	}
+}
+
+func TestTranslateMenu_ErrorKeepsOriginals(t *testing.T) {
+	result := spanishMenu()
+	err := TranslateMenu(context.Background(), result, &stubTranslator{err: errors.New("llm down")})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if result.Items[0].Translation != nil || result.Items[0].DishName != "Sopa de ajo" {
+		t.Errorf("item 0 = %+v, want original text and no translation", result.Items[0])
+	}
+}
@@ -0,0 +1,64 @@
+package queryparse
+
+import (
+	"slices"
+	"strings"
+
+	"fodmap/search"
+	"fodmap/textwords"
+)
+
+// Dish is what Allows needs to know about a menu item.
+type Dish struct {
+	Text        string   // name, description and stated ingredients, original and translated
+	Ingredients []string // FODMAP table keys the dish classifier matched
+	Price       *float64
+}
+
+// Allows reports whether d satisfies the dish constraints of f: MaxPrice,
+// Avoid and Dietary. A dish without a price passes a price ceiling, since
+// many menus list none. A dish labelled for a diet ("gluten-free ramen")
+// passes it; otherwise it fails when its text names an ingredient the diet
+// rules out. MaxFodmapLevel is left to the caller, which classifies dishes
+// already.
+func Allows(f search.SearchFilter, d Dish) bool {
+	if f.MaxPrice != nil && d.Price != nil && *d.Price > *f.MaxPrice {
+		return false
+	}
+	if len(f.Avoid) == 0 && len(f.Dietary) == 0 {
+		return true
+	}
+	words := textwords.Split(d.Text)
+	for _, a := range f.Avoid {
+		if slices.Contains(d.Ingredients, a) || containsAny(words, append([]string{a, a + "s", strings.TrimSuffix(a, "s")}, avoidTerms[a]...)) {
+			return false
+		}
+	}
+	for _, tag := range f.Dietary {
+		rule, ok := dietRules[tag]
+		if !ok || containsAny(words, rule.labels) {
+			continue
+		}
+		if containsAny(words, rule.exclude) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsAny reports whether words contains any of the phrases as a run of
+// whole words.
+func containsAny(words, phrases []string) bool {
+	for _, p := range phrases {
+		pw := textwords.Split(p)
+		if len(pw) == 0 {
+			continue
+		}
+		for i := 0; i+len(pw) <= len(words); i++ {
+			if slices.Equal(words[i:i+len(pw)], pw) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package queryparse
+
+import (
+	"testing"
+
+	"fodmap/search"
+)
+
+func TestAllows(t *testing.T) {
+	price := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name   string
+		filter search.SearchFilter
+		dish   Dish
+		want   bool
+	}{
+		{"no constraints", search.SearchFilter{}, Dish{Text: "Tonkotsu Ramen"}, true},
+		{"over budget", search.SearchFilter{MaxPrice: price(20)}, Dish{Price: price(24)}, false},
+		{"unpriced", search.SearchFilter{MaxPrice: price(20)}, Dish{Text: "Shoyu Ramen"}, true},
+		{"avoided ingredient matched", search.SearchFilter{Avoid: []string{"garlic"}}, Dish{Ingredients: []string{"garlic"}}, false},
+		{"avoided ingredient in text", search.SearchFilter{Avoid: []string{"onion"}}, Dish{Text: "Burger with grilled onions"}, false},
+		{"avoided food group", search.SearchFilter{Avoid: []string{"dairy"}}, Dish{Text: "Penne alla vodka, parmesan"}, false},
+		{"gluten in the dish", search.SearchFilter{Dietary: []string{GlutenFree}}, Dish{Text: "Miso Ramen"}, false},
+		{"labelled gluten-free", search.SearchFilter{Dietary: []string{GlutenFree}}, Dish{Text: "Gluten-Free Ramen (rice noodles)"}, true},
+		{"vegetarian", search.SearchFilter{Dietary: []string{Vegetarian}}, Dish{Text: "Pork belly bao"}, false},
+		{"vegan label suits lactose-free", search.SearchFilter{Dietary: []string{LactoseFree}}, Dish{Text: "Vegan ice cream"}, true},
+	}
+	for _, tt := range tests {
+		if got := Allows(tt.filter, tt.dish); got != tt.want {
+			t.Errorf("%s: Allows = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
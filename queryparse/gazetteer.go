@@ -0,0 +1,148 @@
+package queryparse
+
+import (
+	"strings"
+
+	"fodmap/geo"
+)
+
+// Place is a location the gazetteer knows: a state, a city or an NYC
+// borough or neighbourhood. Neighbourhoods carry an approximate bounding box
+// on top of the city they belong to, since their restaurants are all filed
+// under City "New York".
+type Place struct {
+	Name  string // display name, e.g. "Astoria"
+	City  string // empty for a state
+	State string // two-letter code
+	BBox  *geo.BBox
+}
+
+// states maps lower-cased state names to their postal codes. Canadian
+// provinces in the Yelp dataset are included.
+var states = map[string]string{
+	"alabama": "AL", "alaska": "AK", "arizona": "AZ", "arkansas": "AR",
+	"california": "CA", "colorado": "CO", "connecticut": "CT", "delaware": "DE",
+	"florida": "FL", "georgia": "GA", "hawaii": "HI", "idaho": "ID",
+	"illinois": "IL", "indiana": "IN", "iowa": "IA", "kansas": "KS",
+	"kentucky": "KY", "louisiana": "LA", "maine": "ME", "maryland": "MD",
+	"massachusetts": "MA", "michigan": "MI", "minnesota": "MN", "mississippi": "MS",
+	"missouri": "MO", "montana": "MT", "nebraska": "NE", "nevada": "NV",
+	"new hampshire": "NH", "new jersey": "NJ", "new mexico": "NM", "new york state": "NY",
+	"north carolina": "NC", "north dakota": "ND", "ohio": "OH", "oklahoma": "OK",
+	"oregon": "OR", "pennsylvania": "PA", "rhode island": "RI", "south carolina": "SC",
+	"south dakota": "SD", "tennessee": "TN", "texas": "TX", "utah": "UT",
+	"vermont": "VT", "virginia": "VA", "washington": "WA", "west virginia": "WV",
+	"wisconsin": "WI", "wyoming": "WY", "district of columbia": "DC",
+	"alberta": "AB",
+}
+
+// stateCodes is the inverse of states, for recognising "Tampa, FL".
+var stateCodes = func() map[string]bool {
+	m := make(map[string]bool, len(states))
+	for _, code := range states {
+		m[code] = true
+	}
+	return m
+}()
+
+// cities lists the cities of the Yelp dataset and New York, keyed by their
+// lower-cased name. "New York" means the city; the state is "new york
+// state".
+var cities = map[string]Place{
+	"philadelphia":     {Name: "Philadelphia", City: "Philadelphia", State: "PA"},
+	"philly":           {Name: "Philadelphia", City: "Philadelphia", State: "PA"},
+	"west chester":     {Name: "West Chester", City: "West Chester", State: "PA"},
+	"tucson":           {Name: "Tucson", City: "Tucson", State: "AZ"},
+	"tampa":            {Name: "Tampa", City: "Tampa", State: "FL"},
+	"clearwater":       {Name: "Clearwater", City: "Clearwater", State: "FL"},
+	"st. petersburg":   {Name: "Saint Petersburg", City: "Saint Petersburg", State: "FL"},
+	"st petersburg":    {Name: "Saint Petersburg", City: "Saint Petersburg", State: "FL"},
+	"saint petersburg": {Name: "Saint Petersburg", City: "Saint Petersburg", State: "FL"},
+	"largo":            {Name: "Largo", City: "Largo", State: "FL"},
+	"brandon":          {Name: "Brandon", City: "Brandon", State: "FL"},
+	"palm harbor":      {Name: "Palm Harbor", City: "Palm Harbor", State: "FL"},
+	"indianapolis":     {Name: "Indianapolis", City: "Indianapolis", State: "IN"},
+	"carmel":           {Name: "Carmel", City: "Carmel", State: "IN"},
+	"fishers":          {Name: "Fishers", City: "Fishers", State: "IN"},
+	"nashville":        {Name: "Nashville", City: "Nashville", State: "TN"},
+	"franklin":         {Name: "Franklin", City: "Franklin", State: "TN"},
+	"brentwood":        {Name: "Brentwood", City: "Brentwood", State: "TN"},
+	"new orleans":      {Name: "New Orleans", City: "New Orleans", State: "LA"},
+	"nola":             {Name: "New Orleans", City: "New Orleans", State: "LA"},
+	"metairie":         {Name: "Metairie", City: "Metairie", State: "LA"},
+	"kenner":           {Name: "Kenner", City: "Kenner", State: "LA"},
+	"reno":             {Name: "Reno", City: "Reno", State: "NV"},
+	"sparks":           {Name: "Sparks", City: "Sparks", State: "NV"},
+	"edmonton":         {Name: "Edmonton", City: "Edmonton", State: "AB"},
+	"st. louis":        {Name: "Saint Louis", City: "Saint Louis", State: "MO"},
+	"st louis":         {Name: "Saint Louis", City: "Saint Louis", State: "MO"},
+	"saint louis":      {Name: "Saint Louis", City: "Saint Louis", State: "MO"},
+	"santa barbara":    {Name: "Santa Barbara", City: "Santa Barbara", State: "CA"},
+	"goleta":           {Name: "Goleta", City: "Goleta", State: "CA"},
+	"boise":            {Name: "Boise", City: "Boise", State: "ID"},
+	"meridian":         {Name: "Meridian", City: "Meridian", State: "ID"},
+	"wilmington":       {Name: "Wilmington", City: "Wilmington", State: "DE"},
+	"cherry hill":      {Name: "Cherry Hill", City: "Cherry Hill", State: "NJ"},
+	"new york":         {Name: "New York", City: "New York", State: "NY"},
+	"new york city":    {Name: "New York", City: "New York", State: "NY"},
+	"nyc":              {Name: "New York", City: "New York", State: "NY"},
+}
+
+// neighborhoods lists NYC boroughs and neighbourhoods with rough bounding
+// boxes ([minLon, minLat, maxLon, maxLat]). The boxes are generous; the
+// named areas of `restaurants areas` remain the precise definition.
+var neighborhoods = map[string]Place{
+	"manhattan":         nyc("Manhattan", -74.020, 40.700, -73.907, 40.880),
+	"brooklyn":          nyc("Brooklyn", -74.042, 40.570, -73.833, 40.740),
+	"queens":            nyc("Queens", -73.962, 40.540, -73.700, 40.800),
+	"the bronx":         nyc("Bronx", -73.933, 40.785, -73.765, 40.917),
+	"bronx":             nyc("Bronx", -73.933, 40.785, -73.765, 40.917),
+	"staten island":     nyc("Staten Island", -74.260, 40.490, -74.050, 40.650),
+	"astoria":           nyc("Astoria", -73.940, 40.755, -73.890, 40.785),
+	"ditmars":           nyc("Astoria", -73.925, 40.770, -73.900, 40.785),
+	"long island city":  nyc("Long Island City", -73.962, 40.735, -73.925, 40.757),
+	"lic":               nyc("Long Island City", -73.962, 40.735, -73.925, 40.757),
+	"sunnyside":         nyc("Sunnyside", -73.930, 40.735, -73.910, 40.750),
+	"woodside":          nyc("Woodside", -73.912, 40.738, -73.890, 40.756),
+	"jackson heights":   nyc("Jackson Heights", -73.895, 40.745, -73.870, 40.760),
+	"flushing":          nyc("Flushing", -73.840, 40.745, -73.800, 40.775),
+	"williamsburg":      nyc("Williamsburg", -73.968, 40.700, -73.935, 40.725),
+	"greenpoint":        nyc("Greenpoint", -73.962, 40.719, -73.935, 40.739),
+	"bushwick":          nyc("Bushwick", -73.940, 40.680, -73.900, 40.710),
+	"park slope":        nyc("Park Slope", -73.990, 40.660, -73.970, 40.680),
+	"chinatown":         nyc("Chinatown", -74.002, 40.711, -73.990, 40.720),
+	"east village":      nyc("East Village", -73.992, 40.720, -73.972, 40.733),
+	"west village":      nyc("West Village", -74.012, 40.728, -73.998, 40.740),
+	"greenwich village": nyc("Greenwich Village", -74.010, 40.725, -73.990, 40.740),
+	"lower east side":   nyc("Lower East Side", -73.995, 40.710, -73.975, 40.724),
+	"harlem":            nyc("Harlem", -73.960, 40.800, -73.930, 40.830),
+	"midtown":           nyc("Midtown", -74.000, 40.745, -73.965, 40.765),
+}
+
+func nyc(name string, minLon, minLat, maxLon, maxLat float64) Place {
+	return Place{Name: name, City: "New York", State: "NY", BBox: &geo.BBox{minLon, minLat, maxLon, maxLat}}
+}
+
+// maxPlaceWords bounds the length of a place name the gazetteer is asked
+// about.
+const maxPlaceWords = 4
+
+// LookupPlace resolves a place name: a neighbourhood, a city, a state name
+// or an upper-case state code. It reports false for anything else.
+func LookupPlace(name string) (Place, bool) {
+	key := strings.Join(strings.Fields(strings.ToLower(strings.Trim(name, " ,."))), " ")
+	if p, ok := neighborhoods[key]; ok {
+		return p, true
+	}
+	if p, ok := cities[key]; ok {
+		return p, true
+	}
+	if code, ok := states[key]; ok {
+		return Place{Name: name, State: code}, true
+	}
+	// Codes are only taken in capitals: "in" and "or" are states too.
+	if code := strings.TrimSpace(name); stateCodes[code] {
+		return Place{Name: code, State: code}, true
+	}
+	return Place{}, false
+}
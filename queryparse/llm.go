@@ -0,0 +1,68 @@
+package queryparse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"fodmap/chat"
+)
+
+// llmTimeout bounds a place lookup, which holds up the search it is part
+// of.
+const llmTimeout = 5 * time.Second
+
+const placePrompt = `You resolve place names from restaurant search queries to a location.
+Reply with a single JSON object and nothing else:
+{"city": "...", "state": "...", "neighborhood": "..."}
+city is the city the place is in, state its two-letter US state or Canadian
+province code, and neighborhood the neighborhood or borough when the place is
+one (or a street or landmark inside one), else "". New York City
+neighborhoods and boroughs have city "New York". If the text is not a place
+you know, reply {"city": "", "state": "", "neighborhood": ""}.`
+
+// resolvePlaceLLM asks llm where text is. It returns nil, without error,
+// when the model does not know the place. A neighbourhood the gazetteer
+// knows brings its bounding box along.
+func resolvePlaceLLM(ctx context.Context, llm chat.ChatBackend, text string) (*Place, error) {
+	ctx, cancel := context.WithTimeout(ctx, llmTimeout)
+	defer cancel()
+	msg, err := llm.Generate(ctx, chat.GenerateOpts{
+		SystemPrompt: placePrompt,
+		History:      []chat.Message{{Role: "user", Text: text}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reply := strings.TrimSpace(msg.Text)
+	reply = strings.TrimPrefix(reply, "```json")
+	reply = strings.TrimPrefix(reply, "```")
+	reply = strings.TrimSuffix(reply, "```")
+	var got struct {
+		City         string `json:"city"`
+		State        string `json:"state"`
+		Neighborhood string `json:"neighborhood"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(reply)), &got); err != nil {
+		return nil, fmt.Errorf("decoding place reply: %w", err)
+	}
+
+	state := strings.ToUpper(strings.TrimSpace(got.State))
+	city := strings.TrimSpace(got.City)
+	if !stateCodes[state] {
+		return nil, nil
+	}
+	if p, ok := neighborhoods[strings.ToLower(strings.TrimSpace(got.Neighborhood))]; ok && p.State == state {
+		return &p, nil
+	}
+	if p, ok := cities[strings.ToLower(city)]; ok && p.State == state {
+		return &p, nil
+	}
+	if city == "" {
+		return &Place{Name: state, State: state}, nil
+	}
+	return &Place{Name: city, City: city, State: state}, nil
+}
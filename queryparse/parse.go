@@ -0,0 +1,593 @@
+package queryparse
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"fodmap/chat"
+	"fodmap/search"
+)
+
+// QuotedAlpha is the hybrid alpha given to a query with a quoted phrase: the
+// user wants those exact words, so keyword matching is weighted over
+// semantic similarity.
+const QuotedAlpha = 0.25
+
+// Result is a free-text query split into the constraints it names and the
+// words left for semantic search. Category, City, State, Geo and Alpha map
+// onto the backend filters; Dietary, Avoid, MaxPrice and MaxFodmapLevel are
+// dish constraints that menu search enforces (see Allows) and other
+// searches drop (see PlaceConstraints).
+type Result struct {
+	Query          string            `json:"query"`
+	PlaceQuery     string            `json:"-"` // Query with the dish constraint phrases left in
+	Category       string            `json:"category,omitempty"`
+	City           string            `json:"city,omitempty"`
+	State          string            `json:"state,omitempty"`
+	Place          string            `json:"place,omitempty"` // the place as the gazetteer names it, e.g. "Astoria"
+	Geo            *search.GeoFilter `json:"-"`
+	Alpha          float32           `json:"alpha,omitempty"`
+	Dietary        []string          `json:"dietary,omitempty"`
+	Avoid          []string          `json:"avoid,omitempty"`
+	MaxPrice       *float64          `json:"max_price,omitempty"`
+	MaxFodmapLevel string            `json:"max_fodmap_level,omitempty"`
+}
+
+// Filter returns the constraints of r as a SearchFilter.
+func (r Result) Filter() search.SearchFilter {
+	f := search.SearchFilter{}
+	r.Fill(&f)
+	return f
+}
+
+// Fill copies the constraints of r into the fields of f that are unset, so
+// parameters a caller passed explicitly win over what the text implies. A
+// location is only taken when f names none: City, State and Geo together.
+func (r Result) Fill(f *search.SearchFilter) {
+	if f.Category == "" {
+		f.Category = r.Category
+	}
+	if f.City == "" && f.State == "" && f.Geo == nil {
+		f.City, f.State, f.Geo = r.City, r.State, r.Geo
+	}
+	if f.Alpha == 0 {
+		f.Alpha = r.Alpha
+	}
+	if len(f.Dietary) == 0 {
+		f.Dietary = r.Dietary
+	}
+	if len(f.Avoid) == 0 {
+		f.Avoid = r.Avoid
+	}
+	if f.MaxPrice == nil {
+		f.MaxPrice = r.MaxPrice
+	}
+	if f.MaxFodmapLevel == "" {
+		f.MaxFodmapLevel = r.MaxFodmapLevel
+	}
+}
+
+// PlaceConstraints returns r for a search that cannot enforce the dish
+// constraints, such as business or review search: it keeps the category,
+// location and alpha, and searches with the price, FODMAP and "no
+// <ingredient>" phrases left in the query rather than silently dropped.
+func (r Result) PlaceConstraints() Result {
+	return Result{
+		Query: r.PlaceQuery, PlaceQuery: r.PlaceQuery,
+		Category: r.Category, City: r.City, State: r.State, Place: r.Place, Geo: r.Geo, Alpha: r.Alpha,
+	}
+}
+
+// Empty reports whether r carries no constraint, i.e. the query was taken
+// as it was.
+func (r Result) Empty() bool {
+	return r.Category == "" && r.City == "" && r.State == "" && r.Geo == nil && r.Alpha == 0 &&
+		len(r.Dietary) == 0 && len(r.Avoid) == 0 && r.MaxPrice == nil && r.MaxFodmapLevel == ""
+}
+
+// Parser turns free-text queries into a Result with rules and a gazetteer.
+// The zero value is ready to use.
+type Parser struct {
+	// LLM, when set, resolves place names the gazetteer does not know, such
+	// as "in Fishtown" or "near Ditmars Blvd". It is asked only when the
+	// rules find a location cue they cannot resolve, and its answers are
+	// cached; a failure leaves the words in the query.
+	LLM chat.ChatBackend
+
+	mu     sync.Mutex
+	places map[string]*Place // LLM resolutions by place text; nil = unknown
+}
+
+// New returns a Parser that falls back to llm for unknown places; llm may be
+// nil.
+func New(llm chat.ChatBackend) *Parser {
+	return &Parser{LLM: llm}
+}
+
+// Parse extracts location, cuisine, dietary, ingredient, price and FODMAP
+// constraints from q. Location, price, FODMAP and "no <ingredient>" phrases
+// are removed from the returned Query, and only location ones from
+// PlaceQuery; cuisine and diet words stay, since they still describe what
+// to match. When nothing is left the original query is kept.
+func (p *Parser) Parse(ctx context.Context, q string) Result {
+	res, unresolved := parseRules(q)
+	if unresolved != nil && p.LLM != nil {
+		if place := p.resolvePlace(ctx, unresolved.text); place != nil {
+			res.setPlace(*place)
+			res.Query, res.PlaceQuery = unresolved.residual, unresolved.placeResidual
+		}
+	}
+	return res
+}
+
+// unresolvedPlace is a location cue the gazetteer did not know, with the
+// residual queries to use should it be resolved.
+type unresolvedPlace struct {
+	text                    string
+	residual, placeResidual string
+}
+
+func (p *Parser) resolvePlace(ctx context.Context, text string) *Place {
+	key := strings.ToLower(text)
+	p.mu.Lock()
+	place, ok := p.places[key]
+	p.mu.Unlock()
+	if ok {
+		return place
+	}
+
+	place, err := resolvePlaceLLM(ctx, p.LLM, text)
+	if err != nil {
+		// Not cached: the next query retries.
+		slog.Warn("query parsing: resolving place failed", "place", text, "error", err)
+		return nil
+	}
+	p.mu.Lock()
+	if p.places == nil || len(p.places) >= maxCachedPlaces {
+		p.places = make(map[string]*Place)
+	}
+	p.places[key] = place
+	p.mu.Unlock()
+	return place
+}
+
+// maxCachedPlaces bounds the LLM place cache; it is dropped wholesale when
+// full.
+const maxCachedPlaces = 1024
+
+func (r *Result) setPlace(p Place) {
+	r.Place, r.City, r.State, r.Geo = p.Name, p.City, p.State, nil
+	if p.BBox != nil {
+		bbox := *p.BBox
+		r.Geo = &search.GeoFilter{BBox: &bbox}
+	}
+}
+
+var (
+	quotedRe = regexp.MustCompile(`"[^"]+"|“[^”]+”`)
+	priceRes = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\b(?:under|below|less than|cheaper than|up to|at most|max(?:imum)?|no more than)\s+\$?\s*(\d+(?:\.\d{1,2})?)(?:\s*(?:dollars|bucks|usd)\b)?`),
+		regexp.MustCompile(`(?i)\$\s*(\d+(?:\.\d{1,2})?)\s+(?:or less|or under|or cheaper|max)\b`),
+	}
+	fodmapRe         = regexp.MustCompile(`(?i)\b(low|moderate|medium|high)[\s-]*fodmaps?\b`)
+	fodmapFriendlyRe = regexp.MustCompile(`(?i)\b(?:fodmap|ibs)[\s-]*(?:friendly|safe)\b`)
+	ingredientFreeRe = regexp.MustCompile(`(?i)\b([a-z]+)[\s-]free\b`)
+	tokenRe          = regexp.MustCompile(`[\p{L}\p{N}$][\p{L}\p{N}'’.\-$]*|[,;&/]`)
+)
+
+type token struct {
+	text, lower string
+	start, end  int
+	removed     bool
+	dish        bool // removed as a dish constraint rather than a location
+}
+
+// parseRules applies the rules and gazetteer to q. It reports a location
+// cue it could not resolve, if any.
+func parseRules(q string) (Result, *unresolvedPlace) {
+	q = strings.TrimSpace(q)
+	var res Result
+	var cut [][2]int
+
+	if quotedRe.MatchString(q) {
+		res.Alpha = QuotedAlpha
+	}
+	for _, re := range priceRes {
+		if m := re.FindStringSubmatchIndex(q); m != nil && res.MaxPrice == nil {
+			if v, err := strconv.ParseFloat(q[m[2]:m[3]], 64); err == nil && v > 0 {
+				res.MaxPrice = &v
+				cut = append(cut, [2]int{m[0], m[1]})
+			}
+		}
+	}
+	if m := fodmapRe.FindStringSubmatchIndex(q); m != nil {
+		level := strings.ToLower(q[m[2]:m[3]])
+		if level == "medium" {
+			level = "moderate"
+		}
+		res.MaxFodmapLevel = level
+		cut = append(cut, [2]int{m[0], m[1]})
+	} else if m := fodmapFriendlyRe.FindStringIndex(q); m != nil {
+		res.MaxFodmapLevel = "low"
+		cut = append(cut, [2]int{m[0], m[1]})
+	}
+	res.Dietary = dietaryTags(q)
+	for _, m := range ingredientFreeRe.FindAllStringSubmatchIndex(q, -1) {
+		word := strings.ToLower(q[m[2]:m[3]])
+		if _, isDiet := freeDiets[word]; isDiet {
+			continue
+		}
+		if name, ok := ingredientName(word); ok {
+			res.Avoid = appendUnique(res.Avoid, name)
+			cut = append(cut, [2]int{m[0], m[1]})
+		}
+	}
+
+	var toks []*token
+	for _, m := range tokenRe.FindAllStringIndex(q, -1) {
+		t := &token{text: q[m[0]:m[1]], start: m[0], end: m[1]}
+		t.lower = strings.ToLower(t.text)
+		for _, c := range cut {
+			if t.start < c[1] && t.end > c[0] {
+				t.removed, t.dish = true, true
+			}
+		}
+		toks = append(toks, t)
+	}
+
+	res.Avoid = scanAvoid(toks, res.Avoid)
+	place, unresolved := scanPlace(toks)
+	if place != nil {
+		res.setPlace(*place)
+	}
+	res.Query, res.PlaceQuery = residual(toks, q, false), residual(toks, q, true)
+
+	var u *unresolvedPlace
+	if unresolved != nil {
+		// Work out the query as it would read once the place is resolved.
+		for _, t := range unresolved.tokens {
+			t.removed = true
+		}
+		u = &unresolvedPlace{text: unresolved.text, residual: residual(toks, q, false), placeResidual: residual(toks, q, true)}
+	}
+	dishConstrained := len(res.Dietary) > 0 || len(res.Avoid) > 0 || res.MaxPrice != nil || res.MaxFodmapLevel != ""
+	res.Category = scanCuisine(toks, dishConstrained)
+	return res, u
+}
+
+// residual joins the tokens left in toks, without the filler a query
+// opens or trails with; with keepDish, tokens removed as dish constraints
+// count as left. It returns q itself when nothing is left.
+func residual(toks []*token, q string, keepDish bool) string {
+	kept := residualTokens(toks, keepDish)
+	if len(kept) == 0 {
+		return q
+	}
+	var b strings.Builder
+	for i, t := range kept {
+		if i > 0 && t.text != "," && t.text != ";" {
+			b.WriteByte(' ')
+		}
+		b.WriteString(t.text)
+	}
+	return b.String()
+}
+
+// residualTokens returns the tokens residual joins.
+func residualTokens(toks []*token, keepDish bool) []*token {
+	var kept []*token
+	for _, t := range toks {
+		if !t.removed || (keepDish && t.dish) {
+			kept = append(kept, t)
+		}
+	}
+	for {
+		n := len(kept)
+		for _, f := range leadingFiller {
+			if len(kept) > len(f) && matchWords(kept, f) {
+				kept = kept[len(f):]
+				break
+			}
+		}
+		for len(kept) > 0 && connectors[kept[0].lower] {
+			kept = kept[1:]
+		}
+		for len(kept) > 0 && connectors[kept[len(kept)-1].lower] {
+			kept = kept[:len(kept)-1]
+		}
+		if len(kept) == n {
+			break
+		}
+	}
+	return kept
+}
+
+func matchWords(toks []*token, words []string) bool {
+	if len(toks) < len(words) {
+		return false
+	}
+	for i, w := range words {
+		if toks[i].lower != w {
+			return false
+		}
+	}
+	return true
+}
+
+var leadingFiller = [][]string{
+	{"where", "can", "i", "get"}, {"where", "can", "i", "find"}, {"where", "can", "i", "eat"},
+	{"i'm", "looking", "for"}, {"im", "looking", "for"}, {"looking", "for"},
+	{"where", "to", "get"}, {"where", "to", "eat"}, {"search", "for"},
+	{"find", "me"}, {"show", "me"}, {"i", "want"}, {"i'd", "like"}, {"find"},
+}
+
+// connectors are words and punctuation that mean nothing at either end of
+// a residual query, typically left behind by a removed phrase: "ramen with
+// no garlic" leaves "ramen with".
+var connectors = map[string]bool{
+	"and": true, "or": true, "with": true, "for": true, "that": true, "which": true,
+	"is": true, "are": true, "the": true, "a": true, "an": true, "at": true,
+	"in": true, "near": true, "around": true, "some": true, "any": true,
+	",": true, ";": true, "&": true, "/": true,
+}
+
+// scanAvoid collects the ingredients named after "no", "without" and the
+// like, e.g. "no onion or garlic", and removes those phrases.
+func scanAvoid(toks []*token, avoid []string) []string {
+	for i := 0; i < len(toks); i++ {
+		n := avoidCue(toks[i:])
+		if n == 0 {
+			continue
+		}
+		j := i + n
+		first := true
+		for j < len(toks) {
+			k := j
+			if !first {
+				// ", garlic", "or garlic", "and no garlic"
+				for k < len(toks) && (toks[k].lower == "," || toks[k].lower == "or" || toks[k].lower == "and" || toks[k].lower == "&") {
+					k++
+				}
+				if k < len(toks) && toks[k].lower == "no" {
+					k++
+				}
+			}
+			name, m := matchIngredient(toks[k:])
+			if m == 0 {
+				break
+			}
+			avoid = appendUnique(avoid, name)
+			for _, t := range toks[i : k+m] {
+				t.removed, t.dish = true, true
+			}
+			j, first = k+m, false
+		}
+		if !first {
+			i = j - 1
+		}
+	}
+	return avoid
+}
+
+// avoidCue reports how many tokens of an avoidance cue open toks.
+func avoidCue(toks []*token) int {
+	switch {
+	case len(toks) == 0 || toks[0].removed:
+		return 0
+	case toks[0].lower == "no" || toks[0].lower == "without" || toks[0].lower == "minus" ||
+		toks[0].lower == "avoid" || toks[0].lower == "avoiding":
+		return 1
+	case matchWords(toks, []string{"hold", "the"}), matchWords(toks, []string{"free", "of"}),
+		matchWords(toks, []string{"allergic", "to"}):
+		return 2
+	}
+	return 0
+}
+
+// matchIngredient matches the longest ingredient name, up to three words,
+// opening toks.
+func matchIngredient(toks []*token) (string, int) {
+	for n := min(3, len(toks)); n > 0; n-- {
+		words := make([]string, n)
+		for i, t := range toks[:n] {
+			if t.removed {
+				return "", 0
+			}
+			words[i] = t.lower
+		}
+		if name, ok := ingredientName(strings.Join(words, " ")); ok {
+			return name, n
+		}
+	}
+	return "", 0
+}
+
+// placeMatch is a place found after a location cue, or a cue left
+// unresolved, with the tokens it covers.
+type placeMatch struct {
+	text   string
+	tokens []*token
+}
+
+// scanPlace finds the first location in toks: a gazetteer place or
+// "<City>, <ST>" after "in", "near", "around" or "close to". A place named
+// without a cue is left alone, since it is as likely to be part of a
+// business or dish name ("Brooklyn Diner", "Manhattan clam chowder"). "near
+// me" is dropped, as there is no origin to search from. A capitalised name
+// after a cue that is none of these is reported as unresolved and left in
+// the query; one that only starts with a gazetteer place is removed and
+// reported as well.
+func scanPlace(toks []*token) (*Place, *placeMatch) {
+	var unresolved *placeMatch
+	for i := 0; i < len(toks); i++ {
+		n := 0
+		switch {
+		case toks[i].removed:
+			continue
+		case toks[i].lower == "nearby":
+			toks[i].removed = true
+			continue
+		case toks[i].lower == "in" || toks[i].lower == "near" || toks[i].lower == "around":
+			n = 1
+		case matchWords(toks[i:], []string{"close", "to"}):
+			n = 2
+		default:
+			continue
+		}
+		j := i + n
+		if j < len(toks) && toks[j].lower == "me" {
+			toks[i].removed, toks[j].removed = true, true
+			continue
+		}
+		if j < len(toks) && toks[j].lower == "the" {
+			j++
+		}
+		if place, m := matchPlace(toks[j:]); m > 0 {
+			// "near Ditmars Blvd" names more than the gazetteer matched:
+			// keep its guess, but let the LLM have a look at the whole.
+			var partial *placeMatch
+			if run := capitalisedRun(toks[j:]); run > m {
+				m = run
+				partial = &placeMatch{text: joinTokens(toks[j : j+m]), tokens: toks[i : j+m]}
+			}
+			for _, t := range toks[i : j+m] {
+				t.removed = true
+			}
+			return &place, partial
+		}
+		if unresolved == nil {
+			if m := capitalisedRun(toks[j:]); m > 0 {
+				unresolved = &placeMatch{text: joinTokens(toks[j : j+m]), tokens: toks[i : j+m]}
+			}
+		}
+	}
+	return nil, unresolved
+}
+
+// matchPlace matches the longest place opening toks, followed optionally by
+// ", <state>" or ", <borough>". Unknown capitalised names are accepted as a
+// city when a state follows.
+func matchPlace(toks []*token) (Place, int) {
+	var place Place
+	m := 0
+	for n := min(maxPlaceWords, len(toks)); n > 0 && m == 0; n-- {
+		if slices.ContainsFunc(toks[:n], func(t *token) bool { return t.removed || t.text == "," }) {
+			continue
+		}
+		if p, ok := LookupPlace(joinTokens(toks[:n])); ok {
+			place, m = p, n
+		}
+	}
+	if m == 0 {
+		m = capitalisedRun(toks)
+		if m == 0 {
+			return Place{}, 0
+		}
+		place = Place{Name: joinTokens(toks[:m])}
+		place.City = place.Name
+	}
+	if m+1 < len(toks) && toks[m].text == "," {
+		for n := min(3, len(toks)-m-1); n > 0; n-- {
+			next, ok := LookupPlace(joinTokens(toks[m+1 : m+1+n]))
+			if !ok {
+				continue
+			}
+			switch {
+			case next.City == "" && place.City != "" && place.State != next.State:
+				// "Franklin, MA": a namesake of a gazetteer city, or an
+				// unknown one.
+				place = Place{Name: place.Name, City: place.City, State: next.State}
+			case next.City == "" || next.State == place.State:
+				// "Tampa, FL", "Astoria, Queens"
+			default:
+				continue
+			}
+			return place, m + 1 + n
+		}
+	}
+	if place.State == "" {
+		// An unknown name with no state after it.
+		return Place{}, 0
+	}
+	return place, m
+}
+
+// capitalisedRun counts the capitalised words, up to maxPlaceWords, that
+// open toks.
+func capitalisedRun(toks []*token) int {
+	n := 0
+	for n < len(toks) && n < maxPlaceWords && !toks[n].removed && isCapitalised(toks[n].text) {
+		n++
+	}
+	return n
+}
+
+func isCapitalised(s string) bool {
+	for _, r := range s {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
+
+func joinTokens(toks []*token) string {
+	words := make([]string, len(toks))
+	for i, t := range toks {
+		words[i] = t.text
+	}
+	return strings.Join(words, " ")
+}
+
+// scanCuisine returns the category of the first cuisine word left in toks
+// that names the kind of place searched for rather than a dish, since the
+// category is a hard filter and a dish is served under many. A cuisine
+// name counts unless a dish follows it ("thai food" and "vegan thai" do,
+// "italian sausage" does not). A dish word counts only when it is all that
+// is left of the query, bar place words ("tacos", "sushi place"), and
+// dishConstrained (a diet, price, FODMAP level or avoided ingredient) does
+// not make it a dish search ("no garlic pasta").
+func scanCuisine(toks []*token, dishConstrained bool) string {
+	for i, t := range toks {
+		if t.removed {
+			continue
+		}
+		phrase, n := t.lower, 1
+		if i+1 < len(toks) && !toks[i+1].removed {
+			if _, ok := cuisines[phrase+" "+toks[i+1].lower]; ok {
+				phrase, n = phrase+" "+toks[i+1].lower, 2
+			}
+		}
+		c, ok := cuisines[phrase]
+		if !ok {
+			continue
+		}
+		if cuisineNames[phrase] {
+			if next := i + n; next >= len(toks) || toks[next].removed || connectors[toks[next].lower] || placeWords[toks[next].lower] {
+				return c
+			}
+			continue
+		}
+		if dishConstrained {
+			continue
+		}
+		rest := slices.DeleteFunc(residualTokens(toks, false), func(r *token) bool {
+			return slices.Contains(toks[i:i+n], r) || placeWords[r.lower]
+		})
+		if len(rest) == 0 {
+			return c
+		}
+	}
+	return ""
+}
+
+func appendUnique(list []string, s string) []string {
+	if slices.Contains(list, s) {
+		return list
+	}
+	return append(list, s)
+}
@@ -0,0 +1,166 @@
+package queryparse
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"fodmap/chat"
+	"fodmap/search"
+)
+
+func TestParse(t *testing.T) {
+	price := func(v float64) *float64 { return &v }
+	tests := []struct {
+		q    string
+		want Result
+	}{
+		// Dish constraints make a dish word a dish, not a category.
+		{"gluten-free ramen in Astoria under $20", Result{
+			Query: "gluten-free ramen", City: "New York", State: "NY", Place: "Astoria",
+			Dietary: []string{GlutenFree}, MaxPrice: price(20),
+		}},
+		{"lactose free pizza without onions or garlic in Philadelphia, PA", Result{
+			Query: "lactose free pizza", City: "Philadelphia", State: "PA", Place: "Philadelphia",
+			Dietary: []string{LactoseFree}, Avoid: []string{"onion", "garlic"},
+		}},
+		{"find me low-FODMAP thai food with no garlic near me", Result{
+			Query: "thai food", Category: "Thai", Avoid: []string{"garlic"}, MaxFodmapLevel: "low",
+		}},
+		{"tacos in Springfield, IL less than 15 dollars", Result{
+			Query: "tacos", City: "Springfield", State: "IL", Place: "Springfield", MaxPrice: price(15),
+		}},
+		{"tacos in Springfield, IL", Result{
+			Query: "tacos", Category: "Mexican", City: "Springfield", State: "IL", Place: "Springfield",
+		}},
+		{"no garlic pasta", Result{Query: "pasta", Avoid: []string{"garlic"}}},
+		{"italian sausage sandwich near Chinatown", Result{
+			Query: "italian sausage sandwich", City: "New York", State: "NY", Place: "Chinatown",
+		}},
+		{"vegan thai place", Result{Query: "vegan thai place", Category: "Thai", Dietary: []string{Vegan}}},
+		{`"spicy tonkotsu" in Williamsburg`, Result{
+			Query: "spicy tonkotsu", City: "New York", State: "NY", Place: "Williamsburg", Alpha: QuotedAlpha,
+		}},
+		{"garlic-free vegan curry in Indiana", Result{
+			Query: "vegan curry", State: "IN", Place: "Indiana",
+			Dietary: []string{Vegan}, Avoid: []string{"garlic"},
+		}},
+		// A city name without a cue is a dish, and "no wait" names no
+		// ingredient.
+		{"philly cheesesteak no wait", Result{Query: "philly cheesesteak no wait"}},
+		// An unknown place stays in the query without an LLM.
+		{"ramen in Fishtown", Result{Query: "ramen in Fishtown", Category: "Ramen"}},
+		{"in Astoria", Result{Query: "in Astoria", City: "New York", State: "NY", Place: "Astoria"}},
+		// A place named without a cue is part of a name.
+		{"Brooklyn Diner", Result{Query: "Brooklyn Diner"}},
+		{"Manhattan clam chowder", Result{Query: "Manhattan clam chowder"}},
+		{"Chinatown Express", Result{Query: "Chinatown Express"}},
+	}
+	var p Parser
+	for _, tt := range tests {
+		got := p.Parse(context.Background(), tt.q)
+		if (got.Geo != nil) != (tt.want.Place != "" && neighborhoods[lower(tt.want.Place)].BBox != nil) {
+			t.Errorf("%q: geo = %+v", tt.q, got.Geo)
+		}
+		got.Geo = nil
+		if !equalResults(got, tt.want) {
+			t.Errorf("%q:\n got  %+v\n want %+v", tt.q, got, tt.want)
+		}
+	}
+}
+
+func lower(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if 'A' <= c && c <= 'Z' {
+			b[i] = c + 'a' - 'A'
+		}
+	}
+	return string(b)
+}
+
+func equalResults(a, b Result) bool {
+	pa, pb := a.MaxPrice, b.MaxPrice
+	if (pa == nil) != (pb == nil) || (pa != nil && *pa != *pb) {
+		return false
+	}
+	a.MaxPrice, b.MaxPrice = nil, nil
+	return slices.Equal(a.Dietary, b.Dietary) && slices.Equal(a.Avoid, b.Avoid) &&
+		a.Query == b.Query && a.Category == b.Category && a.City == b.City && a.State == b.State &&
+		a.Place == b.Place && a.Alpha == b.Alpha && a.MaxFodmapLevel == b.MaxFodmapLevel
+}
+
+func TestResult_FillKeepsExplicitFilters(t *testing.T) {
+	var p Parser
+	res := p.Parse(context.Background(), "gluten-free thai food in Astoria under $20")
+
+	f := search.SearchFilter{City: "Boston", Alpha: 0.5}
+	res.Fill(&f)
+	if f.City != "Boston" || f.State != "" || f.Geo != nil {
+		t.Errorf("location = %q %q %v; an explicit city must win whole", f.City, f.State, f.Geo)
+	}
+	if f.Alpha != 0.5 || f.Category != "Thai" || f.MaxPrice == nil || *f.MaxPrice != 20 {
+		t.Errorf("filter = %+v", f)
+	}
+
+	f = res.Filter()
+	if f.City != "New York" || f.Geo == nil || f.Geo.BBox == nil || !f.Geo.BBox.Contains(40.7644, -73.9235) {
+		t.Errorf("filter = %+v, want Astoria's box", f)
+	}
+}
+
+func TestResult_PlaceConstraints(t *testing.T) {
+	var p Parser
+	res := p.Parse(context.Background(), "find me low-FODMAP thai food with no garlic in Astoria under $20").PlaceConstraints()
+	want := Result{Query: "low-FODMAP thai food with no garlic under $20", Category: "Thai", City: "New York", State: "NY", Place: "Astoria"}
+	if !equalResults(res, want) || res.Geo == nil {
+		t.Errorf("got  %+v\nwant %+v", res, want)
+	}
+
+	// With only dish constraints there is nothing left to apply.
+	if res := p.Parse(context.Background(), "something spicy under $20").PlaceConstraints(); !res.Empty() || res.Query != "something spicy under $20" {
+		t.Errorf("dish constraints only = %+v, want an empty parse of the whole query", res)
+	}
+}
+
+// placeBackend answers place lookups with a fixed reply and counts calls.
+type placeBackend struct {
+	reply string
+	err   error
+	calls int
+}
+
+func (b *placeBackend) Generate(_ context.Context, _ chat.GenerateOpts) (chat.Message, error) {
+	b.calls++
+	return chat.Message{Role: "model", Text: b.reply}, b.err
+}
+
+func TestParse_LLMResolvesUnknownPlaces(t *testing.T) {
+	llm := &placeBackend{reply: "```json\n{\"city\": \"New York\", \"state\": \"NY\", \"neighborhood\": \"Astoria\"}\n```"}
+	p := New(llm)
+
+	for range 2 {
+		got := p.Parse(context.Background(), "ramen near Ditmars Blvd")
+		if got.Query != "ramen" || got.Place != "Astoria" || got.Geo == nil {
+			t.Errorf("got %+v", got)
+		}
+	}
+	if llm.calls != 1 {
+		t.Errorf("LLM called %d times, want 1 with the answer cached", llm.calls)
+	}
+
+	// Known places never reach the LLM.
+	p.Parse(context.Background(), "ramen in Astoria")
+	if llm.calls != 1 {
+		t.Errorf("LLM called for a gazetteer place")
+	}
+
+	// A failure or an unknown place leaves the query alone.
+	for _, b := range []*placeBackend{{err: errors.New("quota")}, {reply: `{"city": "", "state": ""}`}, {reply: "somewhere"}} {
+		got := New(b).Parse(context.Background(), "ramen in Fishtown")
+		if got.Query != "ramen in Fishtown" || got.City != "" {
+			t.Errorf("got %+v", got)
+		}
+	}
+}
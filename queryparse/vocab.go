@@ -0,0 +1,183 @@
+package queryparse
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"fodmap/data"
+)
+
+// Dietary tags Parse recognises.
+const (
+	GlutenFree  = "gluten-free"
+	DairyFree   = "dairy-free"
+	LactoseFree = "lactose-free"
+	NutFree     = "nut-free"
+	Vegetarian  = "vegetarian"
+	Vegan       = "vegan"
+)
+
+// dietPatterns recognise each dietary tag in a query.
+var dietPatterns = []struct {
+	tag string
+	re  *regexp.Regexp
+}{
+	{GlutenFree, regexp.MustCompile(`(?i)\bgluten[\s-]*free\b|\bgf\b|\bc(?:o)?eliac\b`)},
+	{DairyFree, regexp.MustCompile(`(?i)\bdairy[\s-]*free\b|\bnon[\s-]*dairy\b`)},
+	{LactoseFree, regexp.MustCompile(`(?i)\blactose[\s-]*(?:free|intolerant)\b`)},
+	{NutFree, regexp.MustCompile(`(?i)\b(?:pea)?nut[\s-]*free\b`)},
+	{Vegetarian, regexp.MustCompile(`(?i)\bvegetarian\b|\bveggie\b`)},
+	{Vegan, regexp.MustCompile(`(?i)\bvegan\b|\bplant[\s-]*based\b`)},
+}
+
+// freeDiets are the words that make an "X-free" phrase a diet rather than
+// an ingredient to avoid.
+var freeDiets = map[string]string{
+	"gluten": GlutenFree, "dairy": DairyFree, "lactose": LactoseFree, "nut": NutFree, "peanut": NutFree,
+}
+
+// dietaryTags returns the dietary tags q names, in order of appearance.
+func dietaryTags(q string) []string {
+	type hit struct {
+		tag string
+		at  int
+	}
+	var hits []hit
+	for _, d := range dietPatterns {
+		if m := d.re.FindStringIndex(q); m != nil {
+			hits = append(hits, hit{d.tag, m[0]})
+		}
+	}
+	if len(hits) == 0 {
+		return nil
+	}
+	slices.SortFunc(hits, func(a, b hit) int { return a.at - b.at })
+	tags := make([]string, len(hits))
+	for i, h := range hits {
+		tags[i] = h.tag
+	}
+	return tags
+}
+
+// extraIngredients are things to avoid that are not FODMAP table entries:
+// allergens and food groups.
+var extraIngredients = []string{
+	"dairy", "gluten", "lactose", "nuts", "peanuts", "tree nuts", "shellfish", "meat", "eggs",
+	"soy", "sesame", "cilantro", "cheese", "butter", "spice", "msg", "seafood",
+}
+
+// ingredientName resolves a word or phrase from a query to the ingredient
+// it names: a FODMAP table key or one of extraIngredients, allowing for
+// plurals ("onions", "chickpea").
+func ingredientName(s string) (string, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, c := range []string{s, s + "s", strings.TrimSuffix(s, "s"), strings.TrimSuffix(s, "es")} {
+		if c == "" {
+			continue
+		}
+		if _, ok := data.FodmapDB[c]; ok {
+			return c, true
+		}
+		for _, e := range extraIngredients {
+			if c == e {
+				return c, true
+			}
+		}
+	}
+	return "", false
+}
+
+// cuisines maps cuisine and dish words to the business category they
+// imply. Categories are matched as substrings, so the broader category is
+// used where a narrower one is often missing (tacos are "Mexican").
+var cuisines = map[string]string{
+	"ramen": "Ramen", "sushi": "Sushi", "pizza": "Pizza", "thai": "Thai",
+	"mexican": "Mexican", "taco": "Mexican", "tacos": "Mexican", "burrito": "Mexican", "burritos": "Mexican",
+	"italian": "Italian", "pasta": "Italian", "chinese": "Chinese", "dim sum": "Dim Sum",
+	"indian": "Indian", "curry": "Indian", "japanese": "Japanese", "korean": "Korean",
+	"vietnamese": "Vietnamese", "pho": "Vietnamese", "greek": "Greek", "mediterranean": "Mediterranean",
+	"middle eastern": "Middle Eastern", "french": "French", "spanish": "Spanish", "tapas": "Tapas",
+	"burger": "Burgers", "burgers": "Burgers", "bbq": "Barbeque", "barbecue": "Barbeque",
+	"seafood": "Seafood", "steak": "Steakhouses", "steakhouse": "Steakhouses",
+	"bakery": "Bakeries", "brunch": "Breakfast & Brunch", "breakfast": "Breakfast & Brunch",
+	"coffee": "Coffee & Tea", "dessert": "Desserts", "desserts": "Desserts",
+	"sandwich": "Sandwiches", "sandwiches": "Sandwiches", "hot pot": "Hot Pot", "poke": "Poke",
+	"cajun": "Cajun", "ethiopian": "Ethiopian", "caribbean": "Caribbean", "peruvian": "Peruvian",
+	"turkish": "Turkish", "lebanese": "Lebanese", "filipino": "Filipino", "halal": "Halal",
+}
+
+// cuisineNames are the cuisines keys that name a cuisine rather than a
+// dish or a kind of place (see scanCuisine).
+var cuisineNames = map[string]bool{
+	"thai": true, "mexican": true, "italian": true, "chinese": true, "indian": true, "japanese": true,
+	"korean": true, "vietnamese": true, "greek": true, "mediterranean": true, "middle eastern": true,
+	"french": true, "spanish": true, "cajun": true, "ethiopian": true, "caribbean": true,
+	"peruvian": true, "turkish": true, "lebanese": true, "filipino": true, "halal": true,
+}
+
+// placeWords name a kind of place after a cuisine or dish word: "thai
+// food", "sushi place".
+var placeWords = map[string]bool{
+	"food": true, "cuisine": true, "restaurant": true, "restaurants": true, "place": true, "places": true,
+	"spot": true, "spots": true, "joint": true, "joints": true, "shop": true, "shops": true,
+}
+
+// Dish words each dietary tag rules out, and the labels that vouch for a
+// dish regardless ("gluten-free ramen" is fine although ramen is not).
+var (
+	glutenTerms = []string{
+		"wheat", "barley", "rye", "flour", "bread", "breaded", "pasta", "noodle", "noodles",
+		"ramen", "udon", "seitan", "couscous", "dumpling", "dumplings", "bun", "pita", "panko",
+		"tempura", "soy sauce", "beer", "croissant", "crouton", "croutons",
+	}
+	dairyTerms = []string{
+		"milk", "cheese", "cream", "butter", "yogurt", "ghee", "ricotta", "mozzarella",
+		"parmesan", "feta", "brie", "custard", "paneer", "mascarpone", "burrata", "queso",
+	}
+	lactoseTerms = []string{
+		"milk", "cream", "yogurt", "ricotta", "cottage cheese", "cream cheese", "custard",
+		"paneer", "mascarpone", "burrata", "ice cream",
+	}
+	nutTerms = []string{
+		"nut", "nuts", "peanut", "peanuts", "cashew", "cashews", "almond", "almonds",
+		"pistachio", "pistachios", "walnut", "walnuts", "pecan", "pecans", "hazelnut",
+		"hazelnuts", "pesto", "satay",
+	}
+	meatTerms = []string{
+		"chicken", "beef", "pork", "lamb", "fish", "shrimp", "bacon", "ham", "sausage",
+		"meat", "duck", "turkey", "veal", "salmon", "tuna", "anchovy", "anchovies", "crab",
+		"lobster", "clam", "clams", "oyster", "oysters", "squid", "octopus", "prosciutto",
+		"chorizo", "pepperoni", "steak", "brisket", "tonkotsu", "mussels", "scallops",
+	}
+	shellfishTerms = []string{
+		"shrimp", "prawn", "prawns", "crab", "lobster", "clam", "clams", "oyster", "oysters",
+		"mussel", "mussels", "scallop", "scallops", "shellfish",
+	}
+
+	dietRules = map[string]struct{ exclude, labels []string }{
+		GlutenFree:  {glutenTerms, []string{"gluten free", "gf"}},
+		DairyFree:   {dairyTerms, []string{"dairy free", "non dairy", "vegan"}},
+		LactoseFree: {lactoseTerms, []string{"lactose free", "dairy free", "non dairy", "vegan"}},
+		NutFree:     {nutTerms, []string{"nut free"}},
+		Vegetarian:  {meatTerms, []string{"vegetarian", "veggie", "vegan"}},
+		Vegan:       {slices.Concat(meatTerms, dairyTerms, []string{"egg", "eggs", "honey", "mayo", "mayonnaise"}), []string{"vegan", "plant based"}},
+	}
+
+	// avoidTerms expands the food groups of extraIngredients into the dish
+	// words that contain them.
+	avoidTerms = map[string][]string{
+		"dairy":     dairyTerms,
+		"lactose":   lactoseTerms,
+		"gluten":    glutenTerms,
+		"nuts":      nutTerms,
+		"tree nuts": nutTerms,
+		"peanuts":   {"peanut", "peanuts", "satay"},
+		"meat":      meatTerms,
+		"shellfish": shellfishTerms,
+		"seafood":   slices.Concat(shellfishTerms, []string{"fish", "salmon", "tuna", "squid", "octopus", "anchovy", "anchovies"}),
+		"eggs":      {"egg", "eggs", "omelette", "omelet", "mayo", "mayonnaise", "aioli"},
+		"cheese":    {"cheese", "mozzarella", "parmesan", "feta", "ricotta", "brie", "burrata", "queso"},
+		"spice":     {"spicy", "chili", "chilli", "jalapeno", "sriracha"},
+	}
+)
@@ -0,0 +1,137 @@
+package scraper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+)
+
+// ErrCassetteMiss is returned by a replaying Cassette for a request it has no
+// recording of.
+var ErrCassetteMiss = errors.New("no recorded response for request")
+
+// Cassette is an http.RoundTripper that records backend responses to Dir, or
+// replays them from it, so extraction backends (the LLM endpoint, the scraper
+// service) can run offline. Requests are keyed by method, URL and body;
+// headers, including Authorization, are not part of the key and are never
+// written. Install it with OpenAICompatExtractor.SetTransport or
+// ServiceExtractor.SetTransport.
+type Cassette struct {
+	Dir string
+	// Record forwards requests to Next and saves the responses. When false,
+	// every request is answered from Dir and a missing recording fails with
+	// ErrCassetteMiss.
+	Record bool
+	// Next is the transport used when recording; nil means
+	// http.DefaultTransport.
+	Next http.RoundTripper
+}
+
+// cassetteEntry is the on-disk form of one recorded response.
+type cassetteEntry struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"`
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body,omitempty"`
+	// RawBody holds bodies that are not valid UTF-8, base64-encoded.
+	RawBody []byte `json:"raw_body,omitempty"`
+}
+
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("cassette: reading request body: %w", err)
+		}
+		reqBody = b
+	}
+	sum := sha256.Sum256(append([]byte(req.Method+" "+req.URL.String()+"\n"), reqBody...))
+	path := filepath.Join(c.Dir, hex.EncodeToString(sum[:16])+".json")
+
+	if !c.Record {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s %s", ErrCassetteMiss, req.Method, req.URL)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cassette: %w", err)
+		}
+		var e cassetteEntry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("cassette: decoding %s: %w", path, err)
+		}
+		return e.response(req), nil
+	}
+
+	next := c.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(reqBody))
+	out.ContentLength = int64(len(reqBody))
+	resp, err := next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("cassette: reading response body: %w", err)
+	}
+	e := cassetteEntry{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if utf8.Valid(body) {
+		e.Body = string(body)
+	} else {
+		e.RawBody = body
+	}
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("cassette: encoding response: %w", err)
+	}
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("cassette: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return nil, fmt.Errorf("cassette: %w", err)
+	}
+	return e.response(req), nil
+}
+
+func (e cassetteEntry) response(req *http.Request) *http.Response {
+	body := e.RawBody
+	if e.Body != "" {
+		body = []byte(e.Body)
+	}
+	h := make(http.Header)
+	if e.ContentType != "" {
+		h.Set("Content-Type", e.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
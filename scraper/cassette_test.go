@@ -0,0 +1,90 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCassetteRecordReplay(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"echo":` + string(body) + `}`))
+	}))
+	dir := t.TempDir()
+
+	post := func(c *Cassette, body string) (string, error) {
+		client := &http.Client{Transport: c}
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/chat/completions", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret-key")
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = resp.Body.Close() }()
+		b, _ := io.ReadAll(resp.Body)
+		return string(b), nil
+	}
+
+	got, err := post(&Cassette{Dir: dir, Record: true}, `"menu"`)
+	if err != nil || got != `{"echo":"menu"}` {
+		t.Fatalf("record = %q, %v", got, err)
+	}
+	srv.Close()
+
+	replay := &Cassette{Dir: dir}
+	if got, err := post(replay, `"menu"`); err != nil || got != `{"echo":"menu"}` {
+		t.Errorf("replay = %q, %v", got, err)
+	}
+	if calls != 1 {
+		t.Errorf("backend calls = %d, want 1", calls)
+	}
+	if _, err := post(replay, `"other menu"`); !errors.Is(err, ErrCassetteMiss) {
+		t.Errorf("unrecorded request err = %v, want ErrCassetteMiss", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("recordings = %v", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	if strings.Contains(string(data), "secret-key") {
+		t.Error("recording contains the Authorization header")
+	}
+}
+
+func TestCassetteReplaysExtractor(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"{\"restaurant_name\":\"Thai Spot\",\"items\":[{\"dish\":\"Pad Thai\",\"description\":\"\",\"stated_ingredients\":[],\"has_full_ingredients\":false}]}"},"finish_reason":"stop"}]}`))
+	}))
+	dir := t.TempDir()
+
+	ex, err := NewOpenAICompatExtractor(srv.URL+"/v1", "test-model", "", "none")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ex.SetTransport(&Cassette{Dir: dir, Record: true})
+	if _, err := ex.Extract(context.Background(), "Pad Thai $14"); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	srv.Close()
+
+	ex.SetTransport(&Cassette{Dir: dir})
+	res, err := ex.Extract(context.Background(), "Pad Thai $14")
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if len(res.Items) != 1 || res.Items[0].DishName != "Pad Thai" {
+		t.Errorf("items = %+v", res.Items)
+	}
+}
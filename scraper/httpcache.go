@@ -0,0 +1,203 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultHTTPCacheDir is where the HTTP content cache lives by default,
+// alongside the raw page snapshots in the bronze layer.
+const DefaultHTTPCacheDir = "data/bronze/httpcache"
+
+// CacheEntry is the metadata stored alongside a cached response body.
+type CacheEntry struct {
+	URL          string    `json:"url"`
+	ContentType  string    `json:"content_type"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	SHA256       string    `json:"sha256"`
+	FetchedAt    time.Time `json:"fetched_at"`
+	// ChangedAt is when the body last differed from the previous fetch.
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// HTTPCache is a disk-backed store of the last response seen for each URL.
+// Each URL is keyed by the SHA-256 of the URL and stored as two files,
+// <key>.json (CacheEntry) and <key>.body, written atomically via rename so
+// concurrent workers never observe a torn entry.
+type HTTPCache struct {
+	dir string
+}
+
+// NewHTTPCache returns a cache rooted at dir. The directory is created on the
+// first Store.
+func NewHTTPCache(dir string) *HTTPCache {
+	return &HTTPCache{dir: dir}
+}
+
+// Load returns the cached entry and body for rawURL. A miss returns a nil
+// entry and a nil error.
+func (c *HTTPCache) Load(rawURL string) (*CacheEntry, []byte, error) {
+	base := c.path(rawURL)
+	meta, err := os.ReadFile(base + ".json")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading cache entry: %w", err)
+	}
+	var entry CacheEntry
+	if err := json.Unmarshal(meta, &entry); err != nil {
+		return nil, nil, fmt.Errorf("decoding cache entry: %w", err)
+	}
+	body, err := os.ReadFile(base + ".body")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading cached body: %w", err)
+	}
+	return &entry, body, nil
+}
+
+// Store records entry and body for entry.URL, replacing any previous entry.
+// The body is written before the metadata so a reader never sees metadata
+// whose body is missing.
+func (c *HTTPCache) Store(entry CacheEntry, body []byte) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("creating cache dir: %w", err)
+	}
+	meta, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding cache entry: %w", err)
+	}
+	base := c.path(entry.URL)
+	if err := writeFileAtomic(base+".body", body); err != nil {
+		return err
+	}
+	return writeFileAtomic(base+".json", meta)
+}
+
+// path returns the cache file path for rawURL, without extension.
+func (c *HTTPCache) path(rawURL string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+// writeFileAtomic writes data to a temp file in path's directory and renames
+// it over path.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("creating temp file: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("closing %s: %w", path, err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("renaming %s: %w", path, err)
+	}
+	return nil
+}
+
+// CachingFetcher puts an HTTPCache in front of a Fetcher. When the wrapped
+// fetcher is a ConditionalFetcher it revalidates cached URLs with
+// If-None-Match / If-Modified-Since and serves the cached body on a 304.
+// Either way it compares the body hash with the cached one, so FetchResult
+// reports NotModified even for servers that send no validators.
+//
+// Cache reads and writes are best-effort: a corrupt or unwritable cache
+// degrades to an uncached fetch rather than failing the scrape.
+type CachingFetcher struct {
+	Fetcher Fetcher
+	Cache   *HTTPCache
+	now     func() time.Time
+}
+
+// NewCachingFetcher wraps f with cache.
+func NewCachingFetcher(f Fetcher, cache *HTTPCache) *CachingFetcher {
+	return &CachingFetcher{Fetcher: f, Cache: cache, now: time.Now}
+}
+
+// Fetch retrieves rawURL through the cache. The returned Body is always
+// fully buffered.
+func (f *CachingFetcher) Fetch(ctx context.Context, rawURL string) (FetchResult, error) {
+	entry, cached, err := f.Cache.Load(rawURL)
+	if err != nil {
+		slog.Warn("http cache: ignoring unreadable entry", "url", rawURL, "err", err)
+		entry, cached = nil, nil
+	}
+
+	var res FetchResult
+	if cf, ok := f.Fetcher.(ConditionalFetcher); ok && entry != nil {
+		res, err = cf.FetchConditional(ctx, rawURL, Validators{ETag: entry.ETag, LastModified: entry.LastModified})
+	} else {
+		res, err = f.Fetcher.Fetch(ctx, rawURL)
+	}
+	if err != nil {
+		return FetchResult{}, err
+	}
+	now := f.now().UTC()
+
+	if res.NotModified && entry != nil {
+		entry.FetchedAt = now
+		f.store(*entry, cached)
+		return FetchResult{
+			Body:         io.NopCloser(bytes.NewReader(cached)),
+			ContentType:  entry.ContentType,
+			ETag:         entry.ETag,
+			LastModified: entry.LastModified,
+			NotModified:  true,
+		}, nil
+	}
+
+	body, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return FetchResult{}, fmt.Errorf("reading body: %w", err)
+	}
+	sum := sha256.Sum256(body)
+	next := CacheEntry{
+		URL:          rawURL,
+		ContentType:  res.ContentType,
+		ETag:         res.ETag,
+		LastModified: res.LastModified,
+		SHA256:       hex.EncodeToString(sum[:]),
+		FetchedAt:    now,
+		ChangedAt:    now,
+	}
+	unchanged := entry != nil && entry.SHA256 == next.SHA256
+	if unchanged {
+		next.ChangedAt = entry.ChangedAt
+	}
+	f.store(next, body)
+
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	res.NotModified = unchanged
+	return res, nil
+}
+
+// store writes entry to the cache, logging rather than failing on error.
+func (f *CachingFetcher) store(entry CacheEntry, body []byte) {
+	if err := f.Cache.Store(entry, body); err != nil {
+		slog.Warn("http cache: failed to store entry", "url", entry.URL, "err", err)
+	}
+}
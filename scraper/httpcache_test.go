@@ -0,0 +1,97 @@
+package scraper
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(t *testing.T, res FetchResult) string {
+	t.Helper()
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	return string(b)
+}
+
+func TestCachingFetcher_ConditionalGET(t *testing.T) {
+	var conditional atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditional.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("<p>menu</p>"))
+	}))
+	defer srv.Close()
+
+	f := NewCachingFetcher(NewHTTPFetcher(false), NewHTTPCache(t.TempDir()))
+
+	first, err := f.Fetch(context.Background(), srv.URL+"/menu")
+	require.NoError(t, err)
+	assert.False(t, first.NotModified, "first fetch is a cache miss")
+	assert.Equal(t, "<p>menu</p>", readAll(t, first))
+
+	second, err := f.Fetch(context.Background(), srv.URL+"/menu")
+	require.NoError(t, err)
+	assert.True(t, second.NotModified)
+	assert.Equal(t, "text/html", second.ContentType)
+	assert.Equal(t, "<p>menu</p>", readAll(t, second), "304 must serve the cached body")
+	assert.Equal(t, int32(1), conditional.Load())
+}
+
+// bodyFetcher is a non-conditional Fetcher returning a settable body.
+type bodyFetcher struct{ body string }
+
+func (b *bodyFetcher) Fetch(_ context.Context, _ string) (FetchResult, error) {
+	return FetchResult{Body: io.NopCloser(strings.NewReader(b.body)), ContentType: "text/html"}, nil
+}
+
+func TestCachingFetcher_HashFallback(t *testing.T) {
+	inner := &bodyFetcher{body: "a"}
+	cache := NewHTTPCache(t.TempDir())
+	f := NewCachingFetcher(inner, cache)
+
+	res, err := f.Fetch(context.Background(), "https://example.com/menu")
+	require.NoError(t, err)
+	assert.False(t, res.NotModified)
+
+	res, err = f.Fetch(context.Background(), "https://example.com/menu")
+	require.NoError(t, err)
+	assert.True(t, res.NotModified, "identical body without validators is unchanged")
+	entry, _, err := cache.Load("https://example.com/menu")
+	require.NoError(t, err)
+	changedAt := entry.ChangedAt
+
+	inner.body = "b"
+	res, err = f.Fetch(context.Background(), "https://example.com/menu")
+	require.NoError(t, err)
+	assert.False(t, res.NotModified)
+	assert.Equal(t, "b", readAll(t, res))
+
+	entry, body, err := cache.Load("https://example.com/menu")
+	require.NoError(t, err)
+	assert.Equal(t, "b", string(body))
+	assert.False(t, entry.ChangedAt.Before(changedAt))
+}
+
+func TestHTTPCache_Miss(t *testing.T) {
+	entry, body, err := NewHTTPCache(t.TempDir()).Load("https://example.com/")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+	assert.Nil(t, body)
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
-	"net/http"
 	"strings"
 	"time"
 
@@ -47,7 +46,7 @@ func NewChromeRenderedFetcher(ctx context.Context, ignoreRobots bool) *ChromeRen
 	)
 	allocCtx, cancel := chromedp.NewExecAllocator(ctx, allocOpts...)
 	return &ChromeRenderedFetcher{
-		HTTPFetcher: HTTPFetcher{Client: &http.Client{Timeout: 30 * time.Second}, IgnoreRobots: ignoreRobots},
+		HTTPFetcher: *NewHTTPFetcher(ignoreRobots),
 		allocCancel: cancel,
 		browserCtx:  allocCtx,
 	}
@@ -0,0 +1,152 @@
+package scraper
+
+import (
+	"strings"
+	"unicode"
+
+	"fodmap/textwords"
+)
+
+// LanguageEnglish is the ISO 639-1 code DetectMenuLanguage returns for menus
+// that need no translation.
+const LanguageEnglish = "en"
+
+// scriptLanguages maps non-Latin scripts to the language a NYC menu written in
+// them is almost always in. Kana outranks Han, so a Japanese menu (which
+// mixes kanji and kana) is not reported as Chinese.
+var scriptLanguages = []struct {
+	table *unicode.RangeTable
+	lang  string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Han, "zh"},
+	{unicode.Greek, "el"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Thai, "th"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Bengali, "bn"},
+}
+
+// latinMarkers are words that mark a Latin-script menu as written in a given
+// language. They are mostly function words and everyday ingredient words:
+// dish names ("tacos al pastor", "penne alla vodka") routinely appear on
+// English menus and must not outvote the English around them.
+var latinMarkers = map[string][]string{
+	"en": {"and", "with", "the", "of", "served", "topped", "our", "choice", "your", "side", "fresh", "fried", "grilled", "chicken", "beef", "pork", "cheese", "sauce"},
+	"es": {"y", "con", "los", "las", "del", "el", "sin", "pollo", "carne", "cerdo", "res", "queso", "arroz", "frijoles", "huevo", "huevos", "ajo", "cebolla", "camarones", "pescado", "papas", "asado", "frito", "frita", "bebidas", "postres"},
+	"it": {"e", "con", "alla", "della", "dello", "il", "di", "ai", "pomodoro", "formaggio", "aglio", "cipolla", "funghi", "pollo", "manzo", "maiale", "bevande", "dolci", "antipasti", "contorni"},
+	"fr": {"et", "avec", "le", "les", "du", "des", "au", "aux", "poulet", "boeuf", "porc", "fromage", "ail", "oignon", "champignons", "frites", "boissons", "desserts", "entrées"},
+	"pt": {"e", "com", "do", "da", "dos", "das", "ao", "frango", "carne", "porco", "queijo", "arroz", "feijão", "alho", "cebola", "batata", "bebidas", "sobremesas"},
+}
+
+// latinLetters flags accented letters that only occur in one of the Latin
+// languages above.
+var latinLetters = map[rune]string{
+	'ñ': "es", '¿': "es", '¡': "es",
+	'ã': "pt", 'õ': "pt",
+	'œ': "fr", 'ê': "fr", 'è': "fr", 'ç': "fr", 'û': "fr",
+	'ì': "it", 'ò': "it",
+}
+
+// DetectMenuLanguage guesses the ISO 639-1 language of a menu from its dish
+// names and descriptions. Menus in a non-Latin script are identified by the
+// script most of their letters are in; Latin-script menus by marker words
+// and accented letters, where another language must clearly outweigh
+// English. It returns LanguageEnglish when no other language stands out and
+// "" when the items hold no text. The guess is a heuristic for deciding what
+// to translate, not a general language identifier.
+func DetectMenuLanguage(items []MenuEntry) string {
+	var b strings.Builder
+	for _, it := range items {
+		b.WriteString(it.DishName)
+		b.WriteByte('\n')
+		b.WriteString(it.Description)
+		b.WriteByte('\n')
+		for _, ing := range it.StatedIngredients {
+			b.WriteString(ing)
+			b.WriteByte('\n')
+		}
+	}
+	return DetectLanguage(b.String())
+}
+
+// DetectLanguage is DetectMenuLanguage for free text.
+func DetectLanguage(text string) string {
+	letters, latin := 0, 0
+	scripts := make(map[string]int)
+	accents := make(map[string]int)
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			if lang, ok := latinLetters[r]; ok {
+				accents[lang]++
+			}
+			continue
+		}
+		letters++
+		if unicode.Is(unicode.Latin, r) {
+			latin++
+			if lang, ok := latinLetters[unicode.ToLower(r)]; ok {
+				accents[lang]++
+			}
+			continue
+		}
+		for _, s := range scriptLanguages {
+			if unicode.Is(s.table, r) {
+				scripts[s.lang]++
+				break
+			}
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+
+	// A menu with a substantial share of non-Latin letters is in that
+	// script's language even when it carries English glosses, as bilingual
+	// menus usually do.
+	if latin*10 < letters*7 {
+		if scripts["ja"] > 0 && scripts["ja"]*10 >= scripts["zh"] {
+			return "ja"
+		}
+		best, bestN := "", 0
+		for _, s := range scriptLanguages {
+			if n := scripts[s.lang]; n > bestN {
+				best, bestN = s.lang, n
+			}
+		}
+		if best != "" {
+			return best
+		}
+	}
+
+	scores := make(map[string]int)
+	for _, w := range textwords.Split(text) {
+		for lang, words := range latinMarkers {
+			for _, m := range words {
+				if w == m {
+					scores[lang]++
+					break
+				}
+			}
+		}
+	}
+	for lang, n := range accents {
+		scores[lang] += 2 * n
+	}
+	best, bestN := LanguageEnglish, 0
+	for _, lang := range []string{"es", "it", "fr", "pt"} {
+		if scores[lang] > bestN {
+			best, bestN = lang, scores[lang]
+		}
+	}
+	// Shared words ("con", "e", "de") lift several Romance languages at
+	// once; the winner must beat English by a wide margin to count.
+	if bestN < 3 || bestN < 2*scores[LanguageEnglish] {
+		return LanguageEnglish
+	}
+	return best
+}
@@ -0,0 +1,41 @@
+package scraper
+
+import "testing"
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name, text, want string
+	}{
+		{"english", "Grilled chicken with rice and steamed vegetables", "en"},
+		{"spanish", "Pollo asado con arroz y frijoles de la casa", "es"},
+		{"italian", "Spaghetti aglio e olio con peperoncino della casa", "it"},
+		{"french", "Poulet rôti avec pommes de terre et crème fraîche", "fr"},
+		{"korean", "김치찌개 돼지고기 두부", "ko"},
+		{"japanese", "とんかつ定食 ご飯と味噌汁付き", "ja"},
+		{"chinese", "宫保鸡丁 花生 辣椒", "zh"},
+		{"greek", "Χωριάτικη σαλάτα με φέτα και ντομάτα", "el"},
+		{"english with borrowed dish names", "Chicken tacos al pastor with pico de gallo", "en"},
+		{"no letters", "12.95 / 14.50", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectLanguage(tt.text); got != tt.want {
+				t.Errorf("DetectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectMenuLanguage(t *testing.T) {
+	items := []MenuEntry{
+		{DishName: "Sopa de ajo", Description: "Caldo con pan y huevo", StatedIngredients: []string{"ajo", "pan", "huevo"}},
+		{DishName: "Tacos", Description: "Tortillas de maíz con cebolla y cilantro"},
+		{DishName: "Flan", Description: "Postre de la casa"},
+	}
+	if got := DetectMenuLanguage(items); got != "es" {
+		t.Errorf("DetectMenuLanguage = %q, want es", got)
+	}
+	if got := DetectMenuLanguage(nil); got != "" {
+		t.Errorf("DetectMenuLanguage(nil) = %q, want empty", got)
+	}
+}
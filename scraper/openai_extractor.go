@@ -37,6 +37,7 @@ type OpenAICompatExtractor struct {
 	APIKey          string
 	ReasoningEffort string
 	schema          json.RawMessage
+	translateSchema json.RawMessage
 	client          *http.Client
 }
 
@@ -48,12 +49,17 @@ func NewOpenAICompatExtractor(baseURL, model, apiKey, reasoningEffort string) (*
 	if err != nil {
 		return nil, fmt.Errorf("building menu schema: %w", err)
 	}
+	translateSchema, err := menuTranslationSchema()
+	if err != nil {
+		return nil, fmt.Errorf("building translation schema: %w", err)
+	}
 	return &OpenAICompatExtractor{
 		BaseURL:         baseURL,
 		Model:           model,
 		APIKey:          apiKey,
 		ReasoningEffort: reasoningEffort,
 		schema:          schema,
+		translateSchema: translateSchema,
 		client:          &http.Client{Timeout: 5 * time.Minute},
 	}, nil
 }
@@ -107,6 +113,12 @@ type chatResponse struct {
 	} `json:"choices"`
 }
 
+// SetTransport replaces the transport of the extractor's HTTP client, e.g.
+// with a Cassette to record or replay LLM responses.
+func (e *OpenAICompatExtractor) SetTransport(rt http.RoundTripper) {
+	e.client.Transport = rt
+}
+
 // Extract sends pageText to the LLM and parses the returned JSON.
 func (e *OpenAICompatExtractor) Extract(ctx context.Context, pageText string) (MenuExtractionResult, error) {
 	pageText = truncateText(pageText, MaxInputChars)
@@ -169,6 +181,26 @@ func (e *OpenAICompatExtractor) chatJSON(ctx context.Context, prompt string, ima
 		content = []contentPart{{Type: "text", Text: prompt}}
 	}
 
+	raw, err := e.chatCompletion(ctx, content, "menu_extraction", e.schema)
+	if err != nil {
+		return MenuExtractionResult{}, err
+	}
+
+	var payload llmMenuPayload
+	if err := json.Unmarshal([]byte(raw), &payload); err != nil {
+		return MenuExtractionResult{}, fmt.Errorf("parse LLM JSON output: %w (raw: %.200s)", err, raw)
+	}
+	return MenuExtractionResult{
+		RestaurantName: payload.RestaurantName,
+		City:           payload.City,
+		State:          payload.State,
+		Items:          payload.Items,
+	}, nil
+}
+
+// chatCompletion sends one user message whose reply is constrained to schema
+// and returns the model's JSON content, which is never empty.
+func (e *OpenAICompatExtractor) chatCompletion(ctx context.Context, content []contentPart, schemaName string, schema json.RawMessage) (string, error) {
 	req := chatRequest{
 		Model: e.Model,
 		Messages: []chatMessage{
@@ -177,9 +209,9 @@ func (e *OpenAICompatExtractor) chatJSON(ctx context.Context, prompt string, ima
 		ResponseFormat: &respFormat{
 			Type: "json_schema",
 			JSONSchema: &jsonSchemaFormat{
-				Name:   "menu_extraction",
+				Name:   schemaName,
 				Strict: true,
-				Schema: e.schema,
+				Schema: schema,
 			},
 		},
 		ReasoningEffort: e.ReasoningEffort,
@@ -187,13 +219,13 @@ func (e *OpenAICompatExtractor) chatJSON(ctx context.Context, prompt string, ima
 
 	body, err := json.Marshal(req)
 	if err != nil {
-		return MenuExtractionResult{}, fmt.Errorf("marshal request: %w", err)
+		return "", fmt.Errorf("marshal request: %w", err)
 	}
 
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost,
 		e.BaseURL+"/chat/completions", bytes.NewReader(body))
 	if err != nil {
-		return MenuExtractionResult{}, fmt.Errorf("build request: %w", err)
+		return "", fmt.Errorf("build request: %w", err)
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 	if e.APIKey != "" {
@@ -202,21 +234,21 @@ func (e *OpenAICompatExtractor) chatJSON(ctx context.Context, prompt string, ima
 
 	resp, err := e.client.Do(httpReq)
 	if err != nil {
-		return MenuExtractionResult{}, fmt.Errorf("LLM request: %w", err)
+		return "", fmt.Errorf("LLM request: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
 		b, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
-		return MenuExtractionResult{}, fmt.Errorf("LLM returned status %d: %s", resp.StatusCode, string(b))
+		return "", fmt.Errorf("LLM returned status %d: %s", resp.StatusCode, string(b))
 	}
 
 	var chatResp chatResponse
 	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
-		return MenuExtractionResult{}, fmt.Errorf("decode LLM response: %w", err)
+		return "", fmt.Errorf("decode LLM response: %w", err)
 	}
 	if len(chatResp.Choices) == 0 {
-		return MenuExtractionResult{}, fmt.Errorf("LLM returned no choices")
+		return "", fmt.Errorf("LLM returned no choices")
 	}
 
 	choice := chatResp.Choices[0]
@@ -236,29 +268,19 @@ func (e *OpenAICompatExtractor) chatJSON(ctx context.Context, prompt string, ima
 
 	if strings.TrimSpace(raw) == "" {
 		if reasoning != "" {
-			return MenuExtractionResult{}, fmt.Errorf(
+			return "", fmt.Errorf(
 				"LLM returned reasoning but empty content (finish_reason: %q); "+
 					"some models (e.g. Gemma family on Ollama) emit all output in the reasoning channel — "+
 					"try --llm-reasoning-effort=low or restart Ollama with --reasoning-parser deepseek_r1",
 				choice.FinishReason,
 			)
 		}
-		return MenuExtractionResult{}, fmt.Errorf(
+		return "", fmt.Errorf(
 			"LLM returned an empty response (finish_reason: %q). This usually happens when the restaurant menu is too large and exceeds the model's context window.\n\n"+
 				"If you are using Ollama locally, you can fix this by increasing its context window memory. Stop your current Ollama server and restart it with:\n"+
 				"  OLLAMA_NUM_CTX=16384 ollama serve --reasoning-parser deepseek_r1",
 			choice.FinishReason,
 		)
 	}
-
-	var payload llmMenuPayload
-	if err := json.Unmarshal([]byte(raw), &payload); err != nil {
-		return MenuExtractionResult{}, fmt.Errorf("parse LLM JSON output: %w (raw: %.200s)", err, raw)
-	}
-	return MenuExtractionResult{
-		RestaurantName: payload.RestaurantName,
-		City:           payload.City,
-		State:          payload.State,
-		Items:          payload.Items,
-	}, nil
+	return raw, nil
 }
@@ -0,0 +1,607 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+)
+
+// ErrNoPlatformMenu is returned by a PlatformAdapter when the page matched
+// its platform but carried no menu it could read, e.g. a JS shell whose
+// state is fetched after load. Callers fall through to the generic cascade.
+var ErrNoPlatformMenu = errors.New("no menu found in platform page")
+
+// PlatformAdapter reads menus straight from a known ordering or delivery
+// platform — the state JSON its pages embed for hydration, or its public
+// menu API — instead of sending the rendered page to the LLM. Those pages
+// are mostly JS shells, and where they do carry text it is cart and checkout
+// noise; the embedded state has every item with its price and modifiers.
+type PlatformAdapter interface {
+	// Name identifies the platform in logs, e.g. "toast".
+	Name() string
+	// Match reports whether u is a menu page on this platform.
+	Match(u *url.URL) bool
+	// ExtractMenu returns the menu in body, the page fetched from pageURL.
+	// f may be used to call the platform's menu API. It returns
+	// ErrNoPlatformMenu when no menu is found.
+	ExtractMenu(ctx context.Context, f Fetcher, pageURL *url.URL, body []byte) ([]MenuEntry, error)
+}
+
+var (
+	platformMu       sync.RWMutex
+	platformAdapters = []PlatformAdapter{
+		&stateAdapter{
+			name:    "toast",
+			hosts:   []string{"toasttab.com"},
+			globals: []string{"__OO_STATE__", "__APOLLO_STATE__"},
+		},
+		&stateAdapter{
+			name:    "square",
+			hosts:   []string{"square.site", "squareup.com"},
+			globals: []string{"__BOOTSTRAP_STATE__", "__INITIAL_STATE__"},
+		},
+		&stateAdapter{
+			name:    "clover",
+			hosts:   []string{"clover.com"},
+			globals: []string{"__PRELOADED_STATE__", "__INITIAL_STATE__"},
+			cents:   true,
+		},
+		&stateAdapter{
+			name:    "chownow",
+			hosts:   []string{"chownow.com"},
+			globals: []string{"__PRELOADED_STATE__", "__INITIAL_STATE__"},
+			apiURL:  chowNowMenuAPI,
+		},
+		&stateAdapter{
+			name:      "doordash",
+			hosts:     []string{"doordash.com"},
+			scriptIDs: []string{"__NEXT_DATA__"},
+			globals:   []string{"__APOLLO_STATE__"},
+			cents:     true,
+		},
+		&stateAdapter{
+			name:    "grubhub",
+			hosts:   []string{"grubhub.com", "seamless.com"},
+			globals: []string{"__PRELOADED_STATE__", "__INITIAL_STATE__"},
+			cents:   true,
+		},
+		&stateAdapter{
+			name:      "ubereats",
+			hosts:     []string{"ubereats.com", "postmates.com"},
+			scriptIDs: []string{"__REACT_QUERY_STATE__", "__NEXT_DATA__"},
+			cents:     true,
+		},
+	}
+)
+
+// RegisterPlatformAdapter adds a to the registry consulted by
+// PlatformAdapterFor. Adapters registered later take precedence, so a more
+// specific adapter can override a built-in one for the same host.
+func RegisterPlatformAdapter(a PlatformAdapter) {
+	platformMu.Lock()
+	defer platformMu.Unlock()
+	platformAdapters = append(platformAdapters, a)
+}
+
+// PlatformAdapterFor returns the adapter for rawURL's platform, or nil when
+// the URL is not on a recognised platform.
+func PlatformAdapterFor(rawURL string) PlatformAdapter {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	platformMu.RLock()
+	defer platformMu.RUnlock()
+	for i := len(platformAdapters) - 1; i >= 0; i-- {
+		if platformAdapters[i].Match(u) {
+			return platformAdapters[i]
+		}
+	}
+	return nil
+}
+
+// stateAdapter is a PlatformAdapter for platforms whose pages embed their
+// menu as JSON, either in a <script id=...> element or assigned to a window
+// global. The state layouts differ between platforms and change without
+// notice, so rather than hard-coding paths it walks the JSON for objects
+// shaped like menu sections and items (see menuFromState).
+type stateAdapter struct {
+	name      string
+	hosts     []string
+	scriptIDs []string // <script id=...> elements holding JSON
+	globals   []string // window globals assigned a JSON literal or JSON.parse("...")
+	// cents marks platforms that store numeric prices in minor units.
+	cents bool
+	// apiURL, when set, maps a page URL to the platform's public menu API,
+	// tried when the page carries no usable state.
+	apiURL func(u *url.URL) string
+}
+
+func (a *stateAdapter) Name() string { return a.name }
+
+func (a *stateAdapter) Match(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	for _, h := range a.hosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *stateAdapter) ExtractMenu(ctx context.Context, f Fetcher, pageURL *url.URL, body []byte) ([]MenuEntry, error) {
+	for _, state := range embeddedStates(body, a.scriptIDs, a.globals) {
+		if items := menuFromState(state, a.cents); len(items) > 0 {
+			return items, nil
+		}
+	}
+	if a.apiURL == nil || f == nil {
+		return nil, ErrNoPlatformMenu
+	}
+	apiURL := a.apiURL(pageURL)
+	if apiURL == "" {
+		return nil, ErrNoPlatformMenu
+	}
+	res, err := f.Fetch(ctx, apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("%s menu API: %w", a.name, err)
+	}
+	defer func() { _ = res.Body.Close() }()
+	var state any
+	if err := json.NewDecoder(io.LimitReader(res.Body, MaxBodyBytes)).Decode(&state); err != nil {
+		return nil, fmt.Errorf("%s menu API: decoding: %w", a.name, err)
+	}
+	if items := menuFromState(state, a.cents); len(items) > 0 {
+		return items, nil
+	}
+	return nil, ErrNoPlatformMenu
+}
+
+// chowNowLocationRe matches the location ID in ChowNow ordering URLs such as
+// https://direct.chownow.com/order/1234/locations/5678.
+var chowNowLocationRe = regexp.MustCompile(`/locations/(\d+)`)
+
+// chowNowMenuAPI returns ChowNow's public menu endpoint for a location page.
+func chowNowMenuAPI(u *url.URL) string {
+	m := chowNowLocationRe.FindStringSubmatch(u.Path)
+	if m == nil {
+		return ""
+	}
+	return "https://api.chownow.com/api/restaurant/" + m[1] + "/menu"
+}
+
+// embeddedStates returns the decoded JSON held by the named <script id=...>
+// elements and window globals in body, in that order.
+func embeddedStates(body []byte, scriptIDs, globals []string) []any {
+	var states []any
+	if len(scriptIDs) > 0 {
+		if doc, err := html.Parse(bytes.NewReader(body)); err == nil {
+			for _, id := range scriptIDs {
+				text := scriptTextByID(doc, id)
+				if text == "" {
+					continue
+				}
+				if v, ok := decodeStateJSON(text); ok {
+					states = append(states, v)
+				}
+			}
+		}
+	}
+	for _, name := range globals {
+		if v, ok := globalState(body, name); ok {
+			states = append(states, v)
+		}
+	}
+	return states
+}
+
+// scriptTextByID returns the text of the first <script> element with id.
+func scriptTextByID(n *html.Node, id string) string {
+	if n.Type == html.ElementNode && n.Data == "script" {
+		for _, a := range n.Attr {
+			if a.Key == "id" && a.Val == id {
+				if n.FirstChild != nil {
+					return n.FirstChild.Data
+				}
+				return ""
+			}
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if s := scriptTextByID(c, id); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// decodeStateJSON parses a script's JSON, which some platforms ship
+// percent-encoded.
+func decodeStateJSON(text string) (any, bool) {
+	text = strings.TrimSpace(text)
+	var v any
+	if err := json.Unmarshal([]byte(text), &v); err == nil {
+		return v, true
+	}
+	if unescaped, err := url.QueryUnescape(text); err == nil {
+		if err := json.Unmarshal([]byte(unescaped), &v); err == nil {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// globalState finds `name = {...}` or `name = JSON.parse("...")` in body and
+// decodes the value. The literal must be valid JSON; JS-only syntax such as
+// undefined or trailing commas makes it unreadable.
+func globalState(body []byte, name string) (any, bool) {
+	rest := body
+	for {
+		i := bytes.Index(rest, []byte(name))
+		if i < 0 {
+			return nil, false
+		}
+		rest = rest[i+len(name):]
+		after := bytes.TrimLeft(rest, " \t\r\n\"']")
+		if len(after) == 0 || after[0] != '=' {
+			continue
+		}
+		after = bytes.TrimLeft(after[1:], " \t\r\n")
+		if p, ok := bytes.CutPrefix(after, []byte("JSON.parse(")); ok {
+			var s string
+			if err := json.NewDecoder(bytes.NewReader(bytes.TrimLeft(p, " \t\r\n"))).Decode(&s); err != nil {
+				continue
+			}
+			var v any
+			if err := json.Unmarshal([]byte(s), &v); err == nil {
+				return v, true
+			}
+			continue
+		}
+		var v any
+		if err := json.NewDecoder(bytes.NewReader(after)).Decode(&v); err == nil {
+			return v, true
+		}
+	}
+}
+
+// Keys the state walker recognises, in order of preference.
+var (
+	stateNameKeys        = []string{"name", "title", "displayName", "itemName"}
+	stateDescriptionKeys = []string{"description", "itemDescription", "short_description", "desc"}
+	statePriceKeys       = []string{"price", "displayPrice", "basePrice", "unitPrice", "priceMoney", "price_money"}
+	moneyCurrencyKeys    = []string{"currency", "currencyCode", "currency_code"}
+	stateItemListKeys    = []string{"items", "menuItems", "menu_items", "catalogItems", "products", "hasMenuItem", "dishes"}
+	stateModifierKeys    = []string{"modifierGroups", "modifier_groups", "modifierCategories", "modifier_categories", "optionGroups", "option_groups", "customizationsList", "customizations", "modifiers", "options"}
+	stateOptionListKeys  = []string{"modifiers", "options", "choices", "items", "values"}
+)
+
+// featuredSections are promotional sections that repeat items from the real
+// menu. Their items are kept unsectioned so the real section wins on dedup.
+var featuredSections = map[string]bool{
+	"popular":        true,
+	"popular items":  true,
+	"most ordered":   true,
+	"most popular":   true,
+	"featured":       true,
+	"featured items": true,
+	"recommended":    true,
+	"picked for you": true,
+	"best sellers":   true,
+}
+
+// menuFromState walks platform state JSON for menu sections — objects with
+// a name and a list of named items — and returns their items. Arrays are
+// walked in order and object keys sorted, so the result is stable. When no section is found it falls back to loose objects with both
+// a name and a price. Numeric prices are read as cents when cents is set;
+// strings such as "$12.50" are always dollars. Items seen more than once,
+// as normalised caches tend to hold them, are returned once; the {"__ref":
+// key} pointers of an Apollo cache are first replaced by the objects they
+// name (see resolveRefs).
+func menuFromState(state any, cents bool) []MenuEntry {
+	if root, ok := state.(map[string]any); ok {
+		state = resolveRefs(root)
+	}
+	w := stateWalker{cents: cents, seen: make(map[string]int)}
+	w.walk(state, false)
+	if len(w.items) == 0 {
+		w.walk(state, true)
+	}
+	return w.items
+}
+
+// resolveRefs returns root with every {"__ref": "Type:id"} object, as an
+// Apollo normalised cache (Toast's and DoorDash's __APOLLO_STATE__) links
+// its entities, replaced by root's entry for that key. A section then holds
+// its items rather than pointers to them. Entries are resolved once each,
+// in key order, and shared; a ref back to an entry still being resolved is
+// left as it is, so cycles end. A ref to a missing key is left too.
+func resolveRefs(root map[string]any) map[string]any {
+	resolved := make(map[string]any, len(root))
+	resolving := make(map[string]bool)
+	var resolve func(v any) any
+	entry := func(key string) (any, bool) {
+		if r, ok := resolved[key]; ok {
+			return r, true
+		}
+		target, ok := root[key]
+		if !ok || resolving[key] {
+			return nil, false
+		}
+		resolving[key] = true
+		r := resolve(target)
+		delete(resolving, key)
+		resolved[key] = r
+		return r, true
+	}
+	resolve = func(v any) any {
+		switch t := v.(type) {
+		case []any:
+			out := make([]any, len(t))
+			for i, e := range t {
+				out[i] = resolve(e)
+			}
+			return out
+		case map[string]any:
+			if key, ok := t["__ref"].(string); ok && len(t) == 1 {
+				if r, ok := entry(key); ok {
+					return r
+				}
+				return t
+			}
+			out := make(map[string]any, len(t))
+			for k, e := range t {
+				out[k] = resolve(e)
+			}
+			return out
+		}
+		return v
+	}
+	for _, k := range sortedKeys(root) {
+		entry(k)
+	}
+	return resolved
+}
+
+type stateWalker struct {
+	cents bool
+	items []MenuEntry
+	seen  map[string]int // dedup key → index in items
+}
+
+func (w *stateWalker) walk(v any, loose bool) {
+	switch t := v.(type) {
+	case []any:
+		for _, e := range t {
+			w.walk(e, loose)
+		}
+	case map[string]any:
+		if !loose {
+			if section, items, ok := w.section(t); ok {
+				if featuredSections[strings.ToLower(section)] {
+					section = ""
+				}
+				for _, it := range items {
+					w.add(section, it)
+				}
+				return
+			}
+		} else if it, ok := w.item(t, true); ok {
+			w.add("", it)
+			return
+		}
+		for _, k := range sortedKeys(t) {
+			w.walk(t[k], loose)
+		}
+	}
+}
+
+// section reports whether m is a named section listing at least one priced
+// item, and returns its items. Unpriced items are kept inside a section
+// ("market price"), since the section already marks them as menu items.
+func (w *stateWalker) section(m map[string]any) (string, []MenuEntry, bool) {
+	name := stateString(m, stateNameKeys)
+	if name == "" {
+		return "", nil, false
+	}
+	for _, k := range stateItemListKeys {
+		list, ok := m[k].([]any)
+		if !ok {
+			continue
+		}
+		var items []MenuEntry
+		priced := false
+		for _, e := range list {
+			em, ok := e.(map[string]any)
+			if !ok {
+				continue
+			}
+			if it, ok := w.item(em, false); ok {
+				priced = priced || it.Price != nil
+				items = append(items, it)
+			}
+		}
+		if priced {
+			return name, items, true
+		}
+	}
+	return "", nil, false
+}
+
+// item reads m as a menu item. requirePrice rejects unpriced objects, which
+// outside a section are as likely to be categories or banners as dishes.
+func (w *stateWalker) item(m map[string]any, requirePrice bool) (MenuEntry, bool) {
+	name := stateString(m, stateNameKeys)
+	if name == "" {
+		return MenuEntry{}, false
+	}
+	price := w.price(m)
+	if requirePrice && price == nil {
+		return MenuEntry{}, false
+	}
+	return MenuEntry{
+		DishName:    name,
+		Description: stateString(m, stateDescriptionKeys),
+		Price:       price,
+		Modifiers:   w.modifiers(m),
+	}, true
+}
+
+// modifiers flattens an item's modifier groups into their options. A list of
+// priced options with no groups is read directly.
+func (w *stateWalker) modifiers(m map[string]any) []Modifier {
+	var out []Modifier
+	for _, k := range stateModifierKeys {
+		groups, ok := m[k].([]any)
+		if !ok {
+			continue
+		}
+		for _, g := range groups {
+			gm, ok := g.(map[string]any)
+			if !ok {
+				continue
+			}
+			nested := false
+			for _, lk := range stateOptionListKeys {
+				opts, isList := gm[lk].([]any)
+				if !isList {
+					continue
+				}
+				nested = true
+				for _, o := range opts {
+					if om, isMap := o.(map[string]any); isMap {
+						if name := stateString(om, stateNameKeys); name != "" {
+							out = append(out, Modifier{Name: name, Price: w.price(om)})
+						}
+					}
+				}
+				break
+			}
+			if !nested {
+				if name := stateString(gm, stateNameKeys); name != "" {
+					out = append(out, Modifier{Name: name, Price: w.price(gm)})
+				}
+			}
+		}
+		if len(out) > 0 {
+			break
+		}
+	}
+	return out
+}
+
+// price reads the first price key of m: a number, a string such as "$12.50",
+// or an object carrying the amount (Square's {low, high}, or a Money object
+// such as Square's priceMoney or Grubhub's {amount, currency}).
+func (w *stateWalker) price(m map[string]any) *float64 {
+	for _, k := range statePriceKeys {
+		if p := priceValue(m[k], w.cents); p != nil {
+			return p
+		}
+	}
+	return nil
+}
+
+// priceValue reads v as a price, dividing numeric amounts by 100 when cents
+// is set.
+func priceValue(v any, cents bool) *float64 {
+	switch t := v.(type) {
+	case float64:
+		if t < 0 {
+			return nil
+		}
+		if cents {
+			t /= 100
+		}
+		return &t
+	case string:
+		s := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(t), "$"))
+		f, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+		if err != nil || f < 0 {
+			return nil
+		}
+		if cents && !strings.Contains(t, ".") && !strings.Contains(t, "$") {
+			f /= 100
+		}
+		return &f
+	case map[string]any:
+		// A Money object holds its amount in minor units whatever the
+		// platform's other prices use: {"amount": 1250, "currency": "USD"}
+		// is $12.50.
+		if amount, ok := t["amount"]; ok && stateString(t, moneyCurrencyKeys) != "" {
+			return priceValue(amount, true)
+		}
+		for _, k := range []string{"amount", "value", "low", "regular_low", "unitAmount"} {
+			if p := priceValue(t[k], cents); p != nil {
+				return p
+			}
+		}
+	}
+	return nil
+}
+
+func (w *stateWalker) add(section string, it MenuEntry) {
+	it.Section = section
+	key := strings.ToLower(it.DishName) + "\x00" + formatPrice(it.Price)
+	if i, ok := w.seen[key]; ok {
+		if w.items[i].Section == "" || w.items[i].Section == section {
+			if w.items[i].Section == "" {
+				w.items[i].Section = section
+			}
+			if len(w.items[i].Modifiers) == 0 {
+				w.items[i].Modifiers = it.Modifiers
+			}
+			return
+		}
+		if section == "" {
+			return
+		}
+	}
+	w.seen[key] = len(w.items)
+	w.items = append(w.items, it)
+}
+
+func formatPrice(p *float64) string {
+	if p == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*p, 'f', 2, 64)
+}
+
+// stateString returns the first non-empty string among keys. A value may be
+// a plain string or an object with a "text" field, as Uber Eats nests titles.
+func stateString(m map[string]any, keys []string) string {
+	for _, k := range keys {
+		switch v := m[k].(type) {
+		case string:
+			if s := strings.TrimSpace(v); s != "" {
+				return s
+			}
+		case map[string]any:
+			if s, ok := v["text"].(string); ok && strings.TrimSpace(s) != "" {
+				return strings.TrimSpace(s)
+			}
+		}
+	}
+	return ""
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
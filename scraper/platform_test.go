@@ -0,0 +1,221 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readPlatformFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", "platforms", name))
+	require.NoError(t, err)
+	return b
+}
+
+func extractPlatformFixture(t *testing.T, pageURL, fixture string, f Fetcher) ([]MenuEntry, error) {
+	t.Helper()
+	a := PlatformAdapterFor(pageURL)
+	require.NotNil(t, a, "no adapter for %s", pageURL)
+	u, err := url.Parse(pageURL)
+	require.NoError(t, err)
+	return a.ExtractMenu(context.Background(), f, u, readPlatformFixture(t, fixture))
+}
+
+func TestPlatformAdapterFor(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://www.toasttab.com/kopitiam-nyc/v3", "toast"},
+		{"https://order.toasttab.com/online/kopitiam", "toast"},
+		{"https://cafe-luna.square.site/", "square"},
+		{"https://www.clover.com/online-ordering/golden-dumpling", "clover"},
+		{"https://direct.chownow.com/order/1234/locations/5678", "chownow"},
+		{"https://www.doordash.com/store/sals-pizzeria-new-york-24681/", "doordash"},
+		{"https://www.grubhub.com/restaurant/pho-bac-1/998877", "grubhub"},
+		{"https://www.seamless.com/menu/pho-bac-1/998877", "grubhub"},
+		{"https://www.ubereats.com/store/taqueria-ramirez/abc", "ubereats"},
+		{"https://kopitiamnyc.com/menu", ""},
+		{"https://nottoasttab.com/menu", ""},
+		{"not a url", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			a := PlatformAdapterFor(tt.url)
+			if tt.want == "" {
+				assert.Nil(t, a)
+				return
+			}
+			require.NotNil(t, a)
+			assert.Equal(t, tt.want, a.Name())
+		})
+	}
+}
+
+func TestPlatformAdapter_Toast(t *testing.T) {
+	items, err := extractPlatformFixture(t, "https://www.toasttab.com/kopitiam-nyc/v3", "toast.html", nil)
+	require.NoError(t, err)
+	require.Len(t, items, 4, "cart fees must not be read as items")
+
+	assert.Equal(t, "Kaya Butter Toast", items[0].DishName)
+	assert.Equal(t, "Toast & Eggs", items[0].Section)
+	assert.Equal(t, "Pandan coconut jam, salted butter", items[0].Description)
+	require.NotNil(t, items[0].Price)
+	assert.InDelta(t, 6.5, *items[0].Price, 1e-9)
+	require.Len(t, items[0].Modifiers, 2)
+	assert.Equal(t, "Soft-boiled eggs", items[0].Modifiers[0].Name)
+	assert.InDelta(t, 3.0, *items[0].Modifiers[0].Price, 1e-9)
+
+	assert.Equal(t, "Noodles", items[2].Section)
+	assert.Equal(t, "Market Fish Laksa", items[3].DishName)
+	assert.Nil(t, items[3].Price, "market-price item keeps a nil price")
+	for _, it := range items {
+		assert.Empty(t, it.StatedIngredients, "descriptions are not ingredient lists")
+		assert.False(t, it.HasFullIngredients)
+	}
+}
+
+func TestPlatformAdapter_ToastApolloRefs(t *testing.T) {
+	items, err := extractPlatformFixture(t, "https://www.toasttab.com/izakaya-rin/v3", "toast_apollo.html", nil)
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+
+	assert.Equal(t, "Charred Shishitos", items[0].DishName)
+	assert.Equal(t, "Small Plates", items[0].Section, "groups list their items by __ref")
+	assert.InDelta(t, 9.0, *items[0].Price, 1e-9)
+	require.Len(t, items[0].Modifiers, 2, "modifier groups and options are refs too")
+	assert.Equal(t, "Bonito flakes", items[0].Modifiers[0].Name)
+	assert.Equal(t, "Chicken Karaage", items[1].DishName)
+	assert.Equal(t, "Small Plates", items[1].Section)
+	assert.Equal(t, "Cold Soba", items[2].DishName)
+	assert.Equal(t, "Noodles", items[2].Section)
+}
+
+func TestResolveRefs_Cycle(t *testing.T) {
+	root := map[string]any{
+		"A:1":  map[string]any{"name": "a", "next": map[string]any{"__ref": "B:1"}},
+		"B:1":  map[string]any{"name": "b", "next": map[string]any{"__ref": "A:1"}},
+		"ROOT": map[string]any{"start": map[string]any{"__ref": "A:1"}, "missing": map[string]any{"__ref": "C:1"}},
+	}
+	got := resolveRefs(root)["ROOT"].(map[string]any)
+	a := got["start"].(map[string]any)
+	b := a["next"].(map[string]any)
+	assert.Equal(t, "b", b["name"])
+	assert.Equal(t, map[string]any{"__ref": "A:1"}, b["next"], "a ref back into the cycle is left unresolved")
+	assert.Equal(t, map[string]any{"__ref": "C:1"}, got["missing"], "a dangling ref is left as it is")
+}
+
+func TestPlatformAdapter_DoorDash(t *testing.T) {
+	items, err := extractPlatformFixture(t, "https://www.doordash.com/store/sals-pizzeria-new-york-24681/", "doordash.html", nil)
+	require.NoError(t, err)
+	require.Len(t, items, 3, "items repeated under Most Ordered are returned once")
+
+	assert.Equal(t, "Plain Slice", items[0].DishName)
+	assert.Equal(t, "Pizza", items[0].Section, "featured sections yield to the real section")
+	assert.InDelta(t, 3.5, *items[0].Price, 1e-9, "numeric prices are cents")
+	assert.Equal(t, "Grandma Pie", items[1].DishName)
+	assert.InDelta(t, 28.0, *items[1].Price, 1e-9)
+	assert.Equal(t, "Garlic Knots", items[2].DishName)
+	assert.InDelta(t, 6.95, *items[2].Price, 1e-9, "display prices are dollars")
+}
+
+func TestPlatformAdapter_UberEats(t *testing.T) {
+	items, err := extractPlatformFixture(t, "https://www.ubereats.com/store/taqueria-ramirez/abc", "ubereats.html", nil)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "Suadero", items[0].DishName)
+	assert.Equal(t, "Tacos", items[0].Section)
+	assert.Equal(t, "Brisket confit, onion, cilantro", items[0].Description)
+	assert.InDelta(t, 5.0, *items[0].Price, 1e-9)
+}
+
+func TestPlatformAdapter_Square(t *testing.T) {
+	items, err := extractPlatformFixture(t, "https://cafe-luna.square.site/", "square.html", nil)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "Cortado", items[0].DishName)
+	assert.Equal(t, "Coffee", items[0].Section)
+	assert.Equal(t, "Double espresso, steamed milk", items[0].Description)
+	assert.InDelta(t, 4.25, *items[0].Price, 1e-9)
+	require.Len(t, items[0].Modifiers, 2)
+	assert.Equal(t, "Oat milk", items[0].Modifiers[0].Name)
+	assert.InDelta(t, 0.75, *items[0].Modifiers[0].Price, 1e-9)
+	assert.Nil(t, items[0].Modifiers[1].Price)
+}
+
+func TestPlatformAdapter_SquareMoney(t *testing.T) {
+	items, err := extractPlatformFixture(t, "https://bodega-verde.square.site/", "square_money.html", nil)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "Cubano", items[0].DishName)
+	assert.Equal(t, "Sandwiches", items[0].Section)
+	assert.InDelta(t, 12.50, *items[0].Price, 1e-9)
+	require.Len(t, items[0].Modifiers, 1)
+	assert.InDelta(t, 0.75, *items[0].Modifiers[0].Price, 1e-9)
+	assert.InDelta(t, 6.00, *items[1].Price, 1e-9)
+}
+
+func TestPlatformAdapter_Clover(t *testing.T) {
+	items, err := extractPlatformFixture(t, "https://www.clover.com/online-ordering/golden-dumpling", "clover.html", nil)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "Pork & Chive Dumplings (8)", items[0].DishName)
+	assert.InDelta(t, 10.95, *items[0].Price, 1e-9)
+	require.Len(t, items[0].Modifiers, 2)
+	assert.Equal(t, "Pan-fried", items[0].Modifiers[1].Name)
+	assert.InDelta(t, 1.5, *items[0].Modifiers[1].Price, 1e-9)
+}
+
+func TestPlatformAdapter_ChowNowAPI(t *testing.T) {
+	f := &recordingFetcher{stubFetcher: stubFetcher{body: string(readPlatformFixture(t, "chownow_menu.json")), contentType: "application/json"}}
+	shell := "https://direct.chownow.com/order/1234/locations/5678"
+	a := PlatformAdapterFor(shell)
+	u, _ := url.Parse(shell)
+	items, err := a.ExtractMenu(context.Background(), f, u, []byte(`<html><body><div id="root"></div></body></html>`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://api.chownow.com/api/restaurant/5678/menu"}, f.urls)
+	require.Len(t, items, 2)
+	assert.Equal(t, "Scallion Pancake", items[0].DishName)
+	assert.Equal(t, "Appetizers", items[0].Section)
+	assert.InDelta(t, 7.5, *items[0].Price, 1e-9)
+	require.Len(t, items[0].Modifiers, 2)
+	assert.Equal(t, "Chili oil", items[0].Modifiers[1].Name)
+}
+
+func TestPlatformAdapter_ShellWithoutMenu(t *testing.T) {
+	_, err := extractPlatformFixture(t, "https://www.grubhub.com/restaurant/pho-bac-1/998877", "grubhub_shell.html", nil)
+	assert.True(t, errors.Is(err, ErrNoPlatformMenu), "got %v", err)
+}
+
+func TestRegisterPlatformAdapter_Overrides(t *testing.T) {
+	platformMu.RLock()
+	saved := append([]PlatformAdapter(nil), platformAdapters...)
+	platformMu.RUnlock()
+	t.Cleanup(func() {
+		platformMu.Lock()
+		platformAdapters = saved
+		platformMu.Unlock()
+	})
+
+	RegisterPlatformAdapter(&stateAdapter{name: "toast-v2", hosts: []string{"order.toasttab.com"}})
+	assert.Equal(t, "toast-v2", PlatformAdapterFor("https://order.toasttab.com/online/kopitiam").Name())
+	assert.Equal(t, "toast", PlatformAdapterFor("https://www.toasttab.com/kopitiam").Name())
+}
+
+// recordingFetcher is a stubFetcher that records the URLs it was asked for.
+type recordingFetcher struct {
+	stubFetcher
+	urls []string
+}
+
+func (r *recordingFetcher) Fetch(ctx context.Context, rawURL string) (FetchResult, error) {
+	r.urls = append(r.urls, rawURL)
+	return r.stubFetcher.Fetch(ctx, rawURL)
+}
@@ -21,14 +21,37 @@ type llmMenuPayload struct {
 // (ExpandedStruct: true) so the root struct is not a $ref. Nested types retain
 // $ref/$defs, which Gemini's OpenAI-compat endpoint accepts (verified).
 func menuExtractionSchema() (json.RawMessage, error) {
+	return reflectSchema(&llmMenuPayload{}, "menu")
+}
+
+// llmTranslationPayload is the schema the LLM must emit when translating a
+// menu. Index echoes the position of the entry in the request so a dropped
+// or reordered entry cannot shift translations onto the wrong dish.
+type llmTranslationPayload struct {
+	Items []llmTranslatedEntry `json:"items" jsonschema:"required"`
+}
+
+type llmTranslatedEntry struct {
+	Index             int      `json:"index"`
+	DishName          string   `json:"dish"`
+	Description       string   `json:"description"`
+	StatedIngredients []string `json:"stated_ingredients"`
+}
+
+// menuTranslationSchema returns a JSON Schema for llmTranslationPayload.
+func menuTranslationSchema() (json.RawMessage, error) {
+	return reflectSchema(&llmTranslationPayload{}, "translation")
+}
+
+func reflectSchema(v any, name string) (json.RawMessage, error) {
 	r := jsonschema.Reflector{
 		ExpandedStruct:            true,
 		AllowAdditionalProperties: false,
 	}
-	s := r.Reflect(&llmMenuPayload{})
+	s := r.Reflect(v)
 	b, err := json.Marshal(s)
 	if err != nil {
-		return nil, fmt.Errorf("marshal menu schema: %w", err)
+		return nil, fmt.Errorf("marshal %s schema: %w", name, err)
 	}
 	return b, nil
 }
@@ -14,9 +14,13 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 
+	"fodmap/crawlpolicy"
+	"fodmap/egress"
+
 	"golang.org/x/net/html"
 	"golang.org/x/net/html/charset"
 
@@ -54,6 +58,18 @@ type MenuEntry struct {
 	StatedIngredients  []string   `json:"stated_ingredients"`
 	HasFullIngredients bool       `json:"has_full_ingredients"`
 	Modifiers          []Modifier `json:"modifiers,omitempty"`
+	// Translation is the English rendering of an entry from a non-English
+	// menu, filled in after extraction by a MenuTranslator. It is not part of
+	// the LLM extraction schema.
+	Translation *EntryTranslation `json:"translation,omitempty" jsonschema:"-"`
+}
+
+// EntryTranslation holds the English dish name, description and stated
+// ingredients of a MenuEntry; the entry keeps the menu's original wording.
+type EntryTranslation struct {
+	DishName          string   `json:"dish"`
+	Description       string   `json:"description"`
+	StatedIngredients []string `json:"stated_ingredients"`
 }
 
 // MenuExtractionResult is the structured output of the scrape pipeline.
@@ -71,12 +87,28 @@ type MenuExtractionResult struct {
 	// metadata, not part of the service menu document — set by ExtractMenu and
 	// persisted per scrape for tier-mix telemetry. See pipeline.Tier* constants.
 	ExtractionTier string `json:"extraction_tier,omitempty"`
+	// Language is the ISO 639-1 code of the menu text as guessed by
+	// DetectMenuLanguage ("en", "es", "zh", ...); "" when unknown.
+	Language string `json:"language,omitempty"`
+	// Latitude and Longitude locate the restaurant. Like ExtractionTier they
+	// are pipeline metadata, filled in from the restaurants row by whoever
+	// stores the menu so that every stored dish carries them; nil when the
+	// restaurant has no coordinates.
+	Latitude  *float64 `json:"-"`
+	Longitude *float64 `json:"-"`
 }
 
 // FetchResult is the return value of Fetcher.Fetch.
 type FetchResult struct {
 	Body        io.ReadCloser
 	ContentType string
+	// ETag and LastModified are the response's cache validators, if any.
+	ETag         string
+	LastModified string
+	// NotModified reports that the content is unchanged since the previous
+	// fetch of the same URL. HTTPFetcher.FetchConditional sets it on a 304
+	// (with a nil Body); CachingFetcher sets it with the cached body attached.
+	NotModified bool
 }
 
 // Fetcher retrieves a URL and returns its body and content-type.
@@ -84,6 +116,21 @@ type Fetcher interface {
 	Fetch(ctx context.Context, rawURL string) (FetchResult, error)
 }
 
+// Validators are the cache validators of a previously fetched response, sent
+// as If-None-Match and If-Modified-Since on a conditional GET.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// ConditionalFetcher is a Fetcher that can revalidate a previous response.
+// On a 304 Not Modified, FetchConditional returns a FetchResult with
+// NotModified set and a nil Body.
+type ConditionalFetcher interface {
+	Fetcher
+	FetchConditional(ctx context.Context, rawURL string, v Validators) (FetchResult, error)
+}
+
 // RenderedFetcher retrieves a URL that may require JavaScript rendering and
 // returns its body and content-type. Implementations will use a headless
 // browser (e.g. chromedp) behind the existing --enable-js-render flag. This
@@ -114,32 +161,86 @@ func (e *HTTPStatusError) Error() string {
 }
 
 // HTTPFetcher is the production Fetcher. It enforces a body-size cap, sends a
-// polite User-Agent, decodes non-UTF-8 HTML, and optionally checks robots.txt.
+// polite User-Agent, decodes non-UTF-8 HTML, and applies a crawl policy
+// (robots.txt, per-host rate limits and daily budgets) before every request.
 type HTTPFetcher struct {
 	Client       *http.Client
 	IgnoreRobots bool
+	// Policy gates every request. Share one Policy across fetchers so all
+	// workers draw from the same per-host buckets and budgets. When nil and
+	// IgnoreRobots is unset, the fetcher builds its own on first use.
+	Policy *crawlpolicy.Policy
+
+	ownPolicy     sync.Once
+	defaultPolicy *crawlpolicy.Policy
 }
 
-// NewHTTPFetcher returns a Fetcher with a 30 s HTTP timeout.
+// NewHTTPFetcher returns a Fetcher with a 30 s HTTP timeout and its own crawl
+// policy (cached robots.txt and Crawl-delay, no default rate limit).
 // The LLM timeout must be handled separately — local models can take minutes.
 func NewHTTPFetcher(ignoreRobots bool) *HTTPFetcher {
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	return &HTTPFetcher{
+		Client:       client,
+		IgnoreRobots: ignoreRobots,
+		Policy:       crawlpolicy.New(client, crawlpolicy.Config{UserAgent: userAgent, IgnoreRobots: ignoreRobots}),
+	}
+}
+
+// NewHTTPFetcherWithPolicy returns a Fetcher with a 30 s HTTP timeout that
+// defers every politeness decision to policy, typically one shared by all
+// pipeline workers.
+func NewHTTPFetcherWithPolicy(policy *crawlpolicy.Policy) *HTTPFetcher {
 	return &HTTPFetcher{
 		Client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		IgnoreRobots: ignoreRobots,
+		Policy: policy,
+	}
+}
+
+// NewHTTPFetcherWithEgress is NewHTTPFetcherWithPolicy with requests routed
+// through pool's proxies and User-Agent profiles. robots.txt is still
+// evaluated against the scraper's own User-Agent.
+func NewHTTPFetcherWithEgress(policy *crawlpolicy.Policy, pool *egress.Pool) *HTTPFetcher {
+	return &HTTPFetcher{
+		Client: pool.Client(30 * time.Second),
+		Policy: policy,
 	}
 }
 
+// NewCrawlPolicy returns a crawlpolicy.Policy that evaluates robots.txt
+// against the scraper's User-Agent and fetches it with a 30 s timeout.
+func NewCrawlPolicy(cfg crawlpolicy.Config) *crawlpolicy.Policy {
+	cfg.UserAgent = userAgent
+	return crawlpolicy.New(&http.Client{Timeout: 30 * time.Second}, cfg)
+}
+
 // Fetch retrieves rawURL and returns the body capped at MaxBodyBytes.
 func (f *HTTPFetcher) Fetch(ctx context.Context, rawURL string) (FetchResult, error) {
+	return f.FetchConditional(ctx, rawURL, Validators{})
+}
+
+// FetchConditional retrieves rawURL like Fetch, sending v as If-None-Match /
+// If-Modified-Since when set. A 304 response returns NotModified with a nil
+// Body. A conditional request still counts against the crawl policy.
+func (f *HTTPFetcher) FetchConditional(ctx context.Context, rawURL string, v Validators) (FetchResult, error) {
 	u, err := url.Parse(rawURL)
 	if err != nil {
 		return FetchResult{}, fmt.Errorf("invalid URL: %w", err)
 	}
 
-	if !f.IgnoreRobots {
-		if err := checkRobots(ctx, f.Client, u, userAgent); err != nil {
+	policy := f.Policy
+	if policy == nil && !f.IgnoreRobots {
+		f.ownPolicy.Do(func() {
+			f.defaultPolicy = crawlpolicy.New(f.Client, crawlpolicy.Config{UserAgent: userAgent})
+		})
+		policy = f.defaultPolicy
+	}
+	if policy != nil {
+		if err := policy.Acquire(ctx, u); err != nil {
 			return FetchResult{}, err
 		}
 	}
@@ -149,11 +250,21 @@ func (f *HTTPFetcher) Fetch(ctx context.Context, rawURL string) (FetchResult, er
 		return FetchResult{}, fmt.Errorf("building request: %w", err)
 	}
 	req.Header.Set("User-Agent", userAgent)
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
 
 	resp, err := f.Client.Do(req)
 	if err != nil {
 		return FetchResult{}, fmt.Errorf("fetching URL: %w", err)
 	}
+	if resp.StatusCode == http.StatusNotModified && (v.ETag != "" || v.LastModified != "") {
+		_ = resp.Body.Close()
+		return FetchResult{ETag: v.ETag, LastModified: v.LastModified, NotModified: true}, nil
+	}
 	if resp.StatusCode != http.StatusOK {
 		_ = resp.Body.Close()
 		return FetchResult{}, &HTTPStatusError{StatusCode: resp.StatusCode, URL: rawURL}
@@ -161,66 +272,12 @@ func (f *HTTPFetcher) Fetch(ctx context.Context, rawURL string) (FetchResult, er
 
 	ct := resp.Header.Get("Content-Type")
 	body := http.MaxBytesReader(nil, resp.Body, MaxBodyBytes)
-	return FetchResult{Body: body, ContentType: ct}, nil
-}
-
-// checkRobots fetches /robots.txt and returns an error if the path is
-// disallowed for our UA. Errors fetching robots.txt are silently ignored
-// (we treat them as "allowed").
-func checkRobots(ctx context.Context, client *http.Client, u *url.URL, ua string) error {
-	robotsURL := &url.URL{
-		Scheme: u.Scheme,
-		Host:   u.Host,
-		Path:   "/robots.txt",
-	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL.String(), nil)
-	if err != nil {
-		return nil
-	}
-	req.Header.Set("User-Agent", ua)
-	resp, err := client.Do(req)
-	if err != nil || resp.StatusCode != http.StatusOK {
-		if resp != nil {
-			_ = resp.Body.Close()
-		}
-		return nil
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
-	if err != nil {
-		return nil
-	}
-
-	path := u.Path
-	if path == "" {
-		path = "/"
-	}
-	return parseRobotsTxt(string(body), ua, path)
-}
-
-// parseRobotsTxt is a minimal robots.txt parser: handles User-agent and
-// Disallow directives. Returns an error if the path is disallowed.
-func parseRobotsTxt(body, ua, path string) error {
-	var applicable bool
-	for _, line := range strings.Split(body, "\n") {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "#") || line == "" {
-			continue
-		}
-		lower := strings.ToLower(line)
-		if strings.HasPrefix(lower, "user-agent:") {
-			agent := strings.TrimSpace(line[len("user-agent:"):])
-			applicable = agent == "*" || strings.Contains(strings.ToLower(ua), strings.ToLower(agent))
-		}
-		if applicable && strings.HasPrefix(lower, "disallow:") {
-			disallowed := strings.TrimSpace(line[len("disallow:"):])
-			if disallowed != "" && strings.HasPrefix(path, disallowed) {
-				return fmt.Errorf("robots.txt disallows %s for our user-agent (use --ignore-robots to override)", path)
-			}
-		}
-	}
-	return nil
+	return FetchResult{
+		Body:         body,
+		ContentType:  ct,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, nil
 }
 
 // ConvertHTMLToMarkdown walks an HTML parse tree and emits Markdown-flavored
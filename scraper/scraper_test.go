@@ -8,6 +8,8 @@ import (
 	"strings"
 	"testing"
 
+	"fodmap/crawlpolicy"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -230,19 +232,45 @@ func TestValidateAPIURL_Localhost(t *testing.T) {
 
 // --- robots.txt ---
 
-func TestRobotsTxt_Disallowed(t *testing.T) {
-	err := parseRobotsTxt("User-agent: *\nDisallow: /menu", "fodmap-detector/0.1", "/menu/lunch")
-	assert.Error(t, err)
-}
+func TestHTTPFetcher_RobotsWildcardDisallowed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /*.pdf$"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
 
-func TestRobotsTxt_Allowed(t *testing.T) {
-	err := parseRobotsTxt("User-agent: *\nDisallow: /admin", "fodmap-detector/0.1", "/menu/lunch")
-	assert.NoError(t, err)
+	f := NewHTTPFetcher(false)
+	_, err := f.Fetch(context.Background(), srv.URL+"/files/menu.pdf")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, crawlpolicy.ErrDisallowed)
+
+	res, err := f.Fetch(context.Background(), srv.URL+"/menu")
+	require.NoError(t, err)
+	_ = res.Body.Close()
 }
 
-func TestRobotsTxt_Empty(t *testing.T) {
-	err := parseRobotsTxt("", "fodmap-detector/0.1", "/anything")
-	assert.NoError(t, err)
+func TestHTTPFetcher_SharedPolicyBudget(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	policy := NewCrawlPolicy(crawlpolicy.Config{DailyBudget: 1})
+	first, second := NewHTTPFetcherWithPolicy(policy), NewHTTPFetcherWithPolicy(policy.WithoutRobots())
+
+	res, err := first.Fetch(context.Background(), srv.URL+"/a")
+	require.NoError(t, err)
+	_ = res.Body.Close()
+
+	_, err = second.Fetch(context.Background(), srv.URL+"/b")
+	assert.ErrorIs(t, err, crawlpolicy.ErrBudgetExhausted, "fetchers sharing a policy must share its budget")
 }
 
 // --- HTTPFetcher (via httptest) ---
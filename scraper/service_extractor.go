@@ -60,6 +60,13 @@ func NewServiceExtractor(baseURL string, pageTimeout, pdfTimeout time.Duration)
 	}
 }
 
+// SetTransport replaces the transport of the service's HTTP clients, e.g.
+// with a Cassette to record or replay service responses.
+func (s *ServiceExtractor) SetTransport(rt http.RoundTripper) {
+	s.pageClient.Transport = rt
+	s.pdfClient.Transport = rt
+}
+
 // Extract routes HTML/text to the service's extractions:structure endpoint.
 func (s *ServiceExtractor) Extract(ctx context.Context, pageText string) (MenuExtractionResult, error) {
 	structRes, err := s.structure(ctx, pageText)
@@ -0,0 +1,88 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	_ "embed"
+)
+
+//go:embed translate-prompt.txt
+var translatePrompt string
+
+// translateBatchSize bounds the entries sent per translation request, keeping
+// long menus well inside small local models' context windows.
+const translateBatchSize = 40
+
+// languageNames names the languages DetectMenuLanguage reports, for the
+// translation prompt.
+var languageNames = map[string]string{
+	"es": "Spanish", "it": "Italian", "fr": "French", "pt": "Portuguese",
+	"zh": "Chinese", "ja": "Japanese", "ko": "Korean", "el": "Greek",
+	"ru": "Russian", "th": "Thai", "ar": "Arabic", "he": "Hebrew",
+	"hi": "Hindi", "bn": "Bengali",
+}
+
+// MenuTranslator is an optional Extractor capability: translating the entries
+// of a non-English menu into English. lang is the ISO 639-1 code from
+// DetectMenuLanguage. The result is parallel to items; an entry the backend
+// did not translate is nil.
+type MenuTranslator interface {
+	TranslateMenu(ctx context.Context, lang string, items []MenuEntry) ([]*EntryTranslation, error)
+}
+
+// TranslateMenu translates items to English via the LLM, in batches. A batch
+// that fails aborts the whole translation; entries missing from a reply are
+// left nil.
+func (e *OpenAICompatExtractor) TranslateMenu(ctx context.Context, lang string, items []MenuEntry) ([]*EntryTranslation, error) {
+	name := languageNames[lang]
+	if name == "" {
+		name = "a language other than English (ISO 639-1 code " + lang + ")"
+	}
+	prompt := strings.Replace(translatePrompt, "{{LANGUAGE}}", name, 1)
+
+	out := make([]*EntryTranslation, len(items))
+	for start := 0; start < len(items); start += translateBatchSize {
+		end := min(start+translateBatchSize, len(items))
+		batch := make([]llmTranslatedEntry, 0, end-start)
+		for i := start; i < end; i++ {
+			batch = append(batch, llmTranslatedEntry{
+				Index:             i,
+				DishName:          items[i].DishName,
+				Description:       items[i].Description,
+				StatedIngredients: items[i].StatedIngredients,
+			})
+		}
+		input, err := json.Marshal(batch)
+		if err != nil {
+			return nil, fmt.Errorf("marshal translation batch: %w", err)
+		}
+		raw, err := e.chatCompletion(ctx, []contentPart{{Type: "text", Text: prompt + string(input)}}, "menu_translation", e.translateSchema)
+		if err != nil {
+			return nil, fmt.Errorf("translating items [%d:%d]: %w", start, end, err)
+		}
+		var payload llmTranslationPayload
+		if err := json.Unmarshal([]byte(raw), &payload); err != nil {
+			return nil, fmt.Errorf("parse translation JSON output: %w (raw: %.200s)", err, raw)
+		}
+		for _, t := range payload.Items {
+			if t.Index < start || t.Index >= end || strings.TrimSpace(t.DishName) == "" {
+				continue
+			}
+			// A translation that changed the number of stated ingredients
+			// has added or dropped one; keep the dish but not the list.
+			ingredients := t.StatedIngredients
+			if len(ingredients) != len(items[t.Index].StatedIngredients) {
+				ingredients = nil
+			}
+			out[t.Index] = &EntryTranslation{
+				DishName:          t.DishName,
+				Description:       t.Description,
+				StatedIngredients: ingredients,
+			}
+		}
+	}
+	return out, nil
+}
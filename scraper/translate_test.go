@@ -0,0 +1,73 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAICompatExtractor_TranslateMenu(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if req.ResponseFormat == nil || req.ResponseFormat.JSONSchema == nil || req.ResponseFormat.JSONSchema.Name != "menu_translation" {
+			t.Errorf("expected menu_translation json_schema response format, got %+v", req.ResponseFormat)
+		}
+		if prompt := req.Messages[0].Content[0].Text; !strings.Contains(prompt, "Spanish") {
+			t.Errorf("prompt does not name the source language: %.200s", prompt)
+		}
+		// Entry 1 drops an ingredient and entry 2 is missing from the reply.
+		resp := makeChoiceResp(`{"items":[`+
+			`{"index":0,"dish":"Garlic soup","description":"Broth with bread","stated_ingredients":["garlic","bread"]},`+
+			`{"index":1,"dish":"Tacos","description":"Corn tortillas","stated_ingredients":["corn"]},`+
+			`{"index":7,"dish":"Out of range","description":"","stated_ingredients":[]}]}`, "", "", "stop")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	ext := newTestExtractor(t, srv.URL, "gpt-test", "")
+	items := []MenuEntry{
+		{DishName: "Sopa de ajo", Description: "Caldo con pan", StatedIngredients: []string{"ajo", "pan"}},
+		{DishName: "Tacos", Description: "Tortillas de maíz", StatedIngredients: []string{"maíz", "cebolla"}},
+		{DishName: "Flan"},
+	}
+	got, err := ext.TranslateMenu(context.Background(), "es", items)
+	if err != nil {
+		t.Fatalf("TranslateMenu: %v", err)
+	}
+	if len(got) != len(items) {
+		t.Fatalf("got %d translations, want %d", len(got), len(items))
+	}
+	if got[0] == nil || got[0].DishName != "Garlic soup" || len(got[0].StatedIngredients) != 2 {
+		t.Errorf("entry 0 = %+v, want Garlic soup with 2 ingredients", got[0])
+	}
+	if got[1] == nil || got[1].DishName != "Tacos" || got[1].StatedIngredients != nil {
+		t.Errorf("entry 1 = %+v, want ingredient list dropped after count mismatch", got[1])
+	}
+	if got[2] != nil {
+		t.Errorf("entry 2 = %+v, want nil for an untranslated entry", got[2])
+	}
+}
+
+func TestOpenAICompatExtractor_TranslateMenu_Batches(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_ = json.NewEncoder(w).Encode(makeChoiceResp(`{"items":[]}`, "", "", "stop"))
+	}))
+	defer srv.Close()
+
+	ext := newTestExtractor(t, srv.URL, "gpt-test", "")
+	items := make([]MenuEntry, translateBatchSize+1)
+	if _, err := ext.TranslateMenu(context.Background(), "ko", items); err != nil {
+		t.Fatalf("TranslateMenu: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("requests = %d, want 2", calls)
+	}
+}
@@ -5,15 +5,106 @@ import (
 	"strings"
 )
 
-// bm25Score returns a simplified BM25-style keyword relevance score between query and text.
-// It counts matching query terms weighted by IDF (log(1 + 1/df)) approximation,
-// normalized by the number of query terms. Returns 0 when there is no overlap.
-func bm25Score(query, text string) float64 {
+// BM25 parameters: k1 controls term-frequency saturation, b the strength of
+// document-length normalisation.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Fallbacks for scoring without corpus statistics (a backend that was indexed
+// before statistics were kept): every term weighs the same and documents are
+// assumed to be 100 tokens long.
+const (
+	fallbackIDF       = math.Ln2
+	fallbackAvgDocLen = 100.0
+)
+
+// CorpusStats holds the document statistics BM25 scoring needs: the number of
+// documents, their total length in tokens, and the number of documents each
+// term occurs in. Backends update it as they index and persist it alongside
+// the index; a document is whatever text the backend scores (a review chunk
+// for review search). The zero value is an empty corpus.
+type CorpusStats struct {
+	Docs     int64            `json:"docs"`
+	TotalLen int64            `json:"total_len"`
+	DocFreq  map[string]int64 `json:"doc_freq"`
+}
+
+// Add counts text as one more document.
+func (s *CorpusStats) Add(text string) { s.apply(text, 1) }
+
+// Remove uncounts a document previously added with Add, e.g. a chunk that is
+// being replaced on re-index.
+func (s *CorpusStats) Remove(text string) { s.apply(text, -1) }
+
+func (s *CorpusStats) apply(text string, sign int64) {
+	terms := tokenize(text)
+	s.Docs += sign
+	s.TotalLen += sign * int64(len(terms))
+	if s.DocFreq == nil {
+		s.DocFreq = make(map[string]int64)
+	}
+	seen := make(map[string]bool, len(terms))
+	for _, t := range terms {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		s.DocFreq[t] += sign
+		if s.DocFreq[t] == 0 {
+			delete(s.DocFreq, t)
+		}
+	}
+}
+
+// Merge adds the counts of d, which may be negative (a delta built with
+// Remove), to s.
+func (s *CorpusStats) Merge(d CorpusStats) {
+	s.Docs += d.Docs
+	s.TotalLen += d.TotalLen
+	if s.DocFreq == nil {
+		s.DocFreq = make(map[string]int64, len(d.DocFreq))
+	}
+	for t, n := range d.DocFreq {
+		s.DocFreq[t] += n
+		if s.DocFreq[t] <= 0 {
+			delete(s.DocFreq, t)
+		}
+	}
+}
+
+// AvgDocLen returns the mean document length in tokens.
+func (s *CorpusStats) AvgDocLen() float64 {
+	if s == nil || s.Docs <= 0 || s.TotalLen <= 0 {
+		return fallbackAvgDocLen
+	}
+	return float64(s.TotalLen) / float64(s.Docs)
+}
+
+// IDF returns the BM25 inverse document frequency of term,
+// log(1 + (N - df + 0.5) / (df + 0.5)): close to 0 for a term in every
+// document, highest for a term in none. Without statistics every term gets
+// the same weight.
+func (s *CorpusStats) IDF(term string) float64 {
+	if s == nil || s.Docs <= 0 {
+		return fallbackIDF
+	}
+	df := float64(min(max(s.DocFreq[term], 0), s.Docs))
+	n := float64(s.Docs)
+	return math.Log(1 + (n-df+0.5)/(df+0.5))
+}
+
+// bm25Score returns the BM25 relevance of text to query under stats,
+// normalised to [0, 1) by the score a document matching every query term
+// could reach, so it can be blended with a dense similarity. stats may be nil,
+// in which case rarity is ignored. Returns 0 when there is no overlap.
+func bm25Score(query, text string, stats *CorpusStats) float64 {
 	if query == "" || text == "" {
 		return 0
 	}
 
-	queryTerms := tokenize(query)
+	queryTerms := uniqueTerms(tokenize(query))
 	if len(queryTerms) == 0 {
 		return 0
 	}
@@ -29,39 +120,43 @@ func bm25Score(query, text string) float64 {
 	}
 
 	docLen := float64(len(textTerms))
-	const (
-		k1        = 1.2
-		b         = 0.75
-		avgDocLen = 100.0 // assumed average document length for normalization
-	)
+	avgDocLen := stats.AvgDocLen()
 
-	var score float64
+	var score, best float64
 	for _, term := range queryTerms {
+		idf := stats.IDF(term)
+		best += idf * (bm25K1 + 1)
 		freq := float64(tf[term])
 		if freq == 0 {
 			continue
 		}
-		// Simplified IDF: higher weight for rare terms (here approximated as log(2)).
-		idf := math.Log(2)
-		tfNorm := freq * (k1 + 1) / (freq + k1*(1-b+b*docLen/avgDocLen))
+		tfNorm := freq * (bm25K1 + 1) / (freq + bm25K1*(1-bm25B+bm25B*docLen/avgDocLen))
 		score += idf * tfNorm
 	}
-
-	// Normalize by query length so longer queries don't dominate.
-	return score / float64(len(queryTerms))
+	if best == 0 {
+		return 0
+	}
+	return score / best
 }
 
 // blendScore combines a dense vector score with a BM25 keyword score.
 // alpha=0 (unset) or alpha=1 returns the pure dense score (no keyword blending).
 // alpha between 0 (exclusive) and 1 (exclusive) blends both: higher alpha weights dense more.
-func blendScore(query, text string, denseScore float64, alpha float32) float64 {
+func blendScore(query, text string, denseScore float64, alpha float32, stats *CorpusStats) float64 {
 	if alpha <= 0 || alpha >= 1 {
 		return denseScore
 	}
-	return float64(alpha)*denseScore + float64(1-alpha)*bm25Score(query, text)
+	return float64(alpha)*denseScore + float64(1-alpha)*bm25Score(query, text, stats)
+}
+
+// hybridActive reports whether alpha asks for dense and keyword blending.
+func hybridActive(alpha float32) bool {
+	return alpha > 0 && alpha < 1
 }
 
-// tokenize splits text into lowercase tokens, stripping punctuation.
+// tokenize splits text into lowercase tokens, stripping punctuation. The
+// bm25 migration's backfill splits chunk text the same way in SQL; keep the
+// two in step.
 func tokenize(s string) []string {
 	s = strings.ToLower(s)
 	var tokens []string
@@ -79,3 +174,16 @@ func tokenize(s string) []string {
 	}
 	return tokens
 }
+
+// uniqueTerms drops repeated terms, keeping first occurrences in order.
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	out := terms[:0:0]
+	for _, t := range terms {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -5,39 +5,103 @@ import (
 )
 
 func TestBm25Score_ExactMatch(t *testing.T) {
-	score := bm25Score("gluten free pizza", "gluten free pizza is great here")
+	score := bm25Score("gluten free pizza", "gluten free pizza is great here", nil)
 	if score <= 0 {
 		t.Errorf("expected positive score for exact match, got %f", score)
 	}
 }
 
 func TestBm25Score_NoOverlap(t *testing.T) {
-	score := bm25Score("gluten free", "excellent sushi and sake")
+	score := bm25Score("gluten free", "excellent sushi and sake", nil)
 	if score != 0 {
 		t.Errorf("expected 0 for no overlap, got %f", score)
 	}
 }
 
 func TestBm25Score_PartialMatch(t *testing.T) {
-	full := bm25Score("gluten free pizza", "gluten free pizza pasta")
-	partial := bm25Score("gluten free pizza", "gluten free sushi")
-	none := bm25Score("gluten free pizza", "excellent sushi")
+	full := bm25Score("gluten free pizza", "gluten free pizza pasta", nil)
+	partial := bm25Score("gluten free pizza", "gluten free sushi", nil)
+	none := bm25Score("gluten free pizza", "excellent sushi", nil)
 	if !(full > partial && partial > none) {
 		t.Errorf("expected full(%f) > partial(%f) > none(%f)", full, partial, none)
 	}
 }
 
 func TestBm25Score_CaseInsensitive(t *testing.T) {
-	lower := bm25Score("pizza", "great pizza here")
-	upper := bm25Score("Pizza", "great pizza here")
+	lower := bm25Score("pizza", "great pizza here", nil)
+	upper := bm25Score("Pizza", "great pizza here", nil)
 	if lower != upper {
 		t.Errorf("bm25Score should be case-insensitive: lower=%f upper=%f", lower, upper)
 	}
 }
 
 func TestBm25Score_EmptyQuery(t *testing.T) {
-	score := bm25Score("", "great pizza here")
+	score := bm25Score("", "great pizza here", nil)
 	if score != 0 {
 		t.Errorf("expected 0 for empty query, got %f", score)
 	}
 }
+
+func testCorpus() *CorpusStats {
+	stats := &CorpusStats{}
+	for _, doc := range []string{
+		"fresh pasta with tomato sauce",
+		"pasta carbonara was rich",
+		"baked pasta and garlic bread",
+		"the pasta special",
+		"fodmap friendly menu with pasta",
+	} {
+		stats.Add(doc)
+	}
+	return stats
+}
+
+func TestBm25Score_RareTermsWeighMore(t *testing.T) {
+	stats := testCorpus()
+	if !(stats.IDF("fodmap") > stats.IDF("pasta")) {
+		t.Fatalf("IDF(fodmap)=%f should exceed IDF(pasta)=%f", stats.IDF("fodmap"), stats.IDF("pasta"))
+	}
+	rare := bm25Score("fodmap pasta", "fodmap options here", stats)
+	common := bm25Score("fodmap pasta", "pasta options here", stats)
+	if !(rare > common) {
+		t.Errorf("rare-term match (%f) should outscore common-term match (%f)", rare, common)
+	}
+	// Without statistics both terms weigh the same.
+	if a, b := bm25Score("fodmap pasta", "fodmap options here", nil), bm25Score("fodmap pasta", "pasta options here", nil); a != b {
+		t.Errorf("fallback scores differ: %f vs %f", a, b)
+	}
+}
+
+func TestBm25Score_Normalised(t *testing.T) {
+	stats := testCorpus()
+	score := bm25Score("fodmap friendly", "fodmap friendly fodmap friendly", stats)
+	if score <= 0 || score >= 1 {
+		t.Errorf("score = %f, want within (0, 1)", score)
+	}
+}
+
+func TestCorpusStats_AddRemove(t *testing.T) {
+	stats := testCorpus()
+	if stats.Docs != 5 || stats.DocFreq["pasta"] != 5 {
+		t.Fatalf("docs=%d df(pasta)=%d, want 5 and 5", stats.Docs, stats.DocFreq["pasta"])
+	}
+	if got, want := stats.AvgDocLen(), float64(stats.TotalLen)/5; got != want {
+		t.Errorf("AvgDocLen = %f, want %f", got, want)
+	}
+
+	var delta CorpusStats
+	delta.Remove("the pasta special")
+	delta.Add("the pasta special with fodmap notes")
+	stats.Merge(delta)
+	if stats.Docs != 5 {
+		t.Errorf("docs = %d after replace, want 5", stats.Docs)
+	}
+	if stats.DocFreq["fodmap"] != 2 || stats.DocFreq["special"] != 1 {
+		t.Errorf("df(fodmap)=%d df(special)=%d, want 2 and 1", stats.DocFreq["fodmap"], stats.DocFreq["special"])
+	}
+
+	stats.Remove("fodmap friendly menu with pasta")
+	if _, ok := stats.DocFreq["friendly"]; ok {
+		t.Error("a term in no document should be dropped")
+	}
+}
@@ -0,0 +1,242 @@
+package search
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultEmbeddingCacheMaxEntries bounds an embedding cache at about 3 GB of
+// 768-dim vectors; least recently used entries beyond it are evicted.
+const DefaultEmbeddingCacheMaxEntries = 1_000_000
+
+// EmbeddingCacheStore persists cached vectors by key. PostgresEmbeddingCache
+// and LocalEmbeddingCache implement it.
+type EmbeddingCacheStore interface {
+	// LoadEmbeddings returns the stored vectors of the keys it has, marking
+	// them used.
+	LoadEmbeddings(ctx context.Context, keys []string) (map[string][]float32, error)
+	// StoreEmbeddings saves vectors by key under namespace.
+	StoreEmbeddings(ctx context.Context, namespace string, vecs map[string][]float32) error
+	// EvictEmbeddings deletes the least recently used entries beyond
+	// maxEntries, returning how many it deleted.
+	EvictEmbeddings(ctx context.Context, maxEntries int) (int, error)
+	Close() error
+}
+
+// EmbeddingCacheStats counts a CachingEmbedder's lookups since it was built.
+type EmbeddingCacheStats struct {
+	Namespace string  `json:"namespace"`
+	Hits      int64   `json:"hits"`
+	Misses    int64   `json:"misses"`
+	Errors    int64   `json:"errors"` // store failures; the texts were embedded anyway
+	Evicted   int64   `json:"evicted"`
+	HitRate   float64 `json:"hit_rate"`
+}
+
+// CachingEmbedder wraps an Embedder with a content-addressed cache, so a
+// text already embedded by the same model — a review chunk on a re-run
+// index job, a dish on a re-scraped menu, a reseeded ingredient — is not
+// embedded again. Entries are keyed by the model, the task (query for
+// EmbedSingle, document for EmbedBatch, as they carry different prefixes)
+// and the text. A failing store never fails an embedding: it is logged,
+// counted, and the texts are embedded as if missed.
+type CachingEmbedder struct {
+	e          Embedder
+	store      EmbeddingCacheStore
+	namespace  string
+	maxEntries int
+
+	hits, misses, failures, evicted atomic.Int64
+	sinceEvict                      atomic.Int64
+	evictMu                         sync.Mutex
+}
+
+// NewCachingEmbedder caches e, built from cfg, in store. maxEntries <= 0
+// disables eviction. Closing the CachingEmbedder closes e and store.
+func NewCachingEmbedder(e Embedder, cfg EmbedderConfig, store EmbeddingCacheStore, maxEntries int) *CachingEmbedder {
+	return &CachingEmbedder{
+		e:          e,
+		store:      store,
+		namespace:  EmbeddingCacheNamespace(cfg),
+		maxEntries: maxEntries,
+	}
+}
+
+// EmbeddingCacheNamespace identifies the vectors cfg's embedder produces:
+// the backend, model and dimension. The vectorizer proxy does not name its
+// model, so its URL stands in; a TEI server serves whichever model it was
+// started with, whatever the configured name says, so its URL is included.
+func EmbeddingCacheNamespace(cfg EmbedderConfig) string {
+	dim := cfg.Dim
+	if dim == 0 {
+		dim = ExpectedEmbeddingDim
+	}
+	model := cfg.Model()
+	switch cfg.Type {
+	case "vectorizer":
+		model = cfg.VectorizerURL
+	case "tei":
+		model = cfg.TEIURL + "#" + model
+	}
+	return fmt.Sprintf("%s/%s/%d", cfg.Type, model, dim)
+}
+
+// Task names in cache keys.
+const (
+	cacheTaskQuery    = "query"
+	cacheTaskDocument = "document"
+)
+
+// embeddingCacheKey is the hex SHA-256 of namespace, task and text.
+func embeddingCacheKey(namespace, task, text string) string {
+	h := sha256.New()
+	h.Write([]byte(namespace))
+	h.Write([]byte{0})
+	h.Write([]byte(task))
+	h.Write([]byte{0})
+	h.Write([]byte(text))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// EmbedSingle returns the cached query vector of text, embedding and
+// caching it on a miss.
+func (c *CachingEmbedder) EmbedSingle(ctx context.Context, text string) ([]float32, error) {
+	key := embeddingCacheKey(c.namespace, cacheTaskQuery, text)
+	if cached := c.load(ctx, []string{key}); cached[key] != nil {
+		c.hits.Add(1)
+		return cached[key], nil
+	}
+	c.misses.Add(1)
+	vec, err := c.e.EmbedSingle(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	c.save(ctx, map[string][]float32{key: vec})
+	return vec, nil
+}
+
+// EmbedBatch returns the document vectors of texts in order, embedding only
+// the texts not cached, each distinct text once.
+func (c *CachingEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	keys := make([]string, len(texts))
+	for i, t := range texts {
+		keys[i] = embeddingCacheKey(c.namespace, cacheTaskDocument, t)
+	}
+	cached := c.load(ctx, keys)
+
+	var missTexts []string
+	missIdx := make(map[string]int) // key -> index in missTexts
+	for i, k := range keys {
+		if cached[k] != nil {
+			c.hits.Add(1)
+			continue
+		}
+		c.misses.Add(1)
+		if _, ok := missIdx[k]; !ok {
+			missIdx[k] = len(missTexts)
+			missTexts = append(missTexts, texts[i])
+		}
+	}
+
+	if len(missTexts) > 0 {
+		vecs, err := c.e.EmbedBatch(ctx, missTexts)
+		if err != nil {
+			return nil, err
+		}
+		if len(vecs) != len(missTexts) {
+			return nil, fmt.Errorf("embedder returned %d vectors for %d texts", len(vecs), len(missTexts))
+		}
+		fresh := make(map[string][]float32, len(missIdx))
+		for k, i := range missIdx {
+			fresh[k] = vecs[i]
+		}
+		c.save(ctx, fresh)
+		if cached == nil {
+			cached = fresh
+		} else {
+			for k, v := range fresh {
+				cached[k] = v
+			}
+		}
+	}
+
+	out := make([][]float32, len(texts))
+	for i, k := range keys {
+		out[i] = cached[k]
+	}
+	return out, nil
+}
+
+func (c *CachingEmbedder) load(ctx context.Context, keys []string) map[string][]float32 {
+	cached, err := c.store.LoadEmbeddings(ctx, keys)
+	if err != nil {
+		c.failures.Add(1)
+		slog.Warn("embedding cache: load failed; embedding instead", "error", err)
+		return nil
+	}
+	return cached
+}
+
+// save stores fresh vectors and, every tenth of the cache's capacity
+// written, evicts the least recently used entries beyond it.
+func (c *CachingEmbedder) save(ctx context.Context, vecs map[string][]float32) {
+	if err := c.store.StoreEmbeddings(ctx, c.namespace, vecs); err != nil {
+		c.failures.Add(1)
+		slog.Warn("embedding cache: store failed", "error", err)
+		return
+	}
+	if c.maxEntries <= 0 {
+		return
+	}
+	if c.sinceEvict.Add(int64(len(vecs))) >= int64(max(c.maxEntries/10, 1)) {
+		c.evict(ctx)
+	}
+}
+
+func (c *CachingEmbedder) evict(ctx context.Context) {
+	if !c.evictMu.TryLock() {
+		return // another caller is evicting
+	}
+	defer c.evictMu.Unlock()
+	c.sinceEvict.Store(0)
+	n, err := c.store.EvictEmbeddings(ctx, c.maxEntries)
+	if err != nil {
+		c.failures.Add(1)
+		slog.Warn("embedding cache: eviction failed", "error", err)
+		return
+	}
+	c.evicted.Add(int64(n))
+}
+
+// Stats returns the cache's hit and miss counts.
+func (c *CachingEmbedder) Stats() EmbeddingCacheStats {
+	s := EmbeddingCacheStats{
+		Namespace: c.namespace,
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Errors:    c.failures.Load(),
+		Evicted:   c.evicted.Load(),
+	}
+	if total := s.Hits + s.Misses; total > 0 {
+		s.HitRate = float64(s.Hits) / float64(total)
+	}
+	return s
+}
+
+// Close evicts down to the cache's capacity and closes the store and the
+// wrapped embedder.
+func (c *CachingEmbedder) Close() error {
+	if c.maxEntries > 0 && c.sinceEvict.Load() > 0 {
+		c.evict(context.Background())
+	}
+	storeErr := c.store.Close()
+	if err := c.e.Close(); err != nil {
+		return err
+	}
+	return storeErr
+}
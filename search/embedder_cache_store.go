@@ -0,0 +1,274 @@
+package search
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// embeddingCacheTouchAfter is how stale a Postgres cache entry's used_at must
+// be before a hit rewrites it, so a hot entry costs one write an hour
+// rather than one per lookup.
+const embeddingCacheTouchAfter = time.Hour
+
+// PostgresEmbeddingCache keeps cached vectors in the embedding_cache table,
+// shared by every process on the database.
+type PostgresEmbeddingCache struct {
+	db *sql.DB
+}
+
+// NewPostgresEmbeddingCache connects to the database at dsn.
+func NewPostgresEmbeddingCache(dsn string) (*PostgresEmbeddingCache, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PostgreSQL database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to ping PostgreSQL database: %w", err)
+	}
+	return &PostgresEmbeddingCache{db: db}, nil
+}
+
+// LoadEmbeddings implements EmbeddingCacheStore.
+func (c *PostgresEmbeddingCache) LoadEmbeddings(ctx context.Context, keys []string) (map[string][]float32, error) {
+	rows, err := c.db.QueryContext(ctx, `SELECT key, vector FROM embedding_cache WHERE key = ANY($1)`, pq.Array(keys))
+	if err != nil {
+		return nil, fmt.Errorf("query embedding cache: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	out := make(map[string][]float32)
+	for rows.Next() {
+		var key string
+		var raw []byte
+		if err := rows.Scan(&key, &raw); err != nil {
+			return nil, fmt.Errorf("scan embedding cache: %w", err)
+		}
+		vec, err := decodeVector(raw)
+		if err != nil {
+			return nil, fmt.Errorf("embedding cache entry %s: %w", key, err)
+		}
+		out[key] = vec
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query embedding cache: %w", err)
+	}
+	if len(out) > 0 {
+		hit := make([]string, 0, len(out))
+		for k := range out {
+			hit = append(hit, k)
+		}
+		_, err := c.db.ExecContext(ctx, `
+			UPDATE embedding_cache SET used_at = NOW()
+			WHERE key = ANY($1) AND used_at < NOW() - make_interval(secs => $2)
+		`, pq.Array(hit), embeddingCacheTouchAfter.Seconds())
+		if err != nil {
+			return nil, fmt.Errorf("touch embedding cache: %w", err)
+		}
+	}
+	return out, nil
+}
+
+// StoreEmbeddings implements EmbeddingCacheStore.
+func (c *PostgresEmbeddingCache) StoreEmbeddings(ctx context.Context, namespace string, vecs map[string][]float32) error {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO embedding_cache (key, namespace, vector)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET used_at = NOW()
+	`)
+	if err != nil {
+		return fmt.Errorf("prepare stmt: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+	// Sorted keys keep concurrent writers' row locks in one order.
+	for _, key := range sortedKeys(vecs) {
+		if _, err := stmt.ExecContext(ctx, key, namespace, encodeVector(vecs[key])); err != nil {
+			return fmt.Errorf("store embedding cache entry: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// EvictEmbeddings implements EmbeddingCacheStore.
+func (c *PostgresEmbeddingCache) EvictEmbeddings(ctx context.Context, maxEntries int) (int, error) {
+	res, err := c.db.ExecContext(ctx, `
+		DELETE FROM embedding_cache WHERE key IN (
+			SELECT key FROM embedding_cache ORDER BY used_at DESC OFFSET $1
+		)
+	`, maxEntries)
+	if err != nil {
+		return 0, fmt.Errorf("evict embedding cache: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("evict embedding cache: %w", err)
+	}
+	return int(n), nil
+}
+
+// Close closes the database handle.
+func (c *PostgresEmbeddingCache) Close() error {
+	return c.db.Close()
+}
+
+// LocalEmbeddingCache keeps cached vectors in memory, persisted to an
+// append-only log in a directory, for runs without Postgres. Recency is
+// tracked in memory and written out when the log is compacted, so eviction
+// order survives restarts only approximately: entries not used since the
+// last compaction rank by when they were stored. Like a LocalClient, it is
+// used by one process at a time: opening takes the directory's lock, so the
+// directory must not also hold a local index.
+type LocalEmbeddingCache struct {
+	mu      sync.Mutex
+	lock    *os.File
+	log     *localLog
+	entries map[string]*localCacheEntry
+	clock   int64 // last recency stamp handed out
+}
+
+type localCacheEntry struct {
+	namespace string
+	vec       []float32
+	used      int64
+}
+
+// localCacheRecord is one LocalEmbeddingCache log line.
+type localCacheRecord struct {
+	Key       string      `json:"k"`
+	Namespace string      `json:"n"`
+	Vector    localVector `json:"v"`
+}
+
+// NewLocalEmbeddingCache opens (creating if needed) the cache kept in dir.
+func NewLocalEmbeddingCache(dir string) (*LocalEmbeddingCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create embedding cache dir: %w", err)
+	}
+	lock, err := lockLocalDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	c := &LocalEmbeddingCache{lock: lock, entries: make(map[string]*localCacheEntry)}
+	l, err := openLocalLog(filepath.Join(dir, "embeddings.jsonl"), func(rec localCacheRecord) {
+		c.clock++
+		c.entries[rec.Key] = &localCacheEntry{namespace: rec.Namespace, vec: rec.Vector, used: c.clock}
+	})
+	if err != nil {
+		_ = lock.Close()
+		return nil, err
+	}
+	c.log = l
+	return c, nil
+}
+
+// LoadEmbeddings implements EmbeddingCacheStore.
+func (c *LocalEmbeddingCache) LoadEmbeddings(_ context.Context, keys []string) (map[string][]float32, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string][]float32)
+	for _, k := range keys {
+		if e, ok := c.entries[k]; ok {
+			c.clock++
+			e.used = c.clock
+			out[k] = e.vec
+		}
+	}
+	return out, nil
+}
+
+// StoreEmbeddings implements EmbeddingCacheStore.
+func (c *LocalEmbeddingCache) StoreEmbeddings(_ context.Context, namespace string, vecs map[string][]float32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	recs := make([]any, 0, len(vecs))
+	for _, k := range sortedKeys(vecs) {
+		if _, ok := c.entries[k]; ok {
+			continue
+		}
+		recs = append(recs, localCacheRecord{Key: k, Namespace: namespace, Vector: vecs[k]})
+	}
+	if err := c.log.append(recs...); err != nil {
+		return err
+	}
+	for _, r := range recs {
+		rec := r.(localCacheRecord)
+		c.clock++
+		c.entries[rec.Key] = &localCacheEntry{namespace: rec.Namespace, vec: rec.Vector, used: c.clock}
+	}
+	return nil
+}
+
+// EvictEmbeddings implements EmbeddingCacheStore. It rewrites the log with
+// the kept entries, least recently used first.
+func (c *LocalEmbeddingCache) EvictEmbeddings(_ context.Context, maxEntries int) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) <= maxEntries && !c.log.needsCompaction(len(c.entries)) {
+		return 0, nil
+	}
+	keys := sortedKeys(c.entries)
+	slices.SortFunc(keys, func(a, b string) int {
+		return cmp.Compare(c.entries[a].used, c.entries[b].used)
+	})
+	evicted := max(len(keys)-maxEntries, 0)
+	for _, k := range keys[:evicted] {
+		delete(c.entries, k)
+	}
+	keys = keys[evicted:]
+	recs := make([]any, len(keys))
+	for i, k := range keys {
+		e := c.entries[k]
+		recs[i] = localCacheRecord{Key: k, Namespace: e.namespace, Vector: e.vec}
+	}
+	if err := c.log.rewrite(recs); err != nil {
+		return 0, err
+	}
+	return evicted, nil
+}
+
+// Close closes the log and releases the directory's lock.
+func (c *LocalEmbeddingCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return errors.Join(c.log.close(), c.lock.Close())
+}
+
+// encodeVector packs v as little-endian float32s.
+func encodeVector(v []float32) []byte {
+	raw := make([]byte, 4*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(raw[4*i:], math.Float32bits(x))
+	}
+	return raw
+}
+
+// decodeVector unpacks a vector written by encodeVector.
+func decodeVector(raw []byte) ([]float32, error) {
+	if len(raw)%4 != 0 {
+		return nil, fmt.Errorf("vector of %d bytes is not a whole number of float32s", len(raw))
+	}
+	out := make([]float32, len(raw)/4)
+	for i := range out {
+		out[i] = math.Float32frombits(binary.LittleEndian.Uint32(raw[4*i:]))
+	}
+	return out, nil
+}
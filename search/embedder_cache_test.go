@@ -0,0 +1,187 @@
+package search
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+// countingEmbedder embeds a text as its length and counts the texts it was
+// asked to embed.
+type countingEmbedder struct{ embedded []string }
+
+func (e *countingEmbedder) EmbedSingle(_ context.Context, text string) ([]float32, error) {
+	e.embedded = append(e.embedded, "q:"+text)
+	return []float32{float32(len(text)), 1}, nil
+}
+
+func (e *countingEmbedder) EmbedBatch(_ context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		e.embedded = append(e.embedded, "d:"+t)
+		out[i] = []float32{float32(len(t)), 0}
+	}
+	return out, nil
+}
+
+func (e *countingEmbedder) Close() error { return nil }
+
+func TestCachingEmbedder_SkipsEmbeddedTexts(t *testing.T) {
+	dir := t.TempDir()
+	cfg := EmbedderConfig{Type: "ollama", OllamaModel: "nomic-embed-text"}
+	ctx := context.Background()
+
+	inner := &countingEmbedder{}
+	store, err := NewLocalEmbeddingCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewCachingEmbedder(inner, cfg, store, 0)
+	if _, err := c.EmbedBatch(ctx, []string{"pad thai", "garlic naan", "pad thai"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.EmbedSingle(ctx, "pad thai"); err != nil {
+		t.Fatal(err)
+	}
+	// A duplicate in one batch is embedded once; the query of a text already
+	// embedded as a document is not a hit, as its prefix differs.
+	if want := []string{"d:pad thai", "d:garlic naan", "q:pad thai"}; !reflect.DeepEqual(inner.embedded, want) {
+		t.Errorf("embedded %q, want %q", inner.embedded, want)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A re-run (a new process) embeds only the new text.
+	inner = &countingEmbedder{}
+	store, err = NewLocalEmbeddingCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = NewCachingEmbedder(inner, cfg, store, 0)
+	defer func() { _ = c.Close() }()
+	vecs, err := c.EmbedBatch(ctx, []string{"garlic naan", "pho", "pad thai"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"d:pho"}; !reflect.DeepEqual(inner.embedded, want) {
+		t.Errorf("re-run embedded %q, want %q", inner.embedded, want)
+	}
+	if want := [][]float32{{11, 0}, {3, 0}, {8, 0}}; !reflect.DeepEqual(vecs, want) {
+		t.Errorf("vectors = %v, want %v in input order", vecs, want)
+	}
+	if s := c.Stats(); s.Hits != 2 || s.Misses != 1 {
+		t.Errorf("stats = %+v, want 2 hits and 1 miss", s)
+	}
+
+	// Another model does not share the entries.
+	other := NewCachingEmbedder(&countingEmbedder{}, EmbedderConfig{Type: "ollama", OllamaModel: "mxbai-embed-large", Dim: 1024}, store, 0)
+	if _, err := other.EmbedBatch(ctx, []string{"pho"}); err != nil {
+		t.Fatal(err)
+	}
+	if s := other.Stats(); s.Hits != 0 {
+		t.Errorf("another model hit %d cached entries", s.Hits)
+	}
+}
+
+func TestLocalEmbeddingCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	store, err := NewLocalEmbeddingCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"a", "b", "c"} {
+		if err := store.StoreEmbeddings(ctx, "ns", map[string][]float32{k: {1}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.LoadEmbeddings(ctx, []string{"a"}); err != nil {
+		t.Fatal(err)
+	}
+	n, err := store.EvictEmbeddings(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("evicted %d entries, want 1", n)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = NewLocalEmbeddingCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+	got, err := store.LoadEmbeddings(ctx, []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["b"]; ok || len(got) != 2 {
+		t.Errorf("after eviction the cache holds %v, want a and c", sortedKeys(got))
+	}
+}
+
+func TestLocalEmbeddingCache_OneProcessAtATime(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no directory lock on this platform")
+	}
+	dir := t.TempDir()
+	store, err := NewLocalEmbeddingCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other, err := NewLocalEmbeddingCache(dir); err == nil {
+		_ = other.Close()
+		t.Fatal("a second cache opened the directory while the first held it")
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	store, err = NewLocalEmbeddingCache(dir)
+	if err != nil {
+		t.Fatalf("reopening after Close: %v", err)
+	}
+	_ = store.Close()
+}
+
+func TestEmbeddingCacheNamespace_TEIServer(t *testing.T) {
+	a := EmbeddingCacheNamespace(EmbedderConfig{Type: "tei", TEIURL: "http://tei-a:8080", TEIModel: "bge-base"})
+	b := EmbeddingCacheNamespace(EmbedderConfig{Type: "tei", TEIURL: "http://tei-b:8080", TEIModel: "bge-base"})
+	if a == b {
+		t.Errorf("two TEI servers share the namespace %q", a)
+	}
+}
+
+func TestPostgresEmbeddingCache_LoadTouchesHits(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	c := &PostgresEmbeddingCache{db: db}
+
+	mock.ExpectQuery(`SELECT key, vector FROM embedding_cache WHERE key = ANY\(\$1\)`).
+		WithArgs(pq.Array([]string{"k1", "k2"})).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "vector"}).AddRow("k1", encodeVector([]float32{0.5, -1})))
+	mock.ExpectExec(`UPDATE embedding_cache SET used_at = NOW\(\)`).
+		WithArgs(pq.Array([]string{"k1"}), float64(3600)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	got, err := c.LoadEmbeddings(context.Background(), []string{"k1", "k2"})
+	if err != nil {
+		t.Fatalf("LoadEmbeddings: %v", err)
+	}
+	if want := map[string][]float32{"k1": {0.5, -1}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadEmbeddings = %v, want %v", got, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -8,28 +8,54 @@ import (
 
 // EmbedderConfig selects an Embedder backend.
 //
-// Type is one of "ollama" (default), "tei", or "vectorizer". Per-backend
-// fields are required only for the selected type; the others are ignored.
+// Type is one of "ollama" (default), "tei", "vectorizer", or "hash" (the
+// deterministic HashEmbedder, which needs no service). Per-backend fields are
+// required only for the selected type; the others are ignored.
+//
+// The config is also recorded with each embedding space (see
+// EmbeddingSpace), so a process can rebuild the embedder a space was filled
+// with; hence the JSON tags.
 type EmbedderConfig struct {
-	Type          string // "ollama" | "tei" | "vectorizer" (default "ollama")
-	OllamaURL     string
-	OllamaModel   string
-	TEIURL        string
-	TEIModel      string
-	VectorizerURL string
+	Type          string `json:"type,omitempty"` // "ollama" | "tei" | "vectorizer" | "hash" (default "ollama")
+	OllamaURL     string `json:"ollama_url,omitempty"`
+	OllamaModel   string `json:"ollama_model,omitempty"`
+	TEIURL        string `json:"tei_url,omitempty"`
+	TEIModel      string `json:"tei_model,omitempty"`
+	VectorizerURL string `json:"vectorizer_url,omitempty"`
+	// Dim is the vector dimension the model must return; 0 means
+	// ExpectedEmbeddingDim.
+	Dim int `json:"dim,omitempty"`
 }
 
-// ExpectedEmbeddingDim is the vector dimension the menu pipeline expects.
-// It matches the menu_items.embedding halfvec(768) column. If a different
-// embedder/model is configured, NewEmbedder's startup ping fails fast rather
-// than silently inserting wrong-dim vectors.
+// Model names the embedding model the config selects, as recorded on an
+// embedding space: the Ollama or TEI model, else the backend type.
+func (cfg EmbedderConfig) Model() string {
+	switch cfg.Type {
+	case "", "ollama":
+		return cfg.OllamaModel
+	case "tei":
+		return cfg.TEIModel
+	default:
+		return cfg.Type
+	}
+}
+
+// ExpectedEmbeddingDim is the default vector dimension: that of
+// nomic-embed-text, the model the seeded embedding spaces (and their
+// halfvec(768) columns) were filled with. A model of another dimension is
+// introduced as a new embedding space and configured with
+// EmbedderConfig.Dim.
 const ExpectedEmbeddingDim = 768
 
 // NewEmbedder builds the configured Embedder and validates it at startup by
 // embedding a ping string and asserting the returned dimension matches
-// ExpectedEmbeddingDim. This runs before any schema/collection init so a
-// misconfigured backend fails fast.
+// cfg.Dim (ExpectedEmbeddingDim by default). This runs before any
+// schema/collection init so a misconfigured backend fails fast.
 func NewEmbedder(ctx context.Context, cfg EmbedderConfig) (Embedder, error) {
+	dim := cfg.Dim
+	if dim == 0 {
+		dim = ExpectedEmbeddingDim
+	}
 	var e Embedder
 	switch cfg.Type {
 	case "", "ollama":
@@ -50,8 +76,10 @@ func NewEmbedder(ctx context.Context, cfg EmbedderConfig) (Embedder, error) {
 			return nil, errors.New("embedder=vectorizer requires --vectorizer-url")
 		}
 		e = NewVectorizerClient(cfg.VectorizerURL)
+	case "hash":
+		e = &HashEmbedder{dim: dim}
 	default:
-		return nil, fmt.Errorf("unknown --embedder %q (want ollama|tei|vectorizer)", cfg.Type)
+		return nil, fmt.Errorf("unknown --embedder %q (want ollama|tei|vectorizer|hash)", cfg.Type)
 	}
 
 	// Startup ping + dimension validation. Runs before EnsureMenuSchema so a
@@ -61,12 +89,12 @@ func NewEmbedder(ctx context.Context, cfg EmbedderConfig) (Embedder, error) {
 		_ = e.Close()
 		return nil, fmt.Errorf("embedder startup ping failed: %w", err)
 	}
-	if got := len(vec); got != ExpectedEmbeddingDim {
+	if got := len(vec); got != dim {
 		_ = e.Close()
 		return nil, fmt.Errorf(
-			"embedder returned %d-dim vectors, expected %d (menu_items.embedding is halfvec(%d)); "+
-				"check the model served by the %q backend",
-			got, ExpectedEmbeddingDim, ExpectedEmbeddingDim, cfg.Type)
+			"embedder returned %d-dim vectors, expected %d; "+
+				"check the model served by the %q backend (a model of another dimension needs --embedding-dim and its own embedding space)",
+			got, dim, cfg.Type)
 	}
 	return e, nil
 }
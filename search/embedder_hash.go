@@ -0,0 +1,82 @@
+package search
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+)
+
+// Verify HashEmbedder implements Embedder at compile time.
+var _ Embedder = (*HashEmbedder)(nil)
+
+// HashEmbedder is a deterministic, dependency-free Embedder for local
+// development and tests. It feature-hashes a text's word unigrams and bigrams
+// (tokenized as for BM25) into ExpectedEmbeddingDim signed buckets and
+// L2-normalises the result, so texts sharing words score a high cosine
+// similarity. It has no notion of meaning: "gluten" and "wheat" are as far
+// apart as any two words. Queries and documents are embedded alike.
+type HashEmbedder struct {
+	dim int
+}
+
+// NewHashEmbedder creates a hashing embedder producing ExpectedEmbeddingDim
+// vectors, so it fits the seeded halfvec(768) columns. NewEmbedder builds
+// one of EmbedderConfig.Dim dimensions instead.
+func NewHashEmbedder() *HashEmbedder {
+	return &HashEmbedder{dim: ExpectedEmbeddingDim}
+}
+
+// EmbedSingle returns the hashed vector of text.
+func (e *HashEmbedder) EmbedSingle(_ context.Context, text string) ([]float32, error) {
+	return e.embed(text), nil
+}
+
+// EmbedBatch returns the hashed vector of each text, in input order.
+func (e *HashEmbedder) EmbedBatch(_ context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	vecs := make([][]float32, len(texts))
+	for i, t := range texts {
+		vecs[i] = e.embed(t)
+	}
+	return vecs, nil
+}
+
+// Close is a no-op.
+func (e *HashEmbedder) Close() error { return nil }
+
+// embed hashes each feature to a bucket and a sign (the top bit of the same
+// FNV-1a hash). A text without tokens embeds to the zero vector.
+func (e *HashEmbedder) embed(text string) []float32 {
+	vec := make([]float32, e.dim)
+	terms := tokenize(text)
+	add := func(feature string) {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(feature))
+		sum := h.Sum64()
+		sign := float32(1)
+		if sum>>63 == 1 {
+			sign = -1
+		}
+		vec[sum%uint64(e.dim)] += sign
+	}
+	for i, t := range terms {
+		add(t)
+		if i > 0 {
+			add(terms[i-1] + " " + t)
+		}
+	}
+	var norm float64
+	for _, x := range vec {
+		norm += float64(x) * float64(x)
+	}
+	if norm == 0 {
+		return vec
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range vec {
+		vec[i] *= scale
+	}
+	return vec
+}
@@ -0,0 +1,40 @@
+package search
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestHashEmbedder(t *testing.T) {
+	e := NewHashEmbedder()
+	ctx := context.Background()
+
+	a, _ := e.EmbedSingle(ctx, "Garlic-free pasta")
+	b, _ := e.EmbedSingle(ctx, "garlic free PASTA")
+	if len(a) != ExpectedEmbeddingDim {
+		t.Fatalf("dim = %d, want %d", len(a), ExpectedEmbeddingDim)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Error("texts with the same tokens should embed identically")
+	}
+	if n := dot(a, a); n < 0.999 || n > 1.001 {
+		t.Errorf("squared norm = %v, want 1", n)
+	}
+
+	c, _ := e.EmbedSingle(ctx, "garlic bread")
+	d, _ := e.EmbedSingle(ctx, "chocolate mousse")
+	if dot(a, c) <= dot(a, d) {
+		t.Errorf("sharing a word should score higher: %v <= %v", dot(a, c), dot(a, d))
+	}
+
+	empty, _ := e.EmbedSingle(ctx, "!!")
+	if dot(empty, empty) != 0 {
+		t.Error("a text without tokens should embed to the zero vector")
+	}
+
+	vecs, err := e.EmbedBatch(ctx, []string{"garlic free pasta", "garlic bread"})
+	if err != nil || len(vecs) != 2 || !reflect.DeepEqual(vecs[0], b) {
+		t.Errorf("EmbedBatch = %d vectors, err %v; want input order", len(vecs), err)
+	}
+}
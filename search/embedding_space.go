@@ -0,0 +1,754 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// Collections whose Postgres vectors are tracked as embedding spaces.
+const (
+	CollectionReviews = "reviews" // review_chunks
+	CollectionMenu    = "menu"    // menu_items
+	CollectionFodmap  = "fodmap"  // fodmap_ingredients
+)
+
+// Embedding space statuses. A space is building while its column is filled,
+// ready once it is filled and indexed, active while it serves its
+// collection, and retired after another space replaced it.
+const (
+	SpaceBuilding = "building"
+	SpaceReady    = "ready"
+	SpaceActive   = "active"
+	SpaceRetired  = "retired"
+)
+
+// DefaultEmbeddingSpaceTTL is how long a PostgresClient following embedding
+// spaces trusts its view of the active spaces, and so how soon after a
+// cutover its queries and writes use the new model.
+const DefaultEmbeddingSpaceTTL = 30 * time.Second
+
+// EmbeddingSpaceGrace is how long after a cutover writes to the collection
+// clear the vector they store: a client still holding the replaced space
+// embeds with the old model until its view expires. Writes that record the
+// space they were embedded with are re-embedded at write time however late
+// they land (see SpaceEmbedder); the grace covers the writers that don't.
+// Once it has passed, ReembedBatch embeds the cleared rows with the new
+// model.
+const EmbeddingSpaceGrace = 2 * DefaultEmbeddingSpaceTTL
+
+// ErrEmbeddingSpaceIncomplete is returned by ActivateEmbeddingSpace when
+// rows were written or changed since the space was filled; filling it again
+// and retrying cuts over.
+var ErrEmbeddingSpaceIncomplete = errors.New("embedding space has rows left to embed")
+
+// EmbeddingSpace is the set of vectors one model produced for a collection.
+// The active space's vectors are in the collection's `embedding` column;
+// every other space has its own embedding_s<id> column, so the model and
+// dimension of any stored vector is known from the column it is in.
+type EmbeddingSpace struct {
+	ID         int64
+	Collection string
+	Model      string
+	Dim        int
+	// Embedder rebuilds the model's embedder. It is empty for the seeded
+	// spaces, which use whatever embedder the process was configured with.
+	Embedder    EmbedderConfig
+	Status      string
+	Column      string
+	CreatedAt   time.Time
+	ActivatedAt *time.Time
+	RetiredAt   *time.Time
+}
+
+// embeddingTable is where a collection's vectors live and how to rebuild
+// the text each one embeds.
+type embeddingTable struct {
+	table   string
+	key     string // primary key column
+	keyType string // its SQL type
+	index   string // HNSW index on the active `embedding` column
+	// texts selects the key and text of up to $1 rows whose %s column is
+	// NULL, plus the row's xmin so a row changed meanwhile is not
+	// overwritten with a stale vector.
+	texts string
+	scan  func(*sql.Rows) (key, text, xmin string, err error)
+	// single embeds with EmbedSingle, as the collection is written, rather
+	// than EmbedBatch.
+	single bool
+}
+
+var embeddingTables = map[string]embeddingTable{
+	CollectionReviews: {
+		table:   "review_chunks",
+		key:     "chunk_id",
+		keyType: "int",
+		index:   "idx_review_chunks_embedding",
+		texts:   `SELECT chunk_id::text, coalesce(chunk_text, ''), xmin::text FROM review_chunks WHERE %s IS NULL ORDER BY chunk_id LIMIT $1`,
+		scan: func(rows *sql.Rows) (key, text, xmin string, err error) {
+			err = rows.Scan(&key, &text, &xmin)
+			return key, text, xmin, err
+		},
+	},
+	CollectionMenu: {
+		table:   "menu_items",
+		key:     "menu_item_id",
+		keyType: "text",
+		index:   "idx_menu_items_embedding",
+		texts: `SELECT menu_item_id, coalesce(restaurant_name, ''), dish_name, coalesce(description, ''), stated_ingredients,
+		               coalesce(dish_name_en, ''), coalesce(description_en, ''), stated_ingredients_en, xmin::text
+		        FROM menu_items WHERE %s IS NULL ORDER BY menu_item_id LIMIT $1`,
+		scan: func(rows *sql.Rows) (key, text, xmin string, err error) {
+			var m MenuItem
+			err = rows.Scan(&key, &m.RestaurantName, &m.DishName, &m.Description, (*pgxStringArray)(&m.StatedIngredients),
+				&m.DishNameEn, &m.DescriptionEn, (*pgxStringArray)(&m.StatedIngredientsEn), &xmin)
+			return key, MenuEmbeddingText(m), xmin, err
+		},
+	},
+	CollectionFodmap: {
+		table:   "fodmap_ingredients",
+		key:     "ingredient",
+		keyType: "text",
+		index:   "idx_fodmap_embedding",
+		texts:   `SELECT ingredient, ingredient, xmin::text FROM fodmap_ingredients WHERE %s IS NULL ORDER BY ingredient LIMIT $1`,
+		scan: func(rows *sql.Rows) (key, text, xmin string, err error) {
+			err = rows.Scan(&key, &text, &xmin)
+			return key, text, xmin, err
+		},
+		single: true,
+	},
+}
+
+// MenuEmbeddingText is the text a menu item's vector embeds: the dish in
+// the context of its restaurant, its description and stated ingredients,
+// and their English translation when the menu has one, which lets English
+// queries find dishes from menus written in other languages.
+func MenuEmbeddingText(m MenuItem) string {
+	parts := []string{"Menu item at " + m.RestaurantName + ": " + m.DishName}
+	if m.Description != "" {
+		parts = append(parts, m.Description)
+	}
+	if len(m.StatedIngredients) > 0 {
+		parts = append(parts, "Stated ingredients: "+strings.Join(m.StatedIngredients, ", "))
+	}
+	if m.DishNameEn != "" || m.DescriptionEn != "" || len(m.StatedIngredientsEn) > 0 {
+		parts = append(parts, "In English: "+m.DishNameEn)
+		if m.DescriptionEn != "" {
+			parts = append(parts, m.DescriptionEn)
+		}
+		if len(m.StatedIngredientsEn) > 0 {
+			parts = append(parts, "Ingredients: "+strings.Join(m.StatedIngredientsEn, ", "))
+		}
+	}
+	return strings.Join(parts, ". ")
+}
+
+const embeddingSpaceColumns = `id, collection, model, dim, embedder, status, column_name, created_at, activated_at, retired_at`
+
+func scanEmbeddingSpace(row interface{ Scan(...any) error }) (EmbeddingSpace, error) {
+	var s EmbeddingSpace
+	var cfg []byte
+	var activated, retired sql.NullTime
+	if err := row.Scan(&s.ID, &s.Collection, &s.Model, &s.Dim, &cfg, &s.Status, &s.Column, &s.CreatedAt, &activated, &retired); err != nil {
+		return EmbeddingSpace{}, err
+	}
+	if err := json.Unmarshal(cfg, &s.Embedder); err != nil {
+		return EmbeddingSpace{}, fmt.Errorf("decode embedder of embedding space %d: %w", s.ID, err)
+	}
+	if activated.Valid {
+		s.ActivatedAt = &activated.Time
+	}
+	if retired.Valid {
+		s.RetiredAt = &retired.Time
+	}
+	return s, nil
+}
+
+// EmbeddingSpaces lists every embedding space, oldest first.
+func (c *PostgresClient) EmbeddingSpaces(ctx context.Context) ([]EmbeddingSpace, error) {
+	rows, err := c.db.QueryContext(ctx, `SELECT `+embeddingSpaceColumns+` FROM embedding_spaces ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("query embedding spaces: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var spaces []EmbeddingSpace
+	for rows.Next() {
+		s, err := scanEmbeddingSpace(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan embedding space: %w", err)
+		}
+		spaces = append(spaces, s)
+	}
+	return spaces, rows.Err()
+}
+
+// ActiveEmbeddingSpace returns collection's active embedding space, and
+// false when it has none.
+func (c *PostgresClient) ActiveEmbeddingSpace(ctx context.Context, collection string) (EmbeddingSpace, bool, error) {
+	s, err := scanEmbeddingSpace(c.db.QueryRowContext(ctx,
+		`SELECT `+embeddingSpaceColumns+` FROM embedding_spaces WHERE collection = $1 AND status = 'active'`, collection))
+	if errors.Is(err, sql.ErrNoRows) {
+		return EmbeddingSpace{}, false, nil
+	}
+	if err != nil {
+		return EmbeddingSpace{}, false, fmt.Errorf("query active %s embedding space: %w", collection, err)
+	}
+	return s, true, nil
+}
+
+// EmbeddingSpace returns the embedding space with the given ID.
+func (c *PostgresClient) EmbeddingSpace(ctx context.Context, id int64) (EmbeddingSpace, error) {
+	s, err := scanEmbeddingSpace(c.db.QueryRowContext(ctx, `SELECT `+embeddingSpaceColumns+` FROM embedding_spaces WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return EmbeddingSpace{}, fmt.Errorf("embedding space %d not found", id)
+	}
+	if err != nil {
+		return EmbeddingSpace{}, fmt.Errorf("query embedding space %d: %w", id, err)
+	}
+	return s, nil
+}
+
+// EmbeddingSpaceProgress returns how many of the collection's rows have a
+// vector in the space, out of how many rows there are.
+func (c *PostgresClient) EmbeddingSpaceProgress(ctx context.Context, s EmbeddingSpace) (filled, total int64, err error) {
+	t, ok := embeddingTables[s.Collection]
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown collection %q", s.Collection)
+	}
+	err = c.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT count(%s), count(*) FROM %s`, s.Column, t.table)).Scan(&filled, &total)
+	if err != nil {
+		return 0, 0, fmt.Errorf("count %s vectors: %w", t.table, err)
+	}
+	return filled, total, nil
+}
+
+// CreateEmbeddingSpace starts a new embedding space for collection, filled
+// with the embedder cfg describes: it adds the space's halfvec(dim) column
+// and a trigger clearing a row's vector in it whenever the active vector
+// changes, so rows rewritten during the fill are embedded again. Only one
+// space per collection can be pending at a time.
+func (c *PostgresClient) CreateEmbeddingSpace(ctx context.Context, collection string, cfg EmbedderConfig) (EmbeddingSpace, error) {
+	t, ok := embeddingTables[collection]
+	if !ok {
+		return EmbeddingSpace{}, fmt.Errorf("unknown collection %q (want reviews|menu|fodmap)", collection)
+	}
+	if cfg.Dim == 0 {
+		cfg.Dim = ExpectedEmbeddingDim
+	}
+	if cfg.Model() == "" {
+		return EmbeddingSpace{}, errors.New("embedder config names no model")
+	}
+	cfgJSON, err := json.Marshal(cfg)
+	if err != nil {
+		return EmbeddingSpace{}, fmt.Errorf("encode embedder config: %w", err)
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return EmbeddingSpace{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO embedding_spaces (collection, model, dim, embedder, status, column_name)
+		VALUES ($1, $2, $3, $4, 'building', '')
+		RETURNING id
+	`, collection, cfg.Model(), cfg.Dim, cfgJSON).Scan(&id)
+	if err != nil {
+		return EmbeddingSpace{}, fmt.Errorf("insert embedding space (is another %s migration pending?): %w", collection, err)
+	}
+	col := spaceColumn(id)
+	stmts := []string{
+		fmt.Sprintf(`UPDATE embedding_spaces SET column_name = '%s' WHERE id = %d`, col, id),
+		fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s halfvec(%d)`, t.table, col, cfg.Dim),
+		fmt.Sprintf(`CREATE FUNCTION %[1]s_invalidate() RETURNS trigger LANGUAGE plpgsql AS $$
+			BEGIN
+				NEW.%[1]s := NULL;
+				RETURN NEW;
+			END $$`, col),
+		fmt.Sprintf(`CREATE TRIGGER %[1]s_invalidate BEFORE UPDATE OF embedding ON %[2]s
+			FOR EACH ROW WHEN (NEW.embedding IS DISTINCT FROM OLD.embedding)
+			EXECUTE FUNCTION %[1]s_invalidate()`, col, t.table),
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return EmbeddingSpace{}, fmt.Errorf("create embedding space column: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return EmbeddingSpace{}, fmt.Errorf("commit: %w", err)
+	}
+	return c.EmbeddingSpace(ctx, id)
+}
+
+// spaceColumn is the column of a space that is not active.
+func spaceColumn(id int64) string {
+	return fmt.Sprintf("embedding_s%d", id)
+}
+
+// ReembedBatch embeds up to limit rows of s's collection that have no
+// vector in s yet and stores them, returning how many it embedded; 0 means
+// the space is filled. For an active space those are the rows written
+// during its cutover grace period, which it embeds once the period is over.
+// e must be s's embedder.
+func (c *PostgresClient) ReembedBatch(ctx context.Context, s EmbeddingSpace, e Embedder, limit int) (int, error) {
+	t, ok := embeddingTables[s.Collection]
+	if !ok {
+		return 0, fmt.Errorf("unknown collection %q", s.Collection)
+	}
+	switch s.Status {
+	case SpaceBuilding, SpaceReady:
+	case SpaceActive:
+		if s.ActivatedAt != nil && time.Since(*s.ActivatedAt) < EmbeddingSpaceGrace {
+			return 0, fmt.Errorf("embedding space %d was activated less than %s ago; its writes are still being cleared", s.ID, EmbeddingSpaceGrace)
+		}
+	default:
+		return 0, fmt.Errorf("embedding space %d is %s, not being built", s.ID, s.Status)
+	}
+
+	rows, err := c.db.QueryContext(ctx, fmt.Sprintf(t.texts, s.Column), limit)
+	if err != nil {
+		return 0, fmt.Errorf("select %s to embed: %w", t.table, err)
+	}
+	var keys, texts, xmins []string
+	for rows.Next() {
+		key, text, xmin, err := t.scan(rows)
+		if err != nil {
+			_ = rows.Close()
+			return 0, fmt.Errorf("scan %s: %w", t.table, err)
+		}
+		keys, texts, xmins = append(keys, key), append(texts, text), append(xmins, xmin)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("select %s to embed: %w", t.table, err)
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	var vecs [][]float32
+	if t.single {
+		for _, text := range texts {
+			vec, err := e.EmbedSingle(ctx, text)
+			if err != nil {
+				return 0, fmt.Errorf("embed %q: %w", text, err)
+			}
+			vecs = append(vecs, vec)
+		}
+	} else if vecs, err = e.EmbedBatch(ctx, texts); err != nil {
+		return 0, fmt.Errorf("embed %s batch: %w", t.table, err)
+	}
+	if len(vecs) != len(keys) {
+		return 0, fmt.Errorf("embedder returned %d vectors for %d texts", len(vecs), len(keys))
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf(`UPDATE %s SET %s = $1 WHERE %s = $2::text::%s AND xmin::text = $3`,
+		t.table, s.Column, t.key, t.keyType))
+	if err != nil {
+		return 0, fmt.Errorf("prepare stmt: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+	for i, key := range keys {
+		if got := len(vecs[i]); got != s.Dim {
+			return 0, fmt.Errorf("embedder returned a %d-dim vector for embedding space %d of %d dims", got, s.ID, s.Dim)
+		}
+		if _, err := stmt.ExecContext(ctx, pgvector.NewHalfVector(vecs[i]), key, xmins[i]); err != nil {
+			return 0, fmt.Errorf("store %s %s vector: %w", t.table, key, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+	return len(keys), nil
+}
+
+// BuildEmbeddingIndex builds the HNSW index of a filled space without
+// blocking writes and marks the space ready for cutover.
+func (c *PostgresClient) BuildEmbeddingIndex(ctx context.Context, s EmbeddingSpace) error {
+	t, ok := embeddingTables[s.Collection]
+	if !ok {
+		return fmt.Errorf("unknown collection %q", s.Collection)
+	}
+	index := t.index + "_s" + fmt.Sprint(s.ID)
+	if _, err := c.db.ExecContext(ctx, fmt.Sprintf(`CREATE INDEX CONCURRENTLY IF NOT EXISTS %s ON %s USING hnsw (%s halfvec_cosine_ops)`,
+		index, t.table, s.Column)); err != nil {
+		// A failed concurrent build leaves an invalid index that IF NOT
+		// EXISTS would keep skipping.
+		_, _ = c.db.ExecContext(ctx, `DROP INDEX CONCURRENTLY IF EXISTS `+index)
+		return fmt.Errorf("build %s: %w", index, err)
+	}
+	if _, err := c.db.ExecContext(ctx, `UPDATE embedding_spaces SET status = 'ready' WHERE id = $1 AND status = 'building'`, s.ID); err != nil {
+		return fmt.Errorf("mark embedding space %d ready: %w", s.ID, err)
+	}
+	return nil
+}
+
+// ActivateEmbeddingSpace cuts a ready space over to serve its collection.
+// In one transaction, with writes to the table blocked, it checks every row
+// has a vector in the space, then renames the active `embedding` column and
+// its index aside and the space's into their place, so queries never see a
+// mix of models. The replaced space is retired with its column kept until
+// DropEmbeddingSpace.
+//
+// Clients following embedding spaces keep embedding with the replaced model
+// until their view expires. Their writes recording the replaced space are
+// re-embedded when written; for the others, for EmbeddingSpaceGrace a
+// trigger clears the vector of every row written to the collection, and
+// ReembedBatch embeds those rows again afterwards and
+// EndEmbeddingSpaceGrace drops the trigger.
+func (c *PostgresClient) ActivateEmbeddingSpace(ctx context.Context, id int64) error {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	s, err := scanEmbeddingSpace(tx.QueryRowContext(ctx, `SELECT `+embeddingSpaceColumns+` FROM embedding_spaces WHERE id = $1 FOR UPDATE`, id))
+	if err != nil {
+		return fmt.Errorf("load embedding space %d: %w", id, err)
+	}
+	if s.Status != SpaceReady {
+		return fmt.Errorf("embedding space %d is %s; it must be filled and indexed (ready) to activate", id, s.Status)
+	}
+	t := embeddingTables[s.Collection]
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE `+t.table+` IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return fmt.Errorf("lock %s: %w", t.table, err)
+	}
+	var missing int64
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT count(*) FROM %s WHERE %s IS NULL`, t.table, s.Column)).Scan(&missing); err != nil {
+		return fmt.Errorf("count unembedded %s: %w", t.table, err)
+	}
+	if missing > 0 {
+		return fmt.Errorf("%w: %d %s rows", ErrEmbeddingSpaceIncomplete, missing, t.table)
+	}
+	var oldID int64
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM embedding_spaces WHERE collection = $1 AND status = 'active' FOR UPDATE`, s.Collection).Scan(&oldID); err != nil {
+		return fmt.Errorf("load active %s embedding space: %w", s.Collection, err)
+	}
+
+	oldCol := spaceColumn(oldID)
+	stmts := []string{
+		fmt.Sprintf(`DROP TRIGGER IF EXISTS %[1]s_invalidate ON %[2]s`, s.Column, t.table),
+		fmt.Sprintf(`DROP FUNCTION IF EXISTS %s_invalidate()`, s.Column),
+		fmt.Sprintf(`DROP TRIGGER IF EXISTS %[1]s_grace ON %[2]s`, oldCol, t.table),
+		fmt.Sprintf(`DROP FUNCTION IF EXISTS %s_grace()`, oldCol),
+		fmt.Sprintf(`ALTER TABLE %s RENAME COLUMN embedding TO %s`, t.table, oldCol),
+		fmt.Sprintf(`ALTER INDEX IF EXISTS %[1]s RENAME TO %[1]s_s%[2]d`, t.index, oldID),
+		fmt.Sprintf(`ALTER TABLE %s RENAME COLUMN %s TO embedding`, t.table, s.Column),
+		fmt.Sprintf(`ALTER INDEX %[1]s_s%[2]d RENAME TO %[1]s`, t.index, s.ID),
+		fmt.Sprintf(`UPDATE embedding_spaces SET status = 'retired', column_name = '%s', retired_at = NOW() WHERE id = %d`, oldCol, oldID),
+		fmt.Sprintf(`UPDATE embedding_spaces SET status = 'active', column_name = 'embedding', activated_at = NOW() WHERE id = %d`, s.ID),
+		fmt.Sprintf(`CREATE FUNCTION %[1]s_grace() RETURNS trigger LANGUAGE plpgsql AS $$
+			BEGIN
+				IF now() < (SELECT activated_at FROM embedding_spaces WHERE id = %[2]d) + interval '%[3]d seconds' THEN
+					NEW.embedding := NULL;
+				END IF;
+				RETURN NEW;
+			END $$`, s.Column, s.ID, int(EmbeddingSpaceGrace.Seconds())),
+		fmt.Sprintf(`CREATE TRIGGER %[1]s_grace BEFORE INSERT OR UPDATE OF embedding ON %[2]s
+			FOR EACH ROW WHEN (NEW.embedding IS NOT NULL)
+			EXECUTE FUNCTION %[1]s_grace()`, s.Column, t.table),
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("cut over %s embedding space: %w", s.Collection, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	slog.Info("embedding space activated", "collection", s.Collection, "space", s.ID, "model", s.Model, "dim", s.Dim, "retired", oldID)
+	return nil
+}
+
+// EndEmbeddingSpaceGrace drops the trigger ActivateEmbeddingSpace left
+// clearing the vectors written to an active space's collection, once its
+// grace period is over and the cleared rows are embedded again.
+func (c *PostgresClient) EndEmbeddingSpaceGrace(ctx context.Context, s EmbeddingSpace) error {
+	t, ok := embeddingTables[s.Collection]
+	if !ok {
+		return fmt.Errorf("unknown collection %q", s.Collection)
+	}
+	col := spaceColumn(s.ID)
+	for _, stmt := range []string{
+		fmt.Sprintf(`DROP TRIGGER IF EXISTS %[1]s_grace ON %[2]s`, col, t.table),
+		fmt.Sprintf(`DROP FUNCTION IF EXISTS %s_grace()`, col),
+	} {
+		if _, err := c.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("end grace period of embedding space %d: %w", s.ID, err)
+		}
+	}
+	return nil
+}
+
+// DropEmbeddingSpace deletes a space that is not active: a retired one
+// once its model is no longer needed for rollback, or a pending one to
+// abandon a migration. Its column, index and trigger go with it.
+func (c *PostgresClient) DropEmbeddingSpace(ctx context.Context, id int64) error {
+	s, err := c.EmbeddingSpace(ctx, id)
+	if err != nil {
+		return err
+	}
+	if s.Status == SpaceActive {
+		return fmt.Errorf("embedding space %d is active; activate another space first", id)
+	}
+	t := embeddingTables[s.Collection]
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	stmts := []string{
+		fmt.Sprintf(`DROP TRIGGER IF EXISTS %[1]s_invalidate ON %[2]s`, s.Column, t.table),
+		fmt.Sprintf(`DROP FUNCTION IF EXISTS %s_invalidate()`, s.Column),
+		fmt.Sprintf(`ALTER TABLE %s DROP COLUMN IF EXISTS %s`, t.table, s.Column),
+		fmt.Sprintf(`DELETE FROM embedding_spaces WHERE id = %d`, id),
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("drop embedding space %d: %w", id, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// spaceFollower caches a PostgresClient's view of the active embedding
+// spaces and the embedders built for them.
+type spaceFollower struct {
+	ttl       time.Duration
+	mu        sync.Mutex
+	loadedAt  time.Time
+	active    map[string]EmbeddingSpace
+	embedders map[int64]Embedder
+}
+
+// FollowEmbeddingSpaces makes the client embed each collection's queries
+// (and FODMAP ingredient writes) with the embedder of its active embedding
+// space, re-reading the active spaces every ttl, so a cutover takes effect
+// without a restart. Spaces with no recorded embedder use the client's
+// own. Without it, the client always uses its own embedder.
+func (c *PostgresClient) FollowEmbeddingSpaces(ttl time.Duration) {
+	c.spaces = &spaceFollower{ttl: ttl, embedders: make(map[int64]Embedder)}
+}
+
+// embedderFor returns the embedder of collection's active space and the
+// space's ID, 0 when the client does not follow embedding spaces or knows
+// no active space for collection.
+func (c *PostgresClient) embedderFor(ctx context.Context, collection string) (Embedder, int64, error) {
+	f := c.spaces
+	if f == nil {
+		return c.embedder, 0, nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if time.Since(f.loadedAt) >= f.ttl {
+		if active, err := c.activeEmbeddingSpaces(ctx); err != nil {
+			slog.Warn("refreshing embedding spaces failed; keeping the last known", "error", err)
+		} else {
+			f.active = active
+		}
+		f.loadedAt = time.Now()
+	}
+	s, ok := f.active[collection]
+	if !ok {
+		return c.embedder, 0, nil
+	}
+	e, err := c.spaceEmbedderLocked(ctx, s)
+	return e, s.ID, err
+}
+
+// spaceEmbedderLocked returns the embedder of space s, built once and kept
+// in the follower's cache; the client's own for a space with no recorded
+// embedder. The caller holds c.spaces.mu.
+func (c *PostgresClient) spaceEmbedderLocked(ctx context.Context, s EmbeddingSpace) (Embedder, error) {
+	if s.Embedder == (EmbedderConfig{}) {
+		return c.embedder, nil
+	}
+	f := c.spaces
+	if e, ok := f.embedders[s.ID]; ok {
+		return e, nil
+	}
+	e, err := NewEmbedder(ctx, s.Embedder)
+	if err != nil {
+		return nil, fmt.Errorf("embedder of %s embedding space %d (%s): %w", s.Collection, s.ID, s.Model, err)
+	}
+	f.embedders[s.ID] = e
+	return e, nil
+}
+
+func (c *PostgresClient) activeEmbeddingSpaces(ctx context.Context) (map[string]EmbeddingSpace, error) {
+	rows, err := c.db.QueryContext(ctx, `SELECT `+embeddingSpaceColumns+` FROM embedding_spaces WHERE status = 'active'`)
+	if err != nil {
+		return nil, fmt.Errorf("query active embedding spaces: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	active := make(map[string]EmbeddingSpace)
+	for rows.Next() {
+		s, err := scanEmbeddingSpace(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan embedding space: %w", err)
+		}
+		active[s.Collection] = s
+	}
+	return active, rows.Err()
+}
+
+// embedSingle embeds text with the embedder of collection's active space.
+func (c *PostgresClient) embedSingle(ctx context.Context, collection, text string) ([]float32, error) {
+	e, _, err := c.embedderFor(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+	return e.EmbedSingle(ctx, text)
+}
+
+// SpaceEmbedder is an Embedder that embeds with an embedding space, as
+// PostgresClient.CollectionEmbedder's does. EmbedBatchInSpace also returns
+// the space's ID (0 when unknown), which callers record on what they
+// vectorise (Chunk.EmbeddingSpace, MenuItem.EmbeddingSpace): a write that
+// lands after a cutover, however long after it was embedded, is then
+// re-embedded with the new space instead of storing the old model's vectors.
+type SpaceEmbedder interface {
+	Embedder
+	EmbedBatchInSpace(ctx context.Context, texts []string) ([][]float32, int64, error)
+}
+
+// EmbedBatchInSpace embeds texts with e and returns the embedding space
+// they were embedded with: e's when e is a SpaceEmbedder, else 0.
+func EmbedBatchInSpace(ctx context.Context, e Embedder, texts []string) ([][]float32, int64, error) {
+	if se, ok := e.(SpaceEmbedder); ok {
+		return se.EmbedBatchInSpace(ctx, texts)
+	}
+	vecs, err := e.EmbedBatch(ctx, texts)
+	return vecs, 0, err
+}
+
+// CollectionEmbedder returns an Embedder that embeds with collection's
+// active space, for callers that vectorise documents before writing them
+// (the indexer for reviews, the scrape pipeline for menus). It is the
+// client's own embedder unless FollowEmbeddingSpaces was called, and a
+// SpaceEmbedder otherwise. Closing it is a no-op.
+func (c *PostgresClient) CollectionEmbedder(collection string) Embedder {
+	if c.spaces == nil {
+		return c.embedder
+	}
+	return &spaceEmbedder{c: c, collection: collection}
+}
+
+type spaceEmbedder struct {
+	c          *PostgresClient
+	collection string
+}
+
+func (s *spaceEmbedder) EmbedSingle(ctx context.Context, text string) ([]float32, error) {
+	return s.c.embedSingle(ctx, s.collection, text)
+}
+
+func (s *spaceEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	vecs, _, err := s.EmbedBatchInSpace(ctx, texts)
+	return vecs, err
+}
+
+func (s *spaceEmbedder) EmbedBatchInSpace(ctx context.Context, texts []string) ([][]float32, int64, error) {
+	e, id, err := s.c.embedderFor(ctx, s.collection)
+	if err != nil {
+		return nil, 0, err
+	}
+	vecs, err := e.EmbedBatch(ctx, texts)
+	return vecs, id, err
+}
+
+func (s *spaceEmbedder) Close() error { return nil }
+
+// reembedStaleBatch is the number of stale vectors reembedStale embeds per
+// call to the embedder.
+const reembedStaleBatch = 100
+
+// reembedStale re-embeds, with collection's active space, the n vectors of
+// a write whose recorded space (space(i); 0 when unknown) is another one:
+// vectors embedded before a cutover and written after it. text(i) is the
+// text vector i was embedded from and set(i, v) replaces it. It runs in the
+// write's transaction and first locks the collection's table against a
+// cutover, so the space it embeds with stays active until the write
+// commits. It does nothing when no vector records a space; those writes are
+// left to the cutover's grace trigger.
+func (c *PostgresClient) reembedStale(ctx context.Context, tx *sql.Tx, collection string, n int,
+	space func(i int) int64, text func(i int) string, set func(i int, v []float32)) error {
+	stamped := false
+	for i := range n {
+		stamped = stamped || space(i) != 0
+	}
+	if !stamped {
+		return nil
+	}
+	t := embeddingTables[collection]
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE `+t.table+` IN ROW EXCLUSIVE MODE`); err != nil {
+		return fmt.Errorf("lock %s: %w", t.table, err)
+	}
+	active, err := scanEmbeddingSpace(tx.QueryRowContext(ctx,
+		`SELECT `+embeddingSpaceColumns+` FROM embedding_spaces WHERE collection = $1 AND status = 'active'`, collection))
+	if err != nil {
+		return fmt.Errorf("load active %s embedding space: %w", collection, err)
+	}
+	var stale []int
+	for i := range n {
+		if id := space(i); id != 0 && id != active.ID {
+			stale = append(stale, i)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	var e Embedder
+	if c.spaces != nil {
+		c.spaces.mu.Lock()
+		e, err = c.spaceEmbedderLocked(ctx, active)
+		c.spaces.mu.Unlock()
+	} else if active.Embedder == (EmbedderConfig{}) {
+		e = c.embedder
+	} else {
+		e, err = NewEmbedder(ctx, active.Embedder)
+		if err == nil {
+			defer func() { _ = e.Close() }()
+		}
+	}
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(stale); start += reembedStaleBatch {
+		batch := stale[start:min(start+reembedStaleBatch, len(stale))]
+		texts := make([]string, len(batch))
+		for j, i := range batch {
+			texts[j] = text(i)
+		}
+		vecs, err := e.EmbedBatch(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("re-embed %d %s vectors with embedding space %d: %w", len(texts), collection, active.ID, err)
+		}
+		if len(vecs) != len(texts) {
+			return fmt.Errorf("re-embed %s vectors with embedding space %d: got %d vectors for %d texts", collection, active.ID, len(vecs), len(texts))
+		}
+		for j, i := range batch {
+			set(i, vecs[j])
+		}
+	}
+	slog.Info("re-embedded vectors embedded before a cutover", "collection", collection, "space", active.ID, "vectors", len(stale))
+	return nil
+}
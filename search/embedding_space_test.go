@@ -0,0 +1,292 @@
+package search
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pgvector/pgvector-go"
+)
+
+var spaceRowColumns = []string{"id", "collection", "model", "dim", "embedder", "status", "column_name", "created_at", "activated_at", "retired_at"}
+
+func TestMenuEmbeddingText(t *testing.T) {
+	got := MenuEmbeddingText(MenuItem{
+		RestaurantName:    "Trattoria",
+		DishName:          "Pasta al pomodoro",
+		StatedIngredients: []string{"pomodoro", "aglio"},
+	})
+	want := "Menu item at Trattoria: Pasta al pomodoro. Stated ingredients: pomodoro, aglio"
+	if got != want {
+		t.Errorf("untranslated text = %q, want %q", got, want)
+	}
+
+	got = MenuEmbeddingText(MenuItem{
+		RestaurantName:      "Trattoria",
+		DishName:            "Pasta al pomodoro",
+		Description:         "Fatta in casa",
+		StatedIngredients:   []string{"pomodoro", "aglio"},
+		DishNameEn:          "Tomato pasta",
+		DescriptionEn:       "Homemade",
+		StatedIngredientsEn: []string{"tomato", "garlic"},
+	})
+	want = "Menu item at Trattoria: Pasta al pomodoro. Fatta in casa. Stated ingredients: pomodoro, aglio. " +
+		"In English: Tomato pasta. Homemade. Ingredients: tomato, garlic"
+	if got != want {
+		t.Errorf("translated text = %q, want %q", got, want)
+	}
+}
+
+func TestPostgresClient_ReembedBatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	client := &PostgresClient{db: db}
+	space := EmbeddingSpace{ID: 4, Collection: CollectionFodmap, Dim: 2, Status: SpaceBuilding, Column: "embedding_s4"}
+
+	mock.ExpectQuery(`SELECT ingredient, ingredient, xmin::text FROM fodmap_ingredients WHERE embedding_s4 IS NULL`).
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"ingredient", "ingredient", "xmin"}).
+			AddRow("garlic", "garlic", "701").
+			AddRow("onion", "onion", "702"))
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare(`UPDATE fodmap_ingredients SET embedding_s4 = \$1 WHERE ingredient = \$2::text::text AND xmin::text = \$3`)
+	prep.ExpectExec().
+		WithArgs(pgvector.NewHalfVector([]float32{0.6, 0.8}), "garlic", "701").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// onion changed since it was read; the guard skips it and the next
+	// batch picks it up again.
+	prep.ExpectExec().
+		WithArgs(pgvector.NewHalfVector([]float32{0.6, 0.8}), "onion", "702").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	n, err := client.ReembedBatch(context.Background(), space, &mockEmbedder{vec: []float32{0.6, 0.8}}, 10)
+	if err != nil {
+		t.Fatalf("ReembedBatch: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("embedded %d rows, want 2", n)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresClient_ReembedBatch_WrongDim(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	client := &PostgresClient{db: db}
+	space := EmbeddingSpace{ID: 4, Collection: CollectionFodmap, Dim: 3, Status: SpaceBuilding, Column: "embedding_s4"}
+
+	mock.ExpectQuery(`FROM fodmap_ingredients WHERE embedding_s4 IS NULL`).
+		WillReturnRows(sqlmock.NewRows([]string{"ingredient", "ingredient", "xmin"}).AddRow("garlic", "garlic", "701"))
+	mock.ExpectBegin()
+	mock.ExpectPrepare(`UPDATE fodmap_ingredients`)
+	mock.ExpectRollback()
+
+	if _, err := client.ReembedBatch(context.Background(), space, &mockEmbedder{vec: []float32{0.6, 0.8}}, 10); err == nil {
+		t.Fatal("ReembedBatch stored a vector of the wrong dimension")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresClient_ReembedBatch_ActiveGrace(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	client := &PostgresClient{db: db}
+	activated := time.Now().Add(-time.Second)
+	space := EmbeddingSpace{ID: 4, Collection: CollectionFodmap, Dim: 2, Status: SpaceActive, Column: "embedding", ActivatedAt: &activated}
+
+	// Within the grace period the trigger would clear the vectors again.
+	if _, err := client.ReembedBatch(context.Background(), space, &mockEmbedder{vec: []float32{0.6, 0.8}}, 10); err == nil {
+		t.Fatal("ReembedBatch embedded an active space within its grace period")
+	}
+
+	// Afterwards it embeds the rows whose vectors were cleared.
+	activated = time.Now().Add(-EmbeddingSpaceGrace)
+	mock.ExpectQuery(`SELECT ingredient, ingredient, xmin::text FROM fodmap_ingredients WHERE embedding IS NULL`).
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"ingredient", "ingredient", "xmin"}).AddRow("garlic", "garlic", "701"))
+	mock.ExpectBegin()
+	mock.ExpectPrepare(`UPDATE fodmap_ingredients SET embedding = \$1`).ExpectExec().
+		WithArgs(pgvector.NewHalfVector([]float32{0.6, 0.8}), "garlic", "701").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if n, err := client.ReembedBatch(context.Background(), space, &mockEmbedder{vec: []float32{0.6, 0.8}}, 10); err != nil || n != 1 {
+		t.Fatalf("ReembedBatch after grace = %d, %v; want 1, nil", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresClient_ActivateEmbeddingSpace(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	client := &PostgresClient{db: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM embedding_spaces WHERE id = \$1 FOR UPDATE`).
+		WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows(spaceRowColumns).
+			AddRow(4, CollectionMenu, "mxbai-embed-large", 1024, []byte(`{"type":"ollama","ollama_model":"mxbai-embed-large","dim":1024}`),
+				SpaceReady, "embedding_s4", time.Now(), nil, nil))
+	mock.ExpectExec(`LOCK TABLE menu_items IN SHARE ROW EXCLUSIVE MODE`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM menu_items WHERE embedding_s4 IS NULL`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT id FROM embedding_spaces WHERE collection = \$1 AND status = 'active'`).
+		WithArgs(CollectionMenu).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	for _, stmt := range []string{
+		`DROP TRIGGER IF EXISTS embedding_s4_invalidate ON menu_items`,
+		`DROP FUNCTION IF EXISTS embedding_s4_invalidate\(\)`,
+		`DROP TRIGGER IF EXISTS embedding_s2_grace ON menu_items`,
+		`DROP FUNCTION IF EXISTS embedding_s2_grace\(\)`,
+		`ALTER TABLE menu_items RENAME COLUMN embedding TO embedding_s2`,
+		`ALTER INDEX IF EXISTS idx_menu_items_embedding RENAME TO idx_menu_items_embedding_s2`,
+		`ALTER TABLE menu_items RENAME COLUMN embedding_s4 TO embedding`,
+		`ALTER INDEX idx_menu_items_embedding_s4 RENAME TO idx_menu_items_embedding`,
+		`UPDATE embedding_spaces SET status = 'retired', column_name = 'embedding_s2'`,
+		`UPDATE embedding_spaces SET status = 'active', column_name = 'embedding'`,
+		// Writers on the replaced model clear what they write until the
+		// grace period is over.
+		`CREATE FUNCTION embedding_s4_grace\(\)(.|\n)*WHERE id = 4\) \+ interval '60 seconds' THEN\s+NEW.embedding := NULL`,
+		`CREATE TRIGGER embedding_s4_grace BEFORE INSERT OR UPDATE OF embedding ON menu_items`,
+	} {
+		mock.ExpectExec(stmt).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	mock.ExpectCommit()
+
+	if err := client.ActivateEmbeddingSpace(context.Background(), 4); err != nil {
+		t.Fatalf("ActivateEmbeddingSpace: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresClient_ActivateEmbeddingSpace_Incomplete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	client := &PostgresClient{db: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM embedding_spaces WHERE id = \$1 FOR UPDATE`).
+		WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows(spaceRowColumns).
+			AddRow(4, CollectionReviews, "mxbai-embed-large", 1024, []byte(`{}`), SpaceReady, "embedding_s4", time.Now(), nil, nil))
+	mock.ExpectExec(`LOCK TABLE review_chunks`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM review_chunks WHERE embedding_s4 IS NULL`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectRollback()
+
+	err = client.ActivateEmbeddingSpace(context.Background(), 4)
+	if !errors.Is(err, ErrEmbeddingSpaceIncomplete) {
+		t.Fatalf("ActivateEmbeddingSpace error = %v, want ErrEmbeddingSpaceIncomplete", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresClient_FollowEmbeddingSpaces(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	own := &mockEmbedder{vec: []float32{1, 0}}
+	client := &PostgresClient{db: db, embedder: own}
+	client.FollowEmbeddingSpaces(time.Hour)
+
+	// The seeded reviews space runs on the process's own embedder; the
+	// menu collection has been cut over to a hashing model.
+	mock.ExpectQuery(`FROM embedding_spaces WHERE status = 'active'`).
+		WillReturnRows(sqlmock.NewRows(spaceRowColumns).
+			AddRow(1, CollectionReviews, "nomic-embed-text", 768, []byte(`{}`), SpaceActive, "embedding", time.Now(), nil, nil).
+			AddRow(5, CollectionMenu, "hash", 64, []byte(`{"type":"hash","dim":64}`), SpaceActive, "embedding", time.Now(), time.Now(), nil))
+
+	ctx := context.Background()
+	vec, err := client.CollectionEmbedder(CollectionReviews).EmbedSingle(ctx, "pizza")
+	if err != nil {
+		t.Fatalf("reviews EmbedSingle: %v", err)
+	}
+	if len(vec) != 2 {
+		t.Errorf("reviews vector has %d dims, want the process embedder's 2", len(vec))
+	}
+	// Within the TTL the cached view is used: no second query.
+	vec, err = client.CollectionEmbedder(CollectionMenu).EmbedSingle(ctx, "pizza")
+	if err != nil {
+		t.Fatalf("menu EmbedSingle: %v", err)
+	}
+	if len(vec) != 64 {
+		t.Errorf("menu vector has %d dims, want the active space's 64", len(vec))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresClient_BatchUpsertMenu_ReembedsStale(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	client := &PostgresClient{db: db, embedder: &mockEmbedder{vec: []float32{0.6, 0.8}}}
+
+	// The first item was embedded with space 3 before the menu collection
+	// was cut over to space 5; the second already with space 5.
+	items := []MenuItem{
+		{MenuItemID: "m1", DishName: "Risotto", Vector: []float32{1, 0}, EmbeddingSpace: 3},
+		{MenuItemID: "m2", DishName: "Polenta", Vector: []float32{0, 1}, EmbeddingSpace: 5},
+	}
+	menuArgs := func(id string, vec []float32) []driver.Value {
+		args := make([]driver.Value, 21)
+		for i := range args {
+			args[i] = sqlmock.AnyArg()
+		}
+		args[0], args[16] = id, pgvector.NewHalfVector(vec)
+		return args
+	}
+	mock.ExpectBegin()
+	mock.ExpectExec(`LOCK TABLE menu_items IN ROW EXCLUSIVE MODE`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`FROM embedding_spaces WHERE collection = \$1 AND status = 'active'`).
+		WithArgs(CollectionMenu).
+		WillReturnRows(sqlmock.NewRows(spaceRowColumns).
+			AddRow(5, CollectionMenu, "nomic-embed-text", 2, []byte(`{}`), SpaceActive, "embedding", time.Now(), time.Now(), nil))
+	prep := mock.ExpectPrepare(`INSERT INTO menu_items`)
+	prep.ExpectExec().WithArgs(menuArgs("m1", []float32{0.6, 0.8})...).WillReturnResult(sqlmock.NewResult(0, 1))
+	prep.ExpectExec().WithArgs(menuArgs("m2", []float32{0, 1})...).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := client.BatchUpsertMenu(context.Background(), items); err != nil {
+		t.Fatalf("BatchUpsertMenu: %v", err)
+	}
+	if items[0].Vector[0] != 1 {
+		t.Error("BatchUpsertMenu changed the caller's items")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
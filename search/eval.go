@@ -0,0 +1,468 @@
+package search
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of search an eval query runs.
+const (
+	EvalReviews    = "reviews"
+	EvalBusinesses = "businesses"
+	EvalFodmap     = "fodmap"
+	EvalMenu       = "menu"
+)
+
+// DefaultEvalK is the rank cutoff of nDCG@k and recall@k.
+const DefaultEvalK = 10
+
+// EvalFilter is the JSON form of the SearchFilter fields an eval query may
+// set.
+type EvalFilter struct {
+	Category   string   `json:"category,omitempty"`
+	City       string   `json:"city,omitempty"`
+	State      string   `json:"state,omitempty"`
+	BusinessID string   `json:"business_id,omitempty"`
+	Alpha      *float32 `json:"alpha,omitempty"`
+}
+
+// EvalQuery is one query of an eval set with its relevance judgements.
+// Relevant maps the IDs of relevant results to a grade, higher meaning more
+// relevant; grades of 0 or less count as not relevant. IDs are review IDs,
+// business keys, ingredient names (matched case-insensitively) or menu item
+// IDs, by Kind. A business key is "name|city|state", matched as
+// BusinessResult.Key, since each backend gives a business its own ID.
+type EvalQuery struct {
+	ID       string         `json:"id"`
+	Kind     string         `json:"kind"`
+	Query    string         `json:"query"`
+	Filter   EvalFilter     `json:"filter,omitzero"`
+	Relevant map[string]int `json:"relevant"`
+}
+
+// LoadEvalQueries reads an eval query set, one JSON EvalQuery per line.
+// Blank lines and lines starting with # are skipped. A query without an ID
+// is numbered by its line; every query needs at least one relevant result.
+func LoadEvalQueries(path string) ([]EvalQuery, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var queries []EvalQuery
+	seen := make(map[string]bool)
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		var q EvalQuery
+		if err := json.Unmarshal([]byte(text), &q); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if q.ID == "" {
+			q.ID = fmt.Sprintf("line-%d", line)
+		}
+		if seen[q.ID] {
+			return nil, fmt.Errorf("%s:%d: duplicate query id %q", path, line, q.ID)
+		}
+		seen[q.ID] = true
+		switch q.Kind {
+		case EvalReviews, EvalBusinesses, EvalFodmap, EvalMenu:
+		default:
+			return nil, fmt.Errorf("%s:%d: unknown kind %q (want reviews, businesses, fodmap or menu)", path, line, q.Kind)
+		}
+		if !slices.ContainsFunc(slices.Collect(maps.Values(q.Relevant)), func(g int) bool { return g > 0 }) {
+			return nil, fmt.Errorf("%s:%d: query %q has no relevant results", path, line, q.ID)
+		}
+		if q.Kind == EvalBusinesses {
+			keys := make(map[string]int, len(q.Relevant))
+			for id, g := range q.Relevant {
+				parts := strings.Split(id, "|")
+				if len(parts) != 3 {
+					return nil, fmt.Errorf(`%s:%d: business %q is not a "name|city|state" key`, path, line, id)
+				}
+				keys[BusinessKey(parts[0], parts[1], parts[2])] = g
+			}
+			q.Relevant = keys
+		}
+		if q.Filter.BusinessID != "" {
+			if _, err := uuid.Parse(q.Filter.BusinessID); err != nil {
+				return nil, fmt.Errorf("%s:%d: filter business_id: %w", path, line, err)
+			}
+		}
+		queries = append(queries, q)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return queries, nil
+}
+
+// EvalQueryResult is one query of an eval run. A query whose search failed
+// scores zero.
+type EvalQueryResult struct {
+	ID      string   `json:"id"`
+	Kind    string   `json:"kind"`
+	Query   string   `json:"query"`
+	Results []string `json:"results"` // top-k result IDs, in rank order
+	NDCG    float64  `json:"ndcg"`
+	RR      float64  `json:"rr"`
+	Recall  float64  `json:"recall"`
+	Millis  float64  `json:"ms"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// EvalSummary aggregates the queries of one kind, or of a whole run.
+// Quality metrics are means over the queries; latency percentiles cover the
+// queries that succeeded.
+type EvalSummary struct {
+	Queries int     `json:"queries"`
+	Errors  int     `json:"errors"`
+	NDCG    float64 `json:"ndcg"`
+	MRR     float64 `json:"mrr"`
+	Recall  float64 `json:"recall"`
+	P50     float64 `json:"p50_ms"`
+	P95     float64 `json:"p95_ms"`
+	P99     float64 `json:"p99_ms"`
+}
+
+// EvalReport is the outcome of one eval run, e.g. one backend, embedder or
+// alpha over the query set.
+type EvalReport struct {
+	Label   string                 `json:"label"`
+	K       int                    `json:"k"`
+	Queries []EvalQueryResult      `json:"queries"`
+	Kinds   map[string]EvalSummary `json:"kinds"`
+	Total   EvalSummary            `json:"total"`
+}
+
+// EvalOptions configures RunEval.
+type EvalOptions struct {
+	Label string
+	// K is the rank cutoff, and the number of results requested; 0 means
+	// DefaultEvalK.
+	K int
+	// Alpha, when set, replaces every query's hybrid alpha.
+	Alpha *float32
+	// Pipeline ranks review, business and menu searches as the server's
+	// --rrf/--rerank-* flags do; nil leaves ranking to the backend.
+	Pipeline *RetrievalPipeline
+}
+
+// FodmapSearcher is the FODMAP lookup an eval's fodmap queries run.
+type FodmapSearcher interface {
+	SearchFodmap(ctx context.Context, ingredient string) (FodmapResult, float64, error)
+}
+
+// RunEval runs every query against backend and scores its results. backend
+// is any search client; a query whose kind it cannot serve (e.g. menu
+// queries against Pinecone) fails. Queries run one at a time so latencies
+// are not skewed by each other.
+func RunEval(ctx context.Context, queries []EvalQuery, backend any, opts EvalOptions) (EvalReport, error) {
+	k := opts.K
+	if k <= 0 {
+		k = DefaultEvalK
+	}
+	report := EvalReport{Label: opts.Label, K: k, Kinds: make(map[string]EvalSummary)}
+	for _, q := range queries {
+		filter := q.Filter.searchFilter()
+		if opts.Alpha != nil {
+			filter.Alpha = *opts.Alpha
+		}
+		start := time.Now()
+		ids, err := runEvalQuery(ctx, backend, opts.Pipeline, q, k, filter)
+		res := EvalQueryResult{
+			ID:     q.ID,
+			Kind:   q.Kind,
+			Query:  q.Query,
+			Millis: math.Round(float64(time.Since(start).Microseconds())/10) / 100,
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+			res.Error = err.Error()
+		} else {
+			res.Results = ids[:min(k, len(ids))]
+			res.NDCG, res.RR, res.Recall = scoreRanking(res.Results, q.Relevant, k, q.Kind == EvalFodmap)
+		}
+		report.Queries = append(report.Queries, res)
+	}
+
+	byKind := make(map[string][]EvalQueryResult)
+	for _, r := range report.Queries {
+		byKind[r.Kind] = append(byKind[r.Kind], r)
+	}
+	for kind, rs := range byKind {
+		report.Kinds[kind] = summarizeEval(rs)
+	}
+	report.Total = summarizeEval(report.Queries)
+	return report, nil
+}
+
+func (f EvalFilter) searchFilter() SearchFilter {
+	sf := SearchFilter{Category: f.Category, City: f.City, State: f.State}
+	if f.BusinessID != "" {
+		sf.BusinessID, _ = uuid.Parse(f.BusinessID) // validated by LoadEvalQueries
+	}
+	if f.Alpha != nil {
+		sf.Alpha = *f.Alpha
+	}
+	return sf
+}
+
+// runEvalQuery runs q and returns its result IDs in rank order.
+func runEvalQuery(ctx context.Context, backend any, p *RetrievalPipeline, q EvalQuery, k int, filter SearchFilter) ([]string, error) {
+	switch q.Kind {
+	case EvalReviews:
+		r, ok := backend.(ReviewRetriever)
+		if !ok {
+			return nil, fmt.Errorf("backend does not search reviews")
+		}
+		res, err := p.Reviews(ctx, r, q.Query, k, filter)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, len(res.BusinessReviews))
+		for i, rr := range res.BusinessReviews {
+			ids[i] = rr.Review.Review.ReviewID
+		}
+		return ids, nil
+	case EvalBusinesses:
+		r, ok := backend.(BusinessRetriever)
+		if !ok {
+			return nil, fmt.Errorf("backend does not search businesses")
+		}
+		res, err := p.Businesses(ctx, r, q.Query, k, filter)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, len(res.Businesses))
+		for i, b := range res.Businesses {
+			ids[i] = b.Key()
+		}
+		return ids, nil
+	case EvalFodmap:
+		r, ok := backend.(FodmapSearcher)
+		if !ok {
+			return nil, fmt.Errorf("backend does not look up FODMAP ingredients")
+		}
+		res, _, err := r.SearchFodmap(ctx, q.Query)
+		if err != nil {
+			return nil, err
+		}
+		return []string{res.Ingredient}, nil
+	case EvalMenu:
+		r, ok := backend.(MenuRetriever)
+		if !ok {
+			return nil, fmt.Errorf("backend does not search menus")
+		}
+		items, err := p.SearchMenu(ctx, r, q.Query, k, filter)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, len(items))
+		for i, it := range items {
+			ids[i] = it.MenuItemID
+		}
+		return ids, nil
+	}
+	return nil, fmt.Errorf("unknown kind %q", q.Kind)
+}
+
+// scoreRanking scores the top-k results against graded judgements:
+// nDCG@k with exponential gain, the reciprocal rank of the first relevant
+// result, and the share of relevant results retrieved. Each judged ID is
+// credited once. foldCase matches IDs case-insensitively.
+func scoreRanking(results []string, relevant map[string]int, k int, foldCase bool) (ndcg, rr, recall float64) {
+	grades := make(map[string]int, len(relevant))
+	var ideal []int
+	for id, g := range relevant {
+		if foldCase {
+			id = strings.ToLower(id)
+		}
+		grades[id] = g
+		if g > 0 {
+			ideal = append(ideal, g)
+		}
+	}
+	if len(ideal) == 0 {
+		return 0, 0, 0
+	}
+
+	var dcg float64
+	found := 0
+	credited := make(map[string]bool)
+	for i, id := range results[:min(k, len(results))] {
+		if foldCase {
+			id = strings.ToLower(id)
+		}
+		g := grades[id]
+		if g <= 0 || credited[id] {
+			continue
+		}
+		credited[id] = true
+		found++
+		dcg += gain(g) / math.Log2(float64(i+2))
+		if rr == 0 {
+			rr = 1 / float64(i+1)
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(ideal)))
+	var idcg float64
+	for i, g := range ideal[:min(k, len(ideal))] {
+		idcg += gain(g) / math.Log2(float64(i+2))
+	}
+	return dcg / idcg, rr, float64(found) / float64(len(ideal))
+}
+
+func gain(grade int) float64 { return math.Exp2(float64(grade)) - 1 }
+
+func summarizeEval(rs []EvalQueryResult) EvalSummary {
+	s := EvalSummary{Queries: len(rs)}
+	var millis []float64
+	for _, r := range rs {
+		s.NDCG += r.NDCG
+		s.MRR += r.RR
+		s.Recall += r.Recall
+		if r.Error != "" {
+			s.Errors++
+			continue
+		}
+		millis = append(millis, r.Millis)
+	}
+	if n := float64(len(rs)); n > 0 {
+		s.NDCG /= n
+		s.MRR /= n
+		s.Recall /= n
+	}
+	slices.Sort(millis)
+	s.P50, s.P95, s.P99 = percentile(millis, 50), percentile(millis, 95), percentile(millis, 99)
+	return s
+}
+
+// percentile is the nearest-rank p-th percentile of sorted; 0 when empty.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return sorted[max(i, 0)]
+}
+
+// WriteEvalMarkdown writes reports as Markdown tables: metrics per kind and
+// per query, one row per report, so runs of different configurations over
+// the same query set read side by side. Rows after the first report's show
+// the change in each metric against it, so every report must have the same
+// K.
+func WriteEvalMarkdown(w io.Writer, reports ...EvalReport) error {
+	if len(reports) == 0 {
+		return nil
+	}
+	for _, r := range reports[1:] {
+		if r.K != reports[0].K {
+			return fmt.Errorf("report %q was scored at k=%d and %q at k=%d; compare runs at the same --k",
+				r.Label, r.K, reports[0].Label, reports[0].K)
+		}
+	}
+	var b strings.Builder
+	k := reports[0].K
+	b.WriteString("# Search eval\n\n## By kind\n\n")
+	fmt.Fprintf(&b, "| Kind | Run | Queries | Errors | nDCG@%d | MRR | Recall@%d | p50 ms | p95 ms | p99 ms |\n", k, k)
+	b.WriteString("|---|---|---:|---:|---:|---:|---:|---:|---:|---:|\n")
+	kindSet := map[string]bool{}
+	for _, r := range reports {
+		for kind := range r.Kinds {
+			kindSet[kind] = true
+		}
+	}
+	kinds := make([]string, 0, len(kindSet))
+	for kind := range kindSet {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	row := func(kind, label string, s EvalSummary, base *EvalSummary) {
+		var d EvalSummary
+		if base != nil {
+			d = *base
+		}
+		fmt.Fprintf(&b, "| %s | %s | %d | %d | %s | %s | %s | %.1f | %.1f | %.1f |\n", kind, label, s.Queries, s.Errors,
+			evalMetric(s.NDCG, d.NDCG, base != nil), evalMetric(s.MRR, d.MRR, base != nil), evalMetric(s.Recall, d.Recall, base != nil),
+			s.P50, s.P95, s.P99)
+	}
+	for _, kind := range kinds {
+		var base *EvalSummary
+		for i, r := range reports {
+			s, ok := r.Kinds[kind]
+			if !ok {
+				continue
+			}
+			if i == 0 {
+				row(kind, r.Label, s, nil)
+				base = &s
+				continue
+			}
+			row(kind, r.Label, s, base)
+		}
+	}
+	for i, r := range reports {
+		if i == 0 {
+			row("**all**", r.Label, r.Total, nil)
+			continue
+		}
+		row("**all**", r.Label, r.Total, &reports[0].Total)
+	}
+
+	b.WriteString("\n## By query\n\n")
+	fmt.Fprintf(&b, "| Query | Run | Kind | nDCG@%d | RR | Recall@%d | ms | Error |\n", k, k)
+	b.WriteString("|---|---|---|---:|---:|---:|---:|---|\n")
+	type runQuery struct {
+		label string
+		res   EvalQueryResult
+	}
+	var ids []string
+	byID := map[string][]runQuery{}
+	for _, r := range reports {
+		for _, q := range r.Queries {
+			if _, ok := byID[q.ID]; !ok {
+				ids = append(ids, q.ID)
+			}
+			byID[q.ID] = append(byID[q.ID], runQuery{r.Label, q})
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		for _, e := range byID[id] {
+			q := e.res
+			fmt.Fprintf(&b, "| %s | %s | %s | %.3f | %.3f | %.3f | %.1f | %s |\n", id, e.label, q.Kind,
+				q.NDCG, q.RR, q.Recall, q.Millis, strings.ReplaceAll(q.Error, "|", `\|`))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// evalMetric formats a metric, with its change from base when compared.
+func evalMetric(v, base float64, compared bool) string {
+	if !compared {
+		return fmt.Sprintf("%.3f", v)
+	}
+	return fmt.Sprintf("%.3f (%+.3f)", v, v-base)
+}
@@ -0,0 +1,194 @@
+package search
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"fodmap/data"
+)
+
+func TestScoreRanking(t *testing.T) {
+	relevant := map[string]int{"a": 2, "b": 1, "c": 0}
+	tests := []struct {
+		name                     string
+		results                  []string
+		k                        int
+		wantNDCG, wantRR, wantRc float64
+	}{
+		{"ideal", []string{"a", "b", "x"}, 10, 1, 1, 1},
+		{"swapped", []string{"b", "a"}, 10, (1 + 3/math.Log2(3)) / (3 + 1/math.Log2(3)), 1, 1},
+		{"late", []string{"x", "c", "b"}, 10, (1 / math.Log2(4)) / (3 + 1/math.Log2(3)), 1.0 / 3, 0.5},
+		{"cut off", []string{"x", "a"}, 1, 0, 0, 0},
+		{"duplicate credited once", []string{"a", "a"}, 10, 3 / (3 + 1/math.Log2(3)), 1, 0.5},
+	}
+	for _, tt := range tests {
+		ndcg, rr, recall := scoreRanking(tt.results, relevant, tt.k, false)
+		if math.Abs(ndcg-tt.wantNDCG) > 1e-9 || math.Abs(rr-tt.wantRR) > 1e-9 || math.Abs(recall-tt.wantRc) > 1e-9 {
+			t.Errorf("%s: got ndcg=%v rr=%v recall=%v, want %v %v %v", tt.name, ndcg, rr, recall, tt.wantNDCG, tt.wantRR, tt.wantRc)
+		}
+	}
+}
+
+func TestLoadEvalQueries(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	qs, err := LoadEvalQueries(write("ok.jsonl", `# comment
+{"id": "q1", "kind": "reviews", "query": "pasta", "filter": {"city": "Boston", "alpha": 0.5}, "relevant": {"r1": 2}}
+
+{"kind": "fodmap", "query": "garlic", "relevant": {"garlic": 1}}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qs) != 2 || qs[1].ID != "line-4" {
+		t.Fatalf("queries = %+v", qs)
+	}
+	if f := qs[0].Filter.searchFilter(); f.City != "Boston" || f.Alpha != 0.5 {
+		t.Errorf("filter = %+v", f)
+	}
+
+	for name, body := range map[string]string{
+		"kind.jsonl":     `{"kind": "dishes", "query": "x", "relevant": {"a": 1}}`,
+		"relevant.jsonl": `{"kind": "menu", "query": "x", "relevant": {"a": 0}}`,
+		"dup.jsonl":      "{\"id\": \"a\", \"kind\": \"menu\", \"query\": \"x\", \"relevant\": {\"a\": 1}}\n{\"id\": \"a\", \"kind\": \"menu\", \"query\": \"y\", \"relevant\": {\"a\": 1}}",
+		"business.jsonl": `{"kind": "reviews", "query": "x", "filter": {"business_id": "b1"}, "relevant": {"a": 1}}`,
+		"key.jsonl":      `{"kind": "businesses", "query": "x", "relevant": {"3fa85f64-5717-4562-b3fc-2c963f66afa6": 1}}`,
+	} {
+		if _, err := LoadEvalQueries(write(name, body)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestRunEval_BusinessKeys(t *testing.T) {
+	c := newTestLocalClient(t, t.TempDir())
+	seedLocalReviews(t, c)
+	p := filepath.Join(t.TempDir(), "q.jsonl")
+	// The judgement names the business, in any case and spacing, not by an
+	// ID only one backend would return.
+	if err := os.WriteFile(p, []byte(`{"id": "pasta", "kind": "businesses", "query": "pasta", "relevant": {"BIZ  b2|cambridge|ma": 1}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	queries, err := LoadEvalQueries(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := RunEval(context.Background(), queries, c, EvalOptions{K: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := report.Queries[0]; q.Error != "" || q.Recall != 1 || !slices.Contains(q.Results, "biz b2|cambridge|ma") {
+		t.Errorf("pasta = %+v, want Biz b2 found by its key", q)
+	}
+}
+
+// fodmapOnlyBackend serves FODMAP lookups and nothing else.
+type fodmapOnlyBackend struct{}
+
+func (fodmapOnlyBackend) SearchFodmap(_ context.Context, ingredient string) (FodmapResult, float64, error) {
+	return FodmapResult{Ingredient: ingredient}, 1, nil
+}
+
+func TestRunEval_LocalBackend(t *testing.T) {
+	c := newTestLocalClient(t, t.TempDir())
+	seedLocalReviews(t, c)
+	ctx := context.Background()
+	if err := c.BatchUpsertFodmap(ctx, map[string]data.FodmapEntry{
+		"garlic": {Level: "high"},
+		"rice":   {Level: "low"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	queries := []EvalQuery{
+		{ID: "pasta", Kind: EvalReviews, Query: "pasta", Relevant: map[string]int{"r1": 1, "r3": 1}},
+		{ID: "garlic", Kind: EvalFodmap, Query: "garlic", Relevant: map[string]int{"Garlic": 1}},
+		{ID: "rice", Kind: EvalFodmap, Query: "rice", Relevant: map[string]int{"garlic": 1}},
+	}
+	report, err := RunEval(ctx, queries, c, EvalOptions{Label: "local", K: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := map[string]EvalQueryResult{}
+	for _, q := range report.Queries {
+		byID[q.ID] = q
+	}
+	if q := byID["pasta"]; q.Error != "" || q.NDCG != 1 || q.RR != 1 || q.Recall != 1 || len(q.Results) != 2 {
+		t.Errorf("pasta = %+v, want both pasta reviews in the top 2", q)
+	}
+	if q := byID["garlic"]; q.NDCG != 1 || q.Results[0] != "garlic" {
+		t.Errorf("garlic = %+v, want a case-insensitive hit", q)
+	}
+	if q := byID["rice"]; q.NDCG != 0 || q.RR != 0 {
+		t.Errorf("rice = %+v, want a miss", q)
+	}
+	fodmap := report.Kinds[EvalFodmap]
+	if fodmap.Queries != 2 || fodmap.MRR != 0.5 {
+		t.Errorf("fodmap summary = %+v, want 2 queries with MRR 0.5", fodmap)
+	}
+	if report.Total.Queries != 3 || report.Total.Errors != 0 || report.Total.P99 < report.Total.P50 {
+		t.Errorf("total = %+v", report.Total)
+	}
+
+	// A kind the backend cannot serve fails that query only.
+	report, err = RunEval(ctx, []EvalQuery{
+		{ID: "menu", Kind: EvalMenu, Query: "risotto", Relevant: map[string]int{"m1": 1}},
+		{ID: "garlic", Kind: EvalFodmap, Query: "garlic", Relevant: map[string]int{"garlic": 1}},
+	}, fodmapOnlyBackend{}, EvalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.K != DefaultEvalK || report.Total.Errors != 1 || report.Kinds[EvalFodmap].NDCG != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	if !strings.Contains(report.Queries[0].Error, "does not search menus") {
+		t.Errorf("menu error = %q", report.Queries[0].Error)
+	}
+}
+
+func TestWriteEvalMarkdown(t *testing.T) {
+	base := EvalReport{Label: "weaviate", K: 5,
+		Queries: []EvalQueryResult{{ID: "q1", Kind: EvalReviews, NDCG: 0.5, RR: 0.5, Recall: 1, Millis: 12}},
+		Kinds:   map[string]EvalSummary{EvalReviews: {Queries: 1, NDCG: 0.5, MRR: 0.5, Recall: 1, P50: 12, P95: 12, P99: 12}},
+		Total:   EvalSummary{Queries: 1, NDCG: 0.5, MRR: 0.5, Recall: 1, P50: 12, P95: 12, P99: 12},
+	}
+	run := base
+	run.Label = "postgres"
+	run.Kinds = map[string]EvalSummary{EvalReviews: {Queries: 1, NDCG: 0.75, MRR: 1, Recall: 1}}
+	run.Total = run.Kinds[EvalReviews]
+
+	var b strings.Builder
+	if err := WriteEvalMarkdown(&b, base, run); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	// Metrics at different cutoffs are not comparable.
+	other := run
+	other.K = 10
+	if err := WriteEvalMarkdown(&strings.Builder{}, base, other); err == nil {
+		t.Error("WriteEvalMarkdown compared reports of different k")
+	}
+	for _, want := range []string{
+		"nDCG@5",
+		"| reviews | weaviate | 1 | 0 | 0.500 | 0.500 | 1.000 |",
+		"| reviews | postgres | 1 | 0 | 0.750 (+0.250) | 1.000 (+0.500) | 1.000 (+0.000) |",
+		"| q1 | postgres | reviews |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q:\n%s", want, out)
+		}
+	}
+}
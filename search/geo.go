@@ -0,0 +1,115 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"fodmap/geo"
+)
+
+// MaxRadiusMeters caps radius searches. "Near me" queries are walking or
+// short-drive distances; anything wider is better served by the city and
+// state filters.
+const MaxRadiusMeters = 50_000
+
+// ErrGeoFilterUnsupported is returned by backends that cannot apply a
+// GeoFilter to the requested collection, e.g. Weaviate review chunks and
+// Pinecone, which store no coordinates.
+var ErrGeoFilterUnsupported = errors.New("geo filter is not supported by this search backend")
+
+// GeoFilter narrows results to restaurants within RadiusMeters of Origin,
+// inside BBox, or both. Origin is also the reference point for
+// SortByDistance and for the distances reported on results, so a bbox-only
+// filter may still carry one.
+type GeoFilter struct {
+	Origin         *geo.Point
+	RadiusMeters   float64   // 0 = no radius limit; requires Origin
+	BBox           *geo.BBox // nil = no bounding box
+	SortByDistance bool      // nearest first instead of by relevance; requires Origin
+}
+
+// Validate checks that f constrains something and that its parts are
+// well-formed.
+func (f *GeoFilter) Validate() error {
+	if f.Origin == nil && f.BBox == nil {
+		return errors.New("geo filter needs an origin or a bbox")
+	}
+	if f.Origin != nil {
+		if err := f.Origin.Validate(); err != nil {
+			return err
+		}
+	}
+	if f.RadiusMeters < 0 || f.RadiusMeters > MaxRadiusMeters {
+		return fmt.Errorf("radius must be between 0 and %d metres", MaxRadiusMeters)
+	}
+	if f.RadiusMeters > 0 && f.Origin == nil {
+		return errors.New("radius needs an origin")
+	}
+	if f.SortByDistance && f.Origin == nil {
+		return errors.New("sorting by distance needs an origin")
+	}
+	if f.BBox != nil {
+		if err := f.BBox.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Bounds returns a box enclosing every point f can match: the radius box,
+// BBox, or their intersection.
+func (f *GeoFilter) Bounds() geo.BBox {
+	b := geo.BBox{-180, -90, 180, 90}
+	if f.Origin != nil && f.RadiusMeters > 0 {
+		b = f.Origin.BoundsWithin(f.RadiusMeters)
+	}
+	if f.BBox != nil {
+		b = geo.BBox{
+			max(b.MinLon(), f.BBox.MinLon()), max(b.MinLat(), f.BBox.MinLat()),
+			min(b.MaxLon(), f.BBox.MaxLon()), min(b.MaxLat(), f.BBox.MaxLat()),
+		}
+	}
+	return b
+}
+
+// Match reports whether (lat, lon) passes f and returns its distance from
+// Origin (nil without an origin). Missing coordinates never match.
+func (f *GeoFilter) Match(lat, lon *float64) (*float64, bool) {
+	if lat == nil || lon == nil {
+		return nil, false
+	}
+	if f.BBox != nil && !f.BBox.Contains(*lat, *lon) {
+		return nil, false
+	}
+	if f.Origin == nil {
+		return nil, true
+	}
+	d := f.Origin.DistanceTo(*lat, *lon)
+	if f.RadiusMeters > 0 && d > f.RadiusMeters {
+		return nil, false
+	}
+	return &d, true
+}
+
+// SortMenuByDistance orders items nearest first; items without a distance
+// go last. The sort is stable so ties keep their relevance order.
+func SortMenuByDistance(items []MenuItem) {
+	slices.SortStableFunc(items, func(a, b MenuItem) int {
+		switch {
+		case a.DistanceMeters == nil && b.DistanceMeters == nil:
+			return 0
+		case a.DistanceMeters == nil:
+			return 1
+		case b.DistanceMeters == nil:
+			return -1
+		}
+		switch {
+		case *a.DistanceMeters < *b.DistanceMeters:
+			return -1
+		case *a.DistanceMeters > *b.DistanceMeters:
+			return 1
+		}
+		return 0
+	})
+}
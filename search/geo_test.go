@@ -0,0 +1,126 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"fodmap/geo"
+)
+
+func fp(f float64) *float64 { return &f }
+
+func TestGeoFilter_Validate(t *testing.T) {
+	origin := &geo.Point{Lat: 40.76, Lon: -73.92}
+	bbox := &geo.BBox{-74, 40.7, -73.9, 40.8}
+	tests := []struct {
+		name    string
+		f       GeoFilter
+		wantErr bool
+	}{
+		{"radius", GeoFilter{Origin: origin, RadiusMeters: 800}, false},
+		{"bbox only", GeoFilter{BBox: bbox}, false},
+		{"origin only", GeoFilter{Origin: origin, SortByDistance: true}, false},
+		{"empty", GeoFilter{}, true},
+		{"radius without origin", GeoFilter{BBox: bbox, RadiusMeters: 800}, true},
+		{"sort without origin", GeoFilter{BBox: bbox, SortByDistance: true}, true},
+		{"radius too large", GeoFilter{Origin: origin, RadiusMeters: MaxRadiusMeters + 1}, true},
+		{"origin out of range", GeoFilter{Origin: &geo.Point{Lat: 91}}, true},
+		{"inverted bbox", GeoFilter{BBox: &geo.BBox{-73.9, 40.7, -74, 40.8}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.f.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGeoFilter_Match(t *testing.T) {
+	f := GeoFilter{Origin: &geo.Point{Lat: 40.76, Lon: -73.92}, RadiusMeters: 800}
+	if d, ok := f.Match(fp(40.765), fp(-73.92)); !ok || d == nil || *d < 550 || *d > 560 {
+		t.Errorf("~556m away: got %v, %v", d, ok)
+	}
+	if _, ok := f.Match(fp(40.77), fp(-73.92)); ok {
+		t.Error("~1.1km away should not match an 800m radius")
+	}
+	if _, ok := f.Match(nil, fp(-73.92)); ok {
+		t.Error("missing coordinates should not match")
+	}
+
+	box := GeoFilter{BBox: &geo.BBox{-74, 40.7, -73.9, 40.8}}
+	if d, ok := box.Match(fp(40.75), fp(-73.95)); !ok || d != nil {
+		t.Errorf("inside bbox without origin: got %v, %v", d, ok)
+	}
+	if _, ok := box.Match(fp(40.85), fp(-73.95)); ok {
+		t.Error("outside bbox should not match")
+	}
+}
+
+func TestGeoFilter_Bounds(t *testing.T) {
+	f := GeoFilter{Origin: &geo.Point{Lat: 40.76, Lon: -73.92}, RadiusMeters: 1000, BBox: &geo.BBox{-73.93, 40.0, -73.0, 41.0}}
+	b := f.Bounds()
+	if b.MinLon() != -73.93 {
+		t.Errorf("MinLon = %v, want the bbox edge -73.93", b.MinLon())
+	}
+	if b.MaxLat() < 40.768 || b.MaxLat() > 40.77 {
+		t.Errorf("MaxLat = %v, want about 40.769 from the radius", b.MaxLat())
+	}
+	for _, corner := range [][2]float64{{b.MinLat(), -73.92}, {b.MaxLat(), -73.92}} {
+		if d := geo.Distance(40.76, -73.92, corner[0], corner[1]); d < 999 || d > 1001 {
+			t.Errorf("radius edge at %v is %vm from the origin, want 1000m", corner, d)
+		}
+	}
+}
+
+func TestSortMenuByDistance(t *testing.T) {
+	items := []MenuItem{
+		{DishName: "none"},
+		{DishName: "far", DistanceMeters: fp(700)},
+		{DishName: "near", DistanceMeters: fp(100)},
+		{DishName: "near2", DistanceMeters: fp(100)},
+	}
+	SortMenuByDistance(items)
+	var got []string
+	for _, it := range items {
+		got = append(got, it.DishName)
+	}
+	want := []string{"near", "near2", "far", "none"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestBuildMenuWhereFilter_Geo(t *testing.T) {
+	w := buildMenuWhereFilter(SearchFilter{
+		City: "Queens",
+		Geo:  &GeoFilter{Origin: &geo.Point{Lat: 40.76, Lon: -73.92}, RadiusMeters: 800},
+	})
+	if w == nil {
+		t.Fatal("expected a where filter")
+	}
+	got := w.String()
+	for _, want := range []string{"operator: And", `path: ["location"]`, "WithinGeoRange", "distance:{max:800}", `path: ["city"]`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("where filter %s missing %q", got, want)
+		}
+	}
+	if buildMenuWhereFilter(SearchFilter{}) != nil {
+		t.Error("empty filter should build no where clause")
+	}
+}
+
+func TestGeoRange_BBoxEnclosingCircle(t *testing.T) {
+	f := &GeoFilter{BBox: &geo.BBox{-74, 40.7, -73.9, 40.8}}
+	lat, lon, radius := geoRange(f)
+	if lat != 40.75 || lon != -73.95 {
+		t.Errorf("centre = (%v, %v), want (40.75, -73.95)", lat, lon)
+	}
+	for _, c := range [][2]float64{{40.7, -74}, {40.8, -73.9}, {40.7, -73.9}, {40.8, -74}} {
+		if d := geo.Distance(lat, lon, c[0], c[1]); d > radius {
+			t.Errorf("corner %v is %vm from the centre, outside radius %v", c, d, radius)
+		}
+	}
+}
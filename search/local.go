@@ -0,0 +1,795 @@
+package search
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"fodmap/data"
+
+	"github.com/google/uuid"
+)
+
+// LocalClient is an embedded search backend for local development and tests:
+// it implements Searcher, FodmapWriter, MenuStore and MenuItemDeleter in
+// process with no service behind it. Vectors are ranked by a flat
+// (brute-force) cosine scan, keywords through an inverted index scored with
+// BM25, and both retrieval modes and hybrid blending work as on Postgres.
+//
+// Every write is appended to a JSON-lines log per collection in the
+// directory the client was opened on (reviews.jsonl, fodmap.jsonl,
+// menu.jsonl); opening replays the logs and compacts them once most of their
+// records are superseded. Only one client may have a directory open at a
+// time — opening takes a lock on its LOCK file and fails while another
+// process holds it — and every collection is held in memory, so it suits a
+// city's worth of reviews rather than the whole Yelp dataset.
+type LocalClient struct {
+	dir      string
+	embedder Embedder
+
+	mu sync.RWMutex
+
+	reviews     map[string]*localReview
+	postings    map[string]map[chunkRef]struct{} // term → chunks containing it
+	reviewStats CorpusStats                      // one document per chunk
+
+	fodmap map[string]localFodmap
+
+	menu      map[string]*localMenuItem
+	menuStats CorpusStats // one document per menu item
+
+	reviewLog, fodmapLog, menuLog *localLog
+	lock                          *os.File // held for the client's lifetime
+}
+
+// localReview is an indexed review: its metadata without vectors, and its
+// chunks.
+type localReview struct {
+	item   IndexItem
+	chunks []localChunk
+}
+
+// localChunk is a chunk's text and unit-length vector (nil when the chunk
+// was indexed without one).
+type localChunk struct {
+	text string
+	vec  []float32
+}
+
+// chunkRef addresses one chunk of one review.
+type chunkRef struct {
+	reviewID string
+	chunk    int
+}
+
+type localFodmap struct {
+	entry data.FodmapEntry
+	vec   []float32
+}
+
+type localMenuItem struct {
+	item MenuItem // Vector and DistanceMeters cleared
+	vec  []float32
+}
+
+// Log records. A review record replaces all chunks of its review; fodmap
+// and menu records replace or, with Deleted, remove a single entry.
+type (
+	localReviewRecord struct {
+		Item   IndexItem          `json:"item"`
+		Chunks []localChunkRecord `json:"chunks"`
+	}
+	localChunkRecord struct {
+		Text   string      `json:"text"`
+		Vector localVector `json:"vec,omitempty"`
+	}
+	localFodmapRecord struct {
+		Name    string           `json:"name"`
+		Entry   data.FodmapEntry `json:"entry"`
+		Vector  localVector      `json:"vec,omitempty"`
+		Deleted bool             `json:"deleted,omitempty"`
+	}
+	localMenuRecord struct {
+		Item    MenuItem    `json:"item"`
+		Vector  localVector `json:"vec,omitempty"`
+		Deleted bool        `json:"deleted,omitempty"`
+	}
+)
+
+// NewLocalClient opens (creating if needed) the local index in dir. e embeds
+// queries and FODMAP ingredient names; review chunks and menu items arrive
+// with their vectors, as for the other backends.
+func NewLocalClient(dir string, e Embedder) (*LocalClient, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create local index dir: %w", err)
+	}
+	lock, err := lockLocalDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	c := &LocalClient{
+		dir:      dir,
+		embedder: e,
+		reviews:  make(map[string]*localReview),
+		postings: make(map[string]map[chunkRef]struct{}),
+		fodmap:   make(map[string]localFodmap),
+		menu:     make(map[string]*localMenuItem),
+		lock:     lock,
+	}
+
+	if c.reviewLog, err = openLocalLog(filepath.Join(dir, "reviews.jsonl"), c.applyReview); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	if c.fodmapLog, err = openLocalLog(filepath.Join(dir, "fodmap.jsonl"), c.applyFodmap); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	if c.menuLog, err = openLocalLog(filepath.Join(dir, "menu.jsonl"), c.applyMenu); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	if err := c.compact(); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Close closes the collection logs and releases the directory lock. Writes
+// are appended as they happen, so nothing is lost by not calling it.
+func (c *LocalClient) Close() error {
+	var errs []error
+	for _, l := range []*localLog{c.reviewLog, c.fodmapLog, c.menuLog} {
+		if l != nil {
+			errs = append(errs, l.close())
+		}
+	}
+	if c.lock != nil {
+		errs = append(errs, c.lock.Close())
+		c.lock = nil
+	}
+	return errors.Join(errs...)
+}
+
+// compact rewrites the logs that are mostly superseded records.
+func (c *LocalClient) compact() error {
+	if c.reviewLog.needsCompaction(len(c.reviews)) {
+		recs := make([]any, 0, len(c.reviews))
+		for _, id := range sortedKeys(c.reviews) {
+			r := c.reviews[id]
+			rec := localReviewRecord{Item: r.item}
+			for _, ch := range r.chunks {
+				rec.Chunks = append(rec.Chunks, localChunkRecord{Text: ch.text, Vector: ch.vec})
+			}
+			recs = append(recs, rec)
+		}
+		if err := c.reviewLog.rewrite(recs); err != nil {
+			return err
+		}
+	}
+	if c.fodmapLog.needsCompaction(len(c.fodmap)) {
+		recs := make([]any, 0, len(c.fodmap))
+		for _, name := range sortedKeys(c.fodmap) {
+			recs = append(recs, localFodmapRecord{Name: name, Entry: c.fodmap[name].entry, Vector: c.fodmap[name].vec})
+		}
+		if err := c.fodmapLog.rewrite(recs); err != nil {
+			return err
+		}
+	}
+	if c.menuLog.needsCompaction(len(c.menu)) {
+		recs := make([]any, 0, len(c.menu))
+		for _, id := range sortedKeys(c.menu) {
+			recs = append(recs, localMenuRecord{Item: c.menu[id].item, Vector: c.menu[id].vec})
+		}
+		if err := c.menuLog.rewrite(recs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnsureSchema is a no-op; the collections need no schema.
+func (c *LocalClient) EnsureSchema(_ context.Context) error { return nil }
+
+// EnsureFodmapSchema is a no-op; the collections need no schema.
+func (c *LocalClient) EnsureFodmapSchema(_ context.Context) error { return nil }
+
+// EnsureMenuSchema is a no-op; the collections need no schema.
+func (c *LocalClient) EnsureMenuSchema(_ context.Context) error { return nil }
+
+// BatchUpsert indexes reviews, replacing every chunk of a review that was
+// indexed before. An item without Chunks is indexed as a single chunk of its
+// full text with the legacy Vector.
+func (c *LocalClient) BatchUpsert(_ context.Context, items []IndexItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	recs := make([]localReviewRecord, len(items))
+	for i, it := range items {
+		rec := localReviewRecord{Item: it}
+		rec.Item.Vector, rec.Item.Chunks = nil, nil
+		if len(it.Chunks) == 0 {
+			rec.Chunks = []localChunkRecord{{Text: it.Review.Text, Vector: it.Vector}}
+		}
+		for _, ch := range it.Chunks {
+			rec.Chunks = append(rec.Chunks, localChunkRecord{Text: ch.Text, Vector: ch.Vector})
+		}
+		recs[i] = rec
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.reviewLog.append(toAny(recs)...); err != nil {
+		return err
+	}
+	for _, rec := range recs {
+		c.applyReview(rec)
+	}
+	return nil
+}
+
+// applyReview replaces a review's chunks in memory, keeping the postings and
+// BM25 statistics in step.
+func (c *LocalClient) applyReview(rec localReviewRecord) {
+	id := rec.Item.Review.ReviewID
+	if old, ok := c.reviews[id]; ok {
+		for i, ch := range old.chunks {
+			c.reviewStats.Remove(ch.text)
+			for _, t := range uniqueTerms(tokenize(ch.text)) {
+				delete(c.postings[t], chunkRef{id, i})
+				if len(c.postings[t]) == 0 {
+					delete(c.postings, t)
+				}
+			}
+		}
+	}
+	r := &localReview{item: rec.Item}
+	for i, ch := range rec.Chunks {
+		r.chunks = append(r.chunks, localChunk{text: ch.Text, vec: unitVector(ch.Vector)})
+		c.reviewStats.Add(ch.Text)
+		for _, t := range uniqueTerms(tokenize(ch.Text)) {
+			if c.postings[t] == nil {
+				c.postings[t] = make(map[chunkRef]struct{})
+			}
+			c.postings[t][chunkRef{id, i}] = struct{}{}
+		}
+	}
+	c.reviews[id] = r
+}
+
+// reviewHit is a review scored by its best chunk.
+type reviewHit struct {
+	review *localReview
+	chunk  int
+	score  float64
+}
+
+// rankReviews scores every review matching filter by its best chunk and
+// returns them best first. Without a query every matching review scores 1.
+// A lexical Retrieval considers only chunks sharing a query term, scored by
+// BM25; otherwise chunks are scored by cosine similarity, blended with BM25
+// when Alpha asks for hybrid.
+func (c *LocalClient) rankReviews(ctx context.Context, query string, filter SearchFilter) ([]reviewHit, error) {
+	if filter.Geo != nil {
+		return nil, ErrGeoFilterUnsupported
+	}
+	lexical := query != "" && filter.Retrieval == RetrieveLexical
+	hybrid := query != "" && filter.Retrieval == RetrieveDefault && hybridActive(filter.Alpha)
+	var qvec []float32
+	if query != "" && !lexical {
+		var err error
+		if qvec, err = c.embedQuery(ctx, query); err != nil {
+			return nil, err
+		}
+	}
+	terms := uniqueTerms(tokenize(query))
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var matched map[chunkRef]struct{}
+	if lexical || hybrid {
+		matched = make(map[chunkRef]struct{})
+		for _, t := range terms {
+			for ref := range c.postings[t] {
+				matched[ref] = struct{}{}
+			}
+		}
+	}
+
+	var hits []reviewHit
+	score := func(r *localReview) {
+		if !matchLocalReview(r.item, filter) {
+			return
+		}
+		best := reviewHit{review: r, chunk: -1}
+		for i, ch := range r.chunks {
+			_, hasTerm := matched[chunkRef{r.item.Review.ReviewID, i}]
+			var s float64
+			switch {
+			case query == "":
+				s = 1
+			case lexical:
+				if !hasTerm {
+					continue
+				}
+				s = bm25Score(query, ch.text, &c.reviewStats)
+			default:
+				if ch.vec == nil {
+					continue
+				}
+				s = dot(qvec, ch.vec)
+				if hybrid {
+					if hasTerm {
+						s = blendScore(query, ch.text, s, filter.Alpha, &c.reviewStats)
+					} else {
+						s *= float64(filter.Alpha) // no keyword overlap: BM25 is 0
+					}
+				}
+			}
+			if best.chunk < 0 || s > best.score {
+				best.chunk, best.score = i, s
+			}
+		}
+		if best.chunk >= 0 {
+			hits = append(hits, best)
+		}
+	}
+	if lexical {
+		seen := make(map[string]bool)
+		for ref := range matched {
+			if !seen[ref.reviewID] {
+				seen[ref.reviewID] = true
+				score(c.reviews[ref.reviewID])
+			}
+		}
+	} else {
+		for _, r := range c.reviews {
+			score(r)
+		}
+	}
+
+	slices.SortFunc(hits, func(a, b reviewHit) int {
+		if a.score != b.score {
+			return cmp.Compare(b.score, a.score)
+		}
+		return strings.Compare(a.review.item.Review.ReviewID, b.review.item.Review.ReviewID)
+	})
+	return hits, nil
+}
+
+// matchLocalReview applies the review filters the way Postgres does:
+// category and city are case-insensitive substrings, state is
+// case-insensitive, and the business ID matches the restaurants UUID or the
+// raw Yelp ID string.
+func matchLocalReview(it IndexItem, f SearchFilter) bool {
+	if f.Category != "" && !containsFold(it.Categories, f.Category) {
+		return false
+	}
+	if f.City != "" && !containsFold(it.City, f.City) {
+		return false
+	}
+	if f.State != "" && !strings.EqualFold(it.State, f.State) {
+		return false
+	}
+	if f.BusinessID != uuid.Nil && localBusinessID(it) != f.BusinessID {
+		return false
+	}
+	if len(f.ReviewIDs) > 0 && !slices.Contains(f.ReviewIDs, it.Review.ReviewID) {
+		return false
+	}
+	return true
+}
+
+// localBusinessID is the business a review belongs to: its restaurants UUID
+// when the indexer resolved one, else its business ID parsed as a UUID (nil
+// for a raw Yelp ID).
+func localBusinessID(it IndexItem) uuid.UUID {
+	if it.BusinessUUID != nil {
+		return *it.BusinessUUID
+	}
+	return uuidOrNil(it.Review.BusinessID)
+}
+
+// Reviews returns the reviews best matching query, each with its best chunk.
+// A negative limit returns none.
+func (c *LocalClient) Reviews(ctx context.Context, query string, limit int, filter SearchFilter) (SearchReviews, error) {
+	limit = max(limit, 0)
+	hits, err := c.rankReviews(ctx, query, filter)
+	if err != nil {
+		return SearchReviews{}, err
+	}
+	var reviews []RankedReview
+	for _, h := range hits[:min(limit, len(hits))] {
+		reviews = append(reviews, RankedReview{
+			Review:       h.review.item,
+			MatchedChunk: h.review.chunks[h.chunk].text,
+			Score:        h.score,
+		})
+	}
+	return SearchReviews{BusinessReviews: reviews}, nil
+}
+
+// Businesses ranks businesses by the average score of their topKReviews best
+// matching reviews, as the other backends do. A negative limit returns none.
+func (c *LocalClient) Businesses(ctx context.Context, query string, limit int, filter SearchFilter) (SearchResult, error) {
+	limit = max(limit, 0)
+	hits, err := c.rankReviews(ctx, query, filter)
+	if err != nil {
+		return SearchResult{}, err
+	}
+
+	type business struct {
+		result BusinessResult
+		scores []float64 // best first, as hits are
+		stars  []float64
+	}
+	byKey := make(map[string]*business)
+	var order []*business
+	for _, h := range hits {
+		it := h.review.item
+		key := it.Review.BusinessID
+		if it.BusinessUUID != nil {
+			key = it.BusinessUUID.String()
+		}
+		b := byKey[key]
+		if b == nil {
+			b = &business{result: BusinessResult{
+				ID:         localBusinessID(it),
+				Name:       it.BusinessName,
+				City:       it.City,
+				State:      it.State,
+				Categories: it.Categories,
+			}}
+			byKey[key] = b
+			order = append(order, b)
+		}
+		if len(b.scores) < topKReviews {
+			b.scores = append(b.scores, h.score)
+		}
+		if it.Review.Stars > 0 {
+			b.stars = append(b.stars, float64(it.Review.Stars))
+		}
+	}
+
+	results := make([]BusinessResult, 0, len(order))
+	for _, b := range order {
+		b.result.Score = mean(b.scores)
+		b.result.Stars = mean(b.stars)
+		results = append(results, b.result)
+	}
+	slices.SortStableFunc(results, func(a, b BusinessResult) int { return cmp.Compare(b.Score, a.Score) })
+	return SearchResult{Businesses: results[:min(limit, len(results))]}, nil
+}
+
+// SearchFodmap returns the ingredient closest to ingredient by cosine
+// similarity, with that similarity as its certainty.
+func (c *LocalClient) SearchFodmap(ctx context.Context, ingredient string) (FodmapResult, float64, error) {
+	qvec, err := c.embedQuery(ctx, ingredient)
+	if err != nil {
+		return FodmapResult{}, 0, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var bestName string
+	bestScore := -2.0 // below any cosine similarity
+	for name, f := range c.fodmap {
+		if f.vec == nil {
+			continue
+		}
+		if s := dot(qvec, f.vec); s > bestScore || s == bestScore && name < bestName {
+			bestName, bestScore = name, s
+		}
+	}
+	if bestName == "" {
+		return FodmapResult{}, 0, fmt.Errorf("not found")
+	}
+	e := c.fodmap[bestName].entry
+	return FodmapResult{
+		Ingredient:    bestName,
+		Level:         e.Level,
+		Groups:        e.Groups,
+		Notes:         e.Notes,
+		Substitutions: e.Substitutions,
+	}, bestScore, nil
+}
+
+// BatchUpsertFodmap embeds each ingredient name and stores the entries.
+func (c *LocalClient) BatchUpsertFodmap(ctx context.Context, items map[string]data.FodmapEntry) error {
+	if len(items) == 0 {
+		return nil
+	}
+	if c.embedder == nil {
+		return errors.New("embedder is not configured (required for fodmap indexing)")
+	}
+	names := sortedKeys(items)
+	vecs, err := c.embedder.EmbedBatch(ctx, names)
+	if err != nil {
+		return fmt.Errorf("embedding fodmap names: %w", err)
+	}
+	if len(vecs) != len(names) {
+		return fmt.Errorf("embedder returned %d vectors for %d names", len(vecs), len(names))
+	}
+	recs := make([]localFodmapRecord, len(names))
+	for i, name := range names {
+		recs[i] = localFodmapRecord{Name: name, Entry: items[name], Vector: vecs[i]}
+	}
+	return c.writeFodmap(recs...)
+}
+
+// UpsertFodmapItem embeds and stores a single ingredient.
+func (c *LocalClient) UpsertFodmapItem(ctx context.Context, name string, entry data.FodmapEntry) error {
+	return c.BatchUpsertFodmap(ctx, map[string]data.FodmapEntry{name: entry})
+}
+
+// DeleteFodmapItem removes an ingredient. Deleting a missing one is not an
+// error.
+func (c *LocalClient) DeleteFodmapItem(_ context.Context, name string) error {
+	return c.writeFodmap(localFodmapRecord{Name: name, Deleted: true})
+}
+
+func (c *LocalClient) writeFodmap(recs ...localFodmapRecord) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.fodmapLog.append(toAny(recs)...); err != nil {
+		return err
+	}
+	for _, rec := range recs {
+		c.applyFodmap(rec)
+	}
+	return nil
+}
+
+func (c *LocalClient) applyFodmap(rec localFodmapRecord) {
+	if rec.Deleted {
+		delete(c.fodmap, rec.Name)
+		return
+	}
+	c.fodmap[rec.Name] = localFodmap{entry: rec.Entry, vec: unitVector(rec.Vector)}
+}
+
+// BatchUpsertMenu stores menu items with their vectors, overwriting items
+// with the same ID.
+func (c *LocalClient) BatchUpsertMenu(_ context.Context, items []MenuItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	recs := make([]localMenuRecord, len(items))
+	for i, it := range items {
+		rec := localMenuRecord{Item: it, Vector: it.Vector}
+		rec.Item.Vector, rec.Item.DistanceMeters = nil, nil
+		recs[i] = rec
+	}
+	return c.writeMenu(recs...)
+}
+
+// DeleteMenuItems removes menu items by ID. Missing IDs are skipped.
+func (c *LocalClient) DeleteMenuItems(_ context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	recs := make([]localMenuRecord, len(ids))
+	for i, id := range ids {
+		recs[i] = localMenuRecord{Item: MenuItem{MenuItemID: id}, Deleted: true}
+	}
+	return c.writeMenu(recs...)
+}
+
+func (c *LocalClient) writeMenu(recs ...localMenuRecord) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.menuLog.append(toAny(recs)...); err != nil {
+		return err
+	}
+	for _, rec := range recs {
+		c.applyMenu(rec)
+	}
+	return nil
+}
+
+func (c *LocalClient) applyMenu(rec localMenuRecord) {
+	id := rec.Item.MenuItemID
+	if old, ok := c.menu[id]; ok {
+		c.menuStats.Remove(menuDocText(old.item))
+		delete(c.menu, id)
+	}
+	if rec.Deleted {
+		return
+	}
+	c.menu[id] = &localMenuItem{item: rec.Item, vec: unitVector(rec.Vector)}
+	c.menuStats.Add(menuDocText(rec.Item))
+}
+
+// menuDocText is the text lexical menu search scores: dish text and
+// ingredients, original and English, as in Postgres's menuTSVector.
+func menuDocText(m MenuItem) string {
+	return joinNonEmpty(" ", m.DishName, m.Description, strings.Join(m.StatedIngredients, " "),
+		m.DishNameEn, m.DescriptionEn, strings.Join(m.StatedIngredientsEn, " "))
+}
+
+// SearchMenu ranks menu items by cosine similarity, or by BM25 over
+// menuDocText when Retrieval is lexical. City (substring), state, business
+// and geo filters narrow the candidates first. With an empty query, items are
+// ordered by distance from the geo origin, or by recency without one.
+// SortByDistance re-sorts the ranked results nearest first. A negative limit
+// returns none.
+func (c *LocalClient) SearchMenu(ctx context.Context, query string, limit int, filter SearchFilter) ([]MenuItem, error) {
+	limit = max(limit, 0)
+	lexical := query != "" && filter.Retrieval == RetrieveLexical
+	var qvec []float32
+	if query != "" && !lexical {
+		var err error
+		if qvec, err = c.embedQuery(ctx, query); err != nil {
+			return nil, err
+		}
+	}
+
+	c.mu.RLock()
+	type hit struct {
+		item  MenuItem
+		score float64
+	}
+	var hits []hit
+	for _, m := range c.menu {
+		it := m.item
+		if filter.City != "" && !containsFold(it.City, filter.City) ||
+			filter.State != "" && !strings.EqualFold(it.State, filter.State) ||
+			filter.BusinessID != uuid.Nil && it.BusinessID != filter.BusinessID {
+			continue
+		}
+		if filter.Geo != nil {
+			dist, ok := filter.Geo.Match(it.Latitude, it.Longitude)
+			if !ok {
+				continue
+			}
+			it.DistanceMeters = dist
+		}
+		var s float64
+		switch {
+		case lexical:
+			if s = bm25Score(query, menuDocText(it), &c.menuStats); s == 0 {
+				continue
+			}
+		case query != "":
+			if m.vec == nil {
+				continue
+			}
+			s = dot(qvec, m.vec)
+		}
+		hits = append(hits, hit{it, s})
+	}
+	c.mu.RUnlock()
+
+	slices.SortFunc(hits, func(a, b hit) int {
+		switch {
+		case query != "":
+			if a.score != b.score {
+				return cmp.Compare(b.score, a.score)
+			}
+		case filter.Geo != nil && filter.Geo.Origin != nil:
+			if d := cmp.Compare(*a.item.DistanceMeters, *b.item.DistanceMeters); d != 0 {
+				return d
+			}
+		default:
+			if d := strings.Compare(b.item.ScrapedAt, a.item.ScrapedAt); d != 0 {
+				return d
+			}
+		}
+		return strings.Compare(a.item.MenuItemID, b.item.MenuItemID)
+	})
+
+	results := make([]MenuItem, 0, min(limit, len(hits)))
+	for _, h := range hits[:min(limit, len(hits))] {
+		results = append(results, h.item)
+	}
+	if filter.Geo != nil && filter.Geo.SortByDistance {
+		SortMenuByDistance(results)
+	}
+	return results, nil
+}
+
+// ListMenuItems returns a page of menu items, most recently scraped first,
+// optionally narrowed to dish or restaurant names containing search.
+func (c *LocalClient) ListMenuItems(_ context.Context, search string, limit, offset int) ([]MenuItem, int, error) {
+	c.mu.RLock()
+	var items []MenuItem
+	for _, m := range c.menu {
+		it := m.item
+		if search != "" && !containsFold(it.DishName, search) && !containsFold(it.DishNameEn, search) && !containsFold(it.RestaurantName, search) {
+			continue
+		}
+		items = append(items, it)
+	}
+	c.mu.RUnlock()
+
+	slices.SortFunc(items, func(a, b MenuItem) int {
+		if d := strings.Compare(b.ScrapedAt, a.ScrapedAt); d != 0 {
+			return d
+		}
+		return strings.Compare(a.MenuItemID, b.MenuItemID)
+	})
+	total := len(items)
+	offset = min(max(offset, 0), total)
+	return items[offset:min(offset+max(limit, 0), total)], total, nil
+}
+
+// embedQuery embeds a query and scales it to unit length.
+func (c *LocalClient) embedQuery(ctx context.Context, query string) ([]float32, error) {
+	if c.embedder == nil {
+		return nil, errors.New("embedder is not configured (required for semantic search)")
+	}
+	vec, err := c.embedder.EmbedSingle(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("embedding query: %w", err)
+	}
+	return unitVector(vec), nil
+}
+
+// unitVector returns v scaled to unit length, or nil for an empty or zero
+// vector, so cosine similarity is a dot product.
+func unitVector(v []float32) []float32 {
+	var norm float64
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	if norm == 0 {
+		return nil
+	}
+	inv := 1 / math.Sqrt(norm)
+	out := make([]float32, len(v))
+	for i, x := range v {
+		out[i] = float32(float64(x) * inv)
+	}
+	return out
+}
+
+// dot returns the dot product of a and b over their common length; 0 when
+// either is nil.
+func dot(a, b []float32) float64 {
+	var s float64
+	for i := range min(len(a), len(b)) {
+		s += float64(a[i]) * float64(b[i])
+	}
+	return s
+}
+
+func mean(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func toAny[T any](xs []T) []any {
+	out := make([]any, len(xs))
+	for i, x := range xs {
+		out[i] = x
+	}
+	return out
+}
@@ -0,0 +1,19 @@
+//go:build !unix
+
+package search
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// lockLocalDir opens dir's LOCK file without locking it: there is no flock
+// here, so the one-process rule is left to the caller.
+func lockLocalDir(dir string) (*os.File, error) {
+	f, err := os.OpenFile(filepath.Join(dir, "LOCK"), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open local index lock: %w", err)
+	}
+	return f, nil
+}
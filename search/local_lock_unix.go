@@ -0,0 +1,29 @@
+//go:build unix
+
+package search
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// lockLocalDir takes an exclusive lock on dir's LOCK file, failing at once
+// when another LocalClient holds it. The lock is released when the returned
+// file is closed or the process exits.
+func lockLocalDir(dir string) (*os.File, error) {
+	f, err := os.OpenFile(filepath.Join(dir, "LOCK"), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open local index lock: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("local index %s is open in another process", dir)
+		}
+		return nil, fmt.Errorf("lock local index: %w", err)
+	}
+	return f, nil
+}
@@ -0,0 +1,158 @@
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// localCompactMin is how many superseded records a LocalClient log must hold
+// before it is compacted on open; below it the replay cost is negligible.
+const localCompactMin = 1000
+
+// localLog is the append-only JSON-lines file behind one LocalClient
+// collection: one record per write, replayed in order on open.
+type localLog struct {
+	path    string
+	f       *os.File
+	records int // records in the file, live or superseded
+}
+
+// openLocalLog replays the records of the log at path, creating it if
+// missing, into apply and leaves it open for appending. A torn final record
+// (a crash mid-write) is dropped and the file truncated to the last whole
+// one; corruption anywhere else is an error.
+func openLocalLog[T any](path string, apply func(T)) (*localLog, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	l := &localLog{path: path, f: f}
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+	var good int64
+	for {
+		var rec T
+		err := dec.Decode(&rec)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			slog.Warn("local index: dropping torn record", "path", path, "offset", good)
+			if err := f.Truncate(good); err != nil {
+				_ = f.Close()
+				return nil, fmt.Errorf("truncate %s: %w", path, err)
+			}
+			break
+		}
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("read %s at offset %d: %w", path, good, err)
+		}
+		good = dec.InputOffset()
+		apply(rec)
+		l.records++
+	}
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("seek %s: %w", path, err)
+	}
+	return l, nil
+}
+
+// append writes recs to the end of the log in a single write.
+func (l *localLog) append(recs ...any) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, r := range recs {
+		if err := enc.Encode(r); err != nil {
+			return fmt.Errorf("encode record: %w", err)
+		}
+	}
+	if _, err := l.f.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("append %s: %w", l.path, err)
+	}
+	l.records += len(recs)
+	return nil
+}
+
+// needsCompaction reports whether the log holds enough superseded records,
+// relative to live ones, to be worth rewriting.
+func (l *localLog) needsCompaction(live int) bool {
+	stale := l.records - live
+	return stale >= localCompactMin && stale > live
+}
+
+// rewrite replaces the log with recs, writing a temporary file and renaming
+// it over the log so a crash leaves either the old or the new file whole.
+func (l *localLog) rewrite(recs []any) error {
+	tmp := l.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", tmp, err)
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, r := range recs {
+		if err := enc.Encode(r); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("encode record: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write %s: %w", tmp, err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("sync %s: %w", tmp, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", tmp, err)
+	}
+	if err := l.f.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", l.path, err)
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		return fmt.Errorf("rename %s: %w", tmp, err)
+	}
+	if l.f, err = os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND, 0o644); err != nil {
+		return fmt.Errorf("reopen %s: %w", l.path, err)
+	}
+	l.records = len(recs)
+	return nil
+}
+
+// close closes the log file.
+func (l *localLog) close() error {
+	return l.f.Close()
+}
+
+// localVector stores a vector in a log record as base64 little-endian
+// float32s, about half the size of a JSON number array.
+type localVector []float32
+
+// MarshalJSON encodes v as a base64 string (encoding/json's form for
+// []byte).
+func (v localVector) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encodeVector(v))
+}
+
+// UnmarshalJSON decodes a vector written by MarshalJSON.
+func (v *localVector) UnmarshalJSON(b []byte) error {
+	var raw []byte
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	out, err := decodeVector(raw)
+	if err != nil {
+		return err
+	}
+	*v = out
+	return nil
+}
@@ -0,0 +1,339 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"fodmap/data"
+	"fodmap/data/schemas"
+	"fodmap/geo"
+
+	"github.com/google/uuid"
+)
+
+// newTestLocalClient opens a LocalClient with the hashing embedder in dir.
+func newTestLocalClient(t *testing.T, dir string) *LocalClient {
+	t.Helper()
+	c, err := NewLocalClient(dir, NewHashEmbedder())
+	if err != nil {
+		t.Fatalf("NewLocalClient: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+// localReviewItem builds an IndexItem whose single chunk is text, embedded
+// by the hashing embedder.
+func localReviewItem(id, business, city, text string, stars float32) IndexItem {
+	vec, _ := NewHashEmbedder().EmbedSingle(context.Background(), text)
+	return IndexItem{
+		Review:       schemas.Review{ReviewID: id, BusinessID: business, Stars: stars, Text: text},
+		BusinessName: "Biz " + business,
+		City:         city,
+		State:        "MA",
+		Categories:   "Restaurants, Italian",
+		Chunks:       []Chunk{{Text: text, Vector: vec}},
+	}
+}
+
+func seedLocalReviews(t *testing.T, c *LocalClient) {
+	t.Helper()
+	err := c.BatchUpsert(context.Background(), []IndexItem{
+		localReviewItem("r1", "b1", "Boston", "gluten free pasta was excellent", 5),
+		localReviewItem("r2", "b1", "Boston", "the tiramisu was too sweet", 3),
+		localReviewItem("r3", "b2", "Cambridge", "great pasta but heavy on garlic", 4),
+		localReviewItem("r4", "b3", "Boston", "friendly staff and quick service", 4),
+	})
+	if err != nil {
+		t.Fatalf("BatchUpsert: %v", err)
+	}
+}
+
+func localIDs(res SearchReviews) []string {
+	var ids []string
+	for _, r := range res.BusinessReviews {
+		ids = append(ids, r.Review.Review.ReviewID)
+	}
+	return ids
+}
+
+func TestLocalClient_Reviews(t *testing.T) {
+	c := newTestLocalClient(t, t.TempDir())
+	seedLocalReviews(t, c)
+	ctx := context.Background()
+
+	res, err := c.Reviews(ctx, "pasta", 2, SearchFilter{})
+	if err != nil {
+		t.Fatalf("Reviews: %v", err)
+	}
+	if got := localIDs(res); len(got) != 2 || got[0] != "r1" && got[0] != "r3" {
+		t.Errorf("dense reviews = %v, want the pasta reviews first", got)
+	}
+
+	res, err = c.Reviews(ctx, "garlic", 5, SearchFilter{Retrieval: RetrieveLexical})
+	if err != nil {
+		t.Fatalf("Reviews lexical: %v", err)
+	}
+	if got := localIDs(res); !reflect.DeepEqual(got, []string{"r3"}) {
+		t.Errorf("lexical reviews = %v, want only the review containing the term", got)
+	}
+	if res.BusinessReviews[0].MatchedChunk != "great pasta but heavy on garlic" {
+		t.Errorf("matched chunk = %q", res.BusinessReviews[0].MatchedChunk)
+	}
+
+	res, err = c.Reviews(ctx, "pasta", 5, SearchFilter{Alpha: 0.5, City: "bost"})
+	if err != nil {
+		t.Fatalf("Reviews hybrid: %v", err)
+	}
+	if got := localIDs(res); len(got) != 3 || got[0] != "r1" {
+		t.Errorf("hybrid Boston reviews = %v, want r1 first of the three Boston reviews", got)
+	}
+
+	res, err = c.Reviews(ctx, "", 5, SearchFilter{ReviewIDs: []string{"r4", "r2"}})
+	if err != nil {
+		t.Fatalf("Reviews by ID: %v", err)
+	}
+	if got := localIDs(res); !reflect.DeepEqual(got, []string{"r2", "r4"}) {
+		t.Errorf("reviews by ID = %v, want [r2 r4]", got)
+	}
+
+	if _, err := c.Reviews(ctx, "pasta", 5, SearchFilter{Geo: &GeoFilter{Origin: &geo.Point{}}}); !errors.Is(err, ErrGeoFilterUnsupported) {
+		t.Errorf("geo filter err = %v, want ErrGeoFilterUnsupported", err)
+	}
+}
+
+func TestLocalClient_ReplaceReview(t *testing.T) {
+	c := newTestLocalClient(t, t.TempDir())
+	seedLocalReviews(t, c)
+	ctx := context.Background()
+
+	if err := c.BatchUpsert(ctx, []IndexItem{localReviewItem("r3", "b2", "Cambridge", "loved the risotto", 5)}); err != nil {
+		t.Fatalf("BatchUpsert: %v", err)
+	}
+	res, _ := c.Reviews(ctx, "garlic", 5, SearchFilter{Retrieval: RetrieveLexical})
+	if len(res.BusinessReviews) != 0 {
+		t.Errorf("replaced chunk still matches: %v", localIDs(res))
+	}
+	res, _ = c.Reviews(ctx, "risotto", 5, SearchFilter{Retrieval: RetrieveLexical})
+	if got := localIDs(res); !reflect.DeepEqual(got, []string{"r3"}) {
+		t.Errorf("lexical reviews = %v, want [r3]", got)
+	}
+}
+
+func TestLocalClient_Businesses(t *testing.T) {
+	c := newTestLocalClient(t, t.TempDir())
+	seedLocalReviews(t, c)
+
+	res, err := c.Businesses(context.Background(), "pasta", 10, SearchFilter{Retrieval: RetrieveLexical})
+	if err != nil {
+		t.Fatalf("Businesses: %v", err)
+	}
+	if len(res.Businesses) != 2 {
+		t.Fatalf("businesses = %+v, want b1 and b2", res.Businesses)
+	}
+	for _, b := range res.Businesses {
+		if b.Name != "Biz b1" && b.Name != "Biz b2" {
+			t.Errorf("unexpected business %q", b.Name)
+		}
+		if b.Score <= 0 || b.Stars == 0 {
+			t.Errorf("business %q: score %v stars %v, want both set", b.Name, b.Score, b.Stars)
+		}
+	}
+}
+
+func TestLocalClient_NegativeLimit(t *testing.T) {
+	c := newTestLocalClient(t, t.TempDir())
+	seedLocalReviews(t, c)
+	ctx := context.Background()
+	vec, _ := NewHashEmbedder().EmbedSingle(ctx, "pasta")
+	if err := c.BatchUpsertMenu(ctx, []MenuItem{{MenuItemID: "m1", BusinessID: uuid.New(), DishName: "pasta", Vector: vec}}); err != nil {
+		t.Fatalf("BatchUpsertMenu: %v", err)
+	}
+
+	if res, err := c.Reviews(ctx, "pasta", -1, SearchFilter{}); err != nil || len(res.BusinessReviews) != 0 {
+		t.Errorf("Reviews(-1) = %+v, %v; want none", res, err)
+	}
+	if res, err := c.Businesses(ctx, "pasta", -1, SearchFilter{}); err != nil || len(res.Businesses) != 0 {
+		t.Errorf("Businesses(-1) = %+v, %v; want none", res, err)
+	}
+	if items, err := c.SearchMenu(ctx, "pasta", -1, SearchFilter{}); err != nil || len(items) != 0 {
+		t.Errorf("SearchMenu(-1) = %+v, %v; want none", items, err)
+	}
+}
+
+func TestLocalClient_Fodmap(t *testing.T) {
+	c := newTestLocalClient(t, t.TempDir())
+	ctx := context.Background()
+
+	if _, _, err := c.SearchFodmap(ctx, "garlic"); err == nil {
+		t.Error("expected not found on an empty index")
+	}
+	err := c.BatchUpsertFodmap(ctx, map[string]data.FodmapEntry{
+		"garlic": {Level: "high", Groups: []string{"fructan"}},
+		"rice":   {Level: "low"},
+	})
+	if err != nil {
+		t.Fatalf("BatchUpsertFodmap: %v", err)
+	}
+	res, certainty, err := c.SearchFodmap(ctx, "Garlic")
+	if err != nil {
+		t.Fatalf("SearchFodmap: %v", err)
+	}
+	if res.Ingredient != "garlic" || res.Level != "high" || certainty < 0.99 {
+		t.Errorf("SearchFodmap = %+v (%v), want garlic with certainty ~1", res, certainty)
+	}
+
+	if err := c.DeleteFodmapItem(ctx, "garlic"); err != nil {
+		t.Fatalf("DeleteFodmapItem: %v", err)
+	}
+	if res, _, _ := c.SearchFodmap(ctx, "garlic"); res.Ingredient != "rice" {
+		t.Errorf("after delete got %q, want the only remaining ingredient", res.Ingredient)
+	}
+}
+
+func TestLocalClient_Menu(t *testing.T) {
+	c := newTestLocalClient(t, t.TempDir())
+	ctx := context.Background()
+	e := NewHashEmbedder()
+	biz := uuid.New()
+	f := func(v float64) *float64 { return &v }
+	item := func(id, dish string, lat, lon float64, scraped string) MenuItem {
+		vec, _ := e.EmbedSingle(ctx, dish)
+		return MenuItem{MenuItemID: id, BusinessID: biz, RestaurantName: "Trattoria", City: "Boston", State: "MA",
+			DishName: dish, Latitude: f(lat), Longitude: f(lon), ScrapedAt: scraped, Vector: vec}
+	}
+	err := c.BatchUpsertMenu(ctx, []MenuItem{
+		item("m1", "mushroom risotto", 42.3601, -71.0589, "2026-01-01"),
+		item("m2", "garlic bread", 42.3611, -71.0589, "2026-01-03"),
+		item("m3", "lemon risotto", 42.4000, -71.0589, "2026-01-02"),
+	})
+	if err != nil {
+		t.Fatalf("BatchUpsertMenu: %v", err)
+	}
+
+	items, err := c.SearchMenu(ctx, "risotto", 2, SearchFilter{})
+	if err != nil {
+		t.Fatalf("SearchMenu: %v", err)
+	}
+	if len(items) != 2 || items[0].DishName == "garlic bread" || items[0].Vector != nil {
+		t.Errorf("SearchMenu = %+v, want the risottos without vectors", items)
+	}
+
+	origin := &geo.Point{Lat: 42.3601, Lon: -71.0589}
+	items, err = c.SearchMenu(ctx, "risotto", 5, SearchFilter{
+		Retrieval: RetrieveLexical,
+		Geo:       &GeoFilter{Origin: origin, RadiusMeters: 1000},
+	})
+	if err != nil {
+		t.Fatalf("SearchMenu geo: %v", err)
+	}
+	if len(items) != 1 || items[0].MenuItemID != "m1" || items[0].DistanceMeters == nil {
+		t.Errorf("SearchMenu geo = %+v, want only the nearby risotto with a distance", items)
+	}
+
+	items, _ = c.SearchMenu(ctx, "", 5, SearchFilter{Geo: &GeoFilter{Origin: origin}})
+	if len(items) != 3 || items[0].MenuItemID != "m1" || items[2].MenuItemID != "m3" {
+		t.Errorf("SearchMenu without query = %+v, want nearest first", items)
+	}
+
+	list, total, err := c.ListMenuItems(ctx, "RISOTTO", 1, 1)
+	if err != nil || total != 2 || len(list) != 1 || list[0].MenuItemID != "m1" {
+		t.Errorf("ListMenuItems = %+v total %d err %v, want the older risotto on page 2 of 2", list, total, err)
+	}
+
+	if err := c.DeleteMenuItems(ctx, []string{"m2"}); err != nil {
+		t.Fatalf("DeleteMenuItems: %v", err)
+	}
+	if _, total, _ := c.ListMenuItems(ctx, "", 10, 0); total != 2 {
+		t.Errorf("total after delete = %d, want 2", total)
+	}
+}
+
+func TestLocalClient_Persistence(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	c, err := NewLocalClient(dir, NewHashEmbedder())
+	if err != nil {
+		t.Fatalf("NewLocalClient: %v", err)
+	}
+	seedLocalReviews(t, c)
+	if err := c.UpsertFodmapItem(ctx, "onion", data.FodmapEntry{Level: "high"}); err != nil {
+		t.Fatalf("UpsertFodmapItem: %v", err)
+	}
+	if err := c.BatchUpsertMenu(ctx, []MenuItem{{MenuItemID: "m1", DishName: "risotto"}}); err != nil {
+		t.Fatalf("BatchUpsertMenu: %v", err)
+	}
+	want, _ := c.Reviews(ctx, "pasta", 5, SearchFilter{Alpha: 0.5})
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// A crash mid-append leaves a torn record at the end of a log.
+	f, err := os.OpenFile(filepath.Join(dir, "reviews.jsonl"), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"item":{"Review":{"review_id":"torn"`)
+	_ = f.Close()
+
+	c = newTestLocalClient(t, dir)
+	got, err := c.Reviews(ctx, "pasta", 5, SearchFilter{Alpha: 0.5})
+	if err != nil {
+		t.Fatalf("Reviews after reopen: %v", err)
+	}
+	if !reflect.DeepEqual(localIDs(got), localIDs(want)) || got.BusinessReviews[0].Score != want.BusinessReviews[0].Score {
+		t.Errorf("reopened reviews = %v, want %v", localIDs(got), localIDs(want))
+	}
+	if res, _, err := c.SearchFodmap(ctx, "onion"); err != nil || res.Level != "high" {
+		t.Errorf("reopened fodmap = %+v, %v", res, err)
+	}
+	if _, total, _ := c.ListMenuItems(ctx, "", 10, 0); total != 1 {
+		t.Errorf("reopened menu total = %d, want 1", total)
+	}
+
+	// The torn record was dropped, so appending after it stays readable.
+	if err := c.BatchUpsert(ctx, []IndexItem{localReviewItem("r5", "b4", "Boston", "pasta again", 2)}); err != nil {
+		t.Fatalf("BatchUpsert: %v", err)
+	}
+	_ = c.Close()
+	c = newTestLocalClient(t, dir)
+	if res, _ := c.Reviews(ctx, "", 10, SearchFilter{}); len(res.BusinessReviews) != 5 {
+		t.Errorf("reviews after append = %v, want 5", localIDs(res))
+	}
+}
+
+func TestLocalClient_Compaction(t *testing.T) {
+	dir := t.TempDir()
+	c := newTestLocalClient(t, dir)
+	ctx := context.Background()
+	for range localCompactMin + 1 {
+		if err := c.UpsertFodmapItem(ctx, "onion", data.FodmapEntry{Level: "high"}); err != nil {
+			t.Fatalf("UpsertFodmapItem: %v", err)
+		}
+	}
+	_ = c.Close()
+
+	c = newTestLocalClient(t, dir)
+	if c.fodmapLog.records != 1 {
+		t.Errorf("records after compaction = %d, want 1", c.fodmapLog.records)
+	}
+	if res, _, err := c.SearchFodmap(ctx, "onion"); err != nil || res.Ingredient != "onion" {
+		t.Errorf("SearchFodmap after compaction = %+v, %v", res, err)
+	}
+}
+
+func TestLocalClient_Lock(t *testing.T) {
+	dir := t.TempDir()
+	c := newTestLocalClient(t, dir)
+
+	if _, err := NewLocalClient(dir, NewHashEmbedder()); err == nil {
+		t.Fatal("second NewLocalClient on a held dir succeeded, want error")
+	}
+	_ = c.Close()
+	newTestLocalClient(t, dir)
+}
@@ -0,0 +1,144 @@
+package search
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrMenuHistoryUnsupported is returned by menu stores that keep only the
+// latest version of each item, e.g. Weaviate.
+var ErrMenuHistoryUnsupported = errors.New("menu history is not supported by this menu store")
+
+// MenuChangeType classifies an item-level difference between two scrapes of
+// the same menu.
+type MenuChangeType string
+
+const (
+	MenuItemAdded              MenuChangeType = "added"
+	MenuItemRemoved            MenuChangeType = "removed"
+	MenuItemPriceChanged       MenuChangeType = "price_changed"
+	MenuItemIngredientsChanged MenuChangeType = "ingredients_changed"
+	MenuItemDescriptionChanged MenuChangeType = "description_changed"
+)
+
+// MenuChange is one item-level difference recorded against a snapshot. Only
+// the Old/New fields relevant to Type are set.
+type MenuChange struct {
+	MenuItemID     string         `json:"menu_item_id"`
+	DishName       string         `json:"dish_name"`
+	MenuSection    string         `json:"menu_section,omitempty"`
+	Type           MenuChangeType `json:"type"`
+	OldPrice       *float64       `json:"old_price,omitempty"`
+	NewPrice       *float64       `json:"new_price,omitempty"`
+	OldIngredients []string       `json:"old_ingredients,omitempty"`
+	NewIngredients []string       `json:"new_ingredients,omitempty"`
+	OldDescription string         `json:"old_description,omitempty"`
+	NewDescription string         `json:"new_description,omitempty"`
+}
+
+// MenuSnapshot records one scrape of one menu URL and what changed since the
+// previous scrape of that URL. The first snapshot of a URL lists every item
+// as added.
+type MenuSnapshot struct {
+	ID         uuid.UUID    `json:"id"`
+	BusinessID uuid.UUID    `json:"business_id"`
+	SourceURL  string       `json:"source_url"`
+	ScrapedAt  time.Time    `json:"scraped_at"`
+	ItemCount  int          `json:"item_count"`
+	Changes    []MenuChange `json:"changes"`
+}
+
+// DiffMenu compares the items previously stored for a menu with a fresh
+// scrape, matching items by MenuItemID. An item present in both can yield
+// several changes, e.g. a price and an ingredient change. Ingredients are
+// compared as case-insensitive sets, so reordering a list is not a change;
+// descriptions are compared after trimming whitespace. Changes are ordered
+// as next lists its items, followed by removals in prev order.
+func DiffMenu(prev, next []MenuItem) []MenuChange {
+	old := make(map[string]MenuItem, len(prev))
+	for _, it := range prev {
+		old[it.MenuItemID] = it
+	}
+	seen := make(map[string]bool, len(next))
+	var changes []MenuChange
+	for _, it := range next {
+		if seen[it.MenuItemID] {
+			continue
+		}
+		seen[it.MenuItemID] = true
+		base := MenuChange{MenuItemID: it.MenuItemID, DishName: it.DishName, MenuSection: it.MenuSection}
+		was, ok := old[it.MenuItemID]
+		if !ok {
+			c := base
+			c.Type = MenuItemAdded
+			c.NewPrice = it.Price
+			c.NewIngredients = it.StatedIngredients
+			c.NewDescription = it.Description
+			changes = append(changes, c)
+			continue
+		}
+		if !samePrice(was.Price, it.Price) {
+			c := base
+			c.Type = MenuItemPriceChanged
+			c.OldPrice, c.NewPrice = was.Price, it.Price
+			changes = append(changes, c)
+		}
+		if !sameIngredients(was.StatedIngredients, it.StatedIngredients) {
+			c := base
+			c.Type = MenuItemIngredientsChanged
+			c.OldIngredients, c.NewIngredients = was.StatedIngredients, it.StatedIngredients
+			changes = append(changes, c)
+		}
+		if strings.TrimSpace(was.Description) != strings.TrimSpace(it.Description) {
+			c := base
+			c.Type = MenuItemDescriptionChanged
+			c.OldDescription, c.NewDescription = was.Description, it.Description
+			changes = append(changes, c)
+		}
+	}
+	for _, it := range prev {
+		if seen[it.MenuItemID] {
+			continue
+		}
+		seen[it.MenuItemID] = true
+		changes = append(changes, MenuChange{
+			MenuItemID:     it.MenuItemID,
+			DishName:       it.DishName,
+			MenuSection:    it.MenuSection,
+			Type:           MenuItemRemoved,
+			OldPrice:       it.Price,
+			OldIngredients: it.StatedIngredients,
+			OldDescription: it.Description,
+		})
+	}
+	return changes
+}
+
+// samePrice compares prices to the cent, the precision menu_items stores.
+func samePrice(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return math.Round(*a*100) == math.Round(*b*100)
+}
+
+func sameIngredients(a, b []string) bool {
+	return slices.Equal(ingredientSet(a), ingredientSet(b))
+}
+
+// ingredientSet lowercases, trims, sorts and de-duplicates ingredients.
+func ingredientSet(in []string) []string {
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		if s = strings.ToLower(strings.TrimSpace(s)); s != "" {
+			out = append(out, s)
+		}
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
@@ -0,0 +1,65 @@
+package search
+
+import (
+	"testing"
+)
+
+func TestDiffMenu(t *testing.T) {
+	p := func(f float64) *float64 { return &f }
+	prev := []MenuItem{
+		{MenuItemID: "1", DishName: "Pad Thai", Price: p(14), StatedIngredients: []string{"Rice Noodles", "egg", "peanut"}},
+		{MenuItemID: "2", DishName: "Green Curry", Price: p(16), StatedIngredients: []string{"coconut milk", "chicken"}, Description: "Mild."},
+		{MenuItemID: "3", DishName: "Spring Rolls", Price: p(7)},
+	}
+	next := []MenuItem{
+		// Same ingredients reordered and recased, price equal to the cent.
+		{MenuItemID: "1", DishName: "Pad Thai", Price: p(14.001), StatedIngredients: []string{"peanut", "rice noodles", "Egg "}},
+		// Price and sauce changed.
+		{MenuItemID: "2", DishName: "Green Curry", Price: p(17), StatedIngredients: []string{"coconut milk", "chicken", "garlic"}, Description: " Mild. "},
+		{MenuItemID: "4", DishName: "Mango Sticky Rice", Price: p(9)},
+	}
+
+	got := DiffMenu(prev, next)
+	want := []struct {
+		id  string
+		typ MenuChangeType
+	}{
+		{"2", MenuItemPriceChanged},
+		{"2", MenuItemIngredientsChanged},
+		{"4", MenuItemAdded},
+		{"3", MenuItemRemoved},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].MenuItemID != w.id || got[i].Type != w.typ {
+			t.Errorf("change %d = %s/%s, want %s/%s", i, got[i].MenuItemID, got[i].Type, w.id, w.typ)
+		}
+	}
+	if *got[0].OldPrice != 16 || *got[0].NewPrice != 17 {
+		t.Errorf("price change = %v -> %v", *got[0].OldPrice, *got[0].NewPrice)
+	}
+	if len(got[1].NewIngredients) != 3 || got[1].NewIngredients[2] != "garlic" {
+		t.Errorf("new ingredients = %v", got[1].NewIngredients)
+	}
+	if got[3].DishName != "Spring Rolls" || *got[3].OldPrice != 7 {
+		t.Errorf("removed change = %+v", got[3])
+	}
+}
+
+func TestDiffMenu_PriceAppearsAndFirstScrape(t *testing.T) {
+	p := 12.5
+	got := DiffMenu([]MenuItem{{MenuItemID: "1", DishName: "Soup"}}, []MenuItem{{MenuItemID: "1", DishName: "Soup", Price: &p}})
+	if len(got) != 1 || got[0].Type != MenuItemPriceChanged || got[0].OldPrice != nil || *got[0].NewPrice != p {
+		t.Errorf("got %+v, want one price change from nil", got)
+	}
+
+	got = DiffMenu(nil, []MenuItem{{MenuItemID: "1"}, {MenuItemID: "2"}, {MenuItemID: "1"}})
+	if len(got) != 2 || got[0].Type != MenuItemAdded || got[1].Type != MenuItemAdded {
+		t.Errorf("first scrape = %+v, want two additions", got)
+	}
+	if got := DiffMenu([]MenuItem{{MenuItemID: "1", Description: "x"}}, []MenuItem{{MenuItemID: "1", Description: "y"}}); len(got) != 1 || got[0].Type != MenuItemDescriptionChanged {
+		t.Errorf("description change = %+v", got)
+	}
+}
@@ -0,0 +1,468 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"fodmap/data"
+)
+
+// CollectionRegulatory names the regulatory updates in a migration. Its
+// vectors live only in Weaviate.
+const CollectionRegulatory = "regulatory"
+
+// MigrationCollections lists every collection a Migration can copy, in the
+// order it copies them.
+var MigrationCollections = []string{CollectionReviews, CollectionFodmap, CollectionMenu, CollectionRegulatory}
+
+// DefaultMigrationBatchSize is how many records a Migration reads and writes at
+// a time: reviews with all their chunks, ingredients, menu items or
+// regulatory updates.
+const DefaultMigrationBatchSize = 256
+
+// FodmapRecord is a FODMAP ingredient with the vector its backend stores,
+// as exported for migration.
+type FodmapRecord struct {
+	Name   string
+	Entry  data.FodmapEntry
+	Vector []float32
+}
+
+// The exporters read a collection, with its vectors, a page at a time in a
+// stable order. cursor is "" for the first page and otherwise the next
+// cursor the previous page returned; a page with next "" is the last.
+// Backends implement those of their collections they store.
+type (
+	ReviewExporter interface {
+		ExportReviews(ctx context.Context, cursor string, limit int) (items []IndexItem, next string, err error)
+	}
+	FodmapExporter interface {
+		ExportFodmap(ctx context.Context, cursor string, limit int) (items []FodmapRecord, next string, err error)
+	}
+	MenuExporter interface {
+		ExportMenu(ctx context.Context, cursor string, limit int) (items []MenuItem, next string, err error)
+	}
+	RegulatoryExporter interface {
+		ExportRegulatory(ctx context.Context, cursor string, limit int) (items []RegulatoryUpdate, next string, err error)
+	}
+)
+
+// The importers write exported records with the vectors they carry, never
+// embedding. Reviews are written with BatchUpsert unless the destination
+// implements ReviewImporter, and menu items with BatchUpsertMenu unless it
+// implements MenuImporter.
+type (
+	ReviewImporter interface {
+		ImportReviews(ctx context.Context, items []IndexItem) error
+	}
+	FodmapImporter interface {
+		ImportFodmap(ctx context.Context, items []FodmapRecord) error
+	}
+	// MenuImporter writes menu items, skipping those the destination cannot
+	// hold (Postgres needs the restaurant row), and returns how many it
+	// skipped.
+	MenuImporter interface {
+		ImportMenu(ctx context.Context, items []MenuItem) (skipped int, err error)
+	}
+	RegulatoryImporter interface {
+		ImportRegulatory(ctx context.Context, items []RegulatoryUpdate) error
+	}
+)
+
+// CollectionCounter counts the vectors a backend stores for a collection:
+// review chunks for reviews, one per record otherwise.
+type CollectionCounter interface {
+	CountCollection(ctx context.Context, collection string) (int64, error)
+}
+
+// Migration copies collections with their vectors from one search backend
+// to another, so switching backends needs no re-embedding. From and To are
+// backend clients (*Client, *PostgresClient, *PineconeClient or
+// *LocalClient); what each can export, import and count is found by type
+// assertion, as the server does for its capabilities.
+type Migration struct {
+	From, To any
+	// FromName and ToName identify the endpoints in the checkpoint, so a
+	// checkpoint is never resumed against different ones.
+	FromName, ToName string
+	// Collections to copy; empty means every collection both backends
+	// support.
+	Collections []string
+	BatchSize   int
+	// Checkpoint is the file progress is saved to after every batch and
+	// resumed from; empty disables checkpointing.
+	Checkpoint string
+	// FromModel names the embedding model the source's vectors were made
+	// with, for sources that do not record it (every backend but
+	// Postgres); empty when unknown.
+	FromModel string
+
+	spaceChecked map[string]bool
+}
+
+// embeddingSpaceRecorder is implemented by backends that record the model
+// and dimension of each collection's vectors (PostgresClient).
+type embeddingSpaceRecorder interface {
+	ActiveEmbeddingSpace(ctx context.Context, collection string) (EmbeddingSpace, bool, error)
+}
+
+// checkEmbeddingSpace refuses to write vectors of dimension dim to coll when
+// the destination records an active embedding space for coll of another
+// dimension, or of another model than the source's (its active space's, or
+// FromModel). Vectors of another model would be stored and searched as if
+// comparable to the destination's queries. It checks once per collection,
+// on the first batch with a vector; dim is 0 for a batch without one.
+func (m *Migration) checkEmbeddingSpace(ctx context.Context, coll string, dim int) error {
+	if dim == 0 || m.spaceChecked[coll] {
+		return nil
+	}
+	dst, ok := m.To.(embeddingSpaceRecorder)
+	if !ok {
+		return nil
+	}
+	space, ok, err := dst.ActiveEmbeddingSpace(ctx, coll)
+	if err != nil || !ok {
+		return err
+	}
+	if dim != space.Dim {
+		return fmt.Errorf("source vectors have %d dimensions, the destination's active %s embedding space %d (%s) has %d; "+
+			"migrate into a destination embedding the same model, or re-embed the source first", dim, coll, space.ID, space.Model, space.Dim)
+	}
+	model := m.FromModel
+	if src, ok := m.From.(embeddingSpaceRecorder); ok {
+		s, ok, err := src.ActiveEmbeddingSpace(ctx, coll)
+		if err != nil {
+			return fmt.Errorf("source: %w", err)
+		}
+		if ok {
+			model = s.Model
+		}
+	}
+	switch model {
+	case "":
+		slog.Warn("migrate: the source's embedding model is unknown; its vectors are assumed to be the destination's model",
+			"collection", coll, "model", space.Model, "dim", space.Dim)
+	case space.Model:
+	default:
+		return fmt.Errorf("source vectors were embedded with %s, the destination's active %s embedding space %d with %s; "+
+			"migrate into a destination embedding the same model, or re-embed the source first", model, coll, space.ID, space.Model)
+	}
+	if m.spaceChecked == nil {
+		m.spaceChecked = make(map[string]bool)
+	}
+	m.spaceChecked[coll] = true
+	return nil
+}
+
+// MigrationResult reports one collection of a migration.
+type MigrationResult struct {
+	Collection string
+	// Unsupported says why the collection was not copied; the other fields
+	// are then zero.
+	Unsupported string
+	Copied      int64 // records written, over every run of a resumed migration
+	Skipped     int64 // records the destination could not hold
+	// Source and Destination are the vector counts after copying, or -1
+	// when the backend cannot count them. Verified is set when they agree,
+	// allowing for skipped records.
+	Source, Destination int64
+	Verified            bool
+}
+
+// migrationCheckpoint is the saved progress of a migration.
+type migrationCheckpoint struct {
+	From        string                         `json:"from"`
+	To          string                         `json:"to"`
+	Collections map[string]*collectionProgress `json:"collections"`
+}
+
+type collectionProgress struct {
+	Cursor  string `json:"cursor,omitempty"`
+	Copied  int64  `json:"copied"`
+	Skipped int64  `json:"skipped,omitempty"`
+	Done    bool   `json:"done,omitempty"`
+}
+
+// Run copies the collections, resuming from the checkpoint if there is one,
+// and verifies each by comparing vector counts. A collection named in
+// Collections that either backend does not support is an error; when
+// Collections is empty such collections are reported as unsupported.
+func (m *Migration) Run(ctx context.Context) ([]MigrationResult, error) {
+	batch := m.BatchSize
+	if batch <= 0 {
+		batch = DefaultMigrationBatchSize
+	}
+	collections := m.Collections
+	if len(collections) == 0 {
+		collections = MigrationCollections
+	}
+	for _, coll := range collections {
+		if !slices.Contains(MigrationCollections, coll) {
+			return nil, fmt.Errorf("unknown collection %q (want one of %v)", coll, MigrationCollections)
+		}
+		if reason := m.unsupported(coll); reason != "" && len(m.Collections) > 0 {
+			return nil, fmt.Errorf("cannot migrate %s: %s", coll, reason)
+		}
+	}
+
+	cp, err := m.loadCheckpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []MigrationResult
+	for _, coll := range collections {
+		if reason := m.unsupported(coll); reason != "" {
+			results = append(results, MigrationResult{Collection: coll, Unsupported: reason})
+			continue
+		}
+		if err := m.ensureSchema(ctx, coll); err != nil {
+			return results, err
+		}
+		p := cp.Collections[coll]
+		if p == nil {
+			p = &collectionProgress{}
+			cp.Collections[coll] = p
+		}
+		if p.Done {
+			slog.Info("migrate: collection already copied", "collection", coll, "copied", p.Copied)
+		} else if err := m.copyCollection(ctx, coll, batch, p, func() error { return m.saveCheckpoint(cp) }); err != nil {
+			return results, fmt.Errorf("migrate %s: %w", coll, err)
+		}
+		res := MigrationResult{Collection: coll, Copied: p.Copied, Skipped: p.Skipped}
+		if res.Source, err = countCollection(ctx, m.From, coll); err != nil {
+			return results, fmt.Errorf("count source %s: %w", coll, err)
+		}
+		if res.Destination, err = countCollection(ctx, m.To, coll); err != nil {
+			return results, fmt.Errorf("count destination %s: %w", coll, err)
+		}
+		res.Verified = res.Source >= 0 && res.Destination == res.Source-res.Skipped
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// unsupported returns why coll cannot be copied between the backends, or
+// "" if it can.
+func (m *Migration) unsupported(coll string) string {
+	var canExport, canImport bool
+	switch coll {
+	case CollectionReviews:
+		_, canExport = m.From.(ReviewExporter)
+		_, canImport = m.To.(ReviewImporter)
+		if !canImport {
+			_, canImport = m.To.(interface {
+				BatchUpsert(context.Context, []IndexItem) error
+			})
+		}
+	case CollectionFodmap:
+		_, canExport = m.From.(FodmapExporter)
+		_, canImport = m.To.(FodmapImporter)
+	case CollectionMenu:
+		_, canExport = m.From.(MenuExporter)
+		_, canImport = m.To.(MenuImporter)
+		if !canImport {
+			_, canImport = m.To.(interface {
+				BatchUpsertMenu(context.Context, []MenuItem) error
+			})
+		}
+	case CollectionRegulatory:
+		_, canExport = m.From.(RegulatoryExporter)
+		_, canImport = m.To.(RegulatoryImporter)
+	}
+	switch {
+	case !canExport:
+		return "the source backend does not store it"
+	case !canImport:
+		return "the destination backend does not store it"
+	}
+	return ""
+}
+
+// ensureSchema creates coll's schema on the destination where the backend
+// has one to create.
+func (m *Migration) ensureSchema(ctx context.Context, coll string) error {
+	var err error
+	switch coll {
+	case CollectionReviews:
+		if s, ok := m.To.(interface{ EnsureSchema(context.Context) error }); ok {
+			err = s.EnsureSchema(ctx)
+		}
+	case CollectionFodmap:
+		if s, ok := m.To.(interface{ EnsureFodmapSchema(context.Context) error }); ok {
+			err = s.EnsureFodmapSchema(ctx)
+		}
+	case CollectionMenu:
+		if s, ok := m.To.(interface{ EnsureMenuSchema(context.Context) error }); ok {
+			err = s.EnsureMenuSchema(ctx)
+		}
+	case CollectionRegulatory:
+		if s, ok := m.To.(interface{ EnsureRegulatorySchema(context.Context) error }); ok {
+			err = s.EnsureRegulatorySchema(ctx)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s schema init: %w", coll, err)
+	}
+	return nil
+}
+
+// copyCollection dispatches coll to copyPages with its exporter and
+// importer. unsupported has already checked both exist.
+func (m *Migration) copyCollection(ctx context.Context, coll string, batch int, p *collectionProgress, save func() error) error {
+	switch coll {
+	case CollectionReviews:
+		imp := func(ctx context.Context, items []IndexItem) (int, error) {
+			dim := 0
+			for _, it := range items {
+				for _, ch := range it.Chunks {
+					dim = max(dim, len(ch.Vector))
+				}
+				dim = max(dim, len(it.Vector))
+			}
+			if err := m.checkEmbeddingSpace(ctx, coll, dim); err != nil {
+				return 0, err
+			}
+			if ri, ok := m.To.(ReviewImporter); ok {
+				return 0, ri.ImportReviews(ctx, items)
+			}
+			return 0, m.To.(interface {
+				BatchUpsert(context.Context, []IndexItem) error
+			}).BatchUpsert(ctx, items)
+		}
+		return copyPages(ctx, coll, batch, p, save, m.From.(ReviewExporter).ExportReviews, imp)
+	case CollectionFodmap:
+		imp := func(ctx context.Context, items []FodmapRecord) (int, error) {
+			dim := 0
+			for _, it := range items {
+				dim = max(dim, len(it.Vector))
+			}
+			if err := m.checkEmbeddingSpace(ctx, coll, dim); err != nil {
+				return 0, err
+			}
+			return 0, m.To.(FodmapImporter).ImportFodmap(ctx, items)
+		}
+		return copyPages(ctx, coll, batch, p, save, m.From.(FodmapExporter).ExportFodmap, imp)
+	case CollectionMenu:
+		imp := func(ctx context.Context, items []MenuItem) (int, error) {
+			dim := 0
+			for _, it := range items {
+				dim = max(dim, len(it.Vector))
+			}
+			if err := m.checkEmbeddingSpace(ctx, coll, dim); err != nil {
+				return 0, err
+			}
+			if mi, ok := m.To.(MenuImporter); ok {
+				return mi.ImportMenu(ctx, items)
+			}
+			return 0, m.To.(interface {
+				BatchUpsertMenu(context.Context, []MenuItem) error
+			}).BatchUpsertMenu(ctx, items)
+		}
+		return copyPages(ctx, coll, batch, p, save, m.From.(MenuExporter).ExportMenu, imp)
+	case CollectionRegulatory:
+		imp := func(ctx context.Context, items []RegulatoryUpdate) (int, error) {
+			return 0, m.To.(RegulatoryImporter).ImportRegulatory(ctx, items)
+		}
+		return copyPages(ctx, coll, batch, p, save, m.From.(RegulatoryExporter).ExportRegulatory, imp)
+	}
+	return fmt.Errorf("unknown collection %q", coll)
+}
+
+// copyPages copies a collection page by page from p's cursor, saving
+// progress after every page written.
+func copyPages[T any](ctx context.Context, coll string, batch int, p *collectionProgress, save func() error,
+	export func(context.Context, string, int) ([]T, string, error),
+	imp func(context.Context, []T) (int, error),
+) error {
+	for !p.Done {
+		items, next, err := export(ctx, p.Cursor, batch)
+		if err != nil {
+			return fmt.Errorf("export: %w", err)
+		}
+		if len(items) > 0 {
+			skipped, err := imp(ctx, items)
+			if err != nil {
+				return fmt.Errorf("import: %w", err)
+			}
+			p.Copied += int64(len(items) - skipped)
+			p.Skipped += int64(skipped)
+		}
+		p.Cursor, p.Done = next, next == ""
+		if err := save(); err != nil {
+			return err
+		}
+		slog.Info("migrate: batch copied", "collection", coll, "batch", len(items), "copied", p.Copied, "skipped", p.Skipped)
+	}
+	return nil
+}
+
+// countCollection counts coll's vectors on backend, or returns -1 when the
+// backend cannot count them.
+func countCollection(ctx context.Context, backend any, coll string) (int64, error) {
+	c, ok := backend.(CollectionCounter)
+	if !ok {
+		return -1, nil
+	}
+	return c.CountCollection(ctx, coll)
+}
+
+// loadCheckpoint reads the migration's checkpoint, or starts a fresh one
+// when there is none.
+func (m *Migration) loadCheckpoint() (*migrationCheckpoint, error) {
+	fresh := &migrationCheckpoint{From: m.FromName, To: m.ToName, Collections: make(map[string]*collectionProgress)}
+	if m.Checkpoint == "" {
+		return fresh, nil
+	}
+	raw, err := os.ReadFile(m.Checkpoint)
+	if errors.Is(err, os.ErrNotExist) {
+		return fresh, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+	var cp migrationCheckpoint
+	if err := json.Unmarshal(raw, &cp); err != nil {
+		return nil, fmt.Errorf("parse checkpoint %s: %w", m.Checkpoint, err)
+	}
+	if cp.From != m.FromName || cp.To != m.ToName {
+		return nil, fmt.Errorf("checkpoint %s is for a migration from %s to %s; remove it or pass another --checkpoint",
+			m.Checkpoint, cp.From, cp.To)
+	}
+	if cp.Collections == nil {
+		cp.Collections = make(map[string]*collectionProgress)
+	}
+	slog.Info("migrate: resuming from checkpoint", "path", m.Checkpoint)
+	return &cp, nil
+}
+
+// saveCheckpoint writes cp through a temporary file, so a crash mid-write
+// leaves the previous checkpoint intact.
+func (m *Migration) saveCheckpoint(cp *migrationCheckpoint) error {
+	if m.Checkpoint == "" {
+		return nil
+	}
+	raw, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint: %w", err)
+	}
+	if dir := filepath.Dir(m.Checkpoint); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create checkpoint dir: %w", err)
+		}
+	}
+	tmp := m.Checkpoint + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, m.Checkpoint); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Migration support for the local backend: collections are paged in key
+// order, the cursor being the last key of the previous page. The vectors
+// exported are the unit-length ones the index keeps, which rank the same
+// under cosine similarity.
+
+// ExportReviews implements ReviewExporter.
+func (c *LocalClient) ExportReviews(_ context.Context, cursor string, limit int) ([]IndexItem, string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	ids, next := localPage(c.reviews, cursor, limit)
+	items := make([]IndexItem, len(ids))
+	for i, id := range ids {
+		r := c.reviews[id]
+		items[i] = r.item
+		items[i].Chunks = make([]Chunk, len(r.chunks))
+		for j, ch := range r.chunks {
+			items[i].Chunks[j] = Chunk{Text: ch.text, Vector: ch.vec}
+		}
+	}
+	return items, next, nil
+}
+
+// ExportFodmap implements FodmapExporter.
+func (c *LocalClient) ExportFodmap(_ context.Context, cursor string, limit int) ([]FodmapRecord, string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	names, next := localPage(c.fodmap, cursor, limit)
+	items := make([]FodmapRecord, len(names))
+	for i, name := range names {
+		f := c.fodmap[name]
+		items[i] = FodmapRecord{Name: name, Entry: f.entry, Vector: f.vec}
+	}
+	return items, next, nil
+}
+
+// ExportMenu implements MenuExporter.
+func (c *LocalClient) ExportMenu(_ context.Context, cursor string, limit int) ([]MenuItem, string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	ids, next := localPage(c.menu, cursor, limit)
+	items := make([]MenuItem, len(ids))
+	for i, id := range ids {
+		items[i] = c.menu[id].item
+		items[i].Vector = c.menu[id].vec
+	}
+	return items, next, nil
+}
+
+// ImportFodmap implements FodmapImporter.
+func (c *LocalClient) ImportFodmap(_ context.Context, items []FodmapRecord) error {
+	if len(items) == 0 {
+		return nil
+	}
+	recs := make([]localFodmapRecord, len(items))
+	for i, it := range items {
+		recs[i] = localFodmapRecord{Name: it.Name, Entry: it.Entry, Vector: it.Vector}
+	}
+	return c.writeFodmap(recs...)
+}
+
+// CountCollection implements CollectionCounter.
+func (c *LocalClient) CountCollection(_ context.Context, collection string) (int64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	switch collection {
+	case CollectionReviews:
+		var n int64
+		for _, r := range c.reviews {
+			n += int64(len(r.chunks))
+		}
+		return n, nil
+	case CollectionFodmap:
+		return int64(len(c.fodmap)), nil
+	case CollectionMenu:
+		return int64(len(c.menu)), nil
+	}
+	return 0, fmt.Errorf("local backend does not store %s", collection)
+}
+
+// localPage returns up to limit of m's keys after cursor in order, and the
+// cursor of the next page, "" when these are the last.
+func localPage[V any](m map[string]V, cursor string, limit int) ([]string, string) {
+	keys := sortedKeys(m)
+	start, found := slices.BinarySearch(keys, cursor)
+	if found {
+		start++
+	}
+	keys = keys[start:]
+	if len(keys) <= limit {
+		return keys, ""
+	}
+	keys = keys[:limit]
+	return keys, keys[limit-1]
+}
@@ -0,0 +1,319 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fodmap/data"
+)
+
+// Migration support for the Pinecone backend, which stores reviews and
+// FODMAP ingredients. Namespaces are paged with the list endpoint's
+// pagination token.
+
+// pineconeListLimit is the most IDs the list endpoint returns per page.
+const pineconeListLimit = 100
+
+// pineconeUpsertBatch bounds the vectors per upsert request, keeping 768-dim
+// vectors with their metadata under Pinecone's 2 MB request limit.
+const pineconeUpsertBatch = 100
+
+// listIDs returns a page of the vector IDs in namespace starting with
+// prefix, and the token of the next page ("" after the last).
+func (c *PineconeClient) listIDs(ctx context.Context, namespace, prefix, token string, limit int) ([]string, string, error) {
+	q := url.Values{"namespace": {namespace}, "limit": {strconv.Itoa(limit)}}
+	if prefix != "" {
+		q.Set("prefix", prefix)
+	}
+	if token != "" {
+		q.Set("paginationToken", token)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.IndexHost+"/vectors/list?"+q.Encode(), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("creating list request: %w", err)
+	}
+	req.Header.Set("Api-Key", c.APIKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("executing pinecone list: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		out, _ := io.ReadAll(resp.Body)
+		return nil, "", fmt.Errorf("pinecone list error (status %d): %s", resp.StatusCode, string(out))
+	}
+	var res struct {
+		Vectors []struct {
+			ID string `json:"id"`
+		} `json:"vectors"`
+		Pagination *struct {
+			Next string `json:"next"`
+		} `json:"pagination"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, "", fmt.Errorf("decoding pinecone list response: %w", err)
+	}
+	ids := make([]string, len(res.Vectors))
+	for i, v := range res.Vectors {
+		ids[i] = v.ID
+	}
+	var next string
+	if res.Pagination != nil {
+		next = res.Pagination.Next
+	}
+	return ids, next, nil
+}
+
+// pineconeReviewCursor is ExportReviews' cursor: the list token, and the
+// review the previous page ended on, whose chunks it already exported.
+type pineconeReviewCursor struct {
+	Token string `json:"token"`
+	Last  string `json:"last"`
+}
+
+// splitChunkID splits a review chunk's vector ID, "<review>_chunk_<i>".
+func splitChunkID(id string) (reviewID string, chunk int) {
+	i := strings.LastIndex(id, "_chunk_")
+	if i < 0 {
+		return id, 0
+	}
+	n, _ := strconv.Atoi(id[i+len("_chunk_"):])
+	return id[:i], n
+}
+
+// ExportReviews implements ReviewExporter. Listed IDs come in order, so a
+// review's chunks are adjacent; the review a page ends on is completed with
+// a prefix listing and skipped by the next page.
+func (c *PineconeClient) ExportReviews(ctx context.Context, cursor string, limit int) ([]IndexItem, string, error) {
+	var cur pineconeReviewCursor
+	if cursor != "" {
+		if err := json.Unmarshal([]byte(cursor), &cur); err != nil {
+			return nil, "", fmt.Errorf("invalid cursor: %w", err)
+		}
+	}
+
+	var order []string
+	chunkIDs := make(map[string][]string)
+	token := cur.Token
+	for {
+		ids, next, err := c.listIDs(ctx, pineconeReviewNamespace, "", token, pineconeListLimit)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, id := range ids {
+			rid, _ := splitChunkID(id)
+			if rid == cur.Last {
+				continue
+			}
+			if _, ok := chunkIDs[rid]; !ok {
+				order = append(order, rid)
+			}
+			chunkIDs[rid] = append(chunkIDs[rid], id)
+		}
+		token = next
+		if token == "" || len(order) >= limit {
+			break
+		}
+	}
+	if len(order) == 0 {
+		return nil, "", nil
+	}
+
+	last := order[len(order)-1]
+	if token != "" {
+		var all []string
+		var prefixToken string
+		for {
+			ids, next, err := c.listIDs(ctx, pineconeReviewNamespace, last+"_chunk_", prefixToken, pineconeListLimit)
+			if err != nil {
+				return nil, "", err
+			}
+			all = append(all, ids...)
+			if prefixToken = next; prefixToken == "" {
+				break
+			}
+		}
+		chunkIDs[last] = all
+	}
+
+	var ids []string
+	for _, rid := range order {
+		ids = append(ids, chunkIDs[rid]...)
+	}
+	vectors, err := c.fetchVectors(ctx, ids, pineconeReviewNamespace)
+	if err != nil {
+		return nil, "", err
+	}
+
+	items := make([]IndexItem, 0, len(order))
+	for _, rid := range order {
+		cids := slices.Clone(chunkIDs[rid])
+		slices.SortFunc(cids, func(a, b string) int {
+			_, i := splitChunkID(a)
+			_, j := splitChunkID(b)
+			return i - j
+		})
+		var it IndexItem
+		for _, id := range cids {
+			v, ok := vectors[id]
+			if !ok {
+				continue
+			}
+			if it.Review.ReviewID == "" {
+				m := v.Metadata
+				it.Review.ReviewID = rid
+				it.Review.BusinessID, _ = m["business_id"].(string)
+				it.Review.Text, _ = m["text"].(string)
+				it.Review.Stars = metadataFloat32(m, "stars")
+				it.BusinessName, _ = m["business_name"].(string)
+				it.City, _ = m["city"].(string)
+				it.State, _ = m["state"].(string)
+				it.Categories, _ = m["categories"].(string)
+			}
+			text, _ := v.Metadata["chunk_text"].(string)
+			it.Chunks = append(it.Chunks, Chunk{Text: text, Vector: v.Values})
+		}
+		if it.Review.ReviewID != "" {
+			items = append(items, it)
+		}
+	}
+
+	if token == "" {
+		return items, "", nil
+	}
+	next, err := json.Marshal(pineconeReviewCursor{Token: token, Last: last})
+	if err != nil {
+		return nil, "", fmt.Errorf("encode cursor: %w", err)
+	}
+	return items, string(next), nil
+}
+
+// ExportFodmap implements FodmapExporter.
+func (c *PineconeClient) ExportFodmap(ctx context.Context, cursor string, limit int) ([]FodmapRecord, string, error) {
+	ids, next, err := c.listIDs(ctx, pineconeFodmapNamespace, "", cursor, min(limit, pineconeListLimit))
+	if err != nil || len(ids) == 0 {
+		return nil, next, err
+	}
+	vectors, err := c.fetchVectors(ctx, ids, pineconeFodmapNamespace)
+	if err != nil {
+		return nil, "", err
+	}
+	items := make([]FodmapRecord, 0, len(ids))
+	for _, id := range ids {
+		v, ok := vectors[id]
+		if !ok {
+			continue
+		}
+		name, _ := v.Metadata["ingredient"].(string)
+		if name == "" {
+			name = strings.TrimPrefix(id, "fodmap-")
+		}
+		level, _ := v.Metadata["level"].(string)
+		notes, _ := v.Metadata["notes"].(string)
+		items = append(items, FodmapRecord{
+			Name: name,
+			Entry: data.FodmapEntry{
+				Level:         level,
+				Groups:        stringSliceField(v.Metadata, "groups"),
+				Notes:         notes,
+				Substitutions: stringSliceField(v.Metadata, "substitutions"),
+			},
+			Vector: v.Values,
+		})
+	}
+	return items, next, nil
+}
+
+// ImportReviews implements ReviewImporter, upserting reviews in groups
+// small enough for one request each.
+func (c *PineconeClient) ImportReviews(ctx context.Context, items []IndexItem) error {
+	start, vectors := 0, 0
+	for i, it := range items {
+		n := max(len(it.Chunks), 1)
+		if vectors > 0 && vectors+n > pineconeUpsertBatch {
+			if err := c.BatchUpsert(ctx, items[start:i]); err != nil {
+				return err
+			}
+			start, vectors = i, 0
+		}
+		vectors += n
+	}
+	return c.BatchUpsert(ctx, items[start:])
+}
+
+// ImportFodmap implements FodmapImporter.
+func (c *PineconeClient) ImportFodmap(ctx context.Context, items []FodmapRecord) error {
+	var vectors []map[string]any
+	for _, it := range items {
+		groups := it.Entry.Groups
+		if groups == nil {
+			groups = []string{}
+		}
+		meta := map[string]any{
+			"ingredient": it.Name,
+			"level":      it.Entry.Level,
+			"groups":     groups,
+			"notes":      it.Entry.Notes,
+		}
+		if len(it.Entry.Substitutions) > 0 {
+			meta["substitutions"] = it.Entry.Substitutions
+		}
+		vectors = append(vectors, map[string]any{
+			"id":       fmt.Sprintf("fodmap-%s", it.Name),
+			"values":   it.Vector,
+			"metadata": meta,
+		})
+	}
+	for start := 0; start < len(vectors); start += pineconeUpsertBatch {
+		if err := c.doUpsert(ctx, vectors[start:min(start+pineconeUpsertBatch, len(vectors))], pineconeFodmapNamespace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CountCollection implements CollectionCounter from the index statistics,
+// which Pinecone updates a few seconds after writes.
+func (c *PineconeClient) CountCollection(ctx context.Context, collection string) (int64, error) {
+	namespace, ok := map[string]string{
+		CollectionReviews: pineconeReviewNamespace,
+		CollectionFodmap:  pineconeFodmapNamespace,
+	}[collection]
+	if !ok {
+		return 0, fmt.Errorf("pinecone backend does not store %s", collection)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.IndexHost+"/describe_index_stats", bytes.NewReader([]byte("{}")))
+	if err != nil {
+		return 0, fmt.Errorf("creating stats request: %w", err)
+	}
+	req.Header.Set("Api-Key", c.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("executing pinecone stats: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		out, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("pinecone stats error (status %d): %s", resp.StatusCode, string(out))
+	}
+	var res struct {
+		Namespaces map[string]struct {
+			VectorCount int64 `json:"vectorCount"`
+		} `json:"namespaces"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return 0, fmt.Errorf("decoding pinecone stats response: %w", err)
+	}
+	return res.Namespaces[namespace].VectorCount, nil
+}
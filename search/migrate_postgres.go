@@ -0,0 +1,393 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/pgvector/pgvector-go"
+)
+
+// Migration support for the Postgres backend. Collections are paged by
+// primary key, the cursor being the last key of the previous page. Vectors
+// are read from the active `embedding` column as text, which parses the same
+// whether the column is a vector or a halfvec.
+
+// ExportReviews implements ReviewExporter. Review.BusinessID is the Yelp
+// business ID from the restaurants row, as the other backends store it, and
+// BusinessUUID the restaurants.id.
+func (c *PostgresClient) ExportReviews(ctx context.Context, cursor string, limit int) ([]IndexItem, string, error) {
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT r.review_id, r.business_id, coalesce(g.yelp_id, r.business_id::text, ''), coalesce(r.business_name, ''),
+		       coalesce(r.city, ''), coalesce(r.state, ''), coalesce(r.categories, ''), coalesce(r.stars, 0), coalesce(r.text, ''),
+		       coalesce(r.updated_at, r.created_at)
+		FROM reviews r
+		LEFT JOIN restaurants g ON g.id = r.business_id
+		WHERE r.review_id > $1
+		ORDER BY r.review_id
+		LIMIT $2
+	`, cursor, limit)
+	if err != nil {
+		return nil, "", fmt.Errorf("query reviews: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []IndexItem
+	idx := make(map[string]int)
+	for rows.Next() {
+		var it IndexItem
+		var businessUUID uuid.NullUUID
+		var stars float64
+		var updated time.Time
+		if err := rows.Scan(&it.Review.ReviewID, &businessUUID, &it.Review.BusinessID, &it.BusinessName,
+			&it.City, &it.State, &it.Categories, &stars, &it.Review.Text, &updated); err != nil {
+			return nil, "", fmt.Errorf("scan review: %w", err)
+		}
+		it.Review.Stars = float32(stars)
+		it.UpdatedAt = &updated
+		if businessUUID.Valid {
+			it.BusinessUUID = &businessUUID.UUID
+		}
+		idx[it.Review.ReviewID] = len(items)
+		items = append(items, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("rows iteration: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, "", nil
+	}
+
+	ids := make([]string, len(items))
+	for i, it := range items {
+		ids[i] = it.Review.ReviewID
+	}
+	chunkRows, err := c.db.QueryContext(ctx, `
+		SELECT review_id, coalesce(chunk_text, ''), embedding::text
+		FROM review_chunks
+		WHERE review_id = ANY($1)
+		ORDER BY review_id, chunk_id
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, "", fmt.Errorf("query review chunks: %w", err)
+	}
+	defer func() { _ = chunkRows.Close() }()
+	for chunkRows.Next() {
+		var reviewID string
+		var ch Chunk
+		var vec sql.NullString
+		if err := chunkRows.Scan(&reviewID, &ch.Text, &vec); err != nil {
+			return nil, "", fmt.Errorf("scan review chunk: %w", err)
+		}
+		if ch.Vector, err = parseVectorText(vec); err != nil {
+			return nil, "", fmt.Errorf("chunk of review %q: %w", reviewID, err)
+		}
+		i := idx[reviewID]
+		items[i].Chunks = append(items[i].Chunks, ch)
+	}
+	if err := chunkRows.Err(); err != nil {
+		return nil, "", fmt.Errorf("rows iteration: %w", err)
+	}
+	return items, pageCursor(ids[len(ids)-1], len(items), limit), nil
+}
+
+// ExportFodmap implements FodmapExporter.
+func (c *PostgresClient) ExportFodmap(ctx context.Context, cursor string, limit int) ([]FodmapRecord, string, error) {
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT ingredient, coalesce(level, ''), groups, coalesce(notes, ''), substitutions, embedding::text
+		FROM fodmap_ingredients
+		WHERE ingredient > $1
+		ORDER BY ingredient
+		LIMIT $2
+	`, cursor, limit)
+	if err != nil {
+		return nil, "", fmt.Errorf("query fodmap ingredients: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []FodmapRecord
+	for rows.Next() {
+		var r FodmapRecord
+		var vec sql.NullString
+		if err := rows.Scan(&r.Name, &r.Entry.Level, (*pgxStringArray)(&r.Entry.Groups), &r.Entry.Notes,
+			(*pgxStringArray)(&r.Entry.Substitutions), &vec); err != nil {
+			return nil, "", fmt.Errorf("scan fodmap ingredient: %w", err)
+		}
+		if r.Vector, err = parseVectorText(vec); err != nil {
+			return nil, "", fmt.Errorf("fodmap ingredient %q: %w", r.Name, err)
+		}
+		items = append(items, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("rows iteration: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, "", nil
+	}
+	return items, pageCursor(items[len(items)-1].Name, len(items), limit), nil
+}
+
+// ExportMenu implements MenuExporter. Soft-deleted items are left behind,
+// and coordinates come from the restaurants row.
+func (c *PostgresClient) ExportMenu(ctx context.Context, cursor string, limit int) ([]MenuItem, string, error) {
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT m.menu_item_id, m.business_id, m.menu_section, m.restaurant_name, m.city, m.state, m.dish_name, m.description, m.price,
+		       m.stated_ingredients, m.has_full_ingredients, m.modifiers, m.source_url, m.address, m.phone_number, m.scraped_at,
+		       m.language, m.dish_name_en, m.description_en, m.stated_ingredients_en, r.latitude, r.longitude, m.embedding::text
+		FROM menu_items m
+		LEFT JOIN restaurants r ON r.id = m.business_id
+		WHERE m.removed_at IS NULL AND m.menu_item_id > $1
+		ORDER BY m.menu_item_id
+		LIMIT $2
+	`, cursor, limit)
+	if err != nil {
+		return nil, "", fmt.Errorf("query menu items: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []MenuItem
+	for rows.Next() {
+		var m MenuItem
+		var sec, restName, city, state, desc, sourceURL, address, phone, scrapedAt sql.NullString
+		var lang, dishEn, descEn, vec sql.NullString
+		var price, lat, lon sql.NullFloat64
+		var modifiersJSON []byte
+		if err := rows.Scan(&m.MenuItemID, &m.BusinessID, &sec, &restName, &city, &state, &m.DishName, &desc, &price, (*pgxStringArray)(&m.StatedIngredients), &m.HasFullIngredients, &modifiersJSON, &sourceURL, &address, &phone, &scrapedAt,
+			&lang, &dishEn, &descEn, (*pgxStringArray)(&m.StatedIngredientsEn), &lat, &lon, &vec); err != nil {
+			return nil, "", fmt.Errorf("scan menu item: %w", err)
+		}
+		m.Language, m.DishNameEn, m.DescriptionEn = lang.String, dishEn.String, descEn.String
+		m.MenuSection = sec.String
+		m.RestaurantName = restName.String
+		m.City = city.String
+		m.State = state.String
+		m.Description = desc.String
+		if price.Valid {
+			p := price.Float64
+			m.Price = &p
+		}
+		if len(modifiersJSON) > 0 && string(modifiersJSON) != "null" {
+			_ = json.Unmarshal(modifiersJSON, &m.Modifiers)
+		}
+		m.SourceURL = sourceURL.String
+		m.Address = address.String
+		m.PhoneNumber = phone.String
+		m.ScrapedAt = scrapedAt.String
+		if lat.Valid && lon.Valid {
+			m.Latitude, m.Longitude = &lat.Float64, &lon.Float64
+		}
+		if m.Vector, err = parseVectorText(vec); err != nil {
+			return nil, "", fmt.Errorf("menu item %q: %w", m.MenuItemID, err)
+		}
+		items = append(items, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("rows iteration: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, "", nil
+	}
+	return items, pageCursor(items[len(items)-1].MenuItemID, len(items), limit), nil
+}
+
+// ImportReviews implements ReviewImporter. reviews.business_id references
+// restaurants, so each review is linked to the restaurants row of its
+// BusinessUUID when that row exists here, else to the row whose yelp_id is
+// its Review.BusinessID, else to none.
+func (c *PostgresClient) ImportReviews(ctx context.Context, items []IndexItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	var uuids, yelpIDs []string
+	for _, it := range items {
+		if it.BusinessUUID != nil {
+			uuids = append(uuids, it.BusinessUUID.String())
+		}
+		if it.Review.BusinessID != "" {
+			yelpIDs = append(yelpIDs, it.Review.BusinessID)
+		}
+	}
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT id, coalesce(yelp_id, '') FROM restaurants WHERE id = ANY($1::uuid[]) OR yelp_id = ANY($2)
+	`, pq.Array(uuids), pq.Array(yelpIDs))
+	if err != nil {
+		return fmt.Errorf("resolve review businesses: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	known := make(map[uuid.UUID]bool)
+	byYelp := make(map[string]uuid.UUID)
+	for rows.Next() {
+		var id uuid.UUID
+		var yelpID string
+		if err := rows.Scan(&id, &yelpID); err != nil {
+			return fmt.Errorf("scan restaurant: %w", err)
+		}
+		known[id] = true
+		if yelpID != "" {
+			byYelp[yelpID] = id
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration: %w", err)
+	}
+
+	resolved := make([]IndexItem, len(items))
+	for i, it := range items {
+		if it.BusinessUUID == nil || !known[*it.BusinessUUID] {
+			it.BusinessUUID = nil
+			if id, ok := byYelp[it.Review.BusinessID]; ok {
+				it.BusinessUUID = &id
+			}
+		}
+		resolved[i] = it
+	}
+	return c.BatchUpsert(ctx, resolved)
+}
+
+// ImportFodmap implements FodmapImporter.
+func (c *PostgresClient) ImportFodmap(ctx context.Context, items []FodmapRecord) error {
+	if len(items) == 0 {
+		return nil
+	}
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO fodmap_ingredients (ingredient, level, groups, notes, substitutions, embedding)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (ingredient) DO UPDATE SET
+			level = EXCLUDED.level,
+			groups = EXCLUDED.groups,
+			notes = EXCLUDED.notes,
+			substitutions = EXCLUDED.substitutions,
+			embedding = EXCLUDED.embedding
+	`)
+	if err != nil {
+		return fmt.Errorf("prepare stmt: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for _, it := range items {
+		groups := it.Entry.Groups
+		if groups == nil {
+			groups = []string{}
+		}
+		subs := it.Entry.Substitutions
+		if subs == nil {
+			subs = []string{}
+		}
+		var vec any
+		if it.Vector != nil {
+			vec = pgvector.NewHalfVector(it.Vector)
+		}
+		if _, err := stmt.ExecContext(ctx, it.Name, it.Entry.Level, pq.Array(groups), it.Entry.Notes, pq.Array(subs), vec); err != nil {
+			return fmt.Errorf("insert fodmap %q: %w", it.Name, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ImportMenu implements MenuImporter. menu_items.business_id references
+// restaurants, so items of a business with no restaurants row here are
+// skipped; import the restaurants first to keep them.
+func (c *PostgresClient) ImportMenu(ctx context.Context, items []MenuItem) (int, error) {
+	if len(items) == 0 {
+		return 0, nil
+	}
+	ids := make([]string, len(items))
+	for i, it := range items {
+		ids[i] = it.BusinessID.String()
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM restaurants WHERE id = ANY($1::uuid[])`, pq.Array(ids))
+	if err != nil {
+		return 0, fmt.Errorf("resolve menu businesses: %w", err)
+	}
+	known := make(map[uuid.UUID]bool)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return 0, fmt.Errorf("scan restaurant: %w", err)
+		}
+		known[id] = true
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("rows iteration: %w", err)
+	}
+
+	var keep []MenuItem
+	for _, it := range items {
+		if known[it.BusinessID] {
+			keep = append(keep, it)
+		} else {
+			slog.Debug("migrate: skipping menu item without restaurant", "menu_item_id", it.MenuItemID, "business_id", it.BusinessID)
+		}
+	}
+	if len(keep) > 0 {
+		if err := upsertMenuItems(ctx, tx, keep); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+	return len(items) - len(keep), nil
+}
+
+// CountCollection implements CollectionCounter.
+func (c *PostgresClient) CountCollection(ctx context.Context, collection string) (int64, error) {
+	var q string
+	switch collection {
+	case CollectionReviews:
+		q = `SELECT count(*) FROM review_chunks`
+	case CollectionFodmap:
+		q = `SELECT count(*) FROM fodmap_ingredients`
+	case CollectionMenu:
+		q = `SELECT count(*) FROM menu_items WHERE removed_at IS NULL`
+	default:
+		return 0, fmt.Errorf("postgres backend does not store %s vectors", collection)
+	}
+	var n int64
+	if err := c.db.QueryRowContext(ctx, q).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count %s: %w", collection, err)
+	}
+	return n, nil
+}
+
+// parseVectorText parses a vector or halfvec in its text form; NULL is a
+// nil vector.
+func parseVectorText(s sql.NullString) ([]float32, error) {
+	if !s.Valid || s.String == "" {
+		return nil, nil
+	}
+	var v pgvector.HalfVector
+	if err := v.Parse(s.String); err != nil {
+		return nil, fmt.Errorf("parse vector: %w", err)
+	}
+	return v.Slice(), nil
+}
+
+// pageCursor is the cursor following a page of n rows ending at last that
+// was asked for limit rows: a short page is the last.
+func pageCursor(last string, n, limit int) string {
+	if n < limit {
+		return ""
+	}
+	return last
+}
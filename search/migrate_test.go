@@ -0,0 +1,318 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"fodmap/data"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+// seedMigrationSource fills a LocalClient with three reviews (one of two
+// chunks), two ingredients and a menu item.
+func seedMigrationSource(t *testing.T, c *LocalClient) {
+	t.Helper()
+	ctx := context.Background()
+	r1 := localReviewItem("r1", "b1", "Boston", "great pasta", 5)
+	r1.Chunks = append(r1.Chunks, Chunk{Text: "slow service", Vector: []float32{0, 1, 0}})
+	items := []IndexItem{r1,
+		localReviewItem("r2", "b1", "Boston", "garlic everywhere", 2),
+		localReviewItem("r3", "b2", "Cambridge", "lovely rice bowls", 4)}
+	if err := c.BatchUpsert(ctx, items); err != nil {
+		t.Fatalf("BatchUpsert: %v", err)
+	}
+	if err := c.BatchUpsertFodmap(ctx, map[string]data.FodmapEntry{
+		"garlic": {Level: "high", Groups: []string{"fructans"}},
+		"rice":   {Level: "low"},
+	}); err != nil {
+		t.Fatalf("BatchUpsertFodmap: %v", err)
+	}
+	if err := c.BatchUpsertMenu(ctx, []MenuItem{{MenuItemID: "m1", BusinessID: uuid.New(), DishName: "Risotto", Vector: []float32{1, 0, 0}}}); err != nil {
+		t.Fatalf("BatchUpsertMenu: %v", err)
+	}
+}
+
+func TestMigration_LocalToLocal(t *testing.T) {
+	ctx := context.Background()
+	src := newTestLocalClient(t, t.TempDir())
+	dst := newTestLocalClient(t, t.TempDir())
+	seedMigrationSource(t, src)
+
+	m := &Migration{From: src, To: dst, FromName: "local:a", ToName: "local:b", BatchSize: 2,
+		Checkpoint: filepath.Join(t.TempDir(), "migrate.checkpoint")}
+	results, err := m.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	want := map[string]MigrationResult{
+		CollectionReviews:    {Collection: CollectionReviews, Copied: 3, Source: 4, Destination: 4, Verified: true},
+		CollectionFodmap:     {Collection: CollectionFodmap, Copied: 2, Source: 2, Destination: 2, Verified: true},
+		CollectionMenu:       {Collection: CollectionMenu, Copied: 1, Source: 1, Destination: 1, Verified: true},
+		CollectionRegulatory: {Collection: CollectionRegulatory, Unsupported: "the source backend does not store it"},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d: %+v", len(results), len(want), results)
+	}
+	for _, r := range results {
+		if r != want[r.Collection] {
+			t.Errorf("%s: got %+v, want %+v", r.Collection, r, want[r.Collection])
+		}
+	}
+
+	got, _, _ := dst.ExportReviews(ctx, "", 10)
+	if len(got) != 3 || len(got[0].Chunks) != 2 || got[0].Chunks[1].Text != "slow service" {
+		t.Errorf("reviews not copied with their chunks in order: %+v", got)
+	}
+	fodmap, _, _ := dst.ExportFodmap(ctx, "", 10)
+	if len(fodmap) != 2 || fodmap[0].Name != "garlic" || !reflect.DeepEqual(fodmap[0].Entry.Groups, []string{"fructans"}) || len(fodmap[0].Vector) == 0 {
+		t.Errorf("fodmap not copied with vectors: %+v", fodmap)
+	}
+}
+
+// failingImporter fails its second FODMAP import, standing in for a
+// migration that is interrupted.
+type failingImporter struct {
+	*LocalClient
+	calls int
+}
+
+func (f *failingImporter) ImportFodmap(ctx context.Context, items []FodmapRecord) error {
+	if f.calls++; f.calls == 2 {
+		return errors.New("connection reset")
+	}
+	return f.LocalClient.ImportFodmap(ctx, items)
+}
+
+func TestMigration_ResumesFromCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	src := newTestLocalClient(t, t.TempDir())
+	dst := newTestLocalClient(t, t.TempDir())
+	seedMigrationSource(t, src)
+	cp := filepath.Join(t.TempDir(), "migrate.checkpoint")
+
+	m := &Migration{From: src, To: &failingImporter{LocalClient: dst}, FromName: "local:a", ToName: "local:b",
+		Collections: []string{CollectionReviews, CollectionFodmap}, BatchSize: 1, Checkpoint: cp}
+	if _, err := m.Run(ctx); err == nil || !strings.Contains(err.Error(), "connection reset") {
+		t.Fatalf("Run: got %v, want the import error", err)
+	}
+
+	// The reviews were finished and one ingredient copied before the
+	// failure; the re-run copies only the other ingredient.
+	m.To = dst
+	results, err := m.Run(ctx)
+	if err != nil {
+		t.Fatalf("resumed Run: %v", err)
+	}
+	for _, r := range results {
+		if !r.Verified {
+			t.Errorf("%s not verified after resume: %+v", r.Collection, r)
+		}
+	}
+	if results[1].Copied != 2 {
+		t.Errorf("fodmap copied = %d over both runs, want 2", results[1].Copied)
+	}
+
+	other := &Migration{From: src, To: dst, FromName: "local:a", ToName: "local:c", Checkpoint: cp}
+	if _, err := other.Run(ctx); err == nil || !strings.Contains(err.Error(), "is for a migration from local:a to local:b") {
+		t.Errorf("checkpoint of another migration: got %v", err)
+	}
+}
+
+func TestMigration_UnsupportedCollectionNamed(t *testing.T) {
+	src := newTestLocalClient(t, t.TempDir())
+	dst := newTestLocalClient(t, t.TempDir())
+	m := &Migration{From: src, To: dst, FromName: "local:a", ToName: "local:b", Collections: []string{CollectionRegulatory}}
+	if _, err := m.Run(context.Background()); err == nil || !strings.Contains(err.Error(), "cannot migrate regulatory") {
+		t.Errorf("got %v, want an unsupported collection error", err)
+	}
+	m.Collections = []string{"dishes"}
+	if _, err := m.Run(context.Background()); err == nil || !strings.Contains(err.Error(), "unknown collection") {
+		t.Errorf("got %v, want an unknown collection error", err)
+	}
+}
+
+func TestPostgresClient_ExportFodmap(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	client := &PostgresClient{db: db}
+
+	mock.ExpectQuery(`SELECT ingredient, .*embedding::text\s+FROM fodmap_ingredients\s+WHERE ingredient > \$1`).
+		WithArgs("", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"ingredient", "level", "groups", "notes", "substitutions", "embedding"}).
+			AddRow("apple", "high", "{fructose}", "", "{}", "[0.5,0.25]").
+			AddRow("rice", "low", "{}", "", "{}", nil))
+
+	items, next, err := client.ExportFodmap(context.Background(), "", 2)
+	if err != nil {
+		t.Fatalf("ExportFodmap: %v", err)
+	}
+	if next != "rice" {
+		t.Errorf("next = %q, want rice (a full page may have more after it)", next)
+	}
+	if len(items) != 2 || !reflect.DeepEqual(items[0].Vector, []float32{0.5, 0.25}) || items[1].Vector != nil ||
+		!reflect.DeepEqual(items[0].Entry.Groups, []string{"fructose"}) {
+		t.Errorf("items = %+v", items)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestPostgresClient_ImportMenuSkipsUnknownRestaurants(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	client := &PostgresClient{db: db}
+
+	known, unknown := uuid.New(), uuid.New()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM restaurants WHERE id = ANY`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(known))
+	mock.ExpectPrepare(`INSERT INTO menu_items`)
+	mock.ExpectExec(`INSERT INTO menu_items`).
+		WithArgs(sqlmock.AnyArg(), known, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "Risotto",
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	skipped, err := client.ImportMenu(context.Background(), []MenuItem{
+		{MenuItemID: "m1", BusinessID: known, DishName: "Risotto", Vector: []float32{1, 0}},
+		{MenuItemID: "m2", BusinessID: unknown, DishName: "Ramen", Vector: []float32{0, 1}},
+	})
+	if err != nil {
+		t.Fatalf("ImportMenu: %v", err)
+	}
+	if skipped != 1 {
+		t.Errorf("skipped = %d, want 1", skipped)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
+
+func TestMigration_RefusesEmbeddingSpaceMismatch(t *testing.T) {
+	ctx := context.Background()
+	src := newTestLocalClient(t, t.TempDir())
+	seedMigrationSource(t, src)
+
+	cases := []struct {
+		name      string
+		fromModel string
+		model     string
+		dim       int
+	}{
+		// The seeded vectors have 3 dimensions.
+		{"dimension", "", "nomic-embed-text", 768},
+		{"model", "all-minilm", "nomic-embed-text", 3},
+	}
+	for _, tc := range cases {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("failed to open sqlmock: %v", err)
+		}
+		dst := &PostgresClient{db: db}
+		mock.ExpectQuery(`FROM embedding_spaces WHERE collection = \$1 AND status = 'active'`).
+			WithArgs(CollectionMenu).
+			WillReturnRows(sqlmock.NewRows(spaceRowColumns).
+				AddRow(1, CollectionMenu, tc.model, tc.dim, []byte(`{}`), SpaceActive, "embedding", time.Now(), nil, nil))
+
+		m := &Migration{From: src, To: dst, FromName: "local", ToName: "postgres",
+			Collections: []string{CollectionMenu}, FromModel: tc.fromModel}
+		if _, err := m.Run(ctx); err == nil {
+			t.Errorf("%s mismatch: Run wrote the source's vectors", tc.name)
+		}
+		// Nothing was written: no unexpected INSERT reached the mock.
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%s mismatch: %v", tc.name, err)
+		}
+		_ = db.Close()
+	}
+}
+
+func TestPineconeClient_ExportReviewsAcrossPages(t *testing.T) {
+	// r1's chunks straddle the first page boundary; r2 is on the second
+	// page only.
+	pages := map[string]struct {
+		ids  []string
+		next string
+	}{
+		"":   {ids: []string{"r1_chunk_0"}, next: "p2"},
+		"p2": {ids: []string{"r1_chunk_1", "r2_chunk_0"}},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/vectors/list":
+			if prefix := q.Get("prefix"); prefix != "" {
+				_ = json.NewEncoder(w).Encode(map[string]any{"vectors": []map[string]string{
+					{"id": strings.TrimSuffix(prefix, "_") + "_0"}, {"id": strings.TrimSuffix(prefix, "_") + "_1"}}})
+				return
+			}
+			p := pages[q.Get("paginationToken")]
+			var vectors []map[string]string
+			for _, id := range p.ids {
+				vectors = append(vectors, map[string]string{"id": id})
+			}
+			res := map[string]any{"vectors": vectors}
+			if p.next != "" {
+				res["pagination"] = map[string]string{"next": p.next}
+			}
+			_ = json.NewEncoder(w).Encode(res)
+		case "/vectors/fetch":
+			vectors := make(map[string]any)
+			for _, id := range q["ids"] {
+				rid, i := splitChunkID(id)
+				vectors[id] = map[string]any{
+					"values":   []float32{float32(i), 1},
+					"metadata": map[string]any{"review_id": rid, "business_id": "b-" + rid, "stars": 4.0, "text": "full " + rid, "chunk_text": id},
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"vectors": vectors})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	client := NewPineconeClient("test-key", srv.URL, nil)
+	ctx := context.Background()
+
+	page1, cursor, err := client.ExportReviews(ctx, "", 1)
+	if err != nil {
+		t.Fatalf("ExportReviews: %v", err)
+	}
+	if len(page1) != 1 || page1[0].Review.ReviewID != "r1" || len(page1[0].Chunks) != 2 ||
+		page1[0].Chunks[1].Text != "r1_chunk_1" || page1[0].Review.Stars != 4 {
+		t.Fatalf("page 1 = %+v, want r1 with both chunks", page1)
+	}
+	if cursor == "" {
+		t.Fatal("cursor is empty after page 1")
+	}
+
+	page2, cursor, err := client.ExportReviews(ctx, cursor, 1)
+	if err != nil {
+		t.Fatalf("ExportReviews page 2: %v", err)
+	}
+	if len(page2) != 1 || page2[0].Review.ReviewID != "r2" || len(page2[0].Chunks) != 1 {
+		t.Errorf("page 2 = %+v, want r2 alone", page2)
+	}
+	if cursor != "" {
+		t.Errorf("cursor after the last page = %q, want empty", cursor)
+	}
+}
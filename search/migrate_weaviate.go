@@ -0,0 +1,305 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/google/uuid"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate/fault"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate/filters"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate/graphql"
+	"github.com/weaviate/weaviate/entities/models"
+)
+
+// Migration support for the Weaviate backend. Collections are paged with
+// Weaviate's object cursor, the cursor being the ID of the last object of
+// the previous page.
+
+// weaviateChunkQueryLimit caps the chunks fetched for one page of reviews,
+// Weaviate's default QUERY_MAXIMUM_RESULTS.
+const weaviateChunkQueryLimit = 10000
+
+// listObjects reads a page of class's objects with their vectors.
+func (c *Client) listObjects(ctx context.Context, class, cursor string, limit int) ([]*models.Object, string, error) {
+	objs, err := c.wv.Data().ObjectsGetter().
+		WithClassName(class).
+		WithAfter(cursor).
+		WithLimit(limit).
+		WithVector().
+		Do(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("list %s objects: %w", class, err)
+	}
+	if len(objs) == 0 {
+		return nil, "", nil
+	}
+	return objs, pageCursor(objs[len(objs)-1].ID.String(), len(objs), limit), nil
+}
+
+// objectProps returns an object's properties as a map.
+func objectProps(o *models.Object) map[string]any {
+	m, _ := o.Properties.(map[string]any)
+	return m
+}
+
+// ExportReviews implements ReviewExporter. Chunks are matched to their
+// review by the deterministic IDs BatchUpsert gives them, which also
+// restores their order.
+func (c *Client) ExportReviews(ctx context.Context, cursor string, limit int) ([]IndexItem, string, error) {
+	objs, next, err := c.listObjects(ctx, collectionName, cursor, limit)
+	if err != nil || len(objs) == 0 {
+		return nil, next, err
+	}
+	items := make([]IndexItem, len(objs))
+	idOperands := make([]*filters.WhereBuilder, len(objs))
+	for i, o := range objs {
+		p := objectProps(o)
+		items[i] = IndexItem{
+			BusinessName: stringField(p, "businessName"),
+			City:         stringField(p, "city"),
+			State:        stringField(p, "state"),
+			Categories:   stringField(p, "categories"),
+		}
+		items[i].Review.ReviewID = stringField(p, "reviewId")
+		items[i].Review.BusinessID = stringField(p, "businessId")
+		items[i].Review.Text = stringField(p, "text")
+		if stars := float64Field(p, "stars"); stars != nil {
+			items[i].Review.Stars = float32(*stars)
+		}
+		idOperands[i] = filters.Where().
+			WithPath([]string{"hasParent", collectionName, "reviewId"}).
+			WithOperator(filters.Equal).
+			WithValueText(items[i].Review.ReviewID)
+	}
+
+	resp, err := c.wv.GraphQL().Get().
+		WithClassName(chunkCollectionName).
+		WithFields(graphql.Field{Name: "chunkText"}, graphql.Field{Name: "_additional { id vector }"}).
+		WithWhere(filters.Where().WithOperator(filters.Or).WithOperands(idOperands)).
+		WithLimit(weaviateChunkQueryLimit).
+		Do(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("graphql query: %w", err)
+	}
+	if resp.Errors != nil {
+		return nil, "", fmt.Errorf("graphql errors: %s", formatGraphQLErrors(resp.Errors))
+	}
+	getMap, _ := resp.Data["Get"].(map[string]any)
+	rawChunks, _ := getMap[chunkCollectionName].([]any)
+	if len(rawChunks) >= weaviateChunkQueryLimit {
+		return nil, "", fmt.Errorf("%d reviews have more than %d chunks; use a smaller batch size", len(items), weaviateChunkQueryLimit)
+	}
+	chunks := make(map[string]Chunk, len(rawChunks))
+	for _, rc := range rawChunks {
+		m, ok := rc.(map[string]any)
+		if !ok {
+			continue
+		}
+		add, _ := m["_additional"].(map[string]any)
+		id := stringField(add, "id")
+		chunks[id] = Chunk{Text: stringField(m, "chunkText"), Vector: float32SliceField(add, "vector")}
+	}
+	for i := range items {
+		rid := items[i].Review.ReviewID
+		for j := 0; ; j++ {
+			ch, ok := chunks[uuid.NewSHA1(uuid.NameSpaceOID, []byte(fmt.Sprintf("%s_chunk_%d", rid, j))).String()]
+			if !ok {
+				break
+			}
+			items[i].Chunks = append(items[i].Chunks, ch)
+		}
+	}
+	return items, next, nil
+}
+
+// ExportFodmap implements FodmapExporter.
+func (c *Client) ExportFodmap(ctx context.Context, cursor string, limit int) ([]FodmapRecord, string, error) {
+	objs, next, err := c.listObjects(ctx, fodmapCollectionName, cursor, limit)
+	if err != nil || len(objs) == 0 {
+		return nil, next, err
+	}
+	items := make([]FodmapRecord, len(objs))
+	for i, o := range objs {
+		p := objectProps(o)
+		items[i] = FodmapRecord{Name: stringField(p, "ingredient"), Vector: o.Vector}
+		items[i].Entry.Level = stringField(p, "level")
+		items[i].Entry.Groups = stringSliceField(p, "groups")
+		items[i].Entry.Notes = stringField(p, "notes")
+		items[i].Entry.Substitutions = stringSliceField(p, "substitutions")
+	}
+	return items, next, nil
+}
+
+// ExportMenu implements MenuExporter. Modifiers come back as Weaviate keeps
+// them, names with their price written in.
+func (c *Client) ExportMenu(ctx context.Context, cursor string, limit int) ([]MenuItem, string, error) {
+	objs, next, err := c.listObjects(ctx, menuCollectionName, cursor, limit)
+	if err != nil || len(objs) == 0 {
+		return nil, next, err
+	}
+	items := make([]MenuItem, len(objs))
+	for i, o := range objs {
+		items[i] = weaviateMenuItem(objectProps(o))
+		items[i].Vector = o.Vector
+	}
+	return items, next, nil
+}
+
+// ExportRegulatory implements RegulatoryExporter. ID is the object's ID,
+// which ImportRegulatory keeps.
+func (c *Client) ExportRegulatory(ctx context.Context, cursor string, limit int) ([]RegulatoryUpdate, string, error) {
+	objs, next, err := c.listObjects(ctx, regulatoryCollectionName, cursor, limit)
+	if err != nil || len(objs) == 0 {
+		return nil, next, err
+	}
+	items := make([]RegulatoryUpdate, len(objs))
+	for i, o := range objs {
+		p := objectProps(o)
+		items[i] = RegulatoryUpdate{
+			ID:            o.ID.String(),
+			SourceID:      stringField(p, "sourceId"),
+			SourceURL:     stringField(p, "sourceUrl"),
+			CASNumber:     stringField(p, "casNumber"),
+			SubstanceName: stringField(p, "substanceName"),
+			ChangeType:    stringField(p, "changeType"),
+			Description:   stringField(p, "description"),
+			EffectiveDate: stringField(p, "effectiveDate"),
+			Vector:        o.Vector,
+		}
+	}
+	return items, next, nil
+}
+
+// ImportFodmap implements FodmapImporter.
+func (c *Client) ImportFodmap(ctx context.Context, items []FodmapRecord) error {
+	if len(items) == 0 {
+		return nil
+	}
+	batcher := c.wv.Batch().ObjectsBatcher()
+	for _, it := range items {
+		groups := it.Entry.Groups
+		if groups == nil {
+			groups = []string{}
+		}
+		subs := it.Entry.Substitutions
+		if subs == nil {
+			subs = []string{}
+		}
+		batcher = batcher.WithObjects(&models.Object{
+			Class:  fodmapCollectionName,
+			ID:     strfmt.UUID(uuid.NewSHA1(uuid.NameSpaceOID, []byte("fodmap_"+it.Name)).String()),
+			Vector: models.C11yVector(it.Vector),
+			Properties: map[string]any{
+				"ingredient":    it.Name,
+				"level":         it.Entry.Level,
+				"groups":        groups,
+				"notes":         it.Entry.Notes,
+				"substitutions": subs,
+			},
+		})
+	}
+	return doObjectsBatch(ctx, batcher.Do, "fodmap")
+}
+
+// ImportRegulatory implements RegulatoryImporter. Items exported from
+// Weaviate keep their object IDs; any other ID is hashed as
+// BatchUpsertRegulatory does.
+func (c *Client) ImportRegulatory(ctx context.Context, items []RegulatoryUpdate) error {
+	if len(items) == 0 {
+		return nil
+	}
+	batcher := c.wv.Batch().ObjectsBatcher()
+	for _, it := range items {
+		id := it.ID
+		if _, err := uuid.Parse(id); err != nil {
+			id = uuid.NewSHA1(regulatoryUpdateNS, []byte(it.ID)).String()
+		}
+		batcher = batcher.WithObjects(&models.Object{
+			Class:  regulatoryCollectionName,
+			ID:     strfmt.UUID(id),
+			Vector: models.C11yVector(it.Vector),
+			Properties: map[string]any{
+				"sourceId":      it.SourceID,
+				"sourceUrl":     it.SourceURL,
+				"casNumber":     it.CASNumber,
+				"substanceName": it.SubstanceName,
+				"changeType":    it.ChangeType,
+				"description":   it.Description,
+				"effectiveDate": it.EffectiveDate,
+			},
+		})
+	}
+	return doObjectsBatch(ctx, batcher.Do, "regulatory")
+}
+
+// doObjectsBatch runs a batch write, logging the objects it rejected.
+func doObjectsBatch(ctx context.Context, do func(context.Context) ([]models.ObjectsGetResponse, error), what string) error {
+	responses, err := do(ctx)
+	if err != nil {
+		var wErr *fault.WeaviateClientError
+		if errors.As(err, &wErr) && wErr.DerivedFromError != nil {
+			return fmt.Errorf("batch import %s: %w", what, wErr.DerivedFromError)
+		}
+		return fmt.Errorf("batch import %s: %w", what, err)
+	}
+	for _, resp := range responses {
+		if resp.Result != nil && resp.Result.Errors != nil {
+			slog.Warn("batch import error", "collection", what, "errors", resp.Result.Errors)
+		}
+	}
+	return nil
+}
+
+// CountCollection implements CollectionCounter.
+func (c *Client) CountCollection(ctx context.Context, collection string) (int64, error) {
+	class, ok := map[string]string{
+		CollectionReviews:    chunkCollectionName,
+		CollectionFodmap:     fodmapCollectionName,
+		CollectionMenu:       menuCollectionName,
+		CollectionRegulatory: regulatoryCollectionName,
+	}[collection]
+	if !ok {
+		return 0, fmt.Errorf("weaviate backend does not store %s", collection)
+	}
+	resp, err := c.wv.GraphQL().Aggregate().
+		WithClassName(class).
+		WithFields(graphql.Field{Name: "meta", Fields: []graphql.Field{{Name: "count"}}}).
+		Do(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("aggregate %s: %w", class, err)
+	}
+	if resp.Errors != nil {
+		return 0, fmt.Errorf("aggregate %s: %s", class, formatGraphQLErrors(resp.Errors))
+	}
+	agg, _ := resp.Data["Aggregate"].(map[string]any)
+	rows, _ := agg[class].([]any)
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	row, _ := rows[0].(map[string]any)
+	meta, _ := row["meta"].(map[string]any)
+	n := float64Field(meta, "count")
+	if n == nil {
+		return 0, fmt.Errorf("aggregate %s: no count in response", class)
+	}
+	return int64(*n), nil
+}
+
+// float32SliceField reads a number[] value, such as an _additional vector,
+// from a Weaviate GraphQL result.
+func float32SliceField(m map[string]any, key string) []float32 {
+	raw, ok := m[key].([]any)
+	if !ok {
+		return nil
+	}
+	out := make([]float32, 0, len(raw))
+	for _, v := range raw {
+		if f, ok := v.(float64); ok {
+			out = append(out, float32(f))
+		}
+	}
+	return out
+}
@@ -4,10 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,12 +27,22 @@ const (
 	pineconeFodmapNamespace = "fodmap-ingredients"
 )
 
+// DefaultPineconeBM25StatsPath is where the index and serve commands keep the
+// Pinecone review corpus statistics by default.
+const DefaultPineconeBM25StatsPath = "data/bm25/pinecone-reviews.json"
+
 // PineconeClient implements Searcher for Pinecone.
 type PineconeClient struct {
 	APIKey    string
 	IndexHost string
 	embedder  Embedder
 	client    *http.Client
+
+	// statsPath is where the review corpus statistics are persisted; empty
+	// until LoadCorpusStats. Pinecone has no place to keep them itself.
+	statsPath string
+	statsMu   sync.RWMutex
+	stats     *CorpusStats // replaced, never mutated, so readers need no lock past the load
 }
 
 // NewPineconeClient creates a new PineconeClient.
@@ -48,6 +64,41 @@ type PineconeQueryResponse struct {
 	} `json:"matches"`
 }
 
+// LoadCorpusStats reads the review corpus statistics used for hybrid BM25
+// blending from path, and makes BatchUpsert keep them up to date there. A
+// missing file starts an empty corpus: reviews indexed before the file existed
+// are scored without term rarity until they are re-indexed. Stats written by
+// another process (the index command) are picked up on the next load.
+func (c *PineconeClient) LoadCorpusStats(path string) error {
+	stats := &CorpusStats{}
+	raw, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("read corpus stats: %w", err)
+	default:
+		if err := json.Unmarshal(raw, stats); err != nil {
+			return fmt.Errorf("parse corpus stats %s: %w", path, err)
+		}
+	}
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	c.statsPath = path
+	c.stats = stats
+	return nil
+}
+
+// corpusStats returns the loaded statistics, or nil when scoring should fall
+// back to uniform term weights.
+func (c *PineconeClient) corpusStats() *CorpusStats {
+	c.statsMu.RLock()
+	defer c.statsMu.RUnlock()
+	if c.stats == nil || c.stats.Docs <= 0 {
+		return nil
+	}
+	return c.stats
+}
+
 // EnsureSchema is a no-op for Pinecone as it uses implicit namespaces.
 func (c *PineconeClient) EnsureSchema(ctx context.Context) error {
 	return nil
@@ -60,6 +111,12 @@ func (c *PineconeClient) EnsureFodmapSchema(ctx context.Context) error {
 
 // Businesses performs an aggregation-like search by querying reviews and grouping by business.
 func (c *PineconeClient) Businesses(ctx context.Context, query string, limit int, filter SearchFilter) (SearchResult, error) {
+	if filter.Geo != nil {
+		return SearchResult{}, ErrGeoFilterUnsupported
+	}
+	if filter.Retrieval == RetrieveLexical {
+		return SearchResult{}, ErrRetrievalUnsupported
+	}
 	vec, err := c.embedder.EmbedSingle(ctx, query)
 	if err != nil {
 		return SearchResult{}, fmt.Errorf("vectorizing query: %w", err)
@@ -121,6 +178,17 @@ func (c *PineconeClient) Businesses(ctx context.Context, query string, limit int
 
 // Reviews retrieves top reviews for a query, filtered by business if specified.
 func (c *PineconeClient) Reviews(ctx context.Context, query string, limit int, filter SearchFilter) (SearchReviews, error) {
+	if filter.Geo != nil {
+		return SearchReviews{}, ErrGeoFilterUnsupported
+	}
+	// The index holds dense vectors only; keyword relevance exists only as
+	// the BM25 re-score of dense matches, which a dense retrieval skips.
+	switch filter.Retrieval {
+	case RetrieveLexical:
+		return SearchReviews{}, ErrRetrievalUnsupported
+	case RetrieveDense:
+		filter.Alpha = 0
+	}
 	vec, err := c.embedder.EmbedSingle(ctx, query)
 	if err != nil {
 		return SearchReviews{}, fmt.Errorf("vectorizing query: %w", err)
@@ -147,6 +215,7 @@ func (c *PineconeClient) Reviews(ctx context.Context, query string, limit int, f
 
 	var reviews []RankedReview
 	seen := make(map[string]bool)
+	stats := c.corpusStats()
 
 	for _, m := range res.Matches {
 		reviewID, _ := m.Metadata["review_id"].(string)
@@ -158,8 +227,13 @@ func (c *PineconeClient) Reviews(ctx context.Context, query string, limit int, f
 		text, _ := m.Metadata["text"].(string)
 		chunkText, _ := m.Metadata["chunk_text"].(string)
 
-		// blendScore uses the full text for BM25
-		score := blendScore(query, text, m.Score, filter.Alpha)
+		// BM25 scores the matched chunk, the unit the corpus statistics
+		// count; vectors indexed without chunks carry the full text.
+		scored := chunkText
+		if scored == "" {
+			scored = text
+		}
+		score := blendScore(query, scored, m.Score, filter.Alpha, stats)
 		reviews = append(reviews, RankedReview{
 			Score:        score,
 			MatchedChunk: chunkText,
@@ -177,7 +251,7 @@ func (c *PineconeClient) Reviews(ctx context.Context, query string, limit int, f
 	}
 
 	// Re-sort by blended score when hybrid is active.
-	if filter.Alpha > 0 && filter.Alpha < 1 {
+	if hybridActive(filter.Alpha) {
 		sort.Slice(reviews, func(i, j int) bool { return reviews[i].Score > reviews[j].Score })
 	}
 
@@ -243,6 +317,7 @@ func (c *PineconeClient) BatchUpsert(ctx context.Context, items []IndexItem) err
 		return nil
 	}
 	var pineconeVectors []map[string]any
+	var delta CorpusStats
 	for _, item := range items {
 		chunksToProcess := item.Chunks
 		if len(chunksToProcess) == 0 && item.Vector != nil {
@@ -266,9 +341,61 @@ func (c *PineconeClient) BatchUpsert(ctx context.Context, items []IndexItem) err
 					"chunk_text":    chunk.Text,
 				},
 			})
+			delta.Add(chunk.Text)
 		}
 	}
-	return c.doUpsert(ctx, pineconeVectors, pineconeReviewNamespace)
+
+	c.statsMu.RLock()
+	tracking := c.statsPath != ""
+	c.statsMu.RUnlock()
+	if !tracking {
+		return c.doUpsert(ctx, pineconeVectors, pineconeReviewNamespace)
+	}
+
+	// Chunks being overwritten leave the statistics before their
+	// replacements enter them, so re-indexing a review does not count it twice.
+	ids := make([]string, len(pineconeVectors))
+	for i, v := range pineconeVectors {
+		ids[i] = v["id"].(string)
+	}
+	previous, err := c.fetchChunkTexts(ctx, ids, pineconeReviewNamespace)
+	if err != nil {
+		return err
+	}
+	for _, text := range previous {
+		delta.Remove(text)
+	}
+	if err := c.doUpsert(ctx, pineconeVectors, pineconeReviewNamespace); err != nil {
+		return err
+	}
+	return c.updateCorpusStats(delta)
+}
+
+// updateCorpusStats applies delta to the loaded statistics and persists them.
+func (c *PineconeClient) updateCorpusStats(delta CorpusStats) error {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	next := &CorpusStats{}
+	if c.stats != nil {
+		next.Merge(*c.stats)
+	}
+	next.Merge(delta)
+	raw, err := json.Marshal(next)
+	if err != nil {
+		return fmt.Errorf("marshal corpus stats: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.statsPath), 0o755); err != nil {
+		return fmt.Errorf("create corpus stats dir: %w", err)
+	}
+	tmp := c.statsPath + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return fmt.Errorf("write corpus stats: %w", err)
+	}
+	if err := os.Rename(tmp, c.statsPath); err != nil {
+		return fmt.Errorf("write corpus stats: %w", err)
+	}
+	c.stats = next
+	return nil
 }
 
 // BatchUpsertFodmap upserts a batch of FODMAP entries into the Pinecone fodmap namespace.
@@ -410,6 +537,66 @@ func (c *PineconeClient) doQuery(ctx context.Context, payload map[string]any) (P
 	return res, nil
 }
 
+// pineconeFetchBatch bounds the IDs per fetch request, keeping the query
+// string well under URL length limits.
+const pineconeFetchBatch = 100
+
+// fetchChunkTexts returns the chunk_text metadata of those ids that already
+// exist in namespace.
+func (c *PineconeClient) fetchChunkTexts(ctx context.Context, ids []string, namespace string) ([]string, error) {
+	vectors, err := c.fetchVectors(ctx, ids, namespace)
+	if err != nil {
+		return nil, err
+	}
+	var texts []string
+	for _, v := range vectors {
+		text, _ := v.Metadata["chunk_text"].(string)
+		texts = append(texts, text)
+	}
+	return texts, nil
+}
+
+// pineconeVector is a stored vector as the fetch endpoint returns it.
+type pineconeVector struct {
+	Values   []float32      `json:"values"`
+	Metadata map[string]any `json:"metadata"`
+}
+
+// fetchVectors returns those of ids that exist in namespace, by ID.
+func (c *PineconeClient) fetchVectors(ctx context.Context, ids []string, namespace string) (map[string]pineconeVector, error) {
+	out := make(map[string]pineconeVector, len(ids))
+	for start := 0; start < len(ids); start += pineconeFetchBatch {
+		q := url.Values{"namespace": {namespace}, "ids": ids[start:min(start+pineconeFetchBatch, len(ids))]}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.IndexHost+"/vectors/fetch?"+q.Encode(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("creating fetch request: %w", err)
+		}
+		req.Header.Set("Api-Key", c.APIKey)
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("executing pinecone fetch: %w", err)
+		}
+		var res struct {
+			Vectors map[string]pineconeVector `json:"vectors"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			out, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("pinecone fetch error (status %d): %s", resp.StatusCode, string(out))
+		}
+		err = json.NewDecoder(resp.Body).Decode(&res)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decoding pinecone fetch response: %w", err)
+		}
+		for id, v := range res.Vectors {
+			out[id] = v
+		}
+	}
+	return out, nil
+}
+
 func (c *PineconeClient) doUpsert(ctx context.Context, vectors []map[string]any, namespace string) error {
 	body, err := json.Marshal(map[string]any{"vectors": vectors, "namespace": namespace})
 	if err != nil {
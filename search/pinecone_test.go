@@ -3,9 +3,11 @@ package search
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 
 	"github.com/google/uuid"
@@ -274,6 +276,22 @@ func TestPineconeClient_Reviews_NoMatches(t *testing.T) {
 	}
 }
 
+func TestPineconeClient_LexicalUnsupported(t *testing.T) {
+	pineServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	}))
+	defer pineServer.Close()
+
+	client := NewPineconeClient("test-key", pineServer.URL, mockVecEmbedder())
+	filter := SearchFilter{Retrieval: RetrieveLexical}
+	if _, err := client.Reviews(context.Background(), "xyz", 10, filter); !errors.Is(err, ErrRetrievalUnsupported) {
+		t.Errorf("Reviews err = %v, want ErrRetrievalUnsupported", err)
+	}
+	if _, err := client.Businesses(context.Background(), "xyz", 10, filter); !errors.Is(err, ErrRetrievalUnsupported) {
+		t.Errorf("Businesses err = %v, want ErrRetrievalUnsupported", err)
+	}
+}
+
 // TestPineconeClient_Reviews_HybridBlend verifies that when Alpha<1, returned scores
 // are blended between the dense score and BM25 keyword score.
 // Match A: high dense score (0.95) but text doesn't match query.
@@ -571,6 +589,56 @@ func TestPineconeClient_BatchUpsert(t *testing.T) {
 	}
 }
 
+func TestPineconeClient_BatchUpsert_CorpusStats(t *testing.T) {
+	pineServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/vectors/fetch":
+			// r1 was indexed before with a different chunk; r2 is new.
+			if got := r.URL.Query()["ids"]; len(got) != 2 {
+				t.Errorf("fetch ids = %v, want both chunk IDs", got)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"vectors": map[string]any{
+				"r1_chunk_0": map[string]any{"metadata": map[string]any{"chunk_text": "old pasta review"}},
+			}})
+		case "/vectors/upsert":
+			_ = json.NewEncoder(w).Encode(map[string]any{"upsertedCount": 2})
+		}
+	}))
+	defer pineServer.Close()
+
+	path := filepath.Join(t.TempDir(), "bm25", "reviews.json")
+	client := NewPineconeClient("test-key", pineServer.URL, mockVecEmbedder())
+	if err := client.LoadCorpusStats(path); err != nil {
+		t.Fatalf("LoadCorpusStats on a missing file: %v", err)
+	}
+	if client.corpusStats() != nil {
+		t.Error("an empty corpus should score without statistics")
+	}
+	var seed CorpusStats
+	seed.Add("old pasta review")
+	if err := client.updateCorpusStats(seed); err != nil {
+		t.Fatalf("seeding stats: %v", err)
+	}
+
+	items := []IndexItem{
+		{Review: schemas.Review{ReviewID: "r1", Text: "fodmap pasta"}, Vector: []float32{0.1}},
+		{Review: schemas.Review{ReviewID: "r2", Text: "more pasta"}, Vector: []float32{0.2}},
+	}
+	if err := client.BatchUpsert(context.Background(), items); err != nil {
+		t.Fatalf("BatchUpsert failed: %v", err)
+	}
+
+	// The replaced r1 chunk is uncounted, so the corpus is the two new chunks.
+	reloaded := NewPineconeClient("test-key", pineServer.URL, mockVecEmbedder())
+	if err := reloaded.LoadCorpusStats(path); err != nil {
+		t.Fatalf("LoadCorpusStats: %v", err)
+	}
+	stats := reloaded.corpusStats()
+	if stats == nil || stats.Docs != 2 || stats.DocFreq["pasta"] != 2 || stats.DocFreq["fodmap"] != 1 || stats.DocFreq["old"] != 0 {
+		t.Errorf("persisted stats = %+v, want the two new chunks only", stats)
+	}
+}
+
 func TestPineconeClient_BatchUpsert_Empty(t *testing.T) {
 	mockEmb := mockVecEmbedder()
 	client := NewPineconeClient("test-key", "http://localhost:1234", mockEmb)
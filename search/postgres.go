@@ -8,8 +8,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"text/template"
+	"time"
 
 	"fodmap/data"
 
@@ -27,7 +30,16 @@ var sqlTemplates = template.Must(template.ParseFS(sqlFS, "sql/*.sql"))
 // sqlParams holds the dynamic portions injected into query templates.
 type sqlParams struct {
 	Where    string // complete WHERE clause, e.g. "WHERE r.city ILIKE $2"
+	OrderBy  string // ORDER BY expression, for templates whose ordering varies
 	LimitArg string // positional placeholder for the LIMIT value, e.g. "$3"
+	// Keyword is the complete WHERE clause of the full-text arm of a hybrid
+	// query, and KeywordArg the placeholder of its tsquery; an empty Keyword
+	// leaves the arm out.
+	Keyword    string
+	KeywordArg string
+	// Score is the per-row relevance expression (higher is better) for
+	// templates ranked by either vector similarity or full-text rank.
+	Score string
 }
 
 // renderSQL executes a named SQL template and returns the resulting query string.
@@ -43,6 +55,7 @@ func renderSQL(name string, p sqlParams) (string, error) {
 type PostgresClient struct {
 	db       *sql.DB
 	embedder Embedder
+	spaces   *spaceFollower // nil unless FollowEmbeddingSpaces was called
 }
 
 // NewPostgresClient creates a new PostgresClient.
@@ -64,6 +77,19 @@ func NewPostgresClient(dsn string, e Embedder) (*PostgresClient, error) {
 	}, nil
 }
 
+// Close closes the database handle and the embedders built for followed
+// embedding spaces. The client's own embedder belongs to the caller.
+func (c *PostgresClient) Close() error {
+	if f := c.spaces; f != nil {
+		f.mu.Lock()
+		for _, e := range f.embedders {
+			_ = e.Close()
+		}
+		f.mu.Unlock()
+	}
+	return c.db.Close()
+}
+
 // EnsureSchema is a no-op. Schema creation is handled by the centralised
 // migration runner (internal/db). The method is kept to satisfy the Searcher
 // interface.
@@ -85,9 +111,14 @@ func (c *PostgresClient) BatchUpsert(ctx context.Context, items []IndexItem) err
 		_ = tx.Rollback()
 	}()
 
+	items, err = c.freshReviewVectors(ctx, tx, items)
+	if err != nil {
+		return err
+	}
+
 	stmtReview, err := tx.PrepareContext(ctx, `
-		INSERT INTO reviews (review_id, business_id, business_name, city, state, categories, stars, text) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) 
+		INSERT INTO reviews (review_id, business_id, business_name, city, state, categories, stars, text, updated_at) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, coalesce($9::timestamptz, NOW())) 
 		ON CONFLICT (review_id) DO UPDATE SET 
 			business_id = EXCLUDED.business_id,
 			business_name = EXCLUDED.business_name,
@@ -95,33 +126,41 @@ func (c *PostgresClient) BatchUpsert(ctx context.Context, items []IndexItem) err
 			state = EXCLUDED.state,
 			categories = EXCLUDED.categories,
 			stars = EXCLUDED.stars,
-			text = EXCLUDED.text
+			text = EXCLUDED.text,
+			updated_at = EXCLUDED.updated_at
 	`)
 	if err != nil {
 		return fmt.Errorf("prepare review stmt: %w", err)
 	}
 	defer func() { _ = stmtReview.Close() }()
 
-	stmtDelChunks, err := tx.PrepareContext(ctx, `DELETE FROM review_chunks WHERE review_id = $1`)
+	stmtDelChunks, err := tx.PrepareContext(ctx, `DELETE FROM review_chunks WHERE review_id = $1 RETURNING chunk_text, chunk_tsv IS NOT NULL`)
 	if err != nil {
 		return fmt.Errorf("prepare delete chunks stmt: %w", err)
 	}
 	defer func() { _ = stmtDelChunks.Close() }()
 
 	stmtChunk, err := tx.PrepareContext(ctx, `
-		INSERT INTO review_chunks (review_id, chunk_text, embedding) 
-		VALUES ($1, $2, $3)
+		INSERT INTO review_chunks (review_id, chunk_text, embedding, chunk_tsv)
+		VALUES ($1, $2, $3, to_tsvector('simple', coalesce($2::text, '')))
 	`)
 	if err != nil {
 		return fmt.Errorf("prepare chunk stmt: %w", err)
 	}
 	defer func() { _ = stmtChunk.Close() }()
 
+	// delta collects the batch's change to the BM25 corpus statistics:
+	// replaced chunks out, new chunks in.
+	var delta CorpusStats
 	for _, item := range items {
 		var businessID any
 		if item.BusinessUUID != nil {
 			businessID = *item.BusinessUUID
 		}
+		var updatedAt any
+		if item.UpdatedAt != nil {
+			updatedAt = *item.UpdatedAt
+		}
 		if _, err := stmtReview.ExecContext(ctx,
 			item.Review.ReviewID,
 			businessID,
@@ -131,12 +170,13 @@ func (c *PostgresClient) BatchUpsert(ctx context.Context, items []IndexItem) err
 			item.Categories,
 			item.Review.Stars,
 			item.Review.Text,
+			updatedAt,
 		); err != nil {
 			return fmt.Errorf("insert review %q: %w", item.Review.ReviewID, err)
 		}
 
-		if _, err := stmtDelChunks.ExecContext(ctx, item.Review.ReviewID); err != nil {
-			return fmt.Errorf("delete old chunks %q: %w", item.Review.ReviewID, err)
+		if err := removeChunks(ctx, stmtDelChunks, item.Review.ReviewID, &delta); err != nil {
+			return err
 		}
 
 		if len(item.Chunks) > 0 {
@@ -144,18 +184,245 @@ func (c *PostgresClient) BatchUpsert(ctx context.Context, items []IndexItem) err
 				if _, err := stmtChunk.ExecContext(ctx, item.Review.ReviewID, chunk.Text, pgvector.NewHalfVector(chunk.Vector)); err != nil {
 					return fmt.Errorf("insert chunk for %q: %w", item.Review.ReviewID, err)
 				}
+				delta.Add(chunk.Text)
 			}
 		} else if item.Vector != nil {
 			// Fallback for legacy indexing paths that don't chunk yet
 			if _, err := stmtChunk.ExecContext(ctx, item.Review.ReviewID, item.Review.Text, pgvector.NewHalfVector(item.Vector)); err != nil {
 				return fmt.Errorf("insert legacy chunk for %q: %w", item.Review.ReviewID, err)
 			}
+			delta.Add(item.Review.Text)
 		}
 	}
 
+	if err := applyCorpusDelta(ctx, tx, reviewChunkCorpus, delta); err != nil {
+		return err
+	}
 	return tx.Commit()
 }
 
+// freshReviewVectors returns items with the vectors embedded before a
+// cutover of the reviews embedding space re-embedded (see reembedStale).
+// items itself is left as it is.
+func (c *PostgresClient) freshReviewVectors(ctx context.Context, tx *sql.Tx, items []IndexItem) ([]IndexItem, error) {
+	// vector i is chunk refs[i].chunk of item refs[i].item, or the item's
+	// legacy vector when chunk is -1.
+	type ref struct{ item, chunk int }
+	var refs []ref
+	for i, it := range items {
+		if len(it.Chunks) == 0 {
+			if it.Vector != nil {
+				refs = append(refs, ref{i, -1})
+			}
+			continue
+		}
+		for j := range it.Chunks {
+			refs = append(refs, ref{i, j})
+		}
+	}
+	var out []IndexItem
+	err := c.reembedStale(ctx, tx, CollectionReviews, len(refs),
+		func(i int) int64 {
+			if r := refs[i]; r.chunk >= 0 {
+				return items[r.item].Chunks[r.chunk].EmbeddingSpace
+			}
+			return items[refs[i].item].EmbeddingSpace
+		},
+		func(i int) string {
+			if r := refs[i]; r.chunk >= 0 {
+				return items[r.item].Chunks[r.chunk].Text
+			}
+			return items[refs[i].item].Review.Text
+		},
+		func(i int, v []float32) {
+			if out == nil {
+				out = slices.Clone(items)
+			}
+			r := refs[i]
+			if r.chunk < 0 {
+				out[r.item].Vector = v
+				return
+			}
+			if &out[r.item].Chunks[0] == &items[r.item].Chunks[0] {
+				out[r.item].Chunks = slices.Clone(items[r.item].Chunks)
+			}
+			out[r.item].Chunks[r.chunk].Vector = v
+		})
+	if err != nil || out == nil {
+		return items, err
+	}
+	return out, nil
+}
+
+// reviewChunkCorpus names the review chunks' BM25 statistics in bm25_corpus.
+const reviewChunkCorpus = "review_chunks"
+
+// removeChunks deletes a review's chunks and uncounts them from delta.
+// Chunks the statistics do not count yet (no chunk_tsv; see
+// BackfillCorpusStats) are not uncounted.
+func removeChunks(ctx context.Context, stmt *sql.Stmt, reviewID string, delta *CorpusStats) error {
+	rows, err := stmt.QueryContext(ctx, reviewID)
+	if err != nil {
+		return fmt.Errorf("delete old chunks %q: %w", reviewID, err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var text sql.NullString
+		var counted bool
+		if err := rows.Scan(&text, &counted); err != nil {
+			return fmt.Errorf("scan deleted chunk %q: %w", reviewID, err)
+		}
+		if counted {
+			delta.Remove(text.String)
+		}
+	}
+	return rows.Err()
+}
+
+// BackfillCorpusStats indexes up to limit review chunks written before
+// chunks carried a full-text vector (chunk_tsv) and counts them into the
+// BM25 corpus statistics, returning how many it did; 0 means every chunk is
+// counted. Run it in a loop after upgrading: each batch is one short
+// transaction, and concurrent indexing is unaffected.
+func (c *PostgresClient) BackfillCorpusStats(ctx context.Context, limit int) (int, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE review_chunks SET chunk_tsv = to_tsvector('simple', coalesce(chunk_text, ''))
+		WHERE chunk_id IN (
+			SELECT chunk_id FROM review_chunks WHERE chunk_tsv IS NULL
+			ORDER BY chunk_id LIMIT $1 FOR UPDATE SKIP LOCKED)
+		RETURNING chunk_text
+	`, limit)
+	if err != nil {
+		return 0, fmt.Errorf("index review chunks: %w", err)
+	}
+	var delta CorpusStats
+	n := 0
+	for rows.Next() {
+		var text sql.NullString
+		if err := rows.Scan(&text); err != nil {
+			_ = rows.Close()
+			return 0, fmt.Errorf("scan review chunk: %w", err)
+		}
+		delta.Add(text.String)
+		n++
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("index review chunks: %w", err)
+	}
+	if err := applyCorpusDelta(ctx, tx, reviewChunkCorpus, delta); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+	return n, nil
+}
+
+// BackfillReviewUpdatedAt sets updated_at to created_at on up to limit
+// reviews stored before the column existed, returning how many it set; 0
+// means every review has one. Run it in a loop after upgrading, as
+// BackfillCorpusStats: each batch is one short statement, and the reviews
+// table is not readable as an index source until it is done.
+func (c *PostgresClient) BackfillReviewUpdatedAt(ctx context.Context, limit int) (int, error) {
+	res, err := c.db.ExecContext(ctx, `
+		UPDATE reviews SET updated_at = created_at
+		WHERE review_id IN (
+			SELECT review_id FROM reviews WHERE updated_at IS NULL
+			ORDER BY review_id LIMIT $1 FOR UPDATE SKIP LOCKED)
+	`, limit)
+	if err != nil {
+		return 0, fmt.Errorf("backfill review updated_at: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("backfill review updated_at: %w", err)
+	}
+	return int(n), nil
+}
+
+// applyCorpusDelta adds delta to the persisted statistics of corpus. The
+// corpus row is updated first, so concurrent indexers serialise on it before
+// touching any term rows and cannot deadlock on them.
+func applyCorpusDelta(ctx context.Context, tx *sql.Tx, corpus string, delta CorpusStats) error {
+	if delta.Docs == 0 && delta.TotalLen == 0 && len(delta.DocFreq) == 0 {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO bm25_corpus (corpus, doc_count, total_length)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (corpus) DO UPDATE SET
+			doc_count = bm25_corpus.doc_count + EXCLUDED.doc_count,
+			total_length = bm25_corpus.total_length + EXCLUDED.total_length,
+			updated_at = NOW()
+	`, corpus, delta.Docs, delta.TotalLen); err != nil {
+		return fmt.Errorf("update bm25 corpus stats: %w", err)
+	}
+	if len(delta.DocFreq) == 0 {
+		return nil
+	}
+	terms := make([]string, 0, len(delta.DocFreq))
+	for t := range delta.DocFreq {
+		terms = append(terms, t)
+	}
+	sort.Strings(terms)
+	freqs := make([]int64, len(terms))
+	for i, t := range terms {
+		freqs[i] = delta.DocFreq[t]
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO bm25_terms (corpus, term, doc_freq)
+		SELECT $1, t.term, t.doc_freq FROM unnest($2::text[], $3::bigint[]) AS t(term, doc_freq)
+		ON CONFLICT (corpus, term) DO UPDATE SET doc_freq = bm25_terms.doc_freq + EXCLUDED.doc_freq
+	`, corpus, pq.Array(terms), pq.Array(freqs)); err != nil {
+		return fmt.Errorf("update bm25 term stats: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM bm25_terms WHERE corpus = $1 AND term = ANY($2) AND doc_freq <= 0`,
+		corpus, pq.Array(terms)); err != nil {
+		return fmt.Errorf("prune bm25 term stats: %w", err)
+	}
+	return nil
+}
+
+// loadCorpusStats reads the statistics of corpus for the given terms only,
+// which is all scoring a query needs. It returns nil when the corpus has no
+// statistics yet, so scoring falls back to uniform term weights.
+func (c *PostgresClient) loadCorpusStats(ctx context.Context, corpus string, terms []string) (*CorpusStats, error) {
+	stats := &CorpusStats{DocFreq: make(map[string]int64, len(terms))}
+	err := c.db.QueryRowContext(ctx, `SELECT doc_count, total_length FROM bm25_corpus WHERE corpus = $1`, corpus).
+		Scan(&stats.Docs, &stats.TotalLen)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load bm25 corpus stats: %w", err)
+	}
+	if stats.Docs <= 0 {
+		return nil, nil
+	}
+	rows, err := c.db.QueryContext(ctx, `SELECT term, doc_freq FROM bm25_terms WHERE corpus = $1 AND term = ANY($2)`,
+		corpus, pq.Array(terms))
+	if err != nil {
+		return nil, fmt.Errorf("load bm25 term stats: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var term string
+		var df int64
+		if err := rows.Scan(&term, &df); err != nil {
+			return nil, fmt.Errorf("scan bm25 term stats: %w", err)
+		}
+		stats.DocFreq[term] = df
+	}
+	return stats, rows.Err()
+}
+
 // EnsureFodmapSchema is a no-op. Schema creation is handled by the centralised
 // migration runner (internal/db). The method is kept to satisfy the Searcher
 // interface.
@@ -195,7 +462,7 @@ func (c *PostgresClient) BatchUpsertFodmap(ctx context.Context, items map[string
 
 	for name, entry := range items {
 		// Vectorize the ingredient name
-		vec, err := c.embedder.EmbedSingle(ctx, name)
+		vec, err := c.embedSingle(ctx, CollectionFodmap, name)
 		if err != nil {
 			return fmt.Errorf("vectorize %q: %w", name, err)
 		}
@@ -218,7 +485,7 @@ func (c *PostgresClient) BatchUpsertFodmap(ctx context.Context, items map[string
 
 // SearchFodmap looks up an ingredient in the fodmap_ingredients table using cosine distance.
 func (c *PostgresClient) SearchFodmap(ctx context.Context, ingredient string) (FodmapResult, float64, error) {
-	vec, err := c.embedder.EmbedSingle(ctx, ingredient)
+	vec, err := c.embedSingle(ctx, CollectionFodmap, ingredient)
 	if err != nil {
 		return FodmapResult{}, 0, fmt.Errorf("vectorize ingredient: %w", err)
 	}
@@ -249,7 +516,7 @@ func (c *PostgresClient) SearchFodmap(ctx context.Context, ingredient string) (F
 // vector table. It is used by the admin CRUD handler to keep the search index
 // in sync with the canonical catalog.
 func (c *PostgresClient) UpsertFodmapItem(ctx context.Context, name string, entry data.FodmapEntry) error {
-	vec, err := c.embedder.EmbedSingle(ctx, name)
+	vec, err := c.embedSingle(ctx, CollectionFodmap, name)
 	if err != nil {
 		return fmt.Errorf("vectorize ingredient: %w", err)
 	}
@@ -318,45 +585,43 @@ func (a *pgxStringArray) Scan(src any) error {
 }
 
 // Businesses performs an aggregation-like search by querying reviews and grouping by business.
+// A lexical Retrieval ranks chunks by full-text rank instead of the vector.
 func (c *PostgresClient) Businesses(ctx context.Context, query string, limit int, filter SearchFilter) (SearchResult, error) {
-	vec, err := c.embedder.EmbedSingle(ctx, query)
-	if err != nil {
-		return SearchResult{}, fmt.Errorf("vectorize query: %w", err)
+	var args []any
+	var score string
+	var whereClauses []string
+	if filter.Retrieval == RetrieveLexical {
+		terms := uniqueTerms(tokenize(query))
+		if len(terms) == 0 {
+			return SearchResult{}, nil
+		}
+		args = append(args, strings.Join(terms, " | "))
+		score = "ts_rank(rc.chunk_tsv, to_tsquery('simple', $1))"
+		whereClauses = append(whereClauses, "rc.chunk_tsv @@ to_tsquery('simple', $1)")
+	} else {
+		vec, err := c.embedSingle(ctx, CollectionReviews, query)
+		if err != nil {
+			return SearchResult{}, fmt.Errorf("vectorize query: %w", err)
+		}
+		args = append(args, pgvector.NewHalfVector(vec))
+		score = "(1 - (rc.embedding <=> $1))"
 	}
 
-	// Base query parts
-	whereClauses := []string{}
-	args := []any{pgvector.NewHalfVector(vec)}
-	argID := 2
-
-	if filter.Category != "" {
-		whereClauses = append(whereClauses, fmt.Sprintf("r.categories ILIKE $%d", argID))
-		args = append(args, "%"+filter.Category+"%")
-		argID++
-	}
-	if filter.City != "" {
-		whereClauses = append(whereClauses, fmt.Sprintf("r.city ILIKE $%d", argID))
-		args = append(args, "%"+filter.City+"%")
-		argID++
-	}
-	if filter.State != "" {
-		whereClauses = append(whereClauses, fmt.Sprintf("r.state ILIKE $%d", argID))
-		args = append(args, filter.State)
-		argID++
-	}
+	filterClauses, filterArgs := businessFilterClauses(filter, len(args)+1)
+	whereClauses = append(whereClauses, filterClauses...)
+	args = append(args, filterArgs...)
 
 	whereSQL := ""
 	if len(whereClauses) > 0 {
 		whereSQL = "WHERE " + strings.Join(whereClauses, " AND ")
 	}
 
-	sqlQuery, err := renderSQL("get_businesses.sql", sqlParams{Where: whereSQL, LimitArg: fmt.Sprintf("$%d", argID)})
+	args = append(args, limit)
+	sqlQuery, err := renderSQL("get_businesses.sql", sqlParams{Where: whereSQL, Score: score, LimitArg: fmt.Sprintf("$%d", len(args))})
 	if err != nil {
 		return SearchResult{}, err
 	}
 
-	args = append(args, limit)
-
 	rows, err := c.db.QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
 		return SearchResult{}, fmt.Errorf("query businesses: %w", err)
@@ -382,17 +647,87 @@ func (c *PostgresClient) Businesses(ctx context.Context, query string, limit int
 	return SearchResult{Businesses: businesses}, nil
 }
 
+// businessFilterClauses renders the category, city, state and geo filters of
+// a business search as conditions on the reviews table, numbering
+// placeholders from argID.
+func businessFilterClauses(filter SearchFilter, argID int) ([]string, []any) {
+	var whereClauses []string
+	var args []any
+	if filter.Category != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("r.categories ILIKE $%d", argID))
+		args = append(args, "%"+filter.Category+"%")
+		argID++
+	}
+	if filter.City != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("r.city ILIKE $%d", argID))
+		args = append(args, "%"+filter.City+"%")
+		argID++
+	}
+	if filter.State != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("r.state ILIKE $%d", argID))
+		args = append(args, filter.State)
+		argID++
+	}
+	if filter.Geo != nil {
+		clause, geoArgs := geoClause("g", filter.Geo, argID)
+		whereClauses = append(whereClauses, "r.business_id IN (SELECT g.id FROM restaurants g WHERE "+clause+")")
+		args = append(args, geoArgs...)
+	}
+	return whereClauses, args
+}
+
 // Reviews retrieves top reviews for a query, filtered by business if specified.
 func (c *PostgresClient) Reviews(ctx context.Context, query string, limit int, filter SearchFilter) (SearchReviews, error) {
-	vec, err := c.embedder.EmbedSingle(ctx, query)
+	if filter.Retrieval == RetrieveLexical {
+		return c.lexicalReviews(ctx, query, limit, filter)
+	}
+	vec, err := c.embedSingle(ctx, CollectionReviews, query)
 	if err != nil {
 		return SearchReviews{}, fmt.Errorf("vectorize query: %w", err)
 	}
 
-	whereClauses := []string{}
-	args := []any{pgvector.NewHalfVector(vec)}
-	argID := 2
+	whereClauses, filterArgs := reviewFilterClauses(filter, 2)
+	args := append([]any{pgvector.NewHalfVector(vec)}, filterArgs...)
+	argID := len(args) + 1
+
+	if filter.Retrieval == RetrieveDefault && hybridActive(filter.Alpha) {
+		return c.hybridReviews(ctx, query, limit, filter.Alpha, whereClauses, args, argID)
+	}
+
+	whereSQL := ""
+	if len(whereClauses) > 0 {
+		whereSQL = "WHERE " + strings.Join(whereClauses, " AND ")
+	}
+
+	sqlQuery, err := renderSQL("get_reviews.sql", sqlParams{Where: whereSQL, LimitArg: fmt.Sprintf("$%d", argID)})
+	if err != nil {
+		return SearchReviews{}, err
+	}
+
+	args = append(args, limit)
+
+	rows, err := c.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return SearchReviews{}, fmt.Errorf("query reviews: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var reviews []RankedReview
+	for rows.Next() {
+		r, err := scanRankedReview(rows)
+		if err != nil {
+			return SearchReviews{}, err
+		}
+		reviews = append(reviews, r)
+	}
+	return SearchReviews{BusinessReviews: reviews}, nil
+}
 
+// reviewFilterClauses renders the business, city, state, geo and review-ID
+// filters of a review search, numbering placeholders from argID.
+func reviewFilterClauses(filter SearchFilter, argID int) ([]string, []any) {
+	var whereClauses []string
+	var args []any
 	if filter.BusinessID != uuid.Nil {
 		whereClauses = append(whereClauses, fmt.Sprintf("r.business_id = $%d", argID))
 		args = append(args, filter.BusinessID)
@@ -408,25 +743,96 @@ func (c *PostgresClient) Reviews(ctx context.Context, query string, limit int, f
 		args = append(args, filter.State)
 		argID++
 	}
+	if filter.Geo != nil {
+		clause, geoArgs := geoClause("g", filter.Geo, argID)
+		whereClauses = append(whereClauses, "r.business_id IN (SELECT g.id FROM restaurants g WHERE "+clause+")")
+		args = append(args, geoArgs...)
+		argID += len(geoArgs)
+	}
 	if len(filter.ReviewIDs) > 0 {
 		// Use ANY for array check
 		whereClauses = append(whereClauses, fmt.Sprintf("r.review_id = ANY($%d)", argID))
 		// pq/pgx allows passing []string to ANY
 		args = append(args, pq.Array(filter.ReviewIDs))
-		argID++
 	}
+	return whereClauses, args
+}
+
+// hybridCandidateFactor is how many candidate chunks per requested review
+// each arm of a hybrid query contributes for BM25 re-ranking.
+const hybridCandidateFactor = 4
+
+// hybridReviews blends vector certainty with BM25 over the review chunk
+// corpus. Candidates are the closest chunks by vector plus the best
+// full-text matches, so a review the embedding ranks low can still surface
+// on an exact keyword; each review is scored by its best chunk.
+func (c *PostgresClient) hybridReviews(ctx context.Context, query string, limit int, alpha float32, whereClauses []string, args []any, argID int) (SearchReviews, error) {
+	terms := uniqueTerms(tokenize(query))
 
 	whereSQL := ""
 	if len(whereClauses) > 0 {
 		whereSQL = "WHERE " + strings.Join(whereClauses, " AND ")
 	}
+	p := sqlParams{Where: whereSQL}
+	if len(terms) > 0 {
+		// Terms are ASCII letters and digits only, so joining them with the
+		// OR operator always yields a valid tsquery.
+		p.KeywordArg = fmt.Sprintf("$%d", argID)
+		p.Keyword = "WHERE " + strings.Join(append(slices.Clone(whereClauses),
+			fmt.Sprintf("rc.chunk_tsv @@ to_tsquery('simple', %s)", p.KeywordArg)), " AND ")
+		args = append(args, strings.Join(terms, " | "))
+		argID++
+	}
+	p.LimitArg = fmt.Sprintf("$%d", argID)
+	args = append(args, limit*hybridCandidateFactor)
 
-	sqlQuery, err := renderSQL("get_reviews.sql", sqlParams{Where: whereSQL, LimitArg: fmt.Sprintf("$%d", argID)})
+	sqlQuery, err := renderSQL("get_reviews_hybrid.sql", p)
+	if err != nil {
+		return SearchReviews{}, err
+	}
+	stats, err := c.loadCorpusStats(ctx, reviewChunkCorpus, terms)
 	if err != nil {
 		return SearchReviews{}, err
 	}
 
-	args = append(args, limit)
+	rows, err := c.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return SearchReviews{}, fmt.Errorf("query reviews: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return bestChunkPerReview(rows, limit, func(r RankedReview) float64 {
+		return blendScore(query, r.MatchedChunk, r.Score, alpha, stats)
+	})
+}
+
+// lexicalReviews ranks reviews by BM25 over the review chunk corpus alone:
+// the best full-text matching chunks are fetched without a query vector and
+// each review is scored by its best chunk. A query with no searchable terms
+// matches nothing.
+func (c *PostgresClient) lexicalReviews(ctx context.Context, query string, limit int, filter SearchFilter) (SearchReviews, error) {
+	terms := uniqueTerms(tokenize(query))
+	if len(terms) == 0 {
+		return SearchReviews{}, nil
+	}
+
+	whereClauses, filterArgs := reviewFilterClauses(filter, 2)
+	whereClauses = append([]string{"rc.chunk_tsv @@ to_tsquery('simple', $1)"}, whereClauses...)
+	args := append([]any{strings.Join(terms, " | ")}, filterArgs...)
+	args = append(args, limit*hybridCandidateFactor)
+
+	sqlQuery, err := renderSQL("get_reviews_lexical.sql", sqlParams{
+		Where:      "WHERE " + strings.Join(whereClauses, " AND "),
+		KeywordArg: "$1",
+		LimitArg:   fmt.Sprintf("$%d", len(args)),
+	})
+	if err != nil {
+		return SearchReviews{}, err
+	}
+	stats, err := c.loadCorpusStats(ctx, reviewChunkCorpus, terms)
+	if err != nil {
+		return SearchReviews{}, err
+	}
 
 	rows, err := c.db.QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
@@ -434,39 +840,73 @@ func (c *PostgresClient) Reviews(ctx context.Context, query string, limit int, f
 	}
 	defer func() { _ = rows.Close() }()
 
+	return bestChunkPerReview(rows, limit, func(r RankedReview) float64 {
+		return bm25Score(query, r.MatchedChunk, stats)
+	})
+}
+
+// bestChunkPerReview scans candidate chunk rows, scores each with score and
+// keeps the best chunk per review, returning the top limit reviews by score.
+func bestChunkPerReview(rows *sql.Rows, limit int, score func(RankedReview) float64) (SearchReviews, error) {
+	best := make(map[string]int)
 	var reviews []RankedReview
 	for rows.Next() {
-		var r RankedReview
-		var city, state sql.NullString
-		if err := rows.Scan(
-			&r.Review.Review.ReviewID,
-			&r.Review.Review.BusinessID,
-			&r.Review.BusinessName,
-			&city,
-			&state,
-			&r.Review.Review.Text,
-			&r.MatchedChunk,
-			&r.Score,
-		); err != nil {
-			return SearchReviews{}, fmt.Errorf("scan review: %w", err)
-		}
-		if city.Valid {
-			r.Review.City = city.String
+		r, err := scanRankedReview(rows)
+		if err != nil {
+			return SearchReviews{}, err
 		}
-		if state.Valid {
-			r.Review.State = state.String
+		r.Score = score(r)
+		if i, ok := best[r.Review.Review.ReviewID]; ok {
+			if r.Score > reviews[i].Score {
+				reviews[i] = r
+			}
+			continue
 		}
+		best[r.Review.Review.ReviewID] = len(reviews)
 		reviews = append(reviews, r)
 	}
+	if err := rows.Err(); err != nil {
+		return SearchReviews{}, fmt.Errorf("query reviews: %w", err)
+	}
+	sort.SliceStable(reviews, func(i, j int) bool { return reviews[i].Score > reviews[j].Score })
+	if len(reviews) > limit {
+		reviews = reviews[:limit]
+	}
 	return SearchReviews{BusinessReviews: reviews}, nil
 }
 
+// scanRankedReview scans a get_reviews row; Score holds the vector certainty.
+func scanRankedReview(rows *sql.Rows) (RankedReview, error) {
+	var r RankedReview
+	var city, state sql.NullString
+	if err := rows.Scan(
+		&r.Review.Review.ReviewID,
+		&r.Review.Review.BusinessID,
+		&r.Review.BusinessName,
+		&city,
+		&state,
+		&r.Review.Review.Text,
+		&r.MatchedChunk,
+		&r.Score,
+	); err != nil {
+		return RankedReview{}, fmt.Errorf("scan review: %w", err)
+	}
+	if city.Valid {
+		r.Review.City = city.String
+	}
+	if state.Valid {
+		r.Review.State = state.String
+	}
+	return r, nil
+}
+
 // EnsureMenuSchema is a no-op because migrations handle the schema.
 func (c *PostgresClient) EnsureMenuSchema(_ context.Context) error {
 	return nil
 }
 
-// BatchUpsertMenu upserts menu items to the Postgres menu_items table.
+// BatchUpsertMenu upserts menu items to the Postgres menu_items table. An
+// upserted item that had been soft-deleted is restored.
 func (c *PostgresClient) BatchUpsertMenu(ctx context.Context, items []MenuItem) error {
 	if len(items) == 0 {
 		return nil
@@ -478,9 +918,45 @@ func (c *PostgresClient) BatchUpsertMenu(ctx context.Context, items []MenuItem)
 	}
 	defer func() { _ = tx.Rollback() }()
 
+	items, err = c.freshMenuVectors(ctx, tx, items)
+	if err != nil {
+		return err
+	}
+	if err := upsertMenuItems(ctx, tx, items); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// freshMenuVectors returns items with the vectors embedded before a cutover
+// of the menu embedding space re-embedded (see reembedStale). items itself
+// is left as it is.
+func (c *PostgresClient) freshMenuVectors(ctx context.Context, tx *sql.Tx, items []MenuItem) ([]MenuItem, error) {
+	var out []MenuItem
+	err := c.reembedStale(ctx, tx, CollectionMenu, len(items),
+		func(i int) int64 {
+			if items[i].Vector == nil {
+				return 0
+			}
+			return items[i].EmbeddingSpace
+		},
+		func(i int) string { return MenuEmbeddingText(items[i]) },
+		func(i int, v []float32) {
+			if out == nil {
+				out = slices.Clone(items)
+			}
+			out[i].Vector = v
+		})
+	if err != nil || out == nil {
+		return items, err
+	}
+	return out, nil
+}
+
+func upsertMenuItems(ctx context.Context, tx *sql.Tx, items []MenuItem) error {
 	stmt, err := tx.PrepareContext(ctx, `
-		INSERT INTO menu_items (menu_item_id, business_id, menu_section, restaurant_name, city, state, dish_name, description, price, stated_ingredients, has_full_ingredients, modifiers, source_url, address, phone_number, scraped_at, embedding, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, NOW(), NOW())
+		INSERT INTO menu_items (menu_item_id, business_id, menu_section, restaurant_name, city, state, dish_name, description, price, stated_ingredients, has_full_ingredients, modifiers, source_url, address, phone_number, scraped_at, embedding, language, dish_name_en, description_en, stated_ingredients_en, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, NOW(), NOW())
 		ON CONFLICT (menu_item_id) DO UPDATE SET
 			business_id = EXCLUDED.business_id,
 			menu_section = EXCLUDED.menu_section,
@@ -497,7 +973,12 @@ func (c *PostgresClient) BatchUpsertMenu(ctx context.Context, items []MenuItem)
 			address = EXCLUDED.address,
 			phone_number = EXCLUDED.phone_number,
 			scraped_at = EXCLUDED.scraped_at,
-			embedding = EXCLUDED.embedding
+			embedding = EXCLUDED.embedding,
+			language = EXCLUDED.language,
+			dish_name_en = EXCLUDED.dish_name_en,
+			description_en = EXCLUDED.description_en,
+			stated_ingredients_en = EXCLUDED.stated_ingredients_en,
+			removed_at = NULL
 	`)
 	if err != nil {
 		return fmt.Errorf("prepare stmt: %w", err)
@@ -510,28 +991,351 @@ func (c *PostgresClient) BatchUpsertMenu(ctx context.Context, items []MenuItem)
 			vec = pgvector.NewHalfVector(item.Vector)
 		}
 		modifiersJSON, _ := json.Marshal(item.Modifiers)
-		if _, err := stmt.ExecContext(ctx, item.MenuItemID, item.BusinessID, item.MenuSection, item.RestaurantName, item.City, item.State, item.DishName, item.Description, item.Price, pq.Array(item.StatedIngredients), item.HasFullIngredients, modifiersJSON, item.SourceURL, item.Address, item.PhoneNumber, item.ScrapedAt, vec); err != nil {
+		if _, err := stmt.ExecContext(ctx, item.MenuItemID, item.BusinessID, item.MenuSection, item.RestaurantName, item.City, item.State, item.DishName, item.Description, item.Price, pq.Array(item.StatedIngredients), item.HasFullIngredients, modifiersJSON, item.SourceURL, item.Address, item.PhoneNumber, item.ScrapedAt, vec,
+			nullIfEmpty(item.Language), nullIfEmpty(item.DishNameEn), nullIfEmpty(item.DescriptionEn), translatedIngredients(item)); err != nil {
 			return fmt.Errorf("insert menu item %q: %w", item.MenuItemID, err)
 		}
 	}
+	return nil
+}
 
-	return tx.Commit()
+// snapshotItem is the JSON form of a menu item kept in menu_snapshots.items:
+// everything shown on a menu, without the embedding.
+type snapshotItem struct {
+	MenuItemID        string     `json:"menu_item_id"`
+	MenuSection       string     `json:"menu_section,omitempty"`
+	DishName          string     `json:"dish_name"`
+	Description       string     `json:"description,omitempty"`
+	Price             *float64   `json:"price,omitempty"`
+	StatedIngredients []string   `json:"stated_ingredients,omitempty"`
+	Modifiers         []Modifier `json:"modifiers,omitempty"`
+
+	DishNameEn          string   `json:"dish_name_en,omitempty"`
+	DescriptionEn       string   `json:"description_en,omitempty"`
+	StatedIngredientsEn []string `json:"stated_ingredients_en,omitempty"`
 }
 
-// SearchMenu searches the Postgres menu_items table using cosine distance.
-func (c *PostgresClient) SearchMenu(ctx context.Context, query string, limit int) ([]MenuItem, error) {
-	vec, err := c.embedder.EmbedSingle(ctx, query)
+// ReplaceMenu stores a fresh scrape of one menu URL. items must share a
+// BusinessID and SourceURL, as StoreMenu produces them. In one transaction it
+// diffs items against the live items previously scraped from that URL,
+// upserts items, soft-deletes the items no longer listed (removed_at), and
+// records a snapshot with its changes. An empty scrape records nothing and
+// removes nothing, since it more likely means a failed extraction than an
+// empty menu.
+func (c *PostgresClient) ReplaceMenu(ctx context.Context, items []MenuItem) (*MenuSnapshot, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	businessID, sourceURL := items[0].BusinessID, items[0].SourceURL
+	for _, it := range items[1:] {
+		if it.BusinessID != businessID || it.SourceURL != sourceURL {
+			return nil, fmt.Errorf("replace menu: items span several menus (%s %q and %s %q)",
+				businessID, sourceURL, it.BusinessID, it.SourceURL)
+		}
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	prev, err := liveMenuItems(ctx, tx, businessID, sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	changes := DiffMenu(prev, items)
+
+	items, err = c.freshMenuVectors(ctx, tx, items)
+	if err != nil {
+		return nil, err
+	}
+	if err := upsertMenuItems(ctx, tx, items); err != nil {
+		return nil, err
+	}
+	var removed []string
+	for _, ch := range changes {
+		if ch.Type == MenuItemRemoved {
+			removed = append(removed, ch.MenuItemID)
+		}
+	}
+	if len(removed) > 0 {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE menu_items SET removed_at = NOW() WHERE menu_item_id = ANY($1) AND removed_at IS NULL`,
+			pq.Array(removed)); err != nil {
+			return nil, fmt.Errorf("soft-delete removed menu items: %w", err)
+		}
+	}
+
+	snap := &MenuSnapshot{
+		BusinessID: businessID,
+		SourceURL:  sourceURL,
+		ScrapedAt:  time.Now().UTC(),
+		ItemCount:  len(items),
+		Changes:    changes,
+	}
+	if t, err := time.Parse(time.RFC3339, items[0].ScrapedAt); err == nil {
+		snap.ScrapedAt = t
+	}
+	slim := make([]snapshotItem, len(items))
+	for i, it := range items {
+		slim[i] = snapshotItem{
+			MenuItemID:        it.MenuItemID,
+			MenuSection:       it.MenuSection,
+			DishName:          it.DishName,
+			Description:       it.Description,
+			Price:             it.Price,
+			StatedIngredients: it.StatedIngredients,
+			Modifiers:         it.Modifiers,
+
+			DishNameEn:          it.DishNameEn,
+			DescriptionEn:       it.DescriptionEn,
+			StatedIngredientsEn: it.StatedIngredientsEn,
+		}
+	}
+	itemsJSON, err := json.Marshal(slim)
 	if err != nil {
-		return nil, fmt.Errorf("vectorize query: %w", err)
+		return nil, fmt.Errorf("marshal snapshot items: %w", err)
+	}
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO menu_snapshots (business_id, source_url, scraped_at, item_count, items)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, businessID, sourceURL, snap.ScrapedAt, snap.ItemCount, itemsJSON).Scan(&snap.ID); err != nil {
+		return nil, fmt.Errorf("insert menu snapshot: %w", err)
+	}
+
+	if len(changes) > 0 {
+		stmt, err := tx.PrepareContext(ctx, `
+			INSERT INTO menu_item_changes (snapshot_id, menu_item_id, dish_name, menu_section, change_type, old_price, new_price, old_ingredients, new_ingredients, old_description, new_description)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`)
+		if err != nil {
+			return nil, fmt.Errorf("prepare change stmt: %w", err)
+		}
+		defer func() { _ = stmt.Close() }()
+		for _, ch := range changes {
+			if _, err := stmt.ExecContext(ctx, snap.ID, ch.MenuItemID, ch.DishName, ch.MenuSection, string(ch.Type),
+				ch.OldPrice, ch.NewPrice, pq.Array(ch.OldIngredients), pq.Array(ch.NewIngredients),
+				nullIfEmpty(ch.OldDescription), nullIfEmpty(ch.NewDescription)); err != nil {
+				return nil, fmt.Errorf("insert menu change %q: %w", ch.MenuItemID, err)
+			}
+		}
 	}
 
-	q := `
-		SELECT menu_item_id, business_id, menu_section, restaurant_name, city, state, dish_name, description, price, stated_ingredients, has_full_ingredients, modifiers, source_url, address, phone_number, scraped_at
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	return snap, nil
+}
+
+// liveMenuItems loads the fields DiffMenu compares for the items not yet
+// soft-deleted from one menu URL, locking them for the rest of tx so
+// concurrent scrapes of the same URL serialize.
+func liveMenuItems(ctx context.Context, tx *sql.Tx, businessID uuid.UUID, sourceURL string) ([]MenuItem, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT menu_item_id, dish_name, menu_section, description, price, stated_ingredients
 		FROM menu_items
-		ORDER BY embedding <=> $1
-		LIMIT $2
-	`
-	rows, err := c.db.QueryContext(ctx, q, pgvector.NewHalfVector(vec), limit)
+		WHERE business_id = $1 AND source_url = $2 AND removed_at IS NULL
+		ORDER BY menu_section, dish_name
+		FOR UPDATE
+	`, businessID, sourceURL)
+	if err != nil {
+		return nil, fmt.Errorf("load current menu: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []MenuItem
+	for rows.Next() {
+		var m MenuItem
+		var sec, desc sql.NullString
+		var price sql.NullFloat64
+		if err := rows.Scan(&m.MenuItemID, &m.DishName, &sec, &desc, &price, (*pgxStringArray)(&m.StatedIngredients)); err != nil {
+			return nil, fmt.Errorf("scan current menu item: %w", err)
+		}
+		m.MenuSection = sec.String
+		m.Description = desc.String
+		if price.Valid {
+			p := price.Float64
+			m.Price = &p
+		}
+		items = append(items, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return items, nil
+}
+
+// MenuTimeline returns a restaurant's menu snapshots newest first, each with
+// its changes. With itemID set, only snapshots that changed that item are
+// returned, and only that item's changes.
+func (c *PostgresClient) MenuTimeline(ctx context.Context, businessID uuid.UUID, itemID string, limit int) ([]MenuSnapshot, error) {
+	query := `
+		SELECT s.id, s.business_id, s.source_url, s.scraped_at, s.item_count
+		FROM menu_snapshots s
+		WHERE s.business_id = $1`
+	args := []any{businessID}
+	if itemID != "" {
+		args = append(args, itemID)
+		query += ` AND EXISTS (SELECT 1 FROM menu_item_changes c WHERE c.snapshot_id = s.id AND c.menu_item_id = $2)`
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(` ORDER BY s.scraped_at DESC, s.created_at DESC LIMIT $%d`, len(args))
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query menu snapshots: %w", err)
+	}
+	var snaps []MenuSnapshot
+	index := make(map[uuid.UUID]int)
+	ids := make([]string, 0, limit)
+	for rows.Next() {
+		var s MenuSnapshot
+		if err := rows.Scan(&s.ID, &s.BusinessID, &s.SourceURL, &s.ScrapedAt, &s.ItemCount); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("scan menu snapshot: %w", err)
+		}
+		s.Changes = []MenuChange{}
+		index[s.ID] = len(snaps)
+		ids = append(ids, s.ID.String())
+		snaps = append(snaps, s)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	if len(snaps) == 0 {
+		return snaps, nil
+	}
+
+	changeQuery := `
+		SELECT snapshot_id, menu_item_id, dish_name, menu_section, change_type, old_price, new_price, old_ingredients, new_ingredients, old_description, new_description
+		FROM menu_item_changes
+		WHERE snapshot_id = ANY($1::uuid[])`
+	changeArgs := []any{pq.Array(ids)}
+	if itemID != "" {
+		changeQuery += ` AND menu_item_id = $2`
+		changeArgs = append(changeArgs, itemID)
+	}
+	changeQuery += ` ORDER BY id`
+	crows, err := c.db.QueryContext(ctx, changeQuery, changeArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("query menu changes: %w", err)
+	}
+	defer func() { _ = crows.Close() }()
+	for crows.Next() {
+		var snapID uuid.UUID
+		var ch MenuChange
+		var sec, changeType, oldDesc, newDesc sql.NullString
+		var oldPrice, newPrice sql.NullFloat64
+		if err := crows.Scan(&snapID, &ch.MenuItemID, &ch.DishName, &sec, &changeType, &oldPrice, &newPrice,
+			(*pgxStringArray)(&ch.OldIngredients), (*pgxStringArray)(&ch.NewIngredients), &oldDesc, &newDesc); err != nil {
+			return nil, fmt.Errorf("scan menu change: %w", err)
+		}
+		ch.MenuSection = sec.String
+		ch.Type = MenuChangeType(changeType.String)
+		if oldPrice.Valid {
+			p := oldPrice.Float64
+			ch.OldPrice = &p
+		}
+		if newPrice.Valid {
+			p := newPrice.Float64
+			ch.NewPrice = &p
+		}
+		ch.OldDescription = oldDesc.String
+		ch.NewDescription = newDesc.String
+		if i, ok := index[snapID]; ok {
+			snaps[i].Changes = append(snaps[i].Changes, ch)
+		}
+	}
+	if err := crows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	return snaps, nil
+}
+
+// translatedIngredients returns the English ingredient list to store, or nil
+// (SQL NULL) when the item has none.
+func translatedIngredients(item MenuItem) any {
+	if len(item.StatedIngredientsEn) == 0 {
+		return nil
+	}
+	return pq.Array(item.StatedIngredientsEn)
+}
+
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// menuTSVector is the full-text document of a menu item for lexical menu
+// search: dish text and ingredients, original and English. It is computed per
+// row rather than indexed; menu searches are narrowed by city, business or
+// geo filters first.
+const menuTSVector = `to_tsvector('simple', concat_ws(' ', m.dish_name, m.description, array_to_string(m.stated_ingredients, ' '), m.dish_name_en, m.description_en, array_to_string(m.stated_ingredients_en, ' ')))`
+
+// SearchMenu searches the Postgres menu_items table using cosine distance,
+// or full-text rank when Retrieval is lexical, joined to restaurants for
+// coordinates. City, state, business and geo
+// filters narrow the candidates before ranking. With an empty query, items
+// are ordered by distance from the geo origin, or by recency without one.
+// SortByDistance re-sorts the relevance-ranked results nearest first, so a
+// query like "low-FODMAP lunch" within 800m returns the best matches in the
+// area rather than merely the closest dishes.
+func (c *PostgresClient) SearchMenu(ctx context.Context, query string, limit int, filter SearchFilter) ([]MenuItem, error) {
+	whereClauses := []string{"m.removed_at IS NULL"}
+	var args []any
+	orderBy := "m.scraped_at DESC NULLS LAST"
+	if query != "" && filter.Retrieval == RetrieveLexical {
+		terms := uniqueTerms(tokenize(query))
+		if len(terms) == 0 {
+			return nil, nil
+		}
+		args = append(args, strings.Join(terms, " | "))
+		whereClauses = append(whereClauses, menuTSVector+" @@ to_tsquery('simple', $1)")
+		orderBy = "ts_rank(" + menuTSVector + ", to_tsquery('simple', $1)) DESC"
+	} else if query != "" {
+		vec, err := c.embedSingle(ctx, CollectionMenu, query)
+		if err != nil {
+			return nil, fmt.Errorf("vectorize query: %w", err)
+		}
+		args = append(args, pgvector.NewHalfVector(vec))
+		orderBy = "m.embedding <=> $1"
+	}
+
+	if filter.City != "" {
+		args = append(args, "%"+filter.City+"%")
+		whereClauses = append(whereClauses, fmt.Sprintf("m.city ILIKE $%d", len(args)))
+	}
+	if filter.State != "" {
+		args = append(args, filter.State)
+		whereClauses = append(whereClauses, fmt.Sprintf("m.state ILIKE $%d", len(args)))
+	}
+	if filter.BusinessID != uuid.Nil {
+		args = append(args, filter.BusinessID)
+		whereClauses = append(whereClauses, fmt.Sprintf("m.business_id = $%d", len(args)))
+	}
+	if filter.Geo != nil {
+		clause, geoArgs := geoClause("r", filter.Geo, len(args)+1)
+		whereClauses = append(whereClauses, clause)
+		args = append(args, geoArgs...)
+		if query == "" && filter.Geo.Origin != nil {
+			args = append(args, filter.Geo.Origin.Lat, filter.Geo.Origin.Lon)
+			orderBy = fmt.Sprintf("ll_to_earth(r.latitude, r.longitude) <-> ll_to_earth($%d, $%d)", len(args)-1, len(args))
+		}
+	}
+
+	whereSQL := "WHERE " + strings.Join(whereClauses, " AND ")
+	args = append(args, limit)
+	sqlQuery, err := renderSQL("search_menu.sql", sqlParams{Where: whereSQL, OrderBy: orderBy, LimitArg: fmt.Sprintf("$%d", len(args))})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := c.db.QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query menu items: %w", err)
 	}
@@ -541,11 +1345,14 @@ func (c *PostgresClient) SearchMenu(ctx context.Context, query string, limit int
 	for rows.Next() {
 		var m MenuItem
 		var sec, restName, city, state, desc, sourceURL, address, phone, scrapedAt sql.NullString
-		var price sql.NullFloat64
+		var lang, dishEn, descEn sql.NullString
+		var price, lat, lon sql.NullFloat64
 		var modifiersJSON []byte
-		if err := rows.Scan(&m.MenuItemID, &m.BusinessID, &sec, &restName, &city, &state, &m.DishName, &desc, &price, (*pgxStringArray)(&m.StatedIngredients), &m.HasFullIngredients, &modifiersJSON, &sourceURL, &address, &phone, &scrapedAt); err != nil {
+		if err := rows.Scan(&m.MenuItemID, &m.BusinessID, &sec, &restName, &city, &state, &m.DishName, &desc, &price, (*pgxStringArray)(&m.StatedIngredients), &m.HasFullIngredients, &modifiersJSON, &sourceURL, &address, &phone, &scrapedAt,
+			&lang, &dishEn, &descEn, (*pgxStringArray)(&m.StatedIngredientsEn), &lat, &lon); err != nil {
 			return nil, fmt.Errorf("scan menu item: %w", err)
 		}
+		m.Language, m.DishNameEn, m.DescriptionEn = lang.String, dishEn.String, descEn.String
 		m.MenuSection = sec.String
 		m.RestaurantName = restName.String
 		m.City = city.String
@@ -562,18 +1369,62 @@ func (c *PostgresClient) SearchMenu(ctx context.Context, query string, limit int
 		m.Address = address.String
 		m.PhoneNumber = phone.String
 		m.ScrapedAt = scrapedAt.String
+		if lat.Valid && lon.Valid {
+			m.Latitude, m.Longitude = &lat.Float64, &lon.Float64
+			if filter.Geo != nil && filter.Geo.Origin != nil {
+				d := filter.Geo.Origin.DistanceTo(lat.Float64, lon.Float64)
+				m.DistanceMeters = &d
+			}
+		}
 		results = append(results, m)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration: %w", err)
+	}
+	if filter.Geo != nil && filter.Geo.SortByDistance {
+		SortMenuByDistance(results)
+	}
 	return results, nil
 }
 
+// geoClause renders f as a condition on the latitude/longitude columns of
+// the restaurants table aliased as alias, numbering its placeholders from
+// argID. The radius uses earth_box for the GiST index and earth_distance for
+// the exact cut; the box uses the (latitude, longitude) btree index.
+func geoClause(alias string, f *GeoFilter, argID int) (string, []any) {
+	lat, lon := alias+".latitude", alias+".longitude"
+	var clauses []string
+	var args []any
+	if f.Origin != nil && f.RadiusMeters > 0 {
+		origin := fmt.Sprintf("ll_to_earth($%d, $%d)", argID, argID+1)
+		point := fmt.Sprintf("ll_to_earth(%s, %s)", lat, lon)
+		clauses = append(clauses,
+			fmt.Sprintf("earth_box(%s, $%d) @> %s", origin, argID+2, point),
+			fmt.Sprintf("earth_distance(%s, %s) <= $%d", origin, point, argID+2))
+		args = append(args, f.Origin.Lat, f.Origin.Lon, f.RadiusMeters)
+		argID += 3
+	}
+	if f.BBox != nil {
+		clauses = append(clauses, fmt.Sprintf("%s BETWEEN $%d AND $%d AND %s BETWEEN $%d AND $%d",
+			lat, argID, argID+1, lon, argID+2, argID+3))
+		args = append(args, f.BBox.MinLat(), f.BBox.MaxLat(), f.BBox.MinLon(), f.BBox.MaxLon())
+	}
+	if len(clauses) == 0 {
+		// An origin alone only orders results; still require coordinates so
+		// distances can be reported.
+		clauses = append(clauses, fmt.Sprintf("%s IS NOT NULL AND %s IS NOT NULL", lat, lon))
+	}
+	return strings.Join(clauses, " AND "), args
+}
+
 // ListMenuItems returns paginated menu items with an optional search filter.
+// Soft-deleted items are excluded.
 func (c *PostgresClient) ListMenuItems(ctx context.Context, search string, limit, offset int) ([]MenuItem, int, error) {
-	var whereClause string
+	whereClause := "WHERE m.removed_at IS NULL"
 	var args []any
 
 	if search != "" {
-		whereClause = "WHERE m.dish_name ILIKE $1 OR m.restaurant_name ILIKE $1"
+		whereClause += " AND (m.dish_name ILIKE $1 OR m.dish_name_en ILIKE $1 OR m.restaurant_name ILIKE $1)"
 		args = append(args, "%"+search+"%")
 	}
 
@@ -588,7 +1439,8 @@ func (c *PostgresClient) ListMenuItems(ctx context.Context, search string, limit
 	paramOffset := len(args)
 
 	query := fmt.Sprintf(`
-		SELECT menu_item_id, business_id, menu_section, restaurant_name, city, state, dish_name, description, price, stated_ingredients, has_full_ingredients, modifiers, source_url, address, phone_number, scraped_at
+		SELECT menu_item_id, business_id, menu_section, restaurant_name, city, state, dish_name, description, price, stated_ingredients, has_full_ingredients, modifiers, source_url, address, phone_number, scraped_at,
+		       language, dish_name_en, description_en, stated_ingredients_en
 		FROM menu_items m
 		%s
 		ORDER BY scraped_at DESC NULLS LAST
@@ -605,11 +1457,14 @@ func (c *PostgresClient) ListMenuItems(ctx context.Context, search string, limit
 	for rows.Next() {
 		var m MenuItem
 		var sec, restName, city, state, desc, sourceURL, address, phone, scrapedAt sql.NullString
+		var lang, dishEn, descEn sql.NullString
 		var price sql.NullFloat64
 		var modifiersJSON []byte
-		if err := rows.Scan(&m.MenuItemID, &m.BusinessID, &sec, &restName, &city, &state, &m.DishName, &desc, &price, (*pgxStringArray)(&m.StatedIngredients), &m.HasFullIngredients, &modifiersJSON, &sourceURL, &address, &phone, &scrapedAt); err != nil {
+		if err := rows.Scan(&m.MenuItemID, &m.BusinessID, &sec, &restName, &city, &state, &m.DishName, &desc, &price, (*pgxStringArray)(&m.StatedIngredients), &m.HasFullIngredients, &modifiersJSON, &sourceURL, &address, &phone, &scrapedAt,
+			&lang, &dishEn, &descEn, (*pgxStringArray)(&m.StatedIngredientsEn)); err != nil {
 			return nil, 0, fmt.Errorf("scan menu item: %w", err)
 		}
+		m.Language, m.DishNameEn, m.DescriptionEn = lang.String, dishEn.String, descEn.String
 		m.MenuSection = sec.String
 		m.RestaurantName = restName.String
 		m.City = city.String
@@ -6,9 +6,11 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"fodmap/data"
 	"fodmap/data/schemas"
+	"fodmap/geo"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
@@ -121,17 +123,33 @@ func TestPostgresClient_BatchUpsert(t *testing.T) {
 			"Restaurant, Food",
 			4.5,
 			"Great food!",
+			nil,
 		).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	prepDel.ExpectExec().
+	// The review was indexed before as "Good food!": its chunk leaves the
+	// BM25 statistics as the new one enters them. A chunk from before the
+	// statistics were backfilled was never counted, so it is not uncounted.
+	prepDel.ExpectQuery().
 		WithArgs("rev1").
-		WillReturnResult(sqlmock.NewResult(0, 0))
+		WillReturnRows(sqlmock.NewRows([]string{"chunk_text", "counted"}).
+			AddRow("Good food!", true).
+			AddRow("Okay food", false))
 
 	prepChunk.ExpectExec().
 		WithArgs("rev1", "Great food!", pgvector.NewHalfVector([]float32{0.1, 0.2, 0.3})).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
+	mock.ExpectExec("INSERT INTO bm25_corpus").
+		WithArgs("review_chunks", int64(0), int64(0)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO bm25_terms").
+		WithArgs("review_chunks", pq.Array([]string{"good", "great"}), pq.Array([]int64{-1, 1})).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM bm25_terms").
+		WithArgs("review_chunks", pq.Array([]string{"good", "great"})).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
 	mock.ExpectCommit()
 
 	err = client.BatchUpsert(context.Background(), items)
@@ -147,7 +165,8 @@ func TestPostgresClient_BatchUpsert(t *testing.T) {
 // TestPostgresClient_BatchUpsert_WithBusinessUUID verifies that when
 // IndexItem.BusinessUUID is populated (the Yelp union step), the UUID is
 // passed to the reviews.business_id column instead of the raw string
-// Review.BusinessID.
+// Review.BusinessID, and that a source UpdatedAt is kept rather than
+// replaced by the time of writing.
 func TestPostgresClient_BatchUpsert_WithBusinessUUID(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -158,6 +177,7 @@ func TestPostgresClient_BatchUpsert_WithBusinessUUID(t *testing.T) {
 	client := &PostgresClient{db: db, embedder: nil}
 
 	bizUUID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	updated := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
 	items := []IndexItem{
 		{
 			Review: schemas.Review{
@@ -172,6 +192,7 @@ func TestPostgresClient_BatchUpsert_WithBusinessUUID(t *testing.T) {
 			State:        "NY",
 			Categories:   "Restaurant",
 			Vector:       []float32{0.1, 0.2, 0.3},
+			UpdatedAt:    &updated,
 		},
 	}
 
@@ -190,17 +211,28 @@ func TestPostgresClient_BatchUpsert_WithBusinessUUID(t *testing.T) {
 			"Restaurant",
 			5.0,
 			"Amazing!",
+			updated,
 		).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	mock.ExpectExec("DELETE FROM review_chunks").
+	mock.ExpectQuery("DELETE FROM review_chunks").
 		WithArgs("rev1").
-		WillReturnResult(sqlmock.NewResult(0, 0))
+		WillReturnRows(sqlmock.NewRows([]string{"chunk_text", "counted"}))
 
 	mock.ExpectExec("INSERT INTO review_chunks").
 		WithArgs("rev1", "Amazing!", pgvector.NewHalfVector([]float32{0.1, 0.2, 0.3})).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
+	mock.ExpectExec("INSERT INTO bm25_corpus").
+		WithArgs("review_chunks", int64(1), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO bm25_terms").
+		WithArgs("review_chunks", pq.Array([]string{"amazing"}), pq.Array([]int64{1})).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM bm25_terms").
+		WithArgs("review_chunks", pq.Array([]string{"amazing"})).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
 	mock.ExpectCommit()
 
 	if err := client.BatchUpsert(context.Background(), items); err != nil {
@@ -370,6 +402,103 @@ func TestPostgresClient_Reviews(t *testing.T) {
 	}
 }
 
+func TestPostgresClient_Reviews_Hybrid(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	vec := pgvector.NewHalfVector([]float32{0.1, 0.2, 0.3})
+	client := &PostgresClient{db: db, embedder: &mockEmbedder{vec: []float32{0.1, 0.2, 0.3}}}
+
+	// "pasta" is in nearly every chunk, "fodmap" in few: a review matching
+	// the rare term overtakes one the vector search ranked higher.
+	mock.ExpectQuery("SELECT doc_count, total_length FROM bm25_corpus").
+		WithArgs("review_chunks").
+		WillReturnRows(sqlmock.NewRows([]string{"doc_count", "total_length"}).AddRow(1000, 10000))
+	mock.ExpectQuery("SELECT term, doc_freq FROM bm25_terms").
+		WithArgs("review_chunks", pq.Array([]string{"fodmap", "pasta"})).
+		WillReturnRows(sqlmock.NewRows([]string{"term", "doc_freq"}).AddRow("fodmap", 5).AddRow("pasta", 900))
+	mock.ExpectQuery("UNION").
+		WithArgs(vec, "fodmap | pasta", 8).
+		WillReturnRows(sqlmock.NewRows([]string{"review_id", "business_id", "business_name", "city", "state", "text", "chunk_text", "certainty"}).
+			AddRow("rev1", "b1", "Pasta Place", "New York", "NY", "Pasta every night.", "pasta every night", 0.80).
+			AddRow("rev2", "b2", "Gut Kitchen", "New York", "NY", "Low fodmap options.", "low fodmap options", 0.70).
+			AddRow("rev2", "b2", "Gut Kitchen", "New York", "NY", "Low fodmap options.", "nice staff", 0.75))
+
+	res, err := client.Reviews(context.Background(), "fodmap pasta", 2, SearchFilter{Alpha: 0.5})
+	if err != nil {
+		t.Fatalf("Reviews returned error: %v", err)
+	}
+	if len(res.BusinessReviews) != 2 {
+		t.Fatalf("got %d reviews, want 2 (one per review)", len(res.BusinessReviews))
+	}
+	if got := res.BusinessReviews[0]; got.Review.Review.ReviewID != "rev2" || got.MatchedChunk != "low fodmap options" {
+		t.Errorf("top review = %s (%q), want rev2 matched on its fodmap chunk", got.Review.Review.ReviewID, got.MatchedChunk)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresClient_Reviews_Lexical(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	// A lexical search needs no query vector: the embedder would fail.
+	client := &PostgresClient{db: db, embedder: &mockEmbedder{err: fmt.Errorf("embedder not expected")}}
+	bizID := uuid.New()
+
+	mock.ExpectQuery("SELECT doc_count, total_length FROM bm25_corpus").
+		WithArgs("review_chunks").
+		WillReturnRows(sqlmock.NewRows([]string{"doc_count", "total_length"}))
+	mock.ExpectQuery(`WHERE rc.chunk_tsv @@ to_tsquery\('simple', \$1\) AND r.business_id = \$2\s+ORDER\s+BY rank DESC\s+LIMIT\s+\$3`).
+		WithArgs("fodmap | pasta", bizID, 8).
+		WillReturnRows(sqlmock.NewRows([]string{"review_id", "business_id", "business_name", "city", "state", "text", "chunk_text", "rank"}).
+			AddRow("rev1", "b1", "Pasta Place", "New York", "NY", "Pasta.", "pasta", 0.1).
+			AddRow("rev2", "b1", "Pasta Place", "New York", "NY", "Low fodmap pasta.", "low fodmap pasta", 0.2))
+
+	res, err := client.Reviews(context.Background(), "fodmap pasta", 2, SearchFilter{BusinessID: bizID, Retrieval: RetrieveLexical})
+	if err != nil {
+		t.Fatalf("Reviews returned error: %v", err)
+	}
+	if len(res.BusinessReviews) != 2 || res.BusinessReviews[0].Review.Review.ReviewID != "rev2" {
+		t.Errorf("reviews = %+v, want rev2 (both terms) first", res.BusinessReviews)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresClient_Businesses_Lexical(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	client := &PostgresClient{db: db, embedder: &mockEmbedder{err: fmt.Errorf("embedder not expected")}}
+	mock.ExpectQuery(`ts_rank\(rc.chunk_tsv, to_tsquery\('simple', \$1\)\)\s+AS certainty.+WHERE rc.chunk_tsv @@ to_tsquery\('simple', \$1\) AND r.city ILIKE \$2`).
+		WithArgs("gluten | free", "%Boston%", 5).
+		WillReturnRows(sqlmock.NewRows([]string{"business_id", "name", "city", "state", "categories", "avg_stars", "avg_certainty"}).
+			AddRow(uuid.New(), "GF Bakery", "Boston", "MA", "Bakeries", 4.5, 0.3))
+
+	res, err := client.Businesses(context.Background(), "gluten-free", 5, SearchFilter{City: "Boston", Retrieval: RetrieveLexical})
+	if err != nil {
+		t.Fatalf("Businesses returned error: %v", err)
+	}
+	if len(res.Businesses) != 1 || res.Businesses[0].Name != "GF Bakery" {
+		t.Errorf("businesses = %+v", res.Businesses)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
 // pgxStringArray Test
 func TestPgxStringArray_Scan(t *testing.T) {
 	var arr pgxStringArray
@@ -463,6 +592,59 @@ func TestPostgresClient_BatchUpsert_ExecError(t *testing.T) {
 	}
 }
 
+func TestPostgresClient_BackfillCorpusStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	client := &PostgresClient{db: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE review_chunks SET chunk_tsv = .+ WHERE chunk_tsv IS NULL\s+ORDER BY chunk_id LIMIT \$1 FOR UPDATE SKIP LOCKED`).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"chunk_text"}).AddRow("Good food").AddRow("good pasta"))
+	mock.ExpectExec("INSERT INTO bm25_corpus").
+		WithArgs("review_chunks", int64(2), int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO bm25_terms").
+		WithArgs("review_chunks", pq.Array([]string{"food", "good", "pasta"}), pq.Array([]int64{1, 2, 1})).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("DELETE FROM bm25_terms").
+		WithArgs("review_chunks", pq.Array([]string{"food", "good", "pasta"})).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	n, err := client.BackfillCorpusStats(context.Background(), 2)
+	if err != nil || n != 2 {
+		t.Fatalf("BackfillCorpusStats = %d, %v; want 2 chunks", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresClient_BackfillReviewUpdatedAt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	client := &PostgresClient{db: db}
+
+	mock.ExpectExec(`UPDATE reviews SET updated_at = created_at\s+WHERE review_id IN .+ WHERE updated_at IS NULL\s+ORDER BY review_id LIMIT \$1 FOR UPDATE SKIP LOCKED`).
+		WithArgs(500).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	n, err := client.BackfillReviewUpdatedAt(context.Background(), 500)
+	if err != nil || n != 3 {
+		t.Fatalf("BackfillReviewUpdatedAt = %d, %v; want 3 reviews", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
 func TestPostgresClient_Businesses_QueryError(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -501,3 +683,179 @@ func TestNewPostgresClient(t *testing.T) {
 		t.Error("expected error due to bad connection string")
 	}
 }
+
+func TestPostgresClient_SearchMenu_Geo(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	client := &PostgresClient{db: db, embedder: &mockEmbedder{vec: []float32{0.1, 0.2, 0.3}}}
+
+	cols := []string{"menu_item_id", "business_id", "menu_section", "restaurant_name", "city", "state", "dish_name", "description", "price",
+		"stated_ingredients", "has_full_ingredients", "modifiers", "source_url", "address", "phone_number", "scraped_at",
+		"language", "dish_name_en", "description_en", "stated_ingredients_en", "latitude", "longitude"}
+	mock.ExpectQuery(`LEFT JOIN restaurants r ON r.id = m.business_id\s+WHERE m.removed_at IS NULL AND earth_box\(ll_to_earth\(\$2, \$3\), \$4\) @> ll_to_earth\(r.latitude, r.longitude\) AND earth_distance\(.+\) <= \$4\s+ORDER BY m.embedding <=> \$1`).
+		WithArgs(pgvector.NewHalfVector([]float32{0.1, 0.2, 0.3}), 40.76, -73.92, 800.0, 5).
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow("a", "550e8400-e29b-41d4-a716-446655440000", nil, "Far", nil, nil, "Salad", nil, nil, "{}", false, nil, nil, nil, nil, nil, nil, nil, nil, nil, 40.765, -73.92).
+			AddRow("b", "550e8400-e29b-41d4-a716-446655440000", nil, "Near", nil, nil, "Soup", nil, nil, "{ajo}", false, nil, nil, nil, nil, nil, "es", "Garlic soup", nil, "{garlic}", 40.761, -73.92))
+
+	items, err := client.SearchMenu(context.Background(), "lunch", 5, SearchFilter{
+		Geo: &GeoFilter{Origin: &geo.Point{Lat: 40.76, Lon: -73.92}, RadiusMeters: 800, SortByDistance: true},
+	})
+	if err != nil {
+		t.Fatalf("SearchMenu returned error: %v", err)
+	}
+	if len(items) != 2 || items[0].DishName != "Soup" || items[1].DishName != "Salad" {
+		t.Fatalf("want nearest first [Soup Salad], got %+v", items)
+	}
+	if it := items[0]; it.Language != "es" || it.DishNameEn != "Garlic soup" || len(it.StatedIngredientsEn) != 1 || it.StatedIngredientsEn[0] != "garlic" {
+		t.Errorf("translation = %q %q %v", it.Language, it.DishNameEn, it.StatedIngredientsEn)
+	}
+	if d := items[0].DistanceMeters; d == nil || *d < 100 || *d > 120 {
+		t.Errorf("distance = %v, want about 111m", d)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresClient_SearchMenu_NearestWithoutQuery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	client := &PostgresClient{db: db, embedder: &mockEmbedder{err: fmt.Errorf("must not embed")}}
+
+	mock.ExpectQuery(`WHERE m.removed_at IS NULL AND r.latitude BETWEEN \$1 AND \$2 AND r.longitude BETWEEN \$3 AND \$4\s+ORDER BY ll_to_earth\(r.latitude, r.longitude\) <-> ll_to_earth\(\$5, \$6\)\s+LIMIT \$7`).
+		WithArgs(40.7, 40.8, -74.0, -73.9, 40.75, -73.95, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"menu_item_id"}))
+
+	bbox := geo.BBox{-74.0, 40.7, -73.9, 40.8}
+	_, err = client.SearchMenu(context.Background(), "", 10, SearchFilter{
+		Geo: &GeoFilter{Origin: &geo.Point{Lat: 40.75, Lon: -73.95}, BBox: &bbox},
+	})
+	if err != nil {
+		t.Fatalf("SearchMenu returned error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresClient_SearchMenu_Lexical(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	client := &PostgresClient{db: db, embedder: &mockEmbedder{err: fmt.Errorf("must not embed")}}
+
+	mock.ExpectQuery(`WHERE m.removed_at IS NULL AND to_tsvector\('simple', concat_ws\(.+m.dish_name_en.+\)\) @@ to_tsquery\('simple', \$1\) AND m.city ILIKE \$2\s+ORDER BY ts_rank\(.+\) DESC\s+LIMIT \$3`).
+		WithArgs("risotto", "%Boston%", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"menu_item_id"}))
+
+	_, err = client.SearchMenu(context.Background(), "Risotto!", 10, SearchFilter{City: "Boston", Retrieval: RetrieveLexical})
+	if err != nil {
+		t.Fatalf("SearchMenu returned error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresClient_SearchMenu_WithoutRestaurantRow(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	client := &PostgresClient{db: db, embedder: &mockEmbedder{err: fmt.Errorf("must not embed")}}
+
+	cols := []string{"menu_item_id", "business_id", "menu_section", "restaurant_name", "city", "state", "dish_name", "description", "price",
+		"stated_ingredients", "has_full_ingredients", "modifiers", "source_url", "address", "phone_number", "scraped_at",
+		"language", "dish_name_en", "description_en", "stated_ingredients_en", "latitude", "longitude"}
+	mock.ExpectQuery(`FROM\s+menu_items m\s+LEFT JOIN restaurants r ON r.id = m.business_id`).
+		WithArgs("risotto", 10).
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow("a", "550e8400-e29b-41d4-a716-446655440000", nil, "Orphan", nil, nil, "Risotto", nil, nil, "{}", false, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil))
+
+	items, err := client.SearchMenu(context.Background(), "risotto", 10, SearchFilter{Retrieval: RetrieveLexical})
+	if err != nil {
+		t.Fatalf("SearchMenu returned error: %v", err)
+	}
+	if len(items) != 1 || items[0].DishName != "Risotto" || items[0].DistanceMeters != nil {
+		t.Fatalf("want the item without coordinates, got %+v", items)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresClient_ReplaceMenu(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	client := &PostgresClient{db: db}
+
+	bid := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	snapID := uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	url := "https://example.com/menu"
+	items := []MenuItem{
+		{MenuItemID: "curry", BusinessID: bid, SourceURL: url, DishName: "Green Curry", StatedIngredients: []string{"coconut milk"}, ScrapedAt: "2026-03-01T12:00:00Z"},
+		{MenuItemID: "rice", BusinessID: bid, SourceURL: url, DishName: "Sticky Rice", ScrapedAt: "2026-03-01T12:00:00Z"},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM menu_items\s+WHERE business_id = \$1 AND source_url = \$2 AND removed_at IS NULL`).
+		WithArgs(bid, url).
+		WillReturnRows(sqlmock.NewRows([]string{"menu_item_id", "dish_name", "menu_section", "description", "price", "stated_ingredients"}).
+			AddRow("curry", "Green Curry", nil, nil, nil, "{coconut milk}").
+			AddRow("rolls", "Spring Rolls", nil, nil, 7.0, "{}"))
+	prep := mock.ExpectPrepare("INSERT INTO menu_items")
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(1, 1))
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`UPDATE menu_items SET removed_at = NOW\(\)`).
+		WithArgs(pq.Array([]string{"rolls"})).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO menu_snapshots").
+		WithArgs(bid, url, sqlmock.AnyArg(), 2, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(snapID))
+	prepChange := mock.ExpectPrepare("INSERT INTO menu_item_changes")
+	prepChange.ExpectExec().
+		WithArgs(snapID, "rice", "Sticky Rice", "", "added", nil, nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	prepChange.ExpectExec().
+		WithArgs(snapID, "rolls", "Spring Rolls", "", "removed", sqlmock.AnyArg(), nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	snap, err := client.ReplaceMenu(context.Background(), items)
+	if err != nil {
+		t.Fatalf("ReplaceMenu returned error: %v", err)
+	}
+	if snap.ID != snapID || snap.ItemCount != 2 || len(snap.Changes) != 2 {
+		t.Fatalf("snapshot = %+v", snap)
+	}
+	if snap.ScrapedAt.Format("2006-01-02") != "2026-03-01" {
+		t.Errorf("scraped_at = %v, want the items' scrape time", snap.ScrapedAt)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresClient_ReplaceMenu_MixedMenus(t *testing.T) {
+	client := &PostgresClient{}
+	_, err := client.ReplaceMenu(context.Background(), []MenuItem{
+		{MenuItemID: "a", SourceURL: "https://example.com/lunch"},
+		{MenuItemID: "b", SourceURL: "https://example.com/dinner"},
+	})
+	if err == nil {
+		t.Fatal("expected an error for items from two menu URLs")
+	}
+}
@@ -0,0 +1,128 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river"
+)
+
+// Defaults for ReembedWorker.
+const (
+	DefaultReembedBatchSize = 256
+	DefaultReembedSlice     = 5 * time.Minute
+)
+
+// ReembedArgs is the background job that fills an embedding space while
+// the active one keeps serving, then optionally cuts over to it.
+type ReembedArgs struct {
+	SpaceID int64 `json:"space_id"`
+	// Activate cuts the collection over to the space once it is filled and
+	// indexed; otherwise the space is left ready for `embeddings activate`.
+	Activate bool `json:"activate"`
+}
+
+func (ReembedArgs) Kind() string {
+	return "search.reembed"
+}
+
+// ReembedWorker fills an embedding space in batches with the embedder
+// recorded on it. It works for at most Slice per run and then snoozes, so
+// a large collection is filled over many short runs that survive restarts:
+// progress is the vectors already stored. A filled space gets its HNSW
+// index and, when asked, is activated; rows written since the last batch
+// make activation fail with ErrEmbeddingSpaceIncomplete, and the job
+// snoozes to embed them and try again. Run on a space that is already
+// active, it waits out the cutover's grace period, embeds the rows written
+// during it and ends it.
+type ReembedWorker struct {
+	river.WorkerDefaults[ReembedArgs]
+	Client    *PostgresClient
+	BatchSize int           // rows per batch; 0 means DefaultReembedBatchSize
+	Slice     time.Duration // work per run; 0 means DefaultReembedSlice
+}
+
+// Timeout disables River's job timeout: building the HNSW index of a large
+// collection takes as long as it takes, and filling is bounded by Slice.
+func (w *ReembedWorker) Timeout(*river.Job[ReembedArgs]) time.Duration {
+	return -1
+}
+
+func (w *ReembedWorker) Work(ctx context.Context, job *river.Job[ReembedArgs]) error {
+	s, err := w.Client.EmbeddingSpace(ctx, job.Args.SpaceID)
+	if err != nil {
+		return err
+	}
+	if s.Status == SpaceRetired {
+		return nil
+	}
+	if s.Embedder == (EmbedderConfig{}) {
+		if s.Status == SpaceActive {
+			return nil // a seeded space, never cut over to
+		}
+		return river.JobCancel(fmt.Errorf("embedding space %d records no embedder", s.ID))
+	}
+	if s.Status == SpaceActive && s.ActivatedAt != nil {
+		if left := EmbeddingSpaceGrace - time.Since(*s.ActivatedAt); left > 0 {
+			return river.JobSnooze(left)
+		}
+	}
+	e, err := NewEmbedder(ctx, s.Embedder)
+	if err != nil {
+		return fmt.Errorf("embedder of embedding space %d: %w", s.ID, err)
+	}
+	defer func() { _ = e.Close() }()
+
+	batch := w.BatchSize
+	if batch <= 0 {
+		batch = DefaultReembedBatchSize
+	}
+	slice := w.Slice
+	if slice <= 0 {
+		slice = DefaultReembedSlice
+	}
+	deadline := time.Now().Add(slice)
+	embedded := 0
+	for {
+		n, err := w.Client.ReembedBatch(ctx, s, e, batch)
+		if err != nil {
+			return err
+		}
+		embedded += n
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			slog.Info("reembed: continuing in the next run", "space", s.ID, "collection", s.Collection, "embedded", embedded)
+			return river.JobSnooze(0)
+		}
+	}
+
+	if s.Status == SpaceActive {
+		slog.Info("reembed: embedded the rows written during cutover", "space", s.ID, "collection", s.Collection, "embedded", embedded)
+		return w.Client.EndEmbeddingSpaceGrace(ctx, s)
+	}
+	if s.Status == SpaceBuilding {
+		if err := w.Client.BuildEmbeddingIndex(ctx, s); err != nil {
+			return err
+		}
+	}
+	if !job.Args.Activate {
+		slog.Info("reembed: embedding space ready", "space", s.ID, "collection", s.Collection, "model", s.Model)
+		return nil
+	}
+	err = w.Client.ActivateEmbeddingSpace(ctx, s.ID)
+	if errors.Is(err, ErrEmbeddingSpaceIncomplete) {
+		slog.Info("reembed: rows changed during the fill; embedding them before cutover", "space", s.ID, "error", err)
+		return river.JobSnooze(time.Second)
+	}
+	if err != nil {
+		return err
+	}
+	// Writers still on the replaced model have their vectors cleared until
+	// the grace period is over; come back then to embed those rows.
+	return river.JobSnooze(EmbeddingSpaceGrace)
+}
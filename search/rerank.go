@@ -0,0 +1,104 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Reranker scores how well each text answers query, typically with a
+// cross-encoder that reads the pair together. It returns one score per text,
+// in input order; higher is more relevant.
+type Reranker interface {
+	Rerank(ctx context.Context, query string, texts []string) ([]float64, error)
+}
+
+// Verify TEIReranker implements Reranker at compile time.
+var _ Reranker = (*TEIReranker)(nil)
+
+// TEIReranker implements Reranker against the /rerank endpoint of a
+// HuggingFace Text Embeddings Inference service started with a cross-encoder
+// (e.g. BAAI/bge-reranker-base). Anything speaking the same API works, which
+// makes a local stub a few lines of HTTP handler.
+//
+// TEI's /rerank accepts {"query": ..., "texts": [...], "truncate": true} and
+// returns [{"index": i, "score": s}, ...] sorted by score; the indexes map
+// scores back to input order.
+type TEIReranker struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewTEIReranker creates a reranker pointed at baseURL (e.g.
+// "http://localhost:8081").
+func NewTEIReranker(baseURL string) *TEIReranker {
+	return &TEIReranker{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// teiRerankRequest is the body sent to TEI's /rerank endpoint.
+type teiRerankRequest struct {
+	Query    string   `json:"query"`
+	Texts    []string `json:"texts"`
+	Truncate bool     `json:"truncate"`
+}
+
+// teiRerankResult is one entry of TEI's /rerank response.
+type teiRerankResult struct {
+	Index int     `json:"index"`
+	Score float64 `json:"score"`
+}
+
+// Rerank posts the pair list to /rerank and returns the scores in input
+// order. An empty texts slice returns nil, nil.
+func (r *TEIReranker) Rerank(ctx context.Context, query string, texts []string) ([]float64, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	reqBody, err := json.Marshal(teiRerankRequest{Query: query, Texts: texts, Truncate: true})
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.baseURL+"/rerank", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("tei rerank returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var results []teiRerankResult
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("decode tei rerank response: %w", err)
+	}
+	if len(results) != len(texts) {
+		return nil, fmt.Errorf("tei rerank returned %d scores, expected %d", len(results), len(texts))
+	}
+	scores := make([]float64, len(texts))
+	seen := make([]bool, len(texts))
+	for _, res := range results {
+		if res.Index < 0 || res.Index >= len(texts) || seen[res.Index] {
+			return nil, fmt.Errorf("tei rerank returned bad index %d", res.Index)
+		}
+		seen[res.Index] = true
+		scores[res.Index] = res.Score
+	}
+	return scores, nil
+}
@@ -0,0 +1,57 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestTEIReranker_Rerank(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rerank" {
+			t.Errorf("expected path /rerank, got %s", r.URL.Path)
+		}
+		var req teiRerankRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode req: %v", err)
+		}
+		if req.Query != "gluten free" || len(req.Texts) != 3 || !req.Truncate {
+			t.Errorf("unexpected request: %+v", req)
+		}
+		// TEI sorts by score; indexes point back to the inputs.
+		_ = json.NewEncoder(w).Encode([]teiRerankResult{{Index: 2, Score: 0.9}, {Index: 0, Score: 0.5}, {Index: 1, Score: 0.1}})
+	}))
+	defer srv.Close()
+
+	scores, err := NewTEIReranker(srv.URL+"/").Rerank(context.Background(), "gluten free", []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []float64{0.5, 0.1, 0.9}; !reflect.DeepEqual(scores, want) {
+		t.Errorf("scores = %v, want %v", scores, want)
+	}
+}
+
+func TestTEIReranker_Rerank_Errors(t *testing.T) {
+	cases := map[string]http.HandlerFunc{
+		"status": func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+		},
+		"short": func(w http.ResponseWriter, _ *http.Request) {
+			_ = json.NewEncoder(w).Encode([]teiRerankResult{{Index: 0, Score: 0.5}})
+		},
+		"bad index": func(w http.ResponseWriter, _ *http.Request) {
+			_ = json.NewEncoder(w).Encode([]teiRerankResult{{Index: 0, Score: 0.5}, {Index: 0, Score: 0.4}})
+		},
+	}
+	for name, h := range cases {
+		srv := httptest.NewServer(h)
+		if _, err := NewTEIReranker(srv.URL).Rerank(context.Background(), "q", []string{"a", "b"}); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+		srv.Close()
+	}
+}
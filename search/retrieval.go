@@ -0,0 +1,312 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Retrieval selects the candidate source a backend ranks a query with.
+type Retrieval string
+
+const (
+	// RetrieveDefault is the backend's usual ranking: vector similarity, or
+	// its hybrid when SearchFilter.Alpha asks for one.
+	RetrieveDefault Retrieval = ""
+	// RetrieveDense ranks by vector similarity alone; Alpha is ignored.
+	RetrieveDense Retrieval = "dense"
+	// RetrieveLexical ranks by keyword relevance alone (BM25 or the
+	// backend's full-text index); Alpha is ignored.
+	RetrieveLexical Retrieval = "lexical"
+)
+
+// ErrRetrievalUnsupported is returned when a backend cannot serve the
+// requested SearchFilter.Retrieval mode (e.g. lexical retrieval on Pinecone,
+// which only stores dense vectors).
+var ErrRetrievalUnsupported = errors.New("retrieval mode not supported by this search backend")
+
+// Defaults for RetrievalPipeline fields left at zero.
+const (
+	DefaultRRFK             = 60
+	DefaultFusionCandidates = 50
+	DefaultRerankTopN       = 50
+)
+
+// ReviewRetriever, BusinessRetriever and MenuRetriever are the search
+// surfaces a RetrievalPipeline runs over; every Searcher and MenuStore
+// satisfies the matching one.
+type (
+	ReviewRetriever interface {
+		Reviews(ctx context.Context, query string, limit int, filter SearchFilter) (SearchReviews, error)
+	}
+	BusinessRetriever interface {
+		Businesses(ctx context.Context, query string, limit int, filter SearchFilter) (SearchResult, error)
+	}
+	MenuRetriever interface {
+		SearchMenu(ctx context.Context, query string, limit int, filter SearchFilter) ([]MenuItem, error)
+	}
+)
+
+// RetrievalPipeline ranks a query independently of the backend's own
+// hybrid: with Fuse it retrieves dense and lexical candidates separately and
+// merges them by reciprocal-rank fusion, and with a Reranker it re-scores the
+// top candidates with a cross-encoder. Searches without a query (fetches by
+// ID or business) pass straight through, as does everything on a nil
+// pipeline, so callers can hold an optional *RetrievalPipeline and always
+// call through it.
+//
+// Scores on the results are the reranker's when one ran, otherwise the RRF
+// score (a sum of 1/(k+rank), well below 1); they order results but are not
+// comparable with a backend's certainty.
+type RetrievalPipeline struct {
+	// Fuse retrieves dense and lexical candidates and fuses them; otherwise
+	// the backend's default ranking supplies the candidates. A backend
+	// without lexical retrieval contributes its dense list alone.
+	Fuse bool
+	// RRFK is the RRF rank constant; larger values flatten the advantage of
+	// the top ranks. Default DefaultRRFK.
+	RRFK int
+	// Candidates is how many results each retriever contributes; never fewer
+	// than the requested limit. Default DefaultFusionCandidates.
+	Candidates int
+	// Reranker, if set, re-scores the top RerankTopN candidates (at least
+	// the requested limit). A failing reranker is logged and the fused order
+	// kept.
+	Reranker   Reranker
+	RerankTopN int
+}
+
+// Reviews runs a review search through the pipeline.
+func (p *RetrievalPipeline) Reviews(ctx context.Context, r ReviewRetriever, query string, limit int, filter SearchFilter) (SearchReviews, error) {
+	if p == nil || query == "" {
+		return r.Reviews(ctx, query, limit, filter)
+	}
+	reviews, scores, err := runPipeline(ctx, p, query, limit, filter, candidateSource[RankedReview]{
+		retrieve: func(ctx context.Context, n int, f SearchFilter) ([]RankedReview, error) {
+			res, err := r.Reviews(ctx, query, n, f)
+			return res.BusinessReviews, err
+		},
+		key: func(rr RankedReview) string { return rr.Review.Review.ReviewID },
+		text: func(rr RankedReview) string {
+			if rr.MatchedChunk != "" {
+				return rr.MatchedChunk
+			}
+			return rr.Review.Review.Text
+		},
+	})
+	if err != nil {
+		return SearchReviews{}, err
+	}
+	for i := range reviews {
+		reviews[i].Score = scores[i]
+	}
+	return SearchReviews{BusinessReviews: reviews}, nil
+}
+
+// Businesses runs a business search through the pipeline. The reranker sees
+// each business's name, categories and location, the text a business result
+// carries.
+func (p *RetrievalPipeline) Businesses(ctx context.Context, r BusinessRetriever, query string, limit int, filter SearchFilter) (SearchResult, error) {
+	if p == nil || query == "" {
+		return r.Businesses(ctx, query, limit, filter)
+	}
+	businesses, scores, err := runPipeline(ctx, p, query, limit, filter, candidateSource[BusinessResult]{
+		retrieve: func(ctx context.Context, n int, f SearchFilter) ([]BusinessResult, error) {
+			res, err := r.Businesses(ctx, query, n, f)
+			return res.Businesses, err
+		},
+		key: func(b BusinessResult) string { return b.ID.String() },
+		text: func(b BusinessResult) string {
+			return joinNonEmpty(". ", b.Name, b.Categories, joinNonEmpty(", ", b.City, b.State))
+		},
+	})
+	if err != nil {
+		return SearchResult{}, err
+	}
+	for i := range businesses {
+		businesses[i].Score = scores[i]
+	}
+	return SearchResult{Businesses: businesses}, nil
+}
+
+// SearchMenu runs a menu search through the pipeline. A geo filter asking
+// for distance order still gets it, applied after fusion.
+func (p *RetrievalPipeline) SearchMenu(ctx context.Context, r MenuRetriever, query string, limit int, filter SearchFilter) ([]MenuItem, error) {
+	if p == nil || query == "" {
+		return r.SearchMenu(ctx, query, limit, filter)
+	}
+	items, _, err := runPipeline(ctx, p, query, limit, filter, candidateSource[MenuItem]{
+		retrieve: func(ctx context.Context, n int, f SearchFilter) ([]MenuItem, error) {
+			return r.SearchMenu(ctx, query, n, f)
+		},
+		key: func(m MenuItem) string { return m.MenuItemID },
+		text: func(m MenuItem) string {
+			// The English translation lets an English-only cross-encoder
+			// judge a foreign-language menu.
+			return joinNonEmpty(". ", m.DishName, m.Description, strings.Join(m.StatedIngredients, ", "),
+				m.DishNameEn, m.DescriptionEn, strings.Join(m.StatedIngredientsEn, ", "))
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if filter.Geo != nil && filter.Geo.SortByDistance {
+		SortMenuByDistance(items)
+	}
+	return items, nil
+}
+
+// candidateSource adapts one search surface to runPipeline: how to retrieve
+// n candidates under a filter, how to identify a candidate across retrievers,
+// and the text the reranker scores.
+type candidateSource[T any] struct {
+	retrieve func(ctx context.Context, n int, filter SearchFilter) ([]T, error)
+	key      func(T) string
+	text     func(T) string
+}
+
+// runPipeline returns up to limit candidates in pipeline order with their
+// scores.
+func runPipeline[T any](ctx context.Context, p *RetrievalPipeline, query string, limit int, filter SearchFilter, src candidateSource[T]) ([]T, []float64, error) {
+	n := max(p.Candidates, limit)
+	if p.Candidates <= 0 {
+		n = max(DefaultFusionCandidates, limit)
+	}
+
+	var lists [][]T
+	if p.Fuse {
+		var err error
+		if lists, err = retrieveDenseAndLexical(ctx, n, filter, src.retrieve); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		list, err := src.retrieve(ctx, n, filter)
+		if err != nil {
+			return nil, nil, err
+		}
+		lists = [][]T{list}
+	}
+	k := p.RRFK
+	if k <= 0 {
+		k = DefaultRRFK
+	}
+	// A single list fuses to itself, ranked by its own order.
+	items, scores := fuseRRF(k, src.key, lists...)
+
+	if p.Reranker != nil && len(items) > 0 {
+		topN := p.RerankTopN
+		if topN <= 0 {
+			topN = DefaultRerankTopN
+		}
+		items, scores = rerankTop(ctx, p.Reranker, query, min(len(items), max(topN, limit)), items, scores, src.text)
+	}
+
+	if len(items) > limit {
+		items, scores = items[:limit], scores[:limit]
+	}
+	return items, scores, nil
+}
+
+// retrieveDenseAndLexical runs the dense and lexical retrievers concurrently
+// and returns the lists that came back, dense first. Lexical retrieval is
+// best-effort: a backend without it, or a failing keyword query, leaves the
+// dense list alone. A dense failure fails the search.
+func retrieveDenseAndLexical[T any](ctx context.Context, n int, filter SearchFilter, retrieve func(context.Context, int, SearchFilter) ([]T, error)) ([][]T, error) {
+	denseFilter, lexicalFilter := filter, filter
+	denseFilter.Retrieval, denseFilter.Alpha = RetrieveDense, 0
+	lexicalFilter.Retrieval, lexicalFilter.Alpha = RetrieveLexical, 0
+
+	var dense, lexical []T
+	var denseErr, lexicalErr error
+	var wg sync.WaitGroup
+	wg.Go(func() { dense, denseErr = retrieve(ctx, n, denseFilter) })
+	wg.Go(func() { lexical, lexicalErr = retrieve(ctx, n, lexicalFilter) })
+	wg.Wait()
+
+	if denseErr != nil {
+		return nil, denseErr
+	}
+	if lexicalErr != nil {
+		if !errors.Is(lexicalErr, ErrRetrievalUnsupported) {
+			slog.Warn("lexical retrieval failed; using dense candidates only", "error", lexicalErr)
+		}
+		return [][]T{dense}, nil
+	}
+	return [][]T{dense, lexical}, nil
+}
+
+// fuseRRF merges ranked lists by reciprocal-rank fusion: an item scores the
+// sum of 1/(k+rank) over the lists it appears in (rank counted from 1), so
+// agreement between retrievers outweighs a high rank in only one. Items are
+// identified by key and keep the value of their first appearance; ties keep
+// first-appearance order.
+func fuseRRF[T any](k int, key func(T) string, lists ...[]T) ([]T, []float64) {
+	index := make(map[string]int)
+	var items []T
+	var scores []float64
+	for _, list := range lists {
+		for rank, item := range list {
+			id := key(item)
+			i, ok := index[id]
+			if !ok {
+				i = len(items)
+				index[id] = i
+				items = append(items, item)
+				scores = append(scores, 0)
+			}
+			scores[i] += 1 / float64(k+rank+1)
+		}
+	}
+	return sortByScore(items, scores)
+}
+
+// rerankTop re-scores the first top items with the reranker and reorders
+// them by that score; items past top keep their order and score behind them.
+// On a reranker error the input is returned unchanged.
+func rerankTop[T any](ctx context.Context, rr Reranker, query string, top int, items []T, scores []float64, text func(T) string) ([]T, []float64) {
+	texts := make([]string, top)
+	for i := range top {
+		texts[i] = text(items[i])
+	}
+	rerankScores, err := rr.Rerank(ctx, query, texts)
+	if err == nil && len(rerankScores) != top {
+		err = fmt.Errorf("reranker returned %d scores for %d texts", len(rerankScores), top)
+	}
+	if err != nil {
+		slog.Warn("rerank failed; keeping retrieval order", "error", err)
+		return items, scores
+	}
+	head, headScores := sortByScore(items[:top:top], rerankScores)
+	return append(head, items[top:]...), append(headScores, scores[top:]...)
+}
+
+// sortByScore returns items and scores reordered by descending score,
+// stable for ties. The inputs are not modified.
+func sortByScore[T any](items []T, scores []float64) ([]T, []float64) {
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+	outItems := make([]T, len(items))
+	outScores := make([]float64, len(items))
+	for i, j := range order {
+		outItems[i], outScores[i] = items[j], scores[j]
+	}
+	return outItems, outScores
+}
+
+// joinNonEmpty joins the non-empty parts with sep.
+func joinNonEmpty(sep string, parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, sep)
+}
@@ -0,0 +1,207 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"fodmap/data/schemas"
+	"fodmap/geo"
+)
+
+// stubReviewRetriever serves fixed review IDs per retrieval mode and records
+// the filters it was called with.
+type stubReviewRetriever struct {
+	byMode  map[Retrieval][]string
+	errs    map[Retrieval]error
+	filters []SearchFilter
+}
+
+func (s *stubReviewRetriever) Reviews(_ context.Context, _ string, limit int, filter SearchFilter) (SearchReviews, error) {
+	s.filters = append(s.filters, filter)
+	if err := s.errs[filter.Retrieval]; err != nil {
+		return SearchReviews{}, err
+	}
+	var res SearchReviews
+	for _, id := range s.byMode[filter.Retrieval] {
+		res.BusinessReviews = append(res.BusinessReviews, RankedReview{
+			Review:       IndexItem{Review: schemas.Review{ReviewID: id, Text: "full " + id}},
+			MatchedChunk: "chunk " + id,
+			Score:        0.9,
+		})
+	}
+	if len(res.BusinessReviews) > limit {
+		res.BusinessReviews = res.BusinessReviews[:limit]
+	}
+	return res, nil
+}
+
+// stubReranker scores texts from a fixed table.
+type stubReranker struct {
+	scores map[string]float64
+	err    error
+	texts  []string
+}
+
+func (s *stubReranker) Rerank(_ context.Context, _ string, texts []string) ([]float64, error) {
+	s.texts = texts
+	if s.err != nil {
+		return nil, s.err
+	}
+	out := make([]float64, len(texts))
+	for i, t := range texts {
+		out[i] = s.scores[t]
+	}
+	return out, nil
+}
+
+func reviewIDs(res SearchReviews) []string {
+	var ids []string
+	for _, r := range res.BusinessReviews {
+		ids = append(ids, r.Review.Review.ReviewID)
+	}
+	return ids
+}
+
+func TestFuseRRF(t *testing.T) {
+	id := func(s string) string { return s }
+	items, scores := fuseRRF(60, id, []string{"a", "b", "c"}, []string{"c", "d", "a"})
+	// a: 1/61 + 1/63, c: 1/63 + 1/61 (tie, a seen first), b: 1/62, d: 1/62.
+	if want := []string{"a", "c", "b", "d"}; !reflect.DeepEqual(items, want) {
+		t.Errorf("fused order = %v, want %v", items, want)
+	}
+	if scores[0] != 1.0/61+1.0/63 {
+		t.Errorf("score of a = %v, want %v", scores[0], 1.0/61+1.0/63)
+	}
+}
+
+func TestRetrievalPipeline_Reviews_Fuse(t *testing.T) {
+	r := &stubReviewRetriever{byMode: map[Retrieval][]string{
+		RetrieveDense:   {"r1", "r2", "r3"},
+		RetrieveLexical: {"r3", "r4"},
+	}}
+	p := &RetrievalPipeline{Fuse: true}
+
+	res, err := p.Reviews(context.Background(), r, "low fodmap", 3, SearchFilter{City: "Boston", Alpha: 0.5})
+	if err != nil {
+		t.Fatalf("Reviews: %v", err)
+	}
+	// r3 is in both lists and overtakes the dense-only leader.
+	if got, want := reviewIDs(res), []string{"r3", "r1", "r2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("reviews = %v, want %v", got, want)
+	}
+	if len(r.filters) != 2 {
+		t.Fatalf("retriever called %d times, want 2", len(r.filters))
+	}
+	for _, f := range r.filters {
+		if f.City != "Boston" || f.Alpha != 0 || f.Retrieval == RetrieveDefault {
+			t.Errorf("retriever filter = %+v, want city kept, alpha cleared and a retrieval mode", f)
+		}
+	}
+}
+
+func TestRetrievalPipeline_Reviews_LexicalUnsupported(t *testing.T) {
+	r := &stubReviewRetriever{
+		byMode: map[Retrieval][]string{RetrieveDense: {"r1", "r2"}},
+		errs:   map[Retrieval]error{RetrieveLexical: ErrRetrievalUnsupported},
+	}
+	p := &RetrievalPipeline{Fuse: true}
+
+	res, err := p.Reviews(context.Background(), r, "low fodmap", 5, SearchFilter{})
+	if err != nil {
+		t.Fatalf("Reviews: %v", err)
+	}
+	if got, want := reviewIDs(res), []string{"r1", "r2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("reviews = %v, want dense order %v", got, want)
+	}
+}
+
+func TestRetrievalPipeline_Reviews_DenseError(t *testing.T) {
+	r := &stubReviewRetriever{errs: map[Retrieval]error{RetrieveDense: errors.New("boom")}}
+	p := &RetrievalPipeline{Fuse: true}
+	if _, err := p.Reviews(context.Background(), r, "low fodmap", 5, SearchFilter{}); err == nil {
+		t.Fatal("expected the dense retriever's error")
+	}
+}
+
+func TestRetrievalPipeline_Reviews_Rerank(t *testing.T) {
+	r := &stubReviewRetriever{byMode: map[Retrieval][]string{RetrieveDefault: {"r1", "r2", "r3"}}}
+	rr := &stubReranker{scores: map[string]float64{"chunk r1": 0.1, "chunk r2": 0.2, "chunk r3": 0.9}}
+	p := &RetrievalPipeline{Reranker: rr, RerankTopN: 2}
+
+	res, err := p.Reviews(context.Background(), r, "low fodmap", 2, SearchFilter{})
+	if err != nil {
+		t.Fatalf("Reviews: %v", err)
+	}
+	// Only the top two candidates are reranked; r3 stays behind them.
+	if want := []string{"chunk r1", "chunk r2"}; !reflect.DeepEqual(rr.texts, want) {
+		t.Errorf("reranked texts = %v, want %v", rr.texts, want)
+	}
+	if got, want := reviewIDs(res), []string{"r2", "r1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("reviews = %v, want %v", got, want)
+	}
+	if res.BusinessReviews[0].Score != 0.2 {
+		t.Errorf("score = %v, want the reranker's 0.2", res.BusinessReviews[0].Score)
+	}
+}
+
+func TestRetrievalPipeline_Reviews_RerankFailureKeepsOrder(t *testing.T) {
+	r := &stubReviewRetriever{byMode: map[Retrieval][]string{RetrieveDefault: {"r1", "r2"}}}
+	p := &RetrievalPipeline{Reranker: &stubReranker{err: errors.New("down")}}
+
+	res, err := p.Reviews(context.Background(), r, "low fodmap", 2, SearchFilter{})
+	if err != nil {
+		t.Fatalf("Reviews: %v", err)
+	}
+	if got, want := reviewIDs(res), []string{"r1", "r2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("reviews = %v, want %v", got, want)
+	}
+}
+
+func TestRetrievalPipeline_PassThrough(t *testing.T) {
+	r := &stubReviewRetriever{byMode: map[Retrieval][]string{RetrieveDefault: {"r1"}}}
+	var nilPipeline *RetrievalPipeline
+	for name, p := range map[string]*RetrievalPipeline{"nil pipeline": nilPipeline, "empty query": {Fuse: true}} {
+		r.filters = nil
+		res, err := p.Reviews(context.Background(), r, "", 5, SearchFilter{Alpha: 0.5})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(res.BusinessReviews) != 1 || res.BusinessReviews[0].Score != 0.9 {
+			t.Errorf("%s: got %+v, want the backend's result untouched", name, res.BusinessReviews)
+		}
+		if len(r.filters) != 1 || r.filters[0].Alpha != 0.5 {
+			t.Errorf("%s: filters = %+v, want one call with the caller's filter", name, r.filters)
+		}
+	}
+	// A non-empty query on a nil pipeline also goes straight to the backend.
+	r.filters = nil
+	if _, err := nilPipeline.Reviews(context.Background(), r, "pasta", 5, SearchFilter{}); err != nil || len(r.filters) != 1 {
+		t.Errorf("nil pipeline: err=%v calls=%d, want one backend call", err, len(r.filters))
+	}
+}
+
+// stubMenuRetriever serves fixed menu items per retrieval mode.
+type stubMenuRetriever map[Retrieval][]MenuItem
+
+func (s stubMenuRetriever) SearchMenu(_ context.Context, _ string, _ int, filter SearchFilter) ([]MenuItem, error) {
+	return s[filter.Retrieval], nil
+}
+
+func TestRetrievalPipeline_SearchMenu_SortByDistance(t *testing.T) {
+	dist := func(d float64) *float64 { return &d }
+	far := MenuItem{MenuItemID: "far", DistanceMeters: dist(900)}
+	near := MenuItem{MenuItemID: "near", DistanceMeters: dist(100)}
+	r := stubMenuRetriever{RetrieveDense: {far, near}, RetrieveLexical: {far}}
+	p := &RetrievalPipeline{Fuse: true}
+
+	filter := SearchFilter{Geo: &GeoFilter{Origin: &geo.Point{Lat: 42.36, Lon: -71.06}, RadiusMeters: 1000, SortByDistance: true}}
+	items, err := p.SearchMenu(context.Background(), r, "risotto", 5, filter)
+	if err != nil {
+		t.Fatalf("SearchMenu: %v", err)
+	}
+	if len(items) != 2 || items[0].MenuItemID != "near" {
+		t.Errorf("items = %+v, want the fused set nearest first", items)
+	}
+}
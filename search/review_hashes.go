@@ -0,0 +1,231 @@
+package search
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/lib/pq"
+)
+
+// ReviewHashStore remembers the content hash each review was last indexed
+// with, per index target, so re-running `index` over a source skips the
+// reviews it has already indexed unchanged. PostgresReviewHashes and
+// LocalReviewHashes implement it.
+type ReviewHashStore interface {
+	// LoadReviewHashes returns the stored hashes of the ids it has.
+	LoadReviewHashes(ctx context.Context, target string, ids []string) (map[string]string, error)
+	// StoreReviewHashes saves hashes by review ID.
+	StoreReviewHashes(ctx context.Context, target string, hashes map[string]string) error
+	Close() error
+}
+
+// ReviewContentHash returns the hex SHA-256 of everything indexing item
+// into a backend depends on: the review's text and stars, its business
+// metadata and the chunking parameters. Chunks and vectors are derived from
+// these, so they are not hashed.
+func ReviewContentHash(item IndexItem, chunkSize, overlap int) string {
+	h := sha256.New()
+	business := ""
+	if item.BusinessUUID != nil {
+		business = item.BusinessUUID.String()
+	}
+	for _, f := range []string{
+		item.Review.ReviewID,
+		item.Review.BusinessID,
+		business,
+		item.BusinessName,
+		item.City,
+		item.State,
+		item.Categories,
+		strconv.FormatFloat(float64(item.Review.Stars), 'g', -1, 32),
+		strconv.Itoa(chunkSize),
+		strconv.Itoa(overlap),
+		item.Review.Text,
+	} {
+		// Length-prefixed, so no two field lists hash alike.
+		h.Write([]byte(strconv.Itoa(len(f))))
+		h.Write([]byte{':'})
+		h.Write([]byte(f))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// PostgresReviewHashes keeps review hashes in the review_hashes table.
+type PostgresReviewHashes struct {
+	db *sql.DB
+}
+
+// NewPostgresReviewHashes connects to the database at dsn.
+func NewPostgresReviewHashes(dsn string) (*PostgresReviewHashes, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PostgreSQL database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to ping PostgreSQL database: %w", err)
+	}
+	return &PostgresReviewHashes{db: db}, nil
+}
+
+// LoadReviewHashes implements ReviewHashStore.
+func (s *PostgresReviewHashes) LoadReviewHashes(ctx context.Context, target string, ids []string) (map[string]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT review_id, hash FROM review_hashes WHERE target = $1 AND review_id = ANY($2)
+	`, target, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("query review hashes: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	out := make(map[string]string)
+	for rows.Next() {
+		var id, hash string
+		if err := rows.Scan(&id, &hash); err != nil {
+			return nil, fmt.Errorf("scan review hash: %w", err)
+		}
+		out[id] = hash
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query review hashes: %w", err)
+	}
+	return out, nil
+}
+
+// StoreReviewHashes implements ReviewHashStore.
+func (s *PostgresReviewHashes) StoreReviewHashes(ctx context.Context, target string, hashes map[string]string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO review_hashes (target, review_id, hash)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (target, review_id) DO UPDATE SET hash = EXCLUDED.hash, indexed_at = NOW()
+	`)
+	if err != nil {
+		return fmt.Errorf("prepare stmt: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+	// Sorted keys keep concurrent writers' row locks in one order.
+	for _, id := range sortedKeys(hashes) {
+		if _, err := stmt.ExecContext(ctx, target, id, hashes[id]); err != nil {
+			return fmt.Errorf("store review hash: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database handle.
+func (s *PostgresReviewHashes) Close() error {
+	return s.db.Close()
+}
+
+// LocalReviewHashes keeps review hashes in memory, persisted to an
+// append-only log in a directory, for runs without Postgres.
+type LocalReviewHashes struct {
+	mu     sync.Mutex
+	log    *localLog
+	hashes map[string]map[string]string // target → review ID → hash
+	live   int
+}
+
+// localReviewHashRecord is one LocalReviewHashes log line.
+type localReviewHashRecord struct {
+	Target   string `json:"t"`
+	ReviewID string `json:"r"`
+	Hash     string `json:"h"`
+}
+
+// NewLocalReviewHashes opens (creating if needed) the hashes kept in dir,
+// compacting the log if it is mostly superseded records.
+func NewLocalReviewHashes(dir string) (*LocalReviewHashes, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create review hash dir: %w", err)
+	}
+	s := &LocalReviewHashes{hashes: make(map[string]map[string]string)}
+	l, err := openLocalLog(filepath.Join(dir, "review_hashes.jsonl"), func(rec localReviewHashRecord) {
+		s.set(rec)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.log = l
+	if l.needsCompaction(s.live) {
+		var recs []any
+		for _, target := range sortedKeys(s.hashes) {
+			byID := s.hashes[target]
+			for _, id := range sortedKeys(byID) {
+				recs = append(recs, localReviewHashRecord{Target: target, ReviewID: id, Hash: byID[id]})
+			}
+		}
+		if err := l.rewrite(recs); err != nil {
+			_ = l.close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *LocalReviewHashes) set(rec localReviewHashRecord) {
+	byID := s.hashes[rec.Target]
+	if byID == nil {
+		byID = make(map[string]string)
+		s.hashes[rec.Target] = byID
+	}
+	if _, ok := byID[rec.ReviewID]; !ok {
+		s.live++
+	}
+	byID[rec.ReviewID] = rec.Hash
+}
+
+// LoadReviewHashes implements ReviewHashStore.
+func (s *LocalReviewHashes) LoadReviewHashes(_ context.Context, target string, ids []string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]string)
+	byID := s.hashes[target]
+	for _, id := range ids {
+		if h, ok := byID[id]; ok {
+			out[id] = h
+		}
+	}
+	return out, nil
+}
+
+// StoreReviewHashes implements ReviewHashStore.
+func (s *LocalReviewHashes) StoreReviewHashes(_ context.Context, target string, hashes map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	recs := make([]any, 0, len(hashes))
+	for _, id := range sortedKeys(hashes) {
+		if s.hashes[target][id] == hashes[id] {
+			continue
+		}
+		recs = append(recs, localReviewHashRecord{Target: target, ReviewID: id, Hash: hashes[id]})
+	}
+	if err := s.log.append(recs...); err != nil {
+		return err
+	}
+	for _, r := range recs {
+		s.set(r.(localReviewHashRecord))
+	}
+	return nil
+}
+
+// Close closes the log.
+func (s *LocalReviewHashes) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.log.close()
+}
@@ -0,0 +1,69 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"fodmap/data/schemas"
+)
+
+func TestReviewContentHash(t *testing.T) {
+	item := IndexItem{
+		Review:       schemas.Review{ReviewID: "r1", BusinessID: "b1", Stars: 4, Text: "Great pho", Useful: 1},
+		BusinessName: "Pho 88",
+		City:         "Philadelphia",
+	}
+	base := ReviewContentHash(item, 800, 100)
+
+	votes := item
+	votes.Review.Useful = 7
+	if ReviewContentHash(votes, 800, 100) != base {
+		t.Error("a vote count change should not change the hash")
+	}
+	for name, changed := range map[string]IndexItem{
+		"text":  {Review: schemas.Review{ReviewID: "r1", BusinessID: "b1", Stars: 4, Text: "Great pho!"}, BusinessName: "Pho 88", City: "Philadelphia"},
+		"stars": {Review: schemas.Review{ReviewID: "r1", BusinessID: "b1", Stars: 5, Text: "Great pho"}, BusinessName: "Pho 88", City: "Philadelphia"},
+		"city":  {Review: schemas.Review{ReviewID: "r1", BusinessID: "b1", Stars: 4, Text: "Great pho"}, BusinessName: "Pho 88", City: "Tampa"},
+	} {
+		if ReviewContentHash(changed, 800, 100) == base {
+			t.Errorf("a %s change should change the hash", name)
+		}
+	}
+	if ReviewContentHash(item, 400, 100) == base {
+		t.Error("a chunk size change should change the hash")
+	}
+}
+
+func TestLocalReviewHashes_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	s, err := NewLocalReviewHashes(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.StoreReviewHashes(ctx, "local/a", map[string]string{"r1": "h1", "r2": "h2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.StoreReviewHashes(ctx, "local/a", map[string]string{"r1": "h1b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.StoreReviewHashes(ctx, "local/b", map[string]string{"r1": "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = NewLocalReviewHashes(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+	got, err := s.LoadReviewHashes(ctx, "local/a", []string{"r1", "r2", "r3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got["r1"] != "h1b" || got["r2"] != "h2" {
+		t.Errorf("hashes = %v, want r1=h1b r2=h2", got)
+	}
+}
@@ -0,0 +1,130 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"fodmap/data"
+	"fodmap/data/schemas"
+)
+
+// reviewSourcePage is how many rows PostgresReviewSource reads per query.
+const reviewSourcePage = 1000
+
+// PostgresReviewSource reads the reviews table as a data.ReviewSource, in
+// (updated_at, review_id) order so a delta loaded into the table, or a
+// review edited in it, sorts after the position an earlier run stopped at.
+// Positions are "<updated_at RFC 3339>|<review_id>". A row committed with an
+// updated_at older than a position already read is missed; load deltas
+// before indexing them rather than while.
+type PostgresReviewSource struct {
+	ctx     context.Context
+	db      *sql.DB
+	after   time.Time
+	afterID string
+	page    []data.SourceRecord
+	done    bool
+}
+
+// NewPostgresReviewSource connects to the database at dsn and reads the
+// reviews after position, or all of them when position is empty.
+func NewPostgresReviewSource(ctx context.Context, dsn, position string) (*PostgresReviewSource, error) {
+	s := &PostgresReviewSource{ctx: ctx}
+	if position != "" {
+		ts, id, ok := strings.Cut(position, "|")
+		t, err := time.Parse(time.RFC3339Nano, ts)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("bad reviews table position %q: want <RFC 3339 time>|<review_id>", position)
+		}
+		s.after, s.afterID = t, id
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PostgreSQL database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to ping PostgreSQL database: %w", err)
+	}
+	// Rows stored before updated_at existed have none and would never be
+	// read; refuse rather than index the table partially.
+	var unset bool
+	if err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM reviews WHERE updated_at IS NULL)`).Scan(&unset); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("query reviews: %w", err)
+	}
+	if unset {
+		_ = db.Close()
+		return nil, fmt.Errorf("reviews table has rows without updated_at: run `fodmap-detector search backfill-review-updated-at` first")
+	}
+	s.db = db
+	return s, nil
+}
+
+// Next implements data.ReviewSource.
+func (s *PostgresReviewSource) Next() (data.SourceRecord, error) {
+	if len(s.page) == 0 && !s.done {
+		if err := s.fetch(); err != nil {
+			return data.SourceRecord{}, err
+		}
+	}
+	if len(s.page) == 0 {
+		return data.SourceRecord{}, io.EOF
+	}
+	rec := s.page[0]
+	s.page = s.page[1:]
+	return rec, nil
+}
+
+// fetch reads the next page of reviews after the last one read.
+func (s *PostgresReviewSource) fetch() error {
+	rows, err := s.db.QueryContext(s.ctx, `
+		SELECT review_id, business_id::text, business_name, city, state, categories, stars, text, updated_at
+		FROM reviews
+		WHERE (updated_at, review_id) > ($1, $2)
+		ORDER BY updated_at, review_id
+		LIMIT $3
+	`, s.after, s.afterID, reviewSourcePage)
+	if err != nil {
+		return fmt.Errorf("query reviews: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var (
+			r                                        schemas.Review
+			businessID, name, city, state, cats, txt sql.NullString
+			stars                                    sql.NullFloat64
+			updated                                  time.Time
+		)
+		if err := rows.Scan(&r.ReviewID, &businessID, &name, &city, &state, &cats, &stars, &txt, &updated); err != nil {
+			return fmt.Errorf("scan review: %w", err)
+		}
+		r.BusinessID, r.Stars, r.Text = businessID.String, float32(stars.Float64), txt.String
+		s.after, s.afterID = updated, r.ReviewID
+		s.page = append(s.page, data.SourceRecord{
+			Review: &r,
+			Business: &schemas.Business{
+				BusinessID: businessID.String,
+				Name:       name.String,
+				City:       city.String,
+				State:      state.String,
+				Categories: cats.String,
+			},
+			Position: updated.UTC().Format(time.RFC3339Nano) + "|" + r.ReviewID,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("query reviews: %w", err)
+	}
+	s.done = len(s.page) < reviewSourcePage
+	return nil
+}
+
+// Close closes the database handle.
+func (s *PostgresReviewSource) Close() error {
+	return s.db.Close()
+}
@@ -1,6 +1,7 @@
 WITH chunk_scores AS (
-    -- Score every chunk against the query vector, tagging each with its rank
-    -- within its parent review so we can discard weaker chunks below.
+    -- Score every chunk against the query (vector similarity, or full-text
+    -- rank for a lexical search), tagging each with its rank within its
+    -- parent review so we can discard weaker chunks below.
     SELECT
         r.business_id,
         r.business_name,
@@ -9,10 +10,10 @@ WITH chunk_scores AS (
         r.categories,
         r.stars,
         r.review_id,
-        (1 - (rc.embedding <=> $1))                                    AS certainty,
+        {{.Score}}                                                      AS certainty,
         ROW_NUMBER() OVER (
             PARTITION BY r.review_id
-            ORDER BY     rc.embedding <=> $1
+            ORDER BY     {{.Score}} DESC
         )                                                               AS chunk_rn
     FROM  review_chunks rc
     JOIN  reviews r ON rc.review_id = r.review_id
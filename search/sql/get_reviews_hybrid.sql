@@ -0,0 +1,26 @@
+WITH candidates AS (
+    -- Closest chunks by vector ...
+    (SELECT rc.chunk_id
+     FROM   review_chunks rc
+     JOIN   reviews r ON rc.review_id = r.review_id
+     {{.Where}}
+     ORDER  BY rc.embedding <=> $1
+     LIMIT  {{.LimitArg}})
+{{- if .Keyword}}
+    UNION
+    -- ... plus the best full-text matches, which the vector ranking may miss.
+    (SELECT rc.chunk_id
+     FROM   review_chunks rc
+     JOIN   reviews r ON rc.review_id = r.review_id
+     {{.Keyword}}
+     ORDER  BY ts_rank(rc.chunk_tsv, to_tsquery('simple', {{.KeywordArg}})) DESC
+     LIMIT  {{.LimitArg}})
+{{- end}}
+)
+-- Every candidate chunk with its vector certainty; the caller blends in BM25
+-- and keeps the best chunk per review.
+SELECT r.review_id, r.business_id, r.business_name, r.city, r.state, r.text, rc.chunk_text,
+       (1 - (rc.embedding <=> $1)) AS certainty
+FROM   candidates c
+JOIN   review_chunks rc ON rc.chunk_id = c.chunk_id
+JOIN   reviews r ON rc.review_id = r.review_id
@@ -0,0 +1,10 @@
+-- Best full-text matching chunks, with their full-text rank in place of a
+-- certainty; the caller scores each with BM25 and keeps the best chunk per
+-- review.
+SELECT r.review_id, r.business_id, r.business_name, r.city, r.state, r.text, rc.chunk_text,
+       ts_rank(rc.chunk_tsv, to_tsquery('simple', {{.KeywordArg}})) AS rank
+FROM   review_chunks rc
+JOIN   reviews r ON rc.review_id = r.review_id
+{{.Where}}
+ORDER  BY rank DESC
+LIMIT  {{.LimitArg}}
@@ -0,0 +1,34 @@
+-- Menu items with their restaurant's coordinates. The WHERE clause carries
+-- the city/state/business and geo filters (and the full-text match of a
+-- lexical search); ORDER BY is vector relevance or full-text rank when there
+-- is a query, otherwise KNN distance from the geo origin (served by the
+-- earthdistance GiST index) or recency. Items without a restaurants row
+-- are still returned with NULL coordinates; the geo clauses drop them.
+SELECT
+    m.menu_item_id,
+    m.business_id,
+    m.menu_section,
+    m.restaurant_name,
+    m.city,
+    m.state,
+    m.dish_name,
+    m.description,
+    m.price,
+    m.stated_ingredients,
+    m.has_full_ingredients,
+    m.modifiers,
+    m.source_url,
+    m.address,
+    m.phone_number,
+    m.scraped_at,
+    m.language,
+    m.dish_name_en,
+    m.description_en,
+    m.stated_ingredients_en,
+    r.latitude,
+    r.longitude
+FROM  menu_items m
+LEFT JOIN restaurants r ON r.id = m.business_id
+{{.Where}}
+ORDER BY {{.OrderBy}}
+LIMIT {{.LimitArg}}
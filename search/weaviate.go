@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
 	"slices"
 	"sort"
@@ -13,6 +14,7 @@ import (
 
 	"fodmap/data"
 	"fodmap/data/schemas"
+	"fodmap/geo"
 
 	"github.com/go-openapi/strfmt"
 	"github.com/google/uuid"
@@ -59,6 +61,25 @@ type MenuItem struct {
 	PhoneNumber        string
 	ScrapedAt          string
 	Vector             []float32
+	// EmbeddingSpace is the Postgres embedding space Vector was embedded
+	// with, when a SpaceEmbedder embedded it; 0 when unknown.
+	EmbeddingSpace int64
+
+	// Language is the ISO 639-1 code of the menu's text ("" when unknown).
+	// For menus not in English, the *En fields hold the English translation
+	// of DishName, Description and StatedIngredients when one was made.
+	Language            string
+	DishNameEn          string
+	DescriptionEn       string
+	StatedIngredientsEn []string
+
+	// Latitude and Longitude locate the restaurant. They are written to
+	// Weaviate's geo index; Postgres reads them from the restaurants row.
+	Latitude  *float64
+	Longitude *float64
+	// DistanceMeters is set on search results when the filter has a
+	// GeoFilter origin.
+	DistanceMeters *float64
 }
 
 // Client wraps the Weaviate client with domain-specific operations.
@@ -78,6 +99,20 @@ type BusinessResult struct {
 	Score      float64
 }
 
+// Key identifies the business across backends, whose IDs differ (Postgres
+// returns its restaurants.id, the other backends uuid.Nil for a Yelp
+// business): its name, city and state, case-folded, joined by "|".
+func (b BusinessResult) Key() string {
+	return BusinessKey(b.Name, b.City, b.State)
+}
+
+// BusinessKey is the BusinessResult.Key of a business with the given name,
+// city and state.
+func BusinessKey(name, city, state string) string {
+	fold := func(s string) string { return strings.ToLower(strings.Join(strings.Fields(s), " ")) }
+	return fold(name) + "|" + fold(city) + "|" + fold(state)
+}
+
 // SearchResult holds the ranked list of businesses returned by a search query.
 type SearchResult struct {
 	Businesses []BusinessResult
@@ -99,18 +134,30 @@ type SearchReviews struct {
 
 // SearchFilter holds optional filters to narrow the search by category, city, and/or state.
 type SearchFilter struct {
-	Category   string    // substring match against categories field; empty = no filter
-	City       string    // exact match; empty = no filter
-	State      string    // exact match; empty = no filter
-	BusinessID uuid.UUID // exact match; uuid.Nil = no filter
-	ReviewIDs  []string  // exact match for any of the provided IDs; empty = no filter
-	Alpha      float32   // hybrid search balance: 0 = pure vector (nearText), >0 enables hybrid (0=pure BM25, 1=pure vector)
+	Category   string     // substring match against categories field; empty = no filter
+	City       string     // exact match; empty = no filter
+	State      string     // exact match; empty = no filter
+	BusinessID uuid.UUID  // exact match; uuid.Nil = no filter
+	ReviewIDs  []string   // exact match for any of the provided IDs; empty = no filter
+	Alpha      float32    // hybrid search balance: 0 = pure vector (nearText), >0 enables hybrid (0=pure BM25, 1=pure vector)
+	Geo        *GeoFilter // radius/bbox around the restaurant's coordinates; nil = no filter
+	Retrieval  Retrieval  // dense or lexical candidates only, overriding Alpha; empty = backend default
+
+	// Dish constraints, as parsed from a query by queryparse. Backends do not
+	// apply them; menu search filters the dishes it gets back.
+	MaxPrice       *float64 // dish price ceiling; nil = no filter
+	MaxFodmapLevel string   // "low", "moderate" or "high"; empty = no filter
+	Avoid          []string // ingredients the dish must not contain
+	Dietary        []string // diets the dish must suit, e.g. "gluten-free"
 }
 
 // Chunk holds a single text chunk and its embedding vector.
 type Chunk struct {
 	Text   string
 	Vector []float32
+	// EmbeddingSpace is the Postgres embedding space Vector was embedded
+	// with, when a SpaceEmbedder embedded it; 0 when unknown.
+	EmbeddingSpace int64
 }
 
 // IndexItem pairs a review with its associated business metadata for indexing.
@@ -125,12 +172,21 @@ type IndexItem struct {
 	Vector       []float32 // legacy: used when Chunks is empty
 	Chunks       []Chunk   // chunked text with per-chunk vectors
 
+	// EmbeddingSpace is the Postgres embedding space Vector was embedded
+	// with, as Chunk.EmbeddingSpace; 0 when unknown.
+	EmbeddingSpace int64
+
 	// BusinessUUID is the restaurants.id surrogate UUID for this review's
 	// business. Populated by the Yelp indexer (cli/index.go) via the
 	// UpsertByYelp union step; nil when no matching restaurants row exists.
 	// The Postgres backend writes this to reviews.business_id (UUID FK);
 	// Weaviate/Pinecone still use Review.BusinessID (string) for storage.
 	BusinessUUID *uuid.UUID
+
+	// UpdatedAt is when the review last changed at its source. The Postgres
+	// backend keeps it as reviews.updated_at, so a review copied from another
+	// Postgres database does not look edited; nil stamps the time of writing.
+	UpdatedAt *time.Time
 }
 
 // RankedReview pairs a review with its certainty score.
@@ -323,8 +379,12 @@ func (c *Client) BatchUpsert(ctx context.Context, items []IndexItem) error {
 
 // Businesses performs a nearText vector query and returns restaurant IDs ranked by
 // Top-K average certainty score (K=topKReviews). Optional filters narrow results
-// by category (substring), city (exact), and state (exact).
+// by category (substring), city (exact), and state (exact). A lexical
+// Retrieval ranks chunks by Weaviate's BM25 instead of the vector.
 func (c *Client) Businesses(ctx context.Context, query string, limit int, filter SearchFilter) (SearchResult, error) {
+	if filter.Geo != nil {
+		return SearchResult{}, ErrGeoFilterUnsupported
+	}
 	fields := []graphql.Field{
 		{Name: "chunkText"},
 		{
@@ -351,7 +411,13 @@ func (c *Client) Businesses(ctx context.Context, query string, limit int, filter
 		WithClassName(chunkCollectionName).
 		WithFields(fields...)
 
-	if query != "" && filter.Alpha > 0 {
+	if query != "" && filter.Retrieval == RetrieveLexical {
+		bm25 := c.wv.GraphQL().Bm25ArgBuilder().
+			WithQuery(query).
+			WithProperties("chunkText")
+		getter = getter.WithBM25(bm25)
+		getter = getter.WithLimit(limit * 20)
+	} else if query != "" && filter.Alpha > 0 && filter.Retrieval == RetrieveDefault {
 		if c.embedder == nil {
 			return SearchResult{}, errors.New("embedder is not configured (required for hybrid search)")
 		}
@@ -403,7 +469,11 @@ func (c *Client) Businesses(ctx context.Context, query string, limit int, filter
 }
 
 // Reviews returns the top reviews from a nearText vector query, sorted by certainty score (descending).
+// A lexical Retrieval ranks chunks by Weaviate's BM25 instead.
 func (c *Client) Reviews(ctx context.Context, query string, limit int, filter SearchFilter) (SearchReviews, error) {
+	if filter.Geo != nil {
+		return SearchReviews{}, ErrGeoFilterUnsupported
+	}
 	fields := []graphql.Field{
 		{Name: "chunkText"},
 		{
@@ -429,7 +499,13 @@ func (c *Client) Reviews(ctx context.Context, query string, limit int, filter Se
 		WithClassName(chunkCollectionName).
 		WithFields(fields...)
 
-	if query != "" && filter.Alpha > 0 {
+	if query != "" && filter.Retrieval == RetrieveLexical {
+		bm25 := c.wv.GraphQL().Bm25ArgBuilder().
+			WithQuery(query).
+			WithProperties("chunkText")
+		getter = getter.WithBM25(bm25)
+		getter = getter.WithLimit(limit * 20)
+	} else if query != "" && filter.Alpha > 0 && filter.Retrieval == RetrieveDefault {
 		if c.embedder == nil {
 			return SearchReviews{}, errors.New("embedder is not configured (required for hybrid search)")
 		}
@@ -1071,6 +1147,11 @@ func (c *Client) EnsureMenuSchema(ctx context.Context) error {
 		{Name: "address", DataType: []string{"text"}},
 		{Name: "phoneNumber", DataType: []string{"text"}},
 		{Name: "scrapedAtUtc", DataType: []string{"text"}},
+		{Name: "location", DataType: []string{"geoCoordinates"}},
+		{Name: "language", DataType: []string{"text"}},
+		{Name: "dishNameEn", DataType: []string{"text"}},
+		{Name: "descriptionEn", DataType: []string{"text"}},
+		{Name: "statedIngredientsEn", DataType: []string{"text[]"}},
 	}
 
 	existing, err := c.wv.Schema().ClassGetter().WithClassName(menuCollectionName).Do(ctx)
@@ -1115,22 +1196,27 @@ func (c *Client) BatchUpsertMenu(ctx context.Context, items []MenuItem) error {
 			ID:     strfmt.UUID(item.MenuItemID),
 			Vector: models.C11yVector(item.Vector),
 			Properties: map[string]any{
-				"menuItemId":         item.MenuItemID,
-				"businessId":         item.BusinessID.String(),
-				"menuSection":        item.MenuSection,
-				"restaurantName":     item.RestaurantName,
-				"city":               item.City,
-				"state":              item.State,
-				"dishName":           item.DishName,
-				"description":        item.Description,
-				"price":              weaviatePrice(item.Price),
-				"statedIngredients":  item.StatedIngredients,
-				"hasFullIngredients": item.HasFullIngredients,
-				"modifiers":          weaviateModifiers(item.Modifiers),
-				"sourceUrl":          item.SourceURL,
-				"address":            item.Address,
-				"phoneNumber":        item.PhoneNumber,
-				"scrapedAt":          item.ScrapedAt,
+				"menuItemId":          item.MenuItemID,
+				"businessId":          item.BusinessID.String(),
+				"menuSection":         item.MenuSection,
+				"restaurantName":      item.RestaurantName,
+				"city":                item.City,
+				"state":               item.State,
+				"dishName":            item.DishName,
+				"description":         item.Description,
+				"price":               weaviatePrice(item.Price),
+				"statedIngredients":   item.StatedIngredients,
+				"hasFullIngredients":  item.HasFullIngredients,
+				"modifiers":           weaviateModifiers(item.Modifiers),
+				"sourceUrl":           item.SourceURL,
+				"address":             item.Address,
+				"phoneNumber":         item.PhoneNumber,
+				"scrapedAt":           item.ScrapedAt,
+				"location":            weaviateLocation(item.Latitude, item.Longitude),
+				"language":            item.Language,
+				"dishNameEn":          item.DishNameEn,
+				"descriptionEn":       item.DescriptionEn,
+				"statedIngredientsEn": item.StatedIngredientsEn,
 			},
 		})
 	}
@@ -1150,41 +1236,226 @@ func (c *Client) BatchUpsertMenu(ctx context.Context, items []MenuItem) error {
 	return nil
 }
 
-// SearchMenu performs a nearVector semantic search over the RestaurantMenu collection.
-func (c *Client) SearchMenu(ctx context.Context, query string, limit int) ([]MenuItem, error) {
-	if c.embedder == nil {
-		return nil, errors.New("embedder is not configured (required for menu search)")
+// DeleteMenuItems removes menu items by MenuItemID. Weaviate keeps no
+// history, so a dish dropped from a menu is deleted outright; the Postgres
+// primary holds its soft-deleted row and change history.
+func (c *Client) DeleteMenuItems(ctx context.Context, ids []string) error {
+	for _, id := range ids {
+		err := c.wv.Data().Deleter().
+			WithClassName(menuCollectionName).
+			WithID(id).
+			Do(ctx)
+		if err != nil {
+			var wErr *fault.WeaviateClientError
+			if errors.As(err, &wErr) && wErr.StatusCode == http.StatusNotFound {
+				continue
+			}
+			return fmt.Errorf("delete menu item %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// MenuLocator returns the coordinates of a menu's restaurant, nil when it
+// has none or is unknown.
+type MenuLocator func(ctx context.Context, businessID uuid.UUID) (lat, lon *float64, err error)
+
+// BackfillMenuLocations sets the location of RestaurantMenu objects stored
+// without one, from locate's coordinates for their restaurant, so that geo
+// filters find them. Objects are read a page of batchSize at a time with
+// their vectors and re-upserted whole. It returns how many were updated;
+// dishes whose restaurant has no coordinates are left as they are.
+func (c *Client) BackfillMenuLocations(ctx context.Context, batchSize int, locate MenuLocator) (int, error) {
+	type point struct{ lat, lon *float64 }
+	located := make(map[uuid.UUID]point)
+	var cursor string
+	updated := 0
+	for {
+		items, next, err := c.ExportMenu(ctx, cursor, batchSize)
+		if err != nil {
+			return updated, err
+		}
+		var fill []MenuItem
+		for _, item := range items {
+			if item.Latitude != nil && item.Longitude != nil || item.BusinessID == uuid.Nil {
+				continue
+			}
+			p, ok := located[item.BusinessID]
+			if !ok {
+				p.lat, p.lon, err = locate(ctx, item.BusinessID)
+				if err != nil {
+					return updated, fmt.Errorf("locating restaurant %s: %w", item.BusinessID, err)
+				}
+				located[item.BusinessID] = p
+			}
+			if p.lat == nil || p.lon == nil {
+				continue
+			}
+			item.Latitude, item.Longitude = p.lat, p.lon
+			fill = append(fill, item)
+		}
+		if len(fill) > 0 {
+			if err := c.BatchUpsertMenu(ctx, fill); err != nil {
+				return updated, err
+			}
+			updated += len(fill)
+		}
+		if next == "" {
+			return updated, nil
+		}
+		cursor = next
 	}
-	vec, err := c.embedder.EmbedSingle(ctx, "search_query: "+query)
+}
+
+// SearchMenu performs a nearVector semantic search over the RestaurantMenu
+// collection, or a BM25 search over the dish text and ingredients (original
+// and English) when Retrieval is lexical. City, state and business filters
+// apply as where clauses. A geo filter becomes a WithinGeoRange clause on the
+// location property — around Origin for a radius, or around the circle
+// enclosing BBox — and results are then checked exactly with
+// GeoFilter.Match. With an empty query and an origin the nearest dishes
+// are returned, nearest first (see nearestMenu); with an empty query and no
+// origin the collection is read in storage order.
+func (c *Client) SearchMenu(ctx context.Context, query string, limit int, filter SearchFilter) ([]MenuItem, error) {
+	if query == "" && filter.Geo != nil && filter.Geo.Origin != nil {
+		return c.nearestMenu(ctx, limit, filter)
+	}
+	items, _, err := c.searchMenuPage(ctx, query, limit, filter)
 	if err != nil {
-		return nil, fmt.Errorf("embedding query: %w", err)
+		return nil, err
+	}
+	if filter.Geo != nil && filter.Geo.SortByDistance {
+		SortMenuByDistance(items)
+	}
+	return items, nil
+}
+
+// nearestMenuFirstRadius is the circle nearestMenu tries first, and
+// nearestMenuPage the most dishes it reads per circle.
+const (
+	nearestMenuFirstRadius = 250.0
+	nearestMenuPage        = 1000
+	nearestMenuMaxRounds   = 16
+)
+
+// nearestMenu returns the limit dishes nearest filter.Geo.Origin. Weaviate
+// cannot order by distance, so it reads every dish within a circle around
+// the origin and sorts them, widening the circle until it holds limit
+// matching dishes or reaches the filter's own range, and narrowing it when
+// a circle holds more dishes than one read returns. If the rounds run out
+// on a circle too dense to read whole, the nearest of the dishes read are
+// returned.
+func (c *Client) nearestMenu(ctx context.Context, limit int, filter SearchFilter) ([]MenuItem, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	maxRadius := nearestMenuMaxRadius(filter.Geo)
+	page := max(limit, nearestMenuPage)
+	lo, hi := 0.0, math.Inf(1)
+	radius := min(nearestMenuFirstRadius, maxRadius)
+	var items []MenuItem
+	for range nearestMenuMaxRounds {
+		ring := filter
+		ring.Geo = &GeoFilter{Origin: filter.Geo.Origin, RadiusMeters: radius}
+		var full bool
+		var err error
+		items, full, err = c.searchMenuPage(ctx, "", page, ring)
+		if err != nil {
+			return nil, err
+		}
+		items = slices.DeleteFunc(items, func(it MenuItem) bool {
+			_, ok := filter.Geo.Match(it.Latitude, it.Longitude)
+			return !ok
+		})
+		switch {
+		case full:
+			hi = radius
+		case len(items) < limit && radius < maxRadius:
+			lo = radius
+		default:
+			SortMenuByDistance(items)
+			return items[:min(limit, len(items))], nil
+		}
+		if hi-lo <= 1 {
+			break
+		}
+		if math.IsInf(hi, 1) {
+			radius = min(radius*4, maxRadius)
+		} else {
+			radius = (lo + hi) / 2
+		}
+	}
+	SortMenuByDistance(items)
+	return items[:min(limit, len(items))], nil
+}
+
+// nearestMenuMaxRadius is how far from f.Origin a dish can match f: the
+// radius when set, otherwise the farthest corner of BBox, otherwise half
+// the Earth's circumference.
+func nearestMenuMaxRadius(f *GeoFilter) float64 {
+	if f.RadiusMeters > 0 {
+		return f.RadiusMeters
+	}
+	if f.BBox == nil {
+		return geo.Distance(0, 0, 0, 180)
+	}
+	var radius float64
+	for _, lat := range []float64{f.BBox.MinLat(), f.BBox.MaxLat()} {
+		for _, lon := range []float64{f.BBox.MinLon(), f.BBox.MaxLon()} {
+			radius = max(radius, f.Origin.DistanceTo(lat, lon))
+		}
 	}
+	return radius + 1
+}
+
+// searchMenuPage runs one RestaurantMenu query for SearchMenu and reports
+// whether Weaviate returned a full page of limit objects. Results failing
+// the geo filter are dropped and carry their distance from its origin.
+func (c *Client) searchMenuPage(ctx context.Context, query string, limit int, filter SearchFilter) ([]MenuItem, bool, error) {
 	fields := []graphql.Field{
 		{Name: "menuItemId"}, {Name: "businessId"}, {Name: "restaurantName"},
 		{Name: "menuSection"}, {Name: "dishName"}, {Name: "description"}, {Name: "price"},
 		{Name: "statedIngredients"}, {Name: "hasFullIngredients"}, {Name: "modifiers"},
 		{Name: "sourceUrl"}, {Name: "city"}, {Name: "state"},
+		{Name: "language"}, {Name: "dishNameEn"}, {Name: "descriptionEn"}, {Name: "statedIngredientsEn"},
+		{Name: "location { latitude longitude }"},
 		{Name: "_additional { certainty }"},
 	}
-	resp, err := c.wv.GraphQL().Get().
+	getter := c.wv.GraphQL().Get().
 		WithClassName(menuCollectionName).
 		WithFields(fields...).
-		WithNearVector(c.wv.GraphQL().NearVectorArgBuilder().WithVector(vec)).
-		WithLimit(limit).
-		Do(ctx)
+		WithLimit(limit)
+	if query != "" && filter.Retrieval == RetrieveLexical {
+		getter = getter.WithBM25(c.wv.GraphQL().Bm25ArgBuilder().
+			WithQuery(query).
+			WithProperties("dishName", "description", "statedIngredients", "dishNameEn", "descriptionEn", "statedIngredientsEn"))
+	} else if query != "" {
+		if c.embedder == nil {
+			return nil, false, errors.New("embedder is not configured (required for menu search)")
+		}
+		vec, err := c.embedder.EmbedSingle(ctx, "search_query: "+query)
+		if err != nil {
+			return nil, false, fmt.Errorf("embedding query: %w", err)
+		}
+		getter = getter.WithNearVector(c.wv.GraphQL().NearVectorArgBuilder().WithVector(vec))
+	}
+	if where := buildMenuWhereFilter(filter); where != nil {
+		getter = getter.WithWhere(where)
+	}
+	resp, err := getter.Do(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("menu search: %w", err)
+		return nil, false, fmt.Errorf("menu search: %w", err)
 	}
 	if resp.Errors != nil {
-		return nil, fmt.Errorf("menu search graphql: %s", formatGraphQLErrors(resp.Errors))
+		return nil, false, fmt.Errorf("menu search graphql: %s", formatGraphQLErrors(resp.Errors))
 	}
 	raw, ok := resp.Data["Get"].(map[string]any)
 	if !ok {
-		return nil, nil
+		return nil, false, nil
 	}
 	rawItems, ok := raw[menuCollectionName].([]any)
 	if !ok {
-		return nil, nil
+		return nil, false, nil
 	}
 	var results []MenuItem
 	for _, ri := range rawItems {
@@ -1192,26 +1463,116 @@ func (c *Client) SearchMenu(ctx context.Context, query string, limit int) ([]Men
 		if !ok {
 			continue
 		}
-		results = append(results, MenuItem{
-			MenuItemID:         stringField(m, "menuItemId"),
-			BusinessID:         uuidOrNil(stringField(m, "businessId")),
-			RestaurantName:     stringField(m, "restaurantName"),
-			MenuSection:        stringField(m, "menuSection"),
-			DishName:           stringField(m, "dishName"),
-			Description:        stringField(m, "description"),
-			Price:              float64Field(m, "price"),
-			StatedIngredients:  stringSliceField(m, "statedIngredients"),
-			HasFullIngredients: boolField(m, "hasFullIngredients"),
-			Modifiers:          weaviateModifiersIn(m, "modifiers"),
-			SourceURL:          stringField(m, "sourceUrl"),
-			Address:            stringField(m, "address"),
-			PhoneNumber:        stringField(m, "phoneNumber"),
-			ScrapedAt:          stringField(m, "scrapedAt"),
-			City:               stringField(m, "city"),
-			State:              stringField(m, "state"),
-		})
+		item := weaviateMenuItem(m)
+		if filter.Geo != nil {
+			dist, ok := filter.Geo.Match(item.Latitude, item.Longitude)
+			if !ok {
+				continue
+			}
+			item.DistanceMeters = dist
+		}
+		results = append(results, item)
+	}
+	return results, len(rawItems) >= limit, nil
+}
+
+// buildMenuWhereFilter is buildWhereFilter for the RestaurantMenu
+// collection, whose properties live on the object itself rather than on a
+// parent review.
+func buildMenuWhereFilter(f SearchFilter) *filters.WhereBuilder {
+	var operands []*filters.WhereBuilder
+	if f.City != "" {
+		operands = append(operands,
+			filters.Where().WithPath([]string{"city"}).WithOperator(filters.Like).WithValueText("*"+f.City+"*"))
+	}
+	if f.State != "" {
+		operands = append(operands,
+			filters.Where().WithPath([]string{"state"}).WithOperator(filters.Like).WithValueText("*"+f.State+"*"))
+	}
+	if f.BusinessID != uuid.Nil {
+		operands = append(operands,
+			filters.Where().WithPath([]string{"businessId"}).WithOperator(filters.Equal).WithValueText(f.BusinessID.String()))
+	}
+	if f.Geo != nil {
+		lat, lon, radius := geoRange(f.Geo)
+		operands = append(operands,
+			filters.Where().WithPath([]string{"location"}).WithOperator(filters.WithinGeoRange).
+				WithValueGeoRange(&filters.GeoCoordinatesParameter{
+					Latitude:    float32(lat),
+					Longitude:   float32(lon),
+					MaxDistance: float32(radius),
+				}))
+	}
+	switch len(operands) {
+	case 0:
+		return nil
+	case 1:
+		return operands[0]
+	default:
+		return filters.Where().WithOperator(filters.And).WithOperands(operands)
 	}
-	return results, nil
+}
+
+// geoRange returns the circle Weaviate's WithinGeoRange should search for
+// f: the radius around Origin when set, otherwise the circle enclosing the
+// filter's bounds. Weaviate has no box operator, so box edges are applied
+// afterwards by GeoFilter.Match.
+func geoRange(f *GeoFilter) (lat, lon, radius float64) {
+	if f.Origin != nil && f.RadiusMeters > 0 {
+		return f.Origin.Lat, f.Origin.Lon, f.RadiusMeters
+	}
+	b := f.Bounds()
+	lat, lon = (b.MinLat()+b.MaxLat())/2, (b.MinLon()+b.MaxLon())/2
+	// The corner nearer the equator is farthest, but check all four rather
+	// than reason about hemispheres.
+	for _, cLat := range []float64{b.MinLat(), b.MaxLat()} {
+		for _, cLon := range []float64{b.MinLon(), b.MaxLon()} {
+			radius = max(radius, geo.Distance(lat, lon, cLat, cLon))
+		}
+	}
+	// The float32 coordinates Weaviate takes lose about a metre.
+	return lat, lon, radius + 1
+}
+
+// weaviateMenuItem builds a MenuItem from RestaurantMenu properties.
+func weaviateMenuItem(m map[string]any) MenuItem {
+	item := MenuItem{
+		MenuItemID:         stringField(m, "menuItemId"),
+		BusinessID:         uuidOrNil(stringField(m, "businessId")),
+		RestaurantName:     stringField(m, "restaurantName"),
+		MenuSection:        stringField(m, "menuSection"),
+		DishName:           stringField(m, "dishName"),
+		Description:        stringField(m, "description"),
+		Price:              float64Field(m, "price"),
+		StatedIngredients:  stringSliceField(m, "statedIngredients"),
+		HasFullIngredients: boolField(m, "hasFullIngredients"),
+		Modifiers:          weaviateModifiersIn(m, "modifiers"),
+		SourceURL:          stringField(m, "sourceUrl"),
+		Address:            stringField(m, "address"),
+		PhoneNumber:        stringField(m, "phoneNumber"),
+		ScrapedAt:          stringField(m, "scrapedAt"),
+		City:               stringField(m, "city"),
+		State:              stringField(m, "state"),
+
+		Language:            stringField(m, "language"),
+		DishNameEn:          stringField(m, "dishNameEn"),
+		DescriptionEn:       stringField(m, "descriptionEn"),
+		StatedIngredientsEn: stringSliceField(m, "statedIngredientsEn"),
+	}
+	if loc, ok := m["location"].(map[string]any); ok {
+		item.Latitude = float64Field(loc, "latitude")
+		item.Longitude = float64Field(loc, "longitude")
+	}
+	return item
+}
+
+// weaviateLocation returns a geoCoordinates property value, or nil when the
+// restaurant has no coordinates.
+func weaviateLocation(lat, lon *float64) any {
+	if lat == nil || lon == nil {
+		return nil
+	}
+	return map[string]any{"latitude": *lat, "longitude": *lon}
 }
 
 func stringField(m map[string]any, key string) string {
@@ -1333,6 +1694,9 @@ func (c *Client) EnsureRegulatorySchema(ctx context.Context) error {
 	return nil
 }
 
+// regulatoryUpdateNS namespaces the object IDs of regulatory updates.
+var regulatoryUpdateNS = uuid.MustParse("a3c8e6d0-7f2b-4b1a-9c5d-3e8f1a2b4c6d")
+
 // BatchUpsertRegulatory inserts or updates regulatory updates in Weaviate.
 // Each item carries a pre-computed Vector and a deterministic ID for idempotent
 // upserts, matching the BatchUpsertFodmap pattern.
@@ -1340,7 +1704,6 @@ func (c *Client) BatchUpsertRegulatory(ctx context.Context, items []RegulatoryUp
 	if c.embedder == nil {
 		return errors.New("embedder is not configured (required for regulatory update upsert)")
 	}
-	var regUpdateNS = uuid.MustParse("a3c8e6d0-7f2b-4b1a-9c5d-3e8f1a2b4c6d")
 	batcher := c.wv.Batch().ObjectsBatcher()
 	for _, item := range items {
 		vec := item.Vector
@@ -1351,7 +1714,7 @@ func (c *Client) BatchUpsertRegulatory(ctx context.Context, items []RegulatoryUp
 				return fmt.Errorf("embedding regulatory update %q: %w", item.SubstanceName, err)
 			}
 		}
-		id := uuid.NewSHA1(regUpdateNS, []byte(item.ID)).String()
+		id := uuid.NewSHA1(regulatoryUpdateNS, []byte(item.ID)).String()
 		batcher = batcher.WithObjects(&models.Object{
 			Class:  regulatoryCollectionName,
 			ID:     strfmt.UUID(id),
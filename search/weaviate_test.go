@@ -7,11 +7,14 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 
 	"fodmap/data"
 	"fodmap/data/schemas"
+	"fodmap/geo"
 
 	"github.com/google/uuid"
 	"github.com/weaviate/weaviate/entities/models"
@@ -1127,7 +1130,7 @@ func TestClient_SearchMenu(t *testing.T) {
 	host := strings.TrimPrefix(srv.URL, "http://")
 	client, _ := NewClient(host, "http", "", &mockEmbedder{vec: []float32{0.1, 0.2, 0.3}})
 
-	res, err := client.SearchMenu(context.Background(), "pizza", 1)
+	res, err := client.SearchMenu(context.Background(), "pizza", 1, SearchFilter{})
 	if err != nil {
 		t.Fatalf("SearchMenu failed: %v", err)
 	}
@@ -1136,6 +1139,116 @@ func TestClient_SearchMenu(t *testing.T) {
 	}
 }
 
+func TestClient_SearchMenu_EmptyQueryNearestFirst(t *testing.T) {
+	origin := geo.Point{Lat: 40.76, Lon: -73.92}
+	// Stored farthest first, so a single storage-order page would miss the
+	// nearest dishes.
+	dishes := []struct {
+		name string
+		lat  float64
+	}{{"far", 40.80}, {"middle", 40.78}, {"near", 40.761}}
+	maxRe := regexp.MustCompile(`max:([0-9.]+)`)
+	var rounds int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/graphql" {
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		m := maxRe.FindSubmatch(body)
+		if m == nil {
+			t.Errorf("query has no geo range: %s", body)
+			return
+		}
+		rounds++
+		radius, _ := strconv.ParseFloat(string(m[1]), 64)
+		var objs []any
+		for _, d := range dishes {
+			if origin.DistanceTo(d.lat, origin.Lon) <= radius {
+				objs = append(objs, map[string]any{
+					"dishName": d.name,
+					"location": map[string]any{"latitude": d.lat, "longitude": origin.Lon},
+				})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"Get": map[string]any{menuCollectionName: objs}}})
+	}))
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	client, _ := NewClient(host, "http", "", &mockEmbedder{vec: []float32{0.1}})
+
+	res, err := client.SearchMenu(context.Background(), "", 2, SearchFilter{Geo: &GeoFilter{Origin: &origin, RadiusMeters: 10_000}})
+	if err != nil {
+		t.Fatalf("SearchMenu failed: %v", err)
+	}
+	var got []string
+	for _, it := range res {
+		got = append(got, it.DishName)
+	}
+	if strings.Join(got, ",") != "near,middle" {
+		t.Errorf("dishes = %v, want [near middle]", got)
+	}
+	if rounds < 2 {
+		t.Errorf("rounds = %d, want the circle widened at least once", rounds)
+	}
+}
+
+func TestClient_BackfillMenuLocations(t *testing.T) {
+	located := uuid.New()
+	unknown := uuid.New()
+	var upserted []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/v1/objects":
+			_ = json.NewEncoder(w).Encode(map[string]any{"objects": []any{
+				map[string]any{"id": uuid.NewString(), "class": menuCollectionName, "vector": []float32{0.1},
+					"properties": map[string]any{"dishName": "missing", "businessId": located.String()}},
+				map[string]any{"id": uuid.NewString(), "class": menuCollectionName, "vector": []float32{0.1},
+					"properties": map[string]any{"dishName": "unplaced", "businessId": unknown.String()}},
+				map[string]any{"id": uuid.NewString(), "class": menuCollectionName, "vector": []float32{0.1},
+					"properties": map[string]any{"dishName": "placed", "businessId": located.String(),
+						"location": map[string]any{"latitude": 1.0, "longitude": 2.0}}},
+			}})
+		case r.Method == "POST" && r.URL.Path == "/v1/batch/objects":
+			var body struct {
+				Objects []struct {
+					Properties map[string]any `json:"properties"`
+				} `json:"objects"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			for _, o := range body.Objects {
+				upserted = append(upserted, o.Properties)
+			}
+			_ = json.NewEncoder(w).Encode([]any{})
+		}
+	}))
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	client, _ := NewClient(host, "http", "", nil)
+
+	lat, lon := 40.76, -73.92
+	n, err := client.BackfillMenuLocations(context.Background(), 10, func(_ context.Context, id uuid.UUID) (*float64, *float64, error) {
+		if id == located {
+			return &lat, &lon, nil
+		}
+		return nil, nil, nil
+	})
+	if err != nil {
+		t.Fatalf("BackfillMenuLocations failed: %v", err)
+	}
+	if n != 1 || len(upserted) != 1 {
+		t.Fatalf("updated %d, upserted %v; want only the dish without a location", n, upserted)
+	}
+	if upserted[0]["dishName"] != "missing" {
+		t.Errorf("upserted %v, want the dish missing its location", upserted[0]["dishName"])
+	}
+	loc, _ := upserted[0]["location"].(map[string]any)
+	if loc["latitude"] != lat || loc["longitude"] != lon {
+		t.Errorf("location = %v, want %v, %v", loc, lat, lon)
+	}
+}
+
 func TestClient_EnsureRegulatorySchema(t *testing.T) {
 	var created bool
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
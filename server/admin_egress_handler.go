@@ -0,0 +1,21 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"fodmap/egress"
+)
+
+// EgressStats reports the health of the pipeline's egress pool and the block
+// rate per scraped domain. *egress.Pool implements it.
+type EgressStats interface {
+	Stats() egress.Stats
+}
+
+// adminEgressStatsHandler returns the egress pool snapshot: per-exit traffic
+// and cooldowns, and per-domain 403/429 block rates.
+func (s *Server) adminEgressStatsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.egressStats.Stats())
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fodmap/auth"
+	"fodmap/egress"
+)
+
+type stubEgressStats struct{ stats egress.Stats }
+
+func (s stubEgressStats) Stats() egress.Stats { return s.stats }
+
+func TestAdminEgressStatsHandler(t *testing.T) {
+	store := newStubStore()
+	secret := "test-secret"
+	store.users["admin@example.com"] = &auth.User{ID: "admin-1", Email: "admin@example.com", Role: "admin", Status: "active"}
+	adminToken, _, _ := auth.GenerateTokensWithRole("admin-1", "admin", secret)
+
+	s := &Server{userStore: store, jwtSecret: secret}
+	s.SetEgressStats(stubEgressStats{stats: egress.Stats{
+		Exits:   []egress.ExitStats{{Name: "direct", Requests: 4, Blocked: 1}},
+		Domains: []egress.DomainStats{{Domain: "example.com", Requests: 4, Blocked: 1, BlockRate: 0.25}},
+	}})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/egress", nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var got egress.Stats
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Domains) != 1 || got.Domains[0].BlockRate != 0.25 {
+		t.Errorf("domains = %+v", got.Domains)
+	}
+}
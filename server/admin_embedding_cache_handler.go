@@ -0,0 +1,21 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"fodmap/search"
+)
+
+// EmbeddingCache reports how often embeddings were served from the cache.
+// *search.CachingEmbedder implements it.
+type EmbeddingCache interface {
+	Stats() search.EmbeddingCacheStats
+}
+
+// adminEmbeddingCacheHandler returns the embedding cache's hit and miss
+// counts since the server started.
+func (s *Server) adminEmbeddingCacheHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.embeddingCache.Stats())
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fodmap/auth"
+	"fodmap/search"
+)
+
+type stubEmbeddingCache struct{ stats search.EmbeddingCacheStats }
+
+func (s stubEmbeddingCache) Stats() search.EmbeddingCacheStats { return s.stats }
+
+func TestAdminEmbeddingCacheHandler(t *testing.T) {
+	store := newStubStore()
+	secret := "test-secret"
+	store.users["admin@example.com"] = &auth.User{ID: "admin-1", Email: "admin@example.com", Role: "admin", Status: "active"}
+	adminToken, _, _ := auth.GenerateTokensWithRole("admin-1", "admin", secret)
+
+	s := &Server{userStore: store, jwtSecret: secret}
+	s.SetEmbeddingCache(stubEmbeddingCache{stats: search.EmbeddingCacheStats{
+		Namespace: "ollama/nomic-embed-text/768", Hits: 3, Misses: 1, HitRate: 0.75,
+	}})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/embedding-cache", nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var got search.EmbeddingCacheStats
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Hits != 3 || got.HitRate != 0.75 {
+		t.Errorf("stats = %+v", got)
+	}
+}
@@ -336,13 +336,7 @@ func (s *Server) adminConversationActivityHandler(w http.ResponseWriter, r *http
 }
 
 func (s *Server) adminListMenuItemsHandler(w http.ResponseWriter, r *http.Request) {
-	var ms MenuStore
-	if s.menuStore != nil {
-		ms = s.menuStore
-	} else if cast, ok := s.searcher.(MenuStore); ok {
-		ms = cast
-	}
-
+	ms := s.resolveMenuStore()
 	if ms == nil {
 		respondError(w, "menu store not configured", http.StatusNotImplemented)
 		return
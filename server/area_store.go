@@ -0,0 +1,31 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"fodmap/geo"
+)
+
+// ErrAreaNotFound is returned by AreaStore.DeleteArea when no area has the
+// given name.
+var ErrAreaNotFound = errors.New("area not found")
+
+// AreaStore persists the named geographic areas that scope restaurant
+// imports. Implemented by menusearch (Postgres table or YAML file); defined
+// here so server handlers don't import menusearch.
+type AreaStore interface {
+	ListAreas(ctx context.Context) ([]geo.Area, error)
+	// GetArea returns nil, nil when no area has the given name.
+	GetArea(ctx context.Context, name string) (*geo.Area, error)
+	// PutArea creates or replaces an area. Callers validate it first.
+	PutArea(ctx context.Context, a geo.Area) error
+	DeleteArea(ctx context.Context, name string) error
+}
+
+// RestaurantAreaLister is implemented by restaurant stores that can list the
+// restaurants inside an area, applying point-in-polygon tests to their
+// coordinates.
+type RestaurantAreaLister interface {
+	ListInArea(ctx context.Context, area geo.Area, limit int) ([]Restaurant, error)
+}
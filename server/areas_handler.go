@@ -0,0 +1,140 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"fodmap/geo"
+)
+
+// defaultAreaRestaurantsLimit caps GET /api/v1/areas/{name}/restaurants when
+// no limit is given.
+const defaultAreaRestaurantsLimit = 500
+
+func (s *Server) areaListHandler(w http.ResponseWriter, r *http.Request) {
+	areas, err := s.areaStore.ListAreas(r.Context())
+	if err != nil {
+		slog.Error("areas: list", "err", err)
+		respondError(w, "failed to list areas", http.StatusInternalServerError)
+		return
+	}
+	if areas == nil {
+		areas = []geo.Area{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"areas": areas})
+}
+
+func (s *Server) areaGetHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	area, err := s.areaStore.GetArea(r.Context(), name)
+	if err != nil {
+		slog.Error("areas: get", "name", name, "err", err)
+		respondError(w, "failed to get area", http.StatusInternalServerError)
+		return
+	}
+	if area == nil {
+		respondError(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(area)
+}
+
+// areaPutHandler creates or replaces the area named in the path. The body is
+// a geo.Area; its name, if present, must match the path.
+func (s *Server) areaPutHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	var area geo.Area
+	if err := json.NewDecoder(r.Body).Decode(&area); err != nil {
+		respondError(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if area.Name == "" {
+		area.Name = name
+	}
+	if area.Name != name {
+		respondError(w, "body name does not match path", http.StatusBadRequest)
+		return
+	}
+	if err := area.Validate(); err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.areaStore.PutArea(r.Context(), area); err != nil {
+		slog.Error("areas: put", "name", name, "err", err)
+		respondError(w, "failed to save area", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(area)
+}
+
+func (s *Server) areaDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if err := s.areaStore.DeleteArea(r.Context(), name); err != nil {
+		if errors.Is(err, ErrAreaNotFound) {
+			respondError(w, "not found", http.StatusNotFound)
+			return
+		}
+		slog.Error("areas: delete", "name", name, "err", err)
+		respondError(w, "failed to delete area", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// areaRestaurantsHandler lists the restaurants inside an area, including
+// point-in-polygon matches on their coordinates.
+func (s *Server) areaRestaurantsHandler(w http.ResponseWriter, r *http.Request) {
+	lister, ok := s.restaurantStore.(RestaurantAreaLister)
+	if !ok {
+		respondError(w, "restaurant store does not support area queries", http.StatusNotImplemented)
+		return
+	}
+	name := r.PathValue("name")
+	limit := defaultAreaRestaurantsLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			respondError(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	ctx := r.Context()
+	area, err := s.areaStore.GetArea(ctx, name)
+	if err != nil {
+		slog.Error("areas: get", "name", name, "err", err)
+		respondError(w, "failed to get area", http.StatusInternalServerError)
+		return
+	}
+	if area == nil {
+		respondError(w, "not found", http.StatusNotFound)
+		return
+	}
+	rows, err := lister.ListInArea(ctx, *area, limit)
+	if err != nil {
+		slog.Error("areas: list restaurants", "name", name, "err", err)
+		respondError(w, "failed to list restaurants", http.StatusInternalServerError)
+		return
+	}
+	if rows == nil {
+		rows = []Restaurant{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"area":        area.Name,
+		"restaurants": rows,
+		"total":       len(rows),
+		"limit":       limit,
+	})
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fodmap/geo"
+)
+
+// stubAreaStore is an in-memory AreaStore.
+type stubAreaStore struct {
+	areas map[string]geo.Area
+}
+
+func (s *stubAreaStore) ListAreas(_ context.Context) ([]geo.Area, error) {
+	var out []geo.Area
+	for _, a := range s.areas {
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+func (s *stubAreaStore) GetArea(_ context.Context, name string) (*geo.Area, error) {
+	a, ok := s.areas[name]
+	if !ok {
+		return nil, nil
+	}
+	return &a, nil
+}
+
+func (s *stubAreaStore) PutArea(_ context.Context, a geo.Area) error {
+	s.areas[a.Name] = a
+	return nil
+}
+
+func (s *stubAreaStore) DeleteArea(_ context.Context, name string) error {
+	if _, ok := s.areas[name]; !ok {
+		return ErrAreaNotFound
+	}
+	delete(s.areas, name)
+	return nil
+}
+
+// areaRestaurantStore adds ListInArea to the restaurant stub, filtering with
+// the area's matcher like the Postgres store does.
+type areaRestaurantStore struct {
+	*stubRestaurantStore
+}
+
+func (s areaRestaurantStore) ListInArea(_ context.Context, area geo.Area, limit int) ([]Restaurant, error) {
+	m, err := area.Matcher()
+	if err != nil {
+		return nil, err
+	}
+	var out []Restaurant
+	for _, r := range s.rows {
+		if m.Match(geo.Place{Lat: r.Latitude, Lon: r.Longitude}) && len(out) < limit {
+			out = append(out, *r)
+		}
+	}
+	return out, nil
+}
+
+func TestAreaPutHandler(t *testing.T) {
+	store := &stubAreaStore{areas: map[string]geo.Area{}}
+	s := &Server{areaStore: store}
+
+	put := func(name, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/areas/"+name, bytes.NewBufferString(body))
+		req.SetPathValue("name", name)
+		w := httptest.NewRecorder()
+		s.areaPutHandler(w, req)
+		return w
+	}
+
+	if w := put("brooklyn", `{"boroughs":["Brooklyn"]}`); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if got := store.areas["brooklyn"]; len(got.Boroughs) != 1 {
+		t.Errorf("stored area = %+v", got)
+	}
+	if w := put("brooklyn", `{"name":"queens","boroughs":["Queens"]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("mismatched name: status = %d, want 400", w.Code)
+	}
+	if w := put("empty", `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("no filters: status = %d, want 400", w.Code)
+	}
+	if w := put("bad-geo", `{"geometry":{"type":"Point","coordinates":[0,0]}}`); w.Code != http.StatusBadRequest {
+		t.Errorf("point geometry: status = %d, want 400", w.Code)
+	}
+}
+
+func TestAreaDeleteHandler_NotFound(t *testing.T) {
+	s := &Server{areaStore: &stubAreaStore{areas: map[string]geo.Area{}}}
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/areas/nowhere", nil)
+	req.SetPathValue("name", "nowhere")
+	w := httptest.NewRecorder()
+	s.areaDeleteHandler(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
+
+func TestAreaRestaurantsHandler_PointInPolygon(t *testing.T) {
+	g, err := geo.ParseGeoJSON([]byte(`{"type":"Polygon","coordinates":[[[-74,40.7],[-73.9,40.7],[-73.9,40.8],[-74,40.8],[-74,40.7]]]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	areas := &stubAreaStore{areas: map[string]geo.Area{"box": {Name: "box", Geometry: g}}}
+	rs := newStubRestaurantStore()
+	in, out := "in", "out"
+	inLat, inLon, outLat, outLon := 40.75, -73.95, 40.9, -73.95
+	rs.rows[in] = &Restaurant{CAMIS: &in, DBA: "Inside", Latitude: &inLat, Longitude: &inLon}
+	rs.rows[out] = &Restaurant{CAMIS: &out, DBA: "Outside", Latitude: &outLat, Longitude: &outLon}
+
+	s := NewServerWithChat(nil, 0, ChatConfig{})
+	s.SetRestaurantStore(areaRestaurantStore{rs})
+	s.SetAreaStore(areas)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/areas/box/restaurants", nil)
+	req.SetPathValue("name", "box")
+	w := httptest.NewRecorder()
+	s.areaRestaurantsHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var got struct {
+		Restaurants []Restaurant `json:"restaurants"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Restaurants) != 1 || got.Restaurants[0].DBA != "Inside" {
+		t.Errorf("restaurants = %+v, want only Inside", got.Restaurants)
+	}
+}
@@ -135,7 +135,7 @@ func (s *Server) chatHandler(backend chat.ChatBackend) http.HandlerFunc {
 			// Legacy fallback: if no conversation was found, but we have a query (from legacy path),
 			// try to create a new one on the fly.
 			if query != "" {
-				bizResult, err := s.searcher.Businesses(ctx, query, 1, search.SearchFilter{})
+				bizResult, err := s.lookupBusiness(ctx, query)
 				if err != nil || len(bizResult.Businesses) == 0 {
 					respondError(w, "business search failed or no businesses found", http.StatusNotFound)
 					return
@@ -227,6 +227,11 @@ func (s *Server) chatHandler(backend chat.ChatBackend) http.HandlerFunc {
 					slog.Warn("chat: render prompt failed, using name-only fallback", "error", err)
 					systemPrompt = fmt.Sprintf("You are a FODMAP and food allergen expert helping people understand dishes at %s (%s, %s).", biz.Name, biz.City, biz.State)
 				}
+				// The menu goes in the system prompt rather than the stored
+				// history, so each turn sees the latest scrape.
+				if menu := s.chatMenuContext(ctx, conv.BusinessID, biz.Name); menu != "" {
+					systemPrompt += "\n\n" + menu
+				}
 			}
 		}
 
@@ -284,6 +289,10 @@ func (s *Server) chatHandler(backend chat.ChatBackend) http.HandlerFunc {
 			Tools:          chat.FodmapAllergenTools(),
 			History:        history,
 		}
+		if conv.BusinessID != uuid.Nil && s.resolveMenuStore() != nil {
+			session.MenuClient = fodmapClient
+			session.BusinessID = conv.BusinessID.String()
+		}
 
 		// Save user message to history.
 		startSeq := len(history)
@@ -473,3 +482,19 @@ func saveModelResponse(ctx context.Context, store auth.ChatStore, convID string,
 	}
 	return msg
 }
+
+// chatMenuContext returns the menu of the business for a chat's system
+// prompt, or "" when no menu store is configured or the business has no
+// scraped menu.
+func (s *Server) chatMenuContext(ctx context.Context, businessID uuid.UUID, name string) string {
+	if businessID == uuid.Nil || s.resolveMenuStore() == nil {
+		return ""
+	}
+	items, err := NewDirectFodmapClient(s).SearchMenu(ctx, businessID.String(), "", chat.MenuContextLimit)
+	if err != nil {
+		slog.Warn("chat: failed to load menu", "business_id", businessID, "error", err)
+		return ""
+	}
+	slog.Info("chat: menu loaded", "business_id", businessID, "dishes", len(items))
+	return chat.FormatMenuContext(name, items)
+}
@@ -61,7 +61,7 @@ func (s *Server) createConversationHandler(w http.ResponseWriter, r *http.Reques
 		}
 	} else {
 		// Search based on query
-		bizResult, err := s.searcher.Businesses(r.Context(), req.Query, 1, search.SearchFilter{})
+		bizResult, err := s.lookupBusiness(r.Context(), req.Query)
 		if err != nil || len(bizResult.Businesses) == 0 {
 			respondError(w, "business search failed or no businesses found", http.StatusNotFound)
 			return
@@ -90,7 +90,7 @@ func (s *Server) createConversationHandler(w http.ResponseWriter, r *http.Reques
 	if query == "" {
 		query = "menu and food" // fallback for broad context
 	}
-	reviewResult, err := s.searcher.Reviews(r.Context(), query, 10, search.SearchFilter{BusinessID: businessID})
+	reviewResult, err := s.retrieval.Reviews(r.Context(), s.searcher, query, 10, search.SearchFilter{BusinessID: businessID})
 	if err == nil {
 		for _, rr := range reviewResult.BusinessReviews {
 			conv.ReviewContext = append(conv.ReviewContext, auth.ReviewScore{
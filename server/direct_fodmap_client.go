@@ -2,11 +2,18 @@ package server
 
 import (
 	"context"
+	"errors"
+	"fmt"
+
 	"fodmap/chat"
+	"fodmap/search"
+
+	"github.com/google/uuid"
 )
 
 // DirectFodmapClient is an adapter that implements chat.FodmapServerClient
-// by calling the server's Searcher directly, avoiding an HTTP round-trip.
+// and chat.MenuClient by calling the server's Searcher and MenuStore
+// directly, avoiding an HTTP round-trip.
 type DirectFodmapClient struct {
 	s *Server
 }
@@ -41,3 +48,38 @@ func (c *DirectFodmapClient) LookupFodmap(ctx context.Context, ingredient string
 		Substitutions: res.Substitutions,
 	}, nil
 }
+
+// SearchMenu implements chat.MenuClient by searching the server's menu
+// store for one restaurant's dishes, classified for FODMAPs as the menu
+// search endpoint classifies them.
+func (c *DirectFodmapClient) SearchMenu(ctx context.Context, businessID, query string, limit int) ([]chat.MenuItem, error) {
+	ms := c.s.resolveMenuStore()
+	if ms == nil {
+		return nil, errors.New("menu search not configured")
+	}
+	id, err := uuid.Parse(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("business_id must be a valid UUID: %w", err)
+	}
+	items, err := c.s.retrieval.SearchMenu(ctx, ms, query, limit, search.SearchFilter{BusinessID: id})
+	if err != nil {
+		return nil, err
+	}
+	classifier := c.s.dishClassifier(ctx)
+	out := make([]chat.MenuItem, len(items))
+	for i, it := range items {
+		level, _ := classifyMenuItem(classifier, it)
+		if level == "" {
+			level = "unknown"
+		}
+		out[i] = chat.MenuItem{
+			DishName:    it.DishName,
+			Section:     it.MenuSection,
+			Description: it.Description,
+			Ingredients: it.StatedIngredients,
+			Price:       it.Price,
+			FodmapLevel: level,
+		}
+	}
+	return out, nil
+}
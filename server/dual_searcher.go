@@ -0,0 +1,372 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"fodmap/data"
+	"fodmap/search"
+)
+
+// shadowReadTimeout bounds a shadow read, which runs detached from the
+// request that triggered it.
+const shadowReadTimeout = 10 * time.Second
+
+// maxShadowReads caps the shadow reads in flight. A read arriving while all
+// slots are busy is not shadowed, so a slow secondary never queues work.
+const maxShadowReads = 8
+
+// errFodmapWriteUnsupported is returned by DualSearcher's FodmapWriter
+// methods when the primary cannot write single ingredients.
+var errFodmapWriteUnsupported = errors.New("primary search backend does not support single-ingredient writes")
+
+// DualSearcher writes reviews and FODMAP ingredients to a primary Searcher
+// (the source of truth) and best-effort mirrors them to a secondary. Reads
+// are answered by the primary. With shadow reads on, each read is repeated
+// against the secondary in the background and the ranking divergence
+// (overlap@k and rank correlation) is logged; the secondary's results never
+// reach the response.
+//
+// It is the Searcher counterpart of DualMenuStore, used to migrate between
+// backends (e.g. Weaviate → Postgres): copy the data with `search migrate`,
+// dual-write while the secondary is compared, then promote it.
+type DualSearcher struct {
+	primary     Searcher
+	secondary   Searcher
+	shadowRead  bool
+	shadowSlots chan struct{}
+
+	mu     sync.Mutex // guards closed and adding to wg
+	closed bool
+	wg     sync.WaitGroup // in-flight shadow reads
+}
+
+// NewDualSearcher wraps primary as the source of truth and secondary as a
+// best-effort mirror, shadow-reading the secondary when shadowRead is set.
+func NewDualSearcher(primary, secondary Searcher, shadowRead bool) *DualSearcher {
+	return &DualSearcher{
+		primary:     primary,
+		secondary:   secondary,
+		shadowRead:  shadowRead,
+		shadowSlots: make(chan struct{}, maxShadowReads),
+	}
+}
+
+// Primary returns the Searcher reads are answered by.
+func (d *DualSearcher) Primary() Searcher {
+	return d.primary
+}
+
+// Close stops starting shadow reads and waits for those in flight, each
+// bounded by shadowReadTimeout, so none is cut off mid-comparison on
+// shutdown. The backends stay open; they belong to the caller.
+func (d *DualSearcher) Close() error {
+	d.mu.Lock()
+	d.closed = true
+	d.mu.Unlock()
+	d.wg.Wait()
+	return nil
+}
+
+// UnwrapSearcher returns the primary of a DualSearcher, else s. Capability
+// checks (MenuStore, regulatory vector sinks) go through it, so a secondary
+// mirror leaves them as they are with a single backend.
+func UnwrapSearcher(s Searcher) Searcher {
+	if d, ok := s.(*DualSearcher); ok {
+		return d.primary
+	}
+	return s
+}
+
+// EnsureSchema ensures both backends' review schemas. A secondary failure is
+// logged and does not block startup.
+func (d *DualSearcher) EnsureSchema(ctx context.Context) error {
+	if err := d.primary.EnsureSchema(ctx); err != nil {
+		return fmt.Errorf("primary ensure schema: %w", err)
+	}
+	if err := d.secondary.EnsureSchema(ctx); err != nil {
+		slog.Warn("dual searcher: secondary EnsureSchema failed", "error", err)
+	}
+	return nil
+}
+
+// EnsureFodmapSchema ensures both backends' FODMAP schemas. A secondary
+// failure is logged and does not block startup.
+func (d *DualSearcher) EnsureFodmapSchema(ctx context.Context) error {
+	if err := d.primary.EnsureFodmapSchema(ctx); err != nil {
+		return fmt.Errorf("primary ensure fodmap schema: %w", err)
+	}
+	if err := d.secondary.EnsureFodmapSchema(ctx); err != nil {
+		slog.Warn("dual searcher: secondary EnsureFodmapSchema failed", "error", err)
+	}
+	return nil
+}
+
+// BatchUpsert writes reviews to the primary (hard error on failure), then
+// mirrors them to the secondary best-effort.
+func (d *DualSearcher) BatchUpsert(ctx context.Context, items []search.IndexItem) error {
+	if err := d.primary.BatchUpsert(ctx, items); err != nil {
+		return fmt.Errorf("primary upsert: %w", err)
+	}
+	if err := d.secondary.BatchUpsert(ctx, items); err != nil {
+		slog.Warn("dual searcher: secondary upsert failed (primary succeeded)",
+			"items", len(items), "error", err)
+	}
+	return nil
+}
+
+// BatchUpsertFodmap writes ingredients to the primary (hard error on
+// failure), then mirrors them to the secondary best-effort.
+func (d *DualSearcher) BatchUpsertFodmap(ctx context.Context, items map[string]data.FodmapEntry) error {
+	if err := d.primary.BatchUpsertFodmap(ctx, items); err != nil {
+		return fmt.Errorf("primary fodmap upsert: %w", err)
+	}
+	if err := d.secondary.BatchUpsertFodmap(ctx, items); err != nil {
+		slog.Warn("dual searcher: secondary fodmap upsert failed (primary succeeded)",
+			"items", len(items), "error", err)
+	}
+	return nil
+}
+
+// UpsertFodmapItem implements FodmapWriter: the primary's write decides the
+// result and the secondary's is best-effort.
+func (d *DualSearcher) UpsertFodmapItem(ctx context.Context, name string, entry data.FodmapEntry) error {
+	fw, ok := d.primary.(FodmapWriter)
+	if !ok {
+		return errFodmapWriteUnsupported
+	}
+	if err := fw.UpsertFodmapItem(ctx, name, entry); err != nil {
+		return err
+	}
+	if sw, ok := d.secondary.(FodmapWriter); ok {
+		if err := sw.UpsertFodmapItem(ctx, name, entry); err != nil {
+			slog.Warn("dual searcher: secondary ingredient upsert failed (primary succeeded)",
+				"name", name, "error", err)
+		}
+	}
+	return nil
+}
+
+// DeleteFodmapItem implements FodmapWriter like UpsertFodmapItem.
+func (d *DualSearcher) DeleteFodmapItem(ctx context.Context, name string) error {
+	fw, ok := d.primary.(FodmapWriter)
+	if !ok {
+		return errFodmapWriteUnsupported
+	}
+	if err := fw.DeleteFodmapItem(ctx, name); err != nil {
+		return err
+	}
+	if sw, ok := d.secondary.(FodmapWriter); ok {
+		if err := sw.DeleteFodmapItem(ctx, name); err != nil {
+			slog.Warn("dual searcher: secondary ingredient delete failed (primary succeeded)",
+				"name", name, "error", err)
+		}
+	}
+	return nil
+}
+
+// Businesses answers from the primary and shadows the query to the
+// secondary, comparing business IDs.
+func (d *DualSearcher) Businesses(ctx context.Context, query string, limit int, filter search.SearchFilter) (search.SearchResult, error) {
+	start := time.Now()
+	res, err := d.primary.Businesses(ctx, query, limit, filter)
+	if err == nil {
+		primaryIDs := businessIDs(res)
+		elapsed := time.Since(start)
+		d.shadow(ctx, "businesses", query, func(ctx context.Context) ([]any, error) {
+			sec, err := d.secondary.Businesses(ctx, query, limit, filter)
+			if err != nil {
+				return nil, err
+			}
+			return rankingAttrs(primaryIDs, businessIDs(sec), limit, elapsed), nil
+		})
+	}
+	return res, err
+}
+
+// Reviews answers from the primary and shadows the query to the secondary,
+// comparing review IDs.
+func (d *DualSearcher) Reviews(ctx context.Context, query string, limit int, filter search.SearchFilter) (search.SearchReviews, error) {
+	start := time.Now()
+	res, err := d.primary.Reviews(ctx, query, limit, filter)
+	if err == nil {
+		primaryIDs := reviewIDs(res)
+		elapsed := time.Since(start)
+		d.shadow(ctx, "reviews", query, func(ctx context.Context) ([]any, error) {
+			sec, err := d.secondary.Reviews(ctx, query, limit, filter)
+			if err != nil {
+				return nil, err
+			}
+			return rankingAttrs(primaryIDs, reviewIDs(sec), limit, elapsed), nil
+		})
+	}
+	return res, err
+}
+
+// SearchFodmap answers from the primary and shadows the lookup to the
+// secondary, comparing the matched ingredient and its level.
+func (d *DualSearcher) SearchFodmap(ctx context.Context, ingredient string) (search.FodmapResult, float64, error) {
+	res, score, err := d.primary.SearchFodmap(ctx, ingredient)
+	if err == nil {
+		d.shadow(ctx, "fodmap", ingredient, func(ctx context.Context) ([]any, error) {
+			sec, secScore, err := d.secondary.SearchFodmap(ctx, ingredient)
+			if err != nil {
+				return nil, err
+			}
+			return []any{
+				"same_ingredient", sec.Ingredient == res.Ingredient,
+				"same_level", sec.Level == res.Level,
+				"primary_ingredient", res.Ingredient,
+				"secondary_ingredient", sec.Ingredient,
+				"primary_score", score,
+				"secondary_score", secScore,
+			}, nil
+		})
+	}
+	return res, score, err
+}
+
+// shadow runs compare against the secondary in the background when shadow
+// reads are on and a slot is free, and logs the attributes it returns or
+// its error.
+// compare gets a context detached from the request's cancellation, so the
+// comparison outlives a response that has already been written.
+func (d *DualSearcher) shadow(ctx context.Context, kind, query string, compare func(context.Context) ([]any, error)) {
+	if !d.shadowRead {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+	select {
+	case d.shadowSlots <- struct{}{}:
+	default:
+		slog.Debug("dual searcher: shadow read skipped, all slots busy", "kind", kind)
+		return
+	}
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer func() { <-d.shadowSlots }()
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shadowReadTimeout)
+		defer cancel()
+		start := time.Now()
+		attrs, err := compare(ctx)
+		if err != nil {
+			slog.Warn("dual searcher: shadow read failed", "kind", kind, "query", query, "error", err)
+			return
+		}
+		attrs = append([]any{"kind", kind, "query", query}, attrs...)
+		slog.Info("dual searcher: shadow read", append(attrs, "secondary_ms", time.Since(start).Milliseconds())...)
+	}()
+}
+
+// rankingAttrs returns the log attributes comparing two rankings.
+func rankingAttrs(primary, secondary []string, limit int, primaryElapsed time.Duration) []any {
+	k := limit
+	if k <= 0 {
+		k = max(len(primary), len(secondary))
+	}
+	attrs := []any{
+		"k", k,
+		"primary_hits", len(primary),
+		"secondary_hits", len(secondary),
+		"overlap_at_k", overlapAtK(primary, secondary, k),
+		"primary_ms", primaryElapsed.Milliseconds(),
+	}
+	if rho, ok := rankCorrelation(primary, secondary); ok {
+		attrs = append(attrs, "rank_correlation", rho)
+	}
+	return attrs
+}
+
+// overlapAtK is the fraction of the top k results the two rankings share,
+// relative to the longer of the two top-k lists; two empty rankings agree.
+func overlapAtK(a, b []string, k int) float64 {
+	a, b = a[:min(k, len(a))], b[:min(k, len(b))]
+	n := max(len(a), len(b))
+	if n == 0 {
+		return 1
+	}
+	inA := make(map[string]bool, len(a))
+	for _, id := range a {
+		inA[id] = true
+	}
+	shared := 0
+	for _, id := range b {
+		if inA[id] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(n)
+}
+
+// rankCorrelation is Spearman's rank correlation of the results both
+// rankings contain, ranked by their order within each ranking. It is
+// undefined (ok false) for fewer than two shared results.
+func rankCorrelation(a, b []string) (rho float64, ok bool) {
+	posB := make(map[string]int, len(b))
+	for i, id := range b {
+		if _, dup := posB[id]; !dup {
+			posB[id] = i
+		}
+	}
+	// Shared results in a's order, with their position in b.
+	var order []int
+	seen := make(map[string]bool, len(a))
+	for _, id := range a {
+		if j, found := posB[id]; found && !seen[id] {
+			seen[id] = true
+			order = append(order, j)
+		}
+	}
+	n := len(order)
+	if n < 2 {
+		return 0, false
+	}
+	// Rank of each shared result within b, by position.
+	rankB := make(map[int]int, n)
+	sorted := slices.Sorted(slices.Values(order))
+	for r, j := range sorted {
+		rankB[j] = r
+	}
+	var sumD2 float64
+	for r, j := range order {
+		dr := float64(r - rankB[j])
+		sumD2 += dr * dr
+	}
+	nf := float64(n)
+	return 1 - 6*sumD2/(nf*(nf*nf-1)), true
+}
+
+// businessIDs lists a business result's keys in rank order. The backends
+// give a business different IDs, so it is keyed by name and place
+// (BusinessResult.Key).
+func businessIDs(res search.SearchResult) []string {
+	ids := make([]string, len(res.Businesses))
+	for i, b := range res.Businesses {
+		ids[i] = b.Key()
+	}
+	return ids
+}
+
+// reviewIDs lists a review result's IDs in rank order.
+func reviewIDs(res search.SearchReviews) []string {
+	ids := make([]string, len(res.BusinessReviews))
+	for i, r := range res.BusinessReviews {
+		ids[i] = r.Review.Review.ReviewID
+	}
+	return ids
+}
+
+var (
+	_ Searcher     = (*DualSearcher)(nil)
+	_ FodmapWriter = (*DualSearcher)(nil)
+)
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"strings"
+	"testing"
+
+	"fodmap/data"
+	"fodmap/search"
+
+	"github.com/google/uuid"
+)
+
+// dualSearcherStub is a recording Searcher stub for dual-searcher tests.
+type dualSearcherStub struct {
+	handlersTestSearcher
+	upsertErr     error
+	upserted      [][]search.IndexItem
+	fodmapUpserts []string
+	reviewCalls   int
+}
+
+func (s *dualSearcherStub) Reviews(ctx context.Context, q string, limit int, f search.SearchFilter) (search.SearchReviews, error) {
+	s.reviewCalls++
+	return s.handlersTestSearcher.Reviews(ctx, q, limit, f)
+}
+
+func (s *dualSearcherStub) BatchUpsert(_ context.Context, items []search.IndexItem) error {
+	s.upserted = append(s.upserted, items)
+	return s.upsertErr
+}
+
+func (s *dualSearcherStub) UpsertFodmapItem(_ context.Context, name string, _ data.FodmapEntry) error {
+	s.fodmapUpserts = append(s.fodmapUpserts, name)
+	return nil
+}
+
+func (s *dualSearcherStub) DeleteFodmapItem(_ context.Context, _ string) error {
+	return nil
+}
+
+func rankedReviews(ids ...string) search.SearchReviews {
+	var res search.SearchReviews
+	for _, id := range ids {
+		var r search.RankedReview
+		r.Review.Review.ReviewID = id
+		res.BusinessReviews = append(res.BusinessReviews, r)
+	}
+	return res
+}
+
+// captureLog routes the default logger to a buffer for the test's duration.
+func captureLog(t *testing.T) *strings.Builder {
+	t.Helper()
+	var buf strings.Builder
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+func TestDualSearcher_SecondaryUpsertFailureIsLogged(t *testing.T) {
+	primary := &dualSearcherStub{}
+	secondary := &dualSearcherStub{upsertErr: errors.New("postgres down")}
+	d := NewDualSearcher(primary, secondary, false)
+	buf := captureLog(t)
+
+	if err := d.BatchUpsert(context.Background(), []search.IndexItem{{}}); err != nil {
+		t.Fatalf("secondary failure should not propagate; got %v", err)
+	}
+	if len(primary.upserted) != 1 || len(secondary.upserted) != 1 {
+		t.Errorf("upserts: primary %d, secondary %d; want 1 each", len(primary.upserted), len(secondary.upserted))
+	}
+	if !strings.Contains(buf.String(), "secondary upsert failed") {
+		t.Errorf("expected warn log about secondary failure, got: %s", buf.String())
+	}
+}
+
+func TestDualSearcher_PrimaryUpsertFailurePropagates(t *testing.T) {
+	primary := &dualSearcherStub{upsertErr: errors.New("weaviate down")}
+	secondary := &dualSearcherStub{}
+	d := NewDualSearcher(primary, secondary, false)
+
+	if err := d.BatchUpsert(context.Background(), []search.IndexItem{{}}); err == nil {
+		t.Fatal("primary failure must propagate")
+	}
+	if len(secondary.upserted) != 0 {
+		t.Error("secondary should not be written when the primary fails")
+	}
+}
+
+func TestDualSearcher_MirrorsIngredientWrites(t *testing.T) {
+	primary := &dualSearcherStub{}
+	secondary := &dualSearcherStub{}
+	var fw FodmapWriter = NewDualSearcher(primary, secondary, false)
+
+	if err := fw.UpsertFodmapItem(context.Background(), "garlic", data.FodmapEntry{Level: "high"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(primary.fodmapUpserts) != 1 || len(secondary.fodmapUpserts) != 1 {
+		t.Errorf("ingredient upserts: primary %v, secondary %v", primary.fodmapUpserts, secondary.fodmapUpserts)
+	}
+}
+
+func TestDualSearcher_ShadowReadLogsDivergence(t *testing.T) {
+	primary := &dualSearcherStub{handlersTestSearcher: handlersTestSearcher{reviewResult: rankedReviews("a", "b", "c")}}
+	secondary := &dualSearcherStub{handlersTestSearcher: handlersTestSearcher{reviewResult: rankedReviews("b", "a", "d")}}
+	d := NewDualSearcher(primary, secondary, true)
+	buf := captureLog(t)
+
+	res, err := d.Reviews(context.Background(), "gluten free", 3, search.SearchFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = d.Close()
+
+	if got := reviewIDs(res); strings.Join(got, ",") != "a,b,c" {
+		t.Errorf("response = %v, want the primary's a,b,c", got)
+	}
+	if secondary.reviewCalls != 1 {
+		t.Errorf("secondary reviews calls = %d, want 1", secondary.reviewCalls)
+	}
+	out := buf.String()
+	for _, want := range []string{"kind=reviews", "overlap_at_k=0.666", "rank_correlation=-1"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log missing %q: %s", want, out)
+		}
+	}
+}
+
+func TestDualSearcher_NoShadowReadByDefault(t *testing.T) {
+	primary := &dualSearcherStub{}
+	secondary := &dualSearcherStub{}
+	d := NewDualSearcher(primary, secondary, false)
+
+	if _, err := d.Reviews(context.Background(), "q", 5, search.SearchFilter{}); err != nil {
+		t.Fatal(err)
+	}
+	_ = d.Close()
+	if secondary.reviewCalls != 0 {
+		t.Errorf("secondary read %d times without shadow reads", secondary.reviewCalls)
+	}
+}
+
+func TestDualSearcher_NoShadowReadAfterClose(t *testing.T) {
+	primary := &dualSearcherStub{}
+	secondary := &dualSearcherStub{}
+	d := NewDualSearcher(primary, secondary, true)
+	_ = d.Close()
+
+	if _, err := d.Reviews(context.Background(), "q", 5, search.SearchFilter{}); err != nil {
+		t.Fatal(err)
+	}
+	d.wg.Wait()
+	if secondary.reviewCalls != 0 {
+		t.Errorf("secondary read %d times after Close", secondary.reviewCalls)
+	}
+}
+
+func TestUnwrapSearcher(t *testing.T) {
+	primary := &dualSearcherStub{}
+	if got := UnwrapSearcher(NewDualSearcher(primary, &dualSearcherStub{}, false)); got != Searcher(primary) {
+		t.Errorf("UnwrapSearcher(dual) = %v, want the primary", got)
+	}
+	if got := UnwrapSearcher(primary); got != Searcher(primary) {
+		t.Errorf("UnwrapSearcher(plain) = %v, want it unchanged", got)
+	}
+}
+
+func TestBusinessIDs_AcrossBackends(t *testing.T) {
+	// Postgres returns the restaurants.id; Weaviate uuid.Nil for the same
+	// Yelp business.
+	postgres := search.SearchResult{Businesses: []search.BusinessResult{
+		{ID: uuid.New(), Name: "Joe's Pizza", City: "Boston", State: "MA"},
+		{ID: uuid.New(), Name: "Thai Palace", City: "Cambridge", State: "MA"},
+	}}
+	weaviate := search.SearchResult{Businesses: []search.BusinessResult{
+		{ID: uuid.Nil, Name: "Thai  Palace", City: "cambridge", State: "MA"},
+		{ID: uuid.Nil, Name: "Joe's Pizza", City: "Boston", State: "MA"},
+	}}
+	a, b := businessIDs(postgres), businessIDs(weaviate)
+	if got := overlapAtK(a, b, 2); got != 1 {
+		t.Errorf("overlap of the same businesses = %v, want 1", got)
+	}
+	if rho, ok := rankCorrelation(a, b); !ok || rho != -1 {
+		t.Errorf("rank correlation = %v, %v; want -1, true", rho, ok)
+	}
+}
+
+func TestOverlapAtK(t *testing.T) {
+	tests := []struct {
+		a, b []string
+		k    int
+		want float64
+	}{
+		{nil, nil, 10, 1},
+		{[]string{"a", "b"}, []string{"b", "a"}, 10, 1},
+		{[]string{"a", "b", "c"}, []string{"a", "x", "y"}, 3, 1.0 / 3},
+		{[]string{"a", "b", "c"}, []string{"c", "b", "a"}, 1, 0},
+		{[]string{"a"}, nil, 5, 0},
+	}
+	for _, tt := range tests {
+		if got := overlapAtK(tt.a, tt.b, tt.k); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("overlapAtK(%v, %v, %d) = %v, want %v", tt.a, tt.b, tt.k, got, tt.want)
+		}
+	}
+}
+
+func TestRankCorrelation(t *testing.T) {
+	tests := []struct {
+		a, b   []string
+		want   float64
+		wantOK bool
+	}{
+		{[]string{"a", "b", "c"}, []string{"a", "b", "c"}, 1, true},
+		{[]string{"a", "b", "c"}, []string{"c", "b", "a"}, -1, true},
+		{[]string{"a", "b", "c"}, []string{"x", "a", "y", "c", "b"}, 0.5, true},
+		{[]string{"a", "b"}, []string{"a", "x"}, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := rankCorrelation(tt.a, tt.b)
+		if ok != tt.wantOK || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("rankCorrelation(%v, %v) = %v, %v; want %v, %v", tt.a, tt.b, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// favoriteStore returns the restaurant store's favorites capability, or nil.
+func (s *Server) favoriteStore() FavoriteStore {
+	fs, _ := s.restaurantStore.(FavoriteStore)
+	return fs
+}
+
+func (s *Server) listFavoritesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(userContextKey).(string)
+	if !ok {
+		respondError(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	fs := s.favoriteStore()
+	if fs == nil {
+		respondError(w, "favorites not supported", http.StatusNotImplemented)
+		return
+	}
+
+	favs, err := fs.ListFavorites(r.Context(), userID)
+	if err != nil {
+		respondError(w, "failed to list favorites", http.StatusInternalServerError)
+		return
+	}
+	if favs == nil {
+		favs = []FavoriteRestaurant{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string][]FavoriteRestaurant{"favorites": favs})
+}
+
+func (s *Server) addFavoriteHandler(w http.ResponseWriter, r *http.Request) {
+	s.setFavorite(w, r, true)
+}
+
+func (s *Server) removeFavoriteHandler(w http.ResponseWriter, r *http.Request) {
+	s.setFavorite(w, r, false)
+}
+
+func (s *Server) setFavorite(w http.ResponseWriter, r *http.Request, on bool) {
+	userID, ok := r.Context().Value(userContextKey).(string)
+	if !ok {
+		respondError(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	fs := s.favoriteStore()
+	if fs == nil {
+		respondError(w, "favorites not supported", http.StatusNotImplemented)
+		return
+	}
+	businessID, err := uuid.Parse(r.PathValue("business_id"))
+	if err != nil {
+		respondError(w, "business_id must be a valid UUID", http.StatusBadRequest)
+		return
+	}
+
+	if on {
+		err = fs.AddFavorite(r.Context(), userID, businessID)
+	} else {
+		err = fs.RemoveFavorite(r.Context(), userID, businessID)
+	}
+	if errors.Is(err, ErrRestaurantNotFound) {
+		respondError(w, "restaurant not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		respondError(w, "failed to update favorite", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// favoriteRestaurantStore is a stubRestaurantStore with favorites.
+type favoriteRestaurantStore struct {
+	*stubRestaurantStore
+	favs map[string][]uuid.UUID
+}
+
+func (s *favoriteRestaurantStore) AddFavorite(ctx context.Context, userID string, businessID uuid.UUID) error {
+	if r, _ := s.GetByID(ctx, businessID); r == nil {
+		return ErrRestaurantNotFound
+	}
+	s.favs[userID] = append(s.favs[userID], businessID)
+	return nil
+}
+
+func (s *favoriteRestaurantStore) RemoveFavorite(_ context.Context, userID string, businessID uuid.UUID) error {
+	kept := s.favs[userID][:0]
+	for _, id := range s.favs[userID] {
+		if id != businessID {
+			kept = append(kept, id)
+		}
+	}
+	s.favs[userID] = kept
+	return nil
+}
+
+func (s *favoriteRestaurantStore) ListFavorites(ctx context.Context, userID string) ([]FavoriteRestaurant, error) {
+	var out []FavoriteRestaurant
+	for _, id := range s.favs[userID] {
+		r, _ := s.GetByID(ctx, id)
+		out = append(out, FavoriteRestaurant{ID: id, Name: r.DBA, Status: r.Status})
+	}
+	return out, nil
+}
+
+func favoriteRequest(s *Server, method, path, userID string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/favorites", s.listFavoritesHandler)
+	mux.HandleFunc("PUT /api/v1/favorites/{business_id}", s.addFavoriteHandler)
+	mux.HandleFunc("DELETE /api/v1/favorites/{business_id}", s.removeFavoriteHandler)
+	req := httptest.NewRequest(method, path, nil)
+	if userID != "" {
+		req = req.WithContext(context.WithValue(req.Context(), userContextKey, userID))
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestFavoritesHandlers(t *testing.T) {
+	rs := &favoriteRestaurantStore{stubRestaurantStore: newStubRestaurantStore(), favs: map[string][]uuid.UUID{}}
+	rest, _ := rs.Upsert(context.Background(), Restaurant{DBA: "Thai Spot", Status: "scraped"})
+	s := &Server{restaurantStore: rs}
+	path := "/api/v1/favorites/" + rest.ID.String()
+
+	if w := favoriteRequest(s, http.MethodPut, path, "u1"); w.Code != http.StatusNoContent {
+		t.Fatalf("PUT status = %d: %s", w.Code, w.Body.String())
+	}
+	w := favoriteRequest(s, http.MethodGet, "/api/v1/favorites", "u1")
+	var body struct {
+		Favorites []FavoriteRestaurant `json:"favorites"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Favorites) != 1 || body.Favorites[0].Name != "Thai Spot" {
+		t.Errorf("favorites = %+v", body.Favorites)
+	}
+
+	if w := favoriteRequest(s, http.MethodDelete, path, "u1"); w.Code != http.StatusNoContent {
+		t.Fatalf("DELETE status = %d", w.Code)
+	}
+	if len(rs.favs["u1"]) != 0 {
+		t.Errorf("favorite not removed: %v", rs.favs["u1"])
+	}
+
+	if w := favoriteRequest(s, http.MethodPut, "/api/v1/favorites/"+uuid.NewString(), "u1"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown restaurant: status = %d, want 404", w.Code)
+	}
+	if w := favoriteRequest(s, http.MethodPut, "/api/v1/favorites/nope", "u1"); w.Code != http.StatusBadRequest {
+		t.Errorf("bad id: status = %d, want 400", w.Code)
+	}
+	if w := favoriteRequest(s, http.MethodGet, "/api/v1/favorites", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("no user: status = %d, want 401", w.Code)
+	}
+	if w := favoriteRequest(&Server{restaurantStore: newStubRestaurantStore()}, http.MethodGet, "/api/v1/favorites", "u1"); w.Code != http.StatusNotImplemented {
+		t.Errorf("no favorites support: status = %d, want 501", w.Code)
+	}
+}
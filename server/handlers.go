@@ -2,12 +2,15 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"fodmap/data"
+	"fodmap/queryparse"
 	"fodmap/search"
 
 	"github.com/google/uuid"
@@ -68,8 +71,18 @@ func (s *Server) getBusinessesHandler(w http.ResponseWriter, r *http.Request) {
 		}
 		filter.Alpha = float32(f)
 	}
+	geoFilter, err := parseGeoFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	filter.Geo = geoFilter
 
-	result, err := s.searcher.Businesses(r.Context(), q, limit, filter)
+	result, parsed, err := s.searchBusinesses(r.Context(), q, limit, filter)
+	if errors.Is(err, search.ErrGeoFilterUnsupported) {
+		http.Error(w, `{"error":"search backend does not support geo filters"}`, http.StatusNotImplemented)
+		return
+	}
 	if err != nil {
 		slog.Error("Search error in getBusinessesHandler", "error", err, "query", q, "filter", filter)
 		http.Error(w, `{"error":"search failed"}`, http.StatusInternalServerError)
@@ -94,8 +107,12 @@ func (s *Server) getBusinessesHandler(w http.ResponseWriter, r *http.Request) {
 	for i, b := range result.Businesses {
 		out[i] = business{ID: b.ID, Name: b.Name, City: b.City, State: b.State, Categories: b.Categories, Rating: b.Stars, Score: b.Score}
 	}
+	resp := struct {
+		Businesses     []business         `json:"businesses"`
+		Interpretation *queryparse.Result `json:"interpretation,omitempty"`
+	}{out, parsed}
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(map[string][]business{"businesses": out}); err != nil {
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		slog.Error("encode error", "error", err)
 	}
 }
@@ -144,7 +161,7 @@ func (s *Server) getReviewsHandler(w http.ResponseWriter, r *http.Request) {
 		filter.Alpha = float32(f)
 	}
 
-	result, err := s.searcher.Reviews(r.Context(), q, limit, filter)
+	result, parsed, err := s.searchReviews(r.Context(), q, limit, filter)
 	if err != nil {
 		slog.Error("search error", "error", err)
 		http.Error(w, `{"error":"search failed"}`, http.StatusInternalServerError)
@@ -176,8 +193,12 @@ func (s *Server) getReviewsHandler(w http.ResponseWriter, r *http.Request) {
 			Score:        rr.Score,
 		}
 	}
+	resp := struct {
+		Reviews        []review           `json:"reviews"`
+		Interpretation *queryparse.Result `json:"interpretation,omitempty"`
+	}{out, parsed}
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(map[string][]review{"reviews": out}); err != nil {
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		slog.Error("encode error", "error", err)
 	}
 }
@@ -24,17 +24,19 @@ type handlersTestSearcher struct {
 	fodmapResult       search.FodmapResult
 	fodmapCert         float64
 	fodmapErr          error
+	lastBusinessQuery  string
 	lastBusinessFilter search.SearchFilter
+	lastReviewQuery    string
 	lastReviewFilter   search.SearchFilter
 }
 
-func (m *handlersTestSearcher) Businesses(_ context.Context, _ string, _ int, filter search.SearchFilter) (search.SearchResult, error) {
-	m.lastBusinessFilter = filter
+func (m *handlersTestSearcher) Businesses(_ context.Context, q string, _ int, filter search.SearchFilter) (search.SearchResult, error) {
+	m.lastBusinessQuery, m.lastBusinessFilter = q, filter
 	return m.businessResult, m.businessErr
 }
 
-func (m *handlersTestSearcher) Reviews(_ context.Context, _ string, _ int, filter search.SearchFilter) (search.SearchReviews, error) {
-	m.lastReviewFilter = filter
+func (m *handlersTestSearcher) Reviews(_ context.Context, q string, _ int, filter search.SearchFilter) (search.SearchReviews, error) {
+	m.lastReviewQuery, m.lastReviewFilter = q, filter
 	return m.reviewResult, m.reviewErr
 }
 
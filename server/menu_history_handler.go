@@ -0,0 +1,55 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"fodmap/search"
+
+	"github.com/google/uuid"
+)
+
+// menuTimelineHandler serves GET /api/v1/menu/{business_id}/timeline: the
+// restaurant's menu snapshots newest first, each listing the dishes added,
+// removed, repriced or changed since the previous scrape. item_id narrows
+// the timeline to one dish, e.g. to check whether a dish that was safe last
+// month changed its sauce.
+func (s *Server) menuTimelineHandler(w http.ResponseWriter, r *http.Request) {
+	mv, ok := s.resolveMenuStore().(MenuVersioner)
+	if !ok {
+		http.Error(w, `{"error":"menu store does not keep menu history"}`, http.StatusNotImplemented)
+		return
+	}
+	businessID, err := uuid.Parse(r.PathValue("business_id"))
+	if err != nil {
+		http.Error(w, `{"error":"business_id must be a valid UUID"}`, http.StatusBadRequest)
+		return
+	}
+	params := r.URL.Query()
+	limit, err := parseGeoLimit(params, 20)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	snaps, err := mv.MenuTimeline(r.Context(), businessID, params.Get("item_id"), limit)
+	if errors.Is(err, search.ErrMenuHistoryUnsupported) {
+		http.Error(w, `{"error":"menu store does not keep menu history"}`, http.StatusNotImplemented)
+		return
+	}
+	if err != nil {
+		slog.Error("menu timeline error", "error", err, "business_id", businessID)
+		http.Error(w, `{"error":"failed to load menu timeline"}`, http.StatusInternalServerError)
+		return
+	}
+	if snaps == nil {
+		snaps = []search.MenuSnapshot{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{"business_id": businessID, "snapshots": snaps}); err != nil {
+		slog.Error("encode error", "error", err)
+	}
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fodmap/search"
+
+	"github.com/google/uuid"
+)
+
+// versionedMenuStore is a MenuStore stub with menu history.
+type versionedMenuStore struct {
+	menuStoreStub
+	snap        *search.MenuSnapshot
+	timeline    []search.MenuSnapshot
+	replaced    [][]search.MenuItem
+	gotBusiness uuid.UUID
+	gotItem     string
+	gotLimit    int
+}
+
+func (s *versionedMenuStore) ReplaceMenu(_ context.Context, items []search.MenuItem) (*search.MenuSnapshot, error) {
+	s.replaced = append(s.replaced, items)
+	return s.snap, nil
+}
+
+func (s *versionedMenuStore) MenuTimeline(_ context.Context, businessID uuid.UUID, itemID string, limit int) ([]search.MenuSnapshot, error) {
+	s.gotBusiness, s.gotItem, s.gotLimit = businessID, itemID, limit
+	return s.timeline, nil
+}
+
+// deletingMenuStore is a MenuStore stub that records deletions.
+type deletingMenuStore struct {
+	menuStoreStub
+	deleted []string
+}
+
+func (s *deletingMenuStore) DeleteMenuItems(_ context.Context, ids []string) error {
+	s.deleted = append(s.deleted, ids...)
+	return nil
+}
+
+func TestDualMenuStore_ReplaceMenu_DeletesRemovedFromSecondary(t *testing.T) {
+	primary := &versionedMenuStore{snap: &search.MenuSnapshot{Changes: []search.MenuChange{
+		{MenuItemID: "new", Type: search.MenuItemAdded},
+		{MenuItemID: "gone", Type: search.MenuItemRemoved},
+	}}}
+	secondary := &deletingMenuStore{}
+	d := NewDualMenuStore(primary, secondary)
+
+	items := []search.MenuItem{{MenuItemID: "new"}}
+	if _, err := d.ReplaceMenu(context.Background(), items); err != nil {
+		t.Fatalf("ReplaceMenu: %v", err)
+	}
+	if len(primary.replaced) != 1 || len(primary.upserted) != 0 {
+		t.Errorf("primary should be replaced, not upserted: replaced=%d upserted=%d", len(primary.replaced), len(primary.upserted))
+	}
+	if len(secondary.upserted) != 1 {
+		t.Errorf("secondary should mirror the items, got %d batches", len(secondary.upserted))
+	}
+	if len(secondary.deleted) != 1 || secondary.deleted[0] != "gone" {
+		t.Errorf("secondary deleted %v, want [gone]", secondary.deleted)
+	}
+}
+
+func TestDualMenuStore_ReplaceMenu_FallsBackToUpsert(t *testing.T) {
+	primary := &menuStoreStub{}
+	d := NewDualMenuStore(primary, nil)
+
+	snap, err := d.ReplaceMenu(context.Background(), []search.MenuItem{{MenuItemID: "a"}})
+	if err != nil || snap != nil {
+		t.Fatalf("ReplaceMenu = %v, %v; want nil snapshot and no error", snap, err)
+	}
+	if len(primary.upserted) != 1 {
+		t.Errorf("primary should be upserted, got %d batches", len(primary.upserted))
+	}
+	if _, err := d.MenuTimeline(context.Background(), uuid.New(), "", 10); err != search.ErrMenuHistoryUnsupported {
+		t.Errorf("MenuTimeline err = %v, want ErrMenuHistoryUnsupported", err)
+	}
+}
+
+func menuTimeline(s *Server, path string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/menu/{business_id}/timeline", s.menuTimelineHandler)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestMenuTimelineHandler(t *testing.T) {
+	bid := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	oldPrice, newPrice := 16.0, 17.0
+	ms := &versionedMenuStore{timeline: []search.MenuSnapshot{{
+		BusinessID: bid,
+		ItemCount:  12,
+		Changes: []search.MenuChange{{
+			MenuItemID: "curry", DishName: "Green Curry", Type: search.MenuItemIngredientsChanged,
+			OldIngredients: []string{"coconut milk"}, NewIngredients: []string{"coconut milk", "garlic"},
+			OldPrice: &oldPrice, NewPrice: &newPrice,
+		}},
+	}}}
+	s := &Server{menuStore: ms}
+
+	w := menuTimeline(s, "/api/v1/menu/"+bid.String()+"/timeline?item_id=curry&limit=5")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	if ms.gotBusiness != bid || ms.gotItem != "curry" || ms.gotLimit != 5 {
+		t.Errorf("store got business=%s item=%q limit=%d", ms.gotBusiness, ms.gotItem, ms.gotLimit)
+	}
+	var body struct {
+		Snapshots []struct {
+			ItemCount int `json:"item_count"`
+			Changes   []struct {
+				Type           string   `json:"type"`
+				NewIngredients []string `json:"new_ingredients"`
+			} `json:"changes"`
+		} `json:"snapshots"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Snapshots) != 1 || body.Snapshots[0].ItemCount != 12 {
+		t.Fatalf("snapshots = %+v", body.Snapshots)
+	}
+	ch := body.Snapshots[0].Changes
+	if len(ch) != 1 || ch[0].Type != "ingredients_changed" || len(ch[0].NewIngredients) != 2 {
+		t.Errorf("changes = %+v", ch)
+	}
+}
+
+func TestMenuTimelineHandler_Errors(t *testing.T) {
+	if w := menuTimeline(&Server{menuStore: &versionedMenuStore{}}, "/api/v1/menu/not-a-uuid/timeline"); w.Code != http.StatusBadRequest {
+		t.Errorf("bad uuid: status = %d, want 400", w.Code)
+	}
+	if w := menuTimeline(&Server{menuStore: &menuStoreStub{}}, "/api/v1/menu/"+uuid.NewString()+"/timeline"); w.Code != http.StatusNotImplemented {
+		t.Errorf("no history: status = %d, want 501", w.Code)
+	}
+	if w := menuTimeline(&Server{menuStore: NewDualMenuStore(&menuStoreStub{}, nil)}, "/api/v1/menu/"+uuid.NewString()+"/timeline"); w.Code != http.StatusNotImplemented {
+		t.Errorf("dual without history: status = %d, want 501", w.Code)
+	}
+}
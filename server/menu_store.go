@@ -6,6 +6,8 @@ import (
 	"log/slog"
 
 	"fodmap/search"
+
+	"github.com/google/uuid"
 )
 
 // DualMenuStore writes menu items to a primary MenuStore (the source of
@@ -60,8 +62,8 @@ func (d *DualMenuStore) BatchUpsertMenu(ctx context.Context, items []search.Menu
 }
 
 // SearchMenu reads from the primary only. The secondary is write-only.
-func (d *DualMenuStore) SearchMenu(ctx context.Context, query string, limit int) ([]search.MenuItem, error) {
-	return d.primary.SearchMenu(ctx, query, limit)
+func (d *DualMenuStore) SearchMenu(ctx context.Context, query string, limit int, filter search.SearchFilter) ([]search.MenuItem, error) {
+	return d.primary.SearchMenu(ctx, query, limit, filter)
 }
 
 // ListMenuItems reads from the primary only.
@@ -69,16 +71,109 @@ func (d *DualMenuStore) ListMenuItems(ctx context.Context, search string, limit,
 	return d.primary.ListMenuItems(ctx, search, limit, offset)
 }
 
-// Compile-time check: DualMenuStore satisfies MenuStore.
-var _ MenuStore = (*DualMenuStore)(nil)
+// ReplaceMenu versions the scrape on the primary when it is a MenuVersioner,
+// otherwise it falls back to BatchUpsertMenu and returns a nil snapshot. The
+// items are then mirrored to the secondary best-effort, and items the
+// primary retired are deleted from it when it supports deletion.
+func (d *DualMenuStore) ReplaceMenu(ctx context.Context, items []search.MenuItem) (*search.MenuSnapshot, error) {
+	mv, ok := d.primary.(MenuVersioner)
+	if !ok {
+		return nil, d.BatchUpsertMenu(ctx, items)
+	}
+	snap, err := mv.ReplaceMenu(ctx, items)
+	if err != nil {
+		return nil, fmt.Errorf("primary replace menu: %w", err)
+	}
+	if d.secondary == nil {
+		return snap, nil
+	}
+	if err := d.secondary.BatchUpsertMenu(ctx, items); err != nil {
+		slog.Warn("dual menu store: secondary upsert failed (primary succeeded)",
+			"items", len(items), "error", err)
+	}
+	deleter, ok := d.secondary.(MenuItemDeleter)
+	if !ok || snap == nil {
+		return snap, nil
+	}
+	var removed []string
+	for _, ch := range snap.Changes {
+		if ch.Type == search.MenuItemRemoved {
+			removed = append(removed, ch.MenuItemID)
+		}
+	}
+	if len(removed) > 0 {
+		if err := deleter.DeleteMenuItems(ctx, removed); err != nil {
+			slog.Warn("dual menu store: secondary delete failed (primary succeeded)",
+				"items", len(removed), "error", err)
+		}
+	}
+	return snap, nil
+}
+
+// MenuTimeline reads from the primary only. It returns
+// search.ErrMenuHistoryUnsupported when the primary keeps no history.
+func (d *DualMenuStore) MenuTimeline(ctx context.Context, businessID uuid.UUID, itemID string, limit int) ([]search.MenuSnapshot, error) {
+	mv, ok := d.primary.(MenuVersioner)
+	if !ok {
+		return nil, search.ErrMenuHistoryUnsupported
+	}
+	return mv.MenuTimeline(ctx, businessID, itemID, limit)
+}
+
+// CollectionEmbedder is implemented by menu stores that embed with their
+// collections' active embedding spaces (search.PostgresClient). It returns
+// nil when the store keeps no embedding spaces.
+type CollectionEmbedder interface {
+	CollectionEmbedder(collection string) search.Embedder
+}
+
+// CollectionEmbedder returns the primary's collection embedder, or nil when
+// the primary has none.
+func (d *DualMenuStore) CollectionEmbedder(collection string) search.Embedder {
+	ce, ok := d.primary.(CollectionEmbedder)
+	if !ok {
+		return nil
+	}
+	return ce.CollectionEmbedder(collection)
+}
+
+// MenuEmbedder returns the embedder scraped menus written to store should
+// be embedded with: the menu collection's active embedding space when store
+// keeps embedding spaces, else fallback.
+func MenuEmbedder(store MenuStore, fallback search.Embedder) search.Embedder {
+	if ce, ok := store.(CollectionEmbedder); ok {
+		if e := ce.CollectionEmbedder(search.CollectionMenu); e != nil {
+			return e
+		}
+	}
+	return fallback
+}
+
+// Compile-time checks for the menu history and embedding space
+// capabilities.
+var (
+	_ MenuStore          = (*DualMenuStore)(nil)
+	_ MenuVersioner      = (*DualMenuStore)(nil)
+	_ MenuVersioner      = (*search.PostgresClient)(nil)
+	_ CollectionEmbedder = (*DualMenuStore)(nil)
+	_ CollectionEmbedder = (*search.PostgresClient)(nil)
+
+	_ Searcher        = (*search.LocalClient)(nil)
+	_ FodmapWriter    = (*search.LocalClient)(nil)
+	_ MenuStore       = (*search.LocalClient)(nil)
+	_ MenuItemDeleter = (*search.LocalClient)(nil)
+)
 
 // MenuStoreConfig selects a MenuStore backend.
 //
-// Type is one of "postgres" (default), "weaviate", or "dual". "dual" writes
-// Postgres primary + Weaviate best-effort mirror and reads from Postgres
-// only. Per-backend fields are required only for the selected type.
+// Type is one of "postgres" (default), "weaviate", "dual" or "local". "dual"
+// writes Postgres primary + Weaviate best-effort mirror and reads from
+// Postgres only. "local" is the embedded on-disk backend in LocalIndexDir,
+// for development without services. Per-backend fields are required only
+// for the selected type.
 type MenuStoreConfig struct {
-	Type           string // "postgres" | "weaviate" | "dual" (default "postgres")
+	Type           string // "postgres" | "weaviate" | "dual" | "local" (default "postgres")
+	LocalIndexDir  string
 	PostgresDSN    string
 	WeaviateHost   string
 	WeaviateScheme string
@@ -93,13 +188,22 @@ type MenuStoreConfig struct {
 // "dual" requires both a Postgres DSN and a Weaviate host; a missing
 // dependency errors explicitly rather than silently degrading to a single
 // backend (the whole point of dual mode is to exercise both stores).
+//
+// Postgres stores follow the active embedding spaces (see
+// search.PostgresClient.FollowEmbeddingSpaces), so menu queries switch model
+// when the menu collection is cut over.
 func NewMenuStore(ctx context.Context, cfg MenuStoreConfig) (MenuStore, error) {
 	switch cfg.Type {
 	case "", "postgres":
 		if cfg.PostgresDSN == "" {
 			return nil, fmt.Errorf("menu-store=postgres requires --postgres-dsn")
 		}
-		return search.NewPostgresClient(cfg.PostgresDSN, cfg.Embedder)
+		pg, err := search.NewPostgresClient(cfg.PostgresDSN, cfg.Embedder)
+		if err != nil {
+			return nil, err
+		}
+		pg.FollowEmbeddingSpaces(search.DefaultEmbeddingSpaceTTL)
+		return pg, nil
 
 	case "weaviate":
 		if cfg.WeaviateHost == "" {
@@ -125,6 +229,7 @@ func NewMenuStore(ctx context.Context, cfg MenuStoreConfig) (MenuStore, error) {
 		if err != nil {
 			return nil, fmt.Errorf("postgres client: %w", err)
 		}
+		pg.FollowEmbeddingSpaces(search.DefaultEmbeddingSpaceTTL)
 		wc, err := search.NewClient(cfg.WeaviateHost, cfg.WeaviateScheme, cfg.WeaviateAPIKey, cfg.Embedder)
 		if err != nil {
 			return nil, fmt.Errorf("weaviate client: %w", err)
@@ -134,7 +239,13 @@ func NewMenuStore(ctx context.Context, cfg MenuStoreConfig) (MenuStore, error) {
 		}
 		return NewDualMenuStore(pg, wc), nil
 
+	case "local":
+		if cfg.LocalIndexDir == "" {
+			return nil, fmt.Errorf("menu-store=local requires --local-index")
+		}
+		return search.NewLocalClient(cfg.LocalIndexDir, cfg.Embedder)
+
 	default:
-		return nil, fmt.Errorf("unknown --menu-store %q (want postgres|weaviate|dual)", cfg.Type)
+		return nil, fmt.Errorf("unknown --menu-store %q (want postgres|weaviate|dual|local)", cfg.Type)
 	}
 }
@@ -30,7 +30,7 @@ func (s *menuStoreStub) BatchUpsertMenu(_ context.Context, items []search.MenuIt
 	return s.upsertErr
 }
 
-func (s *menuStoreStub) SearchMenu(_ context.Context, _ string, _ int) ([]search.MenuItem, error) {
+func (s *menuStoreStub) SearchMenu(_ context.Context, _ string, _ int, _ search.SearchFilter) ([]search.MenuItem, error) {
 	s.searchCalls++
 	if s.searchErr != nil {
 		return nil, s.searchErr
@@ -111,7 +111,7 @@ func TestDualMenuStore_SecondaryNil_Passthrough(t *testing.T) {
 	if err := d.BatchUpsertMenu(context.Background(), []search.MenuItem{{}}); err != nil {
 		t.Fatalf("upsert: %v", err)
 	}
-	res, err := d.SearchMenu(context.Background(), "q", 5)
+	res, err := d.SearchMenu(context.Background(), "q", 5, search.SearchFilter{})
 	if err != nil {
 		t.Fatalf("search: %v", err)
 	}
@@ -128,7 +128,7 @@ func TestDualMenuStore_SearchReadsPrimaryOnly(t *testing.T) {
 	secondary := &menuStoreStub{}
 	d := NewDualMenuStore(primary, secondary)
 
-	_, _ = d.SearchMenu(context.Background(), "q", 5)
+	_, _ = d.SearchMenu(context.Background(), "q", 5, search.SearchFilter{})
 	if secondary.searchCalls != 0 {
 		t.Errorf("secondary should never be read; got %d calls", secondary.searchCalls)
 	}
@@ -191,3 +191,35 @@ func TestNewMenuStore_Dual_MissingWeaviateHost(t *testing.T) {
 		t.Fatal("expected error for missing weaviate host in dual mode")
 	}
 }
+
+// spaceStoreStub is a menuStoreStub that embeds with its collections'
+// embedding spaces, as a Postgres store does.
+type spaceStoreStub struct {
+	menuStoreStub
+	embedder search.Embedder
+}
+
+func (s *spaceStoreStub) CollectionEmbedder(string) search.Embedder { return s.embedder }
+
+func TestMenuEmbedder(t *testing.T) {
+	process := search.NewHashEmbedder()
+	space := search.NewHashEmbedder()
+	pg := &spaceStoreStub{embedder: space}
+
+	cases := []struct {
+		name  string
+		store MenuStore
+		want  search.Embedder
+	}{
+		{"postgres", pg, space},
+		{"dual over postgres", NewDualMenuStore(pg, &menuStoreStub{}), space},
+		{"dual over another store", NewDualMenuStore(&menuStoreStub{}, pg), process},
+		{"no spaces", &menuStoreStub{}, process},
+		{"none", nil, process},
+	}
+	for _, tc := range cases {
+		if got := MenuEmbedder(tc.store, process); got != tc.want {
+			t.Errorf("%s: MenuEmbedder returned the wrong embedder", tc.name)
+		}
+	}
+}
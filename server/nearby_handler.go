@@ -0,0 +1,369 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fodmap/data"
+	"fodmap/fodmap/store"
+	"fodmap/geo"
+	"fodmap/queryparse"
+	"fodmap/search"
+
+	"github.com/google/uuid"
+)
+
+// defaultNearbyRadiusMeters bounds a geo query that gives an origin but no
+// radius or bbox — roughly a ten-minute walk.
+const defaultNearbyRadiusMeters = 800
+
+// maxGeoSearchLimit caps limit on the geo endpoints, and the over-fetch used
+// to fill a page after FODMAP filtering.
+const maxGeoSearchLimit = 200
+
+// parseGeoFilter reads lat, lon, radius_m, bbox (minLon,minLat,maxLon,maxLat)
+// and sort=distance from q. It returns nil when none of them are set.
+func parseGeoFilter(q url.Values) (*search.GeoFilter, error) {
+	latStr, lonStr := q.Get("lat"), q.Get("lon")
+	radiusStr, bboxStr := q.Get("radius_m"), q.Get("bbox")
+	if latStr == "" && lonStr == "" && radiusStr == "" && bboxStr == "" {
+		return nil, nil
+	}
+
+	f := &search.GeoFilter{}
+	if latStr != "" || lonStr != "" {
+		lat, err1 := strconv.ParseFloat(latStr, 64)
+		lon, err2 := strconv.ParseFloat(lonStr, 64)
+		if err1 != nil || err2 != nil {
+			return nil, errors.New("lat and lon must both be decimal degrees")
+		}
+		f.Origin = &geo.Point{Lat: lat, Lon: lon}
+	}
+	if radiusStr != "" {
+		r, err := strconv.ParseFloat(radiusStr, 64)
+		if err != nil || r <= 0 {
+			return nil, errors.New("radius_m must be a positive number of metres")
+		}
+		f.RadiusMeters = r
+	}
+	if bboxStr != "" {
+		parts := strings.Split(bboxStr, ",")
+		if len(parts) != 4 {
+			return nil, errors.New("bbox must be minLon,minLat,maxLon,maxLat")
+		}
+		var b geo.BBox
+		for i, p := range parts {
+			v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+			if err != nil {
+				return nil, errors.New("bbox must be minLon,minLat,maxLon,maxLat")
+			}
+			b[i] = v
+		}
+		f.BBox = &b
+	}
+	if f.Origin != nil && f.RadiusMeters == 0 && f.BBox == nil {
+		f.RadiusMeters = defaultNearbyRadiusMeters
+	}
+	switch q.Get("sort") {
+	case "", "relevance":
+	case "distance":
+		f.SortByDistance = true
+	default:
+		return nil, errors.New("sort must be relevance or distance")
+	}
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// parseGeoLimit reads limit, defaulting to def and capping at
+// maxGeoSearchLimit.
+func parseGeoLimit(q url.Values, def int) (int, error) {
+	l := q.Get("limit")
+	if l == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(l)
+	if err != nil || n <= 0 {
+		return 0, errors.New("limit must be a positive integer")
+	}
+	return min(n, maxGeoSearchLimit), nil
+}
+
+// dishClassifier builds a FODMAP classifier from the catalog, falling back to
+// the static table when no catalog is configured or it cannot be read.
+func (s *Server) dishClassifier(ctx context.Context) *data.DishClassifier {
+	if s.catalogStore != nil {
+		items, err := s.catalogStore.ListAll(ctx)
+		if err == nil && len(items) > 0 {
+			return data.NewDishClassifier(store.ToMap(items))
+		}
+		if err != nil {
+			slog.Warn("menu search: listing fodmap catalog failed; using static table", "error", err)
+		}
+	}
+	return data.NewDishClassifier(data.FodmapDB)
+}
+
+// classifyMenuItem classifies a dish on its original wording and, for a
+// translated menu, its English rendering as well, so an ingredient missing
+// from the alias table is still caught through the translation.
+func classifyMenuItem(c *data.DishClassifier, it search.MenuItem) (string, []string) {
+	ingredients := it.StatedIngredients
+	if len(ingredients) > 0 && len(it.StatedIngredientsEn) > 0 {
+		ingredients = append(slices.Clone(ingredients), it.StatedIngredientsEn...)
+	}
+	return c.Classify(joinNonEmpty(it.DishName, it.DishNameEn), joinNonEmpty(it.Description, it.DescriptionEn), ingredients)
+}
+
+// joinNonEmpty appends the translated text, when there is one, to the
+// original.
+func joinNonEmpty(orig, en string) string {
+	if en == "" {
+		return orig
+	}
+	return orig + "\n" + en
+}
+
+// menuDish gathers what queryparse.Allows checks of a menu item, given the
+// FODMAP table keys its classification matched.
+func menuDish(it search.MenuItem, matches []string) queryparse.Dish {
+	text := []string{it.DishName, it.DishNameEn, it.Description, it.DescriptionEn}
+	text = append(text, it.StatedIngredients...)
+	text = append(text, it.StatedIngredientsEn...)
+	return queryparse.Dish{Text: strings.Join(text, "\n"), Ingredients: matches, Price: it.Price}
+}
+
+// resolveMenuStore returns the dedicated MenuStore, else the searcher when it
+// doubles as one, else nil.
+func (s *Server) resolveMenuStore() MenuStore {
+	if s.menuStore != nil {
+		return s.menuStore
+	}
+	if ms, ok := UnwrapSearcher(s.searcher).(MenuStore); ok {
+		return ms
+	}
+	return nil
+}
+
+// searchMenuHandler serves GET /api/v1/search/menu/{query...}: semantic dish
+// search combinable with a geo filter and a FODMAP ceiling, e.g.
+// /api/v1/search/menu/lunch?lat=40.76&lon=-73.92&radius_m=800&fodmap_level=low.
+// The query may be empty when an origin is given, which lists the nearest
+// dishes. fodmap_level keeps dishes at or below that level; dishes whose
+// ingredients are not recognised are excluded from a filtered search. With
+// query parsing on, "gluten-free ramen in Astoria under $20" also limits
+// dishes by diet, avoided ingredients and price.
+func (s *Server) searchMenuHandler(w http.ResponseWriter, r *http.Request) {
+	ms := s.resolveMenuStore()
+	if ms == nil {
+		http.Error(w, `{"error":"menu search not configured"}`, http.StatusServiceUnavailable)
+		return
+	}
+
+	q := strings.TrimSpace(r.PathValue("query"))
+	params := r.URL.Query()
+	geoFilter, err := parseGeoFilter(params)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	var businessID uuid.UUID
+	if bid := params.Get("business_id"); bid != "" {
+		businessID, err = uuid.Parse(bid)
+		if err != nil {
+			http.Error(w, `{"error":"business_id must be a valid UUID"}`, http.StatusBadRequest)
+			return
+		}
+	}
+	// An empty query with a business_id lists that restaurant's menu.
+	hasOrigin := geoFilter != nil && geoFilter.Origin != nil
+	if q == "" && !hasOrigin && businessID == uuid.Nil {
+		http.Error(w, `{"error":"search query, lat/lon or business_id is required"}`, http.StatusBadRequest)
+		return
+	}
+	if q == "" && hasOrigin {
+		geoFilter.SortByDistance = true
+	}
+	limit, err := parseGeoLimit(params, 10)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	maxLevel := strings.ToLower(strings.TrimSpace(params.Get("fodmap_level")))
+	if maxLevel != "" && data.FodmapLevelRank(maxLevel) == 0 {
+		http.Error(w, `{"error":"fodmap_level must be low, moderate or high"}`, http.StatusBadRequest)
+		return
+	}
+
+	filter := search.SearchFilter{
+		City:           strings.TrimSpace(params.Get("city")),
+		State:          strings.TrimSpace(params.Get("state")),
+		Geo:            geoFilter,
+		MaxFodmapLevel: maxLevel,
+		BusinessID:     businessID,
+	}
+	q, parsed := s.interpretQuery(r.Context(), q, true, &filter)
+	maxLevel = filter.MaxFodmapLevel
+
+	// Over-fetch when filtering dishes so a page still fills after unsafe,
+	// unknown and unsuitable dishes are dropped.
+	fetch := limit
+	if maxLevel != "" || filter.MaxPrice != nil || len(filter.Avoid) > 0 || len(filter.Dietary) > 0 {
+		fetch = min(limit*4, maxGeoSearchLimit)
+	}
+	items, err := s.retrieval.SearchMenu(r.Context(), ms, q, fetch, filter)
+	if errors.Is(err, search.ErrGeoFilterUnsupported) && dropParsedGeo(parsed, &filter) {
+		items, err = s.retrieval.SearchMenu(r.Context(), ms, q, fetch, filter)
+	}
+	if errors.Is(err, search.ErrGeoFilterUnsupported) {
+		http.Error(w, `{"error":"menu store does not support geo filters"}`, http.StatusNotImplemented)
+		return
+	}
+	if err != nil {
+		slog.Error("menu search error", "error", err, "query", q)
+		http.Error(w, `{"error":"search failed"}`, http.StatusInternalServerError)
+		return
+	}
+
+	type dish struct {
+		ID             string            `json:"id"`
+		BusinessID     uuid.UUID         `json:"business_id"`
+		RestaurantName string            `json:"restaurant_name"`
+		DishName       string            `json:"dish_name"`
+		Description    string            `json:"description,omitempty"`
+		MenuSection    string            `json:"menu_section,omitempty"`
+		Ingredients    []string          `json:"stated_ingredients,omitempty"`
+		Price          *float64          `json:"price,omitempty"`
+		Modifiers      []search.Modifier `json:"modifiers,omitempty"`
+		Address        string            `json:"address,omitempty"`
+		City           string            `json:"city,omitempty"`
+		State          string            `json:"state,omitempty"`
+		Latitude       *float64          `json:"latitude,omitempty"`
+		Longitude      *float64          `json:"longitude,omitempty"`
+		DistanceMeters *float64          `json:"distance_m,omitempty"`
+		Language       string            `json:"language,omitempty"`
+		DishNameEn     string            `json:"dish_name_en,omitempty"`
+		DescriptionEn  string            `json:"description_en,omitempty"`
+		FodmapLevel    string            `json:"fodmap_level"`
+		FodmapMatches  []string          `json:"fodmap_matches,omitempty"`
+		SourceURL      string            `json:"source_url,omitempty"`
+	}
+	classifier := s.dishClassifier(r.Context())
+	out := make([]dish, 0, min(len(items), limit))
+	for _, it := range items {
+		level, matches := classifyMenuItem(classifier, it)
+		if maxLevel != "" && (level == "" || data.FodmapLevelRank(level) > data.FodmapLevelRank(maxLevel)) {
+			continue
+		}
+		if !queryparse.Allows(filter, menuDish(it, matches)) {
+			continue
+		}
+		if level == "" {
+			level = "unknown"
+		}
+		out = append(out, dish{
+			ID:             it.MenuItemID,
+			BusinessID:     it.BusinessID,
+			RestaurantName: it.RestaurantName,
+			DishName:       it.DishName,
+			Description:    it.Description,
+			MenuSection:    it.MenuSection,
+			Ingredients:    it.StatedIngredients,
+			Price:          it.Price,
+			Modifiers:      it.Modifiers,
+			Address:        it.Address,
+			City:           it.City,
+			State:          it.State,
+			Latitude:       it.Latitude,
+			Longitude:      it.Longitude,
+			DistanceMeters: it.DistanceMeters,
+			Language:       it.Language,
+			DishNameEn:     it.DishNameEn,
+			DescriptionEn:  it.DescriptionEn,
+			FodmapLevel:    level,
+			FodmapMatches:  matches,
+			SourceURL:      it.SourceURL,
+		})
+		if len(out) == limit {
+			break
+		}
+	}
+	resp := struct {
+		Items          []dish             `json:"items"`
+		Interpretation *queryparse.Result `json:"interpretation,omitempty"`
+	}{out, parsed}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error("encode error", "error", err)
+	}
+}
+
+// nearbyRestaurantsHandler serves GET /api/v1/search/nearby: restaurants
+// within radius_m of lat/lon and/or inside bbox, nearest first.
+func (s *Server) nearbyRestaurantsHandler(w http.ResponseWriter, r *http.Request) {
+	lister, ok := s.restaurantStore.(NearbyRestaurantLister)
+	if !ok {
+		http.Error(w, `{"error":"restaurant store does not support geo queries"}`, http.StatusNotImplemented)
+		return
+	}
+	params := r.URL.Query()
+	geoFilter, err := parseGeoFilter(params)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	if geoFilter == nil {
+		http.Error(w, `{"error":"lat/lon or bbox is required"}`, http.StatusBadRequest)
+		return
+	}
+	limit, err := parseGeoLimit(params, 20)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	results, err := lister.ListNearby(r.Context(), *geoFilter, params.Get("status"), limit)
+	if err != nil {
+		slog.Error("nearby restaurants error", "error", err)
+		http.Error(w, `{"error":"search failed"}`, http.StatusInternalServerError)
+		return
+	}
+
+	type restaurant struct {
+		ID             uuid.UUID `json:"id"`
+		Name           string    `json:"name"`
+		Address        *string   `json:"address,omitempty"`
+		Cuisine        *string   `json:"cuisine,omitempty"`
+		Latitude       *float64  `json:"latitude"`
+		Longitude      *float64  `json:"longitude"`
+		DistanceMeters *float64  `json:"distance_m,omitempty"`
+		ItemCount      int       `json:"item_count"`
+	}
+	out := make([]restaurant, len(results))
+	for i, n := range results {
+		out[i] = restaurant{
+			ID:             n.ID,
+			Name:           n.DBA,
+			Address:        n.Address,
+			Cuisine:        n.Cuisine,
+			Latitude:       n.Latitude,
+			Longitude:      n.Longitude,
+			DistanceMeters: n.DistanceMeters,
+			ItemCount:      n.ItemCount,
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string][]restaurant{"restaurants": out}); err != nil {
+		slog.Error("encode error", "error", err)
+	}
+}
@@ -0,0 +1,279 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"fodmap/chat"
+	"fodmap/geo"
+	"fodmap/search"
+
+	"github.com/google/uuid"
+)
+
+// geoMenuStore is a MenuStore stub that applies the geo filter like the
+// real backends and records the filter it was given.
+type geoMenuStore struct {
+	menuStoreStub
+	items     []search.MenuItem
+	err       error
+	gotQuery  string
+	gotLimit  int
+	gotFilter search.SearchFilter
+}
+
+func (s *geoMenuStore) SearchMenu(_ context.Context, query string, limit int, filter search.SearchFilter) ([]search.MenuItem, error) {
+	s.gotQuery, s.gotLimit, s.gotFilter = query, limit, filter
+	if s.err != nil {
+		return nil, s.err
+	}
+	var out []search.MenuItem
+	for _, it := range s.items {
+		if filter.Geo != nil {
+			d, ok := filter.Geo.Match(it.Latitude, it.Longitude)
+			if !ok {
+				continue
+			}
+			it.DistanceMeters = d
+		}
+		out = append(out, it)
+	}
+	if filter.Geo != nil && filter.Geo.SortByDistance {
+		search.SortMenuByDistance(out)
+	}
+	return out, nil
+}
+
+func ptrF(f float64) *float64 { return &f }
+
+func menuSearch(t *testing.T, s *Server, path string) (*httptest.ResponseRecorder, []map[string]any) {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/search/menu/{query...}", s.searchMenuHandler)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if w.Code != http.StatusOK {
+		return w, nil
+	}
+	var body struct {
+		Items []map[string]any `json:"items"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return w, body.Items
+}
+
+func TestSearchMenuHandler_RadiusAndFodmapLevel(t *testing.T) {
+	ms := &geoMenuStore{items: []search.MenuItem{
+		{DishName: "Garlic Noodles", StatedIngredients: []string{"noodles", "garlic"}, Latitude: ptrF(40.761), Longitude: ptrF(-73.92)},
+		{DishName: "Grilled Chicken Rice Bowl", StatedIngredients: []string{"chicken", "rice"}, Latitude: ptrF(40.765), Longitude: ptrF(-73.92)},
+		{DishName: "Chef's Special", Latitude: ptrF(40.762), Longitude: ptrF(-73.92)},
+		{DishName: "Faraway Rice", StatedIngredients: []string{"rice"}, Latitude: ptrF(40.80), Longitude: ptrF(-73.92)},
+	}}
+	s := &Server{menuStore: ms}
+
+	w, items := menuSearch(t, s, "/api/v1/search/menu/lunch?lat=40.76&lon=-73.92&radius_m=800&fodmap_level=low&limit=5")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	if len(items) != 1 || items[0]["dish_name"] != "Grilled Chicken Rice Bowl" || items[0]["fodmap_level"] != "low" {
+		t.Fatalf("items = %v, want only the low-FODMAP bowl within 800m", items)
+	}
+	if _, ok := items[0]["distance_m"]; !ok {
+		t.Error("distance_m missing from result")
+	}
+	g := ms.gotFilter.Geo
+	if g == nil || g.RadiusMeters != 800 || g.Origin == nil || g.Origin.Lat != 40.76 {
+		t.Errorf("geo filter = %+v", g)
+	}
+	if ms.gotLimit != 20 {
+		t.Errorf("fetch limit = %d, want 4x over-fetch for the FODMAP filter", ms.gotLimit)
+	}
+}
+
+func TestSearchMenuHandler_NearestWithoutQuery(t *testing.T) {
+	ms := &geoMenuStore{items: []search.MenuItem{
+		{DishName: "far", Latitude: ptrF(40.765), Longitude: ptrF(-73.92)},
+		{DishName: "near", Latitude: ptrF(40.761), Longitude: ptrF(-73.92)},
+	}}
+	s := &Server{menuStore: ms}
+
+	w, items := menuSearch(t, s, "/api/v1/search/menu/?lat=40.76&lon=-73.92")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	if len(items) != 2 || items[0]["dish_name"] != "near" || items[1]["fodmap_level"] != "unknown" {
+		t.Errorf("items = %v, want nearest first with unknown levels", items)
+	}
+	if g := ms.gotFilter.Geo; g == nil || !g.SortByDistance || g.RadiusMeters != defaultNearbyRadiusMeters {
+		t.Errorf("geo filter = %+v, want distance sort and the default radius", g)
+	}
+}
+
+func TestSearchMenuHandler_BusinessMenuWithoutQuery(t *testing.T) {
+	bid := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	ms := &geoMenuStore{items: []search.MenuItem{
+		{DishName: "Risotto", MenuSection: "Mains", StatedIngredients: []string{"rice", "parmesan"}},
+	}}
+	s := &Server{menuStore: ms}
+
+	w, items := menuSearch(t, s, "/api/v1/search/menu/?business_id="+bid.String())
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	if ms.gotQuery != "" || ms.gotFilter.BusinessID != bid || ms.gotFilter.Geo != nil {
+		t.Errorf("query = %q, filter = %+v, want the business's menu", ms.gotQuery, ms.gotFilter)
+	}
+	if len(items) != 1 || items[0]["stated_ingredients"] == nil {
+		t.Errorf("items = %v, want the dish with its stated ingredients", items)
+	}
+}
+
+func TestChatMenuContext(t *testing.T) {
+	bid := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	ms := &geoMenuStore{items: []search.MenuItem{
+		{DishName: "Garlic Noodles", MenuSection: "Noodles", StatedIngredients: []string{"noodles", "garlic"}},
+	}}
+	s := &Server{menuStore: ms}
+
+	got := s.chatMenuContext(t.Context(), bid, "Noodle Bar")
+	if !strings.Contains(got, "MENU OF NOODLE BAR") || !strings.Contains(got, "- Garlic Noodles [FODMAP: high]") {
+		t.Errorf("menu context = %q", got)
+	}
+	if ms.gotFilter.BusinessID != bid || ms.gotLimit != chat.MenuContextLimit {
+		t.Errorf("filter = %+v, limit = %d", ms.gotFilter, ms.gotLimit)
+	}
+	if got := s.chatMenuContext(t.Context(), uuid.Nil, "Noodle Bar"); got != "" {
+		t.Errorf("no business: menu context = %q, want empty", got)
+	}
+	if got := (&Server{}).chatMenuContext(t.Context(), bid, "Noodle Bar"); got != "" {
+		t.Errorf("no menu store: menu context = %q, want empty", got)
+	}
+}
+
+func TestSearchMenuHandler_BadRequests(t *testing.T) {
+	s := &Server{menuStore: &geoMenuStore{}}
+	for _, path := range []string{
+		"/api/v1/search/menu/",
+		"/api/v1/search/menu/x?lat=40.7",
+		"/api/v1/search/menu/x?lat=40.7&lon=-73.9&radius_m=-1",
+		"/api/v1/search/menu/x?lat=40.7&lon=-73.9&radius_m=60000",
+		"/api/v1/search/menu/x?bbox=1,2,3",
+		"/api/v1/search/menu/x?bbox=-73.9,40.7,-74,40.8",
+		"/api/v1/search/menu/x?bbox=-74,40.7,-73.9,40.8&sort=distance",
+		"/api/v1/search/menu/x?fodmap_level=safe",
+		"/api/v1/search/menu/?business_id=not-a-uuid",
+	} {
+		if w, _ := menuSearch(t, s, path); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", path, w.Code)
+		}
+	}
+}
+
+func TestSearchMenuHandler_UnsupportedBackend(t *testing.T) {
+	s := &Server{menuStore: &geoMenuStore{err: search.ErrGeoFilterUnsupported}}
+	if w, _ := menuSearch(t, s, "/api/v1/search/menu/x?lat=40.7&lon=-73.9"); w.Code != http.StatusNotImplemented {
+		t.Errorf("status = %d, want 501", w.Code)
+	}
+	s = &Server{}
+	if w, _ := menuSearch(t, s, "/api/v1/search/menu/x"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("no menu store: status = %d, want 503", w.Code)
+	}
+}
+
+// nearbyRestaurantStore adds ListNearby to the restaurant stub.
+type nearbyRestaurantStore struct {
+	*stubRestaurantStore
+}
+
+func (s nearbyRestaurantStore) ListNearby(_ context.Context, f search.GeoFilter, _ string, limit int) ([]NearbyRestaurant, error) {
+	var out []NearbyRestaurant
+	for _, r := range s.rows {
+		if d, ok := f.Match(r.Latitude, r.Longitude); ok && len(out) < limit {
+			out = append(out, NearbyRestaurant{Restaurant: *r, DistanceMeters: d})
+		}
+	}
+	return out, nil
+}
+
+func TestNearbyRestaurantsHandler(t *testing.T) {
+	rs := newStubRestaurantStore()
+	in, out := "in", "out"
+	inLat, inLon, outLat, outLon := 40.761, -73.92, 40.9, -73.92
+	rs.rows[in] = &Restaurant{CAMIS: &in, DBA: "Inside", Latitude: &inLat, Longitude: &inLon}
+	rs.rows[out] = &Restaurant{CAMIS: &out, DBA: "Outside", Latitude: &outLat, Longitude: &outLon}
+
+	get := func(s *Server, q url.Values) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.nearbyRestaurantsHandler(w, httptest.NewRequest(http.MethodGet, "/api/v1/search/nearby?"+q.Encode(), nil))
+		return w
+	}
+
+	s := &Server{restaurantStore: nearbyRestaurantStore{rs}}
+	w := get(s, url.Values{"lat": {"40.76"}, "lon": {"-73.92"}, "radius_m": {"500"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var got struct {
+		Restaurants []struct {
+			Name           string   `json:"name"`
+			DistanceMeters *float64 `json:"distance_m"`
+		} `json:"restaurants"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Restaurants) != 1 || got.Restaurants[0].Name != "Inside" || got.Restaurants[0].DistanceMeters == nil {
+		t.Errorf("restaurants = %+v, want only Inside with a distance", got.Restaurants)
+	}
+
+	if w := get(s, url.Values{}); w.Code != http.StatusBadRequest {
+		t.Errorf("no location: status = %d, want 400", w.Code)
+	}
+	if w := get(&Server{restaurantStore: rs}, url.Values{"lat": {"40.76"}, "lon": {"-73.92"}}); w.Code != http.StatusNotImplemented {
+		t.Errorf("store without ListNearby: status = %d, want 501", w.Code)
+	}
+}
+
+func TestParseGeoFilter_BBoxOnly(t *testing.T) {
+	f, err := parseGeoFilter(url.Values{"bbox": {"-74, 40.7, -73.9, 40.8"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := geo.BBox{-74, 40.7, -73.9, 40.8}
+	if f.BBox == nil || *f.BBox != want || f.Origin != nil || f.RadiusMeters != 0 {
+		t.Errorf("filter = %+v", f)
+	}
+	if f, err := parseGeoFilter(url.Values{"city": {"x"}}); f != nil || err != nil {
+		t.Errorf("no geo params: got %+v, %v", f, err)
+	}
+}
+
+func TestBusinessesHandler_GeoFilter(t *testing.T) {
+	mock := &handlersTestSearcher{}
+	s := &Server{searcher: mock}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/search/businesses/{query...}", s.getBusinessesHandler)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/search/businesses/pizza?lat=40.76&lon=-73.92&radius_m=800", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	if g := mock.lastBusinessFilter.Geo; g == nil || g.RadiusMeters != 800 {
+		t.Errorf("geo filter = %+v, want an 800m radius", g)
+	}
+
+	mock.businessErr = search.ErrGeoFilterUnsupported
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/search/businesses/pizza?lat=40.76&lon=-73.92", nil))
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("unsupported backend: status = %d, want 501", w.Code)
+	}
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"fodmap/queryparse"
+	"fodmap/search"
+)
+
+// interpretQuery parses q, when query parsing is on, and fills the fields
+// of filter the request left unset from it. The dish constraints (price,
+// FODMAP level, diets and avoided ingredients) are only taken out of the
+// query when dishes is set, for menu search, which enforces them; other
+// searches keep their phrases in the text. It returns the query to search
+// with and the parse, or nil when parsing is off or found no constraint.
+func (s *Server) interpretQuery(ctx context.Context, q string, dishes bool, filter *search.SearchFilter) (string, *queryparse.Result) {
+	if s.queryParser == nil || q == "" {
+		return q, nil
+	}
+	res := s.queryParser.Parse(ctx, q)
+	if !dishes {
+		res = res.PlaceConstraints()
+	}
+	if res.Empty() {
+		return q, nil
+	}
+	res.Fill(filter)
+	return res.Query, &res
+}
+
+// dropParsedGeo clears filter's geo filter when it came from the parse
+// rather than the request, so a search the backend turned down for it can
+// be retried on City and State alone. It reports whether it did.
+func dropParsedGeo(parsed *queryparse.Result, filter *search.SearchFilter) bool {
+	if parsed == nil || parsed.Geo == nil || filter.Geo != parsed.Geo {
+		return false
+	}
+	filter.Geo = nil
+	return true
+}
+
+// searchBusinesses runs a business search for a free-text query, parsed
+// first as interpretQuery does.
+func (s *Server) searchBusinesses(ctx context.Context, q string, limit int, filter search.SearchFilter) (search.SearchResult, *queryparse.Result, error) {
+	q, parsed := s.interpretQuery(ctx, q, false, &filter)
+	result, err := s.retrieval.Businesses(ctx, s.searcher, q, limit, filter)
+	if errors.Is(err, search.ErrGeoFilterUnsupported) && dropParsedGeo(parsed, &filter) {
+		result, err = s.retrieval.Businesses(ctx, s.searcher, q, limit, filter)
+	}
+	return result, parsed, err
+}
+
+// lookupBusiness searches for the business q names, as a conversation is
+// started for, and returns the best match. q is searched as it is, not
+// parsed: a business name often reads as a place or a cuisine ("Brooklyn
+// Diner", "Chinatown Express"), which as filters would rule the business
+// out.
+func (s *Server) lookupBusiness(ctx context.Context, q string) (search.SearchResult, error) {
+	return s.retrieval.Businesses(ctx, s.searcher, q, 1, search.SearchFilter{})
+}
+
+// searchReviews runs a review search for a free-text query, parsed first
+// as interpretQuery does.
+func (s *Server) searchReviews(ctx context.Context, q string, limit int, filter search.SearchFilter) (search.SearchReviews, *queryparse.Result, error) {
+	q, parsed := s.interpretQuery(ctx, q, false, &filter)
+	result, err := s.retrieval.Reviews(ctx, s.searcher, q, limit, filter)
+	if errors.Is(err, search.ErrGeoFilterUnsupported) && dropParsedGeo(parsed, &filter) {
+		result, err = s.retrieval.Reviews(ctx, s.searcher, q, limit, filter)
+	}
+	return result, parsed, err
+}
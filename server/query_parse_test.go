@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fodmap/queryparse"
+	"fodmap/search"
+)
+
+func TestSearchMenuHandler_ParsedQuery(t *testing.T) {
+	astoria := func(name string, price float64, ingredients ...string) search.MenuItem {
+		return search.MenuItem{DishName: name, Price: ptrF(price), StatedIngredients: ingredients, Latitude: ptrF(40.765), Longitude: ptrF(-73.92)}
+	}
+	ms := &geoMenuStore{items: []search.MenuItem{
+		astoria("Tonkotsu Ramen", 18, "wheat noodles", "pork broth"),
+		astoria("Gluten-Free Shio Ramen", 19, "rice noodles", "chicken broth"),
+		astoria("Gluten-Free Deluxe Ramen", 26, "rice noodles", "chicken broth"),
+		{DishName: "Gluten-Free Ramen", Price: ptrF(15), Latitude: ptrF(40.71), Longitude: ptrF(-73.96)}, // Williamsburg
+	}}
+	s := &Server{menuStore: ms, queryParser: queryparse.New(nil)}
+
+	w, items := menuSearch(t, s, "/api/v1/search/menu/gluten-free%20ramen%20in%20Astoria%20under%20$20")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	if len(items) != 1 || items[0]["dish_name"] != "Gluten-Free Shio Ramen" {
+		t.Errorf("items = %v, want only the gluten-free ramen in Astoria under $20", items)
+	}
+	if ms.gotQuery != "gluten-free ramen" || ms.gotFilter.City != "New York" || ms.gotFilter.Geo == nil {
+		t.Errorf("searched %q with %+v", ms.gotQuery, ms.gotFilter)
+	}
+
+	// An explicit location wins over the parsed one.
+	menuSearch(t, s, "/api/v1/search/menu/ramen%20in%20Astoria?lat=40.71&lon=-73.96")
+	if g := ms.gotFilter.Geo; g == nil || g.Origin == nil || g.BBox != nil || ms.gotFilter.City != "" {
+		t.Errorf("filter = %+v, want the request's origin only", ms.gotFilter)
+	}
+}
+
+// geoRejectingSearcher refuses geo-filtered business searches, as the
+// Weaviate and Pinecone backends do.
+type geoRejectingSearcher struct {
+	handlersTestSearcher
+}
+
+func (s *geoRejectingSearcher) Businesses(ctx context.Context, q string, limit int, filter search.SearchFilter) (search.SearchResult, error) {
+	if filter.Geo != nil {
+		return search.SearchResult{}, search.ErrGeoFilterUnsupported
+	}
+	return s.handlersTestSearcher.Businesses(ctx, q, limit, filter)
+}
+
+func TestBusinessesHandler_ParsedLocationWithoutGeoSupport(t *testing.T) {
+	mock := &geoRejectingSearcher{}
+	s := &Server{searcher: mock, queryParser: queryparse.New(nil)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/search/businesses/{query...}", s.getBusinessesHandler)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/search/businesses/thai%20food%20in%20Astoria", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if f := mock.lastBusinessFilter; f.City != "New York" || f.State != "NY" || f.Category != "Thai" || f.Geo != nil {
+		t.Errorf("filter = %+v, want the city and category without the bbox", f)
+	}
+	var body struct {
+		Interpretation *queryparse.Result `json:"interpretation"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Interpretation == nil || body.Interpretation.Query != "thai food" || body.Interpretation.Place != "Astoria" {
+		t.Errorf("interpretation = %+v", body.Interpretation)
+	}
+
+	// An explicit geo filter the backend cannot apply is still an error.
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/search/businesses/thai?lat=40.76&lon=-73.92", nil))
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("explicit geo: status = %d, want 501", rec.Code)
+	}
+}
+
+func TestReviewsHandler_ParsedQueryKeepsDishConstraints(t *testing.T) {
+	mock := &handlersTestSearcher{}
+	s := &Server{searcher: mock, queryParser: queryparse.New(nil)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/search/reviews/{query...}", s.getReviewsHandler)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/search/reviews/low%20fodmap%20ramen%20in%20Astoria%20under%20$20%20with%20no%20garlic", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	// Review search cannot apply the price, FODMAP and ingredient
+	// constraints, so their words stay in the query; the location it can
+	// apply is still taken out.
+	if mock.lastReviewQuery != "low fodmap ramen under $20 with no garlic" {
+		t.Errorf("searched %q", mock.lastReviewQuery)
+	}
+	// The dish constraints make "ramen" a dish rather than a category.
+	if f := mock.lastReviewFilter; f.City != "New York" || f.Category != "" || f.MaxPrice != nil || f.MaxFodmapLevel != "" || len(f.Avoid) != 0 {
+		t.Errorf("filter = %+v, want the location only", f)
+	}
+}
+
+func TestLookupBusiness_NotParsed(t *testing.T) {
+	mock := &handlersTestSearcher{}
+	s := &Server{searcher: mock, queryParser: queryparse.New(nil)}
+
+	// Parsed, the name would become a Thai search in Astoria.
+	if _, err := s.lookupBusiness(context.Background(), "Thai Food in Astoria"); err != nil {
+		t.Fatal(err)
+	}
+	if mock.lastBusinessQuery != "Thai Food in Astoria" {
+		t.Errorf("searched %q, want the name as given", mock.lastBusinessQuery)
+	}
+	if f := mock.lastBusinessFilter; f.Category != "" || f.City != "" || f.Geo != nil {
+		t.Errorf("filter = %+v, want none", f)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"time"
 
+	"fodmap/search"
+
 	"github.com/google/uuid"
 	"github.com/riverqueue/river"
 	"github.com/riverqueue/river/rivertype"
@@ -65,10 +67,47 @@ type RestaurantStore interface {
 	List(ctx context.Context, status string, search string, limit, offset int) ([]Restaurant, error)
 	Count(ctx context.Context, status string, search string) (int, error)
 	PipelineStats(ctx context.Context) (*PipelineStats, error)
-	UpdateDiscoveryURLs(ctx context.Context, camis, websiteURL string, menuURLs []string, source, address, phone string) error
-	UpdateScrapeResult(ctx context.Context, camis, status string, itemCount int, lastError string) error
+	UpdateDiscoveryURLs(ctx context.Context, id uuid.UUID, websiteURL string, menuURLs []string, source, address, phone string) error
+	UpdateScrapeResult(ctx context.Context, id uuid.UUID, status string, itemCount int, lastError string) error
+}
+
+// NearbyRestaurant is a restaurant returned by a geo query, with its
+// distance from the query origin (nil when the query had none).
+type NearbyRestaurant struct {
+	Restaurant
+	DistanceMeters *float64 `json:"distance_m,omitempty"`
+}
+
+// NearbyRestaurantLister is implemented by restaurant stores that can answer
+// radius and bounding-box queries over restaurant coordinates. status
+// filters by pipeline status when non-empty.
+type NearbyRestaurantLister interface {
+	ListNearby(ctx context.Context, f search.GeoFilter, status string, limit int) ([]NearbyRestaurant, error)
 }
 
+// FavoriteRestaurant is a restaurant a user has saved.
+type FavoriteRestaurant struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Address     *string    `json:"address,omitempty"`
+	Cuisine     *string    `json:"cuisine,omitempty"`
+	Status      string     `json:"status"`
+	ScrapedAt   *time.Time `json:"scraped_at"`
+	FavoritedAt time.Time  `json:"favorited_at"`
+}
+
+// FavoriteStore is implemented by restaurant stores that keep per-user
+// favorites. Favorites also raise a restaurant's menu refresh priority.
+type FavoriteStore interface {
+	AddFavorite(ctx context.Context, userID string, businessID uuid.UUID) error
+	RemoveFavorite(ctx context.Context, userID string, businessID uuid.UUID) error
+	ListFavorites(ctx context.Context, userID string) ([]FavoriteRestaurant, error)
+}
+
+// ErrRestaurantNotFound is returned when an operation names a restaurant
+// that does not exist.
+var ErrRestaurantNotFound = errors.New("restaurant not found")
+
 // RiverInserter inserts River jobs. Same interface as menutracking's.
 type RiverInserter interface {
 	Insert(ctx context.Context, args river.JobArgs, opts *river.InsertOpts) (*rivertype.JobInsertResult, error)
@@ -11,7 +11,7 @@ import (
 
 // restaurantStatusNeedsRescrape returns true if a retry should re-scrape.
 func restaurantStatusNeedsRescrape(status string) bool {
-	return status == "failed_scrape" || status == "scraped" || status == "url_found" || status == "scraping" || status == "failed_permanently"
+	return status == "failed_scrape" || status == "scraped" || status == "url_found" || status == "scraping" || status == "failed_permanently" || status == "quarantined"
 }
 
 // restaurantsCreateRequest is the body for POST /api/v1/restaurants.
@@ -193,7 +193,7 @@ func (s *Server) restaurantTriggerDiscoverHandler(w http.ResponseWriter, r *http
 		respondError(w, "failed to enqueue discovery", http.StatusInternalServerError)
 		return
 	}
-	if err := s.restaurantStore.UpdateScrapeResult(ctx, camis, "pending_discovery", 0, ""); err != nil {
+	if err := s.restaurantStore.UpdateScrapeResult(ctx, row.ID, "pending_discovery", 0, ""); err != nil {
 		slog.Warn("restaurants: update status after enqueue discover", "camis", camis, "err", err)
 	}
 
@@ -273,7 +273,7 @@ func (s *Server) restaurantRetryHandler(w http.ResponseWriter, r *http.Request)
 			respondError(w, "failed to enqueue scrape", http.StatusInternalServerError)
 			return
 		}
-		if err := s.restaurantStore.UpdateScrapeResult(ctx, camis, "url_found", 0, ""); err != nil {
+		if err := s.restaurantStore.UpdateScrapeResult(ctx, row.ID, "url_found", 0, ""); err != nil {
 			slog.Warn("restaurants: update status after retry enqueue scrape", "camis", camis, "err", err)
 		}
 		action = "scrape"
@@ -287,7 +287,7 @@ func (s *Server) restaurantRetryHandler(w http.ResponseWriter, r *http.Request)
 			respondError(w, "failed to enqueue discovery", http.StatusInternalServerError)
 			return
 		}
-		if err := s.restaurantStore.UpdateScrapeResult(ctx, camis, "pending_discovery", 0, ""); err != nil {
+		if err := s.restaurantStore.UpdateScrapeResult(ctx, row.ID, "pending_discovery", 0, ""); err != nil {
 			slog.Warn("restaurants: update status after retry enqueue discover", "camis", camis, "err", err)
 		}
 		action = "discover"
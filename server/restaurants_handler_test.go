@@ -113,11 +113,11 @@ func (s *stubRestaurantStore) PipelineStats(_ context.Context) (*PipelineStats,
 	return stats, nil
 }
 
-func (s *stubRestaurantStore) UpdateDiscoveryURLs(_ context.Context, camis, websiteURL string, menuURLs []string, source, address, phone string) error {
+func (s *stubRestaurantStore) UpdateDiscoveryURLs(ctx context.Context, id uuid.UUID, websiteURL string, menuURLs []string, source, address, phone string) error {
 	if s.updateErr != nil {
 		return s.updateErr
 	}
-	if r, ok := s.rows[camis]; ok {
+	if r, _ := s.GetByID(ctx, id); r != nil {
 		r.WebsiteURL = &websiteURL
 		r.MenuURLs = menuURLs
 		r.URLSource = &source
@@ -127,11 +127,11 @@ func (s *stubRestaurantStore) UpdateDiscoveryURLs(_ context.Context, camis, webs
 	return nil
 }
 
-func (s *stubRestaurantStore) UpdateScrapeResult(_ context.Context, camis, status string, itemCount int, _ string) error {
+func (s *stubRestaurantStore) UpdateScrapeResult(ctx context.Context, id uuid.UUID, status string, itemCount int, _ string) error {
 	if s.updateErr != nil {
 		return s.updateErr
 	}
-	if r, ok := s.rows[camis]; ok {
+	if r, _ := s.GetByID(ctx, id); r != nil {
 		r.Status = status
 		r.ItemCount = itemCount
 	}
@@ -184,6 +184,7 @@ func TestRestaurantStatusNeedsRescrape(t *testing.T) {
 		{"scraped", true},
 		{"url_found", true},
 		{"scraping", true},
+		{"quarantined", true},
 		{"pending_discovery", false},
 		{"discovered", false},
 		{"", false},
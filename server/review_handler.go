@@ -0,0 +1,256 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"fodmap/scraper"
+)
+
+// maxReviewBodySize bounds an approval body; corrected menus run to a few
+// hundred items.
+const maxReviewBodySize = 1 << 20
+
+// menuReviewStore returns the restaurant store's review capability, or nil.
+func (s *Server) menuReviewStore() MenuReviewStore {
+	rs, _ := s.restaurantStore.(MenuReviewStore)
+	return rs
+}
+
+// reviewFromPath resolves the review store and the {id} path value, writing
+// the error response and returning ok false when either is missing.
+func (s *Server) reviewFromPath(w http.ResponseWriter, r *http.Request) (MenuReviewStore, uuid.UUID, bool) {
+	rs := s.menuReviewStore()
+	if rs == nil {
+		respondError(w, "menu review not supported", http.StatusNotImplemented)
+		return nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, "id must be a valid UUID", http.StatusBadRequest)
+		return nil, uuid.Nil, false
+	}
+	return rs, id, true
+}
+
+func (s *Server) reviewListHandler(w http.ResponseWriter, r *http.Request) {
+	rs := s.menuReviewStore()
+	if rs == nil {
+		respondError(w, "menu review not supported", http.StatusNotImplemented)
+		return
+	}
+
+	// The queue shows pending reviews unless asked otherwise.
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = ReviewPending
+	case "all":
+		status = ""
+	case ReviewPending, ReviewApproved, ReviewRejected, ReviewSuperseded:
+	default:
+		respondError(w, "status must be pending, approved, rejected, superseded or all", http.StatusBadRequest)
+		return
+	}
+
+	limit := 50
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if v, err := strconv.Atoi(l); err == nil && v > 0 {
+			limit = v
+		}
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	offset := 0
+	if o := r.URL.Query().Get("offset"); o != "" {
+		if v, err := strconv.Atoi(o); err == nil && v >= 0 {
+			offset = v
+		}
+	}
+
+	reviews, total, err := rs.ListMenuReviews(r.Context(), status, limit, offset)
+	if err != nil {
+		slog.Error("reviews: list", "err", err)
+		respondError(w, "failed to list reviews", http.StatusInternalServerError)
+		return
+	}
+	if reviews == nil {
+		reviews = []MenuReview{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"reviews": reviews,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
+func (s *Server) reviewGetHandler(w http.ResponseWriter, r *http.Request) {
+	rs, id, ok := s.reviewFromPath(w, r)
+	if !ok {
+		return
+	}
+	review, err := rs.GetMenuReview(r.Context(), id)
+	if errors.Is(err, ErrReviewNotFound) {
+		respondError(w, "not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("reviews: get", "id", id, "err", err)
+		respondError(w, "failed to get review", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(review)
+}
+
+// reviewSourceHandler serves the raw page or PDF a review's extraction ran
+// on, for display beside the extracted items. Scraped HTML is untrusted, so
+// it is served sandboxed: no scripts, no same-origin access.
+func (s *Server) reviewSourceHandler(w http.ResponseWriter, r *http.Request) {
+	rs, id, ok := s.reviewFromPath(w, r)
+	if !ok {
+		return
+	}
+	body, err := rs.MenuReviewSource(r.Context(), id)
+	if errors.Is(err, ErrReviewNotFound) {
+		respondError(w, "not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, ErrReviewNoSource) {
+		respondError(w, "source not available", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("reviews: source", "id", id, "err", err)
+		respondError(w, "failed to read source", http.StatusInternalServerError)
+		return
+	}
+
+	ctype := http.DetectContentType(body)
+	if bytes.HasPrefix(body, []byte("%PDF-")) {
+		ctype = "application/pdf"
+	}
+	w.Header().Set("Content-Type", ctype)
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	_, _ = w.Write(body)
+}
+
+func (s *Server) reviewApproveHandler(w http.ResponseWriter, r *http.Request) {
+	rs, id, ok := s.reviewFromPath(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Items []scraper.MenuEntry `json:"items"`
+		Note  string              `json:"note"`
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxReviewBodySize)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Items != nil && len(req.Items) == 0 {
+		respondError(w, "items must not be empty; reject the review instead", http.StatusBadRequest)
+		return
+	}
+	for _, it := range req.Items {
+		if it.DishName == "" {
+			respondError(w, "every item needs a dish name", http.StatusBadRequest)
+			return
+		}
+	}
+	applier, _ := s.restaurantJobQueue.(MenuReviewApplier)
+	if applier == nil {
+		respondError(w, "job queue not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	userID, _ := r.Context().Value(userContextKey).(string)
+	review, ok := s.resolveReview(w, r, rs, id, ReviewDecision{
+		Approve:    true,
+		Items:      req.Items,
+		ReviewerID: userID,
+		Note:       req.Note,
+	})
+	if !ok {
+		return
+	}
+
+	// The review is approved before its apply job is queued; if queueing
+	// fails the review stays approved but unapplied and may be approved
+	// again.
+	if err := applier.EnqueueApplyReview(r.Context(), id); err != nil && !errors.Is(err, ErrJobAlreadyQueued) {
+		if errors.Is(err, ErrJobKindNotRegistered) {
+			respondError(w, "review approved but the apply worker is not configured; approve again once it is", http.StatusServiceUnavailable)
+			return
+		}
+		slog.Error("reviews: enqueue apply", "id", id, "err", err)
+		respondError(w, "review approved but failed to queue; approve again to retry", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(review)
+}
+
+func (s *Server) reviewRejectHandler(w http.ResponseWriter, r *http.Request) {
+	rs, id, ok := s.reviewFromPath(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Note string `json:"note"`
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := r.Context().Value(userContextKey).(string)
+	review, ok := s.resolveReview(w, r, rs, id, ReviewDecision{ReviewerID: userID, Note: req.Note})
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(review)
+}
+
+// resolveReview applies d, writing the error response and returning ok false
+// on failure.
+func (s *Server) resolveReview(w http.ResponseWriter, r *http.Request, rs MenuReviewStore, id uuid.UUID, d ReviewDecision) (*MenuReview, bool) {
+	review, err := rs.ResolveMenuReview(r.Context(), id, d)
+	if errors.Is(err, ErrReviewNotFound) {
+		respondError(w, "not found", http.StatusNotFound)
+		return nil, false
+	}
+	if errors.Is(err, ErrReviewClosed) {
+		respondError(w, "review already resolved", http.StatusConflict)
+		return nil, false
+	}
+	if errors.Is(err, ErrReviewNoItems) {
+		respondError(w, "review has no extracted items; approve with corrected items", http.StatusBadRequest)
+		return nil, false
+	}
+	if err != nil {
+		slog.Error("reviews: resolve", "id", id, "approve", d.Approve, "err", err)
+		respondError(w, "failed to resolve review", http.StatusInternalServerError)
+		return nil, false
+	}
+	return review, true
+}
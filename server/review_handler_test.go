@@ -0,0 +1,251 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"fodmap/scraper"
+)
+
+// reviewRestaurantStore is a stubRestaurantStore holding menu reviews.
+type reviewRestaurantStore struct {
+	*stubRestaurantStore
+	reviews map[uuid.UUID]*MenuReview
+	sources map[uuid.UUID][]byte
+}
+
+func (s *reviewRestaurantStore) ListMenuReviews(_ context.Context, status string, limit, offset int) ([]MenuReview, int, error) {
+	var out []MenuReview
+	for _, m := range s.reviews {
+		if status == "" || m.Status == status {
+			out = append(out, *m)
+		}
+	}
+	return out, len(out), nil
+}
+
+func (s *reviewRestaurantStore) GetMenuReview(_ context.Context, id uuid.UUID) (*MenuReview, error) {
+	m, ok := s.reviews[id]
+	if !ok {
+		return nil, ErrReviewNotFound
+	}
+	return m, nil
+}
+
+func (s *reviewRestaurantStore) MenuReviewSource(_ context.Context, id uuid.UUID) ([]byte, error) {
+	if _, ok := s.reviews[id]; !ok {
+		return nil, ErrReviewNotFound
+	}
+	body, ok := s.sources[id]
+	if !ok {
+		return nil, ErrReviewNoSource
+	}
+	return body, nil
+}
+
+func (s *reviewRestaurantStore) ResolveMenuReview(_ context.Context, id uuid.UUID, d ReviewDecision) (*MenuReview, error) {
+	m, ok := s.reviews[id]
+	if !ok {
+		return nil, ErrReviewNotFound
+	}
+	if m.Status != ReviewPending && !(d.Approve && m.Status == ReviewApproved && m.AppliedAt == nil) {
+		return nil, ErrReviewClosed
+	}
+	if !d.Approve {
+		m.Status = ReviewRejected
+		return m, nil
+	}
+	items := d.Items
+	if items == nil {
+		items = m.Items
+	}
+	if len(items) == 0 {
+		return nil, ErrReviewNoItems
+	}
+	m.Status, m.Corrected, m.ReviewedBy = ReviewApproved, items, &d.ReviewerID
+	return m, nil
+}
+
+// reviewJobQueue is a stubRestaurantJobQueue that records applied reviews.
+type reviewJobQueue struct {
+	stubRestaurantJobQueue
+	applied  []uuid.UUID
+	applyErr error
+}
+
+func (q *reviewJobQueue) EnqueueApplyReview(_ context.Context, id uuid.UUID) error {
+	if q.applyErr != nil {
+		return q.applyErr
+	}
+	q.applied = append(q.applied, id)
+	return nil
+}
+
+func reviewRequest(s *Server, method, path, body string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/restaurants/reviews", s.reviewListHandler)
+	mux.HandleFunc("GET /api/v1/restaurants/reviews/{id}", s.reviewGetHandler)
+	mux.HandleFunc("GET /api/v1/restaurants/reviews/{id}/source", s.reviewSourceHandler)
+	mux.HandleFunc("POST /api/v1/restaurants/reviews/{id}/approve", s.reviewApproveHandler)
+	mux.HandleFunc("POST /api/v1/restaurants/reviews/{id}/reject", s.reviewRejectHandler)
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), userContextKey, "admin1"))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func newReviewFixture() (*reviewRestaurantStore, *MenuReview, *MenuReview) {
+	quarantined := &MenuReview{
+		ID:          uuid.New(),
+		Status:      ReviewPending,
+		Quarantined: true,
+		Items:       []scraper.MenuEntry{{DishName: "Pad Thai"}, {DishName: "Pad Thai"}},
+	}
+	failed := &MenuReview{ID: uuid.New(), Status: ReviewPending, LastError: strPtr("no menu items found"), HasSource: true}
+	rs := &reviewRestaurantStore{
+		stubRestaurantStore: newStubRestaurantStore(),
+		reviews:             map[uuid.UUID]*MenuReview{quarantined.ID: quarantined, failed.ID: failed},
+		sources:             map[uuid.UUID][]byte{failed.ID: []byte("<html><script>alert(1)</script><p>Menu</p></html>")},
+	}
+	return rs, quarantined, failed
+}
+
+func TestReviewListAndGet(t *testing.T) {
+	rs, quarantined, _ := newReviewFixture()
+	quarantined.Status = ReviewRejected
+	s := &Server{restaurantStore: rs}
+
+	w := reviewRequest(s, http.MethodGet, "/api/v1/restaurants/reviews", "")
+	var body struct {
+		Reviews []MenuReview `json:"reviews"`
+		Total   int          `json:"total"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Total != 1 || len(body.Reviews) != 1 || body.Reviews[0].Status != ReviewPending {
+		t.Errorf("default list = %+v, want only the pending review", body)
+	}
+	if w := reviewRequest(s, http.MethodGet, "/api/v1/restaurants/reviews?status=all", ""); !strings.Contains(w.Body.String(), `"total":2`) {
+		t.Errorf("status=all body = %s", w.Body.String())
+	}
+	if w := reviewRequest(s, http.MethodGet, "/api/v1/restaurants/reviews?status=bogus", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("bogus status = %d, want 400", w.Code)
+	}
+
+	if w := reviewRequest(s, http.MethodGet, "/api/v1/restaurants/reviews/"+quarantined.ID.String(), ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Pad Thai") {
+		t.Errorf("get = %d %s", w.Code, w.Body.String())
+	}
+	if w := reviewRequest(s, http.MethodGet, "/api/v1/restaurants/reviews/"+uuid.NewString(), ""); w.Code != http.StatusNotFound {
+		t.Errorf("unknown review = %d, want 404", w.Code)
+	}
+	if w := reviewRequest(s, http.MethodGet, "/api/v1/restaurants/reviews/nope", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("bad id = %d, want 400", w.Code)
+	}
+}
+
+func TestReviewSourceIsSandboxed(t *testing.T) {
+	rs, quarantined, failed := newReviewFixture()
+	s := &Server{restaurantStore: rs}
+
+	w := reviewRequest(s, http.MethodGet, "/api/v1/restaurants/reviews/"+failed.ID.String()+"/source", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("source = %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Security-Policy"); got != "sandbox" {
+		t.Errorf("Content-Security-Policy = %q, want sandbox", got)
+	}
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/html") {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if w := reviewRequest(s, http.MethodGet, "/api/v1/restaurants/reviews/"+quarantined.ID.String()+"/source", ""); w.Code != http.StatusNotFound {
+		t.Errorf("review without source = %d, want 404", w.Code)
+	}
+}
+
+func TestReviewApprove(t *testing.T) {
+	rs, quarantined, failed := newReviewFixture()
+	q := &reviewJobQueue{}
+	s := &Server{restaurantStore: rs, restaurantJobQueue: q}
+
+	// A failed extraction has nothing to approve unchanged.
+	if w := reviewRequest(s, http.MethodPost, "/api/v1/restaurants/reviews/"+failed.ID.String()+"/approve", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("approve failed extraction unchanged = %d, want 400", w.Code)
+	}
+	if w := reviewRequest(s, http.MethodPost, "/api/v1/restaurants/reviews/"+quarantined.ID.String()+"/approve", `{"items":[]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("approve with empty items = %d, want 400", w.Code)
+	}
+
+	body := `{"items":[{"dish":"Pad Thai","price":14,"stated_ingredients":["rice noodles","egg"]}],"note":"dropped duplicate"}`
+	w := reviewRequest(s, http.MethodPost, "/api/v1/restaurants/reviews/"+quarantined.ID.String()+"/approve", body)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("approve = %d: %s", w.Code, w.Body.String())
+	}
+	if len(quarantined.Corrected) != 1 || quarantined.Corrected[0].StatedIngredients[1] != "egg" {
+		t.Errorf("corrected = %+v", quarantined.Corrected)
+	}
+	if quarantined.ReviewedBy == nil || *quarantined.ReviewedBy != "admin1" {
+		t.Errorf("reviewed_by = %v, want admin1", quarantined.ReviewedBy)
+	}
+	if len(q.applied) != 1 || q.applied[0] != quarantined.ID {
+		t.Errorf("applied = %v", q.applied)
+	}
+
+	// Approved but not yet applied: approving again re-queues it.
+	if w := reviewRequest(s, http.MethodPost, "/api/v1/restaurants/reviews/"+quarantined.ID.String()+"/approve", ""); w.Code != http.StatusAccepted {
+		t.Errorf("re-approve = %d, want 202", w.Code)
+	}
+	if w := reviewRequest(s, http.MethodPost, "/api/v1/restaurants/reviews/"+quarantined.ID.String()+"/reject", ""); w.Code != http.StatusConflict {
+		t.Errorf("reject approved review = %d, want 409", w.Code)
+	}
+}
+
+func TestReviewApproveWithoutApplier(t *testing.T) {
+	rs, quarantined, _ := newReviewFixture()
+	s := &Server{restaurantStore: rs, restaurantJobQueue: &stubRestaurantJobQueue{}}
+
+	if w := reviewRequest(s, http.MethodPost, "/api/v1/restaurants/reviews/"+quarantined.ID.String()+"/approve", ""); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("approve = %d, want 503", w.Code)
+	}
+	if quarantined.Status != ReviewPending {
+		t.Errorf("status = %q, want the review left pending", quarantined.Status)
+	}
+
+	s.restaurantJobQueue = &reviewJobQueue{applyErr: ErrJobKindNotRegistered}
+	if w := reviewRequest(s, http.MethodPost, "/api/v1/restaurants/reviews/"+quarantined.ID.String()+"/approve", ""); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("approve without worker = %d, want 503", w.Code)
+	}
+}
+
+func TestReviewReject(t *testing.T) {
+	rs, _, failed := newReviewFixture()
+	s := &Server{restaurantStore: rs}
+
+	w := reviewRequest(s, http.MethodPost, "/api/v1/restaurants/reviews/"+failed.ID.String()+"/reject", `{"note":"not a menu"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reject = %d: %s", w.Code, w.Body.String())
+	}
+	if failed.Status != ReviewRejected {
+		t.Errorf("status = %q, want rejected", failed.Status)
+	}
+	if w := reviewRequest(s, http.MethodPost, "/api/v1/restaurants/reviews/"+failed.ID.String()+"/reject", ""); w.Code != http.StatusConflict {
+		t.Errorf("second reject = %d, want 409", w.Code)
+	}
+}
+
+func TestReviewHandlersUnsupported(t *testing.T) {
+	s := &Server{restaurantStore: newStubRestaurantStore()}
+	if w := reviewRequest(s, http.MethodGet, "/api/v1/restaurants/reviews", ""); w.Code != http.StatusNotImplemented {
+		t.Errorf("list = %d, want 501", w.Code)
+	}
+	if w := reviewRequest(s, http.MethodPost, "/api/v1/restaurants/reviews/"+uuid.NewString()+"/reject", ""); w.Code != http.StatusNotImplemented {
+		t.Errorf("reject = %d, want 501", w.Code)
+	}
+}
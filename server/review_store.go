@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fodmap/scraper"
+)
+
+// Menu review statuses. A review is opened as pending when an extraction is
+// quarantined or fails with a stored source; a later successful scrape of the
+// same URL supersedes it.
+const (
+	ReviewPending    = "pending"
+	ReviewApproved   = "approved"
+	ReviewRejected   = "rejected"
+	ReviewSuperseded = "superseded"
+)
+
+var (
+	// ErrReviewNotFound is returned when no review has the given ID.
+	ErrReviewNotFound = errors.New("menu review not found")
+	// ErrReviewClosed is returned when resolving a review that was already
+	// rejected, superseded, or approved and applied.
+	ErrReviewClosed = errors.New("menu review already resolved")
+	// ErrReviewNoSource is returned when a review's raw source was not kept
+	// or is no longer on disk.
+	ErrReviewNoSource = errors.New("menu review has no stored source")
+	// ErrReviewNoItems is returned when approving a review unchanged that
+	// has no extracted items, e.g. a failed extraction.
+	ErrReviewNoItems = errors.New("menu review has no items to approve")
+)
+
+// MenuReview is an extraction awaiting, or given, human review. Items,
+// Report and Corrected are only set by MenuReviewStore.GetMenuReview.
+type MenuReview struct {
+	ID               uuid.UUID           `json:"id"`
+	BusinessID       uuid.UUID           `json:"business_id"`
+	CAMIS            *string             `json:"camis,omitempty"`
+	RestaurantName   string              `json:"restaurant_name"`
+	RestaurantStatus string              `json:"restaurant_status"`
+	SourceURL        string              `json:"source_url"`
+	ExtractionTier   *string             `json:"extraction_tier"`
+	Score            float64             `json:"score"`
+	Grounded         bool                `json:"grounded"`
+	Quarantined      bool                `json:"quarantined"`
+	LastError        *string             `json:"last_error,omitempty"`
+	Status           string              `json:"status"`
+	ItemCount        int                 `json:"item_count"`
+	FlaggedCount     int                 `json:"flagged_count"`
+	HasSource        bool                `json:"has_source"`
+	Items            []scraper.MenuEntry `json:"items,omitempty"`
+	Report           json.RawMessage     `json:"report,omitempty"`
+	Corrected        []scraper.MenuEntry `json:"corrected,omitempty"`
+	ReviewedBy       *string             `json:"reviewed_by,omitempty"`
+	ReviewedAt       *time.Time          `json:"reviewed_at,omitempty"`
+	ReviewNote       *string             `json:"review_note,omitempty"`
+	AppliedAt        *time.Time          `json:"applied_at,omitempty"`
+	CreatedAt        time.Time           `json:"created_at"`
+}
+
+// ReviewDecision resolves a pending review.
+type ReviewDecision struct {
+	Approve bool
+	// Items are the approved items, as corrected by the reviewer. Nil
+	// approves the extracted items unchanged.
+	Items      []scraper.MenuEntry
+	ReviewerID string
+	Note       string
+}
+
+// MenuReviewStore is implemented by restaurant stores that keep quarantined
+// and failed extractions for review.
+type MenuReviewStore interface {
+	// ListMenuReviews returns reviews with the given status ("" for all),
+	// newest first, and the total matching.
+	ListMenuReviews(ctx context.Context, status string, limit, offset int) ([]MenuReview, int, error)
+	// GetMenuReview returns ErrReviewNotFound when no review has id.
+	GetMenuReview(ctx context.Context, id uuid.UUID) (*MenuReview, error)
+	// MenuReviewSource returns the raw page or document the review's
+	// extraction ran on, or ErrReviewNoSource.
+	MenuReviewSource(ctx context.Context, id uuid.UUID) ([]byte, error)
+	// ResolveMenuReview records d. Approving stores d.Items (or the
+	// extracted items) as corrected; an approved review that has not been
+	// applied yet may be approved again. Rejecting moves a quarantined
+	// restaurant to failed_scrape. It returns ErrReviewNotFound,
+	// ErrReviewClosed or ErrReviewNoItems.
+	ResolveMenuReview(ctx context.Context, id uuid.UUID, d ReviewDecision) (*MenuReview, error)
+}
+
+// MenuReviewApplier is implemented by job queues that can write an approved
+// review's items to the menu store.
+type MenuReviewApplier interface {
+	EnqueueApplyReview(ctx context.Context, reviewID uuid.UUID) error
+}
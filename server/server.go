@@ -11,8 +11,10 @@ import (
 	"fodmap/chat"
 	"fodmap/data"
 	"fodmap/fodmap/store"
+	"fodmap/queryparse"
 	"fodmap/search"
 
+	"github.com/google/uuid"
 	"golang.org/x/time/rate"
 	"google.golang.org/genai"
 )
@@ -69,19 +71,39 @@ type CatalogStore interface {
 
 // MenuStore manages the RestaurantMenu collection. It is defined separately
 // from Searcher so the same Weaviate/Postgres/Pinecone backend types can
-// satisfy both interfaces independently. Currently only *search.Client
-// (Weaviate) implements it; DeleteStaleMenu is deferred to a follow-up PR
-// when a --purge-stale flag is added.
+// satisfy both interfaces independently. *search.Client (Weaviate) and
+// *search.PostgresClient implement it. Stale items are retired by
+// MenuVersioner.ReplaceMenu where supported; BatchUpsertMenu only adds and
+// overwrites.
 type MenuStore interface {
 	EnsureMenuSchema(ctx context.Context) error
 	BatchUpsertMenu(ctx context.Context, items []search.MenuItem) error
-	SearchMenu(ctx context.Context, query string, limit int) ([]search.MenuItem, error)
+	SearchMenu(ctx context.Context, query string, limit int, filter search.SearchFilter) ([]search.MenuItem, error)
 	ListMenuItems(ctx context.Context, search string, limit, offset int) ([]search.MenuItem, int, error)
 }
 
+// MenuVersioner is an optional MenuStore capability: keeping a snapshot per
+// scrape with item-level changes, soft-deleting items that leave the menu,
+// and serving a restaurant's menu timeline. *search.PostgresClient
+// implements it; the scrape pipeline prefers ReplaceMenu over
+// BatchUpsertMenu when the store has it.
+type MenuVersioner interface {
+	ReplaceMenu(ctx context.Context, items []search.MenuItem) (*search.MenuSnapshot, error)
+	MenuTimeline(ctx context.Context, businessID uuid.UUID, itemID string, limit int) ([]search.MenuSnapshot, error)
+}
+
+// MenuItemDeleter is implemented by menu stores that can drop items by ID.
+// DualMenuStore uses it to remove retired items from a mirror that keeps
+// no history.
+type MenuItemDeleter interface {
+	DeleteMenuItems(ctx context.Context, ids []string) error
+}
+
 type Server struct {
-	searcher           Searcher  // nil when Weaviate is not configured
-	menuStore          MenuStore // nil when no dedicated MenuStore configured; cli falls back to type-asserting searcher
+	searcher           Searcher                  // nil when Weaviate is not configured
+	menuStore          MenuStore                 // nil when no dedicated MenuStore configured; cli falls back to type-asserting searcher
+	retrieval          *search.RetrievalPipeline // nil = rank with the backend alone
+	queryParser        *queryparse.Parser        // nil = search with queries as given
 	catalogStore       CatalogStore
 	port               int
 	chatBackend        chat.ChatBackend // nil when chat is not configured
@@ -99,6 +121,9 @@ type Server struct {
 	menutrackingAdmin  http.Handler       // nil when menutracking is not configured
 	restaurantStore    RestaurantStore    // nil when menusearch is not configured
 	restaurantJobQueue RestaurantJobQueue // nil when menusearch is not configured
+	egressStats        EgressStats        // nil when the pipeline is not running
+	embeddingCache     EmbeddingCache     // nil when embeddings are not cached
+	areaStore          AreaStore          // nil when no area store is configured
 	ctx                context.Context
 	cancel             context.CancelFunc
 }
@@ -107,19 +132,44 @@ type Config struct {
 	Port int
 
 	// Search configuration.
+	LocalIndexDir     string          // optional; embedded on-disk backend, takes precedence over the others
 	WeaviateHost      string          // optional; if empty, Weaviate is not used
 	WeaviateScheme    string          // optional; e.g. "http" or "https"
 	WeaviateAPIKey    string          // optional; for Weaviate Cloud (WCD)
 	PineconeAPIKey    string          // optional
 	PineconeIndexHost string          // optional (must start with https://)
+	PineconeBM25Stats string          // optional; BM25 corpus stats file written by `index`
 	VectorizerURL     string          // required for Pinecone; optional otherwise
 	PostgresSearch    bool            // optional; if true, uses PostgreSQL for search
 	PostgresDSN       string          // required; used by both auth and the FODMAP catalog store
 	Embedder          search.Embedder // embedding provider (LlamaEmbedder or VectorizerClient)
 	CatalogStore      CatalogStore    // canonical FODMAP ingredient store
 
+	// SecondarySearch names a second search backend ("postgres", "weaviate",
+	// "pinecone" or "local", connected with the fields above, except that a
+	// local one opens SecondaryLocalIndexDir) that review and FODMAP writes
+	// are mirrored to best-effort; empty = primary only. ShadowRead also
+	// repeats reads against it and logs how its rankings diverge from the
+	// primary's. See DualSearcher.
+	SecondarySearch        string
+	SecondaryLocalIndexDir string
+	ShadowRead             bool
+
+	// Retrieval fuses dense and lexical candidates and/or reranks them for
+	// query searches over reviews, businesses and menus; nil leaves ranking
+	// to the search backend.
+	Retrieval *search.RetrievalPipeline
+
+	// ParseQueries reads location, cuisine, diet, ingredient, price and
+	// FODMAP constraints out of free-text business, review, menu and chat
+	// queries (see queryparse); explicit query parameters still win.
+	// ParseQueriesLLM lets the filter model resolve places the gazetteer
+	// does not know; it needs the chat configuration below.
+	ParseQueries    bool
+	ParseQueriesLLM bool
+
 	// MenuStore selection. MenuStoreType is "postgres" (default), "weaviate",
-	// or "dual". When empty, the legacy Searcher selection below is used for
+	// "dual" or "local". When empty, the legacy Searcher selection below is used for
 	// menu writes too (type-asserted in cli/serve.go). When set, a dedicated
 	// MenuStore is built via NewMenuStore and the Searcher selection is used
 	// only for the review/fodmap search surfaces.
@@ -156,27 +206,38 @@ func New(ctx context.Context, cfg Config) (*Server, error) {
 		jwtSecret:          cfg.JWTSecret,
 		adminEmail:         cfg.AdminEmail,
 		menutrackingAdmin:  cfg.MenutrackingAdmin,
+		retrieval:          cfg.Retrieval,
 		ctx:                serverCtx,
 		cancel:             cancel,
 	}
 
-	if cfg.PostgresSearch && cfg.PostgresDSN != "" {
-		sc, err := search.NewPostgresClient(cfg.PostgresDSN, cfg.Embedder)
+	if kind := primarySearchKind(cfg); kind != "" {
+		sc, err := openSearcher(kind, cfg)
 		if err != nil {
-			return nil, fmt.Errorf("initializing postgres search client: %w", err)
+			return nil, err
 		}
 		s.searcher = sc
-		slog.Info("postgres (pgvector) search enabled")
-	} else if cfg.PineconeAPIKey != "" && cfg.PineconeIndexHost != "" {
-		s.searcher = search.NewPineconeClient(cfg.PineconeAPIKey, cfg.PineconeIndexHost, cfg.Embedder)
-		slog.Info("pinecone search enabled", "host", cfg.PineconeIndexHost)
-	} else if cfg.WeaviateHost != "" {
-		sc, err := search.NewClient(cfg.WeaviateHost, cfg.WeaviateScheme, cfg.WeaviateAPIKey, cfg.Embedder)
+	}
+	if cfg.SecondarySearch != "" {
+		if s.searcher == nil {
+			return nil, fmt.Errorf("a secondary search backend needs a primary one")
+		}
+		if cfg.SecondarySearch == primarySearchKind(cfg) {
+			return nil, fmt.Errorf("secondary search backend %q is also the primary", cfg.SecondarySearch)
+		}
+		// The secondary has its own index directory: the primary's can
+		// only be open once.
+		secondaryCfg := cfg
+		secondaryCfg.LocalIndexDir = cfg.SecondaryLocalIndexDir
+		if cfg.SecondarySearch == "local" && secondaryCfg.LocalIndexDir == "" {
+			return nil, fmt.Errorf("secondary local search requires --secondary-local-index")
+		}
+		secondary, err := openSearcher(cfg.SecondarySearch, secondaryCfg)
 		if err != nil {
-			return nil, fmt.Errorf("initializing weaviate client: %w", err)
+			return nil, fmt.Errorf("secondary search: %w", err)
 		}
-		s.searcher = sc
-		slog.Info("weaviate search enabled", "host", cfg.WeaviateHost)
+		s.searcher = NewDualSearcher(s.searcher, secondary, cfg.ShadowRead)
+		slog.Info("dual-write search enabled", "secondary", cfg.SecondarySearch, "shadow_read", cfg.ShadowRead)
 	}
 
 	if s.searcher != nil {
@@ -191,9 +252,15 @@ func New(ctx context.Context, cfg Config) (*Server, error) {
 	// Build a dedicated MenuStore when --menu-store is set. When empty,
 	// callers fall back to type-asserting s.searcher as a MenuStore (legacy
 	// behavior preserved so existing deployments don't need to set the flag).
-	if cfg.MenuStoreType != "" {
+	// A local menu store shares the searcher's client: a local index
+	// directory can only be open once.
+	if lc, ok := UnwrapSearcher(s.searcher).(*search.LocalClient); ok && cfg.MenuStoreType == "local" {
+		s.menuStore = lc
+		slog.Info("menu store enabled", "type", cfg.MenuStoreType)
+	} else if cfg.MenuStoreType != "" {
 		ms, err := NewMenuStore(ctx, MenuStoreConfig{
 			Type:           cfg.MenuStoreType,
+			LocalIndexDir:  cfg.LocalIndexDir,
 			PostgresDSN:    cfg.PostgresDSN,
 			WeaviateHost:   cfg.WeaviateHost,
 			WeaviateScheme: cfg.WeaviateScheme,
@@ -259,6 +326,13 @@ func New(ctx context.Context, cfg Config) (*Server, error) {
 		slog.Info("chat endpoint enabled", "model", chatModel, "project", cfg.GoogleCloudProject, "location", location)
 	}
 
+	if cfg.ParseQueries {
+		s.queryParser = queryparse.New(nil)
+		if cfg.ParseQueriesLLM && s.genaiClient != nil {
+			s.queryParser.LLM = chat.NewGeminiBackend(s.genaiClient, s.filterModel)
+		}
+	}
+
 	// Legacy Gemini Developer API path (API key). Kept for reference; the
 	// service now uses Vertex AI / ADC above. Restore this block (and the
 	// GeminiAPIKey Config field) to revert.
@@ -400,6 +474,8 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("GET /api/v1/search/businesses/{query...}", s.getBusinessesHandler)
 	mux.HandleFunc("GET /api/v1/search/reviews/{query...}", s.getReviewsHandler)
 	mux.HandleFunc("GET /api/v1/search/fodmap/{ingredient...}", s.getFodmapHandler)
+	mux.HandleFunc("GET /api/v1/search/menu/{query...}", s.searchMenuHandler)
+	mux.HandleFunc("GET /api/v1/menu/{business_id}/timeline", s.menuTimelineHandler)
 
 	// Auth handlers
 	mux.HandleFunc("POST /api/v1/auth/register", s.registerHandler)
@@ -431,6 +507,12 @@ func (s *Server) Handler() http.Handler {
 	mux.Handle("POST /api/v1/admin/ingredients/reseed", adminMid(s.adminReseedIngredientsHandler))
 	mux.Handle("GET /api/v1/admin/analytics/overview", adminMid(s.adminAnalyticsOverviewHandler))
 	mux.Handle("GET /api/v1/admin/analytics/activity", adminMid(s.adminConversationActivityHandler))
+	if s.egressStats != nil {
+		mux.Handle("GET /api/v1/admin/egress", adminMid(s.adminEgressStatsHandler))
+	}
+	if s.embeddingCache != nil {
+		mux.Handle("GET /api/v1/admin/embedding-cache", adminMid(s.adminEmbeddingCacheHandler))
+	}
 
 	// Conversation handlers (protected by JWT)
 	mux.Handle("GET /api/v1/conversations", jwtAuth(s.jwtSecret)(http.HandlerFunc(s.listConversationsHandler)))
@@ -475,10 +557,30 @@ func (s *Server) Handler() http.Handler {
 		mux.Handle("POST /api/v1/restaurants", adminMid(s.restaurantCreateHandler))
 		mux.Handle("GET /api/v1/restaurants", adminMid(s.restaurantListHandler))
 		mux.Handle("GET /api/v1/restaurants/stats", adminMid(s.restaurantStatsHandler))
+		mux.Handle("GET /api/v1/restaurants/reviews", adminMid(s.reviewListHandler))
+		mux.Handle("GET /api/v1/restaurants/reviews/{id}", adminMid(s.reviewGetHandler))
+		mux.Handle("GET /api/v1/restaurants/reviews/{id}/source", adminMid(s.reviewSourceHandler))
+		mux.Handle("POST /api/v1/restaurants/reviews/{id}/approve", adminMid(s.reviewApproveHandler))
+		mux.Handle("POST /api/v1/restaurants/reviews/{id}/reject", adminMid(s.reviewRejectHandler))
 		mux.Handle("GET /api/v1/restaurants/{camis}", adminMid(s.restaurantGetHandler))
 		mux.Handle("POST /api/v1/restaurants/{camis}/discover", adminMid(s.restaurantTriggerDiscoverHandler))
 		mux.Handle("POST /api/v1/restaurants/{camis}/scrape", adminMid(s.restaurantTriggerScrapeHandler))
 		mux.Handle("POST /api/v1/restaurants/{camis}/retry", adminMid(s.restaurantRetryHandler))
+		mux.HandleFunc("GET /api/v1/search/nearby", s.nearbyRestaurantsHandler)
+		mux.Handle("GET /api/v1/favorites", jwtAuth(s.jwtSecret)(http.HandlerFunc(s.listFavoritesHandler)))
+		mux.Handle("PUT /api/v1/favorites/{business_id}", jwtAuth(s.jwtSecret)(http.HandlerFunc(s.addFavoriteHandler)))
+		mux.Handle("DELETE /api/v1/favorites/{business_id}", jwtAuth(s.jwtSecret)(http.HandlerFunc(s.removeFavoriteHandler)))
+	}
+
+	// Geographic area admin endpoints (protected by JWT admin).
+	if s.areaStore != nil {
+		mux.Handle("GET /api/v1/areas", adminMid(s.areaListHandler))
+		mux.Handle("GET /api/v1/areas/{name}", adminMid(s.areaGetHandler))
+		mux.Handle("PUT /api/v1/areas/{name}", adminMid(s.areaPutHandler))
+		mux.Handle("DELETE /api/v1/areas/{name}", adminMid(s.areaDeleteHandler))
+		if s.restaurantStore != nil {
+			mux.Handle("GET /api/v1/areas/{name}/restaurants", adminMid(s.areaRestaurantsHandler))
+		}
 	}
 
 	// Menutracking admin endpoints (protected by JWT or ChatAPIKey).
@@ -512,14 +614,99 @@ func (s *Server) SetRestaurantStore(rs RestaurantStore) {
 	s.restaurantStore = rs
 }
 
+// SetEgressStats wires the pipeline's egress pool for the admin egress
+// endpoint.
+func (s *Server) SetEgressStats(es EgressStats) {
+	s.egressStats = es
+}
+
+// SetEmbeddingCache wires the embedder's cache for the admin embedding
+// cache endpoint.
+func (s *Server) SetEmbeddingCache(ec EmbeddingCache) {
+	s.embeddingCache = ec
+}
+
+// SetAreaStore wires the geographic area store for the admin area endpoints.
+func (s *Server) SetAreaStore(as AreaStore) {
+	s.areaStore = as
+}
+
 // SetRestaurantJobQueue wires the job queue for restaurant discover/scrape triggers.
 func (s *Server) SetRestaurantJobQueue(q RestaurantJobQueue) {
 	s.restaurantJobQueue = q
 }
 
+// primarySearchKind names the search backend cfg selects: the embedded
+// backend, then Postgres, Pinecone and Weaviate in that order of precedence,
+// or "" when none is configured.
+func primarySearchKind(cfg Config) string {
+	switch {
+	case cfg.LocalIndexDir != "":
+		return "local"
+	case cfg.PostgresSearch && cfg.PostgresDSN != "":
+		return "postgres"
+	case cfg.PineconeAPIKey != "" && cfg.PineconeIndexHost != "":
+		return "pinecone"
+	case cfg.WeaviateHost != "":
+		return "weaviate"
+	}
+	return ""
+}
+
+// openSearcher connects the search backend kind with cfg's settings for it.
+func openSearcher(kind string, cfg Config) (Searcher, error) {
+	switch kind {
+	case "local":
+		if cfg.LocalIndexDir == "" {
+			return nil, fmt.Errorf("local search requires --local-index")
+		}
+		lc, err := search.NewLocalClient(cfg.LocalIndexDir, cfg.Embedder)
+		if err != nil {
+			return nil, fmt.Errorf("opening local index: %w", err)
+		}
+		slog.Info("local (embedded) search enabled", "dir", cfg.LocalIndexDir)
+		return lc, nil
+	case "postgres":
+		if cfg.PostgresDSN == "" {
+			return nil, fmt.Errorf("postgres search requires --postgres-dsn")
+		}
+		sc, err := search.NewPostgresClient(cfg.PostgresDSN, cfg.Embedder)
+		if err != nil {
+			return nil, fmt.Errorf("initializing postgres search client: %w", err)
+		}
+		sc.FollowEmbeddingSpaces(search.DefaultEmbeddingSpaceTTL)
+		slog.Info("postgres (pgvector) search enabled")
+		return sc, nil
+	case "pinecone":
+		if cfg.PineconeAPIKey == "" || cfg.PineconeIndexHost == "" {
+			return nil, fmt.Errorf("pinecone search requires --pinecone-api-key and --pinecone-index-host")
+		}
+		pc := search.NewPineconeClient(cfg.PineconeAPIKey, cfg.PineconeIndexHost, cfg.Embedder)
+		if cfg.PineconeBM25Stats != "" {
+			if err := pc.LoadCorpusStats(cfg.PineconeBM25Stats); err != nil {
+				return nil, fmt.Errorf("loading pinecone bm25 stats: %w", err)
+			}
+		}
+		slog.Info("pinecone search enabled", "host", cfg.PineconeIndexHost)
+		return pc, nil
+	case "weaviate":
+		if cfg.WeaviateHost == "" {
+			return nil, fmt.Errorf("weaviate search requires --weaviate")
+		}
+		sc, err := search.NewClient(cfg.WeaviateHost, cfg.WeaviateScheme, cfg.WeaviateAPIKey, cfg.Embedder)
+		if err != nil {
+			return nil, fmt.Errorf("initializing weaviate client: %w", err)
+		}
+		slog.Info("weaviate search enabled", "host", cfg.WeaviateHost)
+		return sc, nil
+	}
+	return nil, fmt.Errorf("unknown search backend %q (want postgres|weaviate|pinecone|local)", kind)
+}
+
 // Searcher returns the underlying search client, or nil if search is not
 // enabled. The return type is the concrete interface so callers can type-assert
-// to access additional methods.
+// to access additional methods. With a secondary backend it is a
+// *DualSearcher; assert capabilities on UnwrapSearcher(Searcher()).
 func (s *Server) Searcher() Searcher {
 	return s.searcher
 }
@@ -532,8 +719,12 @@ func (s *Server) MenuStore() MenuStore {
 }
 
 // Start registers routes and begins serving HTTP requests. It blocks until
-// the server stops via Stop, SIGTERM, or an error.
+// the server stops via Stop, SIGTERM, or an error, then waits for a dual
+// searcher's shadow reads to finish.
 func (s *Server) Start() error {
+	if d, ok := s.searcher.(*DualSearcher); ok {
+		defer func() { _ = d.Close() }()
+	}
 	addr := fmt.Sprintf(":%d", s.port)
 	slog.Info("server listening", "addr", addr)
 	srv := &http.Server{Addr: addr, Handler: s.Handler()}
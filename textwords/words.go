@@ -0,0 +1,17 @@
+// Package textwords holds the word splitting shared by the text matchers:
+// quality grounding, ingredient classification, query parsing, menu
+// language detection and directory address normalisation.
+package textwords
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Split returns the lower-cased words of s, splitting on every rune that is
+// neither a letter nor a digit, so "Gluten-free" becomes "gluten", "free".
+func Split(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,24 @@
+package textwords
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Gluten-free Pad Thai", []string{"gluten", "free", "pad", "thai"}},
+		{"31st  Street, Apt #2", []string{"31st", "street", "apt", "2"}},
+		{"Crème brûlée", []string{"crème", "brûlée"}},
+		{"大蒜/洋葱", []string{"大蒜", "洋葱"}},
+		{" -- ", nil},
+	}
+	for _, tt := range tests {
+		if got := Split(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("Split(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}